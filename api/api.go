@@ -46,6 +46,8 @@ type Endpoint struct {
 	Body string
 	// Stream flag
 	Stream bool
+	// Scopes an account needs to call the endpoint, any of them grants access
+	Scopes []string
 }
 
 // Service represents an API service
@@ -97,6 +99,7 @@ func Encode(e *Endpoint) map[string]string {
 	set("method", strings.Join(e.Method, ","))
 	set("path", strings.Join(e.Path, ","))
 	set("host", strings.Join(e.Host, ","))
+	set("scopes", strings.Join(e.Scopes, ","))
 
 	return ep
 }
@@ -114,6 +117,7 @@ func Decode(e map[string]string) *Endpoint {
 		Path:        slice(e["path"]),
 		Host:        slice(e["host"]),
 		Handler:     e["handler"],
+		Scopes:      slice(e["scopes"]),
 	}
 }
 
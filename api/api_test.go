@@ -15,6 +15,7 @@ func TestEncoding(t *testing.T) {
 			Host:        []string{"foo.com"},
 			Method:      []string{"GET"},
 			Path:        []string{"/test"},
+			Scopes:      []string{"admin", "ops"},
 		},
 	}
 
@@ -109,6 +110,9 @@ func TestEncoding(t *testing.T) {
 		if ok := compare(d.Host, de.Host); !ok {
 			t.Fatalf("expected %v got %v", d.Host, de.Host)
 		}
+		if ok := compare(d.Scopes, de.Scopes); !ok {
+			t.Fatalf("expected %v got %v", d.Scopes, de.Scopes)
+		}
 	}
 }
 
@@ -9,6 +9,7 @@ import (
 	api "github.com/micro/go-micro/v3/api/proto"
 	"github.com/micro/go-micro/v3/client"
 	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/selector"
 	"github.com/micro/go-micro/v3/util/ctx"
 	"github.com/micro/go-micro/v3/util/router"
 )
@@ -72,7 +73,13 @@ func (a *apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// create the context from headers
 	cx := ctx.FromRequest(r)
 
-	if err := c.Call(cx, req, rsp, client.WithRouter(router.New(service.Services))); err != nil {
+	// create custom router and weight the selection by the nodes weight
+	callOpts := []client.CallOption{
+		client.WithRouter(router.New(service.Services)),
+		client.WithSelectOptions(selector.Weights(router.Weights(service.Services))),
+	}
+
+	if err := c.Call(cx, req, rsp, callOpts...); err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		ce := errors.Parse(err.Error())
 		switch ce.Code {
@@ -0,0 +1,484 @@
+// Package async provides a handler for long running operations. Requests are accepted with
+// a 202 and the id of an operation, the call is made asynchronously through the broker and
+// the status and result of the operation are served from the store on /operations/{id}, only
+// to the account which made the request.
+package async
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/api/handler"
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/broker"
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/util/ctx"
+)
+
+const (
+	Handler = "async"
+)
+
+var (
+	// Path the operations are served on, followed by their id
+	Path = "/operations/"
+	// Prefix of the keys of the operations in the store
+	Prefix = "operation/"
+	// ForwardedMetadata are the keys of the metadata of the request passed on to the call,
+	// the rest isn't published. Only bearer tokens are passed as the authorization.
+	ForwardedMetadata = []string{"Authorization", "Micro-Trace-Id", "Micro-Span-Id", "Traceparent", "Tracestate"}
+)
+
+// Status of an operation
+type Status string
+
+const (
+	// Pending operations are waiting to be called
+	Pending Status = "pending"
+	// Running operations are being called
+	Running Status = "running"
+	// Succeeded operations hold the response of the call
+	Succeeded Status = "succeeded"
+	// Failed operations hold the error of the call
+	Failed Status = "failed"
+)
+
+// Operation is the state of an accepted request
+type Operation struct {
+	Id       string          `json:"id"`
+	Service  string          `json:"service"`
+	Endpoint string          `json:"endpoint"`
+	Status   Status          `json:"status"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    *errors.Error   `json:"error,omitempty"`
+	Created  time.Time       `json:"created"`
+	Updated  time.Time       `json:"updated"`
+	// Owner is the id of the account which made the request, blank when anonymous
+	Owner string `json:"owner,omitempty"`
+
+	// version of the record the operation was read from
+	version uint64
+}
+
+// request is published to the broker for the call to be made
+type request struct {
+	Id       string            `json:"id"`
+	Service  string            `json:"service"`
+	Endpoint string            `json:"endpoint"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Body     json.RawMessage   `json:"body,omitempty"`
+}
+
+// Async is the handler of the operations, it must be started to make their calls
+type Async struct {
+	opts    handler.Options
+	store   store.Store
+	auth    auth.Auth
+	topic   string
+	ttl     time.Duration
+	timeout time.Duration
+
+	sync.Mutex
+	sub  broker.Subscriber
+	exit chan bool
+}
+
+// forward returns the metadata of the request passed on to the call
+func forward(md metadata.Metadata) map[string]string {
+	fwd := make(map[string]string)
+	for _, k := range ForwardedMetadata {
+		v, ok := md.Get(k)
+		if !ok {
+			continue
+		}
+		if k == "Authorization" && !strings.HasPrefix(v, "Bearer ") {
+			continue
+		}
+		fwd[k] = v
+	}
+	return fwd
+}
+
+// account returns the account making the request, set in its context by the api or
+// inspected from its bearer token
+func (a *Async) account(r *http.Request) *auth.Account {
+	if acc, ok := auth.AccountFromContext(r.Context()); ok {
+		return acc
+	}
+	if a.auth == nil {
+		return nil
+	}
+
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, auth.BearerScheme) {
+		return nil
+	}
+	acc, _ := a.auth.Inspect(strings.TrimPrefix(header, auth.BearerScheme))
+	return acc
+}
+
+func (a *Async) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, Path) {
+		a.serveOperation(w, r)
+		return
+	}
+
+	bsize := handler.DefaultMaxRecvSize
+	if a.opts.MaxRecvSize > 0 {
+		bsize = a.opts.MaxRecvSize
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, bsize)
+	defer r.Body.Close()
+
+	if a.opts.Router == nil {
+		writeError(w, errors.InternalServerError("go.micro.api", "no route found"))
+		return
+	}
+
+	service, err := a.opts.Router.Route(r)
+	if err != nil {
+		writeError(w, errors.InternalServerError("go.micro.api", err.Error()))
+		return
+	}
+
+	var body []byte
+	if r.Method == "GET" {
+		body, _ = json.Marshal(r.URL.Query())
+	} else if body, err = ioutil.ReadAll(r.Body); err != nil {
+		writeError(w, errors.BadRequest("go.micro.api", err.Error()))
+		return
+	}
+
+	md, _ := metadata.FromContext(ctx.FromRequest(r))
+
+	now := time.Now()
+	op := &Operation{
+		Id:       uuid.New().String(),
+		Service:  service.Name,
+		Endpoint: service.Endpoint.Name,
+		Status:   Pending,
+		Created:  now,
+		Updated:  now,
+	}
+	if acc := a.account(r); acc != nil {
+		op.Owner = acc.ID
+	}
+
+	if err := a.write(op); err != nil {
+		writeError(w, errors.InternalServerError("go.micro.api", err.Error()))
+		return
+	}
+
+	b, err := json.Marshal(&request{
+		Id:       op.Id,
+		Service:  op.Service,
+		Endpoint: op.Endpoint,
+		Metadata: forward(md),
+		Body:     body,
+	})
+	if err != nil {
+		writeError(w, errors.InternalServerError("go.micro.api", err.Error()))
+		return
+	}
+
+	if err := a.broker().Publish(a.topic, &broker.Message{Body: b}); err != nil {
+		a.fail(op, errors.InternalServerError("go.micro.api", err.Error()))
+		writeError(w, errors.InternalServerError("go.micro.api", err.Error()))
+		return
+	}
+
+	w.Header().Set("Location", Path+op.Id)
+	writeJSON(w, http.StatusAccepted, op)
+}
+
+// serveOperation serves the status and result of an operation
+func (a *Async) serveOperation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, errors.MethodNotAllowed("go.micro.api", "%s not allowed", r.Method))
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, Path)
+
+	op, err := a.read(id)
+	if err == store.ErrNotFound {
+		writeError(w, errors.NotFound("go.micro.api", "operation %s not found", id))
+		return
+	} else if err != nil {
+		writeError(w, errors.InternalServerError("go.micro.api", err.Error()))
+		return
+	}
+
+	// only the account which made the request reads the operation
+	if len(op.Owner) > 0 {
+		acc := a.account(r)
+		if acc == nil {
+			writeError(w, errors.Unauthorized("go.micro.api", "Unauthorized request to operation %s", id))
+			return
+		}
+		if acc.ID != op.Owner {
+			writeError(w, errors.Forbidden("go.micro.api", "Forbidden request to operation %s by %s", id, acc.ID))
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, op)
+}
+
+// process makes the call of an accepted request, recording its outcome in the operation
+func (a *Async) process(m *broker.Message) error {
+	var req request
+	if err := json.Unmarshal(m.Body, &req); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error decoding the request of an operation: %v", err)
+		}
+		return nil
+	}
+
+	op, err := a.read(req.Id)
+	if err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error reading operation %s: %v", req.Id, err)
+		}
+		return nil
+	}
+
+	// the operation was redelivered after it completed or was failed as interrupted
+	if op.Status != Pending {
+		return nil
+	}
+
+	if err := a.claim(op); err == store.ErrConflict {
+		// the redelivered request is processed by another instance
+		return nil
+	} else if err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error writing operation %s: %v", op.Id, err)
+		}
+		return nil
+	}
+
+	c := a.opts.Client
+	cx := metadata.NewContext(context.Background(), req.Metadata)
+
+	body := req.Body
+	var rsp json.RawMessage
+
+	creq := c.NewRequest(req.Service, req.Endpoint, &body, client.WithContentType("application/json"))
+	if err := c.Call(cx, creq, &rsp, client.WithRequestTimeout(a.timeout)); err != nil {
+		a.fail(op, err)
+		return nil
+	}
+
+	op.Status = Succeeded
+	op.Result = rsp
+	op.Updated = time.Now()
+	if err := a.write(op); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error writing operation %s: %v", op.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// sweep fails the operations which were pending or running for longer than the calls
+// can take, their calls were interrupted e.g by an instance of the handler crashing
+func (a *Async) sweep() {
+	recs, err := a.store.Read(Prefix, store.ReadPrefix())
+	if err != nil && err != store.ErrNotFound {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error reading operations: %v", err)
+		}
+		return
+	}
+
+	for _, r := range recs {
+		op := new(Operation)
+		if err := json.Unmarshal(r.Value, op); err != nil {
+			continue
+		}
+		if op.Status != Pending && op.Status != Running {
+			continue
+		}
+		if time.Since(op.Updated) < a.timeout*2 {
+			continue
+		}
+		a.fail(op, errors.InternalServerError("go.micro.api", "operation %s was interrupted", op.Id))
+	}
+}
+
+// Start subscribes to the topic of the operations to make their calls, competing with the
+// other instances of the handler, and periodically fails the operations which were interrupted
+func (a *Async) Start() error {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.sub != nil {
+		return nil
+	}
+
+	if err := a.broker().Connect(); err != nil {
+		return err
+	}
+	sub, err := a.broker().Subscribe(a.topic, a.process, broker.Queue(a.topic))
+	if err != nil {
+		return err
+	}
+	a.sub = sub
+	a.exit = make(chan bool)
+
+	go func(exit chan bool) {
+		a.sweep()
+
+		t := time.NewTicker(a.timeout)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+				a.sweep()
+			case <-exit:
+				return
+			}
+		}
+	}(a.exit)
+
+	return nil
+}
+
+// Close unsubscribes from the topic of the operations, the calls being made are completed
+func (a *Async) Close() error {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.sub == nil {
+		return nil
+	}
+
+	close(a.exit)
+	err := a.sub.Unsubscribe()
+	a.sub = nil
+	return err
+}
+
+// claim sets the pending operation running unless it changed since it was read,
+// ErrConflict is returned when it did
+func (a *Async) claim(op *Operation) error {
+	op.Status = Running
+	op.Updated = time.Now()
+	return a.write(op, store.WriteIfMatch())
+}
+
+func (a *Async) fail(op *Operation, err error) {
+	op.Status = Failed
+	op.Error = errors.FromError(err)
+	op.Updated = time.Now()
+	if err := a.write(op); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error writing operation %s: %v", op.Id, err)
+		}
+	}
+}
+
+func (a *Async) read(id string) (*Operation, error) {
+	recs, err := a.store.Read(Prefix + id)
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, store.ErrNotFound
+	}
+
+	op := new(Operation)
+	if err := json.Unmarshal(recs[0].Value, op); err != nil {
+		return nil, err
+	}
+	op.version = recs[0].Version
+	return op, nil
+}
+
+func (a *Async) write(op *Operation, opts ...store.WriteOption) error {
+	b, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	return a.store.Write(&store.Record{
+		Key:     Prefix + op.Id,
+		Value:   b,
+		Expiry:  a.ttl,
+		Version: op.version,
+	}, opts...)
+}
+
+func (a *Async) broker() broker.Broker {
+	return a.opts.Client.Options().Broker
+}
+
+func (a *Async) String() string {
+	return "async"
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, errors.InternalServerError("go.micro.api", err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(b)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	ce := errors.FromError(err)
+	if ce.Code == 0 {
+		ce.Code = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(ce.Code))
+	w.Write([]byte(ce.Error()))
+}
+
+// NewHandler returns a handler accepting the requests as operations, it must be
+// started for the calls of the operations to be made
+func NewHandler(opts ...handler.Option) *Async {
+	options := handler.NewOptions(opts...)
+
+	a := &Async{
+		opts:    options,
+		store:   store.DefaultStore,
+		topic:   DefaultTopic,
+		ttl:     DefaultTTL,
+		timeout: DefaultTimeout,
+	}
+
+	if s, ok := options.Context.Value(storeKey{}).(store.Store); ok {
+		a.store = s
+	}
+	if au, ok := options.Context.Value(authKey{}).(auth.Auth); ok {
+		a.auth = au
+	}
+	if t, ok := options.Context.Value(topicKey{}).(string); ok {
+		a.topic = t
+	}
+	if d, ok := options.Context.Value(ttlKey{}).(time.Duration); ok {
+		a.ttl = d
+	}
+	if d, ok := options.Context.Value(timeoutKey{}).(time.Duration); ok {
+		a.timeout = d
+	}
+
+	return a
+}
@@ -0,0 +1,230 @@
+package async
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/api"
+	"github.com/micro/go-micro/v3/api/handler"
+	"github.com/micro/go-micro/v3/api/router"
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/store/memory"
+	"github.com/micro/go-micro/v3/util/test"
+)
+
+type Greeter struct{}
+
+type HelloRequest struct {
+	Name string `json:"name"`
+}
+
+type HelloResponse struct {
+	Msg string `json:"msg"`
+}
+
+func (g *Greeter) Hello(ctx context.Context, req *HelloRequest, rsp *HelloResponse) error {
+	if len(req.Name) == 0 {
+		return errors.BadRequest("greeter", "missing name")
+	}
+	rsp.Msg = "Hello " + req.Name
+	return nil
+}
+
+// testRouter routes every request to the endpoint
+type testRouter struct {
+	router.Router
+	endpoint string
+}
+
+func (t *testRouter) Route(r *http.Request) (*api.Service, error) {
+	return &api.Service{
+		Name:     "greeter",
+		Endpoint: &api.Endpoint{Name: t.endpoint},
+	}, nil
+}
+
+func TestAsync(t *testing.T) {
+	env := test.NewEnv()
+	defer env.Stop()
+
+	if _, err := env.Run("greeter", new(Greeter)); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(
+		handler.WithClient(env.NewClient()),
+		handler.WithRouter(&testRouter{endpoint: "Greeter.Hello"}),
+		Store(memory.NewStore()),
+	)
+	if err := h.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	// poll the operation until it's done
+	poll := func(location string) *Operation {
+		for i := 0; i < 100; i++ {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", location, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d got %d", http.StatusOK, w.Code)
+			}
+
+			op := new(Operation)
+			if err := json.Unmarshal(w.Body.Bytes(), op); err != nil {
+				t.Fatal(err)
+			}
+			if op.Status == Succeeded || op.Status == Failed {
+				return op
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		t.Fatal("Operation didn't complete")
+		return nil
+	}
+
+	call := func(body string) *Operation {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/greeter/hello", strings.NewReader(body)))
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected status %d got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+		}
+		location := w.Header().Get("Location")
+		if location == "" {
+			t.Fatal("Expected the location of the operation")
+		}
+		return poll(location)
+	}
+
+	op := call(`{"name": "John"}`)
+	if op.Status != Succeeded {
+		t.Fatalf("Expected the operation to succeed, got %+v", op)
+	}
+	var rsp HelloResponse
+	if err := json.Unmarshal(op.Result, &rsp); err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Msg != "Hello John" {
+		t.Fatalf("Unexpected result %s", op.Result)
+	}
+
+	op = call(`{}`)
+	if op.Status != Failed || op.Error == nil || op.Error.Code != 400 {
+		t.Fatalf("Expected the operation to fail, got %+v", op)
+	}
+
+	// unknown operations aren't found
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", Path+"unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Expected status %d got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestForward(t *testing.T) {
+	md := metadata.Metadata{
+		"Authorization":  "Bearer token",
+		"Micro-Trace-Id": "trace",
+		"Cookie":         "session",
+	}
+
+	fwd := forward(md)
+	if len(fwd) != 2 || fwd["Authorization"] != "Bearer token" || fwd["Micro-Trace-Id"] != "trace" {
+		t.Fatalf("Unexpected forwarded metadata %v", fwd)
+	}
+
+	md["Authorization"] = "Basic credentials"
+	if _, ok := forward(md)["Authorization"]; ok {
+		t.Fatal("Expected the basic credentials not to be forwarded")
+	}
+}
+
+func TestSweep(t *testing.T) {
+	h := NewHandler(Store(memory.NewStore()), Timeout(time.Second))
+
+	stale := &Operation{Id: "stale", Status: Running, Updated: time.Now().Add(-time.Minute)}
+	running := &Operation{Id: "running", Status: Running, Updated: time.Now()}
+	for _, op := range []*Operation{stale, running} {
+		if err := h.write(op); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h.sweep()
+
+	op, err := h.read("stale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Status != Failed || op.Error == nil {
+		t.Fatalf("Expected the interrupted operation to fail, got %+v", op)
+	}
+	op, err = h.read("running")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Status != Running {
+		t.Fatalf("Expected the operation to keep running, got %+v", op)
+	}
+}
+
+func TestOperationOwner(t *testing.T) {
+	h := NewHandler(Store(memory.NewStore()))
+
+	if err := h.write(&Operation{Id: "op", Status: Succeeded, Owner: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(acc *auth.Account) int {
+		r := httptest.NewRequest("GET", Path+"op", nil)
+		if acc != nil {
+			r = r.WithContext(auth.ContextWithAccount(r.Context(), acc))
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := get(nil); code != http.StatusUnauthorized {
+		t.Fatalf("Expected status %d got %d", http.StatusUnauthorized, code)
+	}
+	if code := get(&auth.Account{ID: "bob"}); code != http.StatusForbidden {
+		t.Fatalf("Expected status %d got %d", http.StatusForbidden, code)
+	}
+	if code := get(&auth.Account{ID: "alice"}); code != http.StatusOK {
+		t.Fatalf("Expected status %d got %d", http.StatusOK, code)
+	}
+}
+
+func TestClaim(t *testing.T) {
+	h := NewHandler(Store(memory.NewStore()))
+
+	if err := h.write(&Operation{Id: "op", Status: Pending}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the request is delivered to two instances
+	op1, err := h.read("op")
+	if err != nil {
+		t.Fatal(err)
+	}
+	op2, err := h.read("op")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.claim(op1); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.claim(op2); err != store.ErrConflict {
+		t.Fatalf("Expected %v got %v", store.ErrConflict, err)
+	}
+}
@@ -0,0 +1,60 @@
+package async
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/api/handler"
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/store"
+)
+
+var (
+	// DefaultTopic the accepted requests are published to
+	DefaultTopic = "go.micro.api.operations"
+	// DefaultTTL is how long operations are kept in the store
+	DefaultTTL = time.Hour * 24
+	// DefaultTimeout of the calls made for the operations
+	DefaultTimeout = time.Minute * 10
+)
+
+type storeKey struct{}
+type authKey struct{}
+type topicKey struct{}
+type ttlKey struct{}
+type timeoutKey struct{}
+
+func setOption(k, v interface{}) handler.Option {
+	return func(o *handler.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// Store sets the store the operations are kept in
+func Store(s store.Store) handler.Option {
+	return setOption(storeKey{}, s)
+}
+
+// Auth inspects the bearer tokens of the requests when the api doesn't set their account
+// in the context. The operations of authenticated requests are only served to their account.
+func Auth(a auth.Auth) handler.Option {
+	return setOption(authKey{}, a)
+}
+
+// Topic sets the topic the accepted requests are published to
+func Topic(t string) handler.Option {
+	return setOption(topicKey{}, t)
+}
+
+// TTL sets how long operations are kept in the store
+func TTL(d time.Duration) handler.Option {
+	return setOption(ttlKey{}, d)
+}
+
+// Timeout sets the timeout of the calls made for the operations
+func Timeout(d time.Duration) handler.Option {
+	return setOption(timeoutKey{}, d)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETag generated for a response
+type ETag int
+
+const (
+	// StrongETag changes whenever a byte of the response does
+	StrongETag ETag = iota
+	// WeakETag marks responses as semantically equivalent, e.g. when they're compressed
+	WeakETag
+	// NoETag disables the ETags and conditional requests
+	NoETag
+)
+
+// Generate returns the ETag of the response body, blank for NoETag
+func (e ETag) Generate(b []byte) string {
+	if e == NoETag {
+		return ""
+	}
+
+	sum := sha256.Sum256(b)
+	tag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	if e == WeakETag {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// NotModified evaluates the conditional headers of a GET or HEAD request against the
+// ETag and last modified time of the response. If-Modified-Since is only evaluated
+// when the request has no If-None-Match, a zero modified time never matches.
+func NotModified(r *http.Request, etag string, modified time.Time) bool {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); len(inm) > 0 {
+		if len(etag) == 0 {
+			return false
+		}
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			// weak comparison, the W/ prefix is ignored
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); len(ims) > 0 && !modified.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		// the header has a resolution of a second
+		return !modified.Truncate(time.Second).After(t)
+	}
+
+	return false
+}
+
+// WriteNotModified writes a 304 with the validators of the response and no body
+func WriteNotModified(w http.ResponseWriter, etag string) {
+	h := w.Header()
+	delete(h, "Content-Type")
+	delete(h, "Content-Length")
+	if len(etag) > 0 {
+		h.Set("ETag", etag)
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
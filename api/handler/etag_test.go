@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestETag(t *testing.T) {
+	b := []byte(`{"foo":"bar"}`)
+
+	strong := StrongETag.Generate(b)
+	if !strings.HasPrefix(strong, `"`) || strong != StrongETag.Generate(b) {
+		t.Fatalf("Expected a stable strong ETag, got %s", strong)
+	}
+	if weak := WeakETag.Generate(b); weak != "W/"+strong {
+		t.Fatalf("Expected weak ETag W/%s, got %s", strong, weak)
+	}
+	if StrongETag.Generate([]byte(`{}`)) == strong {
+		t.Fatal("Expected the ETag to change with the response")
+	}
+	if tag := NoETag.Generate(b); len(tag) > 0 {
+		t.Fatalf("Expected no ETag, got %s", tag)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	modified := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		name     string
+		method   string
+		header   map[string]string
+		modified time.Time
+		expect   bool
+	}{
+		{"unconditional", "GET", nil, modified, false},
+		{"etag match", "GET", map[string]string{"If-None-Match": `"abc"`}, modified, true},
+		{"weak etag match", "GET", map[string]string{"If-None-Match": `W/"abc"`}, modified, true},
+		{"etag list", "GET", map[string]string{"If-None-Match": `"foo", "abc"`}, modified, true},
+		{"wildcard", "GET", map[string]string{"If-None-Match": `*`}, modified, true},
+		{"etag mismatch", "GET", map[string]string{"If-None-Match": `"foo"`}, modified, false},
+		{"post", "POST", map[string]string{"If-None-Match": `"abc"`}, modified, false},
+		{"not modified since", "GET", map[string]string{"If-Modified-Since": time.Now().UTC().Format(http.TimeFormat)}, modified, true},
+		{"modified since", "GET", map[string]string{"If-Modified-Since": modified.Add(-time.Hour).UTC().Format(http.TimeFormat)}, modified, false},
+		{"unknown modified time", "GET", map[string]string{"If-Modified-Since": time.Now().UTC().Format(http.TimeFormat)}, time.Time{}, false},
+		{"etag takes precedence", "GET", map[string]string{
+			"If-None-Match":     `"foo"`,
+			"If-Modified-Since": time.Now().UTC().Format(http.TimeFormat),
+		}, modified, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := http.NewRequest(tc.method, "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tc.header {
+				r.Header.Set(k, v)
+			}
+			if got := NotModified(r, `"abc"`, tc.modified); got != tc.expect {
+				t.Fatalf("Expected %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
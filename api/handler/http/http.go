@@ -2,12 +2,15 @@
 package http
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"time"
 
 	"github.com/micro/go-micro/v3/api"
 	"github.com/micro/go-micro/v3/api/handler"
@@ -43,7 +46,55 @@ func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.NewSingleHostReverseProxy(rp).ServeHTTP(w, r)
+	proxy := httputil.NewSingleHostReverseProxy(rp)
+	if r.Method == "GET" && h.options.ETag != handler.NoETag {
+		proxy.ModifyResponse = h.conditional(r)
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// conditional sets the ETag of the responses the service didn't set one for and
+// answers the conditional request with a 304 if the response wasn't modified
+func (h *httpHandler) conditional(r *http.Request) func(*http.Response) error {
+	return func(rsp *http.Response) error {
+		if rsp.StatusCode != http.StatusOK {
+			return nil
+		}
+
+		etag := rsp.Header.Get("ETag")
+
+		// only buffer responses of a known size, streams are passed through
+		if len(etag) == 0 && rsp.ContentLength >= 0 && rsp.ContentLength <= h.options.MaxRecvSize {
+			b, err := ioutil.ReadAll(rsp.Body)
+			rsp.Body.Close()
+			if err != nil {
+				return err
+			}
+			rsp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+			etag = h.options.ETag.Generate(b)
+			rsp.Header.Set("ETag", etag)
+		}
+
+		var modified time.Time
+		if lm := rsp.Header.Get("Last-Modified"); len(lm) > 0 {
+			modified, _ = http.ParseTime(lm)
+		}
+
+		if !handler.NotModified(r, etag, modified) {
+			return nil
+		}
+
+		rsp.Body.Close()
+		rsp.Body = http.NoBody
+		rsp.ContentLength = 0
+		rsp.StatusCode = http.StatusNotModified
+		rsp.Status = "304 Not Modified"
+		rsp.Header.Del("Content-Length")
+		rsp.Header.Del("Content-Type")
+
+		return nil
+	}
 }
 
 // getService returns the service for this request from the selector
@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/micro/go-micro/v3/api"
 	"github.com/micro/go-micro/v3/api/handler"
 	"github.com/micro/go-micro/v3/api/resolver"
 	"github.com/micro/go-micro/v3/api/resolver/vpath"
@@ -125,3 +127,78 @@ func TestHttpHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestHttpHandlerETag(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	modified := time.Now().Add(-time.Hour).UTC()
+
+	m := http.NewServeMux()
+	m.HandleFunc("/etag", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`you got served`))
+	})
+	m.HandleFunc("/modified", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"upstream"`)
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		w.Write([]byte(`you got served`))
+	})
+	go http.Serve(l, m)
+
+	s := &api.Service{
+		Name: "go.micro.api.test",
+		Services: []*registry.Service{{
+			Name:  "go.micro.api.test",
+			Nodes: []*registry.Node{{Id: "test-1", Address: l.Addr().String()}},
+		}},
+	}
+	h := WithService(s)
+
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/etag", nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || len(etag) == 0 || w.Body.String() != "you got served" {
+		t.Fatalf("Expected a 200 with an ETag, got %d %q %s", w.Code, etag, w.Body.String())
+	}
+
+	w = serve("/etag", http.Header{"If-None-Match": {`"other", ` + etag}})
+	if w.Code != http.StatusNotModified || w.Body.Len() > 0 || w.Header().Get("ETag") != etag {
+		t.Fatalf("Expected a 304 without a body, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = serve("/etag", http.Header{"If-None-Match": {`"other"`}})
+	if w.Code != 200 {
+		t.Fatalf("Expected a 200 for another ETag, got %d", w.Code)
+	}
+
+	// the ETag and last modified time of the service are used
+	w = serve("/modified", http.Header{"If-None-Match": {`W/"upstream"`}})
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("Expected a 304 for the ETag of the service, got %d", w.Code)
+	}
+
+	w = serve("/modified", http.Header{"If-Modified-Since": {time.Now().UTC().Format(http.TimeFormat)}})
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("Expected a 304 for an unmodified response, got %d", w.Code)
+	}
+
+	w = serve("/modified", http.Header{"If-Modified-Since": {modified.Add(-time.Hour).Format(http.TimeFormat)}})
+	if w.Code != 200 {
+		t.Fatalf("Expected a 200 for a modified response, got %d", w.Code)
+	}
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+
 	"github.com/micro/go-micro/v3/api/router"
 	"github.com/micro/go-micro/v3/client"
 	"github.com/micro/go-micro/v3/client/grpc"
@@ -15,13 +17,23 @@ type Options struct {
 	Namespace   string
 	Router      router.Router
 	Client      client.Client
+	// ETag generated for the responses to GET requests
+	ETag ETag
+	// Validate the request bodies against the schemas of the endpoints
+	Validate bool
+
+	// Other options for implementations of the interface
+	// can be stored in a context
+	Context context.Context
 }
 
 type Option func(o *Options)
 
 // NewOptions fills in the blanks
 func NewOptions(opts ...Option) Options {
-	var options Options
+	options := Options{
+		Context: context.Background(),
+	}
 	for _, o := range opts {
 		o(&options)
 	}
@@ -68,3 +80,18 @@ func WithMaxRecvSize(size int64) Option {
 		o.MaxRecvSize = size
 	}
 }
+
+// WithETag sets the ETag generated for the responses to GET requests, strong by default
+func WithETag(e ETag) Option {
+	return func(o *Options) {
+		o.ETag = e
+	}
+}
+
+// WithValidation validates the request bodies against the schemas the endpoints advertise
+// in the registry, malformed requests are rejected with a 400 before reaching the service
+func WithValidation() Option {
+	return func(o *Options) {
+		o.Validate = true
+	}
+}
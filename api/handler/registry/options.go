@@ -0,0 +1,33 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/api/handler"
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/registry"
+)
+
+type registryKey struct{}
+type authKey struct{}
+
+func setOption(k, v interface{}) handler.Option {
+	return func(o *handler.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// Registry sets the registry the services are listed from, the registry of the
+// router is used by default
+func Registry(r registry.Registry) handler.Option {
+	return setOption(registryKey{}, r)
+}
+
+// Auth gates the catalog, the account of the bearer token must be allowed
+// access to the Resource by the rules. The catalog isn't served without it.
+func Auth(a auth.Auth) handler.Option {
+	return setOption(authKey{}, a)
+}
@@ -0,0 +1,213 @@
+// Package registry provides a handler serving the catalog of the services in the registry.
+// GET /registry lists the services and GET /registry/{service} returns the versions of a
+// service with their nodes and endpoints. The catalog is only served to the accounts allowed
+// access, for the domain of their issuer unless they've the admin scope and set another
+// domain by the domain query parameter.
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/micro/go-micro/v3/api/handler"
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/registry"
+)
+
+const (
+	Handler = "registry"
+)
+
+var (
+	// Path the catalog is served on
+	Path = "/registry"
+	// Resource the accounts are verified against
+	Resource = &auth.Resource{Type: "api", Name: "registry", Endpoint: "*"}
+	// AdminScope allows an account to list the services of any domain
+	AdminScope = "admin"
+)
+
+type catalog struct {
+	opts     handler.Options
+	registry registry.Registry
+	auth     auth.Auth
+}
+
+func (c *catalog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		writeError(w, errors.MethodNotAllowed("go.micro.api", "%s not allowed", r.Method))
+		return
+	}
+
+	acc, err := c.verify(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	reg := c.registry
+	if reg == nil && c.opts.Router != nil {
+		reg = c.opts.Router.Options().Registry
+	}
+	if reg == nil {
+		writeError(w, errors.InternalServerError("go.micro.api", "no registry"))
+		return
+	}
+
+	domain := r.URL.Query().Get("domain")
+	if len(domain) == 0 {
+		domain = issuer(acc)
+	}
+	if domain != issuer(acc) && !hasScope(acc, AdminScope) {
+		writeError(w, errors.Forbidden("go.micro.api", "Forbidden request to the registry of %s by %s", domain, acc.ID))
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, Path), "/")
+	if len(name) == 0 {
+		services, err := reg.ListServices(registry.ListDomain(domain))
+		if err != nil {
+			writeError(w, errors.InternalServerError("go.micro.api", err.Error()))
+			return
+		}
+		writeJSON(w, http.StatusOK, catalogServices(reg, services, domain))
+		return
+	}
+
+	services, err := reg.GetService(name, registry.GetDomain(domain))
+	if err == registry.ErrNotFound {
+		writeError(w, errors.NotFound("go.micro.api", "service %s not found", name))
+		return
+	} else if err != nil {
+		writeError(w, errors.InternalServerError("go.micro.api", err.Error()))
+		return
+	}
+
+	sortServices(services)
+	writeJSON(w, http.StatusOK, services)
+}
+
+// verify the account of the bearer token has access to the catalog
+func (c *catalog) verify(r *http.Request) (*auth.Account, error) {
+	// the catalog would expose the services of every domain
+	if c.auth == nil {
+		return nil, errors.InternalServerError("go.micro.api", "no auth")
+	}
+
+	var acc *auth.Account
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, auth.BearerScheme) {
+		acc, _ = c.auth.Inspect(strings.TrimPrefix(header, auth.BearerScheme))
+	}
+
+	if err := c.auth.Verify(acc, Resource); err != nil {
+		if acc == nil {
+			return nil, errors.Unauthorized("go.micro.api", "Unauthorized request to the registry")
+		}
+		return nil, errors.Forbidden("go.micro.api", "Forbidden request to the registry by %s", acc.ID)
+	}
+	if acc == nil {
+		return nil, errors.Unauthorized("go.micro.api", "Unauthorized request to the registry")
+	}
+
+	return acc, nil
+}
+
+// issuer returns the domain of the account
+func issuer(acc *auth.Account) string {
+	if len(acc.Issuer) == 0 {
+		return registry.DefaultDomain
+	}
+	return acc.Issuer
+}
+
+func hasScope(acc *auth.Account, scope string) bool {
+	for _, s := range acc.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *catalog) String() string {
+	return Handler
+}
+
+// catalogServices returns the versions of the listed services with their nodes and
+// endpoints, the registries which list services without them are queried for each
+func catalogServices(reg registry.Registry, services []*registry.Service, domain string) []*registry.Service {
+	names := make(map[string]bool)
+	var list []*registry.Service
+
+	for _, srv := range services {
+		if len(srv.Nodes) > 0 || len(srv.Endpoints) > 0 {
+			list = append(list, srv)
+			continue
+		}
+		if names[srv.Name] {
+			continue
+		}
+		names[srv.Name] = true
+
+		versions, err := reg.GetService(srv.Name, registry.GetDomain(domain))
+		if err != nil {
+			list = append(list, srv)
+			continue
+		}
+		list = append(list, versions...)
+	}
+
+	sortServices(list)
+	return list
+}
+
+func sortServices(services []*registry.Service) {
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Name != services[j].Name {
+			return services[i].Name < services[j].Name
+		}
+		return services[i].Version < services[j].Version
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, errors.InternalServerError("go.micro.api", err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(b)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	ce := errors.FromError(err)
+	if ce.Code == 0 {
+		ce.Code = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(ce.Code))
+	w.Write([]byte(ce.Error()))
+}
+
+// NewHandler returns a handler serving the catalog of the services in the registry
+func NewHandler(opts ...handler.Option) handler.Handler {
+	options := handler.NewOptions(opts...)
+
+	c := &catalog{
+		opts: options,
+	}
+
+	if r, ok := options.Context.Value(registryKey{}).(registry.Registry); ok {
+		c.registry = r
+	}
+	if a, ok := options.Context.Value(authKey{}).(auth.Auth); ok {
+		c.auth = a
+	}
+
+	return c
+}
@@ -0,0 +1,147 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/auth/noop"
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/registry/memory"
+)
+
+// testAuth only allows the admin and tenant accounts access to the registry, the
+// admin account has the admin scope and the tenant account is issued by the tenant
+type testAuth struct {
+	auth.Auth
+}
+
+func (a *testAuth) Inspect(token string) (*auth.Account, error) {
+	switch token {
+	case "invalid":
+		return nil, errors.New("invalid token")
+	case "admin":
+		return &auth.Account{ID: token, Issuer: registry.DefaultDomain, Scopes: []string{AdminScope}}, nil
+	case "tenant":
+		return &auth.Account{ID: token, Issuer: "tenant"}, nil
+	}
+	return &auth.Account{ID: token}, nil
+}
+
+func (a *testAuth) Verify(acc *auth.Account, res *auth.Resource, opts ...auth.VerifyOption) error {
+	if acc == nil || (acc.ID != "admin" && acc.ID != "tenant") || res.Name != "registry" {
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+func TestCatalog(t *testing.T) {
+	reg := memory.NewRegistry()
+	for _, srv := range []*registry.Service{
+		{
+			Name:      "foo",
+			Version:   "v2",
+			Nodes:     []*registry.Node{{Id: "foo-2", Address: "10.0.0.2:8080"}},
+			Endpoints: []*registry.Endpoint{{Name: "Foo.Call", Metadata: map[string]string{"method": "POST"}}},
+		},
+		{
+			Name:    "foo",
+			Version: "v1",
+			Nodes:   []*registry.Node{{Id: "foo-1", Address: "10.0.0.1:8080"}},
+		},
+		{
+			Name:    "bar",
+			Version: "latest",
+			Nodes:   []*registry.Node{{Id: "bar-1", Address: "10.0.0.3:8080"}},
+		},
+	} {
+		if err := reg.Register(srv); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get := func(h http.Handler, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if len(token) > 0 {
+			req.Header.Set("Authorization", auth.BearerScheme+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	// the catalog isn't served without auth
+	if w := get(NewHandler(Registry(reg)), "/registry", ""); w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500, got %d", w.Code)
+	}
+
+	h := NewHandler(Registry(reg), Auth(&testAuth{noop.NewAuth()}))
+
+	// the catalog lists every version with its nodes and endpoints
+	w := get(h, "/registry", "admin")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var services []*registry.Service
+	if err := json.Unmarshal(w.Body.Bytes(), &services); err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 3 {
+		t.Fatalf("Expected 3 services, got %d", len(services))
+	}
+	for i, expected := range []string{"bar latest", "foo v1", "foo v2"} {
+		if got := services[i].Name + " " + services[i].Version; got != expected {
+			t.Fatalf("Expected service %d to be %s, got %s", i, expected, got)
+		}
+		if len(services[i].Nodes) != 1 {
+			t.Fatalf("Expected the nodes of %s", expected)
+		}
+	}
+	if len(services[2].Endpoints) != 1 || services[2].Endpoints[0].Metadata["method"] != "POST" {
+		t.Fatalf("Expected the endpoints of foo v2, got %+v", services[2].Endpoints)
+	}
+
+	// a single service
+	w = get(h, "/registry/bar", "admin")
+	if err := json.Unmarshal(w.Body.Bytes(), &services); err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || services[0].Name != "bar" {
+		t.Fatalf("Expected the bar service, got %s", w.Body.String())
+	}
+
+	if w = get(h, "/registry/baz", "admin"); w.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404, got %d", w.Code)
+	}
+
+	// the catalog requires an account allowed access
+	for token, code := range map[string]int{
+		"":        http.StatusUnauthorized,
+		"invalid": http.StatusUnauthorized,
+		"user":    http.StatusForbidden,
+		"admin":   http.StatusOK,
+		"tenant":  http.StatusOK,
+	} {
+		if w = get(h, "/registry", token); w.Code != code {
+			t.Fatalf("Expected %d for token %q, got %d", code, token, w.Code)
+		}
+	}
+
+	// the accounts without the admin scope only list the services of their issuer
+	w = get(h, "/registry", "tenant")
+	if err := json.Unmarshal(w.Body.Bytes(), &services); err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 0 {
+		t.Fatalf("Expected no services in the tenant domain, got %s", w.Body.String())
+	}
+	if w = get(h, "/registry?domain="+registry.DefaultDomain, "tenant"); w.Code != http.StatusForbidden {
+		t.Fatalf("Expected 403, got %d", w.Code)
+	}
+	if w = get(h, "/registry?domain=tenant", "admin"); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+}
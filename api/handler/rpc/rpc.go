@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	jsonpatch "github.com/evanphx/json-patch/v5"
 	"github.com/micro/go-micro/v3/api"
@@ -16,12 +17,15 @@ import (
 	"github.com/micro/go-micro/v3/codec"
 	"github.com/micro/go-micro/v3/codec/jsonrpc"
 	"github.com/micro/go-micro/v3/codec/protorpc"
+	"github.com/micro/go-micro/v3/debug/report"
 	"github.com/micro/go-micro/v3/errors"
 	"github.com/micro/go-micro/v3/logger"
 	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/selector"
 	"github.com/micro/go-micro/v3/util/ctx"
 	"github.com/micro/go-micro/v3/util/qson"
 	"github.com/micro/go-micro/v3/util/router"
+	"github.com/micro/go-micro/v3/util/schema"
 	"github.com/oxtoacart/bpool"
 )
 
@@ -106,14 +110,23 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// set merged context to request
 	*r = *r.Clone(cx)
-	// if stream we currently only support json
-	if isStream(r, service) {
-		serveWebsocket(cx, w, r, service, c)
-		return
+	// stream the responses over websockets or as chunked json when the endpoint streams
+	if isStream(service) {
+		switch {
+		case isWebSocket(r):
+			serveWebsocket(cx, w, r, service, c)
+			return
+		case !hasCodec(ct, protoCodecs):
+			serveChunked(cx, w, r, service, c)
+			return
+		}
 	}
 
-	// create custom router
-	callOpt := client.WithRouter(router.New(service.Services))
+	// create custom router and weight the selection by the nodes weight
+	callOpts := []client.CallOption{
+		client.WithRouter(router.New(service.Services)),
+		client.WithSelectOptions(selector.Weights(router.Weights(service.Services))),
+	}
 
 	// walk the standard call path
 	// get payload
@@ -145,7 +158,7 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		)
 
 		// make the call
-		if err := c.Call(cx, req, response, callOpt); err != nil {
+		if err := c.Call(cx, req, response, callOpts...); err != nil {
 			writeError(w, r, err)
 			return
 		}
@@ -163,6 +176,14 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			ct = "application/json"
 		}
 
+		// reject malformed bodies before they reach the service
+		if h.opts.Validate && r.Method != "GET" {
+			if err := schema.Validate(requestSchema(service), br); err != nil {
+				writeError(w, r, errors.BadRequest("go.micro.api", err.Error()))
+				return
+			}
+		}
+
 		// default to trying json
 		var request json.RawMessage
 		// if the extracted payload isn't empty lets use it
@@ -180,7 +201,7 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			client.WithContentType(ct),
 		)
 		// make the call
-		if err := c.Call(cx, req, &response, callOpt); err != nil {
+		if err := c.Call(cx, req, &response, callOpts...); err != nil {
 			writeError(w, r, err)
 			return
 		}
@@ -193,6 +214,17 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// answer conditional requests from the ETag of the response
+	if r.Method == "GET" && len(rsp) > 0 {
+		if etag := h.opts.ETag.Generate(rsp); len(etag) > 0 {
+			if handler.NotModified(r, etag, time.Time{}) {
+				handler.WriteNotModified(w, etag)
+				return
+			}
+			w.Header().Set("ETag", etag)
+		}
+	}
+
 	// write the response
 	writeResponse(w, r, rsp)
 }
@@ -201,6 +233,21 @@ func (rh *rpcHandler) String() string {
 	return "rpc"
 }
 
+// requestSchema returns the schema of the request the endpoint advertises in the registry
+func requestSchema(service *api.Service) string {
+	for _, srv := range service.Services {
+		for _, e := range srv.Endpoints {
+			if e.Name != service.Endpoint.Name || e.Metadata == nil {
+				continue
+			}
+			if s := e.Metadata[schema.RequestMetadata]; len(s) > 0 {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
 func hasCodec(ct string, codecs []string) bool {
 	for _, codec := range codecs {
 		if ct == codec {
@@ -431,6 +478,11 @@ func writeError(w http.ResponseWriter, r *http.Request, err error) {
 		w.WriteHeader(int(ce.Code))
 	}
 
+	// report the internal errors along with the request
+	if ce.Code == http.StatusInternalServerError {
+		report.Error(ctx.FromRequest(r), "go.micro.api", r.URL.Path, ce)
+	}
+
 	// response content type
 	w.Header().Set("Content-Type", "application/json")
 
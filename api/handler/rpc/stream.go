@@ -16,6 +16,7 @@ import (
 	"github.com/micro/go-micro/v3/client"
 	raw "github.com/micro/go-micro/v3/codec/bytes"
 	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/selector"
 	"github.com/micro/go-micro/v3/util/router"
 )
 
@@ -110,11 +111,14 @@ func serveWebsocket(ctx context.Context, w http.ResponseWriter, r *http.Request,
 		client.StreamingRequest(),
 	)
 
-	// create custom router
-	callOpt := client.WithRouter(router.New(service.Services))
+	// create custom router and weight the selection by the nodes weight
+	callOpts := []client.CallOption{
+		client.WithRouter(router.New(service.Services)),
+		client.WithSelectOptions(selector.Weights(router.Weights(service.Services))),
+	}
 
 	// create a new stream
-	stream, err := c.Stream(ctx, req, callOpt)
+	stream, err := c.Stream(ctx, req, callOpts...)
 	if err != nil {
 		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 			logger.Error(err)
@@ -173,6 +177,117 @@ func serveWebsocket(ctx context.Context, w http.ResponseWriter, r *http.Request,
 	}
 }
 
+// serveChunked streams the responses of the rpc back over chunked http as they're received,
+// each is flushed as a line of NDJSON when the client accepts application/x-ndjson and as an
+// element of a JSON array otherwise. An error once the response has started is set in the
+// Micro-Error trailer.
+func serveChunked(ctx context.Context, w http.ResponseWriter, r *http.Request, service *api.Service, c client.Client) {
+	payload, err := requestPayload(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// the request is always sent as a server streaming endpoint waits for it
+	if len(payload) == 0 {
+		payload = []byte(`{}`)
+	}
+	request := json.RawMessage(payload)
+
+	req := c.NewRequest(
+		service.Name,
+		service.Endpoint.Name,
+		&request,
+		client.WithContentType("application/json"),
+		client.StreamingRequest(),
+	)
+
+	// create custom router and weight the selection by the nodes weight
+	callOpts := []client.CallOption{
+		client.WithRouter(router.New(service.Services)),
+		client.WithSelectOptions(selector.Weights(router.Weights(service.Services))),
+	}
+
+	stream, err := c.Stream(ctx, req, callOpts...)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer stream.Close()
+
+	if err := stream.Send(&request); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	ndjson := strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+
+	var count int
+	var line bytes.Buffer
+
+	for {
+		var buf json.RawMessage
+		err := stream.Recv(&buf)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			// nothing has been written so the error can be returned as usual
+			if count == 0 {
+				writeError(w, r, err)
+				return
+			}
+			w.Header().Set("Micro-Error", err.Error())
+			break
+		}
+
+		if count == 0 {
+			if ndjson {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+			} else {
+				w.Header().Set("Content-Type", "application/json")
+			}
+			w.Header().Set("Trailer", "Micro-Error")
+			w.WriteHeader(http.StatusOK)
+		}
+
+		// a message must be on a single line
+		line.Reset()
+		if err := json.Compact(&line, buf); err != nil {
+			line.Write(buf)
+		}
+
+		switch {
+		case ndjson:
+			line.WriteByte('\n')
+		case count == 0:
+			w.Write([]byte("["))
+		default:
+			w.Write([]byte(","))
+		}
+		count++
+
+		if _, err := w.Write(line.Bytes()); err != nil {
+			// the client has gone away
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	switch {
+	case count > 0 && !ndjson:
+		w.Write([]byte("]"))
+	case count == 0 && ndjson:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	case count == 0:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}
+}
+
 // writeLoop
 func writeLoop(rw io.ReadWriter, stream client.Stream) {
 	// close stream when done
@@ -221,11 +336,7 @@ func writeLoop(rw io.ReadWriter, stream client.Stream) {
 	}
 }
 
-func isStream(r *http.Request, srv *api.Service) bool {
-	// check if it's a web socket
-	if !isWebSocket(r) {
-		return false
-	}
+func isStream(srv *api.Service) bool {
 	// check if the endpoint supports streaming
 	for _, service := range srv.Services {
 		for _, ep := range service.Endpoints {
@@ -0,0 +1,135 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v3/api"
+	"github.com/micro/go-micro/v3/api/handler"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/server"
+	"github.com/micro/go-micro/v3/util/test"
+)
+
+type ExportRequest struct {
+	Count int `json:"count"`
+	Fail  bool `json:"fail"`
+}
+
+type ExportRecord struct {
+	Id int `json:"id"`
+}
+
+type Exporter struct{}
+
+func (e *Exporter) Export(ctx context.Context, stream server.Stream) error {
+	var req ExportRequest
+	if err := stream.Recv(&req); err != nil {
+		return err
+	}
+	for i := 0; i < req.Count; i++ {
+		if err := stream.Send(&ExportRecord{Id: i}); err != nil {
+			return err
+		}
+	}
+	if req.Fail {
+		return errors.InternalServerError("test.export", "export failed")
+	}
+	return nil
+}
+
+func TestServeChunked(t *testing.T) {
+	env := test.NewEnv()
+	defer env.Stop()
+
+	if _, err := env.Run("test.export", &Exporter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	services, err := env.Registry.GetService("test.export")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := WithService(&api.Service{
+		Name:     "test.export",
+		Endpoint: &api.Endpoint{Name: "Exporter.Export"},
+		Services: services,
+	}, handler.WithClient(env.NewClient()))
+
+	serve := func(body, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/export", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		if len(accept) > 0 {
+			r.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("JSON array", func(t *testing.T) {
+		w := serve(`{"count": 3}`, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var records []*ExportRecord
+		if err := json.Unmarshal(w.Body.Bytes(), &records); err != nil {
+			t.Fatalf("Expected a JSON array, got %s: %v", w.Body.String(), err)
+		}
+		if len(records) != 3 || records[2].Id != 2 {
+			t.Fatalf("Expected 3 records, got %s", w.Body.String())
+		}
+		if !w.Flushed {
+			t.Fatal("Expected the records to be flushed")
+		}
+	})
+
+	t.Run("Empty array", func(t *testing.T) {
+		w := serve(``, "")
+		if w.Code != http.StatusOK || w.Body.String() != "[]" {
+			t.Fatalf("Expected an empty array, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		w := serve(`{"count": 3}`, "application/x-ndjson")
+		if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Fatalf("Expected content type application/x-ndjson, got %s", ct)
+		}
+		var lines int
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var rec ExportRecord
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil || rec.Id != lines {
+				t.Fatalf("Expected record %d on line %d, got %s", lines, lines, scanner.Text())
+			}
+			lines++
+		}
+		if lines != 3 {
+			t.Fatalf("Expected 3 lines, got %d", lines)
+		}
+	})
+
+	t.Run("Error before the first record", func(t *testing.T) {
+		w := serve(`{"fail": true}`, "")
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("Expected status 500, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Error after the first record", func(t *testing.T) {
+		w := serve(`{"count": 2, "fail": true}`, "application/x-ndjson")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if e := w.Result().Trailer.Get("Micro-Error"); !strings.Contains(e, "export failed") {
+			t.Fatalf("Expected the error in the trailer, got %q", e)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+// Package namespace is a resolver which uses the issuer of the authenticated account to determine
+// the domain to route to, so a tenant can only reach the services registered in their namespace.
+// It offloads the endpoint resolution to a child resolver which is provided in New.
+package namespace
+
+import (
+	"net/http"
+
+	"github.com/micro/go-micro/v3/api/resolver"
+	"github.com/micro/go-micro/v3/auth"
+)
+
+func NewResolver(parent resolver.Resolver, opts ...resolver.Option) resolver.Resolver {
+	options := resolver.NewOptions(opts...)
+	return &Resolver{options, parent}
+}
+
+type Resolver struct {
+	opts resolver.Options
+	resolver.Resolver
+}
+
+func (r *Resolver) Resolve(req *http.Request, opts ...resolver.ResolveOption) (*resolver.Endpoint, error) {
+	if ns := r.Namespace(req); len(ns) > 0 {
+		opts = append(opts, resolver.Domain(ns))
+	}
+
+	return r.Resolver.Resolve(req, opts...)
+}
+
+// Namespace returns the issuer of the account in the request context, the
+// account is set by the auth wrapper once the token has been verified
+func (r *Resolver) Namespace(req *http.Request) string {
+	acc, ok := auth.AccountFromContext(req.Context())
+	if !ok {
+		return ""
+	}
+	return acc.Issuer
+}
+
+func (r *Resolver) String() string {
+	return "namespace"
+}
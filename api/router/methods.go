@@ -0,0 +1,98 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+)
+
+var (
+	// Methods are the request methods probed when building the Allow header of a path
+	Methods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+)
+
+// MatchMethod reports whether a request method is served by an endpoint with the given
+// methods. HEAD requests are served by GET endpoints when HEAD isn't listed explicitly.
+func MatchMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method || (method == "HEAD" && m == "GET") {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed returns the methods of the endpoints which match the path of the request,
+// HEAD is included when GET is and OPTIONS whenever any method matches
+func Allowed(r Router, req *http.Request) []string {
+	var allowed []string
+
+	for _, m := range Methods {
+		// endpoint matching may rewrite the request so use a copy
+		preq := req.Clone(req.Context())
+		preq.Method = m
+		if _, err := r.Endpoint(preq); err != nil {
+			continue
+		}
+		allowed = append(allowed, m)
+		if m == "GET" {
+			allowed = append(allowed, "HEAD")
+		}
+	}
+
+	if len(allowed) > 0 {
+		allowed = append(allowed, "OPTIONS")
+	}
+
+	return allowed
+}
+
+// Handler wraps a http handler, answering OPTIONS requests with the methods allowed by the
+// router and serving HEAD requests through the GET handling without writing the body
+func Handler(r Router, h http.Handler) http.Handler {
+	return &methodHandler{r, h}
+}
+
+type methodHandler struct {
+	router  Router
+	handler http.Handler
+}
+
+func (m *methodHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		allowed := Allowed(m.router, r)
+		if len(allowed) == 0 {
+			// no endpoint, leave it to the handler
+			m.handler.ServeHTTP(w, r)
+			return
+		}
+		allow := strings.Join(allowed, ", ")
+		w.Header().Set("Allow", allow)
+		// answer the preflight of browsers with the same methods
+		if len(r.Header.Get("Access-Control-Request-Method")) > 0 {
+			w.Header().Set("Access-Control-Allow-Methods", allow)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "HEAD":
+		req := r.Clone(r.Context())
+		req.Method = "GET"
+		m.handler.ServeHTTP(&headWriter{w}, req)
+	default:
+		m.handler.ServeHTTP(w, r)
+	}
+}
+
+// headWriter discards the body of the response to a HEAD request
+type headWriter struct {
+	http.ResponseWriter
+}
+
+func (h *headWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (h *headWriter) Flush() {
+	if f, ok := h.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
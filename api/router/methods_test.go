@@ -0,0 +1,72 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/micro/go-micro/v3/api"
+	"github.com/micro/go-micro/v3/api/router"
+	rstatic "github.com/micro/go-micro/v3/api/router/static"
+	"github.com/micro/go-micro/v3/registry"
+	rmemory "github.com/micro/go-micro/v3/registry/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterMethods(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	assert.NoError(t, reg.Register(&registry.Service{
+		Name:  "foo",
+		Nodes: []*registry.Node{{Id: "foo-1", Address: "127.0.0.1:8080"}},
+	}))
+
+	r := rstatic.NewRouter(router.WithRegistry(reg))
+	defer r.Close()
+
+	for _, ep := range []*api.Endpoint{
+		{Name: "foo.Foo.Read", Method: []string{"GET"}, Path: []string{"/foo/{id}"}, Handler: "rpc"},
+		{Name: "foo.Foo.Update", Method: []string{"PUT", "PATCH"}, Path: []string{"/foo/{id}"}, Handler: "rpc"},
+		{Name: "foo.Foo.Create", Method: []string{"POST"}, Path: []string{"/foo"}, Handler: "rpc"},
+	} {
+		assert.NoError(t, r.Register(ep))
+	}
+
+	// HEAD is served by the GET endpoint
+	ep, err := r.Endpoint(httptest.NewRequest("HEAD", "/foo/1", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "Foo.Read", ep.Endpoint.Name)
+
+	assert.Equal(t, []string{"GET", "HEAD", "PUT", "PATCH", "OPTIONS"}, router.Allowed(r, httptest.NewRequest("OPTIONS", "/foo/1", nil)))
+	assert.Equal(t, []string{"POST", "OPTIONS"}, router.Allowed(r, httptest.NewRequest("OPTIONS", "/foo", nil)))
+	assert.Empty(t, router.Allowed(r, httptest.NewRequest("OPTIONS", "/bar", nil)))
+
+	var served string
+	h := router.Handler(r, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		served = req.Method
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1"}`))
+	}))
+
+	// OPTIONS is answered from the routes without reaching the handler
+	req := httptest.NewRequest("OPTIONS", "/foo/1", nil)
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET, HEAD, PUT, PATCH, OPTIONS", w.Header().Get("Allow"))
+	assert.Equal(t, "GET, HEAD, PUT, PATCH, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Empty(t, served)
+
+	// HEAD goes through the GET handling without the body
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("HEAD", "/foo/1", nil))
+	assert.Equal(t, "GET", served)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Body.String())
+
+	// unknown paths fall through to the handler
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("OPTIONS", "/bar", nil))
+	assert.Equal(t, "OPTIONS", served)
+}
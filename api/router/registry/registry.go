@@ -21,6 +21,8 @@ import (
 
 // endpoint struct, that holds compiled pcre
 type endpoint struct {
+	// the domain the service is registered in
+	domain   string
 	hostregs []*regexp.Regexp
 	pathregs []util.Pattern
 	pcreregs []*regexp.Regexp
@@ -35,6 +37,7 @@ type registryRouter struct {
 	rc cache.Cache
 
 	sync.RWMutex
+	// endpoints keyed by domain:service.endpoint
 	eps map[string]*api.Service
 	// compiled regexp for host and path
 	ceps map[string]*endpoint
@@ -54,7 +57,7 @@ func (r *registryRouter) refresh() {
 	var attempts int
 
 	for {
-		services, err := r.opts.Registry.ListServices()
+		services, err := r.opts.Registry.ListServices(registry.ListDomain(registry.WildcardDomain))
 		if err != nil {
 			attempts++
 			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
@@ -68,14 +71,15 @@ func (r *registryRouter) refresh() {
 
 		// for each service, get service and store endpoints
 		for _, s := range services {
-			service, err := r.rc.GetService(s.Name)
+			domain := getDomain(s)
+			service, err := r.rc.GetService(s.Name, registry.GetDomain(domain))
 			if err != nil {
 				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 					logger.Errorf("unable to get service: %v", err)
 				}
 				continue
 			}
-			r.store(service)
+			r.store(domain, service)
 		}
 
 		// refresh list in 10 minutes... cruft
@@ -96,7 +100,8 @@ func (r *registryRouter) process(res *registry.Result) {
 	}
 
 	// get entry from cache
-	service, err := r.rc.GetService(res.Service.Name)
+	domain := getDomain(res.Service)
+	service, err := r.rc.GetService(res.Service.Name, registry.GetDomain(domain))
 	if err != nil {
 		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 			logger.Errorf("unable to get %v service: %v", res.Service.Name, err)
@@ -105,11 +110,43 @@ func (r *registryRouter) process(res *registry.Result) {
 	}
 
 	// update our local endpoints
-	r.store(service)
+	r.store(domain, service)
 }
 
-// store local endpoint cache
-func (r *registryRouter) store(services []*registry.Service) {
+// getDomain returns the domain a service was registered in
+func getDomain(srv *registry.Service) string {
+	if srv.Metadata != nil && len(srv.Metadata["domain"]) > 0 {
+		return srv.Metadata["domain"]
+	} else if len(srv.Nodes) > 0 && srv.Nodes[0].Metadata != nil && len(srv.Nodes[0].Metadata["domain"]) > 0 {
+		return srv.Nodes[0].Metadata["domain"]
+	}
+	return registry.DefaultDomain
+}
+
+// available returns a copy of the services without the nodes which are down or draining
+func available(services []*registry.Service) []*registry.Service {
+	avail := make([]*registry.Service, 0, len(services))
+
+	for _, service := range services {
+		srv := *service
+		srv.Nodes = make([]*registry.Node, 0, len(service.Nodes))
+		for _, node := range service.Nodes {
+			if node.Health == registry.HealthDown || node.Health == registry.HealthDraining {
+				continue
+			}
+			srv.Nodes = append(srv.Nodes, node)
+		}
+		avail = append(avail, &srv)
+	}
+
+	return avail
+}
+
+// store local endpoint cache of the services in the domain
+func (r *registryRouter) store(domain string, services []*registry.Service) {
+	// exclude nodes which aren't accepting requests e.g during a deploy
+	services = available(services)
+
 	// endpoints
 	eps := map[string]*api.Service{}
 
@@ -123,8 +160,8 @@ func (r *registryRouter) store(services []*registry.Service) {
 
 		// map per endpoint
 		for _, sep := range service.Endpoints {
-			// create a key service:endpoint_name
-			key := fmt.Sprintf("%s.%s", service.Name, sep.Name)
+			// create a key domain:service.endpoint_name
+			key := fmt.Sprintf("%s:%s.%s", domain, service.Name, sep.Name)
 			// decode endpoint
 			end := api.Decode(sep.Metadata)
 			// no endpoint or no name
@@ -160,7 +197,7 @@ func (r *registryRouter) store(services []*registry.Service) {
 	// delete any existing eps for services we know
 	for key, service := range r.eps {
 		// skip what we don't care about
-		if !names[service.Name] {
+		if !names[service.Name] || r.ceps[key].domain != domain {
 			continue
 		}
 
@@ -172,7 +209,7 @@ func (r *registryRouter) store(services []*registry.Service) {
 	// now set the eps we have
 	for name, ep := range eps {
 		r.eps[name] = ep
-		cep := &endpoint{}
+		cep := &endpoint{domain: domain}
 
 		for _, h := range ep.Endpoint.Host {
 			if h == "" || h == "*" {
@@ -234,7 +271,7 @@ func (r *registryRouter) watch() {
 		}
 
 		// watch for changes
-		w, err := r.opts.Registry.Watch()
+		w, err := r.opts.Registry.Watch(registry.WatchDomain(registry.WildcardDomain))
 		if err != nil {
 			attempts++
 			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
@@ -301,6 +338,12 @@ func (r *registryRouter) Endpoint(req *http.Request) (*api.Service, error) {
 		return nil, errors.New("router closed")
 	}
 
+	// only match the endpoints in the domain the request resolves to
+	domain := registry.DefaultDomain
+	if rp, err := r.opts.Resolver.Resolve(req); err == nil && len(rp.Domain) > 0 {
+		domain = rp.Domain
+	}
+
 	r.RLock()
 	defer r.RUnlock()
 
@@ -314,19 +357,13 @@ func (r *registryRouter) Endpoint(req *http.Request) (*api.Service, error) {
 	// TODO: weighted matching
 	for n, e := range r.eps {
 		cep, ok := r.ceps[n]
-		if !ok {
+		if !ok || cep.domain != domain {
 			continue
 		}
 		ep := e.Endpoint
 		var mMatch, hMatch, pMatch bool
 		// 1. try method
-		for _, m := range ep.Method {
-			if m == req.Method {
-				mMatch = true
-				break
-			}
-		}
-		if !mMatch {
+		if mMatch = router.MatchMethod(ep.Method, req.Method); !mMatch {
 			continue
 		}
 		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
@@ -441,6 +478,7 @@ func (r *registryRouter) Route(req *http.Request) (*api.Service, error) {
 	if err != nil {
 		return nil, err
 	}
+	services = available(services)
 
 	// only use endpoint matching when the meta handler is set aka api.Default
 	switch r.opts.Handler {
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"net/http/httptest"
 	"testing"
 
 	"github.com/micro/go-micro/v3/registry"
@@ -9,7 +10,7 @@ import (
 
 func TestStoreRegex(t *testing.T) {
 	router := newRouter()
-	router.store([]*registry.Service{
+	router.store(registry.DefaultDomain, []*registry.Service{
 		{
 			Name:    "Foobar",
 			Version: "latest",
@@ -30,5 +31,41 @@ func TestStoreRegex(t *testing.T) {
 	},
 	)
 
-	assert.Len(t, router.ceps["Foobar.foo"].pcreregs, 1)
+	assert.Len(t, router.ceps["micro:Foobar.foo"].pcreregs, 1)
+}
+
+func TestStoreDomain(t *testing.T) {
+	router := newRouter()
+
+	service := func(path string) []*registry.Service {
+		return []*registry.Service{
+			{
+				Name: "Foobar",
+				Endpoints: []*registry.Endpoint{
+					{
+						Name: "foo",
+						Metadata: map[string]string{
+							"endpoint": "FooEndpoint",
+							"method":   "POST",
+							"path":     path,
+							"handler":  "rpc",
+						},
+					},
+				},
+			},
+		}
+	}
+
+	router.store(registry.DefaultDomain, service("^/foo/$"))
+	router.store("tenant", service("^/bar/$"))
+
+	assert.Len(t, router.eps, 2)
+
+	// the request resolves to the default domain so can't reach the tenant endpoint
+	_, err := router.Endpoint(httptest.NewRequest("POST", "/bar/", nil))
+	assert.Error(t, err)
+
+	ep, err := router.Endpoint(httptest.NewRequest("POST", "/foo/", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "Foobar", ep.Name)
 }
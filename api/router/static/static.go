@@ -240,13 +240,7 @@ func (r *staticRouter) endpoint(req *http.Request) (*endpoint, error) {
 		var mMatch, hMatch, pMatch bool
 
 		// 1. try method
-		for _, m := range ep.apiep.Method {
-			if m == req.Method {
-				mMatch = true
-				break
-			}
-		}
-		if !mMatch {
+		if mMatch = router.MatchMethod(ep.apiep.Method, req.Method); !mMatch {
 			continue
 		}
 		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
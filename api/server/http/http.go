@@ -12,6 +12,7 @@ import (
 	"github.com/micro/go-micro/v3/api/server"
 	"github.com/micro/go-micro/v3/api/server/cors"
 	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/util/proxyproto"
 )
 
 type httpServer struct {
@@ -63,6 +64,12 @@ func (s *httpServer) Handle(path string, handler http.Handler) {
 		handler = cors.CombinedCORSHandler(handler)
 	}
 
+	// callers can't debug the requests, see debugHandler
+	handler = debugHandler(handler)
+
+	// report the metrics of the requests
+	handler = metricsHandler(handler)
+
 	// wrap with logger
 	handler = handlers.CombinedLoggingHandler(os.Stdout, handler)
 
@@ -73,11 +80,13 @@ func (s *httpServer) Start() error {
 	var l net.Listener
 	var err error
 
-	if s.opts.EnableACME && s.opts.ACMEProvider != nil {
+	if s.opts.EnableProxyProtocol {
+		l, err = s.listenProxy()
+	} else if s.opts.EnableACME && s.opts.ACMEProvider != nil {
 		// should we check the address to make sure its using :443?
 		l, err = s.opts.ACMEProvider.Listen(s.opts.ACMEHosts...)
-	} else if s.opts.EnableTLS && s.opts.TLSConfig != nil {
-		l, err = tls.Listen("tcp", s.address, s.opts.TLSConfig)
+	} else if config := s.tlsConfig(); config != nil {
+		l, err = tls.Listen("tcp", s.address, config)
 	} else {
 		// otherwise plain listen
 		l, err = net.Listen("tcp", s.address)
@@ -109,6 +118,64 @@ func (s *httpServer) Start() error {
 	return nil
 }
 
+// listenProxy listens for connections with the PROXY protocol header,
+// the header precedes the TLS handshake
+func (s *httpServer) listenProxy() (net.Listener, error) {
+	l, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err = proxyproto.NewListener(l, proxyproto.Trusted(s.opts.TrustedProxies...))
+	if err != nil {
+		return nil, err
+	}
+
+	if s.opts.EnableACME && s.opts.ACMEProvider != nil {
+		config, err := s.opts.ACMEProvider.TLSConfig(s.opts.ACMEHosts...)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+		return tls.NewListener(l, config), nil
+	}
+
+	if config := s.tlsConfig(); config != nil {
+		return tls.NewListener(l, config), nil
+	}
+
+	return l, nil
+}
+
+// tlsConfig returns the config to listen with if TLS is enabled, the certificate
+// is selected by server name when GetCertificate is set
+func (s *httpServer) tlsConfig() *tls.Config {
+	if !s.opts.EnableTLS {
+		return nil
+	}
+	if s.opts.GetCertificate == nil {
+		return s.opts.TLSConfig
+	}
+
+	config := &tls.Config{}
+	if s.opts.TLSConfig != nil {
+		config = s.opts.TLSConfig.Clone()
+	}
+	config.GetCertificate = s.opts.GetCertificate
+
+	return config
+}
+
+// debugHandler removes the debug header from the requests so callers can't turn on
+// debug logging in every service the request reaches. Wrappers can still debug the
+// requests of trusted accounts with logger.NewDebugContext.
+func debugHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del(logger.DebugHeader)
+		h.ServeHTTP(w, r)
+	})
+}
+
 func (s *httpServer) Stop() error {
 	ch := make(chan error)
 	s.exit <- ch
@@ -1,10 +1,18 @@
 package http
 
 import (
+	"bufio"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/api/server"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/metrics"
+	"github.com/micro/go-micro/v3/util/ctx"
 )
 
 func TestHTTPServer(t *testing.T) {
@@ -39,3 +47,123 @@ func TestHTTPServer(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestHTTPServerProxyProtocol(t *testing.T) {
+	s := NewServer("localhost:0", server.EnableProxyProtocol(true), server.TrustedProxies("127.0.0.1", "::1"))
+
+	s.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.RemoteAddr)
+	}))
+
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", s.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	fmt.Fprint(conn, "PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\n")
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: localhost\r\nConnection: close\r\n\r\n")
+
+	rsp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rsp.Body.Close()
+
+	b, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "192.168.0.1:56324" {
+		t.Fatalf("Expected the remote address of the client, got %s", string(b))
+	}
+}
+
+type testReporter struct {
+	id   string
+	tags metrics.Tags
+}
+
+func (r *testReporter) Count(id string, value int64, tags metrics.Tags) error {
+	return nil
+}
+
+func (r *testReporter) Gauge(id string, value float64, tags metrics.Tags) error {
+	return nil
+}
+
+func (r *testReporter) Timing(id string, value time.Duration, tags metrics.Tags) error {
+	r.id, r.tags = id, tags
+	return nil
+}
+
+func TestHTTPServerMetrics(t *testing.T) {
+	r := new(testReporter)
+	defer func(d metrics.Reporter) { metrics.DefaultReporter = d }(metrics.DefaultReporter)
+	metrics.DefaultReporter = r
+
+	s := NewServer("localhost:0")
+	s.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "failed", http.StatusBadGateway)
+	}))
+
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	rsp, err := http.Get(fmt.Sprintf("http://%s/", s.Address()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp.Body.Close()
+
+	if r.id != MetricName {
+		t.Fatalf("Expected %s got %s", MetricName, r.id)
+	}
+	expected := metrics.Tags{"method": "GET", "status": "502", "result": "failure"}
+	for k, v := range expected {
+		if r.tags[k] != v {
+			t.Fatalf("Expected %s to be %s got %s", k, v, r.tags[k])
+		}
+	}
+}
+
+func TestHTTPServerDebugHeader(t *testing.T) {
+	s := NewServer("localhost:0")
+
+	s.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, logger.IsDebug(ctx.FromRequest(r)))
+	}))
+
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("http://%s/", s.Address()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(logger.DebugHeader, "true")
+
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rsp.Body.Close()
+
+	b, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "false" {
+		t.Fatal("Expected the debug header of the caller to be removed")
+	}
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/micro/go-micro/v3/metrics"
+)
+
+var (
+	// MetricName is the timing metric of the requests served tagged with the method,
+	// status and result. The count of the timing is the rate of requests and errors.
+	MetricName = "api.request"
+)
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Hijack the connection e.g for websockets
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return hj.Hijack()
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// metricsHandler reports the requests served to the default metrics reporter,
+// server errors are failures
+func metricsHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, r)
+
+		result := "success"
+		if sw.status >= http.StatusInternalServerError {
+			result = "failure"
+		}
+
+		metrics.DefaultReporter.Timing(MetricName, time.Since(started), metrics.Tags{
+			"method": r.Method,
+			"status": strconv.Itoa(sw.status),
+			"result": result,
+		})
+	})
+}
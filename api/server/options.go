@@ -19,6 +19,14 @@ type Options struct {
 	TLSConfig    *tls.Config
 	Resolver     resolver.Resolver
 	Wrappers     []Wrapper
+	// EnableProxyProtocol accepts the PROXY protocol header from
+	// load balancers so the address of the client is preserved
+	EnableProxyProtocol bool
+	// TrustedProxies are the addresses or CIDRs the header is accepted from,
+	// at least one is required to enable the proxy protocol
+	TrustedProxies []string
+	// GetCertificate selects the certificate by the server name the client indicates
+	GetCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
 }
 
 type Wrapper func(h http.Handler) http.Handler
@@ -65,8 +73,30 @@ func TLSConfig(t *tls.Config) Option {
 	}
 }
 
+// GetCertificate selects the certificate presented by the server name the client
+// indicates e.g. the domain mapped to a namespace, see sni.Selector
+func GetCertificate(fn func(*tls.ClientHelloInfo) (*tls.Certificate, error)) Option {
+	return func(o *Options) {
+		o.GetCertificate = fn
+	}
+}
+
 func Resolver(r resolver.Resolver) Option {
 	return func(o *Options) {
 		o.Resolver = r
 	}
 }
+
+func EnableProxyProtocol(b bool) Option {
+	return func(o *Options) {
+		o.EnableProxyProtocol = b
+	}
+}
+
+// TrustedProxies sets the addresses or CIDRs of the load balancers the PROXY
+// protocol header is accepted from, they're required by EnableProxyProtocol
+func TrustedProxies(addrs ...string) Option {
+	return func(o *Options) {
+		o.TrustedProxies = addrs
+	}
+}
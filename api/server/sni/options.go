@@ -0,0 +1,70 @@
+package sni
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+var (
+	// DefaultPrefix of the keys of the certificates in the store, followed by their host
+	DefaultPrefix = "certificate/"
+	// DefaultInterval at which the certificates are polled by stores which can't watch
+	DefaultInterval = time.Minute
+)
+
+// Options of the certificate selection
+type Options struct {
+	// Store the certificates are loaded from
+	Store store.Store
+	// Prefix of the keys of the certificates
+	Prefix string
+	// Interval at which the certificates are polled by stores which can't watch
+	Interval time.Duration
+	// Default certificate presented when none matches the server name
+	Default *tls.Certificate
+}
+
+// Option sets an option of the certificate selection
+type Option func(o *Options)
+
+// Store sets the store the certificates are loaded from
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// Prefix sets the prefix of the keys of the certificates
+func Prefix(p string) Option {
+	return func(o *Options) {
+		o.Prefix = p
+	}
+}
+
+// Interval sets the interval at which the certificates are polled by stores which can't watch
+func Interval(d time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = d
+	}
+}
+
+// Default sets the certificate presented when none matches the server name
+func Default(c *tls.Certificate) Option {
+	return func(o *Options) {
+		o.Default = c
+	}
+}
+
+func newOptions(opts ...Option) Options {
+	options := Options{
+		Store:    store.DefaultStore,
+		Prefix:   DefaultPrefix,
+		Interval: DefaultInterval,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
@@ -0,0 +1,255 @@
+// Package sni selects the certificate presented to clients by the server name they indicate,
+// so the namespaces mapped to domains e.g tenant1.example.com and tenant2.example.com each
+// present their own certificate. The certificates are loaded from the store, keyed by host,
+// and reloaded when they're rotated.
+package sni
+
+import (
+	"crypto/tls"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/util/backoff"
+)
+
+var (
+	// ErrNoCertificate is returned when no certificate matches the server name
+	ErrNoCertificate = errors.New("no certificate for server name")
+)
+
+// Selector selects the certificates by server name
+type Selector struct {
+	opts Options
+
+	once sync.Once
+	exit chan bool
+
+	sync.RWMutex
+	watcher store.Watcher
+	// certificates keyed by host, wildcards are keyed as *.example.com
+	certs map[string]*tls.Certificate
+}
+
+// NewSelector loads the certificates from the store and watches them for changes
+func NewSelector(opts ...Option) (*Selector, error) {
+	s := &Selector{
+		opts:  newOptions(opts...),
+		exit:  make(chan bool),
+		certs: make(map[string]*tls.Certificate),
+	}
+
+	// watch before loading so no rotation is missed in between
+	w, err := s.newWatcher()
+	if err != nil {
+		return nil, err
+	}
+	s.watcher = w
+
+	if err := s.Reload(); err != nil {
+		w.Stop()
+		return nil, err
+	}
+
+	go s.watch(w)
+
+	return s, nil
+}
+
+func (s *Selector) newWatcher() (store.Watcher, error) {
+	return store.Watch(s.opts.Store,
+		store.WatchPrefix(s.opts.Prefix),
+		store.WatchInterval(s.opts.Interval),
+	)
+}
+
+// Reload reads all the certificates from the store
+func (s *Selector) Reload() error {
+	recs, err := s.opts.Store.Read(s.opts.Prefix, store.ReadPrefix())
+	if err != nil && err != store.ErrNotFound {
+		return err
+	}
+
+	certs := make(map[string]*tls.Certificate, len(recs))
+	for _, r := range recs {
+		cert, err := parse(r)
+		if err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error loading certificate %s: %v", r.Key, err)
+			}
+			continue
+		}
+		certs[s.host(r.Key)] = cert
+	}
+
+	s.Lock()
+	s.certs = certs
+	s.Unlock()
+
+	return nil
+}
+
+// watch applies the changes to the certificates until the selector is stopped, the
+// watcher is re-created with a backoff when it fails
+func (s *Selector) watch(w store.Watcher) {
+	for {
+		ev, err := w.Next()
+		if err == store.ErrWatcherStopped {
+			return
+		} else if err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error watching certificates: %v", err)
+			}
+			w.Stop()
+			if w = s.rewatch(); w == nil {
+				return
+			}
+			continue
+		}
+
+		host := s.host(ev.Record.Key)
+
+		switch ev.Type {
+		case store.Create, store.Update:
+			cert, err := parse(ev.Record)
+			if err != nil {
+				// keep presenting the previous certificate
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("Error loading certificate %s: %v", ev.Record.Key, err)
+				}
+				continue
+			}
+			s.Lock()
+			s.certs[host] = cert
+			s.Unlock()
+
+			if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+				logger.Infof("Loaded certificate for %s", host)
+			}
+		case store.Delete, store.Expired:
+			s.Lock()
+			delete(s.certs, host)
+			s.Unlock()
+		}
+	}
+}
+
+// rewatch re-creates the watcher, reloading the certificates changed in between.
+// It returns nil when the selector is stopped.
+func (s *Selector) rewatch() store.Watcher {
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-s.exit:
+			return nil
+		case <-time.After(backoff.Do(attempt)):
+		}
+
+		w, err := s.newWatcher()
+		if err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error watching certificates: %v", err)
+			}
+			continue
+		}
+
+		s.Lock()
+		select {
+		case <-s.exit:
+			s.Unlock()
+			w.Stop()
+			return nil
+		default:
+		}
+		s.watcher = w
+		s.Unlock()
+
+		if err := s.Reload(); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error loading certificates: %v", err)
+			}
+		}
+
+		return w
+	}
+}
+
+// GetCertificate returns the certificate of the server name, a certificate of a
+// wildcard matching it or the default certificate. It's used as the GetCertificate
+// func of a tls.Config.
+func (s *Selector) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := strings.TrimSuffix(strings.ToLower(hello.ServerName), ".")
+
+	s.RLock()
+	defer s.RUnlock()
+
+	if cert, ok := s.certs[name]; ok {
+		return cert, nil
+	}
+
+	// a wildcard only matches a single label e.g *.example.com matches foo.example.com
+	if i := strings.Index(name, "."); i > 0 {
+		if cert, ok := s.certs["*"+name[i:]]; ok {
+			return cert, nil
+		}
+	}
+
+	if s.opts.Default != nil {
+		return s.opts.Default, nil
+	}
+
+	return nil, ErrNoCertificate
+}
+
+// TLSConfig returns a tls config presenting the certificates selected by server name
+func (s *Selector) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: s.GetCertificate,
+	}
+}
+
+// Stop watching the certificates
+func (s *Selector) Stop() {
+	s.once.Do(func() {
+		s.Lock()
+		close(s.exit)
+		s.watcher.Stop()
+		s.Unlock()
+	})
+}
+
+func (s *Selector) host(key string) string {
+	return strings.ToLower(strings.TrimPrefix(key, s.opts.Prefix))
+}
+
+// Write stores the PEM encoded certificate chain and key of the host, a wildcard host
+// e.g *.example.com matches a single label. The selectors load it on their next change.
+func Write(host string, certPEM, keyPEM []byte, opts ...Option) error {
+	options := newOptions(opts...)
+
+	// validate the pair so a broken rotation isn't stored
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return err
+	}
+
+	value := make([]byte, 0, len(certPEM)+len(keyPEM)+1)
+	value = append(value, certPEM...)
+	value = append(value, '\n')
+	value = append(value, keyPEM...)
+
+	return options.Store.Write(&store.Record{
+		Key:   options.Prefix + strings.ToLower(host),
+		Value: value,
+	})
+}
+
+// parse the certificate chain and key PEM encoded in the value of the record
+func parse(r *store.Record) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(r.Value, r.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
@@ -0,0 +1,217 @@
+package sni
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/store/memory"
+)
+
+// generate a self signed certificate and key for the host
+func generate(t *testing.T, host string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kb, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kb})
+}
+
+func serial(t *testing.T, s *Selector, name string) *big.Int {
+	cert, err := s.GetCertificate(&tls.ClientHelloInfo{ServerName: name})
+	if err != nil {
+		return nil
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.SerialNumber
+}
+
+func TestSelector(t *testing.T) {
+	st := memory.NewStore()
+	opts := []Option{Store(st), Interval(time.Millisecond * 10)}
+
+	hosts := []string{"tenant1.example.com", "tenant2.example.com", "*.example.org"}
+	serials := make(map[string]*big.Int)
+
+	for _, host := range hosts {
+		c, k := generate(t, host)
+		if err := Write(host, c, k, opts...); err != nil {
+			t.Fatal(err)
+		}
+		leaf, _ := pem.Decode(c)
+		cert, _ := x509.ParseCertificate(leaf.Bytes)
+		serials[host] = cert.SerialNumber
+	}
+
+	// a broken pair isn't stored
+	if err := Write("broken.example.com", []byte("cert"), []byte("key"), opts...); err == nil {
+		t.Fatal("Expected an error writing an invalid certificate")
+	}
+
+	s, err := NewSelector(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	testCases := []struct {
+		name   string
+		expect *big.Int
+	}{
+		{"tenant1.example.com", serials["tenant1.example.com"]},
+		{"TENANT2.example.com.", serials["tenant2.example.com"]},
+		{"foo.example.org", serials["*.example.org"]},
+		{"foo.bar.example.org", nil},
+		{"tenant3.example.com", nil},
+	}
+
+	for _, tc := range testCases {
+		if got := serial(t, s, tc.name); (got == nil) != (tc.expect == nil) || (got != nil && got.Cmp(tc.expect) != 0) {
+			t.Fatalf("Expected serial %v for %s, got %v", tc.expect, tc.name, got)
+		}
+	}
+
+	// the certificate is presented in the handshake
+	l, err := tls.Listen("tcp", "127.0.0.1:0", s.TLSConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			c.(*tls.Conn).Handshake()
+			c.Close()
+		}
+	}()
+
+	conn, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{ServerName: "tenant2.example.com", InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer := conn.ConnectionState().PeerCertificates[0]
+	conn.Close()
+	if peer.SerialNumber.Cmp(serials["tenant2.example.com"]) != 0 {
+		t.Fatalf("Expected the certificate of tenant2 to be presented, got %s", peer.Subject.CommonName)
+	}
+
+	// rotated certificates are reloaded
+	c, k := generate(t, "tenant1.example.com")
+	if err := Write("tenant1.example.com", c, k, opts...); err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := pem.Decode(c)
+	rotated, _ := x509.ParseCertificate(leaf.Bytes)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if got := serial(t, s, "tenant1.example.com"); got != nil && got.Cmp(rotated.SerialNumber) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the rotated certificate to be reloaded")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// deleted certificates are no longer presented
+	if err := st.Delete(DefaultPrefix + "tenant2.example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline = time.Now().Add(time.Second)
+	for serial(t, s, "tenant2.example.com") != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the deleted certificate to be unloaded")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+// failingStore fails the first watcher it returns, the next ones poll the store
+type failingStore struct {
+	store.Store
+	failed bool
+}
+
+type failingWatcher struct{}
+
+func (failingWatcher) Next() (*store.Event, error) {
+	return nil, errors.New("watch failed")
+}
+
+func (failingWatcher) Stop() {}
+
+func (f *failingStore) Watch(opts ...store.WatchOption) (store.Watcher, error) {
+	if !f.failed {
+		f.failed = true
+		return failingWatcher{}, nil
+	}
+	return store.Poll(f.Store, opts...)
+}
+
+func TestSelectorRewatch(t *testing.T) {
+	st := &failingStore{Store: memory.NewStore()}
+	opts := []Option{Store(st), Interval(time.Millisecond * 10)}
+
+	s, err := NewSelector(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	// written after the watcher failed
+	c, k := generate(t, "tenant1.example.com")
+	if err := Write("tenant1.example.com", c, k, opts...); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second * 2)
+	for serial(t, s, "tenant1.example.com") == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the certificate to be loaded once watching again")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
@@ -0,0 +1,52 @@
+package token
+
+var (
+	// DefaultCookie is the name of the cookie holding the token
+	DefaultCookie = "micro-token"
+	// DefaultQuery is the query parameter holding the token of websocket handshakes
+	DefaultQuery = "token"
+	// DefaultSources are the sources of the token in order of precedence, only the
+	// Authorization header. The cookie and query sources have to be set explicitly.
+	DefaultSources = []Source{
+		Header(),
+	}
+)
+
+// Options of the token extraction
+type Options struct {
+	// Sources of the token in order of precedence
+	Sources []Source
+	// Secure disables the sources which can leak the token, e.g. into access logs,
+	// or have it sent by the browser on cross site requests
+	Secure bool
+}
+
+// Option sets an option of the token extraction
+type Option func(o *Options)
+
+// Sources sets the sources of the token, the first to yield a token wins
+func Sources(s ...Source) Option {
+	return func(o *Options) {
+		o.Sources = s
+	}
+}
+
+// Secure disables the insecure sources, the cookie and the query string
+func Secure(b bool) Option {
+	return func(o *Options) {
+		o.Secure = b
+	}
+}
+
+// NewOptions returns the options with the defaults applied
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		Sources: DefaultSources,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return options
+}
@@ -0,0 +1,159 @@
+// Package token extracts the auth token of api requests from a chain of sources
+package token
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/micro/go-micro/v3/auth"
+)
+
+// Source of the token of a request
+type Source interface {
+	// Token returns the token of the request if the source holds one
+	Token(r *http.Request) (string, bool)
+	// Insecure sources can leak the token, e.g. into access logs
+	Insecure() bool
+	// String returns the name of the source
+	String() string
+}
+
+// Header is the source of bearer tokens in the Authorization header
+func Header() Source {
+	return &headerSource{name: "Authorization", scheme: auth.BearerScheme}
+}
+
+// CustomHeader is the source of tokens set as the value of the named header
+func CustomHeader(name string) Source {
+	return &headerSource{name: name}
+}
+
+// Cookie is the source of tokens held in the named cookie. It's insecure as the browser
+// sends the cookie on cross site requests, the handlers need protecting against CSRF.
+func Cookie(name string) Source {
+	return &cookieSource{name}
+}
+
+// Query is the source of tokens in the named query parameter of websocket handshakes,
+// browsers can't set headers on them. It's insecure as urls end up in logs.
+func Query(name string) Source {
+	return &querySource{name}
+}
+
+// Extract returns the token of the request and its source, the sources are tried in
+// order of precedence and the insecure ones are skipped when the options are secure
+func Extract(r *http.Request, opts ...Option) (string, Source, bool) {
+	options := NewOptions(opts...)
+
+	for _, s := range options.Sources {
+		if options.Secure && s.Insecure() {
+			continue
+		}
+		if tok, ok := s.Token(r); ok {
+			return tok, s, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// Handler wraps a http handler, setting the token of the request as the bearer token of
+// the Authorization header which is passed to the services called
+func Handler(h http.Handler, opts ...Option) http.Handler {
+	return &tokenHandler{
+		handler: h,
+		opts:    opts,
+	}
+}
+
+type tokenHandler struct {
+	handler http.Handler
+	opts    []Option
+}
+
+func (t *tokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tok, src, ok := Extract(r, t.opts...)
+	if !ok {
+		// drop a bearer token the chain doesn't accept
+		if strings.HasPrefix(r.Header.Get("Authorization"), auth.BearerScheme) {
+			r.Header.Del("Authorization")
+		}
+		t.handler.ServeHTTP(w, r)
+		return
+	}
+
+	r.Header.Set("Authorization", auth.BearerScheme+tok)
+
+	// don't pass the token on in the url
+	if qs, ok := src.(*querySource); ok {
+		q := r.URL.Query()
+		q.Del(qs.name)
+		r.URL.RawQuery = q.Encode()
+	}
+
+	t.handler.ServeHTTP(w, r)
+}
+
+type headerSource struct {
+	name   string
+	scheme string
+}
+
+func (h *headerSource) Token(r *http.Request) (string, bool) {
+	v := r.Header.Get(h.name)
+	if len(h.scheme) > 0 {
+		if !strings.HasPrefix(v, h.scheme) {
+			return "", false
+		}
+		v = strings.TrimPrefix(v, h.scheme)
+	}
+	return v, len(v) > 0
+}
+
+func (h *headerSource) Insecure() bool {
+	return false
+}
+
+func (h *headerSource) String() string {
+	return "header:" + h.name
+}
+
+type cookieSource struct {
+	name string
+}
+
+func (c *cookieSource) Token(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(c.name)
+	if err != nil || len(cookie.Value) == 0 {
+		return "", false
+	}
+	return cookie.Value, true
+}
+
+func (c *cookieSource) Insecure() bool {
+	return true
+}
+
+func (c *cookieSource) String() string {
+	return "cookie:" + c.name
+}
+
+type querySource struct {
+	name string
+}
+
+func (q *querySource) Token(r *http.Request) (string, bool) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return "", false
+	}
+	v := r.URL.Query().Get(q.name)
+	return v, len(v) > 0
+}
+
+func (q *querySource) Insecure() bool {
+	return true
+}
+
+func (q *querySource) String() string {
+	return "query:" + q.name
+}
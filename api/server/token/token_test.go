@@ -0,0 +1,82 @@
+package token
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtract(t *testing.T) {
+	req := httptest.NewRequest("GET", "/foo?token=query", nil)
+	req.Header.Set("Authorization", "Bearer header")
+	req.Header.Set("X-Token", "custom")
+	req.AddCookie(&http.Cookie{Name: DefaultCookie, Value: "cookie"})
+
+	tok, src, ok := Extract(req)
+	assert.True(t, ok)
+	assert.Equal(t, "header", tok)
+	assert.Equal(t, "header:Authorization", src.String())
+
+	// precedence follows the order of the sources
+	tok, _, ok = Extract(req, Sources(CustomHeader("X-Token"), Header()))
+	assert.True(t, ok)
+	assert.Equal(t, "custom", tok)
+
+	tok, _, ok = Extract(req, Sources(Cookie(DefaultCookie), Header()))
+	assert.True(t, ok)
+	assert.Equal(t, "cookie", tok)
+
+	// the query is only a source for websocket handshakes
+	_, _, ok = Extract(req, Sources(Query(DefaultQuery)))
+	assert.False(t, ok)
+
+	req.Header.Set("Upgrade", "websocket")
+	tok, _, ok = Extract(req, Sources(Query(DefaultQuery)))
+	assert.True(t, ok)
+	assert.Equal(t, "query", tok)
+
+	// and is skipped when insecure sources are disabled
+	_, _, ok = Extract(req, Sources(Query(DefaultQuery)), Secure(true))
+	assert.False(t, ok)
+	_, _, ok = Extract(req, Sources(Cookie(DefaultCookie)), Secure(true))
+	assert.False(t, ok)
+
+	// the cookie and the query aren't sources by default
+	req = httptest.NewRequest("GET", "/foo?token=query", nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.AddCookie(&http.Cookie{Name: DefaultCookie, Value: "cookie"})
+	_, _, ok = Extract(req)
+	assert.False(t, ok)
+
+	// only bearer tokens are read from the Authorization header
+	req = httptest.NewRequest("GET", "/foo", nil)
+	req.Header.Set("Authorization", "Basic Zm9vOmJhcg==")
+	_, _, ok = Extract(req)
+	assert.False(t, ok)
+}
+
+func TestHandler(t *testing.T) {
+	var got *http.Request
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}), Sources(Header(), Query(DefaultQuery)))
+
+	// the query token is moved to the Authorization header
+	req := httptest.NewRequest("GET", "/stream?token=secret&foo=bar", nil)
+	req.Header.Set("Upgrade", "websocket")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "Bearer secret", got.Header.Get("Authorization"))
+	assert.Equal(t, "foo=bar", got.URL.RawQuery)
+
+	// bearer tokens from disabled sources are dropped
+	h = Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}), Sources(Cookie(DefaultCookie)))
+
+	req = httptest.NewRequest("GET", "/foo", nil)
+	req.Header.Set("Authorization", "Bearer header")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Empty(t, got.Header.Get("Authorization"))
+}
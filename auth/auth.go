@@ -57,6 +57,8 @@ type Account struct {
 	Issuer string `json:"issuer"`
 	// Any other associated metadata
 	Metadata map[string]string `json:"metadata"`
+	// Claims are typed attributes of the account
+	Claims Claims `json:"claims,omitempty"`
 	// Scopes the account has access to
 	Scopes []string `json:"scopes"`
 	// Secret for the account, e.g. the password
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var (
+	// ErrNoClaim is returned when the account doesn't have the claim
+	ErrNoClaim = errors.New("claim not found")
+)
+
+// Claims are typed attributes of an account which travel with it in its tokens, e.g. the plan
+// tier or the role in an organisation. Each claim is serialized as JSON so any type can be used,
+// types implementing json.Marshaler and json.Unmarshaler control their own encoding.
+type Claims map[string]json.RawMessage
+
+// Set encodes the value of the claim
+func (c Claims) Set(name string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c[name] = b
+	return nil
+}
+
+// Get decodes the claim into v, which must be a pointer to the type it was set with
+func (c Claims) Get(name string, v interface{}) error {
+	b, ok := c[name]
+	if !ok {
+		return ErrNoClaim
+	}
+	return json.Unmarshal(b, v)
+}
+
+// SetClaim sets a claim of the account
+func (a *Account) SetClaim(name string, v interface{}) error {
+	if a.Claims == nil {
+		a.Claims = make(Claims)
+	}
+	return a.Claims.Set(name, v)
+}
+
+// Claim decodes a claim of the account into v e.g.
+//
+//	var plan Plan
+//	err := acc.Claim("plan", &plan)
+func (a *Account) Claim(name string, v interface{}) error {
+	return a.Claims.Get(name, v)
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type orgRole struct {
+	Org   string   `json:"org"`
+	Roles []string `json:"roles"`
+}
+
+func TestClaims(t *testing.T) {
+	acc := &Account{ID: "test"}
+
+	if err := acc.Claim("plan", new(string)); err != ErrNoClaim {
+		t.Fatalf("Expected %v for a missing claim, got %v", ErrNoClaim, err)
+	}
+
+	if err := acc.SetClaim("plan", "enterprise"); err != nil {
+		t.Fatal(err)
+	}
+	if err := acc.SetClaim("role", orgRole{"acme", []string{"admin"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the claims survive the serialization of the account
+	b, err := json.Marshal(acc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got *Account
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	var plan string
+	if err := got.Claim("plan", &plan); err != nil || plan != "enterprise" {
+		t.Fatalf("Expected plan enterprise, got %q (%v)", plan, err)
+	}
+	var role orgRole
+	if err := got.Claim("role", &role); err != nil || role.Org != "acme" || len(role.Roles) != 1 {
+		t.Fatalf("Expected the org role, got %+v (%v)", role, err)
+	}
+
+	// a claim can't be decoded into another type
+	var n int
+	if err := got.Claim("plan", &n); err == nil {
+		t.Fatal("Expected an error decoding the claim into the wrong type")
+	}
+}
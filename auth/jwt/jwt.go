@@ -60,6 +60,7 @@ func (j *jwtAuth) Generate(id string, opts ...auth.GenerateOption) (*auth.Accoun
 		Type:     options.Type,
 		Scopes:   options.Scopes,
 		Metadata: options.Metadata,
+		Claims:   options.Claims,
 		Issuer:   options.Issuer,
 	}
 
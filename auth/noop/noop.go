@@ -45,6 +45,7 @@ func (n *noop) Generate(id string, opts ...auth.GenerateOption) (*auth.Account,
 		ID:       id,
 		Secret:   options.Secret,
 		Metadata: options.Metadata,
+		Claims:   options.Claims,
 		Scopes:   options.Scopes,
 		Issuer:   n.Options().Issuer,
 	}, nil
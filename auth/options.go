@@ -100,6 +100,8 @@ func LoginURL(url string) Option {
 type GenerateOptions struct {
 	// Metadata associated with the account
 	Metadata map[string]string
+	// Claims of the account
+	Claims Claims
 	// Scopes the account has access too
 	Scopes []string
 	// Provider of the account, e.g. oauth
@@ -135,6 +137,13 @@ func WithMetadata(md map[string]string) GenerateOption {
 	}
 }
 
+// WithClaims for the generated account
+func WithClaims(c Claims) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.Claims = c
+	}
+}
+
 // WithProvider for the generated account
 func WithProvider(p string) GenerateOption {
 	return func(o *GenerateOptions) {
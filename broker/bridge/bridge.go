@@ -0,0 +1,159 @@
+// Package bridge replicates the messages of topics from one broker to another, e.g from an
+// on premise nats cluster to a cloud broker during a migration
+package bridge
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/broker"
+	"github.com/micro/go-micro/v3/logger"
+)
+
+var (
+	// Header of the message listing the bridges it passed through
+	Header = "Micro-Bridge"
+
+	// ErrNoBroker is returned when the source or target broker isn't set
+	ErrNoBroker = errors.New("source and target brokers required")
+)
+
+// Bridge subscribes to topics on the source broker and republishes the messages to the target
+type Bridge struct {
+	sync.Mutex
+	opts Options
+	subs []broker.Subscriber
+}
+
+// New returns a bridge, it's started with Start
+func New(opts ...Option) *Bridge {
+	options := Options{
+		Id:      uuid.New().String(),
+		MaxHops: 1,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Bridge{opts: options}
+}
+
+// hops returns the bridges the message passed through
+func hops(m *broker.Message) []string {
+	v, ok := m.Header[Header]
+	if !ok || len(v) == 0 {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+func (b *Bridge) handler(sub string) broker.Handler {
+	return func(m *broker.Message) error {
+		// republish the messages of a wildcard subscription to the topics they were published to
+		topic := sub
+		if t := m.Header[broker.TopicHeader]; broker.IsWildcard(sub) && len(t) > 0 {
+			topic = t
+		}
+
+		// loop prevention
+		passed := hops(m)
+		if len(passed) >= b.opts.MaxHops {
+			return nil
+		}
+		for _, id := range passed {
+			if id == b.opts.Id {
+				return nil
+			}
+		}
+
+		if b.opts.Filter != nil && !b.opts.Filter(topic, m) {
+			return nil
+		}
+
+		// copy the message so the transform doesn't change the one delivered to other subscribers
+		msg := &broker.Message{
+			Header: make(map[string]string, len(m.Header)+1),
+			Body:   m.Body,
+		}
+		for k, v := range m.Header {
+			msg.Header[k] = v
+		}
+
+		target := topic
+		if b.opts.Transform != nil {
+			var err error
+			if target, msg, err = b.opts.Transform(topic, msg); err != nil {
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("Bridge %s failed to transform a message of %s: %v", b.opts.Id, topic, err)
+				}
+				return err
+			}
+			if msg == nil {
+				return nil
+			}
+			if msg.Header == nil {
+				msg.Header = make(map[string]string)
+			}
+		}
+
+		msg.Header[Header] = strings.Join(append(passed, b.opts.Id), ",")
+
+		if err := b.opts.Target.Publish(target, msg); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Bridge %s failed to republish a message of %s to %s: %v", b.opts.Id, topic, target, err)
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+// Start subscribing to the topics, the brokers must be connected
+func (b *Bridge) Start() error {
+	if b.opts.Source == nil || b.opts.Target == nil {
+		return ErrNoBroker
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	if len(b.subs) > 0 {
+		return nil
+	}
+
+	var opts []broker.SubscribeOption
+	if len(b.opts.Queue) > 0 {
+		opts = append(opts, broker.Queue(b.opts.Queue))
+	}
+
+	for _, topic := range b.opts.Topics {
+		sub, err := b.opts.Source.Subscribe(topic, b.handler(topic), opts...)
+		if err != nil {
+			for _, s := range b.subs {
+				s.Unsubscribe()
+			}
+			b.subs = nil
+			return err
+		}
+		b.subs = append(b.subs, sub)
+	}
+
+	return nil
+}
+
+// Stop subscribing to the topics
+func (b *Bridge) Stop() error {
+	b.Lock()
+	defer b.Unlock()
+
+	var err error
+	for _, s := range b.subs {
+		if serr := s.Unsubscribe(); serr != nil {
+			err = serr
+		}
+	}
+	b.subs = nil
+	return err
+}
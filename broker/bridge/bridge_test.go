@@ -0,0 +1,78 @@
+package bridge
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/broker"
+	"github.com/micro/go-micro/v3/broker/memory"
+)
+
+type received struct {
+	sync.Mutex
+	msgs []*broker.Message
+}
+
+func (r *received) handle(m *broker.Message) error {
+	r.Lock()
+	r.msgs = append(r.msgs, m)
+	r.Unlock()
+	return nil
+}
+
+func (r *received) count() int {
+	r.Lock()
+	defer r.Unlock()
+	return len(r.msgs)
+}
+
+func TestBridge(t *testing.T) {
+	a := memory.NewBroker()
+	b := memory.NewBroker()
+	a.Connect()
+	b.Connect()
+
+	// bridges in both directions
+	ab := New(Id("ab"), Source(a), Target(b), Topics("orders"),
+		Filter(func(topic string, m *broker.Message) bool {
+			return m.Header["Internal"] != "true"
+		}),
+		Transform(func(topic string, m *broker.Message) (string, *broker.Message, error) {
+			m.Header["Region"] = "eu"
+			return "eu." + topic, m, nil
+		}),
+	)
+	ba := New(Id("ba"), Source(b), Target(a), Topics("eu.orders"))
+	if err := ab.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ba.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer ab.Stop()
+	defer ba.Stop()
+
+	onA := new(received)
+	onB := new(received)
+	a.Subscribe("orders", onA.handle)
+	b.Subscribe("eu.orders", onB.handle)
+
+	a.Publish("orders", &broker.Message{Header: map[string]string{}, Body: []byte("1")})
+	a.Publish("orders", &broker.Message{Header: map[string]string{"Internal": "true"}, Body: []byte("2")})
+
+	time.Sleep(50 * time.Millisecond)
+
+	if onB.count() != 1 {
+		t.Fatalf("Expected 1 message on the target got %d", onB.count())
+	}
+	m := onB.msgs[0]
+	if string(m.Body) != "1" || m.Header["Region"] != "eu" || m.Header[Header] != "ab" {
+		t.Fatalf("Expected the transformed message got %v", m)
+	}
+
+	// the message isn't echoed back to the source
+	if onA.count() != 2 {
+		t.Fatalf("Expected only the published messages on the source got %d", onA.count())
+	}
+}
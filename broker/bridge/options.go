@@ -0,0 +1,83 @@
+package bridge
+
+import (
+	"github.com/micro/go-micro/v3/broker"
+)
+
+type Options struct {
+	// Id of the bridge recorded in the messages it republishes, a random id if not set
+	Id string
+	// Source broker the messages are subscribed to on
+	Source broker.Broker
+	// Target broker the messages are republished to
+	Target broker.Broker
+	// Topics which are bridged
+	Topics []string
+	// Queue the topics are subscribed to with so replicas of the bridge share the messages
+	Queue string
+	// MaxHops is the number of bridges a message can pass through, the default of
+	// 1 stops messages echoing back between a pair of bridges in opposite directions
+	MaxHops int
+	// Filter returns false for the messages which aren't bridged
+	Filter func(topic string, m *broker.Message) bool
+	// Transform returns the topic and message republished to the target
+	Transform func(topic string, m *broker.Message) (string, *broker.Message, error)
+}
+
+type Option func(o *Options)
+
+// Id of the bridge
+func Id(id string) Option {
+	return func(o *Options) {
+		o.Id = id
+	}
+}
+
+// Source broker the messages are subscribed to on
+func Source(b broker.Broker) Option {
+	return func(o *Options) {
+		o.Source = b
+	}
+}
+
+// Target broker the messages are republished to
+func Target(b broker.Broker) Option {
+	return func(o *Options) {
+		o.Target = b
+	}
+}
+
+// Topics which are bridged
+func Topics(topics ...string) Option {
+	return func(o *Options) {
+		o.Topics = append(o.Topics, topics...)
+	}
+}
+
+// Queue the topics are subscribed to with
+func Queue(q string) Option {
+	return func(o *Options) {
+		o.Queue = q
+	}
+}
+
+// MaxHops sets the number of bridges a message can pass through
+func MaxHops(n int) Option {
+	return func(o *Options) {
+		o.MaxHops = n
+	}
+}
+
+// Filter the messages which are bridged
+func Filter(fn func(topic string, m *broker.Message) bool) Option {
+	return func(o *Options) {
+		o.Filter = fn
+	}
+}
+
+// Transform the messages before they're republished e.g to rename the topic
+func Transform(fn func(topic string, m *broker.Message) (string, *broker.Message, error)) Option {
+	return func(o *Options) {
+		o.Transform = fn
+	}
+}
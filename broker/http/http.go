@@ -296,7 +296,7 @@ func (h *httpBroker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	topic := msg.Header["Micro-Topic"]
+	topic := msg.Header[broker.TopicHeader]
 
 	if len(topic) == 0 {
 		errr := merr.InternalServerError("go.micro.broker", "Topic not found")
@@ -311,11 +311,17 @@ func (h *httpBroker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	var subs []broker.Handler
 
 	h.RLock()
-	for _, subscriber := range h.subscribers[topic] {
-		if id != subscriber.id {
+	for sub, subscribers := range h.subscribers {
+		// emulate the wildcards by matching the topic against each subscription
+		if !broker.Match(sub, topic) {
 			continue
 		}
-		subs = append(subs, subscriber.fn)
+		for _, subscriber := range subscribers {
+			if id != subscriber.id {
+				continue
+			}
+			subs = append(subs, subscriber.fn)
+		}
 	}
 	h.RUnlock()
 
@@ -488,6 +494,10 @@ func (h *httpBroker) Options() broker.Options {
 }
 
 func (h *httpBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	if broker.IsWildcard(topic) {
+		return broker.ErrInvalidTopic
+	}
+
 	// create the message first
 	m := &broker.Message{
 		Header: make(map[string]string),
@@ -498,7 +508,7 @@ func (h *httpBroker) Publish(topic string, msg *broker.Message, opts ...broker.P
 		m.Header[k] = v
 	}
 
-	m.Header["Micro-Topic"] = topic
+	m.Header[broker.TopicHeader] = topic
 
 	// encode the message
 	b, err := h.opts.Codec.Marshal(m)
@@ -551,8 +561,8 @@ func (h *httpBroker) Publish(topic string, msg *broker.Message, opts ...broker.P
 					continue
 				}
 
-				// look for nodes for the topic
-				if node.Metadata["topic"] != topic {
+				// look for nodes subscribed to the topic
+				if !broker.Match(node.Metadata["topic"], topic) {
 					continue
 				}
 
@@ -231,6 +231,50 @@ func TestBroker(t *testing.T) {
 	}
 }
 
+func TestBrokerWildcard(t *testing.T) {
+	m := newTestRegistry()
+	b := NewBroker(broker.Registry(m))
+
+	if err := b.Init(); err != nil {
+		t.Fatalf("Unexpected init error: %v", err)
+	}
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error: %v", err)
+	}
+
+	topics := make(chan string, 2)
+
+	sub, err := b.Subscribe("orders.>", func(m *broker.Message) error {
+		topics <- m.Header[broker.TopicHeader]
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error: %v", err)
+	}
+
+	for _, topic := range []string{"users.created", "orders.eu.created"} {
+		if err := b.Publish(topic, &broker.Message{Body: []byte(`{}`)}); err != nil {
+			t.Fatalf("Unexpected publish error: %v", err)
+		}
+	}
+
+	select {
+	case topic := <-topics:
+		if topic != "orders.eu.created" {
+			t.Fatalf("Expected a message of orders.eu.created, got %s", topic)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Expected a message matching the wildcard")
+	}
+
+	sub.Unsubscribe()
+
+	if err := b.Disconnect(); err != nil {
+		t.Fatalf("Unexpected disconnect error: %v", err)
+	}
+}
+
 func TestConcurrentSubBroker(t *testing.T) {
 	m := newTestRegistry()
 	b := NewBroker(broker.Registry(m))
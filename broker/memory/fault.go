@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"github.com/micro/go-micro/v3/broker"
+	"github.com/micro/go-micro/v3/util/fault"
+)
+
+// SetFaults sets the faults simulated when delivering messages, the messages are delivered
+// in the background after the delay and each subscriber may miss them
+func (m *Broker) SetFaults(f fault.Faults) {
+	m.Lock()
+	m.faults = f
+	m.Unlock()
+}
+
+// Partition splits the views, identified by their ids, into groups which don't receive the
+// messages published by each other until the partition is healed
+func (m *Broker) Partition(groups ...[]string) {
+	m.partition.Split(groups...)
+}
+
+// Heal the partition so every view receives the messages of every other
+func (m *Broker) Heal() {
+	m.partition.Heal()
+}
+
+// View returns the broker as used by the node with the id, when the broker is partitioned
+// it only delivers the messages published by the views the node can reach
+func (m *Broker) View(id string) broker.Broker {
+	return &view{Broker: m, id: id}
+}
+
+// view of the broker from a node
+type view struct {
+	*Broker
+	id string
+}
+
+func (v *view) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	return v.publish(v.id, topic, msg)
+}
+
+func (v *view) Subscribe(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	return v.subscribe(v.id, topic, h, opts...)
+}
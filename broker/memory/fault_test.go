@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/broker"
+	"github.com/micro/go-micro/v3/util/fault"
+)
+
+func TestBrokerFaults(t *testing.T) {
+	b := NewBroker().(*Broker)
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	received := make(chan string, 10)
+	subscribe := func(id string) {
+		_, err := b.View(id).Subscribe("events", func(m *broker.Message) error {
+			received <- id
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	subscribe("a")
+	subscribe("b")
+
+	count := func() int {
+		var n int
+		for {
+			select {
+			case <-received:
+				n++
+			case <-time.After(time.Millisecond * 50):
+				return n
+			}
+		}
+	}
+
+	publish := func(b broker.Broker) {
+		if err := b.Publish("events", &broker.Message{Body: []byte(`{}`)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// partitioned views only receive the messages of their group
+	b.Partition([]string{"a", "c"}, []string{"b"})
+	publish(b.View("c"))
+	if n := count(); n != 1 {
+		t.Fatalf("Expected 1 subscriber to receive the message across the partition, got %d", n)
+	}
+	publish(b)
+	if n := count(); n != 2 {
+		t.Fatalf("Expected the messages from outside the partition to be received by all, got %d", n)
+	}
+
+	b.Heal()
+	publish(b.View("c"))
+	if n := count(); n != 2 {
+		t.Fatalf("Expected both subscribers to receive the message once healed, got %d", n)
+	}
+
+	// dropped messages are never received
+	b.SetFaults(fault.Faults{Drop: 1})
+	publish(b)
+	if n := count(); n != 0 {
+		t.Fatalf("Expected the messages to be dropped, got %d", n)
+	}
+
+	// delayed messages are received later
+	b.SetFaults(fault.Faults{Delay: time.Millisecond * 100})
+	start := time.Now()
+	publish(b)
+	<-received
+	<-received
+	if d := time.Since(start); d < time.Millisecond*100 {
+		t.Fatalf("Expected the message to be delayed, received after %v", d)
+	}
+}
@@ -11,35 +11,42 @@ import (
 	"github.com/google/uuid"
 	"github.com/micro/go-micro/v3/broker"
 	maddr "github.com/micro/go-micro/v3/util/addr"
+	"github.com/micro/go-micro/v3/util/fault"
 	mnet "github.com/micro/go-micro/v3/util/net"
 )
 
-type memoryBroker struct {
+// Broker delivers the messages in memory
+type Broker struct {
 	opts broker.Options
 
 	addr string
 	sync.RWMutex
 	connected   bool
 	Subscribers map[string][]*memorySubscriber
+
+	// faults simulated for tests
+	faults    fault.Faults
+	partition fault.Partition
 }
 
 type memorySubscriber struct {
 	id      string
+	view    string
 	topic   string
 	exit    chan bool
 	handler broker.Handler
 	opts    broker.SubscribeOptions
 }
 
-func (m *memoryBroker) Options() broker.Options {
+func (m *Broker) Options() broker.Options {
 	return m.opts
 }
 
-func (m *memoryBroker) Address() string {
+func (m *Broker) Address() string {
 	return m.addr
 }
 
-func (m *memoryBroker) Connect() error {
+func (m *Broker) Connect() error {
 	m.Lock()
 	defer m.Unlock()
 
@@ -62,7 +69,7 @@ func (m *memoryBroker) Connect() error {
 	return nil
 }
 
-func (m *memoryBroker) Disconnect() error {
+func (m *Broker) Disconnect() error {
 	m.Lock()
 	defer m.Unlock()
 
@@ -75,39 +82,90 @@ func (m *memoryBroker) Disconnect() error {
 	return nil
 }
 
-func (m *memoryBroker) Init(opts ...broker.Option) error {
+func (m *Broker) Init(opts ...broker.Option) error {
 	for _, o := range opts {
 		o(&m.opts)
 	}
 	return nil
 }
 
-func (m *memoryBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+func (m *Broker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	return m.publish("", topic, msg)
+}
+
+// publish the message from the view to the subscribers it reaches
+func (m *Broker) publish(from, topic string, msg *broker.Message) error {
+	if broker.IsWildcard(topic) {
+		return broker.ErrInvalidTopic
+	}
+
 	m.RLock()
 	if !m.connected {
 		m.RUnlock()
 		return errors.New("not connected")
 	}
 
-	subs, ok := m.Subscribers[topic]
+	// emulate the wildcards by matching the topic against each subscription
+	var subs []*memorySubscriber
+	for sub, s := range m.Subscribers {
+		if !broker.Match(sub, topic) {
+			continue
+		}
+		for _, sb := range s {
+			if m.partition.Reachable(from, sb.view) {
+				subs = append(subs, sb)
+			}
+		}
+	}
+	faults := m.faults
 	m.RUnlock()
-	if !ok {
+	if len(subs) == 0 {
 		return nil
 	}
 
-	for _, sub := range subs {
-		if err := sub.handler(msg); err != nil {
-			if eh := sub.opts.ErrorHandler; eh != nil {
-				eh(msg, err)
+	// set the topic on a copy so the message of the caller isn't changed
+	if _, ok := msg.Header[broker.TopicHeader]; !ok {
+		header := make(map[string]string, len(msg.Header)+1)
+		for k, v := range msg.Header {
+			header[k] = v
+		}
+		header[broker.TopicHeader] = topic
+		msg = &broker.Message{Header: header, Body: msg.Body}
+	}
+
+	deliver := func() {
+		for _, sub := range subs {
+			if faults.Dropped() {
+				continue
+			}
+			if err := sub.handler(msg); err != nil {
+				if eh := sub.opts.ErrorHandler; eh != nil {
+					eh(msg, err)
+				}
+				continue
 			}
-			continue
 		}
 	}
 
+	// delayed messages are delivered in the background as by a remote broker
+	if faults.Delay > 0 {
+		go func() {
+			time.Sleep(faults.Delay)
+			deliver()
+		}()
+		return nil
+	}
+
+	deliver()
 	return nil
 }
 
-func (m *memoryBroker) Subscribe(topic string, handler broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+func (m *Broker) Subscribe(topic string, handler broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	return m.subscribe("", topic, handler, opts...)
+}
+
+// subscribe the view to the topic
+func (m *Broker) subscribe(view, topic string, handler broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
 	m.RLock()
 	if !m.connected {
 		m.RUnlock()
@@ -123,6 +181,7 @@ func (m *memoryBroker) Subscribe(topic string, handler broker.Handler, opts ...b
 	sub := &memorySubscriber{
 		exit:    make(chan bool, 1),
 		id:      uuid.New().String(),
+		view:    view,
 		topic:   topic,
 		handler: handler,
 		opts:    options,
@@ -149,7 +208,7 @@ func (m *memoryBroker) Subscribe(topic string, handler broker.Handler, opts ...b
 	return sub, nil
 }
 
-func (m *memoryBroker) String() string {
+func (m *Broker) String() string {
 	return "memory"
 }
 
@@ -176,7 +235,7 @@ func NewBroker(opts ...broker.Option) broker.Broker {
 		o(&options)
 	}
 
-	return &memoryBroker{
+	return &Broker{
 		opts:        options,
 		Subscribers: make(map[string][]*memorySubscriber),
 	}
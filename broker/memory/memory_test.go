@@ -48,3 +48,48 @@ func TestMemoryBroker(t *testing.T) {
 		t.Fatalf("Unexpected connect error %v", err)
 	}
 }
+
+func TestMemoryBrokerWildcard(t *testing.T) {
+	b := NewBroker()
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	received := make(map[string][]string)
+	subscribe := func(topic string) {
+		_, err := b.Subscribe(topic, func(m *broker.Message) error {
+			received[topic] = append(received[topic], m.Header[broker.TopicHeader])
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error subscribing to %s: %v", topic, err)
+		}
+	}
+
+	subscribe("orders.*")
+	subscribe("orders.>")
+	subscribe("orders.*.created")
+
+	for _, topic := range []string{"orders.created", "orders.eu.created", "users.created"} {
+		if err := b.Publish(topic, &broker.Message{Body: []byte(`hello world`)}); err != nil {
+			t.Fatalf("Unexpected error publishing to %s: %v", topic, err)
+		}
+	}
+
+	expect := map[string]string{
+		"orders.*":         "[orders.created]",
+		"orders.>":         "[orders.created orders.eu.created]",
+		"orders.*.created": "[orders.eu.created]",
+	}
+	for topic, topics := range expect {
+		if got := fmt.Sprint(received[topic]); got != topics {
+			t.Errorf("Expected %s to receive %s, got %s", topic, topics, got)
+		}
+	}
+
+	if err := b.Publish("orders.*", &broker.Message{}); err != broker.ErrInvalidTopic {
+		t.Fatalf("Expected %v publishing to a wildcard, got %v", broker.ErrInvalidTopic, err)
+	}
+}
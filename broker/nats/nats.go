@@ -156,6 +156,10 @@ func (n *natsBroker) Publish(topic string, msg *broker.Message, opts ...broker.P
 		return errors.New("not connected")
 	}
 
+	if broker.IsWildcard(topic) {
+		return broker.ErrInvalidTopic
+	}
+
 	b, err := n.opts.Codec.Marshal(msg)
 	if err != nil {
 		return err
@@ -193,6 +197,13 @@ func (n *natsBroker) Subscribe(topic string, handler broker.Handler, opts ...bro
 			}
 			return
 		}
+		// the subject is the topic matching a wildcard subscription
+		if len(m.Header[broker.TopicHeader]) == 0 {
+			if m.Header == nil {
+				m.Header = make(map[string]string)
+			}
+			m.Header[broker.TopicHeader] = msg.Subject
+		}
 		if err := handler(m); err != nil {
 			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 				logger.Error(err)
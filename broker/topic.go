@@ -0,0 +1,56 @@
+package broker
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// TopicHeader is set to the topic a message was published to, so subscribers of a
+	// wildcard know which of the topics it matches the message belongs to
+	TopicHeader = "Micro-Topic"
+)
+
+var (
+	// ErrInvalidTopic is returned when publishing to a topic containing wildcards
+	ErrInvalidTopic = errors.New("invalid topic")
+)
+
+// Topics are hierarchical, their tokens are separated by dots e.g orders.eu.created. A subscription
+// can use wildcards in place of tokens, * matches a single token e.g orders.*.created and > matches
+// one or more trailing tokens e.g orders.>. Brokers without native support emulate the semantics.
+
+// IsWildcard returns true if the topic contains wildcards
+func IsWildcard(topic string) bool {
+	for _, t := range strings.Split(topic, ".") {
+		if t == "*" || t == ">" {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns true if the topic matches the subscription, which may contain wildcards
+func Match(subscription, topic string) bool {
+	if subscription == topic {
+		return true
+	}
+
+	subs := strings.Split(subscription, ".")
+	toks := strings.Split(topic, ".")
+
+	for i, s := range subs {
+		if s == ">" {
+			// must be the last token and match at least one
+			return i == len(subs)-1 && len(toks) > i
+		}
+		if i >= len(toks) {
+			return false
+		}
+		if s != "*" && s != toks[i] {
+			return false
+		}
+	}
+
+	return len(subs) == len(toks)
+}
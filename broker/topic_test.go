@@ -0,0 +1,36 @@
+package broker
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		subscription string
+		topic        string
+		expect       bool
+	}{
+		{"orders", "orders", true},
+		{"orders", "orders.created", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders", false},
+		{"orders.*", "orders.eu.created", false},
+		{"orders.*.created", "orders.eu.created", true},
+		{"orders.*.created", "orders.eu.deleted", false},
+		{"orders.>", "orders.created", true},
+		{"orders.>", "orders.eu.created", true},
+		{"orders.>", "orders", false},
+		{"*.created", "users.created", true},
+		{">", "orders.created", true},
+		{"orders.>.created", "orders.eu.created", false},
+		{"orders.created", "orders.deleted", false},
+	}
+
+	for _, tc := range testCases {
+		if got := Match(tc.subscription, tc.topic); got != tc.expect {
+			t.Errorf("Expected %s matching %s to be %v, got %v", tc.subscription, tc.topic, tc.expect, got)
+		}
+	}
+
+	if !IsWildcard("orders.*") || !IsWildcard("orders.>") || IsWildcard("orders.created") || IsWildcard("orders*") {
+		t.Fatal("Expected only tokens to be wildcards")
+	}
+}
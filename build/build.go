@@ -4,7 +4,7 @@ package build
 // Build is an interface for building packages
 type Build interface {
 	// Package builds a package
-	Package(name string, src *Source) (*Package, error)
+	Package(name string, src *Source, opts ...PackageOption) (*Package, error)
 	// Remove removes the package
 	Remove(*Package) error
 }
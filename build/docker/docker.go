@@ -2,9 +2,6 @@
 package docker
 
 import (
-	"archive/tar"
-	"bytes"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -18,47 +15,23 @@ type dockerBuild struct {
 	Client  *docker.Client
 }
 
-func (d *dockerBuild) Package(name string, s *build.Source) (*build.Package, error) {
-	image := name
-
-	buf := new(bytes.Buffer)
-	tw := tar.NewWriter(buf)
-	defer tw.Close()
-
-	dockerFile := "Dockerfile"
+// Package builds an image tagged with the name from the Dockerfile at the source path,
+// the source directory is the build context
+func (d *dockerBuild) Package(name string, s *build.Source, opts ...build.PackageOption) (*build.Package, error) {
+	options := build.NewPackageOptions(opts...)
 
-	// open docker file
-	f, err := os.Open(filepath.Join(s.Path, dockerFile))
-	if err != nil {
-		return nil, err
-	}
-	// read docker file
-	by, err := ioutil.ReadAll(f)
-	if err != nil {
-		return nil, err
-	}
+	image := name
 
-	tarHeader := &tar.Header{
-		Name: dockerFile,
-		Size: int64(len(by)),
-	}
-	err = tw.WriteHeader(tarHeader)
-	if err != nil {
+	if _, err := os.Stat(filepath.Join(s.Path, "Dockerfile")); err != nil {
 		return nil, err
 	}
-	_, err = tw.Write(by)
-	if err != nil {
-		return nil, err
-	}
-	tr := bytes.NewReader(buf.Bytes())
 
-	err = d.Client.BuildImage(docker.BuildImageOptions{
+	err := d.Client.BuildImage(docker.BuildImageOptions{
+		Context:        options.Context,
 		Name:           image,
-		Dockerfile:     dockerFile,
-		InputStream:    tr,
-		OutputStream:   ioutil.Discard,
+		ContextDir:     s.Path,
+		OutputStream:   options.Output,
 		RmTmpContainer: true,
-		SuppressOutput: true,
 	})
 	if err != nil {
 		return nil, err
@@ -84,7 +57,14 @@ func NewBuild(opts ...build.Option) build.Build {
 	for _, o := range opts {
 		o(&options)
 	}
+
 	endpoint := "unix:///var/run/docker.sock"
+	if options.Context != nil {
+		if e, ok := options.Context.Value(endpointKey{}).(string); ok {
+			endpoint = e
+		}
+	}
+
 	client, err := docker.NewClient(endpoint)
 	if err != nil {
 		logger.Fatal(err)
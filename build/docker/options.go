@@ -0,0 +1,19 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/build"
+)
+
+type endpointKey struct{}
+
+// Endpoint of the docker daemon, unix:///var/run/docker.sock by default
+func Endpoint(addr string) build.Option {
+	return func(o *build.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, endpointKey{}, addr)
+	}
+}
@@ -34,11 +34,16 @@ func whichGo() string {
 	return "go"
 }
 
-func (g *goBuild) Package(name string, src *build.Source) (*build.Package, error) {
+// Package builds the go package at the source path into a binary
+func (g *goBuild) Package(name string, src *build.Source, opts ...build.PackageOption) (*build.Package, error) {
+	options := build.NewPackageOptions(opts...)
+
 	binary := filepath.Join(g.Path, name)
-	source := src.Path
 
-	cmd := exec.Command(g.Cmd, "build", "-o", binary, source)
+	cmd := exec.CommandContext(options.Context, g.Cmd, "build", "-o", binary, ".")
+	cmd.Dir = src.Path
+	cmd.Stdout = options.Output
+	cmd.Stderr = options.Output
 	if err := cmd.Run(); err != nil {
 		return nil, err
 	}
@@ -52,8 +57,7 @@ func (g *goBuild) Package(name string, src *build.Source) (*build.Package, error
 }
 
 func (g *goBuild) Remove(b *build.Package) error {
-	binary := filepath.Join(b.Path, b.Name)
-	return os.Remove(binary)
+	return os.Remove(b.Path)
 }
 
 func (g *goBuild) String() string {
@@ -1,8 +1,17 @@
 package build
 
+import (
+	"context"
+	"io"
+	"io/ioutil"
+)
+
 type Options struct {
 	// local path to download source
 	Path string
+	// Other options for implementations of the interface
+	// can be stored in a context
+	Context context.Context
 }
 
 type Option func(o *Options)
@@ -13,3 +22,39 @@ func Path(p string) Option {
 		o.Path = p
 	}
 }
+
+type PackageOptions struct {
+	// Output the build logs are written to
+	Output io.Writer
+	// Context the build is cancelled with
+	Context context.Context
+}
+
+type PackageOption func(o *PackageOptions)
+
+// PackageOutput sets the writer the build logs are written to
+func PackageOutput(w io.Writer) PackageOption {
+	return func(o *PackageOptions) {
+		o.Output = w
+	}
+}
+
+// PackageContext sets the context the build is cancelled with
+func PackageContext(ctx context.Context) PackageOption {
+	return func(o *PackageOptions) {
+		o.Context = ctx
+	}
+}
+
+// NewPackageOptions returns the package options with the output
+// discarded and a background context if they're not set
+func NewPackageOptions(opts ...PackageOption) PackageOptions {
+	options := PackageOptions{
+		Output:  ioutil.Discard,
+		Context: context.Background(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
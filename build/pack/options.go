@@ -0,0 +1,19 @@
+package pack
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/build"
+)
+
+type builderKey struct{}
+
+// Builder sets the buildpacks builder image
+func Builder(image string) build.Option {
+	return func(o *build.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, builderKey{}, image)
+	}
+}
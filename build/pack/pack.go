@@ -0,0 +1,66 @@
+// Package pack builds images with cloud native buildpacks using the pack cli
+package pack
+
+import (
+	"os/exec"
+
+	"github.com/micro/go-micro/v3/build"
+)
+
+// DefaultBuilder is the buildpacks builder image used if none is set
+var DefaultBuilder = "paketobuildpacks/builder:base"
+
+type packBuild struct {
+	Options build.Options
+	Cmd     string
+	Builder string
+}
+
+// Package builds an image tagged with the name from the source path, the buildpacks
+// of the builder detect the language of the source
+func (p *packBuild) Package(name string, src *build.Source, opts ...build.PackageOption) (*build.Package, error) {
+	options := build.NewPackageOptions(opts...)
+
+	cmd := exec.CommandContext(options.Context, p.Cmd, "build", name, "--path", src.Path, "--builder", p.Builder)
+	cmd.Stdout = options.Output
+	cmd.Stderr = options.Output
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	return &build.Package{
+		Name:   name,
+		Path:   name,
+		Type:   "docker",
+		Source: src,
+	}, nil
+}
+
+// Remove is not supported, the image is left to the docker daemon
+func (p *packBuild) Remove(b *build.Package) error {
+	return nil
+}
+
+func (p *packBuild) String() string {
+	return "pack"
+}
+
+func NewBuild(opts ...build.Option) build.Build {
+	options := build.Options{}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	builder := DefaultBuilder
+	if options.Context != nil {
+		if b, ok := options.Context.Value(builderKey{}).(string); ok {
+			builder = b
+		}
+	}
+
+	return &packBuild{
+		Options: options,
+		Cmd:     "pack",
+		Builder: builder,
+	}
+}
@@ -10,7 +10,7 @@ import (
 
 type tarBuild struct{}
 
-func (t *tarBuild) Package(name string, src *build.Source) (*build.Package, error) {
+func (t *tarBuild) Package(name string, src *build.Source, opts ...build.PackageOption) (*build.Package, error) {
 	pkg := name + ".tar.gz"
 	// path to the tarball
 	path := filepath.Join(os.TempDir(), src.Path, pkg)
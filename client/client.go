@@ -103,4 +103,6 @@ var (
 	DefaultPoolSize = 100
 	// DefaultPoolTTL sets the connection pool ttl
 	DefaultPoolTTL = time.Minute
+	// DefaultKeepWarm is the interval at which connections to critical services are refreshed
+	DefaultKeepWarm = time.Second * 30
 )
@@ -1,7 +1,12 @@
 package grpc
 
 import (
+	"strings"
+
 	"github.com/micro/go-micro/v3/errors"
+	pberr "github.com/micro/go-micro/v3/errors/proto"
+	mgrpc "github.com/micro/go-micro/v3/util/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -22,8 +27,17 @@ func microError(err error) error {
 		return err
 	}
 
+	details := s.Details()
+
+	// micro error with its details
+	for _, d := range details {
+		if _, ok := d.(*pberr.Error); ok {
+			return mgrpc.FromStatus(s)
+		}
+	}
+
 	// return first error from details
-	if details := s.Details(); len(details) > 0 {
+	if len(details) > 0 {
 		if verr, ok := details[0].(error); ok {
 			return microError(verr)
 		}
@@ -34,6 +48,18 @@ func microError(err error) error {
 		return e // actually a micro error
 	}
 
+	// a message exceeding the max message size of the client or server
+	if s.Code() == codes.ResourceExhausted && strings.Contains(s.Message(), "larger than max") {
+		return errors.PayloadTooLarge("go.micro.client", s.Message())
+	}
+
 	// fallback
-	return errors.InternalServerError("go.micro.client", s.Message())
+	if s.Code() == codes.Unknown {
+		return errors.InternalServerError("go.micro.client", s.Message())
+	}
+
+	// map the status of a non micro server
+	e := mgrpc.FromStatus(s)
+	e.Id = "go.micro.client"
+	return e
 }
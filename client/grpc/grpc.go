@@ -8,11 +8,13 @@ import (
 	"net"
 	"reflect"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/micro/go-micro/v3/broker"
 	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/codec"
 	raw "github.com/micro/go-micro/v3/codec/bytes"
 	"github.com/micro/go-micro/v3/errors"
 	"github.com/micro/go-micro/v3/metadata"
@@ -20,6 +22,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip"
 	gmetadata "google.golang.org/grpc/metadata"
 )
 
@@ -27,6 +30,10 @@ type grpcClient struct {
 	opts client.Options
 	pool *pool
 	once atomic.Value
+
+	// stops the loop keeping the critical services warm
+	warmMu   sync.Mutex
+	warmExit chan bool
 }
 
 func init() {
@@ -73,7 +80,7 @@ func (g *grpcClient) call(ctx context.Context, addr string, req client.Request,
 	var header map[string]string
 
 	header = make(map[string]string)
-	if md, ok := metadata.FromContext(ctx); ok {
+	if md, ok := metadata.Outgoing(ctx, g.opts.Propagation); ok {
 		header = make(map[string]string, len(md))
 		for k, v := range md {
 			header[strings.ToLower(k)] = v
@@ -109,8 +116,8 @@ func (g *grpcClient) call(ctx context.Context, addr string, req client.Request,
 		),
 	}
 
-	if opts := g.getGrpcDialOptions(); opts != nil {
-		grpcDialOptions = append(grpcDialOptions, opts...)
+	if dopts := g.getGrpcDialOptions(opts); len(dopts) > 0 {
+		grpcDialOptions = append(grpcDialOptions, dopts...)
 	}
 
 	cc, err := g.pool.getConn(addr, grpcDialOptions...)
@@ -122,15 +129,18 @@ func (g *grpcClient) call(ctx context.Context, addr string, req client.Request,
 		g.pool.release(addr, cc, grr)
 	}()
 
+	copts, err := g.getGrpcCallOptions(opts)
+	if err != nil {
+		return errors.InternalServerError("go.micro.client", err.Error())
+	}
+
 	ch := make(chan error, 1)
 
 	go func() {
 		grpcCallOptions := []grpc.CallOption{
 			grpc.ForceCodec(cf),
 			grpc.CallContentSubtype(cf.Name())}
-		if opts := g.getGrpcCallOptions(); opts != nil {
-			grpcCallOptions = append(grpcCallOptions, opts...)
-		}
+		grpcCallOptions = append(grpcCallOptions, copts...)
 		err := cc.Invoke(ctx, methodToGRPC(req.Service(), req.Endpoint()), req.Body(), rsp, grpcCallOptions...)
 		ch <- microError(err)
 	}()
@@ -148,7 +158,7 @@ func (g *grpcClient) call(ctx context.Context, addr string, req client.Request,
 func (g *grpcClient) stream(ctx context.Context, addr string, req client.Request, rsp interface{}, opts client.CallOptions) error {
 	var header map[string]string
 
-	if md, ok := metadata.FromContext(ctx); ok {
+	if md, ok := metadata.Outgoing(ctx, g.opts.Propagation); ok {
 		header = make(map[string]string, len(md))
 		for k, v := range md {
 			header[k] = v
@@ -188,8 +198,8 @@ func (g *grpcClient) stream(ctx context.Context, addr string, req client.Request
 		g.secure(addr),
 	}
 
-	if opts := g.getGrpcDialOptions(); opts != nil {
-		grpcDialOptions = append(grpcDialOptions, opts...)
+	if dopts := g.getGrpcDialOptions(opts); len(dopts) > 0 {
+		grpcDialOptions = append(grpcDialOptions, dopts...)
 	}
 
 	cc, err := grpc.DialContext(dialCtx, addr, grpcDialOptions...)
@@ -203,13 +213,16 @@ func (g *grpcClient) stream(ctx context.Context, addr string, req client.Request
 		ServerStreams: true,
 	}
 
+	copts, err := g.getGrpcCallOptions(opts)
+	if err != nil {
+		return errors.InternalServerError("go.micro.client", err.Error())
+	}
+
 	grpcCallOptions := []grpc.CallOption{
 		grpc.ForceCodec(wc),
 		grpc.CallContentSubtype(cf.Name()),
 	}
-	if opts := g.getGrpcCallOptions(); opts != nil {
-		grpcCallOptions = append(grpcCallOptions, opts...)
-	}
+	grpcCallOptions = append(grpcCallOptions, copts...)
 
 	// create a new cancelling context
 	newCtx, cancel := context.WithCancel(ctx)
@@ -332,7 +345,7 @@ func (g *grpcClient) Init(opts ...client.Option) error {
 		g.pool.Unlock()
 	}
 
-	return nil
+	return g.warmup()
 }
 
 func (g *grpcClient) Options() client.Options {
@@ -411,7 +424,7 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 	}
 
 	// balance the list of nodes
-	next, err := callOpts.Selector.Select(routes)
+	next, err := callOpts.Selector.Select(client.Addresses(routes), client.SelectOptions(routes, callOpts)...)
 	if err != nil {
 		return err
 	}
@@ -526,7 +539,7 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 	}
 
 	// balance the list of nodes
-	next, err := callOpts.Selector.Select(routes)
+	next, err := callOpts.Selector.Select(client.Addresses(routes), client.SelectOptions(routes, callOpts)...)
 	if err != nil {
 		return nil, err
 	}
@@ -617,7 +630,7 @@ func (g *grpcClient) Publish(ctx context.Context, p client.Message, opts ...clie
 		o(&options)
 	}
 
-	md, ok := metadata.FromContext(ctx)
+	md, ok := metadata.Outgoing(ctx, g.opts.Propagation)
 	if !ok {
 		md = make(map[string]string)
 	}
@@ -658,44 +671,65 @@ func (g *grpcClient) String() string {
 	return "grpc"
 }
 
-func (g *grpcClient) getGrpcDialOptions() []grpc.DialOption {
-	if g.opts.CallOptions.Context == nil {
-		return nil
-	}
-
-	v := g.opts.CallOptions.Context.Value(grpcDialOptions{})
+// getGrpcDialOptions returns the dial options set on the client followed by the ones
+// set for the call. As connections are pooled the latter only apply to new connections.
+func (g *grpcClient) getGrpcDialOptions(opts client.CallOptions) []grpc.DialOption {
+	var dopts []grpc.DialOption
 
-	if v == nil {
-		return nil
+	if g.opts.Context != nil {
+		if v, ok := g.opts.Context.Value(grpcDialOptions{}).([]grpc.DialOption); ok {
+			dopts = append(dopts, v...)
+		}
+		if v, ok := g.opts.Context.Value(unaryInterceptors{}).([]grpc.UnaryClientInterceptor); ok && len(v) > 0 {
+			dopts = append(dopts, grpc.WithChainUnaryInterceptor(v...))
+		}
+		if v, ok := g.opts.Context.Value(streamInterceptors{}).([]grpc.StreamClientInterceptor); ok && len(v) > 0 {
+			dopts = append(dopts, grpc.WithChainStreamInterceptor(v...))
+		}
 	}
 
-	opts, ok := v.([]grpc.DialOption)
-
-	if !ok {
-		return nil
+	if opts.Context != nil {
+		if v, ok := opts.Context.Value(grpcDialOptions{}).([]grpc.DialOption); ok {
+			dopts = append(dopts, v...)
+		}
 	}
 
-	return opts
+	return dopts
 }
 
-func (g *grpcClient) getGrpcCallOptions() []grpc.CallOption {
-	if g.opts.CallOptions.Context == nil {
-		return nil
-	}
+// getGrpcCallOptions returns the call options set on the client followed by the ones set for the call
+func (g *grpcClient) getGrpcCallOptions(opts client.CallOptions) ([]grpc.CallOption, error) {
+	var copts []grpc.CallOption
 
-	v := g.opts.CallOptions.Context.Value(grpcCallOptions{})
+	if g.opts.Context != nil {
+		if v, ok := g.opts.Context.Value(grpcCallOptions{}).([]grpc.CallOption); ok {
+			copts = append(copts, v...)
+		}
+	}
 
-	if v == nil {
-		return nil
+	if opts.Context != nil {
+		if v, ok := opts.Context.Value(grpcCallOptions{}).([]grpc.CallOption); ok {
+			copts = append(copts, v...)
+		}
 	}
 
-	opts, ok := v.([]grpc.CallOption)
+	// the size limits of the call take precedence over the ones of the connection
+	if opts.MaxSendSize > 0 {
+		copts = append(copts, grpc.MaxCallSendMsgSize(opts.MaxSendSize))
+	}
+	if opts.MaxRecvSize > 0 {
+		copts = append(copts, grpc.MaxCallRecvMsgSize(opts.MaxRecvSize))
+	}
 
-	if !ok {
-		return nil
+	// the messages are compressed by grpc, it has no threshold
+	if len(opts.Compression) > 0 {
+		if encoding.GetCompressor(opts.Compression) == nil {
+			return nil, fmt.Errorf("%v: %s", codec.ErrUnknownEncoding, opts.Compression)
+		}
+		copts = append(copts, grpc.UseCompressor(opts.Compression))
 	}
 
-	return opts
+	return copts, nil
 }
 
 func newClient(opts ...client.Option) client.Client {
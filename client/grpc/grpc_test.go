@@ -106,3 +106,117 @@ func TestGRPCClient(t *testing.T) {
 	}
 
 }
+
+func TestGRPCClientInterceptors(t *testing.T) {
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer l.Close()
+
+	s := pgrpc.NewServer()
+	pb.RegisterGreeterServer(s, &greeterServer{})
+
+	go s.Serve(l)
+	defer s.Stop()
+
+	r := memory.NewRegistry()
+	r.Register(&registry.Service{
+		Name: "helloworld",
+		Nodes: []*registry.Node{
+			{Id: "test-1", Address: l.Addr().String()},
+		},
+	})
+
+	var called []string
+
+	intercept := func(name string) pgrpc.UnaryClientInterceptor {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *pgrpc.ClientConn, invoker pgrpc.UnaryInvoker, opts ...pgrpc.CallOption) error {
+			called = append(called, name+" "+method)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+
+	c := NewClient(
+		client.Router(regRouter.NewRouter(router.Registry(r))),
+		WithDialOptions(pgrpc.WithUserAgent("test")),
+		UnaryInterceptor(intercept("first")),
+		UnaryInterceptor(intercept("second")),
+	)
+
+	req := c.NewRequest("helloworld", "Greeter.SayHello", &pb.HelloRequest{Name: "John"})
+
+	var rsp pb.HelloReply
+	if err := c.Call(context.TODO(), req, &rsp); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"first /helloworld.Greeter/SayHello",
+		"second /helloworld.Greeter/SayHello",
+	}
+
+	if len(called) != len(expected) {
+		t.Fatalf("Expected interceptors %v got %v", expected, called)
+	}
+	for i := range expected {
+		if called[i] != expected[i] {
+			t.Fatalf("Expected interceptors %v got %v", expected, called)
+		}
+	}
+}
+
+func TestGRPCClientCompression(t *testing.T) {
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer l.Close()
+
+	s := pgrpc.NewServer()
+	pb.RegisterGreeterServer(s, &greeterServer{})
+
+	go s.Serve(l)
+	defer s.Stop()
+
+	r := memory.NewRegistry()
+	r.Register(&registry.Service{
+		Name:    "helloworld",
+		Version: "test",
+		Nodes: []*registry.Node{
+			{
+				Id:      "test-1",
+				Address: l.Addr().String(),
+				Metadata: map[string]string{
+					"protocol": "grpc",
+				},
+			},
+		},
+	})
+
+	c := NewClient(
+		client.Router(regRouter.NewRouter(router.Registry(r))),
+		client.Compression("gzip", 0),
+	)
+
+	req := c.NewRequest("helloworld", "/helloworld.Greeter/SayHello", &pb.HelloRequest{
+		Name: "John",
+	})
+
+	var rsp pb.HelloReply
+	if err := c.Call(context.TODO(), req, &rsp); err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Message != "Hello John" {
+		t.Fatalf("Got unexpected response %v", rsp.Message)
+	}
+
+	// encodings not registered with grpc are rejected
+	err = c.Call(context.TODO(), req, &rsp, client.WithCompression("zstd", 0))
+	if err == nil {
+		t.Fatal("Expected an error for an unknown encoding")
+	}
+	if verr, ok := err.(*errors.Error); !ok || verr.Code != 500 {
+		t.Fatalf("invalid error received %#+v\n", err)
+	}
+}
@@ -36,6 +36,8 @@ type maxRecvMsgSizeKey struct{}
 type maxSendMsgSizeKey struct{}
 type grpcDialOptions struct{}
 type grpcCallOptions struct{}
+type unaryInterceptors struct{}
+type streamInterceptors struct{}
 
 // maximum streams on a connectioin
 func PoolMaxStreams(n int) client.Option {
@@ -129,3 +131,54 @@ func CallOptions(opts ...grpc.CallOption) client.CallOption {
 		o.Context = context.WithValue(o.Context, grpcCallOptions{}, opts)
 	}
 }
+
+// WithDialOptions sets the gRPC dial options used for every connection of the client,
+// e.g. to set credentials or a resolver
+func WithDialOptions(opts ...grpc.DialOption) client.Option {
+	return func(o *client.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, grpcDialOptions{}, opts)
+	}
+}
+
+// WithCallOptions sets the gRPC call options used for every call of the client
+func WithCallOptions(opts ...grpc.CallOption) client.Option {
+	return func(o *client.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, grpcCallOptions{}, opts)
+	}
+}
+
+// UnaryInterceptor adds interceptors to the unary calls of the client,
+// they're chained in the order they're added
+func UnaryInterceptor(i ...grpc.UnaryClientInterceptor) client.Option {
+	return func(o *client.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		var is []grpc.UnaryClientInterceptor
+		if v, ok := o.Context.Value(unaryInterceptors{}).([]grpc.UnaryClientInterceptor); ok {
+			is = append(is, v...)
+		}
+		o.Context = context.WithValue(o.Context, unaryInterceptors{}, append(is, i...))
+	}
+}
+
+// StreamInterceptor adds interceptors to the streams of the client,
+// they're chained in the order they're added
+func StreamInterceptor(i ...grpc.StreamClientInterceptor) client.Option {
+	return func(o *client.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		var is []grpc.StreamClientInterceptor
+		if v, ok := o.Context.Value(streamInterceptors{}).([]grpc.StreamClientInterceptor); ok {
+			is = append(is, v...)
+		}
+		o.Context = context.WithValue(o.Context, streamInterceptors{}, append(is, i...))
+	}
+}
@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/logger"
+	"google.golang.org/grpc"
+)
+
+// warmup connects to the critical services and starts the loop which keeps
+// them warm, replacing the loop of a previous warmup. The error is only
+// returned for the FailFast policy.
+func (g *grpcClient) warmup() error {
+	g.stopWarm()
+
+	if len(g.opts.Critical) == 0 {
+		return nil
+	}
+
+	if err := client.Warm(g, g.dial); err != nil {
+		if g.opts.Warmup == client.FailFast {
+			return err
+		}
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Client warmup degraded: %v", err)
+		}
+	}
+
+	if g.opts.KeepWarm > 0 {
+		exit := make(chan bool)
+		g.warmMu.Lock()
+		g.warmExit = exit
+		g.warmMu.Unlock()
+
+		go g.keepWarm(g.opts.Context, g.opts.KeepWarm, exit)
+	}
+
+	return nil
+}
+
+// stopWarm stops the loop keeping the critical services warm
+func (g *grpcClient) stopWarm() {
+	g.warmMu.Lock()
+	defer g.warmMu.Unlock()
+
+	if g.warmExit != nil {
+		close(g.warmExit)
+		g.warmExit = nil
+	}
+}
+
+// keepWarm periodically refreshes the connections to the critical services until
+// the context of the client is done or the client is warmed up again
+func (g *grpcClient) keepWarm(ctx context.Context, interval time.Duration, exit chan bool) {
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+		case <-exit:
+			return
+		case <-done:
+			return
+		}
+
+		if err := client.Warm(g, g.dial); err != nil {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Client keep warm: %v", err)
+			}
+		}
+	}
+}
+
+// dial blocks until a connection to the address is established, leaving it
+// in the pool for the calls to come
+func (g *grpcClient) dial(addr string) error {
+	opts := []grpc.DialOption{
+		grpc.WithBlock(),
+		grpc.WithTimeout(g.opts.CallOptions.DialTimeout),
+		g.secure(addr),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(g.maxRecvMsgSizeValue()),
+			grpc.MaxCallSendMsgSize(g.maxSendMsgSizeValue()),
+		),
+	}
+
+	if o := g.getGrpcDialOptions(g.opts.CallOptions); len(o) > 0 {
+		opts = append(opts, o...)
+	}
+
+	cc, err := g.pool.getConn(addr, opts...)
+	if err != nil {
+		return err
+	}
+
+	g.pool.release(addr, cc, nil)
+	return nil
+}
@@ -6,16 +6,17 @@ import (
 
 	"github.com/micro/go-micro/v3/errors"
 	"github.com/micro/go-micro/v3/router"
+	"github.com/micro/go-micro/v3/selector"
 )
 
 // LookupFunc is used to lookup routes for a service
-type LookupFunc func(context.Context, Request, CallOptions) ([]string, error)
+type LookupFunc func(context.Context, Request, CallOptions) ([]router.Route, error)
 
-// LookupRoute for a request using the router and then choose one using the selector
-func LookupRoute(ctx context.Context, req Request, opts CallOptions) ([]string, error) {
+// LookupRoute for a request using the router, the routes are sorted by lowest metric first
+func LookupRoute(ctx context.Context, req Request, opts CallOptions) ([]router.Route, error) {
 	// check to see if an address was provided as a call option
 	if len(opts.Address) > 0 {
-		return opts.Address, nil
+		return addressRoutes(req, opts.Address), nil
 	}
 
 	// construct the router query
@@ -40,11 +41,43 @@ func LookupRoute(ctx context.Context, req Request, opts CallOptions) ([]string,
 		return routes[i].Metric < routes[j].Metric
 	})
 
-	var addrs []string
+	return routes, nil
+}
 
+// addressRoutes returns the routes to the addresses passed as call options, their weights aren't known
+func addressRoutes(req Request, addrs []string) []router.Route {
+	routes := make([]router.Route, 0, len(addrs))
+	for _, addr := range addrs {
+		routes = append(routes, router.Route{Service: req.Service(), Address: addr})
+	}
+	return routes
+}
+
+// Addresses returns the addresses of the routes to pass to the selector
+func Addresses(routes []router.Route) []string {
+	addrs := make([]string, 0, len(routes))
 	for _, route := range routes {
 		addrs = append(addrs, route.Address)
 	}
+	return addrs
+}
 
-	return addrs, nil
+// SelectOptions returns the select options of the call, weighting the selection by
+// the weights of the routes unless the call sets its own
+func SelectOptions(routes []router.Route, opts CallOptions) []selector.SelectOption {
+	var weights map[string]int
+	for _, route := range routes {
+		if route.Weight <= 0 {
+			continue
+		}
+		if weights == nil {
+			weights = make(map[string]int, len(routes))
+		}
+		weights[route.Address] = route.Weight
+	}
+
+	if len(weights) == 0 {
+		return opts.SelectOptions
+	}
+	return append([]selector.SelectOption{selector.Weights(weights)}, opts.SelectOptions...)
 }
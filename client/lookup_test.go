@@ -0,0 +1,62 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/client/mucp"
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/registry/memory"
+	"github.com/micro/go-micro/v3/router"
+	rreg "github.com/micro/go-micro/v3/router/registry"
+	"github.com/micro/go-micro/v3/selector"
+)
+
+func TestSelectOptions(t *testing.T) {
+	reg := memory.NewRegistry()
+	if err := reg.Register(&registry.Service{
+		Name:    "test.weights",
+		Version: "latest",
+		Nodes: []*registry.Node{
+			{Id: "light", Address: "10.0.0.1:8080", Weight: 10},
+			{Id: "heavy", Address: "10.0.0.2:8080", Weight: 300},
+			{Id: "unset", Address: "10.0.0.3:8080"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	rtr := rreg.NewRouter(router.Registry(reg))
+	defer rtr.Close()
+
+	c := mucp.NewClient()
+	req := c.NewRequest("test.weights", "Test.Method", nil)
+
+	routes, err := client.LookupRoute(context.TODO(), req, client.CallOptions{Router: rtr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addrs := client.Addresses(routes); len(addrs) != 3 {
+		t.Fatalf("Expected 3 addresses got %v", addrs)
+	}
+
+	options := selector.NewSelectOptions(client.SelectOptions(routes, client.CallOptions{})...)
+	if w := options.Weight("10.0.0.1:8080"); w != 10 {
+		t.Errorf("Expected the weight of the light node to be 10, got %d", w)
+	}
+	if w := options.Weight("10.0.0.2:8080"); w != 300 {
+		t.Errorf("Expected the weight of the heavy node to be 300, got %d", w)
+	}
+	if w := options.Weight("10.0.0.3:8080"); w != selector.DefaultWeight {
+		t.Errorf("Expected the node without a weight to have the default weight, got %d", w)
+	}
+
+	// the weights set by the call take precedence
+	options = selector.NewSelectOptions(client.SelectOptions(routes, client.CallOptions{
+		SelectOptions: []selector.SelectOption{selector.Weights(map[string]int{"10.0.0.1:8080": 50})},
+	})...)
+	if w := options.Weight("10.0.0.1:8080"); w != 50 {
+		t.Errorf("Expected the weight set by the call, got %d", w)
+	}
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/metrics"
+)
+
+var (
+	// RequestMetric is the timing metric of the calls and streams made tagged with the
+	// service, endpoint and result. The count of the timing is the rate of requests and errors.
+	RequestMetric = "client.request"
+	// PublishMetric is the timing metric of the messages published tagged with the topic and result
+	PublishMetric = "client.publish"
+)
+
+type metricsClient struct {
+	Client
+}
+
+func (m *metricsClient) Call(ctx context.Context, req Request, rsp interface{}, opts ...CallOption) error {
+	started := time.Now()
+	err := m.Client.Call(ctx, req, rsp, opts...)
+
+	metrics.DefaultReporter.Timing(RequestMetric, time.Since(started), metrics.Tags{
+		"service":  req.Service(),
+		"endpoint": req.Endpoint(),
+		"result":   metrics.Result(err),
+	})
+	return err
+}
+
+// Stream reports the time taken to open the stream
+func (m *metricsClient) Stream(ctx context.Context, req Request, opts ...CallOption) (Stream, error) {
+	started := time.Now()
+	stream, err := m.Client.Stream(ctx, req, opts...)
+
+	metrics.DefaultReporter.Timing(RequestMetric, time.Since(started), metrics.Tags{
+		"service":  req.Service(),
+		"endpoint": req.Endpoint(),
+		"result":   metrics.Result(err),
+	})
+	return stream, err
+}
+
+func (m *metricsClient) Publish(ctx context.Context, msg Message, opts ...PublishOption) error {
+	started := time.Now()
+	err := m.Client.Publish(ctx, msg, opts...)
+
+	metrics.DefaultReporter.Timing(PublishMetric, time.Since(started), metrics.Tags{
+		"topic":  msg.Topic(),
+		"result": metrics.Result(err),
+	})
+	return err
+}
+
+// MetricsWrapper reports the requests made and messages published to the default
+// metrics reporter, it's the first wrapper of the clients
+func MetricsWrapper(c Client) Client {
+	return &metricsClient{c}
+}
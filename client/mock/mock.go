@@ -0,0 +1,260 @@
+// Package mock provides a fake client for unit testing the consumers of services, the
+// responses of the endpoints are programmed and the calls made are recorded
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/client"
+	jsonCodec "github.com/micro/go-micro/v3/codec/json"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/registry"
+)
+
+// MockResponse is the programmed response of an endpoint
+type MockResponse struct {
+	Endpoint string
+	// Response is copied to the response of the call, it can be of another type with the same
+	// fields. It can also be a func(req interface{}) (interface{}, error) computing the response.
+	Response interface{}
+	// Error returned by the call
+	Error error
+	// Delay before the call returns, the call fails if the context is done first
+	Delay time.Duration
+}
+
+// MockCall is a call made with the client
+type MockCall struct {
+	Service  string
+	Endpoint string
+	Request  interface{}
+	Metadata metadata.Metadata
+}
+
+type MockClient struct {
+	sync.Mutex
+	Opts client.Options
+	// Responses programmed by service
+	Responses map[string][]MockResponse
+	// Endpoints of the services built from their definitions, the calls to other
+	// endpoints of the services fail and the calls without a response succeed
+	Endpoints map[string]map[string]bool
+	// Calls made in order
+	Calls []MockCall
+	// Messages published in order
+	Messages []client.Message
+}
+
+var (
+	_ client.Client = NewClient()
+)
+
+// NewClient returns a mock client without any responses
+func NewClient(opts ...client.Option) *MockClient {
+	options := client.NewOptions(opts...)
+
+	return &MockClient{
+		Opts:      options,
+		Responses: make(map[string][]MockResponse),
+		Endpoints: make(map[string]map[string]bool),
+	}
+}
+
+// NewClientFromRegistry returns a mock client of the services registered in the registry
+func NewClientFromRegistry(reg registry.Registry, services ...string) (*MockClient, error) {
+	m := NewClient()
+	for _, name := range services {
+		records, err := reg.GetService(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range records {
+			m.Define(s)
+		}
+	}
+	return m, nil
+}
+
+// Define the endpoints of the service, the calls to its other endpoints fail
+func (m *MockClient) Define(s *registry.Service) {
+	m.Lock()
+	defer m.Unlock()
+
+	if _, ok := m.Endpoints[s.Name]; !ok {
+		m.Endpoints[s.Name] = make(map[string]bool)
+	}
+	for _, e := range s.Endpoints {
+		m.Endpoints[s.Name][e.Name] = true
+	}
+}
+
+// Handle programs the responses of the endpoints of the service
+func (m *MockClient) Handle(service string, rsp ...MockResponse) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.Responses[service] = append(m.Responses[service], rsp...)
+}
+
+// Reset the recorded calls and messages
+func (m *MockClient) Reset() {
+	m.Lock()
+	defer m.Unlock()
+
+	m.Calls = nil
+	m.Messages = nil
+}
+
+// CallsTo returns the calls made to the endpoint of the service
+func (m *MockClient) CallsTo(service, endpoint string) []MockCall {
+	m.Lock()
+	defer m.Unlock()
+
+	var calls []MockCall
+	for _, c := range m.Calls {
+		if c.Service == service && c.Endpoint == endpoint {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+func (m *MockClient) Init(opts ...client.Option) error {
+	m.Lock()
+	defer m.Unlock()
+
+	for _, opt := range opts {
+		opt(&m.Opts)
+	}
+	return nil
+}
+
+func (m *MockClient) Options() client.Options {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.Opts
+}
+
+func (m *MockClient) NewMessage(topic string, msg interface{}, opts ...client.MessageOption) client.Message {
+	var options client.MessageOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.ContentType) == 0 {
+		options.ContentType = m.Opts.ContentType
+	}
+
+	return &MockMessage{
+		topic:       topic,
+		payload:     msg,
+		contentType: options.ContentType,
+	}
+}
+
+func (m *MockClient) NewRequest(service, endpoint string, req interface{}, reqOpts ...client.RequestOption) client.Request {
+	var options client.RequestOptions
+	for _, o := range reqOpts {
+		o(&options)
+	}
+	if len(options.ContentType) == 0 {
+		options.ContentType = m.Opts.ContentType
+	}
+
+	return &MockRequest{
+		service:     service,
+		endpoint:    endpoint,
+		body:        req,
+		contentType: options.ContentType,
+		stream:      options.Stream,
+	}
+}
+
+// response returns the programmed response of the request
+func (m *MockClient) response(req client.Request) (*MockResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	for _, r := range m.Responses[req.Service()] {
+		if r.Endpoint == req.Endpoint() {
+			return &r, nil
+		}
+	}
+
+	endpoints, ok := m.Endpoints[req.Service()]
+	if ok && endpoints[req.Endpoint()] {
+		// defined without a programmed response
+		return &MockResponse{Endpoint: req.Endpoint()}, nil
+	} else if ok {
+		return nil, errors.NotFound("go.micro.client.mock", "endpoint %s not found", req.Endpoint())
+	}
+
+	return nil, errors.InternalServerError("go.micro.client.mock", "service %s not found", req.Service())
+}
+
+func (m *MockClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	md, _ := metadata.FromContext(ctx)
+
+	m.Lock()
+	m.Calls = append(m.Calls, MockCall{
+		Service:  req.Service(),
+		Endpoint: req.Endpoint(),
+		Request:  req.Body(),
+		Metadata: md,
+	})
+	m.Unlock()
+
+	r, err := m.response(req)
+	if err != nil {
+		return err
+	}
+
+	if r.Delay > 0 {
+		select {
+		case <-time.After(r.Delay):
+		case <-ctx.Done():
+			return errors.Timeout("go.micro.client.mock", "%v", ctx.Err())
+		}
+	}
+
+	if r.Error != nil {
+		return r.Error
+	}
+
+	v := r.Response
+	if fn, ok := v.(func(interface{}) (interface{}, error)); ok {
+		if v, err = fn(req.Body()); err != nil {
+			return err
+		}
+	}
+	if v == nil || rsp == nil {
+		return nil
+	}
+
+	// copy the response through json so it can be of another type
+	var marshaler jsonCodec.Marshaler
+	b, err := marshaler.Marshal(v)
+	if err != nil {
+		return errors.InternalServerError("go.micro.client.mock", err.Error())
+	}
+	return marshaler.Unmarshal(b, rsp)
+}
+
+func (m *MockClient) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	return nil, errors.NotImplemented("go.micro.client.mock", "streaming is not supported by the mock client")
+}
+
+func (m *MockClient) Publish(ctx context.Context, msg client.Message, opts ...client.PublishOption) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.Messages = append(m.Messages, msg)
+	return nil
+}
+
+func (m *MockClient) String() string {
+	return "mock"
+}
@@ -0,0 +1,65 @@
+package mock
+
+import (
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/codec"
+)
+
+type MockRequest struct {
+	service     string
+	endpoint    string
+	contentType string
+	body        interface{}
+	stream      bool
+}
+
+func (r *MockRequest) Service() string {
+	return r.service
+}
+
+func (r *MockRequest) Method() string {
+	return r.endpoint
+}
+
+func (r *MockRequest) Endpoint() string {
+	return r.endpoint
+}
+
+func (r *MockRequest) ContentType() string {
+	return r.contentType
+}
+
+func (r *MockRequest) Body() interface{} {
+	return r.body
+}
+
+func (r *MockRequest) Codec() codec.Writer {
+	return nil
+}
+
+func (r *MockRequest) Stream() bool {
+	return r.stream
+}
+
+type MockMessage struct {
+	topic       string
+	contentType string
+	payload     interface{}
+}
+
+func (m *MockMessage) Topic() string {
+	return m.topic
+}
+
+func (m *MockMessage) Payload() interface{} {
+	return m.payload
+}
+
+func (m *MockMessage) ContentType() string {
+	return m.contentType
+}
+
+var (
+	_ client.Request = &MockRequest{}
+	_ client.Message = &MockMessage{}
+)
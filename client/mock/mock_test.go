@@ -0,0 +1,80 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/registry/memory"
+)
+
+type testResponse struct {
+	Msg string `json:"msg"`
+}
+
+func TestClient(t *testing.T) {
+	reg := memory.NewRegistry()
+	reg.Register(&registry.Service{
+		Name:      "greeter",
+		Version:   "latest",
+		Endpoints: []*registry.Endpoint{{Name: "Greeter.Hello"}, {Name: "Greeter.Bye"}, {Name: "Greeter.Slow"}},
+		Nodes:     []*registry.Node{{Id: "greeter-1", Address: "localhost:9090"}},
+	})
+
+	c, err := NewClientFromRegistry(reg, "greeter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Handle("greeter",
+		MockResponse{Endpoint: "Greeter.Hello", Response: func(req interface{}) (interface{}, error) {
+			return map[string]string{"msg": "Hello " + req.(map[string]string)["name"]}, nil
+		}},
+		MockResponse{Endpoint: "Greeter.Bye", Error: errors.BadRequest("greeter", "bad")},
+		MockResponse{Endpoint: "Greeter.Slow", Response: &testResponse{Msg: "slow"}, Delay: time.Second},
+	)
+
+	rsp := new(testResponse)
+	req := c.NewRequest("greeter", "Greeter.Hello", map[string]string{"name": "John"})
+	if err := c.Call(context.Background(), req, rsp); err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Msg != "Hello John" {
+		t.Fatalf("Expected Hello John got %v", rsp.Msg)
+	}
+
+	if err := c.Call(context.Background(), c.NewRequest("greeter", "Greeter.Bye", nil), rsp); err == nil {
+		t.Fatal("Expected the programmed error")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.Call(ctx, c.NewRequest("greeter", "Greeter.Slow", nil), rsp); err == nil {
+		t.Fatal("Expected the call to time out")
+	}
+
+	testData := []struct {
+		service  string
+		endpoint string
+		code     int32
+	}{
+		{"greeter", "Greeter.Unknown", 404},
+		{"other", "Other.Call", 500},
+	}
+	for _, d := range testData {
+		err := c.Call(context.Background(), c.NewRequest(d.service, d.endpoint, nil), rsp)
+		if verr, ok := err.(*errors.Error); !ok || verr.Code != d.code {
+			t.Fatalf("Expected %d for %s got %v", d.code, d.endpoint, err)
+		}
+	}
+
+	calls := c.CallsTo("greeter", "Greeter.Hello")
+	if len(calls) != 1 || fmt.Sprint(calls[0].Request) != "map[name:John]" {
+		t.Fatalf("Expected the call to be recorded got %v", calls)
+	}
+	if len(c.Calls) != 5 {
+		t.Fatalf("Expected 5 calls got %d", len(c.Calls))
+	}
+}
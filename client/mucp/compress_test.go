@@ -0,0 +1,65 @@
+package mucp_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/codec"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/util/test"
+)
+
+type Document struct {
+	Body string `json:"body"`
+}
+
+type Receipt struct {
+	Size     int    `json:"size"`
+	Encoding string `json:"encoding"`
+}
+
+type Store struct{}
+
+func (s *Store) Put(ctx context.Context, req *Document, rsp *Receipt) error {
+	rsp.Size = len(req.Body)
+	rsp.Encoding, _ = metadata.Get(ctx, codec.ContentEncoding)
+	return nil
+}
+
+func TestCompression(t *testing.T) {
+	env := test.NewEnv()
+	defer env.Stop()
+
+	if _, err := env.Run("test.compress", new(Store)); err != nil {
+		t.Fatal(err)
+	}
+
+	c := env.NewClient(client.Compression("gzip", 1024))
+
+	for _, tc := range []struct {
+		name     string
+		body     string
+		opts     []client.CallOption
+		encoding string
+	}{
+		{name: "small", body: "doc"},
+		{name: "large", body: strings.Repeat("doc", 1024), encoding: "gzip"},
+		{name: "disabled", body: strings.Repeat("doc", 1024), opts: []client.CallOption{client.WithCompression("", 0)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rsp := new(Receipt)
+			req := c.NewRequest("test.compress", "Store.Put", &Document{Body: tc.body})
+			if err := c.Call(context.TODO(), req, rsp, tc.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if rsp.Size != len(tc.body) {
+				t.Fatalf("Expected the service to receive %d bytes, got %d", len(tc.body), rsp.Size)
+			}
+			if rsp.Encoding != tc.encoding {
+				t.Fatalf("Expected the request to be sent with encoding %q, got %q", tc.encoding, rsp.Encoding)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ package mucp
 import (
 	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -24,6 +25,10 @@ type rpcClient struct {
 	opts client.Options
 	pool pool.Pool
 	seq  uint64
+
+	// stops the loop keeping the critical services warm
+	warmMu   sync.Mutex
+	warmExit chan bool
 }
 
 // NewClient returns a new micro client interface
@@ -68,7 +73,7 @@ func (r *rpcClient) call(ctx context.Context, addr string, req client.Request, r
 		Header: make(map[string]string),
 	}
 
-	md, ok := metadata.FromContext(ctx)
+	md, ok := metadata.Outgoing(ctx, r.opts.Propagation)
 	if ok {
 		for k, v := range md {
 			// don't copy Micro-Topic header, that used for pub/sub
@@ -106,7 +111,7 @@ func (r *rpcClient) call(ctx context.Context, addr string, req client.Request, r
 	}
 
 	seq := atomic.AddUint64(&r.seq, 1) - 1
-	codec := newRpcCodec(msg, c, cf, "")
+	codec := newRpcCodec(msg, c, cf, "", opts)
 
 	rsp := &rpcResponse{
 		socket: c,
@@ -178,7 +183,7 @@ func (r *rpcClient) stream(ctx context.Context, addr string, req client.Request,
 		Header: make(map[string]string),
 	}
 
-	md, ok := metadata.FromContext(ctx)
+	md, ok := metadata.Outgoing(ctx, r.opts.Propagation)
 	if ok {
 		for k, v := range md {
 			msg.Header[k] = v
@@ -217,7 +222,7 @@ func (r *rpcClient) stream(ctx context.Context, addr string, req client.Request,
 	id := fmt.Sprintf("%v", seq)
 
 	// create codec with stream id
-	codec := newRpcCodec(msg, c, cf, id)
+	codec := newRpcCodec(msg, c, cf, id, opts)
 
 	rsp := &rpcResponse{
 		socket: c,
@@ -295,7 +300,7 @@ func (r *rpcClient) Init(opts ...client.Option) error {
 		)
 	}
 
-	return nil
+	return r.warmup()
 }
 
 func (r *rpcClient) Options() client.Options {
@@ -360,7 +365,7 @@ func (r *rpcClient) Call(ctx context.Context, request client.Request, response i
 	}
 
 	// balance the list of nodes
-	next, err := callOpts.Selector.Select(routes)
+	next, err := callOpts.Selector.Select(client.Addresses(routes), client.SelectOptions(routes, callOpts)...)
 	if err != nil {
 		return err
 	}
@@ -468,7 +473,7 @@ func (r *rpcClient) Stream(ctx context.Context, request client.Request, opts ...
 	}
 
 	// balance the list of nodes
-	next, err := callOpts.Selector.Select(routes)
+	next, err := callOpts.Selector.Select(client.Addresses(routes), client.SelectOptions(routes, callOpts)...)
 	if err != nil {
 		return nil, err
 	}
@@ -552,7 +557,7 @@ func (r *rpcClient) Publish(ctx context.Context, msg client.Message, opts ...cli
 		o(&options)
 	}
 
-	md, ok := metadata.FromContext(ctx)
+	md, ok := metadata.Outgoing(ctx, r.opts.Propagation)
 	if !ok {
 		md = make(map[string]string)
 	}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	errs "errors"
 
+	"github.com/micro/go-micro/v3/client"
 	"github.com/micro/go-micro/v3/codec"
 	raw "github.com/micro/go-micro/v3/codec/bytes"
 	"github.com/micro/go-micro/v3/codec/grpc"
@@ -42,6 +43,14 @@ type rpcCodec struct {
 
 	// signify if its a stream
 	stream string
+
+	// the content encoding and size from which request bodies are compressed
+	encoding  string
+	threshold int
+
+	// max size of the request and response bodies
+	maxSend int
+	maxRecv int
 }
 
 type readWriteCloser struct {
@@ -151,17 +160,21 @@ func setupProtocol(msg *transport.Message, node *registry.Node) codec.NewCodec {
 	return defaultCodecs[msg.Header["Content-Type"]]
 }
 
-func newRpcCodec(req *transport.Message, client transport.Client, c codec.NewCodec, stream string) codec.Codec {
+func newRpcCodec(req *transport.Message, client transport.Client, c codec.NewCodec, stream string, opts client.CallOptions) codec.Codec {
 	rwc := &readWriteCloser{
 		wbuf: bytes.NewBuffer(nil),
 		rbuf: bytes.NewBuffer(nil),
 	}
 	r := &rpcCodec{
-		buf:    rwc,
-		client: client,
-		codec:  c(rwc),
-		req:    req,
-		stream: stream,
+		buf:       rwc,
+		client:    client,
+		codec:     c(rwc),
+		req:       req,
+		stream:    stream,
+		encoding:  opts.Compression,
+		threshold: opts.CompressionThreshold,
+		maxSend:   opts.MaxSendSize,
+		maxRecv:   opts.MaxRecvSize,
 	}
 	return r
 }
@@ -192,9 +205,24 @@ func (c *rpcCodec) Write(m *codec.Message, body interface{}) error {
 			if err := c.codec.Write(m, body); err != nil {
 				return errors.InternalServerError("go.micro.client.codec", err.Error())
 			}
-			// set body
-			m.Body = c.buf.wbuf.Bytes()
+			// set body, copied as the buffer is reused by the next write
+			m.Body = append([]byte(nil), c.buf.wbuf.Bytes()...)
+		}
+	}
+
+	// compress large bodies, the server decompresses them before decoding
+	delete(m.Header, codec.ContentEncoding)
+	if len(c.encoding) > 0 && len(m.Body) > 0 && len(m.Body) >= c.threshold {
+		b, err := codec.Compress(c.encoding, m.Body)
+		if err != nil {
+			return errors.InternalServerError("go.micro.client.codec", err.Error())
 		}
+		m.Header[codec.ContentEncoding] = c.encoding
+		m.Body = b
+	}
+
+	if c.maxSend > 0 && len(m.Body) > c.maxSend {
+		return errors.PayloadTooLarge("go.micro.client", "request of %d bytes exceeds the max send size of %d bytes", len(m.Body), c.maxSend)
 	}
 
 	// create new transport message
@@ -205,7 +233,7 @@ func (c *rpcCodec) Write(m *codec.Message, body interface{}) error {
 
 	// send the request
 	if err := c.client.Send(&msg); err != nil {
-		return errors.InternalServerError("go.micro.client.transport", err.Error())
+		return transportError(err)
 	}
 
 	return nil
@@ -216,7 +244,11 @@ func (c *rpcCodec) ReadHeader(m *codec.Message, r codec.MessageType) error {
 
 	// read message from transport
 	if err := c.client.Recv(&tm); err != nil {
-		return errors.InternalServerError("go.micro.client.transport", err.Error())
+		return transportError(err)
+	}
+
+	if c.maxRecv > 0 && len(tm.Body) > c.maxRecv {
+		return errors.PayloadTooLarge("go.micro.client", "response of %d bytes exceeds the max receive size of %d bytes", len(tm.Body), c.maxRecv)
 	}
 
 	c.buf.rbuf.Reset()
@@ -239,6 +271,15 @@ func (c *rpcCodec) ReadHeader(m *codec.Message, r codec.MessageType) error {
 	return nil
 }
 
+// transportError returns the error of the transport, the messages exceeding the max
+// message size of the transport are reported as too large
+func transportError(err error) error {
+	if errs.Is(err, transport.ErrMessageTooLarge) {
+		return errors.PayloadTooLarge("go.micro.client.transport", err.Error())
+	}
+	return errors.InternalServerError("go.micro.client.transport", err.Error())
+}
+
 func (c *rpcCodec) ReadBody(b interface{}) error {
 	// read body
 	// read raw data
@@ -0,0 +1,70 @@
+package mucp_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/server"
+	"github.com/micro/go-micro/v3/util/test"
+)
+
+type Echo struct{}
+
+func (e *Echo) Call(ctx context.Context, req *Document, rsp *Document) error {
+	rsp.Body = req.Body + req.Body
+	return nil
+}
+
+func TestMessageSize(t *testing.T) {
+	env := test.NewEnv()
+	defer env.Stop()
+
+	// the limited service only accepts and returns up to 1KB
+	srv := env.NewServer(server.Name("test.limited"), server.MaxRecvSize(1024), server.MaxSendSize(1024))
+	if err := srv.Handle(srv.NewHandler(new(Echo))); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.Run("test.echo", new(Echo)); err != nil {
+		t.Fatal(err)
+	}
+
+	c := env.NewClient(client.MaxSendSize(2048))
+
+	for _, tc := range []struct {
+		name    string
+		service string
+		body    string
+		opts    []client.CallOption
+		detail  string
+	}{
+		{name: "ok", service: "test.limited", body: strings.Repeat("a", 256)},
+		{name: "client send", service: "test.echo", body: strings.Repeat("a", 4096), detail: "exceeds the max send size of 2048 bytes"},
+		{name: "client receive", service: "test.echo", body: strings.Repeat("a", 512), opts: []client.CallOption{client.WithMaxRecvSize(512)}, detail: "exceeds the max receive size of 512 bytes"},
+		{name: "server receive", service: "test.limited", body: strings.Repeat("a", 1536), detail: "exceeds the max receive size of 1024 bytes"},
+		{name: "server receive compressed", service: "test.limited", body: strings.Repeat("a", 4096), opts: []client.CallOption{client.WithCompression("gzip", 0)}, detail: "exceeds the max receive size of 1024 bytes"},
+		{name: "server send", service: "test.limited", body: strings.Repeat("a", 768), detail: "exceeds the max send size of 1024 bytes"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rsp := new(Document)
+			req := c.NewRequest(tc.service, "Echo.Call", &Document{Body: tc.body})
+			err := c.Call(context.TODO(), req, rsp, tc.opts...)
+			if len(tc.detail) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			merr := errors.FromError(err)
+			if merr.Code != 413 || !strings.Contains(merr.Detail, tc.detail) {
+				t.Fatalf("Expected a payload too large error %q, got %v", tc.detail, err)
+			}
+		})
+	}
+}
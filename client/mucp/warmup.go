@@ -0,0 +1,97 @@
+package mucp
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/network/transport"
+)
+
+// warmup connects to the critical services and starts the loop which keeps
+// them warm, replacing the loop of a previous warmup. The error is only
+// returned for the FailFast policy.
+func (r *rpcClient) warmup() error {
+	r.stopWarm()
+
+	if len(r.opts.Critical) == 0 {
+		return nil
+	}
+
+	if err := client.Warm(r, r.dial); err != nil {
+		if r.opts.Warmup == client.FailFast {
+			return err
+		}
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Client warmup degraded: %v", err)
+		}
+	}
+
+	if r.opts.KeepWarm > 0 {
+		exit := make(chan bool)
+		r.warmMu.Lock()
+		r.warmExit = exit
+		r.warmMu.Unlock()
+
+		go r.keepWarm(r.opts.Context, r.opts.KeepWarm, exit)
+	}
+
+	return nil
+}
+
+// stopWarm stops the loop keeping the critical services warm
+func (r *rpcClient) stopWarm() {
+	r.warmMu.Lock()
+	defer r.warmMu.Unlock()
+
+	if r.warmExit != nil {
+		close(r.warmExit)
+		r.warmExit = nil
+	}
+}
+
+// keepWarm periodically refreshes the connections to the critical services until
+// the context of the client is done or the client is warmed up again
+func (r *rpcClient) keepWarm(ctx context.Context, interval time.Duration, exit chan bool) {
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+		case <-exit:
+			return
+		case <-done:
+			return
+		}
+
+		if err := client.Warm(r, r.dial); err != nil {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Client keep warm: %v", err)
+			}
+		}
+	}
+}
+
+// dial connects to the address, leaving the connection idle in the pool
+func (r *rpcClient) dial(addr string) error {
+	dOpts := []transport.DialOption{
+		transport.WithStream(),
+	}
+	if d := r.opts.CallOptions.DialTimeout; d >= 0 {
+		dOpts = append(dOpts, transport.WithTimeout(d))
+	}
+
+	c, err := r.pool.Get(addr, dOpts...)
+	if err != nil {
+		return err
+	}
+
+	return r.pool.Release(c, nil)
+}
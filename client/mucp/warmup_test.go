@@ -0,0 +1,102 @@
+package mucp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/network/transport"
+	tmemory "github.com/micro/go-micro/v3/network/transport/memory"
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/registry/memory"
+	"github.com/micro/go-micro/v3/router"
+	regRouter "github.com/micro/go-micro/v3/router/registry"
+)
+
+func TestWarmup(t *testing.T) {
+	tr := tmemory.NewTransport()
+
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	accepted := make(chan bool, 10)
+	go l.Accept(func(sock transport.Socket) {
+		accepted <- true
+	})
+
+	reg := memory.NewRegistry()
+	reg.Register(&registry.Service{
+		Name:  "critical",
+		Nodes: []*registry.Node{{Id: "critical-1", Address: l.Addr()}},
+	})
+
+	c := NewClient(
+		client.Transport(tr),
+		client.Router(regRouter.NewRouter(router.Registry(reg))),
+		client.KeepWarm(0),
+	)
+
+	// the critical service is resolved and connected to
+	if err := c.Init(client.Critical("critical"), client.Warmup(client.FailFast)); err != nil {
+		t.Fatalf("Unexpected warmup error: %v", err)
+	}
+
+	select {
+	case <-accepted:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a connection to the critical service")
+	}
+
+	// an unknown service fails the startup
+	err = c.Init(client.Critical("missing"))
+	if !errors.Is(err, client.ErrUnreachable) {
+		t.Fatalf("Expected %v got %v", client.ErrUnreachable, err)
+	}
+
+	// or degrades it
+	if err := c.Init(client.Warmup(client.Degrade)); err != nil {
+		t.Fatalf("Unexpected warmup error: %v", err)
+	}
+}
+
+func TestKeepWarm(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := &rpcClient{}
+	done := make(chan bool)
+	go func() {
+		r.keepWarm(ctx, time.Hour, make(chan bool))
+		close(done)
+	}()
+
+	// the loop stops with the context of the client
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the keep warm loop to stop")
+	}
+
+	// and when the client is warmed up again
+	exit := make(chan bool)
+	r.warmExit = exit
+	done = make(chan bool)
+	go func() {
+		r.keepWarm(context.Background(), time.Hour, exit)
+		close(done)
+	}()
+
+	r.stopWarm()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the keep warm loop to stop")
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"github.com/micro/go-micro/v3/broker"
 	"github.com/micro/go-micro/v3/broker/http"
 	"github.com/micro/go-micro/v3/codec"
+	"github.com/micro/go-micro/v3/metadata"
 	"github.com/micro/go-micro/v3/network/transport"
 	thttp "github.com/micro/go-micro/v3/network/transport/http"
 	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/registry/multi"
 	"github.com/micro/go-micro/v3/router"
 	regRouter "github.com/micro/go-micro/v3/router/registry"
 	"github.com/micro/go-micro/v3/selector"
@@ -39,6 +41,17 @@ type Options struct {
 	// Middleware for client
 	Wrappers []Wrapper
 
+	// Propagation policy of the metadata of the request being served,
+	// all the metadata is propagated if it's not set
+	Propagation *metadata.Policy
+
+	// Critical services resolved and connected to at startup
+	Critical []string
+	// Warmup policy applied when a critical service is unreachable
+	Warmup WarmupPolicy
+	// KeepWarm is the interval at which critical connections are refreshed
+	KeepWarm time.Duration
+
 	// Default Call Options
 	CallOptions CallOptions
 
@@ -72,6 +85,14 @@ type CallOptions struct {
 	AuthToken bool
 	// Network to lookup the route within
 	Network string
+	// Compression is the content encoding request bodies are compressed with
+	Compression string
+	// CompressionThreshold is the size in bytes from which request bodies are compressed
+	CompressionThreshold int
+	// MaxSendSize is the max size in bytes of the requests, unlimited if zero
+	MaxSendSize int
+	// MaxRecvSize is the max size in bytes of the responses, unlimited if zero
+	MaxRecvSize int
 
 	// Middleware for low level call func
 	CallWrappers []CallWrapper
@@ -115,8 +136,10 @@ func NewOptions(options ...Option) Options {
 			DialTimeout:    transport.DefaultDialTimeout,
 		},
 		Lookup:    LookupRoute,
+		Wrappers:  []Wrapper{MetricsWrapper, TraceWrapper},
 		PoolSize:  DefaultPoolSize,
 		PoolTTL:   DefaultPoolTTL,
+		KeepWarm:  DefaultKeepWarm,
 		Broker:    http.NewBroker(),
 		Router:    regRouter.NewRouter(),
 		Selector:  roundrobin.NewSelector(),
@@ -186,6 +209,13 @@ func Registry(r registry.Registry) Option {
 	}
 }
 
+// Registries sets the routers registry to one federating the registries, the
+// services found in each of them are merged. See the registry/multi package for
+// looking them up by precedence instead.
+func Registries(rs ...registry.Registry) Option {
+	return Registry(multi.NewRegistry(rs))
+}
+
 // Router is used to lookup routes for a service
 func Router(r router.Router) Option {
 	return func(o *Options) {
@@ -207,6 +237,14 @@ func Wrap(w Wrapper) Option {
 	}
 }
 
+// PropagationPolicy sets the policy governing which metadata of the request being served
+// is propagated to the requests made by the client
+func PropagationPolicy(p *metadata.Policy) Option {
+	return func(o *Options) {
+		o.Propagation = p
+	}
+}
+
 // Adds a Wrapper to the list of CallFunc wrappers
 func WrapCall(cw ...CallWrapper) Option {
 	return func(o *Options) {
@@ -266,6 +304,33 @@ func DialTimeout(d time.Duration) Option {
 	}
 }
 
+// Compression compresses the request bodies of at least threshold bytes with the
+// content encoding e.g gzip, others such as zstd can be registered in codec.Compressors.
+// The grpc client compresses every message with the compressors registered with grpc
+// instead, gzip is registered.
+func Compression(encoding string, threshold int) Option {
+	return func(o *Options) {
+		o.CallOptions.Compression = encoding
+		o.CallOptions.CompressionThreshold = threshold
+	}
+}
+
+// MaxSendSize limits the size in bytes of the requests, a larger request
+// fails with a PayloadTooLarge error without being sent
+func MaxSendSize(n int) Option {
+	return func(o *Options) {
+		o.CallOptions.MaxSendSize = n
+	}
+}
+
+// MaxRecvSize limits the size in bytes of the responses, a larger response
+// fails with a PayloadTooLarge error
+func MaxRecvSize(n int) Option {
+	return func(o *Options) {
+		o.CallOptions.MaxRecvSize = n
+	}
+}
+
 // Call Options
 
 // WithExchange sets the exchange to route a message through
@@ -358,6 +423,29 @@ func WithNetwork(n string) CallOption {
 	}
 }
 
+// WithCompression is a CallOption which compresses the request body when it's at
+// least threshold bytes, an empty encoding disables the compression
+func WithCompression(encoding string, threshold int) CallOption {
+	return func(o *CallOptions) {
+		o.Compression = encoding
+		o.CompressionThreshold = threshold
+	}
+}
+
+// WithMaxSendSize is a CallOption which limits the size in bytes of the request
+func WithMaxSendSize(n int) CallOption {
+	return func(o *CallOptions) {
+		o.MaxSendSize = n
+	}
+}
+
+// WithMaxRecvSize is a CallOption which limits the size in bytes of the response
+func WithMaxRecvSize(n int) CallOption {
+	return func(o *CallOptions) {
+		o.MaxRecvSize = n
+	}
+}
+
 // WithRouter sets the router to use for this call
 func WithRouter(r router.Router) CallOption {
 	return func(o *CallOptions) {
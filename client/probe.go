@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/registry"
+)
+
+var (
+	// ProbeEndpoint is the health endpoint of the debug handler called by Probe
+	ProbeEndpoint = "Debug.Health"
+)
+
+// NodeHealth is the health of a node of a service reported by Probe
+type NodeHealth struct {
+	// Address of the node
+	Address string
+	// Status reported by the node, down when it couldn't be reached
+	Status string
+	// Error calling the node or reported by its health check
+	Error string
+	// Latency of the health call
+	Latency time.Duration
+}
+
+// Healthy returns true if the node is up
+func (n *NodeHealth) Healthy() bool {
+	return n.Status == registry.HealthUp
+}
+
+// ProbeOptions of a health probe
+type ProbeOptions struct {
+	// Context of the health calls
+	Context context.Context
+	// First only probes the first node the service resolves to rather than all of them
+	First bool
+	// CallOptions of the health calls e.g WithRequestTimeout
+	CallOptions []CallOption
+}
+
+// ProbeOption sets an option of a health probe
+type ProbeOption func(o *ProbeOptions)
+
+// ProbeContext sets the context of the health calls
+func ProbeContext(ctx context.Context) ProbeOption {
+	return func(o *ProbeOptions) {
+		o.Context = ctx
+	}
+}
+
+// ProbeFirst only probes the first node the service resolves to, e.g as a pre-flight check
+func ProbeFirst() ProbeOption {
+	return func(o *ProbeOptions) {
+		o.First = true
+	}
+}
+
+// ProbeCallOptions sets the options of the health calls
+func ProbeCallOptions(opts ...CallOption) ProbeOption {
+	return func(o *ProbeOptions) {
+		o.CallOptions = append(o.CallOptions, opts...)
+	}
+}
+
+type probeRequest struct{}
+
+type probeResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Probe calls the health endpoint served by the debug handler of the nodes the service resolves
+// to and returns the health of each. It's meant for readiness gates and pre-flight checks, an
+// error is only returned when the service can't be resolved.
+func Probe(c Client, service string, opts ...ProbeOption) ([]*NodeHealth, error) {
+	options := ProbeOptions{
+		Context: context.Background(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	callOpts := c.Options().CallOptions
+	if callOpts.Router == nil {
+		callOpts.Router = c.Options().Router
+	}
+	for _, o := range options.CallOptions {
+		o(&callOpts)
+	}
+
+	req := c.NewRequest(service, ProbeEndpoint, &probeRequest{}, WithContentType("application/json"))
+
+	routes, err := c.Options().Lookup(options.Context, req, callOpts)
+	if err != nil {
+		return nil, err
+	}
+	addrs := Addresses(routes)
+	if options.First && len(addrs) > 1 {
+		addrs = addrs[:1]
+	}
+
+	nodes := make([]*NodeHealth, len(addrs))
+
+	var wg sync.WaitGroup
+	for i, addr := range addrs {
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			nodes[i] = probe(options, c, req, addr)
+		}(i, addr)
+	}
+	wg.Wait()
+
+	return nodes, nil
+}
+
+func probe(options ProbeOptions, c Client, req Request, addr string) *NodeHealth {
+	// a probe reports the node as it is rather than retrying
+	callOpts := append([]CallOption{WithRetries(0)}, options.CallOptions...)
+	callOpts = append(callOpts, WithAddress(addr))
+
+	node := &NodeHealth{Address: addr}
+
+	var rsp probeResponse
+	start := time.Now()
+	err := c.Call(options.Context, req, &rsp, callOpts...)
+	node.Latency = time.Since(start)
+
+	if err != nil {
+		node.Status = registry.HealthDown
+		node.Error = err.Error()
+		return node
+	}
+
+	node.Status = rsp.Status
+	node.Error = rsp.Error
+	return node
+}
@@ -0,0 +1,79 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/debug/handler"
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/server"
+	"github.com/micro/go-micro/v3/util/test"
+)
+
+func TestProbe(t *testing.T) {
+	env := test.NewEnv()
+	defer env.Stop()
+
+	// run nodes of the service serving the debug handler
+	run := func(id string, opts ...handler.Option) server.Server {
+		srv := env.NewServer(server.Name("test.probe"), server.Id(id))
+		if err := srv.Handle(srv.NewHandler(handler.NewHandler(opts...))); err != nil {
+			t.Fatal(err)
+		}
+		if err := srv.Start(); err != nil {
+			t.Fatal(err)
+		}
+		return srv
+	}
+
+	up := run("up")
+	down := run("down", handler.Check(func(ctx context.Context) error {
+		return errors.New("database unreachable")
+	}))
+
+	c := env.NewClient()
+
+	nodes, err := client.Probe(c, "test.probe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("Expected 2 nodes, got %d", len(nodes))
+	}
+
+	health := make(map[string]*client.NodeHealth)
+	for _, n := range nodes {
+		health[n.Address] = n
+	}
+	if n := health[up.Options().Address]; n == nil || !n.Healthy() {
+		t.Fatalf("Expected the node %s to be healthy, got %+v", up.Options().Address, n)
+	}
+	if n := health[down.Options().Address]; n == nil || n.Healthy() || n.Error != "database unreachable" {
+		t.Fatalf("Expected the node %s to be down, got %+v", down.Options().Address, n)
+	}
+
+	// a node which can't be reached is down
+	down.Stop()
+	nodes, err = client.Probe(c, "test.probe", client.ProbeCallOptions(client.WithAddress(down.Options().Address)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0].Status != registry.HealthDown || len(nodes[0].Error) == 0 {
+		t.Fatalf("Expected the stopped node to be down, got %+v", nodes)
+	}
+
+	// only the first node is probed
+	nodes, err = client.Probe(c, "test.probe", client.ProbeFirst())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || !nodes[0].Healthy() {
+		t.Fatalf("Expected the healthy node, got %+v", nodes)
+	}
+
+	if _, err := client.Probe(c, "test.missing"); err == nil {
+		t.Fatal("Expected an error probing a service which doesn't exist")
+	}
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/micro/go-micro/v3/debug/trace"
+)
+
+type traceClient struct {
+	Client
+	// the default tracer is used if nil
+	tracer trace.Tracer
+}
+
+// tracer returns the tracer, or the default tracer if nil
+func tracer(t trace.Tracer) trace.Tracer {
+	if t != nil {
+		return t
+	}
+	return trace.DefaultTracer
+}
+
+// finish records the error if any and finishes the span
+func finish(t trace.Tracer, span *trace.Span, err error) {
+	if err != nil {
+		span.Metadata["error"] = err.Error()
+	}
+	t.Finish(span)
+}
+
+func (c *traceClient) Call(ctx context.Context, req Request, rsp interface{}, opts ...CallOption) error {
+	t := tracer(c.tracer)
+	newCtx, span := t.Start(ctx, fmt.Sprintf("%s.%s", req.Service(), req.Endpoint()))
+	if span == nil {
+		return c.Client.Call(ctx, req, rsp, opts...)
+	}
+	span.Type = trace.SpanTypeRequestOutbound
+	span.Service = req.Service()
+
+	err := c.Client.Call(newCtx, req, rsp, opts...)
+	finish(t, span, err)
+	return err
+}
+
+// Stream traces opening the stream
+func (c *traceClient) Stream(ctx context.Context, req Request, opts ...CallOption) (Stream, error) {
+	t := tracer(c.tracer)
+	newCtx, span := t.Start(ctx, fmt.Sprintf("%s.%s", req.Service(), req.Endpoint()))
+	if span == nil {
+		return c.Client.Stream(ctx, req, opts...)
+	}
+	span.Type = trace.SpanTypeRequestOutbound
+	span.Service = req.Service()
+
+	stream, err := c.Client.Stream(newCtx, req, opts...)
+	finish(t, span, err)
+	return stream, err
+}
+
+func (c *traceClient) Publish(ctx context.Context, msg Message, opts ...PublishOption) error {
+	t := tracer(c.tracer)
+	newCtx, span := t.Start(ctx, "Pub to "+msg.Topic())
+	if span == nil {
+		return c.Client.Publish(ctx, msg, opts...)
+	}
+	span.Type = trace.SpanTypeRequestOutbound
+
+	err := c.Client.Publish(newCtx, msg, opts...)
+	finish(t, span, err)
+	return err
+}
+
+// TraceWrapper traces the calls, streams and publications with the default tracer,
+// it's a default wrapper of the clients and does nothing until a tracer is set
+func TraceWrapper(c Client) Client {
+	return &traceClient{Client: c}
+}
+
+// NewTraceWrapper returns a wrapper which traces the calls, streams and publications with the tracer
+func NewTraceWrapper(t trace.Tracer) Wrapper {
+	return func(c Client) Client {
+		return &traceClient{Client: c, tracer: t}
+	}
+}
@@ -0,0 +1,54 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/debug/handler"
+	"github.com/micro/go-micro/v3/debug/trace"
+	"github.com/micro/go-micro/v3/debug/trace/memory"
+	"github.com/micro/go-micro/v3/server"
+	"github.com/micro/go-micro/v3/util/test"
+)
+
+func TestTraceWrapper(t *testing.T) {
+	tracer := memory.NewTracer()
+
+	// the default wrappers trace with the default tracer
+	defer func(t trace.Tracer) {
+		trace.DefaultTracer = t
+	}(trace.DefaultTracer)
+	trace.DefaultTracer = tracer
+
+	env := test.NewEnv()
+	defer env.Stop()
+
+	srv := env.NewServer(server.Name("test.trace"))
+	if err := srv.Handle(srv.NewHandler(handler.NewHandler())); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Probe(env.NewClient(), "test.trace"); err != nil {
+		t.Fatal(err)
+	}
+
+	spans, err := tracer.Read(trace.ReadService("test.trace"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	types := make(map[trace.SpanType]*trace.Span)
+	for _, span := range spans {
+		types[span.Type] = span
+	}
+	out, in := types[trace.SpanTypeRequestOutbound], types[trace.SpanTypeRequestInbound]
+	if out == nil || in == nil {
+		t.Fatalf("Expected the call and the request to be traced, got %+v", spans)
+	}
+	if in.Trace != out.Trace {
+		t.Fatalf("Expected the request to be part of the trace %s, got %s", out.Trace, in.Trace)
+	}
+}
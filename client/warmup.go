@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+)
+
+// WarmupPolicy decides what happens when a critical service can't be reached at startup
+type WarmupPolicy int
+
+const (
+	// Degrade logs the unreachable services and keeps trying to reach them in the background
+	Degrade WarmupPolicy = iota
+	// FailFast fails the startup of the client
+	FailFast
+)
+
+func (p WarmupPolicy) String() string {
+	switch p {
+	case Degrade:
+		return "degrade"
+	case FailFast:
+		return "failfast"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	// ErrUnreachable is returned when a critical service can't be reached
+	ErrUnreachable = errors.New("critical service unreachable")
+)
+
+// Critical declares services the client depends on. They're resolved and
+// connected to when the client is initialised and kept warm until the context
+// of its options is done.
+func Critical(services ...string) Option {
+	return func(o *Options) {
+		o.Critical = append(o.Critical, services...)
+	}
+}
+
+// Warmup sets the policy applied when a critical service is unreachable at startup
+func Warmup(p WarmupPolicy) Option {
+	return func(o *Options) {
+		o.Warmup = p
+	}
+}
+
+// KeepWarm sets the interval at which connections to critical services are
+// refreshed, a zero interval only warms them up at startup
+func KeepWarm(d time.Duration) Option {
+	return func(o *Options) {
+		o.KeepWarm = d
+	}
+}
+
+// Warm resolves the critical services of the client and calls dial for every
+// address they resolve to. It returns ErrUnreachable listing the services none
+// of whose addresses could be dialled.
+func Warm(c Client, dial func(addr string) error) error {
+	opts := c.Options()
+
+	callOpts := opts.CallOptions
+	if callOpts.Router == nil {
+		callOpts.Router = opts.Router
+	}
+	if len(opts.Proxy) > 0 {
+		callOpts.Address = []string{opts.Proxy}
+	}
+
+	var unreachable []string
+
+	for _, service := range opts.Critical {
+		if err := warmService(c, service, callOpts, dial); err != nil {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Client failed to warm %s: %v", service, err)
+			}
+			unreachable = append(unreachable, service)
+		}
+	}
+
+	if len(unreachable) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnreachable, strings.Join(unreachable, ", "))
+	}
+
+	return nil
+}
+
+func warmService(c Client, service string, callOpts CallOptions, dial func(addr string) error) error {
+	req := c.NewRequest(service, "", nil)
+
+	routes, err := c.Options().Lookup(context.Background(), req, callOpts)
+	if err != nil {
+		return err
+	}
+	addrs := Addresses(routes)
+
+	var connected int
+	var lastErr error
+
+	for _, addr := range addrs {
+		if err := dial(addr); err != nil {
+			lastErr = fmt.Errorf("%s: %v", addr, err)
+			continue
+		}
+		connected++
+	}
+
+	if connected > 0 {
+		return nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no nodes found")
+	}
+
+	return lastErr
+}
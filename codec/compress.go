@@ -0,0 +1,116 @@
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+const (
+	// ContentEncoding is the header of the encoding a message body is compressed with
+	ContentEncoding = "Micro-Content-Encoding"
+)
+
+var (
+	// MaxDecompressedSize limits the size of decompressed bodies
+	MaxDecompressedSize int64 = 64 << 20
+	// ErrUnknownEncoding is returned for a body compressed with an unknown encoding
+	ErrUnknownEncoding = errors.New("unknown content encoding")
+	// ErrDecompressedSize is returned when a body exceeds MaxDecompressedSize once decompressed
+	ErrDecompressedSize = errors.New("decompressed body too large")
+
+	// Compressors by content encoding, only gzip is built in. Others such as zstd
+	// are registered by the service importing their library e.g
+	//
+	//	codec.Compressors["zstd"] = zstdCompressor{}
+	Compressors = map[string]Compressor{
+		"gzip": new(gzipCompressor),
+	}
+)
+
+// Compressor compresses message bodies
+type Compressor interface {
+	Compress(b []byte) ([]byte, error)
+	Decompress(b []byte) ([]byte, error)
+}
+
+// LimitDecompressor stops decompressing a body once it exceeds the limit
+type LimitDecompressor interface {
+	DecompressLimit(b []byte, limit int64) ([]byte, error)
+}
+
+// Compress compresses the body with the compressor of the encoding
+func Compress(encoding string, b []byte) ([]byte, error) {
+	c, ok := Compressors[encoding]
+	if !ok {
+		return nil, ErrUnknownEncoding
+	}
+	return c.Compress(b)
+}
+
+// Decompress decompresses the body with the compressor of the encoding
+func Decompress(encoding string, b []byte) ([]byte, error) {
+	c, ok := Compressors[encoding]
+	if !ok {
+		return nil, ErrUnknownEncoding
+	}
+	return c.Decompress(b)
+}
+
+// DecompressLimit decompresses the body with the compressor of the encoding, returning
+// ErrDecompressedSize when it exceeds limit bytes once decompressed
+func DecompressLimit(encoding string, b []byte, limit int64) ([]byte, error) {
+	c, ok := Compressors[encoding]
+	if !ok {
+		return nil, ErrUnknownEncoding
+	}
+	if l, ok := c.(LimitDecompressor); ok {
+		return l.DecompressLimit(b, limit)
+	}
+	out, err := c.Decompress(b)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(out)) > limit {
+		return nil, ErrDecompressedSize
+	}
+	return out, nil
+}
+
+type gzipCompressor struct{}
+
+func (g *gzipCompressor) Compress(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (g *gzipCompressor) Decompress(b []byte) ([]byte, error) {
+	return g.DecompressLimit(b, MaxDecompressedSize)
+}
+
+func (g *gzipCompressor) DecompressLimit(b []byte, limit int64) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// read one byte past the limit to detect bodies exceeding it
+	out, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(out)) > limit {
+		return nil, ErrDecompressedSize
+	}
+	return out, nil
+}
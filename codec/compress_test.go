@@ -0,0 +1,47 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	body := bytes.Repeat([]byte("embedding "), 1000)
+
+	b, err := Compress("gzip", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) >= len(body) {
+		t.Fatalf("Expected the body to be compressed, got %d bytes from %d", len(b), len(body))
+	}
+
+	out, err := Decompress("gzip", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, body) {
+		t.Fatal("Expected the decompressed body to match")
+	}
+
+	if _, err := Compress("br", body); err != ErrUnknownEncoding {
+		t.Fatalf("Expected %v, got %v", ErrUnknownEncoding, err)
+	}
+
+	// bodies decompressing past the limit are rejected
+	if _, err := DecompressLimit("gzip", b, int64(len(body)-1)); err != ErrDecompressedSize {
+		t.Fatalf("Expected %v, got %v", ErrDecompressedSize, err)
+	}
+	if out, err := DecompressLimit("gzip", b, int64(len(body))); err != nil || !bytes.Equal(out, body) {
+		t.Fatalf("Expected the body within the limit, got %v", err)
+	}
+
+	max := MaxDecompressedSize
+	MaxDecompressedSize = int64(len(body) - 1)
+	defer func() {
+		MaxDecompressedSize = max
+	}()
+	if _, err := Decompress("gzip", b); err != ErrDecompressedSize {
+		t.Fatalf("Expected %v, got %v", ErrDecompressedSize, err)
+	}
+}
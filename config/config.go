@@ -7,6 +7,7 @@ import (
 	"github.com/micro/go-micro/v3/config/loader"
 	"github.com/micro/go-micro/v3/config/reader"
 	"github.com/micro/go-micro/v3/config/source"
+	"github.com/micro/go-micro/v3/store"
 )
 
 // Config is an interface abstraction for dynamic configuration
@@ -25,6 +26,17 @@ type Config interface {
 	Sync() error
 	// Watch a value for changes
 	Watch(path ...string) (Watcher, error)
+	// Write a changeset to the loaded sources
+	Write(cs *source.ChangeSet, opts ...WriteOption) error
+	// History of recorded config revisions
+	History() ([]*Revision, error)
+	// Pin the config values to a previous revision
+	Pin(version string) error
+	// Unpin the config values and track the sources again
+	Unpin() error
+	// Rollback writes a previous revision back to the sources and applies it to
+	// the config, the sources which can't be written override it once they change
+	Rollback(version string, opts ...WriteOption) error
 }
 
 // Watcher is the config watcher
@@ -37,6 +49,8 @@ type Options struct {
 	Loader loader.Loader
 	Reader reader.Reader
 	Source []source.Source
+	// History stores every revision of the config
+	History store.Store
 
 	// for alternative data
 	Context context.Context
@@ -44,6 +58,13 @@ type Options struct {
 
 type Option func(o *Options)
 
+type WriteOptions struct {
+	// Author of the change
+	Author string
+}
+
+type WriteOption func(o *WriteOptions)
+
 // NewConfig returns new config
 func NewConfig(opts ...Option) (Config, error) {
 	return newConfig(opts...)
@@ -2,6 +2,9 @@ package config
 
 import (
 	"bytes"
+	"errors"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,6 +13,7 @@ import (
 	"github.com/micro/go-micro/v3/config/reader"
 	"github.com/micro/go-micro/v3/config/reader/json"
 	"github.com/micro/go-micro/v3/config/source"
+	"github.com/micro/go-micro/v3/logger"
 )
 
 type config struct {
@@ -21,6 +25,12 @@ type config struct {
 	snap *loader.Snapshot
 	// the current values
 	vals reader.Values
+	// all the loaded sources
+	sources []source.Source
+	// the revision the values are pinned to
+	pinned string
+	// checksum of the last recorded revision
+	recorded string
 }
 
 type watcher struct {
@@ -70,6 +80,9 @@ func (c *config) Init(opts ...Option) error {
 		return err
 	}
 
+	c.sources = append([]source.Source{}, c.opts.Source...)
+	c.record(c.snap, "")
+
 	return nil
 }
 
@@ -96,10 +109,14 @@ func (c *config) run() {
 			// save
 			c.snap = snap
 
-			// set values
-			c.vals, _ = c.opts.Reader.Values(snap.ChangeSet)
+			// set values unless pinned to a revision
+			if len(c.pinned) == 0 {
+				c.vals, _ = c.opts.Reader.Values(snap.ChangeSet)
+			}
 
 			c.Unlock()
+
+			c.record(snap, "")
 		}
 	}
 
@@ -153,6 +170,10 @@ func (c *config) Scan(v interface{}) error {
 
 // sync loads all the sources, calls the parser and updates the config
 func (c *config) Sync() error {
+	return c.sync("")
+}
+
+func (c *config) sync(author string) error {
 	if err := c.opts.Loader.Sync(); err != nil {
 		return err
 	}
@@ -162,10 +183,25 @@ func (c *config) Sync() error {
 		return err
 	}
 
+	if err := c.update(snap); err != nil {
+		return err
+	}
+
+	c.record(snap, author)
+
+	return nil
+}
+
+// update sets the snapshot and the values unless they're pinned
+func (c *config) update(snap *loader.Snapshot) error {
 	c.Lock()
 	defer c.Unlock()
 
 	c.snap = snap
+	if len(c.pinned) > 0 {
+		return nil
+	}
+
 	vals, err := c.opts.Reader.Values(snap.ChangeSet)
 	if err != nil {
 		return err
@@ -175,6 +211,34 @@ func (c *config) Sync() error {
 	return nil
 }
 
+// record saves the snapshot to the history if it has changed
+func (c *config) record(snap *loader.Snapshot, author string) {
+	if c.opts.History == nil || snap == nil || snap.ChangeSet == nil {
+		return
+	}
+
+	c.Lock()
+	if c.recorded == snap.ChangeSet.Checksum {
+		c.Unlock()
+		return
+	}
+	c.recorded = snap.ChangeSet.Checksum
+	c.Unlock()
+
+	rev := &Revision{
+		Version:   snap.Version,
+		Checksum:  snap.ChangeSet.Checksum,
+		Format:    snap.ChangeSet.Format,
+		Data:      snap.ChangeSet.Data,
+		Author:    author,
+		Timestamp: time.Now(),
+	}
+
+	if err := writeRevision(c.opts.History, rev); err != nil {
+		logger.Errorf("Error recording config revision %s: %v", rev.Version, err)
+	}
+}
+
 func (c *config) Close() error {
 	select {
 	case <-c.exit:
@@ -236,24 +300,136 @@ func (c *config) Load(sources ...source.Source) error {
 		return err
 	}
 
+	c.Lock()
+	c.sources = append(c.sources, sources...)
+	c.Unlock()
+
 	snap, err := c.opts.Loader.Snapshot()
 	if err != nil {
 		return err
 	}
 
+	if err := c.update(snap); err != nil {
+		return err
+	}
+
+	c.record(snap, "")
+
+	return nil
+}
+
+func (c *config) Write(cs *source.ChangeSet, opts ...WriteOption) error {
+	var options WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	c.RLock()
+	sources := c.sources
+	c.RUnlock()
+
+	var gerr []string
+
+	for _, s := range sources {
+		if err := s.Write(cs); err != nil {
+			gerr = append(gerr, s.String()+": "+err.Error())
+		}
+	}
+
+	if len(gerr) > 0 {
+		return errors.New("source write errors: " + strings.Join(gerr, "\n"))
+	}
+
+	return c.sync(options.Author)
+}
+
+func (c *config) History() ([]*Revision, error) {
+	if c.opts.History == nil {
+		return nil, ErrNoHistory
+	}
+	return listRevisions(c.opts.History)
+}
+
+func (c *config) Pin(version string) error {
+	if c.opts.History == nil {
+		return ErrNoHistory
+	}
+
+	rev, err := readRevision(c.opts.History, version)
+	if err != nil {
+		return err
+	}
+
+	vals, err := c.opts.Reader.Values(rev.ChangeSet())
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	c.pinned = version
+	c.vals = vals
+	c.Unlock()
+
+	return nil
+}
+
+func (c *config) Unpin() error {
 	c.Lock()
 	defer c.Unlock()
 
-	c.snap = snap
-	vals, err := c.opts.Reader.Values(snap.ChangeSet)
+	if len(c.pinned) == 0 {
+		return nil
+	}
+
+	vals, err := c.opts.Reader.Values(c.snap.ChangeSet)
 	if err != nil {
 		return err
 	}
+
+	c.pinned = ""
 	c.vals = vals
 
 	return nil
 }
 
+func (c *config) Rollback(version string, opts ...WriteOption) error {
+	if c.opts.History == nil {
+		return ErrNoHistory
+	}
+
+	rev, err := readRevision(c.opts.History, version)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Unpin(); err != nil {
+		return err
+	}
+
+	if err := c.Write(rev.ChangeSet(), opts...); err != nil {
+		return err
+	}
+
+	var options WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// the sources such as files and env can't be written so the revision is applied
+	// to the config directly, it holds until the sources change
+	snap := &loader.Snapshot{
+		ChangeSet: rev.ChangeSet(),
+		Version:   strconv.FormatInt(time.Now().UnixNano(), 10),
+	}
+	if err := c.update(snap); err != nil {
+		return err
+	}
+
+	c.record(snap, options.Author)
+
+	return nil
+}
+
 func (c *config) Watch(path ...string) (Watcher, error) {
 	value := c.Get(path...)
 
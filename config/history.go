@@ -0,0 +1,112 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/micro/go-micro/v3/config/source"
+	"github.com/micro/go-micro/v3/store"
+)
+
+const historyPrefix = "config/history/"
+
+var (
+	// ErrNoHistory is returned when no history store has been configured
+	ErrNoHistory = errors.New("config history not enabled")
+	// ErrRevisionNotFound is returned when a revision does not exist in the history
+	ErrRevisionNotFound = errors.New("config revision not found")
+)
+
+// Revision is a snapshot of the config recorded in the history
+type Revision struct {
+	// Version of the snapshot
+	Version string `json:"version"`
+	// Checksum of the merged data
+	Checksum string `json:"checksum"`
+	// Format of the data e.g json
+	Format string `json:"format"`
+	// Data is the merged config
+	Data []byte `json:"data"`
+	// Author of the change, only known for writes
+	Author string `json:"author,omitempty"`
+	// Timestamp the change was observed
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ChangeSet returns the revision as a changeset
+func (r *Revision) ChangeSet() *source.ChangeSet {
+	cs := &source.ChangeSet{
+		Data:      r.Data,
+		Format:    r.Format,
+		Source:    "history",
+		Timestamp: r.Timestamp,
+	}
+	cs.Checksum = cs.Sum()
+	return cs
+}
+
+// writeRevision saves a revision to the history store
+func writeRevision(s store.Store, rev *Revision) error {
+	b, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+
+	return s.Write(&store.Record{
+		Key:   historyPrefix + rev.Version,
+		Value: b,
+		Metadata: map[string]interface{}{
+			"author":   rev.Author,
+			"checksum": rev.Checksum,
+		},
+	})
+}
+
+// readRevision gets a single revision from the history store
+func readRevision(s store.Store, version string) (*Revision, error) {
+	recs, err := s.Read(historyPrefix + version)
+	if err == store.ErrNotFound {
+		return nil, ErrRevisionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, ErrRevisionNotFound
+	}
+
+	var rev Revision
+	if err := json.Unmarshal(recs[0].Value, &rev); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// listRevisions returns all the revisions in the history store, oldest first
+func listRevisions(s store.Store) ([]*Revision, error) {
+	recs, err := s.Read(historyPrefix, store.ReadPrefix())
+	if err == store.ErrNotFound {
+		return []*Revision{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	revs := make([]*Revision, 0, len(recs))
+	for _, r := range recs {
+		var rev Revision
+		if err := json.Unmarshal(r.Value, &rev); err != nil {
+			return nil, err
+		}
+		revs = append(revs, &rev)
+	}
+
+	sort.Slice(revs, func(i, j int) bool {
+		if len(revs[i].Version) != len(revs[j].Version) {
+			return len(revs[i].Version) < len(revs[j].Version)
+		}
+		return revs[i].Version < revs[j].Version
+	})
+
+	return revs, nil
+}
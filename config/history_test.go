@@ -0,0 +1,110 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/micro/go-micro/v3/config/source"
+	"github.com/micro/go-micro/v3/config/source/file"
+	"github.com/micro/go-micro/v3/config/source/memory"
+	mstore "github.com/micro/go-micro/v3/store/memory"
+)
+
+func TestConfigHistory(t *testing.T) {
+	src := memory.NewSource(memory.WithJSON([]byte(`{"foo": "bar"}`)))
+
+	conf, err := NewConfig(WithSource(src), WithHistory(mstore.NewStore()))
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	defer conf.Close()
+
+	if err := conf.Write(&source.ChangeSet{Data: []byte(`{"foo": "baz"}`), Format: "json"}, WithAuthor("john")); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	equalS(t, conf.Get("foo").String(""), "baz")
+
+	revs, err := conf.History()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if len(revs) != 2 {
+		t.Fatalf("Expected 2 revisions but got %d", len(revs))
+	}
+	equalS(t, revs[1].Author, "john")
+
+	// pinning ignores the sources
+	if err := conf.Pin(revs[0].Version); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	equalS(t, conf.Get("foo").String(""), "bar")
+
+	if err := conf.Unpin(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	equalS(t, conf.Get("foo").String(""), "baz")
+
+	// rolling back writes to the sources
+	if err := conf.Rollback(revs[0].Version, WithAuthor("jane")); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	equalS(t, conf.Get("foo").String(""), "bar")
+
+	revs, err = conf.History()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if len(revs) != 3 {
+		t.Fatalf("Expected 3 revisions but got %d", len(revs))
+	}
+	equalS(t, revs[2].Author, "jane")
+
+	if err := conf.Pin("1"); err != ErrRevisionNotFound {
+		t.Fatalf("Expected %v but got %v", ErrRevisionNotFound, err)
+	}
+}
+
+func TestConfigRollbackReadOnly(t *testing.T) {
+	fh := createFileForTest(t)
+	path := fh.Name()
+	fh.Close()
+	defer os.Remove(path)
+
+	conf, err := NewConfig(WithSource(file.NewSource(file.WithPath(path))), WithHistory(mstore.NewStore()))
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	defer conf.Close()
+	equalS(t, conf.Get("foo").String(""), "bar")
+
+	if err := ioutil.WriteFile(path, []byte(`{"foo": "baz"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := conf.Sync(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	equalS(t, conf.Get("foo").String(""), "baz")
+
+	revs, err := conf.History()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if len(revs) != 2 {
+		t.Fatalf("Expected 2 revisions but got %d", len(revs))
+	}
+
+	// the file source can't be written, the revision is applied to the config
+	if err := conf.Rollback(revs[0].Version, WithAuthor("jane")); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	equalS(t, conf.Get("foo").String(""), "bar")
+
+	revs, err = conf.History()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if len(revs) != 3 || revs[2].Author != "jane" {
+		t.Fatalf("Expected the rollback to be recorded, got %d revisions", len(revs))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/micro/go-micro/v3/config/loader"
 	"github.com/micro/go-micro/v3/config/reader"
 	"github.com/micro/go-micro/v3/config/source"
+	"github.com/micro/go-micro/v3/store"
 )
 
 // WithLoader sets the loader for manager config
@@ -26,3 +27,17 @@ func WithReader(r reader.Reader) Option {
 		o.Reader = r
 	}
 }
+
+// WithHistory records every revision of the config in the store
+func WithHistory(s store.Store) Option {
+	return func(o *Options) {
+		o.History = s
+	}
+}
+
+// WithAuthor sets the author of a write
+func WithAuthor(a string) WriteOption {
+	return func(o *WriteOptions) {
+		o.Author = a
+	}
+}
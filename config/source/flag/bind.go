@@ -0,0 +1,137 @@
+package flag
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotStruct is returned when binding a value which isn't a pointer to a struct
+	ErrNotStruct = errors.New("value must be a pointer to a struct")
+
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// Bind defines a flag for each field of the struct pointed to by v, with the value
+// of the field as its default. The flags are named by the json tags of the fields,
+// nested with dots e.g --database.host, and described by their usage tag.
+//
+// The flags don't set the struct, load the source into the config and scan the
+// config into it so the flags set on the command line override the other sources.
+func Bind(fs *flag.FlagSet, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+	return bind(fs, "", rv.Elem())
+}
+
+func bind(fs *flag.FlagSet, prefix string, rv reflect.Value) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+
+		value := rv.Field(i)
+		if value.Kind() == reflect.Ptr && value.Type().Elem().Kind() == reflect.Struct {
+			if value.IsNil() {
+				value = reflect.New(value.Type().Elem())
+			}
+			value = value.Elem()
+		}
+
+		// embedded structs are flattened as they are by the json encoding
+		if field.Anonymous && len(tag) == 0 && value.Kind() == reflect.Struct {
+			if err := bind(fs, prefix, value); err != nil {
+				return err
+			}
+			continue
+		}
+		// skip the unexported fields
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+
+		name := tag
+		if len(name) == 0 {
+			name = strings.ToLower(field.Name)
+		}
+		name = prefix + name
+
+		if value.Kind() == reflect.Struct && value.Type() != durationType {
+			if err := bind(fs, name+".", value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if fs.Lookup(name) != nil {
+			return fmt.Errorf("flag %s already defined", name)
+		}
+		if err := define(fs, name, field.Tag.Get("usage"), value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// define the flag of the value type with the value as default
+func define(fs *flag.FlagSet, name, usage string, value reflect.Value) error {
+	if value.Type() == durationType {
+		fs.Duration(name, time.Duration(value.Int()), usage)
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		fs.String(name, value.String(), usage)
+	case reflect.Bool:
+		fs.Bool(name, value.Bool(), usage)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fs.Int64(name, value.Int(), usage)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fs.Uint64(name, value.Uint(), usage)
+	case reflect.Float32, reflect.Float64:
+		fs.Float64(name, value.Float(), usage)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s of flag %s", value.Type(), name)
+		}
+		Slice(fs, name, toStrings(value), usage)
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String || value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s of flag %s", value.Type(), name)
+		}
+		Map(fs, name, toMap(value), usage)
+	default:
+		return fmt.Errorf("unsupported type %s of flag %s", value.Type(), name)
+	}
+
+	return nil
+}
+
+func toStrings(value reflect.Value) []string {
+	s := make([]string, value.Len())
+	for i := range s {
+		s[i] = value.Index(i).String()
+	}
+	return s
+}
+
+func toMap(value reflect.Value) map[string]string {
+	m := make(map[string]string, value.Len())
+	iter := value.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = iter.Value().String()
+	}
+	return m
+}
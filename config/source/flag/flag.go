@@ -3,47 +3,56 @@ package flag
 import (
 	"errors"
 	"flag"
-	"github.com/imdario/mergo"
-	"github.com/micro/go-micro/v3/config/source"
 	"strings"
 	"time"
+
+	"github.com/imdario/mergo"
+	"github.com/micro/go-micro/v3/config/source"
 )
 
 type flagsrc struct {
 	opts source.Options
+	set  *flag.FlagSet
 }
 
 func (fs *flagsrc) Read() (*source.ChangeSet, error) {
-	if !flag.Parsed() {
+	if !fs.set.Parsed() {
 		return nil, errors.New("flags not parsed")
 	}
 
 	var changes map[string]interface{}
+	var merr error
 
 	visitFn := func(f *flag.Flag) {
-		n := strings.ToLower(f.Name)
-		keys := strings.FieldsFunc(n, split)
+		if merr != nil {
+			return
+		}
+
+		keys := keys(f.Name)
 		reverse(keys)
 
 		tmp := make(map[string]interface{})
 		for i, k := range keys {
 			if i == 0 {
-				tmp[k] = f.Value
+				tmp[k] = value(f)
 				continue
 			}
 
 			tmp = map[string]interface{}{k: tmp}
 		}
 
-		mergo.Map(&changes, tmp) // need to sort error handling
-		return
+		merr = mergo.Map(&changes, tmp)
 	}
 
 	unset, ok := fs.opts.Context.Value(includeUnsetKey{}).(bool)
 	if ok && unset {
-		flag.VisitAll(visitFn)
+		fs.set.VisitAll(visitFn)
 	} else {
-		flag.Visit(visitFn)
+		fs.set.Visit(visitFn)
+	}
+
+	if merr != nil {
+		return nil, merr
 	}
 
 	b, err := fs.opts.Encoder.Encode(changes)
@@ -62,6 +71,24 @@ func (fs *flagsrc) Read() (*source.ChangeSet, error) {
 	return cs, nil
 }
 
+// keys returns the nested keys of the flag name, dots are the delimiters of the
+// names containing them so the keys can contain hyphens and underscores
+func keys(name string) []string {
+	n := strings.ToLower(name)
+	if strings.Contains(n, ".") {
+		return strings.FieldsFunc(n, func(r rune) bool { return r == '.' })
+	}
+	return strings.FieldsFunc(n, split)
+}
+
+// value returns the typed value of the flag e.g a slice of strings for the slice flags
+func value(f *flag.Flag) interface{} {
+	if g, ok := f.Value.(flag.Getter); ok {
+		return g.Get()
+	}
+	return f.Value.String()
+}
+
 func split(r rune) bool {
 	return r == '-' || r == '_'
 }
@@ -86,16 +113,26 @@ func (fs *flagsrc) String() string {
 }
 
 // NewSource returns a config source for integrating parsed flags.
-// Hyphens are delimiters for nesting, and all keys are lowercased.
+// Dots are delimiters for nesting, or hyphens and underscores for the names
+// without dots, and all keys are lowercased.
 //
 // Example:
-//      dbhost := flag.String("database-host", "localhost", "the db host name")
+//      dbhost := flag.String("database.host", "localhost", "the db host name")
+//      dbuser := flag.String("database-user", "root", "the db user")
 //
 //      {
 //          "database": {
-//              "host": "localhost"
+//              "host": "localhost",
+//              "user": "root"
 //          }
 //      }
 func NewSource(opts ...source.Option) source.Source {
-	return &flagsrc{opts: source.NewOptions(opts...)}
+	options := source.NewOptions(opts...)
+
+	set := flag.CommandLine
+	if s, ok := options.Context.Value(flagSetKey{}).(*flag.FlagSet); ok && s != nil {
+		set = s
+	}
+
+	return &flagsrc{opts: options, set: set}
 }
@@ -3,7 +3,12 @@ package flag
 import (
 	"encoding/json"
 	"flag"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/config"
+	"github.com/micro/go-micro/v3/config/source/memory"
 )
 
 var (
@@ -66,3 +71,84 @@ func TestFlagsrc_ReadAll(t *testing.T) {
 		t.Errorf("expected %v got %v", *dbuser, actualDB["user"])
 	}
 }
+
+type testConfig struct {
+	Database struct {
+		Host     string        `json:"host" usage:"the db host"`
+		Port     int           `json:"port"`
+		MaxConns int           `json:"max_conns"`
+		Timeout  time.Duration `json:"timeout"`
+	} `json:"database"`
+	Hosts  []string          `json:"hosts"`
+	Labels map[string]string `json:"labels"`
+	Debug  bool              `json:"debug"`
+	Ignore string            `json:"-"`
+}
+
+func TestFlagsrc_Bind(t *testing.T) {
+	var defaults testConfig
+	defaults.Database.Host = "localhost"
+	defaults.Database.Port = 5432
+	defaults.Hosts = []string{"a"}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := Bind(fs, &defaults); err != nil {
+		t.Fatal(err)
+	}
+	if fs.Lookup("ignore") != nil {
+		t.Fatal("Expected the ignored field not to be bound")
+	}
+	if f := fs.Lookup("database.host"); f == nil || f.DefValue != "localhost" || f.Usage != "the db host" {
+		t.Fatalf("Expected the database.host flag, got %+v", f)
+	}
+
+	if err := fs.Parse([]string{
+		"--database.port=6543",
+		"--database.max_conns=10",
+		"--database.timeout=5s",
+		"--hosts=b,c", "--hosts=d",
+		"--labels=env=prod", "--labels=tier=web",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the flags override the values of the other sources
+	conf, err := config.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conf.Load(
+		memory.NewSource(memory.WithJSON([]byte(`{"database": {"host": "db.local", "port": 5432}, "debug": true}`))),
+		NewSource(FlagSet(fs)),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	var actual testConfig
+	if err := conf.Scan(&actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.Database.Host != "db.local" || actual.Database.Port != 6543 || actual.Database.MaxConns != 10 {
+		t.Fatalf("Unexpected database config %+v", actual.Database)
+	}
+	if actual.Database.Timeout != 5*time.Second {
+		t.Fatalf("Expected a 5s timeout, got %v", actual.Database.Timeout)
+	}
+	if strings.Join(actual.Hosts, ",") != "b,c,d" {
+		t.Fatalf("Expected the hosts b,c,d, got %v", actual.Hosts)
+	}
+	if actual.Labels["env"] != "prod" || actual.Labels["tier"] != "web" {
+		t.Fatalf("Unexpected labels %v", actual.Labels)
+	}
+	if !actual.Debug {
+		t.Fatal("Expected debug to be set by the other source")
+	}
+	if v := conf.Get("labels", "env").String(""); v != "prod" {
+		t.Fatalf("Expected the labels to be nested keys, got %q", v)
+	}
+
+	if err := Bind(fs, defaults); err != ErrNotStruct {
+		t.Fatalf("Expected %v, got %v", ErrNotStruct, err)
+	}
+}
@@ -2,11 +2,13 @@ package flag
 
 import (
 	"context"
+	"flag"
 
 	"github.com/micro/go-micro/v3/config/source"
 )
 
 type includeUnsetKey struct{}
+type flagSetKey struct{}
 
 // IncludeUnset toggles the loading of unset flags and their respective default values.
 // Default behavior is to ignore any unset flags.
@@ -15,6 +17,16 @@ func IncludeUnset(b bool) source.Option {
 		if o.Context == nil {
 			o.Context = context.Background()
 		}
-		o.Context = context.WithValue(o.Context, includeUnsetKey{}, true)
+		o.Context = context.WithValue(o.Context, includeUnsetKey{}, b)
+	}
+}
+
+// FlagSet sets the flag set the config is read from, the command line flags by default
+func FlagSet(fs *flag.FlagSet) source.Option {
+	return func(o *source.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, flagSetKey{}, fs)
 	}
 }
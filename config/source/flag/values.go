@@ -0,0 +1,109 @@
+package flag
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sliceValue is a flag of comma separated values which can be repeated,
+// setting it replaces the default value
+type sliceValue struct {
+	value *[]string
+	set   bool
+}
+
+func (s *sliceValue) String() string {
+	if s.value == nil {
+		return ""
+	}
+	return strings.Join(*s.value, ",")
+}
+
+func (s *sliceValue) Set(v string) error {
+	if !s.set {
+		*s.value = nil
+		s.set = true
+	}
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); len(p) > 0 {
+			*s.value = append(*s.value, p)
+		}
+	}
+	return nil
+}
+
+func (s *sliceValue) Get() interface{} {
+	return *s.value
+}
+
+// mapValue is a flag of comma separated key=value pairs which can be repeated,
+// the pairs set are added to the default value
+type mapValue struct {
+	value *map[string]string
+}
+
+func (m *mapValue) String() string {
+	if m.value == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*m.value))
+	for k, v := range *m.value {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (m *mapValue) Set(v string) error {
+	if *m.value == nil {
+		*m.value = make(map[string]string)
+	}
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); len(p) == 0 {
+			continue
+		}
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return fmt.Errorf("invalid pair %q, expected key=value", p)
+		}
+		(*m.value)[kv[0]] = kv[1]
+	}
+	return nil
+}
+
+func (m *mapValue) Get() interface{} {
+	return *m.value
+}
+
+// SliceVar defines a flag of comma separated values which can be repeated
+// e.g --hosts=a,b --hosts=c, read as a list by the source
+func SliceVar(fs *flag.FlagSet, p *[]string, name string, value []string, usage string) {
+	*p = append([]string(nil), value...)
+	fs.Var(&sliceValue{value: p}, name, usage)
+}
+
+// Slice defines a flag of comma separated values and returns the address of its value
+func Slice(fs *flag.FlagSet, name string, value []string, usage string) *[]string {
+	p := new([]string)
+	SliceVar(fs, p, name, value, usage)
+	return p
+}
+
+// MapVar defines a flag of key=value pairs which can be repeated e.g
+// --labels=env=prod,tier=web --labels=zone=a, read as nested keys by the source
+func MapVar(fs *flag.FlagSet, p *map[string]string, name string, value map[string]string, usage string) {
+	*p = make(map[string]string, len(value))
+	for k, v := range value {
+		(*p)[k] = v
+	}
+	fs.Var(&mapValue{value: p}, name, usage)
+}
+
+// Map defines a flag of key=value pairs and returns the address of its value
+func Map(fs *flag.FlagSet, name string, value map[string]string, usage string) *map[string]string {
+	p := new(map[string]string)
+	MapVar(fs, p, name, value, usage)
+	return p
+}
@@ -0,0 +1,290 @@
+// Package chaos injects latency, errors and dropped requests and messages into services
+// to test how they cope with failures, e.g their retry and circuit breaker policies in staging
+package chaos
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/config"
+	"github.com/micro/go-micro/v3/config/reader"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/server"
+)
+
+// DefaultPath of the chaos config, e.g
+// {"chaos": {"enabled": true, "faults": [{"service": "foo", "latency": "100ms", "error_rate": 0.1}]}}
+var DefaultPath = []string{"chaos"}
+
+// Fault injected into the requests and messages matching its target, the empty
+// fields of the target match everything
+type Fault struct {
+	// Service the requests are made to or served by
+	Service string `json:"service,omitempty"`
+	// Endpoint of the requests
+	Endpoint string `json:"endpoint,omitempty"`
+	// Topic of the messages
+	Topic string `json:"topic,omitempty"`
+	// Latency added to the requests and messages
+	Latency time.Duration `json:"-"`
+	// Jitter is the max random latency added on top of the latency
+	Jitter time.Duration `json:"-"`
+	// ErrorRate is the fraction of the requests failed e.g 0.1 for 10%
+	ErrorRate float64 `json:"error_rate,omitempty"`
+	// ErrorCode of the failed requests, 500 if not set
+	ErrorCode int32 `json:"error_code,omitempty"`
+	// DropRate is the fraction of the requests and messages dropped, dropped
+	// requests time out and dropped messages are never processed
+	DropRate float64 `json:"drop_rate,omitempty"`
+}
+
+// UnmarshalJSON reads the latency and jitter as durations e.g "100ms"
+func (f *Fault) UnmarshalJSON(b []byte) error {
+	type fault Fault
+	v := struct {
+		*fault
+		Latency string `json:"latency"`
+		Jitter  string `json:"jitter"`
+	}{fault: (*fault)(f)}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	var err error
+	if len(v.Latency) > 0 {
+		if f.Latency, err = time.ParseDuration(v.Latency); err != nil {
+			return err
+		}
+	}
+	if len(v.Jitter) > 0 {
+		if f.Jitter, err = time.ParseDuration(v.Jitter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Fault) matches(service, endpoint, topic string) bool {
+	return (len(f.Service) == 0 || f.Service == service) &&
+		(len(f.Endpoint) == 0 || f.Endpoint == endpoint) &&
+		(len(f.Topic) == 0 || f.Topic == topic)
+}
+
+// Chaos injects the faults into the requests and messages of the wrapped servers and clients
+type Chaos struct {
+	sync.RWMutex
+	enabled bool
+	faults  []*Fault
+}
+
+// New returns chaos injecting the faults, the first fault matching a request or message is injected
+func New(faults ...*Fault) *Chaos {
+	return &Chaos{
+		enabled: true,
+		faults:  faults,
+	}
+}
+
+// Enable or disable the injection of the faults
+func (c *Chaos) Enable(v bool) {
+	c.Lock()
+	c.enabled = v
+	c.Unlock()
+}
+
+// Set the faults which are injected
+func (c *Chaos) Set(faults ...*Fault) {
+	c.Lock()
+	c.faults = faults
+	c.Unlock()
+}
+
+func (c *Chaos) load(v reader.Value) error {
+	var conf struct {
+		Enabled bool     `json:"enabled"`
+		Faults  []*Fault `json:"faults"`
+	}
+	if err := v.Scan(&conf); err != nil {
+		return err
+	}
+
+	c.Lock()
+	c.enabled = conf.Enabled
+	c.faults = conf.Faults
+	c.Unlock()
+	return nil
+}
+
+// Watch the config at the path, or the default path if it's not set, for the faults which
+// are injected so they can be toggled at runtime. The injection is disabled until it's
+// enabled in the config.
+func (c *Chaos) Watch(conf config.Config, path ...string) error {
+	if len(path) == 0 {
+		path = DefaultPath
+	}
+
+	if err := c.load(conf.Get(path...)); err != nil {
+		return err
+	}
+
+	w, err := conf.Watch(path...)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			v, err := w.Next()
+			if err != nil {
+				return
+			}
+			if err := c.load(v); err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error loading the chaos config: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// fault returns the fault matching the request or message
+func (c *Chaos) fault(service, endpoint, topic string) *Fault {
+	c.RLock()
+	defer c.RUnlock()
+
+	if !c.enabled {
+		return nil
+	}
+	for _, f := range c.faults {
+		if f.matches(service, endpoint, topic) {
+			return f
+		}
+	}
+	return nil
+}
+
+// inject the fault, it returns whether the request or message is dropped or the error it fails with
+func (c *Chaos) inject(ctx context.Context, f *Fault) (bool, error) {
+	delay := f.Latency
+	if f.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(f.Jitter)))
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+
+	if f.DropRate > 0 && rand.Float64() < f.DropRate {
+		return true, nil
+	}
+
+	if f.ErrorRate > 0 && rand.Float64() < f.ErrorRate {
+		code := f.ErrorCode
+		if code == 0 {
+			code = 500
+		}
+		return false, errors.New("go.micro.chaos", "injected fault", code)
+	}
+
+	return false, nil
+}
+
+// drop a request, it times out at the deadline of the context
+func drop(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); ok {
+		<-ctx.Done()
+	}
+	return errors.Timeout("go.micro.chaos", "request dropped")
+}
+
+// HandlerWrapper injects the faults into the requests served
+func (c *Chaos) HandlerWrapper() server.HandlerWrapper {
+	return func(fn server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			f := c.fault(req.Service(), req.Endpoint(), "")
+			if f == nil {
+				return fn(ctx, req, rsp)
+			}
+
+			dropped, err := c.inject(ctx, f)
+			if dropped {
+				return drop(ctx)
+			} else if err != nil {
+				return err
+			}
+			return fn(ctx, req, rsp)
+		}
+	}
+}
+
+// SubscriberWrapper injects the faults into the messages processed, dropped messages are acked
+func (c *Chaos) SubscriberWrapper() server.SubscriberWrapper {
+	return func(fn server.SubscriberFunc) server.SubscriberFunc {
+		return func(ctx context.Context, msg server.Message) error {
+			f := c.fault("", "", msg.Topic())
+			if f == nil {
+				return fn(ctx, msg)
+			}
+
+			dropped, err := c.inject(ctx, f)
+			if dropped {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			return fn(ctx, msg)
+		}
+	}
+}
+
+type chaosClient struct {
+	client.Client
+	chaos *Chaos
+}
+
+func (c *chaosClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	f := c.chaos.fault(req.Service(), req.Endpoint(), "")
+	if f == nil {
+		return c.Client.Call(ctx, req, rsp, opts...)
+	}
+
+	dropped, err := c.chaos.inject(ctx, f)
+	if dropped {
+		return drop(ctx)
+	} else if err != nil {
+		return err
+	}
+	return c.Client.Call(ctx, req, rsp, opts...)
+}
+
+func (c *chaosClient) Publish(ctx context.Context, msg client.Message, opts ...client.PublishOption) error {
+	f := c.chaos.fault("", "", msg.Topic())
+	if f == nil {
+		return c.Client.Publish(ctx, msg, opts...)
+	}
+
+	dropped, err := c.chaos.inject(ctx, f)
+	if dropped {
+		// the message is lost without the publisher knowing
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return c.Client.Publish(ctx, msg, opts...)
+}
+
+// ClientWrapper injects the faults into the calls made and messages published
+func (c *Chaos) ClientWrapper() client.Wrapper {
+	return func(cl client.Client) client.Client {
+		return &chaosClient{Client: cl, chaos: c}
+	}
+}
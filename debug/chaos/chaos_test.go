@@ -0,0 +1,85 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/codec"
+	"github.com/micro/go-micro/v3/config"
+	"github.com/micro/go-micro/v3/config/source/memory"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/server"
+)
+
+type testRequest struct {
+	endpoint string
+}
+
+func (r *testRequest) Service() string           { return "test" }
+func (r *testRequest) Method() string            { return r.endpoint }
+func (r *testRequest) Endpoint() string          { return r.endpoint }
+func (r *testRequest) ContentType() string       { return "application/json" }
+func (r *testRequest) Header() map[string]string { return nil }
+func (r *testRequest) Body() interface{}         { return nil }
+func (r *testRequest) Read() ([]byte, error)     { return nil, nil }
+func (r *testRequest) Codec() codec.Reader       { return nil }
+func (r *testRequest) Stream() bool              { return false }
+
+func TestHandlerWrapper(t *testing.T) {
+	c := New(
+		&Fault{Service: "test", Endpoint: "Foo.Error", ErrorRate: 1, ErrorCode: 503},
+		&Fault{Service: "test", Endpoint: "Foo.Drop", DropRate: 1},
+		&Fault{Service: "test", Endpoint: "Foo.Slow", Latency: 20 * time.Millisecond},
+	)
+
+	var calls int
+	h := c.HandlerWrapper()(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		calls++
+		return nil
+	})
+
+	err := h(context.Background(), &testRequest{endpoint: "Foo.Error"}, nil)
+	if verr, ok := err.(*errors.Error); !ok || verr.Code != 503 {
+		t.Fatalf("Expected an injected error got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = h(ctx, &testRequest{endpoint: "Foo.Drop"}, nil)
+	if verr, ok := err.(*errors.Error); !ok || verr.Code != 408 || ctx.Err() == nil {
+		t.Fatalf("Expected the request to time out got %v", err)
+	}
+
+	started := time.Now()
+	if err := h(context.Background(), &testRequest{endpoint: "Foo.Slow"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(started) < 20*time.Millisecond || calls != 1 {
+		t.Fatalf("Expected the request to be delayed")
+	}
+
+	c.Enable(false)
+	if err := h(context.Background(), &testRequest{endpoint: "Foo.Error"}, nil); err != nil || calls != 2 {
+		t.Fatalf("Expected no fault when disabled got %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	conf, err := config.NewConfig(config.WithSource(memory.NewSource(memory.WithJSON([]byte(
+		`{"chaos": {"enabled": true, "faults": [{"service": "test", "latency": "100ms", "jitter": "10ms", "drop_rate": 0.5}]}}`,
+	)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := new(Chaos)
+	if err := c.Watch(conf); err != nil {
+		t.Fatal(err)
+	}
+
+	f := c.fault("test", "Foo.Bar", "")
+	if f == nil || f.Latency != 100*time.Millisecond || f.Jitter != 10*time.Millisecond || f.DropRate != 0.5 {
+		t.Fatalf("Expected the fault from the config got %+v", f)
+	}
+}
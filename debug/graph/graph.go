@@ -0,0 +1,256 @@
+// Package graph aggregates the calls observed between services, from the traces or the
+// client wrapper, into a dependency graph with the call and error rates of every edge
+package graph
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/trace"
+)
+
+var (
+	// DefaultWindow the rates of the edges are computed over
+	DefaultWindow = time.Minute
+)
+
+// Edge of the graph, the calls made by a service to an endpoint of another
+type Edge struct {
+	// Source is the calling service, blank when it's unknown e.g. for the api
+	Source string `json:"source"`
+	// Target is the service called
+	Target string `json:"target"`
+	// Endpoint called e.g Foo.Bar
+	Endpoint string `json:"endpoint"`
+	// Calls is the total number of calls
+	Calls uint64 `json:"calls"`
+	// Errors is the total number of failed calls
+	Errors uint64 `json:"errors"`
+	// Rate is the number of calls per second over the window
+	Rate float64 `json:"rate"`
+	// ErrorRate is the ratio of the calls which failed over the window
+	ErrorRate float64 `json:"error_rate"`
+	// Latency is the mean latency of the calls over the window
+	Latency time.Duration `json:"latency"`
+	// LastSeen is when the last call started
+	LastSeen time.Time `json:"last_seen"`
+}
+
+type key struct {
+	source, target, endpoint string
+}
+
+// bucket holds the calls started in a second
+type bucket struct {
+	calls   uint64
+	errors  uint64
+	latency time.Duration
+}
+
+type edge struct {
+	calls   uint64
+	errors  uint64
+	last    time.Time
+	buckets map[int64]*bucket
+}
+
+// Graph of the calls between services
+type Graph struct {
+	opts Options
+
+	sync.RWMutex
+	edges map[key]*edge
+	// spans already recorded, by id
+	spans map[string]time.Time
+}
+
+// New returns an empty graph
+func New(opts ...Option) *Graph {
+	options := Options{
+		Window: DefaultWindow,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Graph{
+		opts:  options,
+		edges: make(map[key]*edge),
+		spans: make(map[string]time.Time),
+	}
+}
+
+// Record a call of the source to the endpoint of the target
+func (g *Graph) Record(source, target, endpoint string, started time.Time, d time.Duration, err error) {
+	g.Lock()
+	g.record(source, target, endpoint, started, d, err != nil)
+	g.Unlock()
+}
+
+func (g *Graph) record(source, target, endpoint string, started time.Time, d time.Duration, failed bool) {
+	k := key{source, target, endpoint}
+	e, ok := g.edges[k]
+	if !ok {
+		e = &edge{buckets: make(map[int64]*bucket)}
+		g.edges[k] = e
+	}
+
+	e.calls++
+	if failed {
+		e.errors++
+	}
+	if started.After(e.last) {
+		e.last = started
+	}
+
+	sec := started.Unix()
+	b, ok := e.buckets[sec]
+	if !ok {
+		b = new(bucket)
+		e.buckets[sec] = b
+	}
+	b.calls++
+	b.latency += d
+	if failed {
+		b.errors++
+	}
+}
+
+// RecordSpans records the calls of the outbound spans, the source of a call is the
+// service of its parent span. Spans are only recorded once and those older than the
+// window are ignored, so the spans read from a tracer can be recorded periodically.
+func (g *Graph) RecordSpans(spans []*trace.Span) {
+	byId := make(map[string]*trace.Span, len(spans))
+	for _, s := range spans {
+		byId[s.Id] = s
+	}
+
+	oldest := time.Now().Add(-g.opts.Window)
+
+	g.Lock()
+	defer g.Unlock()
+
+	// forget the spans which can no longer be recorded
+	for id, started := range g.spans {
+		if started.Before(oldest) {
+			delete(g.spans, id)
+		}
+	}
+
+	for _, s := range spans {
+		if s.Type != trace.SpanTypeRequestOutbound || len(s.Service) == 0 {
+			continue
+		}
+		if s.Started.Before(oldest) {
+			continue
+		}
+		if _, ok := g.spans[s.Id]; ok {
+			continue
+		}
+		g.spans[s.Id] = s.Started
+
+		var source string
+		if p, ok := byId[s.Parent]; ok {
+			source = p.Service
+		}
+
+		endpoint := strings.TrimPrefix(s.Name, s.Service+".")
+		_, failed := s.Metadata["error"]
+
+		g.record(source, s.Service, endpoint, s.Started, s.Duration, failed)
+	}
+}
+
+// Read the edges of the graph sorted by source, target and endpoint
+func (g *Graph) Read(opts ...ReadOption) []*Edge {
+	var options ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	now := time.Now()
+	oldest := now.Add(-g.opts.Window).Unix()
+
+	g.Lock()
+	defer g.Unlock()
+
+	edges := make([]*Edge, 0, len(g.edges))
+
+	for k, e := range g.edges {
+		if len(options.Service) > 0 && k.source != options.Service && k.target != options.Service {
+			continue
+		}
+
+		edge := &Edge{
+			Source:   k.source,
+			Target:   k.target,
+			Endpoint: k.endpoint,
+			Calls:    e.calls,
+			Errors:   e.errors,
+			LastSeen: e.last,
+		}
+
+		var calls, errors uint64
+		var latency time.Duration
+		for sec, b := range e.buckets {
+			if sec < oldest {
+				delete(e.buckets, sec)
+				continue
+			}
+			calls += b.calls
+			errors += b.errors
+			latency += b.latency
+		}
+
+		if calls > 0 {
+			edge.Rate = float64(calls) / g.opts.Window.Seconds()
+			edge.ErrorRate = float64(errors) / float64(calls)
+			edge.Latency = latency / time.Duration(calls)
+		}
+
+		edges = append(edges, edge)
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Source != edges[j].Source {
+			return edges[i].Source < edges[j].Source
+		}
+		if edges[i].Target != edges[j].Target {
+			return edges[i].Target < edges[j].Target
+		}
+		return edges[i].Endpoint < edges[j].Endpoint
+	})
+
+	return edges
+}
+
+// Services returns the services of the graph sorted by name
+func (g *Graph) Services() []string {
+	g.RLock()
+	defer g.RUnlock()
+
+	seen := make(map[string]bool)
+	for k := range g.edges {
+		if len(k.source) > 0 {
+			seen[k.source] = true
+		}
+		seen[k.target] = true
+	}
+
+	services := make([]string, 0, len(seen))
+	for s := range seen {
+		services = append(services, s)
+	}
+	sort.Strings(services)
+	return services
+}
+
+// Reset the graph
+func (g *Graph) Reset() {
+	g.Lock()
+	g.edges = make(map[key]*edge)
+	g.spans = make(map[string]time.Time)
+	g.Unlock()
+}
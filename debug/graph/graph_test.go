@@ -0,0 +1,98 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/client/mock"
+	"github.com/micro/go-micro/v3/debug/trace"
+)
+
+func TestGraph(t *testing.T) {
+	g := New(Window(time.Second * 10))
+
+	now := time.Now()
+	g.Record("api", "foo", "Foo.Bar", now, time.Millisecond*10, nil)
+	g.Record("api", "foo", "Foo.Bar", now, time.Millisecond*30, errors.New("boom"))
+	g.Record("foo", "bar", "Bar.Baz", now, time.Millisecond, nil)
+	// calls older than the window only count in the totals
+	g.Record("foo", "bar", "Bar.Baz", now.Add(-time.Minute), time.Millisecond, nil)
+
+	edges := g.Read()
+	if len(edges) != 2 {
+		t.Fatalf("Expected 2 edges got %d", len(edges))
+	}
+
+	e := edges[0]
+	if e.Source != "api" || e.Target != "foo" || e.Endpoint != "Foo.Bar" {
+		t.Fatalf("Unexpected edge %+v", e)
+	}
+	if e.Calls != 2 || e.Errors != 1 || e.ErrorRate != 0.5 || e.Rate != 0.2 || e.Latency != time.Millisecond*20 {
+		t.Fatalf("Unexpected rates %+v", e)
+	}
+
+	e = edges[1]
+	if e.Calls != 2 || e.Rate != 0.1 {
+		t.Fatalf("Unexpected rates %+v", e)
+	}
+
+	if edges := g.Read(ReadService("bar")); len(edges) != 1 || edges[0].Target != "bar" {
+		t.Fatalf("Expected the edge to bar got %+v", edges)
+	}
+
+	services := g.Services()
+	if len(services) != 3 || services[0] != "api" || services[1] != "bar" || services[2] != "foo" {
+		t.Fatalf("Unexpected services %v", services)
+	}
+}
+
+func TestRecordSpans(t *testing.T) {
+	g := New()
+
+	now := time.Now()
+	spans := []*trace.Span{
+		{Id: "1", Name: "foo.Foo.Bar", Service: "foo", Type: trace.SpanTypeRequestInbound, Started: now},
+		{Id: "2", Parent: "1", Name: "bar.Bar.Baz", Service: "bar", Type: trace.SpanTypeRequestOutbound, Started: now, Metadata: map[string]string{}},
+		{Id: "3", Parent: "1", Name: "bar.Bar.Baz", Service: "bar", Type: trace.SpanTypeRequestOutbound, Started: now, Metadata: map[string]string{"error": "boom"}},
+		{Id: "4", Name: "Pub to events", Type: trace.SpanTypeRequestOutbound, Started: now},
+	}
+
+	// recording the same spans twice doesn't count them twice
+	g.RecordSpans(spans)
+	g.RecordSpans(spans)
+
+	edges := g.Read()
+	if len(edges) != 1 {
+		t.Fatalf("Expected 1 edge got %+v", edges)
+	}
+
+	e := edges[0]
+	if e.Source != "foo" || e.Target != "bar" || e.Endpoint != "Bar.Baz" || e.Calls != 2 || e.Errors != 1 {
+		t.Fatalf("Unexpected edge %+v", e)
+	}
+}
+
+func TestClientWrapper(t *testing.T) {
+	g := New()
+
+	c := mock.NewClient()
+	c.Handle("bar", mock.MockResponse{Endpoint: "Bar.Baz", Response: map[string]string{}})
+
+	wc := NewClientWrapper(g, "foo")(c)
+
+	var rsp map[string]string
+	if err := wc.Call(context.TODO(), wc.NewRequest("bar", "Bar.Baz", nil), &rsp); err != nil {
+		t.Fatal(err)
+	}
+	wc.Call(context.TODO(), wc.NewRequest("bar", "Bar.Missing", nil), &rsp)
+
+	edges := g.Read()
+	if len(edges) != 2 {
+		t.Fatalf("Expected 2 edges got %+v", edges)
+	}
+	if edges[0].Endpoint != "Bar.Baz" || edges[0].Errors != 0 || edges[1].Endpoint != "Bar.Missing" || edges[1].Errors != 1 {
+		t.Fatalf("Unexpected edges %+v %+v", edges[0], edges[1])
+	}
+}
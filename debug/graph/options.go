@@ -0,0 +1,37 @@
+package graph
+
+import (
+	"time"
+)
+
+// Options of the graph
+type Options struct {
+	// Window the rates of the edges are computed over
+	Window time.Duration
+}
+
+// Option sets an option of the graph
+type Option func(o *Options)
+
+// Window sets the window the rates of the edges are computed over
+func Window(d time.Duration) Option {
+	return func(o *Options) {
+		o.Window = d
+	}
+}
+
+// ReadOptions of the edges read
+type ReadOptions struct {
+	// Service the edges are read for, as the source or the target
+	Service string
+}
+
+// ReadOption sets an option of the edges read
+type ReadOption func(o *ReadOptions)
+
+// ReadService only reads the edges of the calls made by or to the service
+func ReadService(s string) ReadOption {
+	return func(o *ReadOptions) {
+		o.Service = s
+	}
+}
@@ -0,0 +1,35 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/client"
+)
+
+type graphClient struct {
+	client.Client
+	graph  *Graph
+	source string
+}
+
+func (c *graphClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	started := time.Now()
+	err := c.Client.Call(ctx, req, rsp, opts...)
+	c.graph.Record(c.source, req.Service(), req.Endpoint(), started, time.Since(started), err)
+	return err
+}
+
+func (c *graphClient) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	started := time.Now()
+	stream, err := c.Client.Stream(ctx, req, opts...)
+	c.graph.Record(c.source, req.Service(), req.Endpoint(), started, time.Since(started), err)
+	return stream, err
+}
+
+// NewClientWrapper records the calls and streams of the client, made by the source service, in the graph
+func NewClientWrapper(g *Graph, source string) client.Wrapper {
+	return func(c client.Client) client.Client {
+		return &graphClient{Client: c, graph: g, source: source}
+	}
+}
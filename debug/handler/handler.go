@@ -0,0 +1,249 @@
+// Package handler is the Debug rpc handler serving the profiles and stats of a service
+package handler
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/debug/graph"
+	"github.com/micro/go-micro/v3/debug/level"
+	"github.com/micro/go-micro/v3/debug/profile/pprof"
+	"github.com/micro/go-micro/v3/debug/stats"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/registry"
+)
+
+var (
+	// DebugScope is the scope an account needs to call the handler by default
+	DebugScope = "debug"
+)
+
+// Debug is the rpc handler, register it with server.NewHandler to serve Debug.Health,
+// Debug.Profile, Debug.Stats, Debug.Graph and Debug.Level
+type Debug struct {
+	opts Options
+}
+
+// HealthRequest for the health of the service
+type HealthRequest struct{}
+
+// HealthResponse contains the health of the service, up or down with the error of the check
+type HealthResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ProfileRequest for a profile of the given type (cpu, heap, goroutine or mutex),
+// the duration in seconds applies to cpu and mutex profiles
+type ProfileRequest struct {
+	Type     string `json:"type"`
+	Duration int64  `json:"duration,omitempty"`
+}
+
+// ProfileResponse contains the profile in the pprof format
+type ProfileResponse struct {
+	Type string `json:"type"`
+	Data []byte `json:"data"`
+}
+
+// StatsRequest for the stats of the service
+type StatsRequest struct{}
+
+// StatsResponse contains the recorded stats followed by a current snapshot
+type StatsResponse struct {
+	Stats []*stats.Stat `json:"stats"`
+}
+
+// GraphRequest for the dependency graph, optionally limited to the calls made by or to a service
+type GraphRequest struct {
+	Service string `json:"service,omitempty"`
+}
+
+// GraphResponse contains the services and the edges of the calls between them
+type GraphResponse struct {
+	Services []string      `json:"services"`
+	Edges    []*graph.Edge `json:"edges"`
+}
+
+// verify the account in the context has one of the scopes
+func (d *Debug) verify(ctx context.Context, id string) error {
+	for _, s := range d.opts.Scopes {
+		if s == auth.ScopePublic {
+			return nil
+		}
+	}
+
+	acc, ok := auth.AccountFromContext(ctx)
+	if !ok || acc == nil {
+		return errors.Unauthorized(id, "an account is required")
+	}
+
+	for _, s := range d.opts.Scopes {
+		if s == auth.ScopeAccount {
+			return nil
+		}
+		for _, as := range acc.Scopes {
+			if s == as {
+				return nil
+			}
+		}
+	}
+
+	return errors.Forbidden(id, "account does not have the required scope")
+}
+
+// Health reports if the service is ready to serve requests, it doesn't require an account so
+// it can be probed by orchestration
+func (d *Debug) Health(ctx context.Context, req *HealthRequest, rsp *HealthResponse) error {
+	rsp.Status = registry.HealthUp
+
+	if d.opts.Check == nil {
+		return nil
+	}
+
+	if err := d.opts.Check(ctx); err != nil {
+		rsp.Status = registry.HealthDown
+		rsp.Error = err.Error()
+	}
+
+	return nil
+}
+
+// Profile captures a profile of the service
+func (d *Debug) Profile(ctx context.Context, req *ProfileRequest, rsp *ProfileResponse) error {
+	if err := d.verify(ctx, "debug.profile"); err != nil {
+		return err
+	}
+
+	dur := time.Duration(req.Duration) * time.Second
+	if d.opts.MaxDuration > 0 && dur > d.opts.MaxDuration {
+		dur = d.opts.MaxDuration
+	}
+
+	// don't record for longer than the caller waits
+	if dl, ok := ctx.Deadline(); ok {
+		if rem := time.Until(dl) - time.Second; rem > 0 && (dur <= 0 || rem < dur) {
+			dur = rem
+		}
+	}
+
+	var buf bytes.Buffer
+	switch err := pprof.Capture(&buf, req.Type, dur); err {
+	case nil:
+	case pprof.ErrUnknownProfile:
+		return errors.BadRequest("debug.profile", "unknown profile type %q", req.Type)
+	default:
+		return errors.InternalServerError("debug.profile", err.Error())
+	}
+
+	rsp.Type = req.Type
+	rsp.Data = buf.Bytes()
+	return nil
+}
+
+// Stats returns the stats of the service including the per endpoint latencies and slow requests
+func (d *Debug) Stats(ctx context.Context, req *StatsRequest, rsp *StatsResponse) error {
+	if err := d.verify(ctx, "debug.stats"); err != nil {
+		return err
+	}
+
+	if d.opts.Stats == nil {
+		return errors.NotImplemented("debug.stats", "stats are not enabled")
+	}
+
+	st, err := d.opts.Stats.Read()
+	if err != nil {
+		return errors.InternalServerError("debug.stats", err.Error())
+	}
+
+	rsp.Stats = st
+	return nil
+}
+
+// Graph returns the dependency graph of the services with the call and error rates of every edge
+func (d *Debug) Graph(ctx context.Context, req *GraphRequest, rsp *GraphResponse) error {
+	if err := d.verify(ctx, "debug.graph"); err != nil {
+		return err
+	}
+
+	if d.opts.Graph == nil {
+		return errors.NotImplemented("debug.graph", "the graph is not enabled")
+	}
+
+	if d.opts.Tracer != nil {
+		spans, err := d.opts.Tracer.Read()
+		if err != nil {
+			return errors.InternalServerError("debug.graph", err.Error())
+		}
+		d.opts.Graph.RecordSpans(spans)
+	}
+
+	var opts []graph.ReadOption
+	if len(req.Service) > 0 {
+		opts = append(opts, graph.ReadService(req.Service))
+	}
+	rsp.Edges = d.opts.Graph.Read(opts...)
+
+	services := make(map[string]bool)
+	if len(req.Service) > 0 {
+		services[req.Service] = true
+		for _, e := range rsp.Edges {
+			if len(e.Source) > 0 {
+				services[e.Source] = true
+			}
+			services[e.Target] = true
+		}
+	} else {
+		for _, s := range d.opts.Graph.Services() {
+			services[s] = true
+		}
+		if d.opts.Registry != nil {
+			srvs, err := d.opts.Registry.ListServices()
+			if err != nil {
+				return errors.InternalServerError("debug.graph", err.Error())
+			}
+			for _, s := range srvs {
+				services[s.Name] = true
+			}
+		}
+	}
+
+	for s := range services {
+		rsp.Services = append(rsp.Services, s)
+	}
+	sort.Strings(rsp.Services)
+
+	return nil
+}
+
+// Level returns the levels of the logger and its components after setting the level of the
+// request if any, an empty level resets the level of the component to that of the logger
+func (d *Debug) Level(ctx context.Context, req *level.Request, rsp *level.Levels) error {
+	if err := d.verify(ctx, "debug.level"); err != nil {
+		return err
+	}
+
+	if err := level.Set(req); err == level.ErrInvalidLevel {
+		return errors.BadRequest("debug.level", "invalid level %q", req.Level)
+	} else if err != nil {
+		return errors.InternalServerError("debug.level", err.Error())
+	}
+
+	if len(req.Component) > 0 || len(req.Level) > 0 {
+		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+			logger.Infof("Log level of %q set to %q", req.Component, req.Level)
+		}
+	}
+
+	*rsp = *level.Read()
+	return nil
+}
+
+// NewHandler returns the debug handler
+func NewHandler(opts ...Option) *Debug {
+	return &Debug{opts: newOptions(opts...)}
+}
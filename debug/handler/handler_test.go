@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/debug/graph"
+	"github.com/micro/go-micro/v3/debug/level"
+	"github.com/micro/go-micro/v3/debug/stats"
+	memory "github.com/micro/go-micro/v3/debug/stats/memory"
+	"github.com/micro/go-micro/v3/debug/stats/wrapper"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/registry"
+	rmemory "github.com/micro/go-micro/v3/registry/memory"
+	"github.com/micro/go-micro/v3/server"
+	"github.com/micro/go-micro/v3/util/test"
+)
+
+func TestProfile(t *testing.T) {
+	h := NewHandler(Scopes("admin"))
+
+	testCases := []struct {
+		Name    string
+		Account *auth.Account
+		Type    string
+		Code    int32
+	}{
+		{Name: "NoAccount", Type: "heap", Code: 401},
+		{Name: "MissingScope", Account: &auth.Account{ID: "user"}, Type: "heap", Code: 403},
+		{Name: "UnknownType", Account: &auth.Account{ID: "admin", Scopes: []string{"admin"}}, Type: "foo", Code: 400},
+		{Name: "Heap", Account: &auth.Account{ID: "admin", Scopes: []string{"admin"}}, Type: "heap"},
+		{Name: "Goroutine", Account: &auth.Account{ID: "admin", Scopes: []string{"admin"}}, Type: "goroutine"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.Account != nil {
+				ctx = auth.ContextWithAccount(ctx, tc.Account)
+			}
+
+			var rsp ProfileResponse
+			err := h.Profile(ctx, &ProfileRequest{Type: tc.Type}, &rsp)
+			if tc.Code > 0 {
+				if err == nil || errors.FromError(err).Code != tc.Code {
+					t.Fatalf("Expected error code %v, got %v", tc.Code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(rsp.Data) == 0 {
+				t.Fatal("Expected profile data")
+			}
+		})
+	}
+}
+
+func TestDefaultScope(t *testing.T) {
+	h := NewHandler()
+
+	var rsp ProfileResponse
+	ctx := auth.ContextWithAccount(context.Background(), &auth.Account{ID: "user"})
+	if err := h.Profile(ctx, &ProfileRequest{Type: "heap"}, &rsp); errors.FromError(err).Code != 403 {
+		t.Fatalf("Expected forbidden without the debug scope, got %v", err)
+	}
+
+	ctx = auth.ContextWithAccount(context.Background(), &auth.Account{ID: "ops", Scopes: []string{DebugScope}})
+	if err := h.Profile(ctx, &ProfileRequest{Type: "heap"}, &rsp); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestProfilePublic(t *testing.T) {
+	h := NewHandler(Scopes(auth.ScopePublic))
+
+	var rsp ProfileResponse
+	if err := h.Profile(context.Background(), &ProfileRequest{Type: "heap"}, &rsp); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	ctx := auth.ContextWithAccount(context.Background(), &auth.Account{ID: "user", Scopes: []string{DebugScope}})
+
+	var rsp StatsResponse
+	if err := NewHandler().Stats(ctx, &StatsRequest{}, &rsp); errors.FromError(err).Code != 501 {
+		t.Fatalf("Expected not implemented without stats, got %v", err)
+	}
+
+	st := memory.NewStats()
+	st.RecordRequest(&stats.Request{Endpoint: "Foo.Bar"})
+
+	if err := NewHandler(Stats(st)).Stats(ctx, &StatsRequest{}, &rsp); err != nil {
+		t.Fatal(err)
+	}
+	if len(rsp.Stats) == 0 || rsp.Stats[len(rsp.Stats)-1].Requests != 1 {
+		t.Fatalf("Unexpected stats %+v", rsp.Stats)
+	}
+}
+
+func TestGraph(t *testing.T) {
+	ctx := auth.ContextWithAccount(context.Background(), &auth.Account{ID: "user", Scopes: []string{DebugScope}})
+
+	var rsp GraphResponse
+	if err := NewHandler().Graph(ctx, &GraphRequest{}, &rsp); errors.FromError(err).Code != 501 {
+		t.Fatalf("Expected not implemented without a graph, got %v", err)
+	}
+
+	g := graph.New()
+	g.Record("foo", "bar", "Bar.Baz", time.Now(), time.Millisecond, nil)
+
+	reg := rmemory.NewRegistry()
+	reg.Register(&registry.Service{Name: "idle", Nodes: []*registry.Node{{Id: "idle-1"}}})
+
+	h := NewHandler(Graph(g), Registry(reg))
+	if err := h.Graph(ctx, &GraphRequest{}, &rsp); err != nil {
+		t.Fatal(err)
+	}
+	if len(rsp.Edges) != 1 || rsp.Edges[0].Source != "foo" || rsp.Edges[0].Target != "bar" {
+		t.Fatalf("Unexpected edges %+v", rsp.Edges)
+	}
+	if len(rsp.Services) != 3 || rsp.Services[0] != "bar" || rsp.Services[1] != "foo" || rsp.Services[2] != "idle" {
+		t.Fatalf("Unexpected services %v", rsp.Services)
+	}
+
+	rsp = GraphResponse{}
+	if err := h.Graph(ctx, &GraphRequest{Service: "idle"}, &rsp); err != nil {
+		t.Fatal(err)
+	}
+	if len(rsp.Edges) != 0 || len(rsp.Services) != 1 {
+		t.Fatalf("Expected no edges for idle, got %+v", rsp)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	var rsp HealthResponse
+	if err := NewHandler().Health(context.Background(), &HealthRequest{}, &rsp); err != nil || rsp.Status != registry.HealthUp {
+		t.Fatalf("Expected the service to be up, got %s (%v)", rsp.Status, err)
+	}
+
+	h := NewHandler(Check(func(ctx context.Context) error {
+		return errors.InternalServerError("test", "database unreachable")
+	}))
+
+	rsp = HealthResponse{}
+	if err := h.Health(context.Background(), &HealthRequest{}, &rsp); err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Status != registry.HealthDown || len(rsp.Error) == 0 {
+		t.Fatalf("Expected the service to be down with the error of the check, got %+v", rsp)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	h := NewHandler(Scopes("admin"))
+
+	var rsp level.Levels
+	if err := h.Level(context.Background(), &level.Request{}, &rsp); errors.FromError(err).Code != 401 {
+		t.Fatalf("Expected the levels to require an account, got %v", err)
+	}
+
+	ctx := auth.ContextWithAccount(context.Background(), &auth.Account{ID: "admin", Scopes: []string{"admin"}})
+	if err := h.Level(ctx, &level.Request{Level: "loud"}, &rsp); errors.FromError(err).Code != 400 {
+		t.Fatalf("Expected an invalid level to be rejected, got %v", err)
+	}
+	if err := h.Level(ctx, &level.Request{}, &rsp); err != nil || len(rsp.Level) == 0 {
+		t.Fatalf("Expected the levels, got %+v (%v)", rsp, err)
+	}
+}
+
+func TestStatsRedacted(t *testing.T) {
+	env := test.NewEnv()
+	defer env.Stop()
+
+	st := memory.NewStats(stats.SlowThreshold(time.Nanosecond))
+
+	srv := env.NewServer(server.Name("test.stats"), server.WrapHandler(wrapper.HandlerWrapper(st)))
+	if err := srv.Handle(srv.NewHandler(NewHandler(Stats(st), Scopes(auth.ScopePublic)))); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := env.NewClient()
+	ctx := metadata.NewContext(context.Background(), metadata.Metadata{
+		"Authorization": "Bearer secret-token",
+		"Cookie":        "session=secret-token",
+		"Foo":           "Bar",
+	})
+	if err := c.Call(ctx, c.NewRequest("test.stats", "Debug.Health", &HealthRequest{}), &HealthResponse{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var rsp StatsResponse
+	if err := c.Call(context.Background(), c.NewRequest("test.stats", "Debug.Stats", &StatsRequest{}), &rsp); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(rsp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret-token") {
+		t.Fatalf("Expected the credentials not to be recorded, got %s", b)
+	}
+	if !strings.Contains(string(b), `"Foo":"Bar"`) {
+		t.Fatalf("Expected the metadata of the slow request, got %s", b)
+	}
+}
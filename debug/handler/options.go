@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/graph"
+	"github.com/micro/go-micro/v3/debug/stats"
+	"github.com/micro/go-micro/v3/debug/trace"
+	"github.com/micro/go-micro/v3/registry"
+)
+
+type Options struct {
+	// Scopes an account needs one of to call the handler,
+	// the debug scope by default
+	Scopes []string
+	// Stats returned by Debug.Stats
+	Stats stats.Stats
+	// MaxDuration a profile can be recorded for
+	MaxDuration time.Duration
+	// Graph returned by Debug.Graph
+	Graph *graph.Graph
+	// Tracer the spans of which are recorded in the graph
+	Tracer trace.Tracer
+	// Registry the services of which are added to the graph
+	Registry registry.Registry
+	// Check run by Debug.Health, the service is down when it returns an error
+	Check func(context.Context) error
+}
+
+type Option func(o *Options)
+
+// Scopes required to call the handler, the public scope allows requests without an account
+func Scopes(s ...string) Option {
+	return func(o *Options) {
+		o.Scopes = s
+	}
+}
+
+// Stats to serve, record requests into them with the stats wrapper
+func Stats(s stats.Stats) Option {
+	return func(o *Options) {
+		o.Stats = s
+	}
+}
+
+// MaxDuration limits how long a cpu or mutex profile is recorded for
+func MaxDuration(d time.Duration) Option {
+	return func(o *Options) {
+		o.MaxDuration = d
+	}
+}
+
+// Graph to serve, record calls into it with the graph client wrapper
+// or from the spans of the tracer
+func Graph(g *graph.Graph) Option {
+	return func(o *Options) {
+		o.Graph = g
+	}
+}
+
+// Tracer the spans of which are recorded in the graph when it's requested
+func Tracer(t trace.Tracer) Option {
+	return func(o *Options) {
+		o.Tracer = t
+	}
+}
+
+// Registry the services of which are added to the graph, including those without calls
+func Registry(r registry.Registry) Option {
+	return func(o *Options) {
+		o.Registry = r
+	}
+}
+
+// Check is run by Debug.Health to report the readiness of the service e.g pinging its database,
+// the service is reported down when it returns an error
+func Check(fn func(context.Context) error) Option {
+	return func(o *Options) {
+		o.Check = fn
+	}
+}
+
+func newOptions(opts ...Option) Options {
+	options := Options{
+		Scopes:      []string{DebugScope},
+		MaxDuration: time.Second * 30,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
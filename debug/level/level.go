@@ -0,0 +1,110 @@
+// Package level changes the levels of the logger and its components at runtime, the
+// levels are served by the Debug.Level endpoint of the debug handler
+package level
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/logger"
+)
+
+var (
+	// DefaultEndpoint is the endpoint of the debug handler serving the levels
+	DefaultEndpoint = "Debug.Level"
+
+	// ErrInvalidLevel is returned when the level isn't one of the logger levels
+	ErrInvalidLevel = errors.New("invalid log level")
+)
+
+// Levels of the default logger and the components which have a level set
+type Levels struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components,omitempty"`
+}
+
+// Request to change the level of the default logger or of a component if set, an empty
+// level resets the level of the component to that of the logger. An empty request only
+// reads the levels.
+type Request struct {
+	Component string `json:"component,omitempty"`
+	Level     string `json:"level,omitempty"`
+}
+
+// Read returns the current levels
+func Read() *Levels {
+	lvls := &Levels{
+		Level:      logger.DefaultLogger.Options().Level.String(),
+		Components: make(map[string]string),
+	}
+	for k, v := range logger.ComponentLevels() {
+		lvls.Components[k] = v.String()
+	}
+	return lvls
+}
+
+// Set the level of the request, it does nothing if the request is empty
+func Set(req *Request) error {
+	if len(req.Component) == 0 && len(req.Level) == 0 {
+		return nil
+	}
+
+	if len(req.Level) == 0 {
+		logger.ResetComponentLevel(req.Component)
+		return nil
+	}
+
+	lvl, err := logger.GetLevel(strings.ToLower(req.Level))
+	if err != nil {
+		return ErrInvalidLevel
+	}
+
+	if len(req.Component) > 0 {
+		logger.SetComponentLevel(req.Component, lvl)
+		return nil
+	}
+
+	return logger.Init(logger.WithLevel(lvl))
+}
+
+// Client changes the levels of a service serving the debug handler
+type Client struct {
+	client  client.Client
+	service string
+	opts    []client.CallOption
+}
+
+// NewClient returns a client of the levels of the service, the call options
+// e.g client.WithAddress select the node of the service to call
+func NewClient(c client.Client, service string, opts ...client.CallOption) *Client {
+	return &Client{
+		client:  c,
+		service: service,
+		opts:    opts,
+	}
+}
+
+func (c *Client) call(req *Request) (*Levels, error) {
+	var rsp Levels
+	if err := c.client.Call(context.Background(), c.client.NewRequest(c.service, DefaultEndpoint, req), &rsp, c.opts...); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// Levels returns the levels of the service
+func (c *Client) Levels() (*Levels, error) {
+	return c.call(&Request{})
+}
+
+// SetLevel sets the level of the component or of the logger if the component is empty
+func (c *Client) SetLevel(component string, level logger.Level) (*Levels, error) {
+	return c.call(&Request{Component: component, Level: level.String()})
+}
+
+// ResetLevel logs the entries of the component at the level of the logger
+func (c *Client) ResetLevel(component string) (*Levels, error) {
+	return c.call(&Request{Component: component})
+}
@@ -0,0 +1,65 @@
+package level_test
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/debug/handler"
+	"github.com/micro/go-micro/v3/debug/level"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/server"
+	"github.com/micro/go-micro/v3/util/test"
+)
+
+func TestLevel(t *testing.T) {
+	env := test.NewEnv()
+	defer env.Stop()
+
+	srv := env.NewServer(server.Name("test.level"))
+	if err := srv.Handle(srv.NewHandler(handler.NewHandler(handler.Scopes(auth.ScopePublic)))); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer logger.Init(logger.WithLevel(logger.DefaultLogger.Options().Level))
+	defer logger.ResetComponentLevel("store")
+
+	c := level.NewClient(env.NewClient(), "test.level")
+
+	lvls, err := c.SetLevel("store", logger.DebugLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lvls.Components["store"] != "debug" {
+		t.Fatalf("Expected the store to be at debug, got %v", lvls.Components)
+	}
+
+	if _, err := c.SetLevel("", logger.WarnLevel); err != nil {
+		t.Fatal(err)
+	}
+	if lvl := logger.DefaultLogger.Options().Level; lvl != logger.WarnLevel {
+		t.Fatalf("Expected the logger to be at warn, got %v", lvl)
+	}
+
+	lvls, err = c.ResetLevel("store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lvls.Components["store"]; ok || lvls.Level != "warn" {
+		t.Fatalf("Unexpected levels %+v", lvls)
+	}
+
+	lvls, err = c.Levels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lvls.Level != "warn" {
+		t.Fatalf("Expected the logger to be at warn, got %+v", lvls)
+	}
+
+	if err := level.Set(&level.Request{Level: "loud"}); err != level.ErrInvalidLevel {
+		t.Fatalf("Expected %v for an invalid level, got %v", level.ErrInvalidLevel, err)
+	}
+}
@@ -0,0 +1,54 @@
+package pprof
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"time"
+)
+
+var (
+	// DefaultDuration is how long a cpu or mutex profile is recorded for when no duration is given
+	DefaultDuration = time.Second * 10
+
+	// ErrUnknownProfile is returned when capturing a profile type which isn't supported
+	ErrUnknownProfile = errors.New("unknown profile type")
+)
+
+// Types of profile which can be captured
+var Types = []string{"cpu", "heap", "goroutine", "mutex"}
+
+// Capture writes a profile of the given type to w in the pprof format. The cpu
+// profile is recorded for the duration, as is the mutex profile when mutex
+// profiling isn't already enabled; heap and goroutine profiles are a snapshot.
+func Capture(w io.Writer, typ string, d time.Duration) error {
+	if d <= 0 {
+		d = DefaultDuration
+	}
+
+	switch typ {
+	case "cpu":
+		if err := pprof.StartCPUProfile(w); err != nil {
+			return err
+		}
+		time.Sleep(d)
+		pprof.StopCPUProfile()
+		return nil
+	case "heap":
+		runtime.GC()
+		return pprof.Lookup("heap").WriteTo(w, 0)
+	case "goroutine":
+		return pprof.Lookup("goroutine").WriteTo(w, 0)
+	case "mutex":
+		// only sample for the duration if nothing else enabled it
+		if rate := runtime.SetMutexProfileFraction(-1); rate == 0 {
+			runtime.SetMutexProfileFraction(1)
+			time.Sleep(d)
+			defer runtime.SetMutexProfileFraction(0)
+		}
+		return pprof.Lookup("mutex").WriteTo(w, 0)
+	default:
+		return ErrUnknownProfile
+	}
+}
@@ -1,6 +1,8 @@
 // Package profile is for profilers
 package profile
 
+import "context"
+
 type Profile interface {
 	// Start the profiler
 	Start() error
@@ -31,6 +33,10 @@ func (p *noop) String() string {
 type Options struct {
 	// Name to use for the profile
 	Name string
+
+	// Other options for implementations of the interface
+	// can be stored in a context
+	Context context.Context
 }
 
 type Option func(o *Options)
@@ -0,0 +1,56 @@
+package push
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/profile"
+)
+
+type addressKey struct{}
+
+type intervalKey struct{}
+
+type durationKey struct{}
+
+type typesKey struct{}
+
+// Address of the collector the profiles are posted to
+func Address(a string) profile.Option {
+	return func(o *profile.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, addressKey{}, a)
+	}
+}
+
+// Interval between captures, defaults to a minute
+func Interval(d time.Duration) profile.Option {
+	return func(o *profile.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, intervalKey{}, d)
+	}
+}
+
+// Duration the cpu and mutex profiles are recorded for, defaults to 10 seconds
+func Duration(d time.Duration) profile.Option {
+	return func(o *profile.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, durationKey{}, d)
+	}
+}
+
+// Types of profile to capture, defaults to cpu and heap
+func Types(t ...string) profile.Option {
+	return func(o *profile.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, typesKey{}, t)
+	}
+}
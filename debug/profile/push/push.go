@@ -0,0 +1,166 @@
+// Package push is a profiler which periodically captures profiles and posts them to a collector
+package push
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/profile"
+	"github.com/micro/go-micro/v3/debug/profile/pprof"
+	"github.com/micro/go-micro/v3/logger"
+)
+
+var (
+	// DefaultAddress of the collector
+	DefaultAddress = "http://localhost:4040/ingest"
+	// DefaultInterval between captures
+	DefaultInterval = time.Minute
+	// DefaultTypes of profile captured
+	DefaultTypes = []string{"cpu", "heap"}
+)
+
+type pushProfile struct {
+	opts     profile.Options
+	address  string
+	interval time.Duration
+	duration time.Duration
+	types    []string
+	client   *http.Client
+
+	sync.Mutex
+	running bool
+	exit    chan bool
+	wg      sync.WaitGroup
+}
+
+// push posts the profile to the collector with the name, type and time of the capture as parameters
+func (p *pushProfile) push(typ string, t time.Time, data []byte) error {
+	q := url.Values{}
+	q.Set("name", p.opts.Name)
+	q.Set("type", typ)
+	q.Set("time", strconv.FormatInt(t.Unix(), 10))
+
+	rsp, err := p.client.Post(p.address+"?"+q.Encode(), "application/octet-stream", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return fmt.Errorf("collector returned %s", rsp.Status)
+	}
+	return nil
+}
+
+func (p *pushProfile) capture() {
+	for _, typ := range p.types {
+		t := time.Now()
+
+		var buf bytes.Buffer
+		if err := pprof.Capture(&buf, typ, p.duration); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error capturing %s profile: %v", typ, err)
+			}
+			continue
+		}
+
+		if err := p.push(typ, t, buf.Bytes()); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error pushing %s profile to %s: %v", typ, p.address, err)
+			}
+		}
+	}
+}
+
+func (p *pushProfile) run(exit chan bool) {
+	defer p.wg.Done()
+
+	t := time.NewTicker(p.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			p.capture()
+		case <-exit:
+			return
+		}
+	}
+}
+
+// Start the profiler
+func (p *pushProfile) Start() error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.running {
+		return nil
+	}
+
+	p.exit = make(chan bool)
+	p.wg.Add(1)
+	go p.run(p.exit)
+
+	p.running = true
+
+	return nil
+}
+
+// Stop the profiler, waiting for a capture in progress to be pushed
+func (p *pushProfile) Stop() error {
+	p.Lock()
+	defer p.Unlock()
+
+	if !p.running {
+		return nil
+	}
+
+	close(p.exit)
+	p.wg.Wait()
+	p.running = false
+
+	return nil
+}
+
+func (p *pushProfile) String() string {
+	return "push"
+}
+
+// NewProfile returns a profiler which pushes profiles to the collector at the address
+func NewProfile(opts ...profile.Option) profile.Profile {
+	var options profile.Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	p := &pushProfile{
+		opts:     options,
+		address:  DefaultAddress,
+		interval: DefaultInterval,
+		duration: pprof.DefaultDuration,
+		types:    DefaultTypes,
+		client:   &http.Client{Timeout: time.Second * 30},
+	}
+
+	if options.Context != nil {
+		if a, ok := options.Context.Value(addressKey{}).(string); ok && len(a) > 0 {
+			p.address = a
+		}
+		if d, ok := options.Context.Value(intervalKey{}).(time.Duration); ok && d > 0 {
+			p.interval = d
+		}
+		if d, ok := options.Context.Value(durationKey{}).(time.Duration); ok && d > 0 {
+			p.duration = d
+		}
+		if t, ok := options.Context.Value(typesKey{}).([]string); ok && len(t) > 0 {
+			p.types = t
+		}
+	}
+
+	return p
+}
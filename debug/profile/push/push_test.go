@@ -0,0 +1,51 @@
+package push
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/profile"
+)
+
+func TestPushProfile(t *testing.T) {
+	type capture struct {
+		name, typ string
+		size      int
+	}
+	captures := make(chan capture, 10)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		select {
+		case captures <- capture{r.URL.Query().Get("name"), r.URL.Query().Get("type"), len(b)}:
+		default:
+		}
+	}))
+	defer srv.Close()
+
+	p := NewProfile(
+		profile.Name("foo"),
+		Address(srv.URL),
+		Interval(time.Millisecond*10),
+		Duration(time.Millisecond*10),
+		Types("heap", "goroutine"),
+	)
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	for _, typ := range []string{"heap", "goroutine"} {
+		select {
+		case c := <-captures:
+			if c.name != "foo" || c.typ != typ || c.size == 0 {
+				t.Fatalf("Unexpected capture %+v", c)
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatalf("Expected a %s profile to be pushed", typ)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package report
+
+import (
+	"context"
+	"time"
+)
+
+type Options struct {
+	// Address the reports are sent to e.g the sentry DSN or webhook url
+	Address string
+	// Environment the service runs in e.g production
+	Environment string
+	// Timeout of the requests sending the reports
+	Timeout time.Duration
+	// Headers set on the requests sending the reports
+	Headers map[string]string
+	// Other options for implementations of the interface
+	// can be stored in a context
+	Context context.Context
+}
+
+type Option func(o *Options)
+
+// Address the reports are sent to
+func Address(a string) Option {
+	return func(o *Options) {
+		o.Address = a
+	}
+}
+
+// Environment the service runs in
+func Environment(e string) Option {
+	return func(o *Options) {
+		o.Environment = e
+	}
+}
+
+// Timeout of the requests sending the reports
+func Timeout(t time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = t
+	}
+}
+
+// Header sets a header on the requests sending the reports e.g a webhook secret
+func Header(k, v string) Option {
+	return func(o *Options) {
+		if o.Headers == nil {
+			o.Headers = make(map[string]string)
+		}
+		o.Headers[k] = v
+	}
+}
+
+// NewOptions returns the options with the defaults set
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		Timeout: 5 * time.Second,
+		Headers: make(map[string]string),
+		Context: context.Background(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
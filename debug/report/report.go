@@ -0,0 +1,155 @@
+// Package report provides an interface for reporting panics and errors to an error tracking service
+package report
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/debug/trace"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/metadata"
+)
+
+// Reporter reports panics and errors e.g to sentry
+type Reporter interface {
+	// Report sends the report
+	Report(*Report) error
+	// String returns the name of the reporter
+	String() string
+}
+
+// Level of a report
+type Level string
+
+const (
+	// ErrorLevel is the level of the errors returned by handlers
+	ErrorLevel Level = "error"
+	// PanicLevel is the level of the panics recovered
+	PanicLevel Level = "panic"
+)
+
+// Report of a panic or an error
+type Report struct {
+	// Id of the report
+	Id string
+	// Timestamp the report was created at
+	Timestamp time.Time
+	// Level of the report
+	Level Level
+	// Service the panic or error occurred in
+	Service string
+	// Endpoint or topic being served
+	Endpoint string
+	// Message describing the panic or error
+	Message string
+	// Stack of the panic
+	Stack string
+	// TraceId of the request
+	TraceId string
+	// Metadata of the request
+	Metadata map[string]string
+}
+
+var (
+	// DefaultReporter used by the server and api to report panics and errors
+	DefaultReporter Reporter = new(noop)
+	// QueueSize is the number of reports queued to be sent, the reports
+	// are dropped while the queue is full
+	QueueSize = 100
+
+	queueOnce sync.Once
+	queue     chan *queued
+)
+
+// queued is a report waiting to be sent by the reporter
+type queued struct {
+	r   Reporter
+	rep *Report
+}
+
+// New returns a report including the metadata and trace of the request in the context
+func New(ctx context.Context, level Level, service, endpoint, message string) *Report {
+	rep := &Report{
+		Id:        uuid.New().String(),
+		Timestamp: time.Now(),
+		Level:     level,
+		Service:   service,
+		Endpoint:  endpoint,
+		Message:   message,
+		Metadata:  make(map[string]string),
+	}
+
+	if ctx == nil {
+		return rep
+	}
+
+	if md, ok := metadata.FromContext(ctx); ok {
+		rep.Metadata = metadata.Redact(md)
+	}
+	if traceID, _, ok := trace.FromContext(ctx); ok {
+		rep.TraceId = traceID
+	}
+
+	return rep
+}
+
+// Panic reports the value recovered along with the stack, it must be called from the
+// deferred function which recovered it
+func Panic(ctx context.Context, service, endpoint string, r interface{}) {
+	rep := New(ctx, PanicLevel, service, endpoint, fmt.Sprintf("panic recovered: %v", r))
+	rep.Stack = string(debug.Stack())
+	send(rep)
+}
+
+// Error reports an error
+func Error(ctx context.Context, service, endpoint string, err error) {
+	if err == nil {
+		return
+	}
+	send(New(ctx, ErrorLevel, service, endpoint, err.Error()))
+}
+
+// send queues the report so the caller isn't held up by the reporter, the
+// reports are sent one at a time and dropped while the queue is full
+func send(rep *Report) {
+	r := DefaultReporter
+	if _, ok := r.(*noop); ok || r == nil {
+		return
+	}
+
+	queueOnce.Do(func() {
+		queue = make(chan *queued, QueueSize)
+		go process()
+	})
+
+	select {
+	case queue <- &queued{r: r, rep: rep}:
+	default:
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Report queue full, dropping report %s", rep.Id)
+		}
+	}
+}
+
+// process sends the queued reports
+func process() {
+	for q := range queue {
+		if err := q.r.Report(q.rep); err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error sending report %s to %s: %v", q.rep.Id, q.r.String(), err)
+		}
+	}
+}
+
+type noop struct{}
+
+func (n *noop) Report(*Report) error {
+	return nil
+}
+
+func (n *noop) String() string {
+	return "noop"
+}
@@ -0,0 +1,123 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/trace"
+	"github.com/micro/go-micro/v3/metadata"
+)
+
+type testReporter struct {
+	reports chan *Report
+}
+
+func (t *testReporter) Report(rep *Report) error {
+	t.reports <- rep
+	return nil
+}
+
+func (t *testReporter) String() string {
+	return "test"
+}
+
+func TestReport(t *testing.T) {
+	r := &testReporter{reports: make(chan *Report, 2)}
+	DefaultReporter = r
+	defer func() {
+		DefaultReporter = new(noop)
+	}()
+
+	ctx := metadata.NewContext(context.Background(), metadata.Metadata{
+		"Authorization": "Bearer secret",
+		"Foo":           "bar",
+	})
+	ctx = trace.ToContext(ctx, "trace-1", "span-1")
+
+	receive := func() *Report {
+		select {
+		case rep := <-r.reports:
+			return rep
+		case <-time.After(time.Second):
+			t.Fatal("Expected a report")
+		}
+		return nil
+	}
+
+	func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				Panic(ctx, "go.micro.service.foo", "Foo.Call", rec)
+			}
+		}()
+		panic("oops")
+	}()
+
+	rep := receive()
+	if rep.Level != PanicLevel || rep.Service != "go.micro.service.foo" || rep.Endpoint != "Foo.Call" {
+		t.Fatalf("Unexpected report %+v", rep)
+	}
+	if rep.Message != "panic recovered: oops" || !strings.Contains(rep.Stack, "report_test.go") {
+		t.Fatalf("Expected the panic and its stack, got %q %q", rep.Message, rep.Stack)
+	}
+	if rep.TraceId != "trace-1" {
+		t.Fatalf("Expected trace-1, got %q", rep.TraceId)
+	}
+	if rep.Metadata["Foo"] != "bar" {
+		t.Fatalf("Expected the metadata, got %+v", rep.Metadata)
+	}
+	if _, ok := rep.Metadata["Authorization"]; ok {
+		t.Fatal("Expected the authorization to be redacted")
+	}
+
+	Error(ctx, "go.micro.service.foo", "events", errors.New("failed"))
+	if rep = receive(); rep.Level != ErrorLevel || rep.Message != "failed" || len(rep.Stack) > 0 {
+		t.Fatalf("Unexpected report %+v", rep)
+	}
+}
+
+type blockingReporter struct {
+	release chan bool
+	sent    chan bool
+}
+
+func (b *blockingReporter) Report(rep *Report) error {
+	<-b.release
+	b.sent <- true
+	return nil
+}
+
+func (b *blockingReporter) String() string {
+	return "blocking"
+}
+
+func TestReportQueue(t *testing.T) {
+	r := &blockingReporter{release: make(chan bool), sent: make(chan bool, QueueSize*2)}
+	DefaultReporter = r
+	defer func() {
+		DefaultReporter = new(noop)
+	}()
+
+	// the reports past the queue and the one being sent are dropped
+	for i := 0; i < QueueSize*2; i++ {
+		Error(context.Background(), "go.micro.service.foo", "Foo.Call", errors.New("failed"))
+	}
+	close(r.release)
+
+	var sent int
+	for {
+		select {
+		case <-r.sent:
+			sent++
+			continue
+		case <-time.After(time.Millisecond * 100):
+		}
+		break
+	}
+	if sent == 0 || sent > QueueSize+1 {
+		t.Fatalf("Expected at most %d reports to be sent, got %d", QueueSize+1, sent)
+	}
+}
@@ -0,0 +1,167 @@
+// Package sentry reports panics and errors to sentry using its store api, the address
+// of the reporter is the DSN of the project
+package sentry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/report"
+)
+
+var (
+	// ErrInvalidDSN is returned when the address is not a valid DSN
+	ErrInvalidDSN = errors.New("invalid sentry dsn")
+)
+
+const (
+	client  = "go-micro/3"
+	version = "7"
+)
+
+type sentry struct {
+	opts   report.Options
+	client *http.Client
+
+	// url of the store api and key of the project parsed from the DSN
+	url    string
+	key    string
+	secret string
+	err    error
+}
+
+type exception struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type event struct {
+	EventId     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Message     string            `json:"message"`
+	Exception   []exception       `json:"exception,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+}
+
+// parse the store url and keys from the dsn e.g https://key@sentry.io/42
+func (s *sentry) parse(dsn string) error {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil || len(u.Host) == 0 {
+		return ErrInvalidDSN
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	idx := strings.LastIndex(path, "/")
+	if idx < 0 || idx == len(path)-1 {
+		return ErrInvalidDSN
+	}
+
+	s.key = u.User.Username()
+	s.secret, _ = u.User.Password()
+	s.url = fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, path[:idx], path[idx+1:])
+	return nil
+}
+
+func (s *sentry) Report(rep *report.Report) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	ev := &event{
+		// sentry expects the uuid without dashes
+		EventId:     strings.Replace(rep.Id, "-", "", -1),
+		Timestamp:   rep.Timestamp.UTC().Format("2006-01-02T15:04:05"),
+		Level:       "error",
+		Platform:    "go",
+		Logger:      "go-micro",
+		Environment: s.opts.Environment,
+		Transaction: rep.Endpoint,
+		Message:     rep.Message,
+		Tags:        map[string]string{"service": rep.Service},
+		Extra:       make(map[string]string, len(rep.Metadata)+1),
+	}
+	ev.ServerName, _ = os.Hostname()
+
+	if rep.Level == report.PanicLevel {
+		ev.Level = "fatal"
+		ev.Exception = []exception{{Type: "panic", Value: rep.Message}}
+		ev.Extra["stack"] = rep.Stack
+	}
+	if len(rep.Endpoint) > 0 {
+		ev.Tags["endpoint"] = rep.Endpoint
+	}
+	if len(rep.TraceId) > 0 {
+		ev.Tags["trace_id"] = rep.TraceId
+	}
+	for k, v := range rep.Metadata {
+		ev.Extra[k] = v
+	}
+
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth())
+	for k, v := range s.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	rsp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return fmt.Errorf("sentry returned %s", rsp.Status)
+	}
+	return nil
+}
+
+// auth returns the X-Sentry-Auth header
+func (s *sentry) auth() string {
+	auth := fmt.Sprintf("Sentry sentry_version=%s, sentry_client=%s, sentry_timestamp=%d, sentry_key=%s",
+		version, client, time.Now().Unix(), s.key)
+	if len(s.secret) > 0 {
+		auth += ", sentry_secret=" + s.secret
+	}
+	return auth
+}
+
+func (s *sentry) String() string {
+	return "sentry"
+}
+
+// NewReporter returns a reporter sending the reports to the sentry project of the DSN
+// set as the address, an invalid DSN is returned by Report
+func NewReporter(opts ...report.Option) report.Reporter {
+	options := report.NewOptions(opts...)
+
+	s := &sentry{
+		opts:   options,
+		client: &http.Client{Timeout: options.Timeout},
+	}
+	s.err = s.parse(options.Address)
+
+	return s
+}
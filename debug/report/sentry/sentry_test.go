@@ -0,0 +1,51 @@
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v3/debug/report"
+)
+
+func TestSentry(t *testing.T) {
+	var ev event
+	var path, auth string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("X-Sentry-Auth")
+		json.NewDecoder(r.Body).Decode(&ev)
+	}))
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "://", "://public@", 1) + "/sentry/42"
+	r := NewReporter(report.Address(dsn), report.Environment("production"))
+
+	rep := report.New(context.Background(), report.PanicLevel, "go.micro.service.foo", "Foo.Call", "panic recovered: oops")
+	rep.Stack = "goroutine 1 [running]"
+	rep.TraceId = "trace-1"
+	if err := r.Report(rep); err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/sentry/api/42/store/" {
+		t.Fatalf("Expected the store api of the project, got %s", path)
+	}
+	if !strings.Contains(auth, "sentry_key=public") || strings.Contains(auth, "sentry_secret") {
+		t.Fatalf("Unexpected auth header %q", auth)
+	}
+	if len(ev.EventId) != 32 || ev.Level != "fatal" || ev.Environment != "production" {
+		t.Fatalf("Unexpected event %+v", ev)
+	}
+	if ev.Tags["trace_id"] != "trace-1" || ev.Tags["endpoint"] != "Foo.Call" || ev.Extra["stack"] != rep.Stack {
+		t.Fatalf("Expected the trace, endpoint and stack, got %+v %+v", ev.Tags, ev.Extra)
+	}
+
+	if err := NewReporter(report.Address("sentry.io")).Report(rep); err != ErrInvalidDSN {
+		t.Fatalf("Expected %v, got %v", ErrInvalidDSN, err)
+	}
+}
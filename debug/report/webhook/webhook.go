@@ -0,0 +1,82 @@
+// Package webhook reports panics and errors by posting them as json to a url
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/report"
+)
+
+type webhook struct {
+	opts   report.Options
+	client *http.Client
+}
+
+type payload struct {
+	Id          string            `json:"id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       report.Level      `json:"level"`
+	Environment string            `json:"environment,omitempty"`
+	Service     string            `json:"service"`
+	Endpoint    string            `json:"endpoint,omitempty"`
+	Message     string            `json:"message"`
+	Stack       string            `json:"stack,omitempty"`
+	TraceId     string            `json:"trace_id,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+func (w *webhook) Report(rep *report.Report) error {
+	b, err := json.Marshal(&payload{
+		Id:          rep.Id,
+		Timestamp:   rep.Timestamp,
+		Level:       rep.Level,
+		Environment: w.opts.Environment,
+		Service:     rep.Service,
+		Endpoint:    rep.Endpoint,
+		Message:     rep.Message,
+		Stack:       rep.Stack,
+		TraceId:     rep.TraceId,
+		Metadata:    rep.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", w.opts.Address, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	rsp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", rsp.Status)
+	}
+	return nil
+}
+
+func (w *webhook) String() string {
+	return "webhook"
+}
+
+// NewReporter returns a reporter posting the reports to the address
+func NewReporter(opts ...report.Option) report.Reporter {
+	options := report.NewOptions(opts...)
+
+	return &webhook{
+		opts:   options,
+		client: &http.Client{Timeout: options.Timeout},
+	}
+}
@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/micro/go-micro/v3/debug/report"
+)
+
+func TestWebhook(t *testing.T) {
+	var got payload
+	var secret string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret = r.Header.Get("X-Secret")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	r := NewReporter(report.Address(srv.URL), report.Environment("test"), report.Header("X-Secret", "foo"))
+
+	rep := report.New(context.Background(), report.ErrorLevel, "go.micro.service.foo", "Foo.Call", "failed")
+	if err := r.Report(rep); err != nil {
+		t.Fatal(err)
+	}
+
+	if secret != "foo" {
+		t.Fatalf("Expected the header to be set, got %q", secret)
+	}
+	if got.Id != rep.Id || got.Environment != "test" || got.Service != "go.micro.service.foo" || got.Message != "failed" {
+		t.Fatalf("Unexpected payload %+v", got)
+	}
+
+	// failed deliveries are returned
+	srv.Config.Handler = http.NotFoundHandler()
+	if err := r.Report(rep); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"sort"
+	"time"
+)
+
+// Buckets are the upper bounds of the histogram buckets
+var Buckets = []time.Duration{
+	time.Millisecond,
+	time.Millisecond * 2,
+	time.Millisecond * 5,
+	time.Millisecond * 10,
+	time.Millisecond * 25,
+	time.Millisecond * 50,
+	time.Millisecond * 100,
+	time.Millisecond * 250,
+	time.Millisecond * 500,
+	time.Second,
+	time.Second * 2,
+	time.Second * 5,
+	time.Second * 10,
+	time.Second * 30,
+	time.Minute,
+}
+
+// Histogram counts durations in buckets to estimate their percentiles,
+// it isn't safe for concurrent use
+type Histogram struct {
+	// counts per bucket, the last being durations over the largest bucket
+	counts []uint64
+	count  uint64
+	max    time.Duration
+}
+
+// Observe a duration
+func (h *Histogram) Observe(d time.Duration) {
+	i := sort.Search(len(Buckets), func(i int) bool { return d <= Buckets[i] })
+	h.counts[i]++
+	h.count++
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// Count of the durations observed
+func (h *Histogram) Count() uint64 {
+	return h.count
+}
+
+// Quantile estimates the duration below which q (0 to 1) of the durations fall
+// by interpolating within the bucket it lies in
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := q * float64(h.count)
+	var total float64
+
+	for i, c := range h.counts {
+		if c == 0 || total+float64(c) < rank {
+			total += float64(c)
+			continue
+		}
+
+		var lower time.Duration
+		if i > 0 {
+			lower = Buckets[i-1]
+		}
+		upper := h.max
+		if i < len(Buckets) && Buckets[i] < upper {
+			upper = Buckets[i]
+		}
+
+		return lower + time.Duration(float64(upper-lower)*(rank-total)/float64(c))
+	}
+
+	return h.max
+}
+
+// NewHistogram returns an empty histogram
+func NewHistogram() *Histogram {
+	return &Histogram{
+		counts: make([]uint64, len(Buckets)+1),
+	}
+}
@@ -0,0 +1,44 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram()
+	if q := h.Quantile(0.5); q != 0 {
+		t.Fatalf("Expected 0 for an empty histogram, got %v", q)
+	}
+
+	// 1ms to 100ms
+	for i := 1; i <= 100; i++ {
+		h.Observe(time.Duration(i) * time.Millisecond)
+	}
+
+	if h.Count() != 100 {
+		t.Fatalf("Expected 100 observations, got %v", h.Count())
+	}
+
+	testCases := []struct {
+		Quantile float64
+		Min, Max time.Duration
+	}{
+		{0.5, time.Millisecond * 25, time.Millisecond * 50},
+		{0.95, time.Millisecond * 50, time.Millisecond * 100},
+		{0.99, time.Millisecond * 90, time.Millisecond * 100},
+		{1, time.Millisecond * 100, time.Millisecond * 100},
+	}
+
+	for _, tc := range testCases {
+		if q := h.Quantile(tc.Quantile); q < tc.Min || q > tc.Max {
+			t.Errorf("Expected quantile %v between %v and %v, got %v", tc.Quantile, tc.Min, tc.Max, q)
+		}
+	}
+
+	// durations over the largest bucket are bounded by the max
+	h.Observe(time.Hour)
+	if q := h.Quantile(1); q != time.Hour {
+		t.Fatalf("Expected the max of %v, got %v", time.Hour, q)
+	}
+}
@@ -2,6 +2,7 @@ package stats
 
 import (
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 
@@ -10,13 +11,25 @@ import (
 )
 
 type memoryStats struct {
+	opts stats.Options
+
 	// used to store past stats
 	buffer *ring.Buffer
+	// the recent slow requests
+	slow *ring.Buffer
 
 	sync.RWMutex
-	started  int64
+	started   int64
+	requests  uint64
+	errors    uint64
+	endpoints map[string]*endpoint
+}
+
+type endpoint struct {
 	requests uint64
 	errors   uint64
+	latency  *stats.Histogram
+	codes    map[int32]uint64
 }
 
 func (s *memoryStats) snapshot() *stats.Stat {
@@ -28,7 +41,7 @@ func (s *memoryStats) snapshot() *stats.Stat {
 
 	now := time.Now().Unix()
 
-	return &stats.Stat{
+	stat := &stats.Stat{
 		Timestamp: now,
 		Started:   s.started,
 		Uptime:    now - s.started,
@@ -38,6 +51,33 @@ func (s *memoryStats) snapshot() *stats.Stat {
 		Requests:  s.requests,
 		Errors:    s.errors,
 	}
+
+	for name, e := range s.endpoints {
+		codes := make(map[int32]uint64, len(e.codes))
+		for k, v := range e.codes {
+			codes[k] = v
+		}
+		stat.Endpoints = append(stat.Endpoints, &stats.Endpoint{
+			Name:     name,
+			Requests: e.requests,
+			Errors:   e.errors,
+			P50:      e.latency.Quantile(0.5),
+			P95:      e.latency.Quantile(0.95),
+			P99:      e.latency.Quantile(0.99),
+			Codes:    codes,
+		})
+	}
+	sort.Slice(stat.Endpoints, func(i, j int) bool {
+		return stat.Endpoints[i].Name < stat.Endpoints[j].Name
+	})
+
+	for _, b := range s.slow.Get(s.slow.Size()) {
+		if req, ok := b.Value.(*stats.Request); ok {
+			stat.Slow = append(stat.Slow, req)
+		}
+	}
+
+	return stat
 }
 
 func (s *memoryStats) Read() ([]*stats.Stat, error) {
@@ -64,6 +104,7 @@ func (s *memoryStats) Write(stat *stats.Stat) error {
 	return nil
 }
 
+// Record counts the request without its endpoint
 func (s *memoryStats) Record(err error) error {
 	s.Lock()
 	defer s.Unlock()
@@ -79,11 +120,57 @@ func (s *memoryStats) Record(err error) error {
 	return nil
 }
 
+func (s *memoryStats) RecordRequest(req *stats.Request) error {
+	code := req.Code
+	if code == 0 && len(req.Error) > 0 {
+		code = 500
+	} else if code == 0 {
+		code = 200
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	// increment the total request count
+	s.requests++
+
+	// increment the error count
+	if len(req.Error) > 0 {
+		s.errors++
+	}
+
+	e, ok := s.endpoints[req.Endpoint]
+	if !ok {
+		e = &endpoint{
+			latency: stats.NewHistogram(),
+			codes:   make(map[int32]uint64),
+		}
+		s.endpoints[req.Endpoint] = e
+	}
+
+	e.requests++
+	if len(req.Error) > 0 {
+		e.errors++
+	}
+	e.latency.Observe(req.Duration)
+	e.codes[code]++
+
+	if s.opts.SlowThreshold > 0 && req.Duration >= s.opts.SlowThreshold {
+		s.slow.Put(req)
+	}
+
+	return nil
+}
+
 // NewStats returns a new in memory stats buffer
-// TODO add options
-func NewStats() stats.Stats {
+func NewStats(opts ...stats.Option) stats.Stats {
+	options := stats.NewOptions(opts...)
+
 	return &memoryStats{
-		started: time.Now().Unix(),
-		buffer:  ring.New(1),
+		opts:      options,
+		started:   time.Now().Unix(),
+		buffer:    ring.New(1),
+		slow:      ring.New(options.SlowRequests),
+		endpoints: make(map[string]*endpoint),
 	}
 }
@@ -0,0 +1,58 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/stats"
+)
+
+func TestRecord(t *testing.T) {
+	s := NewStats(stats.SlowThreshold(time.Second), stats.SlowRequests(2))
+
+	reqs := []*stats.Request{
+		{Endpoint: "Foo.Bar", Duration: time.Millisecond},
+		{Endpoint: "Foo.Bar", Duration: time.Millisecond * 2},
+		{Endpoint: "Foo.Bar", Duration: time.Second * 2, Error: "timeout", Code: 408},
+		{Endpoint: "Foo.Baz", Duration: time.Second, Error: "boom"},
+		{Endpoint: "Foo.Baz", Duration: time.Second * 3, Metadata: map[string]string{"Foo": "Bar"}},
+	}
+	for _, r := range reqs {
+		if err := s.RecordRequest(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	st, err := s.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat := st[len(st)-1]
+
+	if stat.Requests != 5 || stat.Errors != 2 {
+		t.Fatalf("Expected 5 requests and 2 errors, got %v and %v", stat.Requests, stat.Errors)
+	}
+	if len(stat.Endpoints) != 2 || stat.Endpoints[0].Name != "Foo.Bar" || stat.Endpoints[1].Name != "Foo.Baz" {
+		t.Fatalf("Unexpected endpoints %+v", stat.Endpoints)
+	}
+
+	bar := stat.Endpoints[0]
+	if bar.Requests != 3 || bar.Errors != 1 {
+		t.Fatalf("Expected 3 requests and 1 error for Foo.Bar, got %v and %v", bar.Requests, bar.Errors)
+	}
+	if bar.Codes[200] != 2 || bar.Codes[408] != 1 {
+		t.Fatalf("Unexpected codes %v", bar.Codes)
+	}
+	if bar.P50 > time.Millisecond*2 || bar.P99 < time.Second {
+		t.Fatalf("Unexpected latencies p50 %v p99 %v", bar.P50, bar.P99)
+	}
+
+	if baz := stat.Endpoints[1]; baz.Codes[500] != 1 || baz.Codes[200] != 1 {
+		t.Fatalf("Unexpected codes %v", baz.Codes)
+	}
+
+	// only the 2 most recent slow requests are kept
+	if len(stat.Slow) != 2 || stat.Slow[0].Endpoint != "Foo.Baz" || stat.Slow[1].Metadata["Foo"] != "Bar" {
+		t.Fatalf("Unexpected slow requests %+v", stat.Slow)
+	}
+}
@@ -0,0 +1,45 @@
+package stats
+
+import (
+	"context"
+	"time"
+)
+
+type Options struct {
+	// SlowThreshold is the duration after which a request is slow
+	SlowThreshold time.Duration
+	// SlowRequests is how many of the recent slow requests are kept
+	SlowRequests int
+
+	// Other options for implementations of the interface
+	// can be stored in a context
+	Context context.Context
+}
+
+type Option func(o *Options)
+
+// SlowThreshold after which a request is recorded as slow
+func SlowThreshold(d time.Duration) Option {
+	return func(o *Options) {
+		o.SlowThreshold = d
+	}
+}
+
+// SlowRequests is the number of recent slow requests to keep
+func SlowRequests(n int) Option {
+	return func(o *Options) {
+		o.SlowRequests = n
+	}
+}
+
+// NewOptions returns the options with the defaults set
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		SlowThreshold: time.Second,
+		SlowRequests:  100,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
@@ -1,14 +1,18 @@
 // Package stats provides runtime stats
 package stats
 
+import "time"
+
 // Stats provides stats interface
 type Stats interface {
 	// Read stat snapshot
 	Read() ([]*Stat, error)
 	// Write a stat snapshot
 	Write(*Stat) error
-	// Record a request
+	// Record a request and its error if any
 	Record(error) error
+	// RecordRequest records a request with its endpoint, duration and status code
+	RecordRequest(*Request) error
 }
 
 // A runtime stat
@@ -29,4 +33,41 @@ type Stat struct {
 	Requests uint64
 	// Total errors
 	Errors uint64
+	// Endpoints requested sorted by name
+	Endpoints []*Endpoint
+	// Slow are the most recent requests which took longer than the slow threshold
+	Slow []*Request
+}
+
+// Endpoint stats of the requests to a single endpoint
+type Endpoint struct {
+	// Name of the endpoint e.g Foo.Bar
+	Name string
+	// Total requests
+	Requests uint64
+	// Total errors
+	Errors uint64
+	// Latency percentiles of the requests
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+	// Codes is the number of requests per status code
+	Codes map[int32]uint64
+}
+
+// Request which has been served
+type Request struct {
+	// Endpoint requested
+	Endpoint string
+	// Timestamp the request started at as a unix timestamp
+	Timestamp int64
+	// Duration of the request
+	Duration time.Duration
+	// Code is the status code, 200 on success and 500 for an error if not set
+	Code int32
+	// Error returned if any
+	Error string
+	// Metadata of the request, it's served by the debug handler
+	// so it mustn't contain any credentials
+	Metadata map[string]string
 }
@@ -0,0 +1,40 @@
+// Package wrapper records the requests served by a server in the debug stats
+package wrapper
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/stats"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/server"
+)
+
+// HandlerWrapper records each request with its endpoint, duration, status code and
+// metadata, apart from the metadata.Sensitive keys
+func HandlerWrapper(s stats.Stats) server.HandlerWrapper {
+	return func(fn server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			started := time.Now()
+			err := fn(ctx, req, rsp)
+
+			r := &stats.Request{
+				Endpoint:  req.Endpoint(),
+				Timestamp: started.Unix(),
+				Duration:  time.Since(started),
+				Code:      200,
+			}
+			if md, ok := metadata.FromContext(ctx); ok {
+				r.Metadata = metadata.Redact(md)
+			}
+			if err != nil {
+				r.Error = err.Error()
+				r.Code = errors.FromError(err).Code
+			}
+
+			s.RecordRequest(r)
+			return err
+		}
+	}
+}
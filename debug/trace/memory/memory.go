@@ -2,18 +2,33 @@ package memory
 
 import (
 	"context"
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/micro/go-micro/v3/debug/trace"
+	"github.com/micro/go-micro/v3/store"
 	"github.com/micro/go-micro/v3/util/ring"
 )
 
+var (
+	// DefaultTTL of the spans in the store
+	DefaultTTL = time.Hour * 24
+)
+
 type Tracer struct {
 	opts trace.Options
 
 	// ring buffer of traces
 	buffer *ring.Buffer
+
+	// store the sampled spans are persisted to
+	store      store.Store
+	ttl        time.Duration
+	sampleRate float64
 }
 
 func (t *Tracer) Read(opts ...trace.ReadOption) ([]*trace.Span, error) {
@@ -22,20 +37,76 @@ func (t *Tracer) Read(opts ...trace.ReadOption) ([]*trace.Span, error) {
 		o(&options)
 	}
 
+	if t.store != nil {
+		return t.readStore(options)
+	}
+
 	sp := t.buffer.Get(t.buffer.Size())
 
 	spans := make([]*trace.Span, 0, len(sp))
 
 	for _, span := range sp {
 		val := span.Value.(*trace.Span)
-		// skip if trace id is specified and doesn't match
-		if len(options.Trace) > 0 && val.Trace != options.Trace {
+		// skip if the span doesn't match
+		if !options.Matches(val) {
 			continue
 		}
 		spans = append(spans, val)
 	}
 
-	return spans, nil
+	return limit(spans, options.Limit), nil
+}
+
+// readStore reads the spans of the trace by their key prefix, otherwise every span is scanned
+func (t *Tracer) readStore(options trace.ReadOptions) ([]*trace.Span, error) {
+	key := options.Trace
+	if len(key) > 0 {
+		key += "/"
+	}
+
+	recs, err := t.store.Read(key, store.ReadPrefix())
+	if err == store.ErrNotFound {
+		return []*trace.Span{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	spans := make([]*trace.Span, 0, len(recs))
+	for _, r := range recs {
+		var span *trace.Span
+		if err := json.Unmarshal(r.Value, &span); err != nil {
+			continue
+		}
+		if !options.Matches(span) {
+			continue
+		}
+		spans = append(spans, span)
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].Started.Before(spans[j].Started)
+	})
+
+	return limit(spans, options.Limit), nil
+}
+
+// limit returns the last l spans, the most recent
+func limit(spans []*trace.Span, l uint) []*trace.Span {
+	if l > 0 && l < uint(len(spans)) {
+		return spans[uint(len(spans))-l:]
+	}
+	return spans
+}
+
+// sampled decides if the trace is persisted from its id so
+// every span of a trace gets the same decision
+func (t *Tracer) sampled(id string) bool {
+	if t.sampleRate >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return float64(h.Sum64()) < t.sampleRate*math.MaxUint64
 }
 
 func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *trace.Span) {
@@ -73,7 +144,25 @@ func (t *Tracer) Finish(s *trace.Span) error {
 	// save the span
 	t.buffer.Put(s)
 
-	return nil
+	if t.store == nil || !t.sampled(s.Trace) {
+		return nil
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return t.store.Write(&store.Record{
+		Key:   s.Trace + "/" + s.Id,
+		Value: b,
+		Metadata: map[string]interface{}{
+			"trace":    s.Trace,
+			"service":  s.Service,
+			"duration": int64(s.Duration),
+		},
+		Expiry: t.ttl,
+	})
 }
 
 func NewTracer(opts ...trace.Option) trace.Tracer {
@@ -82,9 +171,25 @@ func NewTracer(opts ...trace.Option) trace.Tracer {
 		o(&options)
 	}
 
-	return &Tracer{
+	t := &Tracer{
 		opts: options,
 		// the last 256 requests
-		buffer: ring.New(256),
+		buffer:     ring.New(256),
+		ttl:        DefaultTTL,
+		sampleRate: 1,
+	}
+
+	if options.Context != nil {
+		if s, ok := options.Context.Value(storeKey{}).(store.Store); ok {
+			t.store = s
+		}
+		if d, ok := options.Context.Value(ttlKey{}).(time.Duration); ok && d > 0 {
+			t.ttl = d
+		}
+		if r, ok := options.Context.Value(sampleRateKey{}).(float64); ok {
+			t.sampleRate = r
+		}
 	}
+
+	return t
 }
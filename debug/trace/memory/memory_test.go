@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/trace"
+	smemory "github.com/micro/go-micro/v3/store/memory"
+)
+
+func TestRead(t *testing.T) {
+	st := smemory.NewStore()
+
+	testCases := []struct {
+		Name   string
+		Tracer trace.Tracer
+	}{
+		{"Buffer", NewTracer()},
+		{"Store", NewTracer(Store(st), TTL(time.Minute))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ctx, root := tc.Tracer.Start(context.Background(), "foo.Foo.Bar")
+			root.Service = "foo"
+
+			_, child := tc.Tracer.Start(ctx, "bar.Bar.Baz")
+			child.Service = "bar"
+			child.Started = child.Started.Add(-time.Second)
+			tc.Tracer.Finish(child)
+			tc.Tracer.Finish(root)
+
+			// another trace
+			_, other := tc.Tracer.Start(context.Background(), "foo.Foo.Bar")
+			other.Service = "foo"
+			tc.Tracer.Finish(other)
+
+			spans, err := tc.Tracer.Read(trace.ReadTrace(root.Trace))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(spans) != 2 {
+				t.Fatalf("Expected 2 spans of the trace, got %v", len(spans))
+			}
+
+			spans, _ = tc.Tracer.Read(trace.ReadService("foo"))
+			if len(spans) != 2 {
+				t.Fatalf("Expected 2 spans of service foo, got %v", len(spans))
+			}
+
+			spans, _ = tc.Tracer.Read(trace.ReadMinDuration(time.Second))
+			if len(spans) != 1 || spans[0].Id != child.Id {
+				t.Fatalf("Expected the slow span, got %+v", spans)
+			}
+
+			spans, _ = tc.Tracer.Read(trace.ReadLimit(1))
+			if len(spans) != 1 || spans[0].Id != other.Id {
+				t.Fatalf("Expected the most recent span, got %+v", spans)
+			}
+		})
+	}
+
+	// the spans are persisted
+	spans, err := NewTracer(Store(st)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 persisted spans, got %v", len(spans))
+	}
+}
+
+func TestSampleRate(t *testing.T) {
+	st := smemory.NewStore()
+	tr := NewTracer(Store(st), SampleRate(0.5))
+
+	for i := 0; i < 100; i++ {
+		_, span := tr.Start(context.Background(), fmt.Sprintf("span-%d", i))
+		tr.Finish(span)
+	}
+
+	spans, err := tr.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) == 0 || len(spans) == 100 {
+		t.Fatalf("Expected about half the spans to be sampled, got %v", len(spans))
+	}
+
+	tr = NewTracer(Store(smemory.NewStore()), SampleRate(0))
+	_, span := tr.Start(context.Background(), "foo")
+	tr.Finish(span)
+
+	spans, _ = tr.Read()
+	if len(spans) != 0 {
+		t.Fatalf("Expected no spans, got %v", len(spans))
+	}
+}
@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/trace"
+	"github.com/micro/go-micro/v3/store"
+)
+
+type storeKey struct{}
+
+type ttlKey struct{}
+
+type sampleRateKey struct{}
+
+// Store persists the sampled spans so they can be read after a restart and by other
+// instances, traces are then read from the store rather than the buffer
+func Store(s store.Store) trace.Option {
+	return func(o *trace.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, storeKey{}, s)
+	}
+}
+
+// TTL of the spans in the store, defaults to a day
+func TTL(d time.Duration) trace.Option {
+	return func(o *trace.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, ttlKey{}, d)
+	}
+}
+
+// SampleRate is the fraction of traces from 0 to 1 persisted in the store, all the spans of a
+// sampled trace are persisted. Every trace is persisted by default.
+func SampleRate(r float64) trace.Option {
+	return func(o *trace.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, sampleRateKey{}, r)
+	}
+}
@@ -0,0 +1,157 @@
+// Package opentelemetry is a tracer which exports spans with OpenTelemetry. The span
+// context is propagated in the metadata as a W3C traceparent header so traces
+// continue through services, the api and other systems which support it.
+package opentelemetry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/trace"
+	"github.com/micro/go-micro/v3/metadata"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/propagation"
+	apitrace "go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+)
+
+var (
+	// DefaultName is the instrumentation name of the tracer
+	DefaultName = "github.com/micro/go-micro/v3"
+
+	// ErrNotSupported is returned when reading spans, they're read from the backend
+	// the provider exports them to
+	ErrNotSupported = errors.New("spans are read from the exporter")
+)
+
+type otelTracer struct {
+	opts       trace.Options
+	tracer     apitrace.Tracer
+	propagator propagation.HTTPPropagator
+
+	sync.Mutex
+	// spans started but not finished by id
+	spans map[string]apitrace.Span
+}
+
+// supplier reads and writes the headers of the metadata
+type supplier metadata.Metadata
+
+func (s supplier) Get(key string) string {
+	v, _ := metadata.Metadata(s).Get(key)
+	return v
+}
+
+func (s supplier) Set(key, val string) {
+	metadata.Metadata(s).Set(strings.Title(key), val)
+}
+
+// Start a span, its parent is the span of the context or the span context of the metadata
+func (o *otelTracer) Start(ctx context.Context, name string) (context.Context, *trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// continue the trace of the caller
+	md, _ := metadata.FromContext(ctx)
+	ctx = o.propagator.Extract(ctx, supplier(md))
+
+	parent := apitrace.SpanFromContext(ctx).SpanContext()
+	if !parent.IsValid() {
+		parent = apitrace.RemoteSpanContextFromContext(ctx)
+	}
+
+	ctx, sp := o.tracer.Start(ctx, name)
+	sc := sp.SpanContext()
+
+	span := &trace.Span{
+		Name:     name,
+		Trace:    sc.TraceID.String(),
+		Id:       sc.SpanID.String(),
+		Started:  time.Now(),
+		Metadata: make(map[string]string),
+	}
+	if parent.IsValid() {
+		span.Parent = parent.SpanID.String()
+	}
+
+	o.Lock()
+	o.spans[span.Id] = sp
+	o.Unlock()
+
+	// pass the span context on in the metadata
+	md = metadata.Copy(md)
+	o.propagator.Inject(ctx, supplier(md))
+	ctx = metadata.NewContext(ctx, md)
+
+	return trace.ToContext(ctx, span.Trace, span.Id), span
+}
+
+// Finish the span, the metadata of the span is recorded as attributes and
+// the error metadata if any as the status
+func (o *otelTracer) Finish(s *trace.Span) error {
+	o.Lock()
+	sp, ok := o.spans[s.Id]
+	delete(o.spans, s.Id)
+	o.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	s.Duration = time.Since(s.Started)
+
+	attrs := make([]label.KeyValue, 0, len(s.Metadata)+1)
+	for k, v := range s.Metadata {
+		attrs = append(attrs, label.String(k, v))
+	}
+	switch s.Type {
+	case trace.SpanTypeRequestInbound:
+		attrs = append(attrs, label.String("span.type", "inbound"))
+	case trace.SpanTypeRequestOutbound:
+		attrs = append(attrs, label.String("span.type", "outbound"))
+	}
+	sp.SetAttributes(attrs...)
+
+	if err, ok := s.Metadata["error"]; ok {
+		sp.SetStatus(codes.Unknown, err)
+	}
+
+	sp.End()
+	return nil
+}
+
+func (o *otelTracer) Read(...trace.ReadOption) ([]*trace.Span, error) {
+	return nil, ErrNotSupported
+}
+
+// NewTracer returns a tracer which exports spans with the OpenTelemetry provider
+func NewTracer(opts ...trace.Option) trace.Tracer {
+	var options trace.Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	provider := global.TraceProvider()
+	var propagator propagation.HTTPPropagator = apitrace.TraceContext{}
+
+	if options.Context != nil {
+		if p, ok := options.Context.Value(providerKey{}).(apitrace.Provider); ok {
+			provider = p
+		}
+		if p, ok := options.Context.Value(propagatorKey{}).(propagation.HTTPPropagator); ok {
+			propagator = p
+		}
+	}
+
+	return &otelTracer{
+		opts:       options,
+		tracer:     provider.Tracer(DefaultName),
+		propagator: propagator,
+		spans:      make(map[string]apitrace.Span),
+	}
+}
@@ -0,0 +1,69 @@
+package opentelemetry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v3/metadata"
+	"go.opentelemetry.io/otel/api/trace/tracetest"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestTracer(t *testing.T) {
+	sr := new(tracetest.StandardSpanRecorder)
+	tr := NewTracer(Provider(tracetest.NewProvider(tracetest.WithSpanRecorder(sr))))
+
+	ctx, span := tr.Start(context.Background(), "client")
+	if len(span.Parent) > 0 {
+		t.Fatalf("Expected a root span, got parent %s", span.Parent)
+	}
+
+	md, ok := metadata.FromContext(ctx)
+	if !ok {
+		t.Fatal("Expected metadata in the context")
+	}
+	parent, ok := md.Get("Traceparent")
+	if !ok {
+		t.Fatal("Expected the traceparent in the metadata")
+	}
+	if expected := "00-" + span.Trace + "-" + span.Id + "-"; !strings.HasPrefix(parent, expected) {
+		t.Fatalf("Expected traceparent %s, got %s", expected, parent)
+	}
+
+	// the server receives the metadata of the client
+	srvCtx, srvSpan := tr.Start(metadata.NewContext(context.Background(), md), "server")
+	if srvSpan.Trace != span.Trace {
+		t.Fatalf("Expected trace %s, got %s", span.Trace, srvSpan.Trace)
+	}
+	if srvSpan.Parent != span.Id {
+		t.Fatalf("Expected parent %s, got %s", span.Id, srvSpan.Parent)
+	}
+	if v, _ := metadata.Get(srvCtx, "Traceparent"); v == parent {
+		t.Fatal("Expected the traceparent of the server span in the metadata")
+	}
+
+	srvSpan.Metadata["error"] = errors.New("failed").Error()
+	if err := tr.Finish(srvSpan); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Finish(span); err != nil {
+		t.Fatal(err)
+	}
+
+	done := sr.Completed()
+	if len(done) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(done))
+	}
+	if done[0].Name() != "server" || done[0].StatusCode() != codes.Unknown {
+		t.Fatalf("Expected the server span to have failed, got %s %v", done[0].Name(), done[0].StatusCode())
+	}
+	if done[0].ParentSpanID().String() != span.Id {
+		t.Fatalf("Expected parent %s, got %s", span.Id, done[0].ParentSpanID())
+	}
+
+	if _, err := tr.Read(); err != ErrNotSupported {
+		t.Fatalf("Expected %v, got %v", ErrNotSupported, err)
+	}
+}
@@ -0,0 +1,34 @@
+package opentelemetry
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/debug/trace"
+	"go.opentelemetry.io/otel/api/propagation"
+	apitrace "go.opentelemetry.io/otel/api/trace"
+)
+
+type providerKey struct{}
+
+type propagatorKey struct{}
+
+// Provider sets the provider of the spans, the global provider is used by default
+func Provider(p apitrace.Provider) trace.Option {
+	return func(o *trace.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, providerKey{}, p)
+	}
+}
+
+// Propagator sets how span contexts are passed between services in the metadata,
+// W3C trace context (the traceparent header) is used by default
+func Propagator(p propagation.HTTPPropagator) trace.Option {
+	return func(o *trace.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, propagatorKey{}, p)
+	}
+}
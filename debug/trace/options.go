@@ -1,8 +1,16 @@
 package trace
 
+import (
+	"context"
+	"time"
+)
+
 type Options struct {
 	// Size is the size of ring buffer
 	Size int
+	// Other options for implementations of the interface
+	// can be stored in a context
+	Context context.Context
 }
 
 type Option func(o *Options)
@@ -10,6 +18,12 @@ type Option func(o *Options)
 type ReadOptions struct {
 	// Trace id
 	Trace string
+	// Service the spans were made to
+	Service string
+	// MinDuration of the spans
+	MinDuration time.Duration
+	// Limit the number of spans returned
+	Limit uint
 }
 
 type ReadOption func(o *ReadOptions)
@@ -21,6 +35,38 @@ func ReadTrace(t string) ReadOption {
 	}
 }
 
+// Read the spans of requests to the service
+func ReadService(s string) ReadOption {
+	return func(o *ReadOptions) {
+		o.Service = s
+	}
+}
+
+// Read the spans which took at least the duration
+func ReadMinDuration(d time.Duration) ReadOption {
+	return func(o *ReadOptions) {
+		o.MinDuration = d
+	}
+}
+
+// Read at most the most recent l spans
+func ReadLimit(l uint) ReadOption {
+	return func(o *ReadOptions) {
+		o.Limit = l
+	}
+}
+
+// Matches returns true if the span matches the read options
+func (o ReadOptions) Matches(s *Span) bool {
+	if len(o.Trace) > 0 && s.Trace != o.Trace {
+		return false
+	}
+	if len(o.Service) > 0 && s.Service != o.Service {
+		return false
+	}
+	return s.Duration >= o.MinDuration
+}
+
 const (
 	// DefaultSize of the buffer
 	DefaultSize = 64
@@ -34,6 +34,8 @@ type Span struct {
 	Trace string
 	// name of the span
 	Name string
+	// Service the request was made to if any
+	Service string
 	// id of the span
 	Id string
 	// parent span id
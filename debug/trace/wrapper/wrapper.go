@@ -0,0 +1,191 @@
+// Package wrapper traces the calls of clients, the requests and messages of servers,
+// the messages of brokers and the requests of the api. The span context is passed on
+// in the metadata so a trace follows a request through every service it reaches.
+package wrapper
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/micro/go-micro/v3/broker"
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/debug/trace"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/server"
+	mctx "github.com/micro/go-micro/v3/util/ctx"
+)
+
+// Wrapper provides the wrappers for trace.Tracer implementations
+type Wrapper struct {
+	tracer trace.Tracer
+}
+
+type traceBroker struct {
+	broker.Broker
+	tracer trace.Tracer
+}
+
+// New returns a *Wrapper configured with the given trace.Tracer
+func New(tracer trace.Tracer) *Wrapper {
+	return &Wrapper{
+		tracer: tracer,
+	}
+}
+
+// finish records the error if any and finishes the span
+func finish(t trace.Tracer, span *trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.Metadata["error"] = err.Error()
+	}
+	t.Finish(span)
+}
+
+// Client traces the calls, streams and publications of the client
+func (w *Wrapper) Client(c client.Client) client.Client {
+	return client.NewTraceWrapper(w.tracer)(c)
+}
+
+// Broker traces the messages published and received through the broker. The span context
+// and request id of the publisher are set in the headers of the message, as the client
+// does for calls, so the handling of the message is linked to the trace it's part of.
+func (w *Wrapper) Broker(b broker.Broker) broker.Broker {
+	return &traceBroker{Broker: b, tracer: w.tracer}
+}
+
+// HandlerFunc traces the requests served by a service
+func (w *Wrapper) HandlerFunc(fn server.HandlerFunc) server.HandlerFunc {
+	return server.NewTraceHandlerWrapper(w.tracer)(fn)
+}
+
+// SubscriberFunc traces the messages received by a service
+func (w *Wrapper) SubscriberFunc(fn server.SubscriberFunc) server.SubscriberFunc {
+	return server.NewTraceSubscriberWrapper(w.tracer)(fn)
+}
+
+// HTTPHandler traces the requests of the api, it's used with the
+// WrapHandler option of the api server
+func (w *Wrapper) HTTPHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx := mctx.FromRequest(r)
+		md, _ := metadata.FromContext(ctx)
+
+		newCtx, span := w.tracer.Start(ctx, r.Method+" "+r.URL.Path)
+		if span == nil {
+			h.ServeHTTP(rw, r)
+			return
+		}
+		span.Type = trace.SpanTypeRequestInbound
+
+		// handlers build the metadata from the headers so
+		// the span context the tracer added is set on them
+		newMd, _ := metadata.FromContext(newCtx)
+		for k, v := range newMd {
+			if old, ok := md[k]; !ok || old != v {
+				r.Header.Set(k, v)
+			}
+		}
+
+		sw := &statusWriter{ResponseWriter: rw, status: http.StatusOK}
+		h.ServeHTTP(sw, r.WithContext(newCtx))
+
+		span.Metadata["http.status_code"] = fmt.Sprintf("%d", sw.status)
+		var err error
+		if sw.status >= http.StatusInternalServerError {
+			err = errors.New(http.StatusText(sw.status))
+		}
+		finish(w.tracer, span, err)
+	})
+}
+
+func (b *traceBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	ctx := options.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	newCtx, span := b.tracer.Start(ctx, "Pub to "+topic)
+	if span == nil {
+		return b.Broker.Publish(topic, msg, opts...)
+	}
+	span.Type = trace.SpanTypeRequestOutbound
+
+	err := b.Broker.Publish(topic, inject(newCtx, msg), opts...)
+	finish(b.tracer, span, err)
+	return err
+}
+
+func (b *traceBroker) Subscribe(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	return b.Broker.Subscribe(topic, func(m *broker.Message) error {
+		// the headers are the metadata of the publisher
+		ctx := metadata.NewContext(context.Background(), m.Header)
+
+		name := topic
+		if t := m.Header[broker.TopicHeader]; len(t) > 0 {
+			name = t
+		}
+
+		newCtx, span := b.tracer.Start(ctx, "Sub from "+name)
+		if span == nil {
+			return h(m)
+		}
+		span.Type = trace.SpanTypeRequestInbound
+
+		// the handler gets the span context so the messages it publishes are its children
+		err := h(inject(newCtx, m))
+		finish(b.tracer, span, err)
+		return err
+	}, opts...)
+}
+
+// inject returns a copy of the message with the metadata of the context set in its headers
+func inject(ctx context.Context, m *broker.Message) *broker.Message {
+	md, _ := metadata.FromContext(ctx)
+
+	msg := &broker.Message{
+		Header: make(map[string]string, len(m.Header)+len(md)),
+		Body:   m.Body,
+	}
+	for k, v := range m.Header {
+		msg.Header[k] = v
+	}
+	for k, v := range md {
+		msg.Header[k] = v
+	}
+	return msg
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Hijack the connection e.g for websockets
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return hj.Hijack()
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
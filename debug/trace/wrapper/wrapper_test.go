@@ -0,0 +1,111 @@
+package wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/micro/go-micro/v3/broker"
+	"github.com/micro/go-micro/v3/broker/memory"
+	"github.com/micro/go-micro/v3/debug/trace"
+	tmemory "github.com/micro/go-micro/v3/debug/trace/memory"
+	"github.com/micro/go-micro/v3/debug/trace/opentelemetry"
+	"github.com/micro/go-micro/v3/metadata"
+	mctx "github.com/micro/go-micro/v3/util/ctx"
+	"go.opentelemetry.io/otel/api/trace/tracetest"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	sr := new(tracetest.StandardSpanRecorder)
+	tr := opentelemetry.NewTracer(opentelemetry.Provider(tracetest.NewProvider(tracetest.WithSpanRecorder(sr))))
+
+	incoming := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	var traceparent string
+	h := New(tr).HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// handlers build the metadata from the request
+		traceparent, _ = metadata.Get(mctx.FromRequest(r), "Traceparent")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	r := httptest.NewRequest("GET", "/foo", nil)
+	r.Header.Set("Traceparent", incoming)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	done := sr.Completed()
+	if len(done) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(done))
+	}
+	sc := done[0].SpanContext()
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("Expected the trace to continue, got %s", sc.TraceID)
+	}
+	if done[0].ParentSpanID().String() != "00f067aa0ba902b7" {
+		t.Fatalf("Expected the parent of the request, got %s", done[0].ParentSpanID())
+	}
+	if expected := "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-01"; traceparent != expected {
+		t.Fatalf("Expected the handler to get traceparent %s, got %s", expected, traceparent)
+	}
+	if v := done[0].Attributes()["http.status_code"].AsString(); v != "500" {
+		t.Fatalf("Expected status code 500, got %s", v)
+	}
+}
+
+func TestBroker(t *testing.T) {
+	tr := tmemory.NewTracer()
+	b := New(tr).Broker(memory.NewBroker())
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	headers := make(chan map[string]string, 1)
+	if _, err := b.Subscribe("orders.created", func(m *broker.Message) error {
+		headers <- m.Header
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := metadata.NewContext(context.Background(), map[string]string{"Micro-Id": "request-1"})
+	ctx = trace.ToContext(ctx, "trace-1", "span-1")
+
+	msg := &broker.Message{Header: map[string]string{"foo": "bar"}, Body: []byte(`{}`)}
+	if err := b.Publish("orders.created", msg, broker.PublishContext(ctx)); err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Header) != 1 {
+		t.Fatalf("Expected the message of the caller to be unchanged, got %v", msg.Header)
+	}
+
+	header := <-headers
+	if header["foo"] != "bar" || header["Micro-Id"] != "request-1" {
+		t.Fatalf("Expected the headers and request id to be passed on, got %v", header)
+	}
+
+	spans, err := tr.Read(trace.ReadTrace("trace-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 2 {
+		t.Fatalf("Expected a publish and subscribe span in the trace, got %d", len(spans))
+	}
+
+	byName := make(map[string]*trace.Span)
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	pub, sub := byName["Pub to orders.created"], byName["Sub from orders.created"]
+	if pub == nil || sub == nil {
+		t.Fatalf("Expected publish and subscribe spans, got %v", byName)
+	}
+	if pub.Parent != "span-1" || sub.Parent != pub.Id {
+		t.Fatalf("Expected the subscribe span to be a child of the publish span, got %s -> %s -> %s", pub.Parent, pub.Id, sub.Parent)
+	}
+
+	// the handler gets the span context of the subscribe span
+	if traceID, spanID, _ := trace.FromContext(metadata.NewContext(context.Background(), header)); traceID != "trace-1" || spanID != sub.Id {
+		t.Fatalf("Expected the handler to get span %s of trace-1, got %s of %s", sub.Id, spanID, traceID)
+	}
+}
@@ -0,0 +1,137 @@
+package watchdog
+
+import (
+	"os"
+	"time"
+
+	"github.com/micro/go-micro/v3/events"
+)
+
+type Options struct {
+	// Name of the service used in the profile file names
+	Name string
+	// Interval between checks
+	Interval time.Duration
+	// Goroutines is the number of goroutines above which the watchdog reports
+	Goroutines int
+	// HeapGrowth is the ratio of the heap to the smallest heap over the window
+	// above which the watchdog reports
+	HeapGrowth float64
+	// MinHeap is the heap size in bytes below which heap growth isn't reported
+	MinHeap uint64
+	// Window is the number of checks the heap growth is measured over, at least 2
+	Window int
+	// GCPause is the mean pause of the collections since the last check above
+	// which the watchdog reports
+	GCPause time.Duration
+	// Cooldown is how long to wait before reporting the same breach again
+	Cooldown time.Duration
+	// Dir the heap profiles are written to, no profile is written if empty
+	Dir string
+	// Stream the breaches are published to if set
+	Stream events.Stream
+	// Topic the breaches are published to
+	Topic string
+}
+
+type Option func(o *Options)
+
+// Name of the service
+func Name(n string) Option {
+	return func(o *Options) {
+		o.Name = n
+	}
+}
+
+// Interval between checks, the default is used if it isn't positive
+func Interval(d time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = d
+	}
+}
+
+// Goroutines above which the watchdog reports
+func Goroutines(n int) Option {
+	return func(o *Options) {
+		o.Goroutines = n
+	}
+}
+
+// HeapGrowth of the heap over the window above which the watchdog reports,
+// the heap growth isn't reported while the heap is smaller than min bytes
+func HeapGrowth(ratio float64, min uint64) Option {
+	return func(o *Options) {
+		o.HeapGrowth = ratio
+		o.MinHeap = min
+	}
+}
+
+// Window is the number of checks the heap growth is measured over, it's at least 2
+func Window(n int) Option {
+	return func(o *Options) {
+		o.Window = n
+	}
+}
+
+// GCPause above which the watchdog reports
+func GCPause(d time.Duration) Option {
+	return func(o *Options) {
+		o.GCPause = d
+	}
+}
+
+// Cooldown before reporting the same breach again
+func Cooldown(d time.Duration) Option {
+	return func(o *Options) {
+		o.Cooldown = d
+	}
+}
+
+// Dir to write the heap profiles to, an empty dir disables them
+func Dir(d string) Option {
+	return func(o *Options) {
+		o.Dir = d
+	}
+}
+
+// Stream to publish the breaches to
+func Stream(s events.Stream) Option {
+	return func(o *Options) {
+		o.Stream = s
+	}
+}
+
+// Topic to publish the breaches to
+func Topic(t string) Option {
+	return func(o *Options) {
+		o.Topic = t
+	}
+}
+
+func newOptions(opts ...Option) Options {
+	options := Options{
+		Name:       "micro",
+		Interval:   time.Second * 10,
+		Goroutines: 10000,
+		HeapGrowth: 2,
+		MinHeap:    64 << 20,
+		Window:     30,
+		GCPause:    time.Millisecond * 100,
+		Cooldown:   time.Minute * 10,
+		Dir:        os.TempDir(),
+		Topic:      DefaultTopic,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// the growth is measured between two checks at least
+	if options.Window < 2 {
+		options.Window = 2
+	}
+	if options.Interval <= 0 {
+		options.Interval = time.Second * 10
+	}
+
+	return options
+}
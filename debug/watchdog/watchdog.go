@@ -0,0 +1,243 @@
+// Package watchdog monitors the goroutines, heap growth and gc pauses of a service and
+// reports when they breach their thresholds with a log, an event and a heap profile
+package watchdog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/profile/pprof"
+	"github.com/micro/go-micro/v3/logger"
+)
+
+var (
+	// DefaultTopic the breaches are published to
+	DefaultTopic = "debug.watchdog"
+)
+
+// Kinds of breach
+const (
+	KindGoroutines = "goroutines"
+	KindHeap       = "heap"
+	KindGCPause    = "gc_pause"
+)
+
+// Breach of a threshold which is logged and published
+type Breach struct {
+	// Kind of breach e.g goroutines
+	Kind string `json:"kind"`
+	// Value which breached the threshold, the number of goroutines,
+	// heap growth ratio or pause in nanoseconds
+	Value float64 `json:"value"`
+	// Threshold which was breached
+	Threshold float64 `json:"threshold"`
+	// Timestamp of the breach
+	Timestamp time.Time `json:"timestamp"`
+	// Profile is the path of the heap profile written
+	Profile string `json:"profile,omitempty"`
+}
+
+// Watchdog checks the runtime at an interval
+type Watchdog struct {
+	opts Options
+
+	sync.Mutex
+	running bool
+	exit    chan bool
+	wg      sync.WaitGroup
+
+	// heap sizes over the window
+	heap []uint64
+	// number of collections at the last check
+	numGC uint32
+	// when each kind was last reported
+	reported map[string]time.Time
+}
+
+// check the runtime stats and return the breaches
+func (w *Watchdog) check(goroutines int, m *runtime.MemStats) []*Breach {
+	var breaches []*Breach
+	now := time.Now()
+
+	if w.opts.Goroutines > 0 && goroutines > w.opts.Goroutines {
+		breaches = append(breaches, &Breach{
+			Kind:      KindGoroutines,
+			Value:     float64(goroutines),
+			Threshold: float64(w.opts.Goroutines),
+			Timestamp: now,
+		})
+	}
+
+	// compare the heap to the smallest over the window
+	w.heap = append(w.heap, m.HeapAlloc)
+	if len(w.heap) > w.opts.Window {
+		w.heap = w.heap[len(w.heap)-w.opts.Window:]
+	}
+	min := w.heap[0]
+	for _, h := range w.heap {
+		if h < min {
+			min = h
+		}
+	}
+	if w.opts.HeapGrowth > 0 && min > 0 && m.HeapAlloc >= w.opts.MinHeap {
+		if growth := float64(m.HeapAlloc) / float64(min); growth > w.opts.HeapGrowth {
+			breaches = append(breaches, &Breach{
+				Kind:      KindHeap,
+				Value:     growth,
+				Threshold: w.opts.HeapGrowth,
+				Timestamp: now,
+			})
+		}
+	}
+
+	// the mean pause of the collections since the last check, the
+	// recent pauses are kept in a circular buffer of 256
+	if n := m.NumGC - w.numGC; n > 0 && w.opts.GCPause > 0 {
+		if n > uint32(len(m.PauseNs)) {
+			n = uint32(len(m.PauseNs))
+		}
+		var total uint64
+		for i := uint32(0); i < n; i++ {
+			total += m.PauseNs[(m.NumGC-i+255)%uint32(len(m.PauseNs))]
+		}
+		if mean := time.Duration(total / uint64(n)); mean > w.opts.GCPause {
+			breaches = append(breaches, &Breach{
+				Kind:      KindGCPause,
+				Value:     float64(mean),
+				Threshold: float64(w.opts.GCPause),
+				Timestamp: now,
+			})
+		}
+	}
+	w.numGC = m.NumGC
+
+	// only report each kind once per cooldown
+	reports := breaches[:0]
+	for _, b := range breaches {
+		if t, ok := w.reported[b.Kind]; ok && now.Sub(t) < w.opts.Cooldown {
+			continue
+		}
+		w.reported[b.Kind] = now
+		reports = append(reports, b)
+	}
+
+	return reports
+}
+
+// profile writes a heap profile to the dir and returns its path
+func (w *Watchdog) profile() (string, error) {
+	path := filepath.Join(w.opts.Dir, fmt.Sprintf("%s.%d.heap.pprof", w.opts.Name, time.Now().Unix()))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := pprof.Capture(f, "heap", 0); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// report logs and publishes the breaches with a heap profile
+func (w *Watchdog) report(breaches []*Breach) {
+	var path string
+	if len(w.opts.Dir) > 0 {
+		p, err := w.profile()
+		if err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error writing heap profile: %v", err)
+		}
+		path = p
+	}
+
+	for _, b := range breaches {
+		b.Profile = path
+
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Watchdog %s of %v breached the threshold of %v, heap profile %s", b.Kind, b.Value, b.Threshold, path)
+		}
+
+		if w.opts.Stream == nil {
+			continue
+		}
+		if err := w.opts.Stream.Publish(w.opts.Topic, b); err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error publishing watchdog breach: %v", err)
+		}
+	}
+}
+
+func (w *Watchdog) run(exit chan bool) {
+	defer w.wg.Done()
+
+	// only the pauses since starting are measured
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	w.numGC = m.NumGC
+
+	t := time.NewTicker(w.opts.Interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			var m runtime.MemStats
+			runtime.ReadMemStats(&m)
+
+			if breaches := w.check(runtime.NumGoroutine(), &m); len(breaches) > 0 {
+				w.report(breaches)
+			}
+		case <-exit:
+			return
+		}
+	}
+}
+
+// Start the watchdog
+func (w *Watchdog) Start() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.running {
+		return nil
+	}
+
+	w.exit = make(chan bool)
+	w.wg.Add(1)
+	go w.run(w.exit)
+
+	w.running = true
+
+	return nil
+}
+
+// Stop the watchdog
+func (w *Watchdog) Stop() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if !w.running {
+		return nil
+	}
+
+	close(w.exit)
+	w.wg.Wait()
+	w.running = false
+
+	return nil
+}
+
+func (w *Watchdog) String() string {
+	return "watchdog"
+}
+
+// New returns a watchdog, start it to begin the checks
+func New(opts ...Option) *Watchdog {
+	return &Watchdog{
+		opts:     newOptions(opts...),
+		reported: make(map[string]time.Time),
+	}
+}
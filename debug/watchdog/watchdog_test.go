@@ -0,0 +1,102 @@
+package watchdog
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/events/stream/memory"
+)
+
+func TestCheck(t *testing.T) {
+	w := New(Goroutines(10), HeapGrowth(2, 100), Window(3), GCPause(time.Millisecond))
+
+	// nothing breached
+	m := &runtime.MemStats{HeapAlloc: 100}
+	if b := w.check(5, m); len(b) != 0 {
+		t.Fatalf("Expected no breaches, got %+v", b)
+	}
+
+	// every threshold breached
+	m = &runtime.MemStats{HeapAlloc: 300, NumGC: 2}
+	m.PauseNs[0] = uint64(time.Millisecond * 2)
+	m.PauseNs[1] = uint64(time.Millisecond * 4)
+
+	b := w.check(20, m)
+	if len(b) != 3 {
+		t.Fatalf("Expected 3 breaches, got %+v", b)
+	}
+	if b[0].Kind != KindGoroutines || b[0].Value != 20 {
+		t.Fatalf("Unexpected breach %+v", b[0])
+	}
+	if b[1].Kind != KindHeap || b[1].Value != 3 {
+		t.Fatalf("Unexpected breach %+v", b[1])
+	}
+	if b[2].Kind != KindGCPause || time.Duration(b[2].Value) != time.Millisecond*3 {
+		t.Fatalf("Unexpected breach %+v", b[2])
+	}
+
+	// the same breaches aren't reported again within the cooldown
+	if b := w.check(20, &runtime.MemStats{HeapAlloc: 300, NumGC: 2}); len(b) != 0 {
+		t.Fatalf("Expected no breaches within the cooldown, got %+v", b)
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watchdog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stream, err := memory.NewStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := stream.Subscribe(DefaultTopic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := New(
+		Name("foo"),
+		Interval(time.Millisecond*10),
+		Goroutines(1),
+		Dir(dir),
+		Stream(stream),
+	)
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	select {
+	case ev := <-events:
+		var b Breach
+		if err := ev.Unmarshal(&b); err != nil {
+			t.Fatal(err)
+		}
+		if b.Kind != KindGoroutines {
+			t.Fatalf("Expected a goroutines breach, got %+v", b)
+		}
+		if _, err := os.Stat(b.Profile); err != nil {
+			t.Fatalf("Expected a heap profile: %v", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Expected a breach to be published")
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	w := New(Window(0), Interval(0), HeapGrowth(2, 100))
+
+	// a window of one check can't measure the growth
+	if w.opts.Window != 2 || w.opts.Interval <= 0 {
+		t.Fatalf("Expected the window and interval to be clamped, got %v and %v", w.opts.Window, w.opts.Interval)
+	}
+	if b := w.check(1, &runtime.MemStats{HeapAlloc: 100}); len(b) != 0 {
+		t.Fatalf("Expected no breaches, got %+v", b)
+	}
+}
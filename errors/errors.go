@@ -4,8 +4,10 @@ package errors
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type Error struct {
@@ -13,6 +15,24 @@ type Error struct {
 	Code   int32
 	Detail string
 	Status string
+	// Violations are the invalid fields of a bad request
+	Violations []*FieldViolation `json:",omitempty"`
+	// Retry is set when the request can be retried
+	Retry *RetryInfo `json:",omitempty"`
+
+	// cause is the wrapped error, it's not sent to the client
+	cause error
+}
+
+// FieldViolation describes a single invalid field of a request
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// RetryInfo tells the client how long to wait before retrying the request
+type RetryInfo struct {
+	Delay time.Duration
 }
 
 func (e *Error) Error() string {
@@ -20,6 +40,39 @@ func (e *Error) Error() string {
 	return string(b)
 }
 
+// Unwrap returns the error wrapped by Wrap
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether the target is an *Error with the same code, and the
+// same id if the target has one. It's used by the standard errors.Is.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || t == nil {
+		return false
+	}
+	if len(t.Id) > 0 && t.Id != e.Id {
+		return false
+	}
+	return t.Code == e.Code
+}
+
+// AddViolation adds an invalid field to the error
+func (e *Error) AddViolation(field, description string) *Error {
+	e.Violations = append(e.Violations, &FieldViolation{
+		Field:       field,
+		Description: description,
+	})
+	return e
+}
+
+// RetryAfter sets the delay after which the request can be retried
+func (e *Error) RetryAfter(d time.Duration) *Error {
+	e.Retry = &RetryInfo{Delay: d}
+	return e
+}
+
 // New generates a custom error.
 func New(id, detail string, code int32) error {
 	return &Error{
@@ -30,6 +83,18 @@ func New(id, detail string, code int32) error {
 	}
 }
 
+// Wrap generates a custom error with err as its cause. The cause can be
+// checked with the standard errors.Is and errors.As but isn't sent to the client.
+func Wrap(err error, id, detail string, code int32) error {
+	return &Error{
+		Id:     id,
+		Code:   code,
+		Detail: detail,
+		Status: http.StatusText(int(code)),
+		cause:  err,
+	}
+}
+
 // Parse tries to parse a JSON string into an error. If that
 // fails, it will set the given string as the error detail.
 func Parse(err string) *Error {
@@ -111,6 +176,16 @@ func Conflict(id, format string, a ...interface{}) error {
 	}
 }
 
+// PayloadTooLarge generates a 413 error.
+func PayloadTooLarge(id, format string, a ...interface{}) error {
+	return &Error{
+		Id:     id,
+		Code:   413,
+		Detail: fmt.Sprintf(format, a...),
+		Status: http.StatusText(413),
+	}
+}
+
 // InternalServerError generates a 500 error.
 func InternalServerError(id, format string, a ...interface{}) error {
 	return &Error{
@@ -183,7 +258,8 @@ func Equal(err1 error, err2 error) bool {
 
 // FromError try to convert go error to *Error
 func FromError(err error) *Error {
-	if verr, ok := err.(*Error); ok && verr != nil {
+	var verr *Error
+	if errors.As(err, &verr) && verr != nil {
 		return verr
 	}
 
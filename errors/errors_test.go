@@ -2,8 +2,10 @@ package errors
 
 import (
 	er "errors"
+	"fmt"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestFromError(t *testing.T) {
@@ -75,3 +77,40 @@ func TestErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestWrap(t *testing.T) {
+	cause := er.New("connection refused")
+	err := Wrap(cause, "go.micro.test", "database unavailable", 503)
+
+	if !er.Is(err, cause) {
+		t.Fatal("Expected the error to wrap its cause")
+	}
+	if !er.Is(err, &Error{Code: 503}) {
+		t.Fatal("Expected the error to match its code")
+	}
+	if er.Is(err, &Error{Id: "go.micro.other", Code: 503}) {
+		t.Fatal("Expected the error not to match another id")
+	}
+
+	var verr *Error
+	if !er.As(fmt.Errorf("call failed: %w", err), &verr) || verr.Code != 503 {
+		t.Fatalf("Expected the error to be found in the chain got %v", verr)
+	}
+	if merr := FromError(fmt.Errorf("call failed: %w", err)); merr.Id != "go.micro.test" {
+		t.Fatalf("Expected the wrapped error got %v", merr)
+	}
+}
+
+func TestDetails(t *testing.T) {
+	err := BadRequest("go.micro.test", "invalid request").(*Error).
+		AddViolation("email", "must be set").
+		RetryAfter(time.Second)
+
+	pe := Parse(err.Error())
+	if len(pe.Violations) != 1 || pe.Violations[0].Field != "email" || pe.Violations[0].Description != "must be set" {
+		t.Fatalf("Expected the violation got %v", pe.Violations)
+	}
+	if pe.Retry == nil || pe.Retry.Delay != time.Second {
+		t.Fatalf("Expected the retry info got %v", pe.Retry)
+	}
+}
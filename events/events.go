@@ -32,6 +32,9 @@ type Event struct {
 	ID string
 	// Topic of event, e.g. "registry.service.created"
 	Topic string
+	// Offset of the event in the topic, the events of a topic are ordered by their offset
+	// which starts at 1. Subscribers can replay a topic from an offset.
+	Offset uint64
 	// Timestamp of the event
 	Timestamp time.Time
 	// Metadata contains the values the event was indexed by
@@ -1,6 +1,9 @@
 package events
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // PublishOptions contains all the options which can be provided when publishing an event
 type PublishOptions struct {
@@ -35,6 +38,11 @@ type SubscribeOptions struct {
 	// StartAtTime is the time from which the messages should be consumed from. If not provided then
 	// the messages will be consumed starting from the moment the Subscription starts.
 	StartAtTime time.Time
+	// Offset is the offset of the topic from which the messages should be consumed from, if supported.
+	// Replaying from an offset takes precedence over the StartAtTime.
+	Offset uint64
+	// Context ends the subscription and closes its channel when it's done, if supported
+	Context context.Context
 }
 
 // SubscribeOption sets attributes on SubscribeOptions
@@ -54,6 +62,20 @@ func WithStartAtTime(t time.Time) SubscribeOption {
 	}
 }
 
+// WithOffset sets the Offset field on SubscribeOptions to the value provided
+func WithOffset(o uint64) SubscribeOption {
+	return func(s *SubscribeOptions) {
+		s.Offset = o
+	}
+}
+
+// WithContext sets the Context field on SubscribeOptions, the subscription ends when it's done
+func WithContext(ctx context.Context) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.Context = ctx
+	}
+}
+
 // WriteOptions contains all the options which can be provided when writing an event to a store
 type WriteOptions struct {
 	// TTL is the duration the event should be recorded for, a zero value TTL indicates the event should
@@ -3,6 +3,9 @@ package memory
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,22 +28,140 @@ func NewStream(opts ...Option) (events.Stream, error) {
 		options.Store = memory.NewStore()
 	}
 
-	return &mem{store: options.Store}, nil
+	return &mem{
+		store:     options.Store,
+		retention: options.Retention,
+		seeded:    make(map[string]bool),
+		groups:    make(map[string]uint64),
+	}, nil
 }
 
 type subscriber struct {
 	Queue   string
 	Topic   string
 	Channel chan events.Event
+
+	// the events waiting to be consumed, a subscriber
+	// receives its events in the order of their offsets
+	sync.Mutex
+	pending []events.Event
+	notify  chan bool
+	// closed when the subscription ends
+	done chan struct{}
+}
+
+// push the event onto the subscribers queue
+func (s *subscriber) push(ev events.Event) {
+	s.Lock()
+	s.pending = append(s.pending, ev)
+	s.Unlock()
+
+	select {
+	case s.notify <- true:
+	default:
+	}
+}
+
+// run sends the pending events to the channel in order, the channel
+// is closed once the subscription ends
+func (s *subscriber) run() {
+	defer close(s.Channel)
+
+	for {
+		select {
+		case <-s.notify:
+		case <-s.done:
+			return
+		}
+
+		for {
+			s.Lock()
+			if len(s.pending) == 0 {
+				s.Unlock()
+				break
+			}
+			ev := s.pending[0]
+			s.pending = s.pending[1:]
+			s.Unlock()
+
+			select {
+			case s.Channel <- ev:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// stop ends the subscription and drops the events it hasn't received
+func (s *subscriber) stop() {
+	s.Lock()
+	s.pending = nil
+	s.Unlock()
+
+	close(s.done)
 }
 
 type mem struct {
-	store store.Store
+	store     store.Store
+	retention time.Duration
 
 	subs []*subscriber
+	// the topics the offset counter of which has been seeded
+	seeded map[string]bool
+	// the number of events delivered to each queue used to
+	// spread the events of a queue over its subscribers
+	groups map[string]uint64
 	sync.RWMutex
 }
 
+// key of the event in the store, the offset is padded so the keys sort in order
+func key(topic string, offset uint64) string {
+	return fmt.Sprintf("%v/%020d", topic, offset)
+}
+
+// nextOffset increments the offset counter of the topic and returns the new offset. The counter
+// is kept in the OffsetsTable without an expiry so the offsets aren't reused once the events
+// expire, it's seeded from the events in the store the first time.
+func (m *mem) nextOffset(topic string) (uint64, error) {
+	table := store.IncrementFrom(m.store.Options().Database, OffsetsTable)
+
+	if !m.seeded[topic] {
+		recs, err := m.store.Read(topic, store.ReadFrom(m.store.Options().Database, OffsetsTable))
+		if err != nil && err != store.ErrNotFound {
+			return 0, err
+		}
+
+		if len(recs) == 0 {
+			keys, err := m.store.List(store.ListPrefix(topic + "/"))
+			if err != nil {
+				return 0, err
+			}
+
+			var last int64
+			for _, k := range keys {
+				o, err := strconv.ParseInt(strings.TrimPrefix(k, topic+"/"), 10, 64)
+				if err == nil && o > last {
+					last = o
+				}
+			}
+			if last > 0 {
+				if _, err := store.Increment(m.store, topic, last, table); err != nil {
+					return 0, err
+				}
+			}
+		}
+
+		m.seeded[topic] = true
+	}
+
+	o, err := store.Increment(m.store, topic, 1, table)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(o), nil
+}
+
 func (m *mem) Publish(topic string, msg interface{}, opts ...events.PublishOption) error {
 	// validate the topic
 	if len(topic) == 0 {
@@ -67,6 +188,11 @@ func (m *mem) Publish(topic string, msg interface{}, opts ...events.PublishOptio
 		payload = p
 	}
 
+	// the lock is held until the event is queued for the
+	// subscribers so they receive the events in order
+	m.Lock()
+	defer m.Unlock()
+
 	// construct the event
 	event := &events.Event{
 		ID:        uuid.New().String(),
@@ -76,20 +202,34 @@ func (m *mem) Publish(topic string, msg interface{}, opts ...events.PublishOptio
 		Payload:   payload,
 	}
 
-	// serialize the event to bytes
-	bytes, err := json.Marshal(event)
-	if err != nil {
-		return errors.Wrap(err, "Error encoding event")
-	}
+	// the event is written if no other stream sharing the store wrote its offset,
+	// the next offset is tried otherwise
+	for i := 0; ; i++ {
+		offset, err := m.nextOffset(topic)
+		if err != nil {
+			return errors.Wrap(err, "Error incrementing topic offset")
+		}
+		event.Offset = offset
 
-	// write to the store
-	key := fmt.Sprintf("%v/%v", event.Topic, event.ID)
-	if err := m.store.Write(&store.Record{Key: key, Value: bytes}); err != nil {
-		return errors.Wrap(err, "Error writing event to store")
+		// serialize the event to bytes
+		bytes, err := json.Marshal(event)
+		if err != nil {
+			return errors.Wrap(err, "Error encoding event")
+		}
+
+		// write to the store
+		rec := &store.Record{Key: key(topic, event.Offset), Value: bytes, Expiry: m.retention}
+		err = m.store.Write(rec, store.WriteIfNotExists())
+		if err == store.ErrConflict && i < store.DefaultIncrementRetries {
+			continue
+		} else if err != nil {
+			return errors.Wrap(err, "Error writing event to store")
+		}
+		break
 	}
 
-	// send to the subscribers async
-	go m.handleEvent(event)
+	// queue for the subscribers
+	m.handleEvent(event)
 
 	return nil
 }
@@ -113,69 +253,110 @@ func (m *mem) Subscribe(topic string, opts ...events.SubscribeOption) (<-chan ev
 		Channel: make(chan events.Event),
 		Topic:   topic,
 		Queue:   options.Queue,
+		notify:  make(chan bool, 1),
+		done:    make(chan struct{}),
 	}
+	go sub.run()
 
-	// register the subscriber
+	// the previous events are queued before the subscriber is registered
+	// while publishing is locked so no events are missed or repeated
 	m.Lock()
+	defer m.Unlock()
+
+	if options.Offset > 0 || options.StartAtTime.Unix() > 0 {
+		if err := m.lookupPreviousEvents(sub, options); err != nil {
+			sub.stop()
+			return nil, err
+		}
+	}
+
+	// register the subscriber
 	m.subs = append(m.subs, sub)
-	m.Unlock()
 
-	// lookup previous events if the start time option was passed
-	if options.StartAtTime.Unix() > 0 {
-		go m.lookupPreviousEvents(sub, options.StartAtTime)
+	// end the subscription with its context
+	if options.Context != nil && options.Context.Done() != nil {
+		go func() {
+			<-options.Context.Done()
+			m.unsubscribe(sub)
+		}()
 	}
 
 	// return the channel
 	return sub.Channel, nil
 }
 
-// lookupPreviousEvents finds events for a subscriber which occured before a given time and sends
-// them into the subscribers channel
-func (m *mem) lookupPreviousEvents(sub *subscriber, startTime time.Time) {
-	// lookup all events which match the topic (a blank topic will return all results)
+// unsubscribe removes the subscriber and ends its subscription
+func (m *mem) unsubscribe(sub *subscriber) {
+	m.Lock()
+	for i, s := range m.subs {
+		if s == sub {
+			m.subs = append(m.subs[:i], m.subs[i+1:]...)
+			break
+		}
+	}
+	m.Unlock()
+
+	sub.stop()
+}
+
+// lookupPreviousEvents finds events for a subscriber from the offset or which occured after the
+// start time and queues them for the subscriber in order
+func (m *mem) lookupPreviousEvents(sub *subscriber, options events.SubscribeOptions) error {
+	// lookup all events which match the topic
 	recs, err := m.store.Read(sub.Topic+"/", store.ReadPrefix())
-	if err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-		logger.Errorf("Error looking up previous events: %v", err)
-		return
+	if err == store.ErrNotFound {
+		return nil
 	} else if err != nil {
-		return
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error looking up previous events: %v", err)
+		}
+		return errors.Wrap(err, "Error looking up previous events")
 	}
 
-	// loop through the records and send it to the channel if it matches
+	evs := make([]events.Event, 0, len(recs))
 	for _, r := range recs {
 		var ev events.Event
 		if err := json.Unmarshal(r.Value, &ev); err != nil {
 			continue
 		}
-		if ev.Timestamp.Unix() < startTime.Unix() {
+		if options.Offset > 0 && ev.Offset < options.Offset {
 			continue
 		}
+		if options.Offset == 0 && ev.Timestamp.Unix() < options.StartAtTime.Unix() {
+			continue
+		}
+		evs = append(evs, ev)
+	}
 
-		sub.Channel <- ev
+	sort.Slice(evs, func(i, j int) bool { return evs[i].Offset < evs[j].Offset })
+	for _, ev := range evs {
+		sub.push(ev)
 	}
+
+	return nil
 }
 
-// handleEvents sends the event to any registered subscribers.
+// handleEvent queues the event for the subscribers of the topic. The subscribers which share
+// a queue form a group which receives each event once, the events are spread over its subscribers.
 func (m *mem) handleEvent(ev *events.Event) {
-	m.RLock()
-	subs := m.subs
-	m.RUnlock()
-
-	// filteredSubs is a KV map of the queue name and subscribers. This is used to prevent a message
-	// being sent to two subscribers with the same queue.
-	filteredSubs := map[string]*subscriber{}
+	groups := map[string][]*subscriber{}
+	var queues []string
 
 	// filter down to subscribers who are interested in this topic
-	for _, sub := range subs {
-		if len(sub.Topic) == 0 || sub.Topic == ev.Topic {
-			filteredSubs[sub.Queue] = sub
+	for _, sub := range m.subs {
+		if sub.Topic != ev.Topic {
+			continue
+		}
+		if _, ok := groups[sub.Queue]; !ok {
+			queues = append(queues, sub.Queue)
 		}
+		groups[sub.Queue] = append(groups[sub.Queue], sub)
 	}
 
-	// send the message to each channel async (since one channel might be blocked)
-	for _, sub := range subs {
-		go func(s *subscriber) {
-			s.Channel <- *ev
-		}(sub)
+	for _, q := range queues {
+		subs := groups[q]
+		group := ev.Topic + "/" + q
+		subs[m.groups[group]%uint64(len(subs))].push(*ev)
+		m.groups[group]++
 	}
 }
@@ -1,12 +1,14 @@
 package memory
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/micro/go-micro/v3/events"
+	smemory "github.com/micro/go-micro/v3/store/memory"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -133,3 +135,161 @@ func TestStream(t *testing.T) {
 		wg.Wait()
 	})
 }
+
+// receive n events from the channel or fail
+func receive(t *testing.T, c <-chan events.Event, n int) []events.Event {
+	var evs []events.Event
+	for i := 0; i < n; i++ {
+		select {
+		case ev := <-c:
+			evs = append(evs, ev)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %v events, got %v", n, len(evs))
+		}
+	}
+	return evs
+}
+
+func TestOffsets(t *testing.T) {
+	stream, err := NewStream()
+	assert.Nil(t, err)
+
+	topic := uuid.New().String()
+	live, err := stream.Subscribe(topic)
+	assert.Nil(t, err)
+
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, stream.Publish(topic, i))
+	}
+
+	// the events are received in order
+	for i, ev := range receive(t, live, 10) {
+		var v int
+		assert.Nil(t, ev.Unmarshal(&v))
+		assert.Equal(t, i, v)
+		assert.Equal(t, uint64(i+1), ev.Offset)
+	}
+
+	// replay from the offset then receive the new events
+	replay, err := stream.Subscribe(topic, events.WithOffset(8))
+	assert.Nil(t, err)
+	assert.Nil(t, stream.Publish(topic, 10))
+
+	evs := receive(t, replay, 4)
+	for i, ev := range evs {
+		assert.Equal(t, uint64(i+8), ev.Offset)
+	}
+}
+
+func TestQueueGroup(t *testing.T) {
+	stream, err := NewStream()
+	assert.Nil(t, err)
+
+	topic := uuid.New().String()
+	sub1, err := stream.Subscribe(topic, events.WithQueue("group"))
+	assert.Nil(t, err)
+	sub2, err := stream.Subscribe(topic, events.WithQueue("group"))
+	assert.Nil(t, err)
+	other, err := stream.Subscribe(topic, events.WithQueue("other"))
+	assert.Nil(t, err)
+
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, stream.Publish(topic, i))
+	}
+
+	// each group receives every event once
+	receive(t, other, 4)
+	evs := append(receive(t, sub1, 2), receive(t, sub2, 2)...)
+	seen := map[uint64]bool{}
+	for _, ev := range evs {
+		seen[ev.Offset] = true
+	}
+	assert.Len(t, seen, 4, "Each event should be received once by the group")
+
+	select {
+	case ev := <-sub1:
+		t.Fatalf("Unexpected event %v", ev.Offset)
+	case <-time.After(time.Millisecond * 50):
+	}
+}
+
+func TestRetention(t *testing.T) {
+	stream, err := NewStream(Retention(time.Millisecond * 50))
+	assert.Nil(t, err)
+
+	topic := uuid.New().String()
+	assert.Nil(t, stream.Publish(topic, 1))
+	time.Sleep(time.Millisecond * 100)
+	assert.Nil(t, stream.Publish(topic, 2))
+
+	// the first event has expired
+	replay, err := stream.Subscribe(topic, events.WithOffset(1))
+	assert.Nil(t, err)
+
+	evs := receive(t, replay, 1)
+	assert.Equal(t, uint64(2), evs[0].Offset)
+
+	select {
+	case ev := <-replay:
+		t.Fatalf("Unexpected event %v", ev.Offset)
+	case <-time.After(time.Millisecond * 50):
+	}
+}
+
+func TestSharedStore(t *testing.T) {
+	st := smemory.NewStore()
+	s1, err := NewStream(Store(st), Retention(time.Millisecond*50))
+	assert.Nil(t, err)
+	s2, err := NewStream(Store(st), Retention(time.Millisecond*50))
+	assert.Nil(t, err)
+
+	// the streams don't write over each others events
+	topic := uuid.New().String()
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, s1.Publish(topic, i))
+		assert.Nil(t, s2.Publish(topic, i))
+	}
+
+	replay, err := s1.Subscribe(topic, events.WithOffset(1))
+	assert.Nil(t, err)
+	for i, ev := range receive(t, replay, 10) {
+		assert.Equal(t, uint64(i+1), ev.Offset)
+	}
+
+	// the offsets aren't reused once the events expire
+	time.Sleep(time.Millisecond * 100)
+	s3, err := NewStream(Store(st))
+	assert.Nil(t, err)
+	assert.Nil(t, s3.Publish(topic, 10))
+
+	replay, err = s3.Subscribe(topic, events.WithOffset(1))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(11), receive(t, replay, 1)[0].Offset)
+}
+
+func TestSubscribeContext(t *testing.T) {
+	stream, err := NewStream()
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	topic := uuid.New().String()
+	sub, err := stream.Subscribe(topic, events.WithContext(ctx))
+	assert.Nil(t, err)
+
+	// the events which aren't received are dropped when the subscription ends
+	assert.Nil(t, stream.Publish(topic, 1))
+	assert.Nil(t, stream.Publish(topic, 2))
+	receive(t, sub, 1)
+	cancel()
+
+	select {
+	case _, ok := <-sub:
+		for ok {
+			_, ok = <-sub
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the channel to be closed")
+	}
+
+	assert.Nil(t, stream.Publish(topic, 3))
+}
@@ -1,10 +1,20 @@
 package memory
 
-import "github.com/micro/go-micro/v3/store"
+import (
+	"time"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+// OffsetsTable is the table of the store the offset counters of the topics are kept in
+var OffsetsTable = "offsets"
 
 // Options which are used to configure the in-memory stream
 type Options struct {
 	Store store.Store
+	// Retention is how long the events are kept in the store for replay,
+	// a zero value keeps them indefinitely
+	Retention time.Duration
 }
 
 // Option is a function which configures options
@@ -16,3 +26,10 @@ func Store(s store.Store) Option {
 		o.Store = s
 	}
 }
+
+// Retention sets how long the events are kept for
+func Retention(d time.Duration) Option {
+	return func(o *Options) {
+		o.Retention = d
+	}
+}
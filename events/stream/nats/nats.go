@@ -135,6 +135,9 @@ func (s *stream) Subscribe(topic string, opts ...events.SubscribeOption) (<-chan
 			return
 		}
 
+		// the sequence of the message in the channel is the offset of the event
+		evt.Offset = m.Sequence
+
 		// push onto the channel and wait for the consumer to take the event off before we acknowledge it.
 		c <- evt
 
@@ -148,8 +151,10 @@ func (s *stream) Subscribe(topic string, opts ...events.SubscribeOption) (<-chan
 		stan.DurableName(topic),
 		stan.SetManualAckMode(),
 	}
-	if options.StartAtTime.Unix() > 0 {
-		stan.StartAtTime(options.StartAtTime)
+	if options.Offset > 0 {
+		subOpts = append(subOpts, stan.StartAtSequence(options.Offset))
+	} else if options.StartAtTime.Unix() > 0 {
+		subOpts = append(subOpts, stan.StartAtTime(options.StartAtTime))
 	}
 
 	// connect the subscriber
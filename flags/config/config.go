@@ -0,0 +1,121 @@
+// Package config provides feature flags read from the config, e.g
+// {"flags": {"new-checkout": {"enabled": true, "percentage": 10}}}
+package config
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/micro/go-micro/v3/config"
+	"github.com/micro/go-micro/v3/config/reader"
+	"github.com/micro/go-micro/v3/flags"
+	"github.com/micro/go-micro/v3/logger"
+)
+
+// DefaultPath of the flags in the config, the flags are keyed by their name
+var DefaultPath = []string{"flags"}
+
+type configFlags struct {
+	sync.RWMutex
+	conf  config.Config
+	flags map[string]*flags.Flag
+}
+
+// NewFlags returns flags read from the config at the path, or the default path if it's not set.
+// The flags are updated as the config changes and can't be written.
+func NewFlags(c config.Config, path ...string) flags.Flags {
+	if len(path) == 0 {
+		path = DefaultPath
+	}
+
+	f := &configFlags{
+		conf:  c,
+		flags: make(map[string]*flags.Flag),
+	}
+	f.load(c.Get(path...))
+
+	w, err := c.Watch(path...)
+	if err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error watching the flags config: %v", err)
+		}
+		return f
+	}
+	go f.watch(w)
+
+	return f
+}
+
+func (c *configFlags) load(v reader.Value) {
+	var data map[string]*flags.Flag
+	if err := v.Scan(&data); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error reading the flags config: %v", err)
+		}
+		return
+	}
+
+	loaded := make(map[string]*flags.Flag, len(data))
+	for name, f := range data {
+		if f == nil {
+			continue
+		}
+		f.Name = name
+		if err := f.Validate(); err != nil {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Ignoring invalid flag %s in the config", name)
+			}
+			continue
+		}
+		loaded[name] = f
+	}
+
+	c.Lock()
+	c.flags = loaded
+	c.Unlock()
+}
+
+func (c *configFlags) watch(w config.Watcher) {
+	for {
+		v, err := w.Next()
+		if err != nil {
+			return
+		}
+		c.load(v)
+	}
+}
+
+func (c *configFlags) List() ([]*flags.Flag, error) {
+	c.RLock()
+	defer c.RUnlock()
+
+	list := make([]*flags.Flag, 0, len(c.flags))
+	for _, f := range c.flags {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func (c *configFlags) Read(name string) (*flags.Flag, error) {
+	c.RLock()
+	defer c.RUnlock()
+
+	f, ok := c.flags[name]
+	if !ok {
+		return nil, flags.ErrNotFound
+	}
+	return f, nil
+}
+
+func (c *configFlags) Write(*flags.Flag) error {
+	return flags.ErrReadOnly
+}
+
+func (c *configFlags) Delete(string) error {
+	return flags.ErrReadOnly
+}
+
+func (c *configFlags) String() string {
+	return "config"
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/config"
+	"github.com/micro/go-micro/v3/config/source"
+	"github.com/micro/go-micro/v3/config/source/memory"
+)
+
+func TestFlags(t *testing.T) {
+	src := memory.NewSource(memory.WithJSON([]byte(`{"flags": {"foo": {"enabled": true}, "bar": {"percentage": 200}}}`)))
+	c, err := config.NewConfig(config.WithSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFlags(c)
+	list, err := f.List()
+	if err != nil || len(list) != 1 || list[0].Name != "foo" || !list[0].Enabled {
+		t.Fatalf("Expected the valid flag got %v %v", list, err)
+	}
+
+	// updated as the config changes, the loader watches the source in the background
+	time.Sleep(100 * time.Millisecond)
+	src.(interface{ Update(*source.ChangeSet) }).Update(&source.ChangeSet{
+		Data:   []byte(`{"flags": {"foo": {"enabled": false}}}`),
+		Format: "json",
+	})
+
+	for i := 0; i < 50; i++ {
+		if flag, err := f.Read("foo"); err == nil && !flag.Enabled {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Expected the flag to be disabled")
+}
@@ -0,0 +1,148 @@
+// Package flags provides feature flags which are evaluated for each request against
+// the account, namespace and metadata of the caller
+package flags
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math/rand"
+
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/metadata"
+)
+
+var (
+	// ErrNotFound is returned when the flag doesn't exist
+	ErrNotFound = errors.New("flag not found")
+	// ErrReadOnly is returned when writing flags which are managed elsewhere
+	ErrReadOnly = errors.New("flags are read only")
+	// ErrInvalidFlag is returned when writing a flag without a name or with an invalid percentage
+	ErrInvalidFlag = errors.New("invalid flag")
+)
+
+// Flags is the store of the feature flags of a service
+type Flags interface {
+	// List the flags
+	List() ([]*Flag, error)
+	// Read a flag
+	Read(name string) (*Flag, error)
+	// Write a flag
+	Write(*Flag) error
+	// Delete a flag
+	Delete(name string) error
+	// String returns the name of the implementation
+	String() string
+}
+
+// Flag is a feature flag. A disabled flag is off for every request. An enabled flag is on for
+// the requests matching any of its targets, and for the percentage of the other requests. If
+// the flag has no targets and no percentage it's on for every request.
+type Flag struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// Percentage of the requests the flag is on for, the requests of an account always get the same result
+	Percentage int `json:"percentage,omitempty"`
+	// Accounts the flag is on for
+	Accounts []string `json:"accounts,omitempty"`
+	// Namespaces the flag is on for, the namespace of a request is the issuer of its account
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Metadata of the requests the flag is on for, all the values must match
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Validate the flag
+func (f *Flag) Validate() error {
+	if len(f.Name) == 0 || f.Percentage < 0 || f.Percentage > 100 {
+		return ErrInvalidFlag
+	}
+	return nil
+}
+
+// targeted returns true if the request matches a target of the flag
+func (f *Flag) targeted(ctx context.Context, acc *auth.Account) bool {
+	if acc != nil {
+		for _, id := range f.Accounts {
+			if id == acc.ID {
+				return true
+			}
+		}
+		for _, ns := range f.Namespaces {
+			if ns == acc.Issuer {
+				return true
+			}
+		}
+	}
+
+	if len(f.Metadata) == 0 {
+		return false
+	}
+	for k, v := range f.Metadata {
+		if mv, ok := metadata.Get(ctx, k); !ok || mv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate returns whether the flag is on for the request of the context
+func (f *Flag) Evaluate(ctx context.Context) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	acc, _ := auth.AccountFromContext(ctx)
+	if f.targeted(ctx, acc) {
+		return true
+	}
+
+	if f.Percentage == 0 {
+		return len(f.Accounts) == 0 && len(f.Namespaces) == 0 && len(f.Metadata) == 0
+	}
+
+	// the bucket of an account is fixed so the flag doesn't flip between its requests
+	var bucket int
+	if acc != nil {
+		h := fnv.New32a()
+		h.Write([]byte(f.Name + "/" + acc.ID))
+		bucket = int(h.Sum32() % 100)
+	} else {
+		bucket = rand.Intn(100)
+	}
+
+	return bucket < f.Percentage
+}
+
+type flagsKey struct{}
+
+// NewContext returns a context with the state of the flags
+func NewContext(ctx context.Context, state map[string]bool) context.Context {
+	return context.WithValue(ctx, flagsKey{}, state)
+}
+
+// FromContext returns the state of the flags in the context
+func FromContext(ctx context.Context) (map[string]bool, bool) {
+	state, ok := ctx.Value(flagsKey{}).(map[string]bool)
+	return state, ok
+}
+
+// IsEnabled returns true if the flag is on for the request of the context, the state
+// of the flags is set in the context by the handler wrapper
+func IsEnabled(ctx context.Context, name string) bool {
+	state, _ := FromContext(ctx)
+	return state[name]
+}
+
+// Evaluate returns the state of all the flags for the request of the context
+func Evaluate(ctx context.Context, f Flags) (map[string]bool, error) {
+	list, err := f.List()
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(map[string]bool, len(list))
+	for _, flag := range list {
+		state[flag.Name] = flag.Evaluate(ctx)
+	}
+	return state, nil
+}
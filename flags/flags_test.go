@@ -0,0 +1,78 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/server"
+)
+
+type testFlags struct {
+	Flags
+	flags []*Flag
+}
+
+func (t *testFlags) List() ([]*Flag, error) {
+	return t.flags, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	acc := auth.ContextWithAccount(context.Background(), &auth.Account{ID: "john", Issuer: "acme"})
+	md := metadata.Set(context.Background(), "Region", "eu")
+
+	testData := []struct {
+		flag   *Flag
+		ctx    context.Context
+		expect bool
+	}{
+		{&Flag{Name: "off"}, acc, false},
+		{&Flag{Name: "on", Enabled: true}, context.Background(), true},
+		{&Flag{Name: "account", Enabled: true, Accounts: []string{"john"}}, acc, true},
+		{&Flag{Name: "account", Enabled: true, Accounts: []string{"jane"}}, acc, false},
+		{&Flag{Name: "namespace", Enabled: true, Namespaces: []string{"acme"}}, acc, true},
+		{&Flag{Name: "metadata", Enabled: true, Metadata: map[string]string{"Region": "eu"}}, md, true},
+		{&Flag{Name: "metadata", Enabled: true, Metadata: map[string]string{"Region": "us"}}, md, false},
+		{&Flag{Name: "all", Enabled: true, Percentage: 100}, acc, true},
+	}
+
+	for _, d := range testData {
+		if v := d.flag.Evaluate(d.ctx); v != d.expect {
+			t.Fatalf("Expected %v for %v got %v", d.expect, d.flag.Name, v)
+		}
+	}
+
+	// the percentage of the accounts the flag is on for
+	flag := &Flag{Name: "rollout", Enabled: true, Percentage: 30}
+	var on int
+	for i := 0; i < 1000; i++ {
+		ctx := auth.ContextWithAccount(context.Background(), &auth.Account{ID: fmt.Sprintf("user-%d", i)})
+		if flag.Evaluate(ctx) {
+			on++
+		}
+		// the result is stable for the account
+		if flag.Evaluate(ctx) != flag.Evaluate(ctx) {
+			t.Fatal("Expected the same result for the account")
+		}
+	}
+	if on < 200 || on > 400 {
+		t.Fatalf("Expected the flag on for about 30%% of the accounts got %d", on)
+	}
+}
+
+func TestHandlerWrapper(t *testing.T) {
+	f := &testFlags{flags: []*Flag{
+		{Name: "foo", Enabled: true},
+		{Name: "bar", Enabled: true, Accounts: []string{"jane"}},
+	}}
+
+	h := NewHandlerWrapper(f)(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		if !IsEnabled(ctx, "foo") || IsEnabled(ctx, "bar") || IsEnabled(ctx, "baz") {
+			t.Fatalf("Unexpected flags %v", ctx.Value(flagsKey{}))
+		}
+		return nil
+	})
+	h(context.Background(), nil, nil)
+}
@@ -0,0 +1,134 @@
+// Package store provides feature flags kept in the store, they're cached and refreshed on an interval
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/flags"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/store"
+)
+
+var (
+	// Prefix of the keys of the flags in the store
+	Prefix = "flag/"
+	// DefaultInterval the flags are refreshed on
+	DefaultInterval = 30 * time.Second
+)
+
+type storeFlags struct {
+	sync.RWMutex
+	store store.Store
+	flags map[string]*flags.Flag
+}
+
+// NewFlags returns flags kept in the store, the flags written by other services are
+// picked up when the cache is refreshed on the interval, or the default interval if it's 0
+func NewFlags(s store.Store, interval time.Duration) flags.Flags {
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+
+	f := &storeFlags{
+		store: s,
+		flags: make(map[string]*flags.Flag),
+	}
+	if err := f.refresh(); err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+		logger.Errorf("Error reading the flags from the store: %v", err)
+	}
+	go f.run(interval)
+
+	return f
+}
+
+func (s *storeFlags) refresh() error {
+	recs, err := s.store.Read(Prefix, store.ReadPrefix())
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string]*flags.Flag, len(recs))
+	for _, r := range recs {
+		f := new(flags.Flag)
+		if err := json.Unmarshal(r.Value, f); err != nil {
+			continue
+		}
+		loaded[f.Name] = f
+	}
+
+	s.Lock()
+	s.flags = loaded
+	s.Unlock()
+	return nil
+}
+
+func (s *storeFlags) run(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for range t.C {
+		if err := s.refresh(); err != nil && logger.V(logger.DebugLevel, logger.DefaultLogger) {
+			logger.Debugf("Error refreshing the flags: %v", err)
+		}
+	}
+}
+
+func (s *storeFlags) List() ([]*flags.Flag, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	list := make([]*flags.Flag, 0, len(s.flags))
+	for _, f := range s.flags {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func (s *storeFlags) Read(name string) (*flags.Flag, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	f, ok := s.flags[name]
+	if !ok {
+		return nil, flags.ErrNotFound
+	}
+	return f, nil
+}
+
+func (s *storeFlags) Write(f *flags.Flag) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Write(&store.Record{Key: Prefix + f.Name, Value: b}); err != nil {
+		return err
+	}
+
+	s.Lock()
+	s.flags[f.Name] = f
+	s.Unlock()
+	return nil
+}
+
+func (s *storeFlags) Delete(name string) error {
+	if err := s.store.Delete(Prefix + name); err != nil && err != store.ErrNotFound {
+		return err
+	}
+
+	s.Lock()
+	delete(s.flags, name)
+	s.Unlock()
+	return nil
+}
+
+func (s *storeFlags) String() string {
+	return "store"
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/v3/flags"
+	"github.com/micro/go-micro/v3/store/memory"
+)
+
+func TestFlags(t *testing.T) {
+	s := memory.NewStore()
+	f := NewFlags(s, 0)
+
+	if err := f.Write(&flags.Flag{Name: "foo", Percentage: 200}); err != flags.ErrInvalidFlag {
+		t.Fatalf("Expected an invalid flag got %v", err)
+	}
+	if err := f.Write(&flags.Flag{Name: "foo", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// another service sees the flag once it refreshes
+	other := NewFlags(s, 0).(*storeFlags)
+	flag, err := other.Read("foo")
+	if err != nil || !flag.Enabled {
+		t.Fatalf("Expected the flag got %v %v", flag, err)
+	}
+
+	if err := f.Delete("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Read("foo"); err != flags.ErrNotFound {
+		t.Fatalf("Expected the flag to be deleted got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package flags
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/server"
+)
+
+// NewHandlerWrapper returns a handler wrapper which evaluates the flags for each request
+// and sets their state in the context, handlers check them with IsEnabled
+func NewHandlerWrapper(f Flags) server.HandlerWrapper {
+	return func(fn server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			state, err := Evaluate(ctx, f)
+			if err != nil {
+				// the flags are off rather than failing the request
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("Error evaluating the flags of %s: %v", req.Endpoint(), err)
+				}
+				state = map[string]bool{}
+			}
+			return fn(NewContext(ctx, state), req, rsp)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+// Package flow runs multi-step distributed transactions as sagas. Each step has a compensating
+// action which undoes it, when a step fails the completed steps are compensated in reverse order.
+// The state of an execution is persisted after every step so it can be resumed after a crash.
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/util/backoff"
+)
+
+var (
+	// ErrNoSteps is returned when running a flow without steps
+	ErrNoSteps = errors.New("flow has no steps")
+	// ErrMissingID is returned when running an execution without an id
+	ErrMissingID = errors.New("missing execution id")
+)
+
+// Status of an execution
+type Status string
+
+const (
+	// StatusRunning is an execution running its steps
+	StatusRunning Status = "running"
+	// StatusCompensating is an execution undoing its steps after one failed
+	StatusCompensating Status = "compensating"
+	// StatusCompleted is an execution which ran every step
+	StatusCompleted Status = "completed"
+	// StatusCompensated is an execution whose completed steps were undone
+	StatusCompensated Status = "compensated"
+	// StatusFailed is an execution whose compensation failed and needs manual intervention
+	StatusFailed Status = "failed"
+)
+
+// Step of a flow, Do and Compensate must be idempotent as a step
+// interrupted by a crash is run again when the execution resumes
+type Step struct {
+	// Name of the step
+	Name string
+	// Do runs the step, values stored in the state are passed on to the following steps
+	Do func(ctx context.Context, s *State) error
+	// Compensate undoes the step, it may be nil if there's nothing to undo
+	Compensate func(ctx context.Context, s *State) error
+	// Retries of the step and its compensation, -1 to not retry
+	Retries int
+	// Timeout of an attempt of the step or its compensation
+	Timeout time.Duration
+}
+
+// State of an execution of a flow
+type State struct {
+	// ID of the execution
+	ID string `json:"id"`
+	// Flow is the name of the flow
+	Flow string `json:"flow"`
+	// Status of the execution
+	Status Status `json:"status"`
+	// Step is the index of the next step to run, or while
+	// compensating the number of steps left to compensate
+	Step int `json:"step"`
+	// Failed is the name of the step which failed
+	Failed string `json:"failed,omitempty"`
+	// Error of the failed step or compensation
+	Error string `json:"error,omitempty"`
+	// Data shared between the steps
+	Data map[string]json.RawMessage `json:"data,omitempty"`
+	// Updated is when the state was last persisted
+	Updated time.Time `json:"updated"`
+}
+
+// Set the value of the key, the value is encoded as json
+func (s *State) Set(key string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if s.Data == nil {
+		s.Data = make(map[string]json.RawMessage)
+	}
+	s.Data[key] = b
+	return nil
+}
+
+// Get decodes the value of the key into v, store.ErrNotFound is returned if it isn't set
+func (s *State) Get(key string, v interface{}) error {
+	b, ok := s.Data[key]
+	if !ok {
+		return store.ErrNotFound
+	}
+	return json.Unmarshal(b, v)
+}
+
+// Done returns true if the execution has finished
+func (s *State) Done() bool {
+	switch s.Status {
+	case StatusCompleted, StatusCompensated, StatusFailed:
+		return true
+	}
+	return false
+}
+
+// Flow is a sequence of steps
+type Flow struct {
+	name  string
+	steps []*Step
+	opts  Options
+}
+
+// key of the state in the store
+func (f *Flow) key(id string) string {
+	return "flow/" + f.name + "/" + id
+}
+
+// load the state of the execution, false if it doesn't exist
+func (f *Flow) load(id string) (*State, bool, error) {
+	recs, err := f.opts.Store.Read(f.key(id))
+	if err == store.ErrNotFound {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	if len(recs) == 0 {
+		return nil, false, nil
+	}
+
+	var s *State
+	if err := json.Unmarshal(recs[0].Value, &s); err != nil {
+		return nil, false, err
+	}
+	return s, true, nil
+}
+
+// save the state of the execution
+func (f *Flow) save(s *State) error {
+	s.Updated = time.Now()
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return f.opts.Store.Write(&store.Record{
+		Key:   f.key(s.ID),
+		Value: b,
+		Metadata: map[string]interface{}{
+			"flow":   s.Flow,
+			"status": string(s.Status),
+		},
+	})
+}
+
+// attempt runs the action of the step with its retries and timeout
+func (f *Flow) attempt(ctx context.Context, step *Step, s *State, fn func(context.Context, *State) error) error {
+	retries := step.Retries
+	if retries == 0 {
+		retries = f.opts.Retries
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	timeout := step.Timeout
+	if timeout == 0 {
+		timeout = f.opts.Timeout
+	}
+
+	var err error
+	for i := 0; i <= retries; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(f.opts.Backoff(i)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		actx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			actx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		err = fn(actx, s)
+		cancel()
+
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+			logger.Debugf("Flow %s execution %s step %s attempt %d failed: %v", f.name, s.ID, step.Name, i+1, err)
+		}
+	}
+
+	return err
+}
+
+// execute runs or resumes the execution from its state
+func (f *Flow) execute(ctx context.Context, s *State) (*State, error) {
+	for s.Status == StatusRunning && s.Step < len(f.steps) {
+		if err := ctx.Err(); err != nil {
+			return s, err
+		}
+
+		step := f.steps[s.Step]
+		if err := f.attempt(ctx, step, s, step.Do); err != nil {
+			// the execution is left running to be resumed
+			if ctx.Err() != nil {
+				return s, ctx.Err()
+			}
+			s.Status = StatusCompensating
+			s.Failed = step.Name
+			s.Error = err.Error()
+		} else {
+			s.Step++
+			if s.Step == len(f.steps) {
+				s.Status = StatusCompleted
+			}
+		}
+
+		if err := f.save(s); err != nil {
+			return s, err
+		}
+	}
+
+	// undo the completed steps in reverse order
+	for s.Status == StatusCompensating {
+		if err := ctx.Err(); err != nil {
+			return s, err
+		}
+
+		if s.Step == 0 {
+			s.Status = StatusCompensated
+		} else if step := f.steps[s.Step-1]; step.Compensate == nil {
+			s.Step--
+		} else if err := f.attempt(ctx, step, s, step.Compensate); err != nil {
+			if ctx.Err() != nil {
+				return s, ctx.Err()
+			}
+			s.Status = StatusFailed
+			s.Error = err.Error()
+		} else {
+			s.Step--
+		}
+
+		if err := f.save(s); err != nil {
+			return s, err
+		}
+	}
+
+	switch s.Status {
+	case StatusCompensated, StatusFailed:
+		return s, errors.New(s.Error)
+	}
+	return s, nil
+}
+
+// Run the execution with the id, if it was interrupted it's resumed from the last completed
+// step and if it has finished its state is returned. The data is set on the state of a new
+// execution. An error is returned if a step failed, the state has the result of the compensation.
+func (f *Flow) Run(ctx context.Context, id string, data map[string]interface{}) (*State, error) {
+	if len(f.steps) == 0 {
+		return nil, ErrNoSteps
+	}
+	if len(id) == 0 {
+		return nil, ErrMissingID
+	}
+
+	s, ok, err := f.load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		s = &State{
+			ID:     id,
+			Flow:   f.name,
+			Status: StatusRunning,
+		}
+		for k, v := range data {
+			if err := s.Set(k, v); err != nil {
+				return nil, err
+			}
+		}
+		if err := f.save(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return f.execute(ctx, s)
+}
+
+// Resume the executions of the flow which haven't finished e.g after a crash. Only a
+// single instance of a service should resume the executions of a flow at a time.
+func (f *Flow) Resume(ctx context.Context) error {
+	keys, err := f.opts.Store.List(store.ListPrefix(f.key("")))
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		s, ok, err := f.load(k[len(f.key("")):])
+		if err != nil {
+			return err
+		}
+		if !ok || s.Done() {
+			continue
+		}
+
+		if _, err := f.execute(ctx, s); err != nil && logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Flow %s execution %s resumed with error: %v", f.name, s.ID, err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// State returns the state of the execution, store.ErrNotFound if it doesn't exist
+func (f *Flow) State(id string) (*State, error) {
+	s, ok, err := f.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return s, nil
+}
+
+// New returns a flow of the steps
+func New(name string, steps []*Step, opts ...Option) *Flow {
+	options := Options{
+		Store:   store.DefaultStore,
+		Retries: 2,
+		Backoff: backoff.Do,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Flow{
+		name:  name,
+		steps: steps,
+		opts:  options,
+	}
+}
@@ -0,0 +1,153 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/store/memory"
+)
+
+func noBackoff(int) time.Duration { return 0 }
+
+func TestRun(t *testing.T) {
+	var calls []string
+	record := func(name string, err error) func(context.Context, *State) error {
+		return func(ctx context.Context, s *State) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	testCases := []struct {
+		Name   string
+		Steps  []*Step
+		Status Status
+		Calls  []string
+	}{
+		{
+			Name: "Completed",
+			Steps: []*Step{
+				{Name: "a", Do: record("a", nil), Compensate: record("undo a", nil)},
+				{Name: "b", Do: record("b", nil), Compensate: record("undo b", nil)},
+			},
+			Status: StatusCompleted,
+			Calls:  []string{"a", "b"},
+		},
+		{
+			Name: "Compensated",
+			Steps: []*Step{
+				{Name: "a", Do: record("a", nil), Compensate: record("undo a", nil)},
+				{Name: "b", Do: record("b", nil)},
+				{Name: "c", Do: record("c", errors.New("boom")), Compensate: record("undo c", nil), Retries: 1},
+			},
+			Status: StatusCompensated,
+			Calls:  []string{"a", "b", "c", "c", "undo a"},
+		},
+		{
+			Name: "Failed",
+			Steps: []*Step{
+				{Name: "a", Do: record("a", nil), Compensate: record("undo a", errors.New("stuck")), Retries: -1},
+				{Name: "b", Do: record("b", errors.New("boom")), Retries: -1},
+			},
+			Status: StatusFailed,
+			Calls:  []string{"a", "b", "undo a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			calls = nil
+			st := memory.NewStore()
+			f := New("test", tc.Steps, Store(st), Backoff(noBackoff))
+
+			s, err := f.Run(context.Background(), "1", map[string]interface{}{"foo": "bar"})
+			if (err != nil) != (tc.Status != StatusCompleted) {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			if s.Status != tc.Status {
+				t.Fatalf("Expected status %v, got %v", tc.Status, s.Status)
+			}
+			if len(calls) != len(tc.Calls) {
+				t.Fatalf("Expected calls %v, got %v", tc.Calls, calls)
+			}
+			for i := range calls {
+				if calls[i] != tc.Calls[i] {
+					t.Fatalf("Expected calls %v, got %v", tc.Calls, calls)
+				}
+			}
+
+			// the finished state is persisted and running it again doesn't repeat the steps
+			calls = nil
+			s, _ = f.Run(context.Background(), "1", nil)
+			if s.Status != tc.Status || len(calls) > 0 {
+				t.Fatalf("Expected the persisted state, got %v and calls %v", s.Status, calls)
+			}
+			var foo string
+			if err := s.Get("foo", &foo); err != nil || foo != "bar" {
+				t.Fatalf("Expected the data to be persisted, got %v %v", foo, err)
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	f := New("test", []*Step{{
+		Name: "slow",
+		Do: func(ctx context.Context, s *State) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		Timeout: time.Millisecond * 10,
+		Retries: -1,
+	}}, Store(memory.NewStore()))
+
+	s, err := f.Run(context.Background(), "1", nil)
+	if err == nil || s.Status != StatusCompensated || s.Failed != "slow" {
+		t.Fatalf("Expected the step to time out, got %v %+v", err, s)
+	}
+}
+
+func TestResume(t *testing.T) {
+	st := memory.NewStore()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var ran []string
+	steps := []*Step{
+		{Name: "a", Do: func(ctx context.Context, s *State) error {
+			ran = append(ran, "a")
+			return s.Set("a", 1)
+		}},
+		{Name: "b", Do: func(ctx context.Context, s *State) error {
+			// crash after the first step
+			cancel()
+			return ctx.Err()
+		}},
+	}
+
+	f := New("test", steps, Store(st), Backoff(noBackoff))
+	if _, err := f.Run(ctx, "1", nil); err != context.Canceled {
+		t.Fatalf("Expected the execution to be interrupted, got %v", err)
+	}
+
+	s, err := f.State("1")
+	if err != nil || s.Status != StatusRunning || s.Step != 1 {
+		t.Fatalf("Expected a running execution at step 1, got %+v %v", s, err)
+	}
+
+	// a new instance resumes from the second step
+	steps[1].Do = func(ctx context.Context, s *State) error {
+		ran = append(ran, "b")
+		var a int
+		return s.Get("a", &a)
+	}
+	if err := New("test", steps, Store(st)).Resume(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ = f.State("1")
+	if s.Status != StatusCompleted || len(ran) != 2 || ran[1] != "b" {
+		t.Fatalf("Expected the execution to complete, got %v after %v", s.Status, ran)
+	}
+}
@@ -0,0 +1,48 @@
+package flow
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+type Options struct {
+	// Store the state of the executions is persisted to
+	Store store.Store
+	// Retries of a step or compensation if the step doesn't set them
+	Retries int
+	// Timeout of an attempt of a step if the step doesn't set one
+	Timeout time.Duration
+	// Backoff returns how long to wait before the attempt
+	Backoff func(attempt int) time.Duration
+}
+
+type Option func(o *Options)
+
+// Store to persist the state to, the default store is used if not set
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// Retries of the steps and compensations
+func Retries(n int) Option {
+	return func(o *Options) {
+		o.Retries = n
+	}
+}
+
+// Timeout of an attempt of the steps and compensations, zero is no timeout
+func Timeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
+
+// Backoff between the attempts of the steps and compensations
+func Backoff(fn func(attempt int) time.Duration) Option {
+	return func(o *Options) {
+		o.Backoff = fn
+	}
+}
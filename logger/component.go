@@ -0,0 +1,67 @@
+package logger
+
+import "sync"
+
+var (
+	componentMu     sync.RWMutex
+	componentLevels = make(map[string]Level)
+)
+
+// SetComponentLevel sets the level of the entries logged with the component field at
+// runtime, it overrides the level of the logger for them
+func SetComponentLevel(component string, level Level) {
+	componentMu.Lock()
+	componentLevels[component] = level
+	componentMu.Unlock()
+}
+
+// ResetComponentLevel logs the entries of the component at the level of the logger
+func ResetComponentLevel(component string) {
+	componentMu.Lock()
+	delete(componentLevels, component)
+	componentMu.Unlock()
+}
+
+// ComponentLevel returns the level set for the component
+func ComponentLevel(component string) (Level, bool) {
+	componentMu.RLock()
+	level, ok := componentLevels[component]
+	componentMu.RUnlock()
+	return level, ok
+}
+
+// EffectiveLevel returns the level of the component of the fields if set or
+// else the level, it's used by implementations to support component levels
+func EffectiveLevel(level Level, fields map[string]interface{}) Level {
+	if component, ok := fields[ComponentKey].(string); ok {
+		if l, ok := ComponentLevel(component); ok {
+			level = l
+		}
+	}
+	// entries of requests being debugged are logged at debug at least
+	if debug, ok := fields[DebugKey].(bool); ok && debug && level > DebugLevel {
+		level = DebugLevel
+	}
+	return level
+}
+
+// Component returns a helper which logs with the component field at the level of the
+// component, e.g logger.V(logger.DebugLevel, l) is true if the component is at debug
+func Component(name string, l Logger) *Helper {
+	if l == nil {
+		l = DefaultLogger
+	}
+	return NewHelper(l).WithFields(map[string]interface{}{ComponentKey: name})
+}
+
+// ComponentLevels returns the levels set for components
+func ComponentLevels() map[string]Level {
+	componentMu.RLock()
+	defer componentMu.RUnlock()
+
+	levels := make(map[string]Level, len(componentLevels))
+	for k, v := range componentLevels {
+		levels[k] = v
+	}
+	return levels
+}
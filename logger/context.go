@@ -1,6 +1,15 @@
 package logger
 
-import "context"
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/metadata"
+)
+
+// DebugHeader is the metadata which logs a request at the debug level when true, it's
+// passed on with the metadata so the request is debugged in every service it reaches.
+// The api removes it from the requests of callers.
+const DebugHeader = "Micro-Debug"
 
 type loggerKey struct{}
 
@@ -12,3 +21,32 @@ func FromContext(ctx context.Context) (Logger, bool) {
 func NewContext(ctx context.Context, l Logger) context.Context {
 	return context.WithValue(ctx, loggerKey{}, l)
 }
+
+// NewDebugContext flags the request of the context to be logged at the debug level
+func NewDebugContext(ctx context.Context) context.Context {
+	return metadata.Set(ctx, DebugHeader, "true")
+}
+
+// IsDebug returns whether the request of the context is logged at the debug level
+func IsDebug(ctx context.Context) bool {
+	v, ok := metadata.Get(ctx, DebugHeader)
+	return ok && v == "true"
+}
+
+// Extract returns the logger of the context or the default logger, it logs at
+// the debug level if the request of the context is being debugged
+func Extract(ctx context.Context) *Helper {
+	l, ok := FromContext(ctx)
+	if !ok {
+		l = DefaultLogger
+	}
+
+	h, ok := l.(*Helper)
+	if !ok {
+		h = NewHelper(l)
+	}
+	if IsDebug(ctx) {
+		h = h.WithFields(map[string]interface{}{DebugKey: true})
+	}
+	return h
+}
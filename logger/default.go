@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"runtime"
@@ -9,8 +10,6 @@ import (
 	"strings"
 	"sync"
 	"time"
-
-	dlog "github.com/micro/go-micro/v3/debug/log"
 )
 
 func init() {
@@ -24,14 +23,18 @@ func init() {
 
 type defaultLogger struct {
 	sync.RWMutex
-	opts Options
+	opts    Options
+	sampler *Sampler
 }
 
 // Init(opts...) should only overwrite provided options
 func (l *defaultLogger) Init(opts ...Option) error {
+	l.Lock()
+	defer l.Unlock()
 	for _, o := range opts {
 		o(&l.opts)
 	}
+	l.sampler = NewSampler(l.opts.Sampling)
 	return nil
 }
 
@@ -79,70 +82,68 @@ func logCallerfilePath(loggingFilePath string) string {
 }
 
 func (l *defaultLogger) Log(level Level, v ...interface{}) {
-	// TODO decide does we need to write message if log level not used?
-	if !l.opts.Level.Enabled(level) {
-		return
-	}
+	l.log(level, "", fmt.Sprint(v...))
+}
 
+func (l *defaultLogger) Logf(level Level, format string, v ...interface{}) {
+	l.log(level, format, fmt.Sprintf(format, v...))
+}
+
+// log the message, entries are sampled by the format if set so
+// messages with varying values count as one
+func (l *defaultLogger) log(level Level, format, msg string) {
 	l.RLock()
 	fields := copyFields(l.opts.Fields)
+	enabled := EffectiveLevel(l.opts.Level, fields).Enabled(level)
+	output := l.opts.Format
+	sampler := l.sampler
+	sinks := l.opts.Sinks
 	l.RUnlock()
 
-	fields["level"] = level.String()
-
-	if _, file, line, ok := runtime.Caller(l.opts.CallerSkipCount); ok {
-		fields["file"] = fmt.Sprintf("%s:%d", logCallerfilePath(file), line)
+	// TODO decide does we need to write message if log level not used?
+	if !enabled {
+		return
 	}
 
-	rec := dlog.Record{
-		Timestamp: time.Now(),
-		Message:   fmt.Sprint(v...),
-		Metadata:  make(map[string]string, len(fields)),
+	key := format
+	if len(key) == 0 {
+		key = msg
 	}
-
-	keys := make([]string, 0, len(fields))
-	for k, v := range fields {
-		keys = append(keys, k)
-		rec.Metadata[k] = fmt.Sprintf("%v", v)
-	}
-
-	sort.Strings(keys)
-	metadata := ""
-
-	for _, k := range keys {
-		metadata += fmt.Sprintf(" %s=%v", k, fields[k])
-	}
-
-	t := rec.Timestamp.Format("2006-01-02 15:04:05")
-	fmt.Printf("%s %s %v\n", t, metadata, rec.Message)
-}
-
-func (l *defaultLogger) Logf(level Level, format string, v ...interface{}) {
-	//	 TODO decide does we need to write message if log level not used?
-	if level < l.opts.Level {
+	if !sampler.Allow(level, key) {
 		return
 	}
 
-	l.RLock()
-	fields := copyFields(l.opts.Fields)
-	l.RUnlock()
-
-	fields["level"] = level.String()
+	fields[LevelKey] = level.String()
 
-	if _, file, line, ok := runtime.Caller(l.opts.CallerSkipCount); ok {
-		fields["file"] = fmt.Sprintf("%s:%d", logCallerfilePath(file), line)
+	if _, file, line, ok := runtime.Caller(l.opts.CallerSkipCount + 1); ok {
+		fields[FileKey] = fmt.Sprintf("%s:%d", logCallerfilePath(file), line)
 	}
 
-	rec := dlog.Record{
-		Timestamp: time.Now(),
-		Message:   fmt.Sprintf(format, v...),
-		Metadata:  make(map[string]string, len(fields)),
-	}
+	rec := NewRecord(msg, fields)
+	WriteSinks(sinks, rec)
 
 	keys := make([]string, 0, len(fields))
-	for k, v := range fields {
+	for k := range fields {
 		keys = append(keys, k)
-		rec.Metadata[k] = fmt.Sprintf("%v", v)
+	}
+
+	if output == JSONFormat {
+		fields[TimeKey] = rec.Timestamp.Format(time.RFC3339)
+		fields[MessageKey] = msg
+		if err, ok := fields[ErrorKey].(error); ok {
+			fields[ErrorKey] = err.Error()
+		}
+		b, err := json.Marshal(fields)
+		if err != nil {
+			b, _ = json.Marshal(map[string]string{
+				TimeKey:    fields[TimeKey].(string),
+				LevelKey:   level.String(),
+				MessageKey: msg,
+				ErrorKey:   err.Error(),
+			})
+		}
+		fmt.Printf("%s\n", b)
+		return
 	}
 
 	sort.Strings(keys)
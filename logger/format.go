@@ -0,0 +1,39 @@
+package logger
+
+// Format of the log entries
+type Format int
+
+const (
+	// TextFormat is the time followed by the fields and message
+	TextFormat Format = iota
+	// JSONFormat is a JSON object per entry with the fields and the keys below
+	JSONFormat
+)
+
+// The keys of the fields every logger writes, they're stable so entries can be parsed
+const (
+	// TimeKey is the time of the entry
+	TimeKey = "time"
+	// LevelKey is the level of the entry
+	LevelKey = "level"
+	// MessageKey is the message of the entry
+	MessageKey = "message"
+	// FileKey is the file:line the entry was logged from
+	FileKey = "file"
+	// ErrorKey is the error of the entry
+	ErrorKey = "error"
+	// ComponentKey is the component the entry was logged by, see SetComponentLevel
+	ComponentKey = "component"
+	// DebugKey is set on the entries of requests being debugged, see NewDebugContext
+	DebugKey = "debug"
+)
+
+func (f Format) String() string {
+	switch f {
+	case TextFormat:
+		return "text"
+	case JSONFormat:
+		return "json"
+	}
+	return ""
+}
@@ -1,7 +1,14 @@
 package logger
 
 import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
 	"testing"
+	"time"
+
+	dlog "github.com/micro/go-micro/v3/debug/log"
 )
 
 func TestLogger(t *testing.T) {
@@ -16,3 +23,133 @@ func TestLogger(t *testing.T) {
 
 	l.Fields(map[string]interface{}{"key3": "val4"}).Log(InfoLevel, "test_msg")
 }
+
+func TestSampler(t *testing.T) {
+	s := NewSampler(&Sampling{Tick: time.Minute, First: 2, Thereafter: 3})
+
+	var logged int
+	for i := 0; i < 8; i++ {
+		if s.Allow(DebugLevel, "msg") {
+			logged++
+		}
+	}
+	// the first 2 then the 5th and 8th
+	if logged != 4 {
+		t.Fatalf("Expected 4 entries to be logged, got %d", logged)
+	}
+
+	for i := 0; i < 8; i++ {
+		if !s.Allow(InfoLevel, "msg") {
+			t.Fatal("Expected info entries not to be sampled")
+		}
+	}
+}
+
+func TestComponentLevel(t *testing.T) {
+	l := NewLogger(WithLevel(InfoLevel))
+	c := Component("test", l)
+
+	if V(DebugLevel, c) {
+		t.Fatal("Expected the component to log at the level of the logger")
+	}
+
+	SetComponentLevel("test", DebugLevel)
+	defer ResetComponentLevel("test")
+
+	if !V(DebugLevel, c) {
+		t.Fatal("Expected the component to log at debug")
+	}
+	if V(DebugLevel, l) {
+		t.Fatal("Expected the logger to log at info")
+	}
+
+	h := NewHelper(l).WithFields(map[string]interface{}{ComponentKey: "test"})
+	if !h.enabled(DebugLevel) {
+		t.Fatal("Expected the helper of the component to log at debug")
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+
+	l := NewLogger(WithFormat(JSONFormat))
+	NewHelper(l).WithFields(map[string]interface{}{"key": "val"}).Infof("hello %s", "world")
+
+	os.Stdout = stdout
+	w.Close()
+
+	var entry map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&entry); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range []string{TimeKey, LevelKey, MessageKey, FileKey, "key"} {
+		if _, ok := entry[k]; !ok {
+			t.Fatalf("Expected the %s field in %v", k, entry)
+		}
+	}
+	if entry[MessageKey] != "hello world" || entry[LevelKey] != "info" {
+		t.Fatalf("Unexpected entry %v", entry)
+	}
+	if file := entry[FileKey].(string); !strings.HasPrefix(file, "logger/logger_test.go") {
+		t.Fatalf("Expected the file of the caller, got %s", file)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	l := NewLogger(WithLevel(InfoLevel))
+	ctx := NewContext(context.Background(), l)
+
+	if V(DebugLevel, Extract(ctx)) {
+		t.Fatal("Expected the request to be logged at info")
+	}
+
+	ctx = NewDebugContext(ctx)
+	if !IsDebug(ctx) {
+		t.Fatal("Expected the request to be debugged")
+	}
+	if !V(DebugLevel, Extract(ctx)) {
+		t.Fatal("Expected the request to be logged at debug")
+	}
+	if V(DebugLevel, l) {
+		t.Fatal("Expected the logger to log at info")
+	}
+}
+
+type testSink struct {
+	records []dlog.Record
+}
+
+func (s *testSink) Write(rec dlog.Record) error {
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func (s *testSink) Close() error {
+	return nil
+}
+
+func (s *testSink) String() string {
+	return "test"
+}
+
+func TestSinks(t *testing.T) {
+	s := new(testSink)
+	l := NewHelper(NewLogger(WithSinks(s)))
+
+	l.WithFields(map[string]interface{}{"key": "val"}).Info("hello")
+	l.Debug("not logged")
+
+	if len(s.records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(s.records))
+	}
+	rec := s.records[0]
+	if rec.Message != "hello" || rec.Metadata["key"] != "val" || rec.Metadata[LevelKey] != "info" {
+		t.Fatalf("Unexpected record %+v", rec)
+	}
+}
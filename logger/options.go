@@ -3,6 +3,7 @@ package logger
 import (
 	"context"
 	"io"
+	"time"
 )
 
 type Option func(*Options)
@@ -16,6 +17,12 @@ type Options struct {
 	Out io.Writer
 	// Caller skip frame count for file:line info
 	CallerSkipCount int
+	// Format of the entries, text by default
+	Format Format
+	// Sampling of the debug and trace entries, all are logged if nil
+	Sampling *Sampling
+	// Sinks the records of the entries are written to as well as the output
+	Sinks []Sink
 	// Alternative options
 	Context context.Context
 }
@@ -48,6 +55,32 @@ func WithCallerSkipCount(c int) Option {
 	}
 }
 
+// WithFormat sets the format of the entries e.g JSONFormat
+func WithFormat(f Format) Option {
+	return func(args *Options) {
+		args.Format = f
+	}
+}
+
+// WithSampling logs the first entries of a debug or trace message in each tick
+// and every thereafter entry after that, so high volume messages don't flood the logs
+func WithSampling(tick time.Duration, first, thereafter int) Option {
+	return func(args *Options) {
+		args.Sampling = &Sampling{
+			Tick:       tick,
+			First:      first,
+			Thereafter: thereafter,
+		}
+	}
+}
+
+// WithSinks ships the records of the entries to the sinks e.g a log aggregator
+func WithSinks(sinks ...Sink) Option {
+	return func(args *Options) {
+		args.Sinks = sinks
+	}
+}
+
 func SetOption(k, v interface{}) Option {
 	return func(o *Options) {
 		if o.Context == nil {
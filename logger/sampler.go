@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Sampling of high volume debug and trace entries, the first entries of a message in
+// each tick are logged then every thereafter entry
+type Sampling struct {
+	Tick       time.Duration
+	First      int
+	Thereafter int
+}
+
+// Sampler decides which entries are logged, it's used by implementations
+// to support the WithSampling option
+type Sampler struct {
+	opts Sampling
+
+	sync.Mutex
+	reset  time.Time
+	counts map[string]int
+}
+
+// NewSampler returns a sampler, all entries are logged if the sampling is nil
+func NewSampler(s *Sampling) *Sampler {
+	if s == nil {
+		return nil
+	}
+	return &Sampler{
+		opts:   *s,
+		counts: make(map[string]int),
+	}
+}
+
+// Allow returns whether the entry of the level with the message is logged,
+// entries above the debug level are always logged
+func (s *Sampler) Allow(level Level, msg string) bool {
+	if s == nil || level > DebugLevel {
+		return true
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	// counts start again each tick
+	if now := time.Now(); now.Sub(s.reset) >= s.opts.Tick {
+		s.reset = now
+		s.counts = make(map[string]int)
+	}
+
+	s.counts[msg]++
+	n := s.counts[msg]
+
+	if n <= s.opts.First {
+		return true
+	}
+	return s.opts.Thereafter > 0 && (n-s.opts.First)%s.opts.Thereafter == 0
+}
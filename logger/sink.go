@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+
+	dlog "github.com/micro/go-micro/v3/debug/log"
+)
+
+// Sink ships the records of the entries logged e.g to a log aggregator, the metadata
+// of a record is the fields of the entry
+type Sink interface {
+	// Write the record, it mustn't block for long as it's called when logging
+	Write(dlog.Record) error
+	// Close the sink, shipping the records written
+	Close() error
+	// String returns the name of the sink
+	String() string
+}
+
+// WriteSinks writes the record of an entry to the sinks, it's used by
+// implementations to support the WithSinks option
+func WriteSinks(sinks []Sink, rec dlog.Record) {
+	for _, s := range sinks {
+		s.Write(rec)
+	}
+}
+
+// NewRecord returns the record of an entry with the fields as the metadata
+func NewRecord(msg string, fields map[string]interface{}) dlog.Record {
+	rec := dlog.Record{
+		Timestamp: time.Now(),
+		Message:   msg,
+		Metadata:  make(map[string]string, len(fields)),
+	}
+	for k, v := range fields {
+		rec.Metadata[k] = fmt.Sprintf("%v", v)
+	}
+	return rec
+}
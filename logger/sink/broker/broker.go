@@ -0,0 +1,28 @@
+// Package broker is a sink which publishes the records of a logger to a broker topic
+package broker
+
+import (
+	"encoding/json"
+
+	"github.com/micro/go-micro/v3/broker"
+	dlog "github.com/micro/go-micro/v3/debug/log"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/logger/sink"
+)
+
+// NewSink returns a sink which publishes batches of records to the topic
+// as a JSON array of records
+func NewSink(b broker.Broker, topic string, opts ...sink.Option) logger.Sink {
+	return sink.NewSink("broker", func(recs []dlog.Record) error {
+		body, err := json.Marshal(recs)
+		if err != nil {
+			return err
+		}
+		return b.Publish(topic, &broker.Message{
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: body,
+		})
+	}, opts...)
+}
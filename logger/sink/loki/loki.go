@@ -0,0 +1,134 @@
+// Package loki is a sink which pushes the records of a logger to Grafana Loki
+package loki
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dlog "github.com/micro/go-micro/v3/debug/log"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/logger/sink"
+)
+
+var (
+	// DefaultPath is the path of the push api
+	DefaultPath = "/loki/api/v1/push"
+	// DefaultTimeout of pushing a batch
+	DefaultTimeout = time.Second * 10
+)
+
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type pushRequest struct {
+	Streams []stream `json:"streams"`
+}
+
+type lokiSink struct {
+	url    string
+	labels map[string]string
+	tenant string
+	client *http.Client
+}
+
+// streamKey identifies the stream of the labels
+func streamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k, v := range labels {
+		keys = append(keys, k+"="+v)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// push the records to streams by their level
+func (l *lokiSink) push(recs []dlog.Record) error {
+	streams := make(map[string]*stream)
+	var order []string
+
+	for _, rec := range recs {
+		labels := make(map[string]string, len(l.labels)+1)
+		for k, v := range l.labels {
+			labels[k] = v
+		}
+		if level, ok := rec.Metadata[logger.LevelKey]; ok {
+			labels[logger.LevelKey] = level
+		}
+
+		key := streamKey(labels)
+		st, ok := streams[key]
+		if !ok {
+			st = &stream{Stream: labels}
+			streams[key] = st
+			order = append(order, key)
+		}
+		st.Values = append(st.Values, [2]string{
+			strconv.FormatInt(rec.Timestamp.UnixNano(), 10),
+			string(sink.JSON(rec)),
+		})
+	}
+
+	req := pushRequest{Streams: make([]stream, 0, len(streams))}
+	for _, key := range order {
+		req.Streams = append(req.Streams, *streams[key])
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	hreq, err := http.NewRequest(http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	if len(l.tenant) > 0 {
+		hreq.Header.Set("X-Scope-OrgID", l.tenant)
+	}
+
+	rsp, err := l.client.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode/100 != 2 {
+		b, _ := ioutil.ReadAll(rsp.Body)
+		return fmt.Errorf("loki push failed: %s: %s", rsp.Status, strings.TrimSpace(string(b)))
+	}
+
+	return nil
+}
+
+// NewSink returns a sink which pushes batches of records to loki at the address
+// e.g http://localhost:3100, each record is a line of JSON
+func NewSink(address string, opts ...sink.Option) logger.Sink {
+	options := sink.NewOptions(opts...)
+
+	l := &lokiSink{
+		url:    strings.TrimSuffix(address, "/") + DefaultPath,
+		labels: map[string]string{},
+		client: &http.Client{Timeout: DefaultTimeout},
+	}
+
+	if options.Context != nil {
+		if labels, ok := options.Context.Value(labelsKey{}).(map[string]string); ok {
+			l.labels = labels
+		}
+		if tenant, ok := options.Context.Value(tenantKey{}).(string); ok {
+			l.tenant = tenant
+		}
+	}
+
+	return sink.NewSink("loki", l.push, opts...)
+}
@@ -0,0 +1,55 @@
+package loki
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dlog "github.com/micro/go-micro/v3/debug/log"
+)
+
+func TestLoki(t *testing.T) {
+	reqs := make(chan pushRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != DefaultPath || r.Header.Get("X-Scope-OrgID") != "tenant" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		var req pushRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reqs <- req
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	s := NewSink(srv.URL, Labels(map[string]string{"service": "test"}), Tenant("tenant"))
+	s.Write(dlog.Record{Timestamp: time.Now(), Message: "one", Metadata: map[string]string{"level": "info"}})
+	s.Write(dlog.Record{Timestamp: time.Now(), Message: "two", Metadata: map[string]string{"level": "error"}})
+	s.Write(dlog.Record{Timestamp: time.Now(), Message: "three", Metadata: map[string]string{"level": "info"}})
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-reqs
+	if len(req.Streams) != 2 {
+		t.Fatalf("Expected a stream per level, got %d", len(req.Streams))
+	}
+	info := req.Streams[0]
+	if info.Stream["service"] != "test" || info.Stream["level"] != "info" || len(info.Values) != 2 {
+		t.Fatalf("Unexpected stream %+v", info)
+	}
+
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(info.Values[1][1]), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line["message"] != "three" {
+		t.Fatalf("Expected the message three, got %v", line)
+	}
+}
@@ -0,0 +1,32 @@
+package loki
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/logger/sink"
+)
+
+type labelsKey struct{}
+
+type tenantKey struct{}
+
+// Labels sets the labels of the stream the records are pushed to, the level
+// of a record is added as a label
+func Labels(labels map[string]string) sink.Option {
+	return func(o *sink.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, labelsKey{}, labels)
+	}
+}
+
+// Tenant sets the tenant the records are pushed for in a multi tenant loki
+func Tenant(id string) sink.Option {
+	return func(o *sink.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, tenantKey{}, id)
+	}
+}
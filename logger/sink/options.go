@@ -0,0 +1,66 @@
+package sink
+
+import (
+	"context"
+	"time"
+)
+
+// Options of the batching of a sink
+type Options struct {
+	// BatchSize is the most records shipped at once
+	BatchSize int
+	// FlushInterval is how often records are shipped if the batch isn't full
+	FlushInterval time.Duration
+	// BufferSize is the most records waiting to be shipped, further records
+	// are dropped or block the logger depending on Block
+	BufferSize int
+	// Block the logger rather than dropping records when the buffer is full
+	Block bool
+	// Other options for implementations of the interface
+	// can be stored in a context
+	Context context.Context
+}
+
+// Option sets an option of a sink
+type Option func(o *Options)
+
+// NewOptions returns the options with the defaults set
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		BatchSize:     DefaultBatchSize,
+		FlushInterval: DefaultFlushInterval,
+		BufferSize:    DefaultBufferSize,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
+
+// BatchSize sets the most records shipped at once
+func BatchSize(n int) Option {
+	return func(o *Options) {
+		o.BatchSize = n
+	}
+}
+
+// FlushInterval sets how often records are shipped if the batch isn't full
+func FlushInterval(d time.Duration) Option {
+	return func(o *Options) {
+		o.FlushInterval = d
+	}
+}
+
+// BufferSize sets the most records waiting to be shipped
+func BufferSize(n int) Option {
+	return func(o *Options) {
+		o.BufferSize = n
+	}
+}
+
+// Block the logger rather than dropping records when the buffer is full
+func Block(b bool) Option {
+	return func(o *Options) {
+		o.Block = b
+	}
+}
@@ -0,0 +1,195 @@
+// Package sink batches the records of a logger sink so shipping them doesn't slow the
+// logger down. Records wait in a buffer which is shipped in batches, when the
+// destination is slow or down the buffer fills and further records are dropped or
+// block the logger.
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	dlog "github.com/micro/go-micro/v3/debug/log"
+	"github.com/micro/go-micro/v3/logger"
+)
+
+var (
+	// DefaultBatchSize is the most records shipped at once
+	DefaultBatchSize = 100
+	// DefaultFlushInterval is how often records are shipped if the batch isn't full
+	DefaultFlushInterval = time.Second
+	// DefaultBufferSize is the most records waiting to be shipped
+	DefaultBufferSize = 10000
+
+	// ErrBufferFull is returned when a record is dropped as the buffer is full
+	ErrBufferFull = errors.New("sink buffer full")
+	// ErrClosed is returned when writing to a closed sink
+	ErrClosed = errors.New("sink closed")
+)
+
+// FlushFunc ships a batch of records
+type FlushFunc func([]dlog.Record) error
+
+type batchSink struct {
+	name  string
+	opts  Options
+	flush FlushFunc
+
+	records chan dlog.Record
+	exit    chan bool
+	done    chan error
+	once    sync.Once
+
+	// records dropped as the buffer was full
+	dropped uint64
+}
+
+// NewSink returns a sink which ships the records in batches with the function, a
+// batch which fails to ship is retried at the flush interval while records buffer
+func NewSink(name string, flush FlushFunc, opts ...Option) logger.Sink {
+	options := NewOptions(opts...)
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = DefaultFlushInterval
+	}
+
+	s := &batchSink{
+		name:    name,
+		opts:    options,
+		flush:   flush,
+		records: make(chan dlog.Record, options.BufferSize),
+		exit:    make(chan bool),
+		done:    make(chan error, 1),
+	}
+
+	go s.run()
+
+	return s
+}
+
+func (s *batchSink) Write(rec dlog.Record) error {
+	select {
+	case <-s.exit:
+		return ErrClosed
+	default:
+	}
+
+	if s.opts.Block {
+		select {
+		case s.records <- rec:
+			return nil
+		case <-s.exit:
+			return ErrClosed
+		}
+	}
+
+	select {
+	case s.records <- rec:
+		return nil
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+		return ErrBufferFull
+	}
+}
+
+// run ships the records until the sink is closed
+func (s *batchSink) run() {
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]dlog.Record, 0, s.opts.BatchSize)
+
+	ship := func() {
+		if len(batch) == 0 {
+			return
+		}
+		// keep the batch to retry it if it fails
+		if err := s.flush(batch); err != nil {
+			return
+		}
+		batch = make([]dlog.Record, 0, s.opts.BatchSize)
+	}
+
+	for {
+		// stop reading records while the batch is full so the buffer
+		// applies backpressure when the destination is down
+		records := s.records
+		if len(batch) >= s.opts.BatchSize {
+			records = nil
+		}
+
+		select {
+		case rec := <-records:
+			batch = append(batch, rec)
+			if len(batch) >= s.opts.BatchSize {
+				ship()
+			}
+		case <-ticker.C:
+			ship()
+		case <-s.exit:
+			s.done <- s.drain(batch)
+			return
+		}
+	}
+}
+
+// drain ships the batch and the buffered records once
+func (s *batchSink) drain(batch []dlog.Record) error {
+	for {
+		select {
+		case rec := <-s.records:
+			batch = append(batch, rec)
+			if len(batch) < s.opts.BatchSize {
+				continue
+			}
+			if err := s.flush(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		default:
+			if len(batch) == 0 {
+				return nil
+			}
+			return s.flush(batch)
+		}
+	}
+}
+
+// Close ships the records written and stops the sink
+func (s *batchSink) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.exit)
+		err = <-s.done
+	})
+	return err
+}
+
+func (s *batchSink) String() string {
+	return s.name
+}
+
+// Dropped returns the number of records the sink dropped as its buffer was full
+func Dropped(s logger.Sink) uint64 {
+	if b, ok := s.(*batchSink); ok {
+		return atomic.LoadUint64(&b.dropped)
+	}
+	return 0
+}
+
+// JSON returns the record as a JSON object of the metadata and message, the keys are
+// those of the logger so records shipped as text can be parsed
+func JSON(rec dlog.Record) []byte {
+	fields := make(map[string]interface{}, len(rec.Metadata)+2)
+	for k, v := range rec.Metadata {
+		fields[k] = v
+	}
+	fields[logger.TimeKey] = rec.Timestamp.Format(time.RFC3339Nano)
+	fields[logger.MessageKey] = rec.Message
+	b, _ := json.Marshal(fields)
+	return b
+}
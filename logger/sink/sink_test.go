@@ -0,0 +1,93 @@
+package sink
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	dlog "github.com/micro/go-micro/v3/debug/log"
+)
+
+type testFlusher struct {
+	sync.Mutex
+	fail    bool
+	batches [][]dlog.Record
+}
+
+func (f *testFlusher) flush(recs []dlog.Record) error {
+	f.Lock()
+	defer f.Unlock()
+	if f.fail {
+		return errors.New("failed")
+	}
+	f.batches = append(f.batches, append([]dlog.Record{}, recs...))
+	return nil
+}
+
+func (f *testFlusher) count() (int, int) {
+	f.Lock()
+	defer f.Unlock()
+	var n int
+	for _, b := range f.batches {
+		n += len(b)
+	}
+	return len(f.batches), n
+}
+
+func TestSink(t *testing.T) {
+	f := new(testFlusher)
+	s := NewSink("test", f.flush, BatchSize(2), FlushInterval(time.Hour))
+
+	for i := 0; i < 5; i++ {
+		if err := s.Write(dlog.Record{Message: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the full batches are shipped and the rest on close
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if batches, n := f.count(); batches != 3 || n != 5 {
+		t.Fatalf("Expected 5 records in 3 batches, got %d in %d", n, batches)
+	}
+
+	if err := s.Write(dlog.Record{}); err != ErrClosed {
+		t.Fatalf("Expected %v got %v", ErrClosed, err)
+	}
+}
+
+func TestSinkBackpressure(t *testing.T) {
+	f := &testFlusher{fail: true}
+	s := NewSink("test", f.flush, BatchSize(1), BufferSize(2), FlushInterval(time.Millisecond*10))
+
+	// one record is held in the failing batch and two are buffered
+	var dropped int
+	for i := 0; i < 10; i++ {
+		if err := s.Write(dlog.Record{Message: i}); err == ErrBufferFull {
+			dropped++
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if dropped == 0 || uint64(dropped) != Dropped(s) {
+		t.Fatalf("Expected records to be dropped, got %d and %d", dropped, Dropped(s))
+	}
+
+	// the batch is retried once the destination recovers
+	f.Lock()
+	f.fail = false
+	f.Unlock()
+
+	time.Sleep(time.Millisecond * 50)
+	if _, n := f.count(); n == 0 {
+		t.Fatal("Expected the failed batch to be retried")
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, n := f.count(); n != 10-dropped {
+		t.Fatalf("Expected %d records, got %d", 10-dropped, n)
+	}
+}
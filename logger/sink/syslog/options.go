@@ -0,0 +1,31 @@
+package syslog
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/logger/sink"
+)
+
+type tagKey struct{}
+
+type facilityKey struct{}
+
+// Tag sets the app name of the messages, the name of the program by default
+func Tag(t string) sink.Option {
+	return func(o *sink.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, tagKey{}, t)
+	}
+}
+
+// Facility sets the facility of the messages, local0 (16) by default
+func Facility(f int) sink.Option {
+	return func(o *sink.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, facilityKey{}, f)
+	}
+}
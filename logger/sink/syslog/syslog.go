@@ -0,0 +1,146 @@
+// Package syslog is a sink which sends the records of a logger to syslog as RFC 5424
+// messages over udp, tcp or a unix socket
+package syslog
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	dlog "github.com/micro/go-micro/v3/debug/log"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/logger/sink"
+)
+
+var (
+	// DefaultFacility is local0
+	DefaultFacility = 16
+	// DefaultTimeout of dialling and writing
+	DefaultTimeout = time.Second * 10
+)
+
+type syslogSink struct {
+	network  string
+	address  string
+	tag      string
+	facility int
+	hostname string
+
+	sync.Mutex
+	conn net.Conn
+}
+
+// severity of the level of the record
+func severity(level string) int {
+	switch level {
+	case logger.TraceLevel.String(), logger.DebugLevel.String():
+		return 7
+	case logger.WarnLevel.String():
+		return 4
+	case logger.ErrorLevel.String():
+		return 3
+	case logger.FatalLevel.String():
+		return 2
+	default:
+		return 6
+	}
+}
+
+// message formats the record as a RFC 5424 message with the record as JSON
+func (s *syslogSink) message(rec dlog.Record) []byte {
+	pri := s.facility*8 + severity(rec.Metadata[logger.LevelKey])
+	return []byte(fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		pri,
+		rec.Timestamp.Format(time.RFC3339Nano),
+		s.hostname,
+		s.tag,
+		os.Getpid(),
+		sink.JSON(rec),
+	))
+}
+
+// send the records, the connection is dialled again after an error
+func (s *syslogSink) send(recs []dlog.Record) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.address, DefaultTimeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(DefaultTimeout))
+
+	for _, rec := range recs {
+		msg := s.message(rec)
+		// stream transports frame messages by octet counting (RFC 6587)
+		if s.network == "tcp" || s.network == "unix" {
+			msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+		}
+		if _, err := s.conn.Write(msg); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeSink closes the connection once the records are sent
+type closeSink struct {
+	logger.Sink
+	syslog *syslogSink
+}
+
+func (c *closeSink) Close() error {
+	err := c.Sink.Close()
+
+	c.syslog.Lock()
+	if c.syslog.conn != nil {
+		c.syslog.conn.Close()
+		c.syslog.conn = nil
+	}
+	c.syslog.Unlock()
+
+	return err
+}
+
+// NewSink returns a sink which sends batches of records to the syslog server at the
+// address on the network e.g udp, tcp, unix or unixgram
+func NewSink(network, address string, opts ...sink.Option) logger.Sink {
+	options := sink.NewOptions(opts...)
+
+	hostname, _ := os.Hostname()
+	if len(hostname) == 0 {
+		hostname = "-"
+	}
+
+	s := &syslogSink{
+		network:  network,
+		address:  address,
+		tag:      filepath.Base(os.Args[0]),
+		facility: DefaultFacility,
+		hostname: hostname,
+	}
+
+	if options.Context != nil {
+		if t, ok := options.Context.Value(tagKey{}).(string); ok {
+			s.tag = t
+		}
+		if f, ok := options.Context.Value(facilityKey{}).(int); ok {
+			s.facility = f
+		}
+	}
+
+	return &closeSink{
+		Sink:   sink.NewSink("syslog", s.send, opts...),
+		syslog: s,
+	}
+}
@@ -0,0 +1,37 @@
+package syslog
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	dlog "github.com/micro/go-micro/v3/debug/log"
+)
+
+func TestSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	s := NewSink("udp", conn.LocalAddr().String(), Tag("test"))
+	s.Write(dlog.Record{Timestamp: time.Now(), Message: "hello", Metadata: map[string]string{"level": "error"}})
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	b := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// local0 and error is 16*8+3
+	msg := string(b[:n])
+	if !strings.HasPrefix(msg, "<131>1 ") || !strings.Contains(msg, " test ") || !strings.Contains(msg, `"message":"hello"`) {
+		t.Fatalf("Unexpected message %s", msg)
+	}
+}
@@ -0,0 +1,68 @@
+package wrapper
+
+import (
+	"github.com/micro/go-micro/v3/logger"
+)
+
+type Options struct {
+	// Logger the requests are logged with, the default logger if not set
+	Logger logger.Logger
+	// Level the successful requests are logged at, failed requests are logged at the error level
+	Level logger.Level
+	// Sample is the fraction of the requests whose payloads are captured e.g 0.01 for 1%,
+	// the payloads of requests being debugged are always captured
+	Sample float64
+	// MaxPayload is the max size of a captured payload, larger payloads are truncated
+	MaxPayload int
+	// Redact lists the fields of the payloads whose values are never logged
+	Redact []string
+}
+
+type Option func(o *Options)
+
+func newOptions(opts ...Option) Options {
+	options := Options{
+		Level:      logger.InfoLevel,
+		MaxPayload: 4096,
+		Redact:     DefaultRedact,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
+
+// WithLogger sets the logger the requests are logged with
+func WithLogger(l logger.Logger) Option {
+	return func(o *Options) {
+		o.Logger = l
+	}
+}
+
+// WithLevel sets the level the successful requests are logged at
+func WithLevel(l logger.Level) Option {
+	return func(o *Options) {
+		o.Level = l
+	}
+}
+
+// Sample sets the fraction of the requests whose payloads are captured
+func Sample(rate float64) Option {
+	return func(o *Options) {
+		o.Sample = rate
+	}
+}
+
+// MaxPayload sets the max size of a captured payload
+func MaxPayload(size int) Option {
+	return func(o *Options) {
+		o.MaxPayload = size
+	}
+}
+
+// Redact adds fields whose values are never logged to the default ones
+func Redact(fields ...string) Option {
+	return func(o *Options) {
+		o.Redact = append(append([]string{}, o.Redact...), fields...)
+	}
+}
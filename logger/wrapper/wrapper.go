@@ -0,0 +1,157 @@
+// Package wrapper logs the requests served by a server and made by a client as structured entries
+// with their duration, result and trace, optionally capturing a sample of the payloads
+package wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/micro/go-micro/v3/client"
+	jsonCodec "github.com/micro/go-micro/v3/codec/json"
+	"github.com/micro/go-micro/v3/debug/trace"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/server"
+)
+
+var (
+	// DefaultRedact is the fields of the payloads which are redacted by default
+	DefaultRedact = []string{"password", "secret", "token", "authorization"}
+	// Replacement of the redacted values
+	Replacement = "[redacted]"
+)
+
+type requestLogger struct {
+	opts      Options
+	marshaler jsonCodec.Marshaler
+}
+
+func (l *requestLogger) logger() logger.Logger {
+	if l.opts.Logger != nil {
+		return l.opts.Logger
+	}
+	return logger.DefaultLogger
+}
+
+// capture returns whether the payloads of the request are logged
+func (l *requestLogger) capture(ctx context.Context) bool {
+	return logger.IsDebug(ctx) || (l.opts.Sample > 0 && rand.Float64() < l.opts.Sample)
+}
+
+// payload returns the redacted json of the payload
+func (l *requestLogger) payload(v interface{}) string {
+	b, err := l.marshaler.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err == nil {
+		if b, err = json.Marshal(l.redact(data)); err != nil {
+			return ""
+		}
+	}
+
+	if l.opts.MaxPayload > 0 && len(b) > l.opts.MaxPayload {
+		b = b[:l.opts.MaxPayload]
+	}
+	return string(b)
+}
+
+// redact replaces the values of the redacted fields at any depth
+func (l *requestLogger) redact(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range t {
+			t[k] = l.redact(fv)
+			for _, f := range l.opts.Redact {
+				if strings.EqualFold(k, f) {
+					t[k] = Replacement
+					break
+				}
+			}
+		}
+	case []interface{}:
+		for i, iv := range t {
+			t[i] = l.redact(iv)
+		}
+	}
+	return v
+}
+
+func (l *requestLogger) log(ctx context.Context, kind, service, endpoint string, req, rsp interface{}, started time.Time, err error) {
+	level := l.opts.Level
+	if err != nil {
+		level = logger.ErrorLevel
+	}
+	log := l.logger()
+	if !logger.V(level, log) {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"kind":     kind,
+		"service":  service,
+		"endpoint": endpoint,
+		"duration": time.Since(started).String(),
+	}
+	if traceID, spanID, ok := trace.FromContext(ctx); ok {
+		fields["trace"] = traceID
+		fields["span"] = spanID
+	}
+	if err != nil {
+		fields["code"] = errors.FromError(err).Code
+		fields["error"] = err.Error()
+	}
+	if req != nil && l.capture(ctx) {
+		fields["request"] = l.payload(req)
+		if err == nil && rsp != nil {
+			fields["response"] = l.payload(rsp)
+		}
+	}
+
+	log.Fields(fields).Logf(level, "%s %s %s", kind, service, endpoint)
+}
+
+// NewHandlerWrapper returns a handler wrapper which logs the requests served
+func NewHandlerWrapper(opts ...Option) server.HandlerWrapper {
+	l := &requestLogger{opts: newOptions(opts...)}
+
+	return func(fn server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			started := time.Now()
+			err := fn(ctx, req, rsp)
+			if req.Stream() {
+				// the stream is the request and response
+				l.log(ctx, "stream", req.Service(), req.Endpoint(), nil, nil, started, err)
+			} else {
+				l.log(ctx, "served", req.Service(), req.Endpoint(), req.Body(), rsp, started, err)
+			}
+			return err
+		}
+	}
+}
+
+type logClient struct {
+	client.Client
+	logger *requestLogger
+}
+
+func (c *logClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	started := time.Now()
+	err := c.Client.Call(ctx, req, rsp, opts...)
+	c.logger.log(ctx, "call", req.Service(), req.Endpoint(), req.Body(), rsp, started, err)
+	return err
+}
+
+// NewClientWrapper returns a client wrapper which logs the calls made
+func NewClientWrapper(opts ...Option) client.Wrapper {
+	l := &requestLogger{opts: newOptions(opts...)}
+
+	return func(c client.Client) client.Client {
+		return &logClient{Client: c, logger: l}
+	}
+}
@@ -0,0 +1,81 @@
+package wrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/v3/codec"
+	dlog "github.com/micro/go-micro/v3/debug/log"
+	"github.com/micro/go-micro/v3/debug/trace"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/server"
+)
+
+type testRequest struct {
+	body interface{}
+}
+
+func (r *testRequest) Service() string           { return "test" }
+func (r *testRequest) Method() string            { return "Foo.Bar" }
+func (r *testRequest) Endpoint() string          { return "Foo.Bar" }
+func (r *testRequest) ContentType() string       { return "application/json" }
+func (r *testRequest) Header() map[string]string { return nil }
+func (r *testRequest) Body() interface{}         { return r.body }
+func (r *testRequest) Read() ([]byte, error)     { return nil, nil }
+func (r *testRequest) Codec() codec.Reader       { return nil }
+func (r *testRequest) Stream() bool              { return false }
+
+type testSink struct {
+	records []dlog.Record
+}
+
+func (s *testSink) Write(r dlog.Record) error {
+	s.records = append(s.records, r)
+	return nil
+}
+
+func (s *testSink) Close() error   { return nil }
+func (s *testSink) String() string { return "test" }
+
+func TestHandlerWrapper(t *testing.T) {
+	sink := new(testSink)
+	l := logger.NewLogger(logger.WithSinks(sink))
+
+	h := NewHandlerWrapper(WithLogger(l), Sample(1), Redact("card"))(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		if req.Body().(map[string]interface{})["fail"] == true {
+			return errors.BadRequest("test", "invalid")
+		}
+		rsp.(map[string]string)["msg"] = "hello"
+		return nil
+	})
+
+	ctx := trace.ToContext(context.Background(), "trace-1", "span-1")
+	req := &testRequest{body: map[string]interface{}{
+		"name":     "john",
+		"password": "secret",
+		"payment":  map[string]interface{}{"card": "4111"},
+	}}
+	if err := h(ctx, req, map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := sink.records[0].Metadata
+	if entry["trace"] != "trace-1" || entry["endpoint"] != "Foo.Bar" {
+		t.Fatalf("Expected the trace and endpoint got %v", entry)
+	}
+	if entry["request"] != `{"name":"john","password":"[redacted]","payment":{"card":"[redacted]"}}` {
+		t.Fatalf("Expected the redacted request got %v", entry["request"])
+	}
+	if entry["response"] != `{"msg":"hello"}` {
+		t.Fatalf("Expected the response got %v", entry["response"])
+	}
+
+	if err := h(context.Background(), &testRequest{body: map[string]interface{}{"fail": true}}, nil); err == nil {
+		t.Fatal("Expected an error")
+	}
+	entry = sink.records[1].Metadata
+	if entry["code"] != "400" || entry["level"] != "error" {
+		t.Fatalf("Expected the error to be logged got %v", entry)
+	}
+}
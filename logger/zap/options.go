@@ -0,0 +1,14 @@
+package zap
+
+import (
+	"github.com/micro/go-micro/v3/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+type encoderConfigKey struct{}
+
+// WithEncoderConfig sets the config of the encoder, the keys of the fields are the
+// stable keys of the logger package by default
+func WithEncoderConfig(c zapcore.EncoderConfig) logger.Option {
+	return logger.SetOption(encoderConfigKey{}, c)
+}
@@ -0,0 +1,210 @@
+// Package zap is a logger which writes entries with zap
+package zap
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type zaplog struct {
+	sync.RWMutex
+	zap     *zap.Logger
+	opts    logger.Options
+	sampler *logger.Sampler
+}
+
+// encodeLevel writes the levels of the logger package, they're zap levels other than trace and fatal
+func encodeLevel(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(logger.Level(l).String())
+}
+
+func encodeTime(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(t.Format(time.RFC3339))
+}
+
+// DefaultEncoderConfig returns the config of the encoder with the stable keys of the logger package
+func DefaultEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		TimeKey:        logger.TimeKey,
+		LevelKey:       logger.LevelKey,
+		MessageKey:     logger.MessageKey,
+		NameKey:        "logger",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    encodeLevel,
+		EncodeTime:     encodeTime,
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+}
+
+func (l *zaplog) Init(opts ...logger.Option) error {
+	l.Lock()
+	defer l.Unlock()
+
+	for _, o := range opts {
+		o(&l.opts)
+	}
+
+	config := DefaultEncoderConfig()
+	if l.opts.Context != nil {
+		if c, ok := l.opts.Context.Value(encoderConfigKey{}).(zapcore.EncoderConfig); ok {
+			config = c
+		}
+	}
+
+	var enc zapcore.Encoder
+	switch l.opts.Format {
+	case logger.JSONFormat:
+		enc = zapcore.NewJSONEncoder(config)
+	default:
+		enc = zapcore.NewConsoleEncoder(config)
+	}
+
+	// levels are checked before logging so the core accepts any
+	core := zapcore.NewCore(enc, zapcore.AddSync(l.opts.Out), zap.LevelEnablerFunc(func(zapcore.Level) bool {
+		return true
+	}))
+
+	l.zap = zap.New(core)
+	l.sampler = logger.NewSampler(l.opts.Sampling)
+
+	return nil
+}
+
+func (l *zaplog) Fields(fields map[string]interface{}) logger.Logger {
+	l.RLock()
+	opts := l.opts
+	opts.Fields = make(map[string]interface{}, len(l.opts.Fields)+len(fields))
+	for k, v := range l.opts.Fields {
+		opts.Fields[k] = v
+	}
+	zl, sampler := l.zap, l.sampler
+	l.RUnlock()
+
+	for k, v := range fields {
+		opts.Fields[k] = v
+	}
+
+	return &zaplog{zap: zl, opts: opts, sampler: sampler}
+}
+
+func (l *zaplog) Log(level logger.Level, v ...interface{}) {
+	l.log(level, "", fmt.Sprint(v...))
+}
+
+func (l *zaplog) Logf(level logger.Level, format string, v ...interface{}) {
+	l.log(level, format, fmt.Sprintf(format, v...))
+}
+
+func (l *zaplog) log(level logger.Level, format, msg string) {
+	l.RLock()
+	fields := l.opts.Fields
+	enabled := logger.EffectiveLevel(l.opts.Level, fields).Enabled(level)
+	sampler := l.sampler
+	sinks := l.opts.Sinks
+	zl := l.zap
+	l.RUnlock()
+
+	if !enabled {
+		return
+	}
+
+	key := format
+	if len(key) == 0 {
+		key = msg
+	}
+	if !sampler.Allow(level, key) {
+		return
+	}
+
+	ce := zl.Check(zapcore.Level(level), msg)
+	if ce == nil {
+		return
+	}
+
+	data := make([]zap.Field, 0, len(fields)+1)
+	for k, v := range fields {
+		if err, ok := v.(error); ok && k == logger.ErrorKey {
+			data = append(data, zap.String(k, err.Error()))
+			continue
+		}
+		data = append(data, zap.Any(k, v))
+	}
+
+	var caller string
+	if _, file, line, ok := runtime.Caller(l.opts.CallerSkipCount + 1); ok {
+		caller = fmt.Sprintf("%s:%d", callerPath(file), line)
+		data = append(data, zap.String(logger.FileKey, caller))
+	}
+
+	sink(sinks, level, msg, caller, fields)
+
+	ce.Write(data...)
+}
+
+// sink writes the record of the entry to the sinks
+func sink(sinks []logger.Sink, level logger.Level, msg, caller string, fields map[string]interface{}) {
+	if len(sinks) == 0 {
+		return
+	}
+
+	nfields := make(map[string]interface{}, len(fields)+2)
+	for k, v := range fields {
+		nfields[k] = v
+	}
+	nfields[logger.LevelKey] = level.String()
+	if len(caller) > 0 {
+		nfields[logger.FileKey] = caller
+	}
+
+	logger.WriteSinks(sinks, logger.NewRecord(msg, nfields))
+}
+
+// callerPath returns the leaf directory and file name
+func callerPath(file string) string {
+	idx := strings.LastIndexByte(file, '/')
+	if idx == -1 {
+		return file
+	}
+	idx = strings.LastIndexByte(file[:idx], '/')
+	if idx == -1 {
+		return file
+	}
+	return file[idx+1:]
+}
+
+func (l *zaplog) Options() logger.Options {
+	l.RLock()
+	defer l.RUnlock()
+	return l.opts
+}
+
+func (l *zaplog) String() string {
+	return "zap"
+}
+
+// NewLogger returns a logger which writes entries with zap, fatal entries are written at
+// a level between error and panic as the logger package exits on fatal itself
+func NewLogger(opts ...logger.Option) (logger.Logger, error) {
+	options := logger.Options{
+		Level:           logger.InfoLevel,
+		Fields:          make(map[string]interface{}),
+		Out:             os.Stderr,
+		CallerSkipCount: 2,
+	}
+
+	l := &zaplog{opts: options}
+	if err := l.Init(opts...); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,170 @@
+// Package zerolog is a logger which writes entries with zerolog
+package zerolog
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/rs/zerolog"
+)
+
+type zeroLogger struct {
+	sync.RWMutex
+	zero    zerolog.Logger
+	opts    logger.Options
+	sampler *logger.Sampler
+}
+
+func (l *zeroLogger) Init(opts ...logger.Option) error {
+	l.Lock()
+	defer l.Unlock()
+
+	for _, o := range opts {
+		o(&l.opts)
+	}
+
+	out := l.opts.Out
+	if l.opts.Format != logger.JSONFormat {
+		out = zerolog.ConsoleWriter{Out: out}
+	}
+
+	// the field names of zerolog are the stable keys of the logger package
+	// and levels are checked before logging so the logger accepts any
+	l.zero = zerolog.New(out).Level(zerolog.TraceLevel).With().Timestamp().Logger()
+	l.sampler = logger.NewSampler(l.opts.Sampling)
+
+	return nil
+}
+
+func (l *zeroLogger) Fields(fields map[string]interface{}) logger.Logger {
+	l.RLock()
+	opts := l.opts
+	opts.Fields = make(map[string]interface{}, len(l.opts.Fields)+len(fields))
+	for k, v := range l.opts.Fields {
+		opts.Fields[k] = v
+	}
+	zl, sampler := l.zero, l.sampler
+	l.RUnlock()
+
+	for k, v := range fields {
+		opts.Fields[k] = v
+	}
+
+	return &zeroLogger{zero: zl, opts: opts, sampler: sampler}
+}
+
+func (l *zeroLogger) Log(level logger.Level, v ...interface{}) {
+	l.log(level, "", fmt.Sprint(v...))
+}
+
+func (l *zeroLogger) Logf(level logger.Level, format string, v ...interface{}) {
+	l.log(level, format, fmt.Sprintf(format, v...))
+}
+
+// zeroLevel returns the zerolog level, they're one above those of the logger package
+func zeroLevel(level logger.Level) zerolog.Level {
+	return zerolog.Level(level + 1)
+}
+
+func (l *zeroLogger) log(level logger.Level, format, msg string) {
+	l.RLock()
+	fields := l.opts.Fields
+	enabled := logger.EffectiveLevel(l.opts.Level, fields).Enabled(level)
+	sampler := l.sampler
+	sinks := l.opts.Sinks
+	zl := l.zero
+	l.RUnlock()
+
+	if !enabled {
+		return
+	}
+
+	key := format
+	if len(key) == 0 {
+		key = msg
+	}
+	if !sampler.Allow(level, key) {
+		return
+	}
+
+	// unlike Fatal, WithLevel doesn't exit
+	ev := zl.WithLevel(zeroLevel(level))
+	for k, v := range fields {
+		if err, ok := v.(error); ok && k == logger.ErrorKey {
+			ev = ev.Str(k, err.Error())
+			continue
+		}
+		ev = ev.Interface(k, v)
+	}
+
+	var caller string
+	if _, file, line, ok := runtime.Caller(l.opts.CallerSkipCount + 1); ok {
+		caller = fmt.Sprintf("%s:%d", callerPath(file), line)
+		ev = ev.Str(logger.FileKey, caller)
+	}
+
+	sink(sinks, level, msg, caller, fields)
+
+	ev.Msg(msg)
+}
+
+// sink writes the record of the entry to the sinks
+func sink(sinks []logger.Sink, level logger.Level, msg, caller string, fields map[string]interface{}) {
+	if len(sinks) == 0 {
+		return
+	}
+
+	nfields := make(map[string]interface{}, len(fields)+2)
+	for k, v := range fields {
+		nfields[k] = v
+	}
+	nfields[logger.LevelKey] = level.String()
+	if len(caller) > 0 {
+		nfields[logger.FileKey] = caller
+	}
+
+	logger.WriteSinks(sinks, logger.NewRecord(msg, nfields))
+}
+
+// callerPath returns the leaf directory and file name
+func callerPath(file string) string {
+	idx := strings.LastIndexByte(file, '/')
+	if idx == -1 {
+		return file
+	}
+	idx = strings.LastIndexByte(file[:idx], '/')
+	if idx == -1 {
+		return file
+	}
+	return file[idx+1:]
+}
+
+func (l *zeroLogger) Options() logger.Options {
+	l.RLock()
+	defer l.RUnlock()
+	return l.opts
+}
+
+func (l *zeroLogger) String() string {
+	return "zerolog"
+}
+
+// NewLogger returns a logger which writes entries with zerolog
+func NewLogger(opts ...logger.Option) (logger.Logger, error) {
+	options := logger.Options{
+		Level:           logger.InfoLevel,
+		Fields:          make(map[string]interface{}),
+		Out:             os.Stderr,
+		CallerSkipCount: 2,
+	}
+
+	l := &zeroLogger{opts: options}
+	if err := l.Init(opts...); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
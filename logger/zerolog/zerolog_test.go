@@ -0,0 +1,55 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v3/logger"
+)
+
+func TestZerolog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l, err := NewLogger(logger.WithOutput(buf), logger.WithFormat(logger.JSONFormat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := logger.NewHelper(l)
+
+	h.WithFields(map[string]interface{}{"key": "val"}).Infof("hello %s", "world")
+	h.Debug("not logged")
+	h.WithFields(map[string]interface{}{logger.ComponentKey: "test"}).Debug("not logged")
+
+	logger.SetComponentLevel("test", logger.TraceLevel)
+	defer logger.ResetComponentLevel("test")
+	h.WithFields(map[string]interface{}{logger.ComponentKey: "test"}).Trace("traced")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 entries, got %d: %s", len(lines), buf.String())
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{logger.TimeKey, logger.LevelKey, logger.MessageKey, logger.FileKey, "key"} {
+		if _, ok := entry[k]; !ok {
+			t.Fatalf("Expected the %s field in %v", k, entry)
+		}
+	}
+	if entry[logger.MessageKey] != "hello world" || entry[logger.LevelKey] != "info" {
+		t.Fatalf("Unexpected entry %v", entry)
+	}
+	if file := entry[logger.FileKey].(string); !strings.HasPrefix(file, "zerolog/zerolog_test.go") {
+		t.Fatalf("Expected the file of the caller, got %s", file)
+	}
+
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry[logger.LevelKey] != "trace" || entry[logger.ComponentKey] != "test" {
+		t.Fatalf("Unexpected entry %v", entry)
+	}
+}
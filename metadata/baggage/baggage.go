@@ -0,0 +1,111 @@
+// Package baggage provides typed values which are carried in the metadata of requests and
+// messages across services, such as the tenant or user a request is made on behalf of. The
+// values are always propagated by the client whatever its metadata propagation policy.
+package baggage
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/micro/go-micro/v3/metadata"
+)
+
+var (
+	// ErrVersion is returned when a value was set with another version of its key
+	ErrVersion = errors.New("unsupported baggage version")
+	// ErrInvalid is returned when a value isn't in the format of the baggage
+	ErrInvalid = errors.New("invalid baggage")
+)
+
+// Key of a typed value in the baggage, the version of the key is changed when
+// the encoding of its values changes so services can detect stale senders
+type Key struct {
+	name    string
+	version int
+	codec   Codec
+}
+
+// NewKey returns a key with the name and version whose values are encoded with the codec
+func NewKey(name string, version int, c Codec) *Key {
+	return &Key{
+		name:    strings.Title(metadata.BaggagePrefix + name),
+		version: version,
+		codec:   c,
+	}
+}
+
+// Name of the metadata the values are stored in
+func (k *Key) Name() string {
+	return k.name
+}
+
+// Version of the encoding of the values
+func (k *Key) Version() int {
+	return k.version
+}
+
+// Set returns a context with the value of the key
+func (k *Key) Set(ctx context.Context, v interface{}) (context.Context, error) {
+	s, err := k.codec.Encode(v)
+	if err != nil {
+		return ctx, err
+	}
+	return metadata.Set(ctx, k.name, strconv.Itoa(k.version)+";"+s), nil
+}
+
+// Get returns the value of the key in the context
+func (k *Key) Get(ctx context.Context) (interface{}, bool, error) {
+	// the keys are lower case when received over grpc
+	md, _ := metadata.FromContext(ctx)
+	s, ok := md[k.name]
+	if !ok {
+		return nil, false, nil
+	}
+
+	parts := strings.SplitN(s, ";", 2)
+	if len(parts) != 2 {
+		return nil, false, ErrInvalid
+	}
+	if parts[0] != strconv.Itoa(k.version) {
+		return nil, false, ErrVersion
+	}
+
+	v, err := k.codec.Decode(parts[1])
+	if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+// Delete returns a context without the value of the key
+func (k *Key) Delete(ctx context.Context) context.Context {
+	return metadata.Delete(ctx, k.name)
+}
+
+// Inject copies the baggage of the context to the header of a message
+// published with the broker directly rather than the client
+func Inject(ctx context.Context, header map[string]string) {
+	md, ok := metadata.FromContext(ctx)
+	if !ok {
+		return
+	}
+	for k, v := range md {
+		if strings.HasPrefix(k, metadata.BaggagePrefix) {
+			header[k] = v
+		}
+	}
+}
+
+// Extract returns a context with the baggage in the header of a message
+// received from the broker directly rather than a subscriber
+func Extract(ctx context.Context, header map[string]string) context.Context {
+	md := make(metadata.Metadata)
+	for k, v := range header {
+		if k = strings.Title(k); strings.HasPrefix(k, metadata.BaggagePrefix) {
+			md[k] = v
+		}
+	}
+	return metadata.MergeContext(ctx, md, true)
+}
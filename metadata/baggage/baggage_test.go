@@ -0,0 +1,54 @@
+package baggage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/v3/metadata"
+)
+
+func TestBaggage(t *testing.T) {
+	ctx := WithTenant(context.Background(), "acme")
+	ctx = WithFeatures(ctx, map[string]bool{"beta": true})
+
+	if v, ok := Tenant(ctx); !ok || v != "acme" {
+		t.Fatalf("Expected the tenant got %v", v)
+	}
+	if _, ok := User(ctx); ok {
+		t.Fatal("Expected no user")
+	}
+	if !Enabled(ctx, "beta") || Enabled(ctx, "alpha") {
+		t.Fatalf("Expected the beta feature got %v", Features(ctx))
+	}
+
+	// values of another type are rejected
+	if _, err := FeaturesKey.Set(ctx, "beta"); err == nil {
+		t.Fatal("Expected an error setting a string feature flags")
+	}
+
+	// values set with another version are rejected
+	v2 := NewKey("Tenant-Id", 2, String{})
+	if _, _, err := v2.Get(ctx); err != ErrVersion {
+		t.Fatalf("Expected a version error got %v", err)
+	}
+
+	// received over grpc with lower case keys
+	md, _ := metadata.FromContext(ctx)
+	rctx := metadata.NewIncomingContext(context.Background(), metadata.Metadata{"baggage-tenant-id": md[TenantKey.Name()]})
+	if v, ok := Tenant(rctx); !ok || v != "acme" {
+		t.Fatalf("Expected the tenant from the lower case key got %v", v)
+	}
+
+	// baggage is propagated whatever the policy
+	out, _ := metadata.Outgoing(rctx, &metadata.Policy{Deny: []string{"*"}})
+	if len(out) != 1 {
+		t.Fatalf("Expected the baggage to be propagated got %v", out)
+	}
+
+	// carried in the header of broker messages
+	header := map[string]string{"Content-Type": "application/json"}
+	Inject(ctx, header)
+	if v, ok := Tenant(Extract(context.Background(), header)); !ok || v != "acme" {
+		t.Fatalf("Expected the tenant from the header got %v", v)
+	}
+}
@@ -0,0 +1,52 @@
+package baggage
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Codec encodes the values of a key to and from the metadata
+type Codec interface {
+	Encode(v interface{}) (string, error)
+	Decode(s string) (interface{}, error)
+}
+
+// String is the codec of string values
+type String struct{}
+
+func (String) Encode(v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("expected a string got %T", v)
+	}
+	return s, nil
+}
+
+func (String) Decode(s string) (interface{}, error) {
+	return s, nil
+}
+
+// JSON is the codec of values of the type of its value, e.g JSON{map[string]bool{}}
+type JSON struct {
+	Value interface{}
+}
+
+func (j JSON) Encode(v interface{}) (string, error) {
+	if t := reflect.TypeOf(j.Value); t != reflect.TypeOf(v) {
+		return "", fmt.Errorf("expected a %v got %T", t, v)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (j JSON) Decode(s string) (interface{}, error) {
+	v := reflect.New(reflect.TypeOf(j.Value))
+	if err := json.Unmarshal([]byte(s), v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
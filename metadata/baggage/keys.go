@@ -0,0 +1,77 @@
+package baggage
+
+import (
+	"context"
+)
+
+var (
+	// TenantKey is the tenant a request is made on behalf of
+	TenantKey = NewKey("Tenant-Id", 1, String{})
+	// UserKey is the user a request is made on behalf of
+	UserKey = NewKey("User-Id", 1, String{})
+	// LocaleKey is the locale of the user e.g en-GB
+	LocaleKey = NewKey("Locale", 1, String{})
+	// FeaturesKey is the feature flags enabled for the request
+	FeaturesKey = NewKey("Features", 1, JSON{map[string]bool{}})
+)
+
+func getString(ctx context.Context, k *Key) (string, bool) {
+	v, ok, err := k.Get(ctx)
+	if err != nil || !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// WithTenant sets the tenant of the requests made with the context
+func WithTenant(ctx context.Context, id string) context.Context {
+	ctx, _ = TenantKey.Set(ctx, id)
+	return ctx
+}
+
+// Tenant returns the tenant of the request
+func Tenant(ctx context.Context) (string, bool) {
+	return getString(ctx, TenantKey)
+}
+
+// WithUser sets the user of the requests made with the context
+func WithUser(ctx context.Context, id string) context.Context {
+	ctx, _ = UserKey.Set(ctx, id)
+	return ctx
+}
+
+// User returns the user of the request
+func User(ctx context.Context) (string, bool) {
+	return getString(ctx, UserKey)
+}
+
+// WithLocale sets the locale of the requests made with the context
+func WithLocale(ctx context.Context, locale string) context.Context {
+	ctx, _ = LocaleKey.Set(ctx, locale)
+	return ctx
+}
+
+// Locale returns the locale of the request
+func Locale(ctx context.Context) (string, bool) {
+	return getString(ctx, LocaleKey)
+}
+
+// WithFeatures sets the feature flags of the requests made with the context
+func WithFeatures(ctx context.Context, flags map[string]bool) context.Context {
+	ctx, _ = FeaturesKey.Set(ctx, flags)
+	return ctx
+}
+
+// Features returns the feature flags of the request
+func Features(ctx context.Context) map[string]bool {
+	v, ok, err := FeaturesKey.Get(ctx)
+	if err != nil || !ok {
+		return map[string]bool{}
+	}
+	return v.(map[string]bool)
+}
+
+// Enabled returns true if the feature flag is enabled for the request
+func Enabled(ctx context.Context, feature string) bool {
+	return Features(ctx)[feature]
+}
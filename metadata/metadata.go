@@ -8,6 +8,11 @@ import (
 
 type metadataKey struct{}
 
+type incomingKey struct{}
+
+// BaggagePrefix is the prefix of the metadata which is always propagated, see the baggage package
+var BaggagePrefix = "Baggage-"
+
 // Metadata is our way of representing request headers internally.
 // They're used at the RPC level and translate back and forth
 // from Transport headers.
@@ -124,3 +129,40 @@ func MergeContext(ctx context.Context, patchMd Metadata, overwrite bool) context
 	}
 	return context.WithValue(ctx, metadataKey{}, cmd)
 }
+
+// NewIncomingContext creates a new context with the metadata of a request being served,
+// it's propagated to the requests made with the context subject to the propagation policy
+func NewIncomingContext(ctx context.Context, md Metadata) context.Context {
+	in := make(Metadata, len(md))
+	for k, v := range md {
+		in[strings.Title(k)] = v
+	}
+	ctx = context.WithValue(ctx, incomingKey{}, in)
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// Outgoing returns the metadata to send with a request made with the context. The metadata
+// inherited from the request being served is filtered by the policy, the metadata set while
+// serving it is always sent. All the metadata is sent if the policy is nil.
+func Outgoing(ctx context.Context, p *Policy) (Metadata, bool) {
+	md, ok := FromContext(ctx)
+	if !ok || p == nil {
+		return md, ok
+	}
+
+	in, _ := ctx.Value(incomingKey{}).(Metadata)
+
+	inherited := make(Metadata)
+	for k, v := range md {
+		if iv, ok := in[k]; ok && iv == v {
+			inherited[k] = v
+			delete(md, k)
+		}
+	}
+
+	for k, v := range p.Filter(inherited) {
+		md[k] = v
+	}
+
+	return md, true
+}
@@ -113,3 +113,48 @@ func TestMergeContext(t *testing.T) {
 		})
 	}
 }
+
+func TestPolicy(t *testing.T) {
+	p := &Policy{
+		Allow:        []string{"X-Trace-*", "Baggage", "Authorization", "Large"},
+		Deny:         []string{"authorization"},
+		MaxValueSize: 8,
+	}
+
+	md := p.Filter(Metadata{
+		"X-Trace-Id":    "1",
+		"Baggage":       "foo",
+		"Authorization": "Bearer token",
+		"Cookie":        "session",
+		"Large":         "123456789",
+	})
+	if len(md) != 2 || md["X-Trace-Id"] != "1" || md["Baggage"] != "foo" {
+		t.Fatalf("Expected the allowed keys got %v", md)
+	}
+
+	// keys are dropped once the size limit is reached
+	p = &Policy{MaxSize: 8}
+	md = p.Filter(Metadata{"Aa": "11", "Bb": "22", "Cc": "33"})
+	if len(md) != 2 || md["Cc"] != "" {
+		t.Fatalf("Expected the first keys within the size got %v", md)
+	}
+}
+
+func TestOutgoing(t *testing.T) {
+	ctx := NewIncomingContext(context.Background(), Metadata{
+		"Authorization": "Bearer token",
+		"Baggage":       "foo",
+	})
+	ctx = Set(ctx, "Authorization", "Bearer service")
+	ctx = Set(ctx, "Micro-Id", "1")
+
+	md, _ := Outgoing(ctx, &Policy{Deny: []string{"Authorization", "Baggage"}})
+	if len(md) != 2 || md["Authorization"] != "Bearer service" || md["Micro-Id"] != "1" {
+		t.Fatalf("Expected only the metadata set by the handler got %v", md)
+	}
+
+	md, _ = Outgoing(ctx, nil)
+	if len(md) != 3 {
+		t.Fatalf("Expected all the metadata without a policy got %v", md)
+	}
+}
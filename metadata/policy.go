@@ -0,0 +1,94 @@
+package metadata
+
+import (
+	"sort"
+	"strings"
+)
+
+var (
+	// Sensitive are the keys of the metadata holding credentials, they're redacted from
+	// what's recorded of the requests such as the debug stats and the error reports
+	Sensitive = []string{"Authorization", "Cookie", "Set-Cookie"}
+)
+
+// Redact returns a copy of the metadata without the Sensitive keys
+func Redact(md Metadata) Metadata {
+	out := make(Metadata, len(md))
+	for k, v := range md {
+		if match(Sensitive, strings.Title(k)) {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// Policy governs which metadata of the request being served is propagated to the
+// requests made while serving it, so sensitive headers don't leak downstream
+type Policy struct {
+	// Allow lists the keys which are propagated, keys ending with * are prefixes.
+	// All the keys are allowed if it's empty.
+	Allow []string
+	// Deny lists the keys which are never propagated, it takes precedence over Allow
+	Deny []string
+	// MaxValueSize is the max size of a propagated value, larger values are dropped
+	MaxValueSize int
+	// MaxSize is the max total size of the propagated keys and values, the keys
+	// which don't fit are dropped in alphabetical order
+	MaxSize int
+}
+
+func match(rules []string, key string) bool {
+	for _, r := range rules {
+		r = strings.Title(r)
+		if strings.HasSuffix(r, "*") {
+			if strings.HasPrefix(key, strings.TrimSuffix(r, "*")) {
+				return true
+			}
+		} else if r == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed returns true if the key is propagated
+func (p *Policy) Allowed(key string) bool {
+	key = strings.Title(key)
+	if match(p.Deny, key) {
+		return false
+	}
+	return len(p.Allow) == 0 || match(p.Allow, key)
+}
+
+// Filter returns the metadata which is propagated, baggage is always propagated
+func (p *Policy) Filter(md Metadata) Metadata {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var size int
+	out := make(Metadata, len(md))
+	for _, k := range keys {
+		v := md[k]
+		if strings.HasPrefix(strings.Title(k), BaggagePrefix) {
+			out[k] = v
+			continue
+		}
+		if !p.Allowed(k) {
+			continue
+		}
+		if p.MaxValueSize > 0 && len(v) > p.MaxValueSize {
+			continue
+		}
+		if p.MaxSize > 0 && size+len(k)+len(v) > p.MaxSize {
+			continue
+		}
+		size += len(k) + len(v)
+		out[k] = v
+	}
+
+	return out
+}
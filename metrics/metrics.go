@@ -3,6 +3,12 @@ package metrics
 
 import "time"
 
+var (
+	// DefaultReporter is the reporter of the metrics of the framework, the requests served
+	// and made by services and the api are reported to it
+	DefaultReporter Reporter = new(noop)
+)
+
 // Tags is a map of fields to add to a metric:
 type Tags map[string]string
 
@@ -12,3 +18,25 @@ type Reporter interface {
 	Gauge(id string, value float64, tags Tags) error
 	Timing(id string, value time.Duration, tags Tags) error
 }
+
+// Result is the result tag of an operation with the error
+func Result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+type noop struct{}
+
+func (n *noop) Count(id string, value int64, tags Tags) error {
+	return nil
+}
+
+func (n *noop) Gauge(id string, value float64, tags Tags) error {
+	return nil
+}
+
+func (n *noop) Timing(id string, value time.Duration, tags Tags) error {
+	return nil
+}
@@ -43,7 +43,7 @@ func Path(value string) Option {
 	}
 }
 
-// Address is the listen address to serve metrics on:
+// Address is the listen address to serve metrics on, empty to not listen:
 func Address(value string) Option {
 	return func(o *Options) {
 		o.Address = value
@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"net"
 	"net/http"
 	"strings"
 
@@ -41,14 +42,28 @@ func New(opts ...metrics.Option) (*Reporter, error) {
 	// Add metrics families for each type:
 	newReporter.metrics = newReporter.newMetricFamily()
 
-	// Handle the metrics endpoint with prometheus:
-	log.Infof("Metrics/Prometheus [http] Listening on %s%s", options.Address, options.Path)
-	http.Handle(options.Path, promhttp.HandlerFor(prometheusRegistry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError}))
-	go http.ListenAndServe(options.Address, nil)
+	// Serve the metrics endpoint unless the handler is served elsewhere:
+	if len(options.Address) > 0 {
+		l, err := net.Listen("tcp", options.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle(options.Path, newReporter.Handler())
+
+		log.Infof("Metrics/Prometheus [http] Listening on %s%s", l.Addr().String(), options.Path)
+		go http.Serve(l, mux)
+	}
 
 	return newReporter, nil
 }
 
+// Handler serves the metrics in the Prometheus format e.g to add them to an existing server:
+func (r *Reporter) Handler() http.Handler {
+	return promhttp.HandlerFor(r.prometheusRegistry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+}
+
 // convertTags turns Tags into prometheus labels:
 func (r *Reporter) convertTags(tags metrics.Tags) prometheus.Labels {
 	labels := prometheus.Labels{}
@@ -0,0 +1,37 @@
+package network
+
+import (
+	"path"
+)
+
+// Filter selects the services whose routes are exchanged over the links to a remote address.
+// It's used to federate networks e.g clusters in different regions only sharing some services.
+type Filter struct {
+	// Address of the remote end of the links, a pattern e.g 10.0.1.*, all the links if empty
+	Address string
+	// Services the routes are exchanged for, patterns e.g go.micro.service.*
+	Services []string
+}
+
+// Match returns true if the routes of the service can be exchanged with the remote address.
+// All the services can if no filter applies to the address.
+func Match(filters []Filter, remote, service string) bool {
+	var filtered bool
+
+	for _, f := range filters {
+		if len(f.Address) > 0 {
+			if ok, _ := path.Match(f.Address, remote); !ok {
+				continue
+			}
+		}
+
+		filtered = true
+		for _, s := range f.Services {
+			if ok, _ := path.Match(s, service); ok {
+				return true
+			}
+		}
+	}
+
+	return !filtered
+}
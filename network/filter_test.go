@@ -0,0 +1,37 @@
+package network
+
+import (
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	filters := []Filter{
+		{Address: "10.0.1.*", Services: []string{"go.micro.service.foo", "go.micro.api.*"}},
+		{Address: "10.0.2.1:8085", Services: []string{"go.micro.service.bar"}},
+	}
+
+	testData := []struct {
+		remote  string
+		service string
+		match   bool
+	}{
+		{"10.0.1.1:8085", "go.micro.service.foo", true},
+		{"10.0.1.1:8085", "go.micro.api.foo", true},
+		{"10.0.1.1:8085", "go.micro.service.bar", false},
+		{"10.0.2.1:8085", "go.micro.service.bar", true},
+		{"10.0.2.1:8085", "go.micro.service.foo", false},
+		// links without a filter exchange all the routes
+		{"10.0.3.1:8085", "go.micro.service.baz", true},
+	}
+
+	for _, d := range testData {
+		if m := Match(filters, d.remote, d.service); m != d.match {
+			t.Errorf("Expected %s %s to match %v, got %v", d.remote, d.service, d.match, m)
+		}
+	}
+
+	// filters without an address apply to all the links
+	if Match([]Filter{{Services: []string{"foo"}}}, "10.0.3.1:8085", "bar") {
+		t.Error("Expected the filter to apply to all the links")
+	}
+}
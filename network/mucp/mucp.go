@@ -170,6 +170,7 @@ func NewNetwork(opts ...network.Option) network.Network {
 		client.Broker(tunBroker),
 		client.Transport(tunTransport),
 		client.Router(options.Router),
+		client.Lookup(lookupBest),
 	)
 
 	network := &mucpNetwork{
@@ -369,6 +370,10 @@ func (n *mucpNetwork) advertise(eventChan <-chan *router.Event) {
 
 			for i := 0; i < max; i++ {
 				if peer := n.node.GetPeerNode(peers[rnd.Intn(len(peers))].Id()); peer != nil {
+					// skip the peers the route isn't exported to
+					if !network.Match(n.options.Export, n.linkRemote(peer.link), event.Route.Service) {
+						continue
+					}
 					if err := n.sendTo("advert", ControlChannel, peer, msg); err != nil {
 						if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 							logger.Debugf("Network failed to advertise routes to %s: %v", peer.Id(), err)
@@ -674,6 +679,11 @@ func (n *mucpNetwork) processCtrlChan(listener tunnel.Listener) {
 						}
 					}
 
+					// skip the routes which aren't imported from the link
+					if !network.Match(n.options.Import, n.linkRemote(m.msg.Header["Micro-Link"]), event.Route.Service) {
+						continue
+					}
+
 					route := router.Route{
 						Service: event.Route.Service,
 						Address: event.Route.Address,
@@ -786,7 +796,7 @@ func (n *mucpNetwork) processNetChan(listener tunnel.Listener) {
 					}
 
 					// get a list of the best routes for each service in our routing table
-					routes, err := n.getProtoRoutes()
+					routes, err := n.getProtoRoutes(peer.link)
 					if err != nil {
 						logger.Debugf("Network node %s failed listing routes: %v", n.id, err)
 					}
@@ -843,7 +853,7 @@ func (n *mucpNetwork) processNetChan(listener tunnel.Listener) {
 						}
 
 						// get a list of the best routes for each service in our routing table
-						routes, err := n.getProtoRoutes()
+						routes, err := n.getProtoRoutes(peer.link)
 						if err != nil {
 							logger.Debugf("Network node %s failed listing routes: %v", n.id, err)
 						}
@@ -943,6 +953,10 @@ func (n *mucpNetwork) processNetChan(listener tunnel.Listener) {
 				for _, pbRoute := range pbSync.Routes {
 					// unmarshal the routes received from remote peer
 					route := ProtoToRoute(pbRoute)
+					// skip the routes which aren't imported from the link
+					if !network.Match(n.options.Import, n.linkRemote(peer.link), route.Service) {
+						continue
+					}
 					// continue if we are the originator of the route
 					if route.Router == n.router.Options().Id {
 						if logger.V(logger.DebugLevel, logger.DefaultLogger) {
@@ -1126,6 +1140,8 @@ func (n *mucpNetwork) manage() {
 	defer prune.Stop()
 	netsync := time.NewTicker(SyncTime)
 	defer netsync.Stop()
+	save := time.NewTicker(SaveTime)
+	defer save.Stop()
 
 	// list of links we've sent to
 	links := make(map[string]time.Time)
@@ -1295,6 +1311,12 @@ func (n *mucpNetwork) manage() {
 					}
 				}
 			}
+		case <-save.C:
+			if err := n.saveRoutes(); err != nil {
+				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+					logger.Debugf("Network node %s failed saving routes: %v", n.id, err)
+				}
+			}
 		case <-netsync.C:
 			// get a list of node peers
 			peers := n.Peers()
@@ -1320,7 +1342,7 @@ func (n *mucpNetwork) manage() {
 				}
 
 				// get a list of the best routes for each service in our routing table
-				routes, err := n.getProtoRoutes()
+				routes, err := n.getProtoRoutes(peer.link)
 				if err != nil {
 					if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 						logger.Debugf("Network node %s failed listing routes: %v", n.id, err)
@@ -1343,15 +1365,22 @@ func (n *mucpNetwork) manage() {
 // getAdvertProtoRoutes returns a list of routes to advertise to remote peer
 // based on the advertisement strategy encoded in protobuf
 // It returns error if the routes failed to be retrieved from the routing table
-func (n *mucpNetwork) getProtoRoutes() ([]*pb.Route, error) {
+func (n *mucpNetwork) getProtoRoutes(link string) ([]*pb.Route, error) {
 	routes, err := n.router.Table().Read()
 	if err != nil && err != router.ErrRouteNotFound {
 		return nil, err
 	}
 
+	// the remote address of the link the routes are exported to
+	remote := n.linkRemote(link)
+
 	// encode the routes to protobuf
 	pbRoutes := make([]*pb.Route, 0, len(routes))
 	for _, route := range routes {
+		// skip the routes which aren't exported to the link
+		if !network.Match(n.options.Export, remote, route.Service) {
+			continue
+		}
 		// generate new route proto
 		pbRoute := RouteToProto(route)
 		// mask the route before outbounding
@@ -1529,6 +1558,16 @@ func (n *mucpNetwork) updatePeerLinks(peer *node) error {
 	return nil
 }
 
+// linkRemote returns the remote address of the link which the route filters are matched against
+func (n *mucpNetwork) linkRemote(linkId string) string {
+	for _, link := range n.tunnel.Links() {
+		if link.Id() == linkId {
+			return link.Remote()
+		}
+	}
+	return ""
+}
+
 // isLoopback checks if a link is a loopback to ourselves
 func (n *mucpNetwork) isLoopback(link tunnel.Link) bool {
 	// skip loopback
@@ -1689,6 +1728,18 @@ func (n *mucpNetwork) Connect() error {
 	// create closed channel
 	n.closed = make(chan bool)
 
+	// reload the routes persisted before a restart, before watching
+	// the table so they aren't advertised until they're validated
+	routes, err := n.loadRoutes()
+	if err != nil {
+		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+			logger.Debugf("Network failed to reload routes: %v", err)
+		}
+	}
+	if len(routes) > 0 {
+		go n.validateRoutes(routes)
+	}
+
 	// start advertising routes
 	watcher, err := n.options.Router.Watch()
 	if err != nil {
@@ -1797,6 +1848,13 @@ func (n *mucpNetwork) Close() error {
 		// unlock the lock otherwise we'll deadlock sending the close
 		n.Unlock()
 
+		// persist the routes for the restart
+		if err := n.saveRoutes(); err != nil {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Network failed saving routes: %v", err)
+			}
+		}
+
 		msg := &pb.Close{
 			Node: &pb.Node{
 				Id:      n.node.id,
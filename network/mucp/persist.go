@@ -0,0 +1,113 @@
+package mucp
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/router"
+	"github.com/micro/go-micro/v3/store"
+)
+
+var (
+	// SaveTime is the interval the routes learned from the network are persisted at
+	SaveTime = 30 * time.Second
+	// ValidateTime is how long the origin of a reloaded route has to reappear
+	// in the peer graph before the route is pruned
+	ValidateTime = 10 * time.Second
+)
+
+// routesKey is the key of the routes of the node in the store
+func (n *mucpNetwork) routesKey() string {
+	return "network/" + n.options.Name + "/routes/" + n.options.Id
+}
+
+// saveRoutes persists the routes learned from the network. They expire when they'd
+// have been pruned had the node kept running.
+func (n *mucpNetwork) saveRoutes() error {
+	if n.options.Store == nil {
+		return nil
+	}
+
+	routes, err := n.router.Table().Read()
+	if err != nil && err != router.ErrRouteNotFound {
+		return err
+	}
+
+	learned := make([]router.Route, 0, len(routes))
+	for _, route := range routes {
+		// our own routes come from the registry
+		if route.Router == n.Id() {
+			continue
+		}
+		learned = append(learned, route)
+	}
+
+	b, err := json.Marshal(learned)
+	if err != nil {
+		return err
+	}
+
+	return n.options.Store.Write(&store.Record{
+		Key:    n.routesKey(),
+		Value:  b,
+		Expiry: PruneTime,
+	})
+}
+
+// loadRoutes adds the persisted routes to the routing table and returns them
+func (n *mucpNetwork) loadRoutes() ([]router.Route, error) {
+	if n.options.Store == nil {
+		return nil, nil
+	}
+
+	recs, err := n.options.Store.Read(n.routesKey())
+	if err == store.ErrNotFound || len(recs) == 0 {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var routes []router.Route
+	if err := json.Unmarshal(recs[0].Value, &routes); err != nil {
+		return nil, err
+	}
+
+	loaded := make([]router.Route, 0, len(routes))
+	for _, route := range routes {
+		if err := n.router.Table().Create(route); err != nil && err != router.ErrDuplicateRoute {
+			return loaded, err
+		}
+		loaded = append(loaded, route)
+	}
+
+	return loaded, nil
+}
+
+// validateRoutes prunes the reloaded routes whose origin hasn't reappeared in the
+// peer graph once the connect messages sent on startup have been answered
+func (n *mucpNetwork) validateRoutes(routes []router.Route) {
+	select {
+	case <-n.closed:
+		return
+	case <-time.After(ValidateTime):
+	}
+
+	pruned := make(map[string]bool)
+
+	for _, route := range routes {
+		if pruned[route.Router] || n.node.GetPeerNode(route.Router) != nil {
+			continue
+		}
+		pruned[route.Router] = true
+
+		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+			logger.Debugf("Network pruning reloaded routes of unreachable router %s", route.Router)
+		}
+		if err := n.pruneRoutes(router.LookupRouter(route.Router), router.LookupLink("*")); err != nil {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Network failed pruning reloaded routes of %s: %v", route.Router, err)
+			}
+		}
+	}
+}
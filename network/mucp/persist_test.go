@@ -0,0 +1,78 @@
+package mucp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/network"
+	"github.com/micro/go-micro/v3/registry/memory"
+	"github.com/micro/go-micro/v3/router"
+	regRouter "github.com/micro/go-micro/v3/router/registry"
+	smemory "github.com/micro/go-micro/v3/store/memory"
+)
+
+func TestPersistRoutes(t *testing.T) {
+	st := smemory.NewStore()
+
+	newNetwork := func() *mucpNetwork {
+		return NewNetwork(
+			network.Id("node-1"),
+			network.Store(st),
+			network.Router(regRouter.NewRouter(router.Registry(memory.NewRegistry()))),
+		).(*mucpNetwork)
+	}
+
+	learned := router.Route{
+		Service: "foo",
+		Address: "10.0.1.1:8080",
+		Gateway: "peer-1",
+		Network: "go.micro",
+		Router:  "peer-1",
+		Link:    DefaultLink,
+		Metric:  100,
+	}
+	own := router.Route{
+		Service: "bar",
+		Address: "10.0.0.1:8080",
+		Network: "go.micro",
+		Router:  "node-1",
+		Link:    "local",
+		Metric:  1,
+	}
+
+	n := newNetwork()
+	for _, route := range []router.Route{learned, own} {
+		if err := n.router.Table().Create(route); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := n.saveRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the restarted node reloads the learned routes only
+	n = newNetwork()
+	routes, err := n.loadRoutes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Hash() != learned.Hash() {
+		t.Fatalf("Expected the learned route to be reloaded, got %+v", routes)
+	}
+	if table, _ := n.router.Table().Read(); len(table) != 1 {
+		t.Fatalf("Expected 1 route in the table, got %d", len(table))
+	}
+
+	// peer-1 never reappears so its routes are pruned
+	ValidateTime = 10 * time.Millisecond
+	defer func() {
+		ValidateTime = 10 * time.Second
+	}()
+
+	n.closed = make(chan bool)
+	n.validateRoutes(routes)
+
+	if table, _ := n.router.Table().Read(); len(table) != 0 {
+		t.Fatalf("Expected the reloaded routes to be pruned, got %+v", table)
+	}
+}
@@ -0,0 +1,85 @@
+package mucp
+
+import (
+	"context"
+	"sort"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/network"
+	"github.com/micro/go-micro/v3/router"
+)
+
+// bestRoutes returns the routes with the lowest metric of each service
+func bestRoutes(routes []router.Route) map[uint64]bool {
+	best := make(map[string]int64)
+	for _, r := range routes {
+		if m, ok := best[r.Service]; !ok || r.Metric < m {
+			best[r.Service] = r.Metric
+		}
+	}
+
+	hashes := make(map[uint64]bool)
+	for _, r := range routes {
+		if r.Metric == best[r.Service] {
+			hashes[r.Hash()] = true
+		}
+	}
+	return hashes
+}
+
+// lookupBest is the lookup of the network client, the requests are only
+// balanced across the routes with the lowest metric so the shortest path
+// to a service is used e.g the service in the local cluster when it's
+// also available in a remote one
+func lookupBest(ctx context.Context, req client.Request, opts client.CallOptions) ([]router.Route, error) {
+	if len(opts.Address) > 0 {
+		return client.LookupRoute(ctx, req, opts)
+	}
+
+	var query []router.LookupOption
+	if len(opts.Network) > 0 {
+		query = append(query, router.LookupNetwork(opts.Network))
+	}
+
+	routes, err := opts.Router.Lookup(req.Service(), query...)
+	if err == router.ErrRouteNotFound {
+		return nil, errors.InternalServerError("go.micro.client", "service %s: %s", req.Service(), err.Error())
+	} else if err != nil {
+		return nil, errors.InternalServerError("go.micro.client", "error getting next %s node: %s", req.Service(), err.Error())
+	}
+
+	best := bestRoutes(routes)
+
+	var shortest []router.Route
+	for _, r := range routes {
+		if best[r.Hash()] {
+			shortest = append(shortest, r)
+		}
+	}
+	return shortest, nil
+}
+
+// Routes returns the routing table sorted by service and metric
+func (n *mucpNetwork) Routes() ([]network.Route, error) {
+	routes, err := n.router.Table().Read()
+	if err != nil && err != router.ErrRouteNotFound {
+		return nil, err
+	}
+
+	best := bestRoutes(routes)
+
+	table := make([]network.Route, 0, len(routes))
+	for _, r := range routes {
+		table = append(table, network.Route{Route: r, Best: best[r.Hash()]})
+	}
+
+	sort.Slice(table, func(i, j int) bool {
+		if table[i].Service != table[j].Service {
+			return table[i].Service < table[j].Service
+		}
+		return table[i].Metric < table[j].Metric
+	})
+
+	return table, nil
+}
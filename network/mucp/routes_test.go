@@ -0,0 +1,24 @@
+package mucp
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/v3/router"
+)
+
+func TestBestRoutes(t *testing.T) {
+	routes := []router.Route{
+		{Service: "foo", Address: "10.0.0.1:8080", Link: "local", Metric: 1},
+		{Service: "foo", Address: "10.0.0.2:8080", Link: "local", Metric: 1},
+		{Service: "foo", Address: "10.0.1.1:8080", Link: "network", Metric: 100},
+		{Service: "bar", Address: "10.0.1.2:8080", Link: "network", Metric: 100},
+	}
+
+	best := bestRoutes(routes)
+
+	for i, expected := range []bool{true, true, false, true} {
+		if best[routes[i].Hash()] != expected {
+			t.Errorf("Expected route %s %s best to be %v", routes[i].Service, routes[i].Address, expected)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package network
 
 import (
 	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/router"
 	"github.com/micro/go-micro/v3/server"
 )
 
@@ -52,4 +53,14 @@ type Network interface {
 	Client() client.Client
 	// Server is micro server
 	Server() server.Server
+	// Routes returns the routing table of the node
+	Routes() ([]Route, error)
+}
+
+// Route of the routing table of a node
+type Route struct {
+	router.Route
+	// Best is true for the routes of the service with the lowest
+	// metric, the requests made over the network use these routes
+	Best bool
 }
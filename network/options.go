@@ -8,6 +8,7 @@ import (
 	"github.com/micro/go-micro/v3/proxy/mucp"
 	"github.com/micro/go-micro/v3/router"
 	regRouter "github.com/micro/go-micro/v3/router/registry"
+	"github.com/micro/go-micro/v3/store"
 )
 
 type Option func(*Options)
@@ -30,6 +31,12 @@ type Options struct {
 	Router router.Router
 	// Proxy is network proxy
 	Proxy proxy.Proxy
+	// Export filters the routes advertised to the links
+	Export []Filter
+	// Import filters the routes learned from the links
+	Import []Filter
+	// Store persists the routes learned from the network
+	Store store.Store
 }
 
 // Id sets the id of the network node
@@ -88,6 +95,31 @@ func Proxy(p proxy.Proxy) Option {
 	}
 }
 
+// Export only advertises the routes of the services to the links of the remote address,
+// the address is a pattern and all the links are filtered if it's empty
+func Export(address string, services ...string) Option {
+	return func(o *Options) {
+		o.Export = append(o.Export, Filter{Address: address, Services: services})
+	}
+}
+
+// Import only accepts the routes of the services from the links of the remote address,
+// the address is a pattern and all the links are filtered if it's empty
+func Import(address string, services ...string) Option {
+	return func(o *Options) {
+		o.Import = append(o.Import, Filter{Address: address, Services: services})
+	}
+}
+
+// Store persists the routes learned from the network so a restarted node reloads
+// them instead of waiting for the peers to advertise them. The node needs a stable
+// Id to find its routes after the restart.
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
 // DefaultOptions returns network default options
 func DefaultOptions() Options {
 	return Options{
@@ -0,0 +1,104 @@
+package transport
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"syscall"
+)
+
+// ConnState is the state of a connection reported to the OnConnState function
+type ConnState int
+
+const (
+	// StateConnected is reported when a connection is dialled or accepted
+	StateConnected ConnState = iota
+	// StateDead is reported when the peer stopped responding to keepalives
+	StateDead
+	// StateClosed is reported when a connection is closed
+	StateClosed
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateDead:
+		return "dead"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+type stateConn struct {
+	net.Conn
+	fn   func(string, ConnState)
+	dead sync.Once
+	once sync.Once
+}
+
+func (c *stateConn) check(err error) {
+	if err == nil {
+		return
+	}
+	// keepalive failures surface as timeouts from the kernel
+	var errno syscall.Errno
+	if errors.As(err, &errno) && (errno == syscall.ETIMEDOUT || errno == syscall.EHOSTUNREACH) {
+		c.dead.Do(func() {
+			c.fn(c.RemoteAddr().String(), StateDead)
+		})
+	}
+}
+
+func (c *stateConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.check(err)
+	return n, err
+}
+
+func (c *stateConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.check(err)
+	return n, err
+}
+
+func (c *stateConn) Close() error {
+	c.once.Do(func() {
+		c.fn(c.RemoteAddr().String(), StateClosed)
+	})
+	return c.Conn.Close()
+}
+
+// WatchConn calls the function with the remote address as the state of the
+// connection changes, starting with StateConnected. It's used by implementations
+// to support the OnConnState option.
+func WatchConn(c net.Conn, fn func(string, ConnState)) net.Conn {
+	if fn == nil {
+		return c
+	}
+	fn(c.RemoteAddr().String(), StateConnected)
+	return &stateConn{Conn: c, fn: fn}
+}
+
+type stateListener struct {
+	net.Listener
+	fn func(string, ConnState)
+}
+
+func (l *stateListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return WatchConn(c, l.fn), nil
+}
+
+// WatchListener calls the function as the state of the connections accepted changes
+func WatchListener(l net.Listener, fn func(string, ConnState)) net.Listener {
+	if fn == nil {
+		return l
+	}
+	return &stateListener{Listener: l, fn: fn}
+}
@@ -9,10 +9,12 @@ import (
 	"github.com/micro/go-micro/v3/network/transport"
 	maddr "github.com/micro/go-micro/v3/util/addr"
 	mnet "github.com/micro/go-micro/v3/util/net"
+	"github.com/micro/go-micro/v3/util/proxyproto"
 	mls "github.com/micro/go-micro/v3/util/tls"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 
 	pb "github.com/micro/go-micro/v3/network/transport/grpc/proto"
 )
@@ -25,6 +27,7 @@ type grpcTransportListener struct {
 	listener net.Listener
 	secure   bool
 	tls      *tls.Config
+	opts     transport.Options
 }
 
 func getTLSConfig(addr string) (*tls.Config, error) {
@@ -75,6 +78,28 @@ func (t *grpcTransportListener) Accept(fn func(transport.Socket)) error {
 		opts = append(opts, grpc.Creds(creds))
 	}
 
+	// limit the size of messages
+	if max := t.opts.MaxMessageSize; max > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(int(max)), grpc.MaxSendMsgSize(int(max)))
+	}
+	if max := t.opts.MaxHeaderSize; max > 0 {
+		opts = append(opts, grpc.MaxHeaderListSize(uint32(max)))
+	}
+
+	// ping clients and allow them to ping at the keepalive interval
+	if t.opts.KeepAlive > 0 {
+		opts = append(opts,
+			grpc.KeepaliveParams(keepalive.ServerParameters{
+				Time:    t.opts.KeepAlive,
+				Timeout: t.opts.KeepAliveTimeout,
+			}),
+			grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+				MinTime:             t.opts.KeepAlive,
+				PermitWithoutStream: true,
+			}),
+		)
+	}
+
 	// new service
 	srv := grpc.NewServer(opts...)
 
@@ -109,6 +134,42 @@ func (t *grpcTransport) Dial(addr string, opts ...transport.DialOption) (transpo
 		options = append(options, grpc.WithInsecure())
 	}
 
+	// limit the size of messages
+	if max := t.opts.MaxMessageSize; max > 0 {
+		options = append(options, grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(int(max)),
+			grpc.MaxCallSendMsgSize(int(max)),
+		))
+	}
+	if max := t.opts.MaxHeaderSize; max > 0 {
+		options = append(options, grpc.WithMaxHeaderListSize(uint32(max)))
+	}
+
+	// ping the server to detect dead connections
+	if t.opts.KeepAlive > 0 {
+		options = append(options, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                t.opts.KeepAlive,
+			Timeout:             t.opts.KeepAliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+
+	// limit the rate of the connection and report its state
+	if t.opts.ReadRate > 0 || t.opts.WriteRate > 0 || t.opts.KeepAlive > 0 || t.opts.OnConnState != nil {
+		options = append(options, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			if err := mnet.KeepAlive(conn, t.opts.KeepAlive, t.opts.KeepAliveTimeout); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			conn = mnet.RateLimit(conn, t.opts.ReadRate, t.opts.WriteRate)
+			return transport.WatchConn(conn, t.opts.OnConnState), nil
+		}))
+	}
+
 	// dial the server
 	ctx, cancel := context.WithTimeout(context.Background(), dopts.Timeout)
 	defer cancel()
@@ -145,10 +206,29 @@ func (t *grpcTransport) Listen(addr string, opts ...transport.ListenOption) (tra
 		return nil, err
 	}
 
+	ln = mnet.KeepAliveListener(ln, t.opts.KeepAlive, t.opts.KeepAliveTimeout)
+
+	// accept the PROXY protocol header if enabled
+	if t.opts.Context != nil {
+		if trusted, ok := t.opts.Context.Value(proxyProtocolKey{}).([]string); ok {
+			pl, err := proxyproto.NewListener(ln, proxyproto.Trusted(trusted...))
+			if err != nil {
+				ln.Close()
+				return nil, err
+			}
+			ln = pl
+		}
+	}
+
+	// limit the connections and their rate
+	ln = mnet.LimitListener(ln, t.opts.MaxConnections, t.opts.ReadRate, t.opts.WriteRate)
+	ln = transport.WatchListener(ln, t.opts.OnConnState)
+
 	return &grpcTransportListener{
 		listener: ln,
 		tls:      t.opts.TLSConfig,
 		secure:   t.opts.Secure,
+		opts:     t.opts,
 	}, nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net"
@@ -17,6 +18,7 @@ import (
 	maddr "github.com/micro/go-micro/v3/util/addr"
 	"github.com/micro/go-micro/v3/util/buf"
 	mnet "github.com/micro/go-micro/v3/util/net"
+	"github.com/micro/go-micro/v3/util/proxyproto"
 	mls "github.com/micro/go-micro/v3/util/tls"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
@@ -82,6 +84,10 @@ func (h *httpTransportClient) Remote() string {
 }
 
 func (h *httpTransportClient) Send(m *transport.Message) error {
+	if max := h.ht.opts.MaxMessageSize; max > 0 && int64(len(m.Body)) > max {
+		return transport.ErrMessageTooLarge
+	}
+
 	header := make(http.Header)
 
 	for k, v := range m.Header {
@@ -145,11 +151,19 @@ func (h *httpTransportClient) Recv(m *transport.Message) error {
 	}
 	defer rsp.Body.Close()
 
-	b, err := ioutil.ReadAll(rsp.Body)
+	if err := h.ht.checkHeader(rsp.Header); err != nil {
+		return err
+	}
+
+	b, err := h.ht.readBody(rsp.Body, rsp.ContentLength)
 	if err != nil {
 		return err
 	}
 
+	// the server rejected the message as larger than its max message size
+	if rsp.StatusCode == http.StatusRequestEntityTooLarge {
+		return fmt.Errorf("%w: %s", transport.ErrMessageTooLarge, rsp.Status)
+	}
 	if rsp.StatusCode != 200 {
 		return errors.New(rsp.Status + ": " + string(b))
 	}
@@ -218,8 +232,12 @@ func (h *httpTransportSocket) Recv(m *transport.Message) error {
 			r = rr
 		}
 
+		if err := h.ht.checkHeader(r.Header); err != nil {
+			return err
+		}
+
 		// read body
-		b, err := ioutil.ReadAll(r.Body)
+		b, err := h.ht.readBody(r.Body, r.ContentLength)
 		if err != nil {
 			return err
 		}
@@ -284,6 +302,10 @@ func (h *httpTransportSocket) Recv(m *transport.Message) error {
 }
 
 func (h *httpTransportSocket) Send(m *transport.Message) error {
+	if max := h.ht.opts.MaxMessageSize; max > 0 && int64(len(m.Body)) > max {
+		return transport.ErrMessageTooLarge
+	}
+
 	if h.r.ProtoMajor == 1 {
 		// make copy of header
 		hdr := make(http.Header)
@@ -404,8 +426,15 @@ func (h *httpTransportListener) Accept(fn func(transport.Socket)) error {
 
 		// read a regular request
 		if r.ProtoMajor == 1 {
-			b, err := ioutil.ReadAll(r.Body)
-			if err != nil {
+			if err := h.ht.checkHeader(r.Header); err != nil {
+				http.Error(w, err.Error(), http.StatusRequestHeaderFieldsTooLarge)
+				return
+			}
+			b, err := h.ht.readBody(r.Body, r.ContentLength)
+			if err == transport.ErrMessageTooLarge {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			} else if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -465,7 +494,8 @@ func (h *httpTransportListener) Accept(fn func(transport.Socket)) error {
 
 	// default http2 server
 	srv := &http.Server{
-		Handler: mux,
+		Handler:        mux,
+		MaxHeaderBytes: h.ht.opts.MaxHeaderSize,
 	}
 
 	// insecure connection use h2c
@@ -489,6 +519,19 @@ func (h *httpTransport) Dial(addr string, opts ...transport.DialOption) (transpo
 	var conn net.Conn
 	var err error
 
+	// dial with keepalives enabled on the tcp connection
+	dial := func(addr string) (net.Conn, error) {
+		c, err := net.DialTimeout("tcp", addr, dopts.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		if err := mnet.KeepAlive(c, h.opts.KeepAlive, h.opts.KeepAliveTimeout); err != nil {
+			c.Close()
+			return nil, err
+		}
+		return c, nil
+	}
+
 	// TODO: support dial option here rather than using internal config
 	if h.opts.Secure || h.opts.TLSConfig != nil {
 		config := h.opts.TLSConfig
@@ -499,18 +542,26 @@ func (h *httpTransport) Dial(addr string, opts ...transport.DialOption) (transpo
 		}
 		config.NextProtos = []string{"http/1.1"}
 		conn, err = newConn(func(addr string) (net.Conn, error) {
-			return tls.DialWithDialer(&net.Dialer{Timeout: dopts.Timeout}, "tcp", addr, config)
+			c, err := dial(addr)
+			if err != nil {
+				return nil, err
+			}
+			return tlsHandshake(c, addr, config, dopts.Timeout)
 		})(addr)
 	} else {
-		conn, err = newConn(func(addr string) (net.Conn, error) {
-			return net.DialTimeout("tcp", addr, dopts.Timeout)
-		})(addr)
+		conn, err = newConn(dial)(addr)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	// report the state of the connection
+	conn = transport.WatchConn(conn, h.opts.OnConnState)
+
+	// limit the rate of the connection
+	conn = mnet.RateLimit(conn, h.opts.ReadRate, h.opts.WriteRate)
+
 	return &httpTransportClient{
 		ht:       h,
 		addr:     addr,
@@ -523,6 +574,92 @@ func (h *httpTransport) Dial(addr string, opts ...transport.DialOption) (transpo
 	}, nil
 }
 
+// tlsHandshake starts a tls session on the connection as tls.DialWithDialer does
+func tlsHandshake(conn net.Conn, addr string, config *tls.Config, timeout time.Duration) (net.Conn, error) {
+	if config.ServerName == "" && !config.InsecureSkipVerify {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	tc := tls.Client(conn, config)
+	if err := tc.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Time{})
+	return tc, nil
+}
+
+// listen on the address, accepting the PROXY protocol header if enabled
+func (h *httpTransport) listen(addr string) (net.Listener, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	l = mnet.KeepAliveListener(l, h.opts.KeepAlive, h.opts.KeepAliveTimeout)
+
+	if h.opts.Context != nil {
+		if trusted, ok := h.opts.Context.Value(proxyProtocolKey{}).([]string); ok {
+			pl, err := proxyproto.NewListener(l, proxyproto.Trusted(trusted...))
+			if err != nil {
+				l.Close()
+				return nil, err
+			}
+			l = pl
+		}
+	}
+
+	l = mnet.LimitListener(l, h.opts.MaxConnections, h.opts.ReadRate, h.opts.WriteRate)
+
+	return transport.WatchListener(l, h.opts.OnConnState), nil
+}
+
+// checkHeader returns an error if the header exceeds the max header size
+func (h *httpTransport) checkHeader(header http.Header) error {
+	if h.opts.MaxHeaderSize <= 0 {
+		return nil
+	}
+	var size int
+	for k, v := range header {
+		for _, vv := range v {
+			size += len(k) + len(vv)
+		}
+	}
+	if size > h.opts.MaxHeaderSize {
+		return transport.ErrHeaderTooLarge
+	}
+	return nil
+}
+
+// readBody reads the body of the length, returning an error if it exceeds the max message size
+func (h *httpTransport) readBody(r io.Reader, length int64) ([]byte, error) {
+	max := h.opts.MaxMessageSize
+	if max <= 0 {
+		return ioutil.ReadAll(r)
+	}
+	if length > max {
+		return nil, transport.ErrMessageTooLarge
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > max {
+		return nil, transport.ErrMessageTooLarge
+	}
+	return b, nil
+}
+
 func (h *httpTransport) Listen(addr string, opts ...transport.ListenOption) (transport.Listener, error) {
 	var options transport.ListenOptions
 	for _, o := range opts {
@@ -556,16 +693,16 @@ func (h *httpTransport) Listen(addr string, opts ...transport.ListenOption) (tra
 				}
 				config = &tls.Config{Certificates: []tls.Certificate{cert}}
 			}
-			return tls.Listen("tcp", addr, config)
+			l, err := h.listen(addr)
+			if err != nil {
+				return nil, err
+			}
+			return tls.NewListener(l, config), nil
 		}
 
 		l, err = mnet.Listen(addr, fn)
 	} else {
-		fn := func(addr string) (net.Listener, error) {
-			return net.Listen("tcp", addr)
-		}
-
-		l, err = mnet.Listen(addr, fn)
+		l, err = mnet.Listen(addr, h.listen)
 	}
 
 	if err != nil {
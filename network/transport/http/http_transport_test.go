@@ -246,3 +246,102 @@ func TestHTTPTransportTimeout(t *testing.T) {
 
 	<-done
 }
+
+func TestHTTPTransportMaxMessageSize(t *testing.T) {
+	tr := NewTransport(transport.MaxMessageSize(1024))
+
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go l.Accept(func(sock transport.Socket) {
+		defer sock.Close()
+		for {
+			var m transport.Message
+			if err := sock.Recv(&m); err != nil {
+				return
+			}
+			if err := sock.Send(&m); err != nil {
+				return
+			}
+		}
+	})
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Send(&transport.Message{Body: make([]byte, 2048)}); err != transport.ErrMessageTooLarge {
+		t.Fatalf("Expected the message to be too large to send, got %v", err)
+	}
+
+	// the limit is enforced by the server regardless of the client
+	c2, err := NewTransport().Dial(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c2.Close()
+
+	if err := c2.Send(&transport.Message{Body: make([]byte, 2048)}); err != nil {
+		t.Fatal(err)
+	}
+
+	var m transport.Message
+	if err := c2.Recv(&m); err == nil {
+		t.Fatal("Expected the server to reject the message")
+	}
+}
+
+func TestHTTPTransportConnState(t *testing.T) {
+	states := make(chan transport.ConnState, 4)
+	tr := NewTransport(
+		transport.KeepAlive(time.Second, time.Second*5),
+		transport.OnConnState(func(addr string, state transport.ConnState) {
+			states <- state
+		}),
+	)
+
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go l.Accept(func(sock transport.Socket) {
+		defer sock.Close()
+		var m transport.Message
+		sock.Recv(&m)
+	})
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Send(&transport.Message{Body: []byte("hello")}); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	// the client and server connections are both connected then closed
+	var connected, closed int
+	for i := 0; i < 4; i++ {
+		select {
+		case s := <-states:
+			switch s {
+			case transport.StateConnected:
+				connected++
+			case transport.StateClosed:
+				closed++
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatalf("Expected 4 state changes, got %d", i)
+		}
+	}
+	if connected != 2 || closed != 2 {
+		t.Fatalf("Expected 2 connected and 2 closed, got %d and %d", connected, closed)
+	}
+}
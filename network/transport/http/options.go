@@ -7,6 +7,8 @@ import (
 	"github.com/micro/go-micro/v3/network/transport"
 )
 
+type proxyProtocolKey struct{}
+
 // Handle registers the handler for the given pattern.
 func Handle(pattern string, handler http.Handler) transport.Option {
 	return func(o *transport.Options) {
@@ -21,3 +23,18 @@ func Handle(pattern string, handler http.Handler) transport.Option {
 		o.Context = context.WithValue(o.Context, "http_handlers", handlers)
 	}
 }
+
+// ProxyProtocol accepts the PROXY protocol header from load balancers at the trusted
+// addresses or CIDRs so the remote address of sockets is the client. At least one
+// address is required, listening fails otherwise.
+func ProxyProtocol(trusted ...string) transport.Option {
+	return func(o *transport.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		if trusted == nil {
+			trusted = []string{}
+		}
+		o.Context = context.WithValue(o.Context, proxyProtocolKey{}, trusted)
+	}
+}
@@ -0,0 +1,212 @@
+package mtls
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/micro/go-micro/v3/util/pki"
+)
+
+// DefaultTTL is the lifetime of certificates issued by the builtin CA
+var DefaultTTL = time.Hour * 24
+
+// newCertificate parses the PEM encoded key pair and roots
+func newCertificate(cert, key []byte, roots ...[]byte) (*Certificate, error) {
+	pair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, err
+	}
+	pair.Leaf, err = x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	for _, root := range roots {
+		if !pool.AppendCertsFromPEM(root) {
+			return nil, errors.New("invalid CA certificate")
+		}
+	}
+
+	return &Certificate{Certificate: pair, Roots: pool}, nil
+}
+
+type ca struct {
+	cert []byte
+	key  []byte
+	ttl  time.Duration
+}
+
+// Issue generates a key and signs a certificate for it with the CA
+func (c *ca) Issue(name string) (*Certificate, error) {
+	pub, priv, err := pki.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := pki.CSR(
+		pki.Subject(pkix.Name{CommonName: name}),
+		pki.DNSNames(name),
+		pki.KeyPair(pub, priv),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	// allow for clock skew between the services
+	now := time.Now()
+	cert, err := pki.Sign(c.cert, c.key, csr,
+		pki.SerialNumber(serial),
+		pki.NotBefore(now.Add(-time.Minute)),
+		pki.NotAfter(now.Add(c.ttl)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	key := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	return newCertificate(cert, key, c.cert)
+}
+
+func (c *ca) String() string {
+	return "ca"
+}
+
+// NewCA returns the builtin authority which signs certificates with the PEM encoded
+// CA cert and key, as generated by pki.CA. Certificates are valid for the ttl or DefaultTTL.
+func NewCA(cert, key []byte, ttl time.Duration) Authority {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	return &ca{cert: cert, key: key, ttl: ttl}
+}
+
+type vault struct {
+	addr   string
+	token  string
+	path   string
+	client *http.Client
+}
+
+type vaultResponse struct {
+	Errors []string `json:"errors"`
+	Data   struct {
+		Certificate string   `json:"certificate"`
+		PrivateKey  string   `json:"private_key"`
+		IssuingCA   string   `json:"issuing_ca"`
+		CAChain     []string `json:"ca_chain"`
+	} `json:"data"`
+}
+
+// Issue requests a certificate from the vault pki secrets engine
+func (v *vault) Issue(name string) (*Certificate, error) {
+	b, err := json.Marshal(map[string]string{"common_name": name})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", v.addr+"/v1/"+v.path, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	var vr vaultResponse
+	if err := json.NewDecoder(rsp.Body).Decode(&vr); err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault error %d: %s", rsp.StatusCode, strings.Join(vr.Errors, ", "))
+	}
+
+	// the chain includes the issuing ca when set
+	roots := [][]byte{[]byte(vr.Data.IssuingCA)}
+	if len(vr.Data.CAChain) > 0 {
+		roots = roots[:0]
+		for _, c := range vr.Data.CAChain {
+			roots = append(roots, []byte(c))
+		}
+	}
+
+	return newCertificate([]byte(vr.Data.Certificate), []byte(vr.Data.PrivateKey), roots...)
+}
+
+func (v *vault) String() string {
+	return "vault"
+}
+
+// NewVault returns an authority which issues certificates from the vault pki secrets
+// engine at the address e.g http://127.0.0.1:8200. The path is the issue endpoint of
+// the role e.g pki/issue/micro, the role must allow the service names.
+func NewVault(addr, token, path string) Authority {
+	return &vault{
+		addr:   strings.TrimSuffix(addr, "/"),
+		token:  token,
+		path:   strings.TrimPrefix(path, "/"),
+		client: &http.Client{Timeout: time.Second * 10},
+	}
+}
+
+type files struct {
+	cert string
+	key  string
+	ca   string
+}
+
+// Issue reads the current certificate from disk, the name is ignored
+func (f *files) Issue(name string) (*Certificate, error) {
+	cert, err := ioutil.ReadFile(f.cert)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ioutil.ReadFile(f.key)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := ioutil.ReadFile(f.ca)
+	if err != nil {
+		return nil, err
+	}
+	return newCertificate(cert, key, ca)
+}
+
+func (f *files) String() string {
+	return "files"
+}
+
+// NewFiles returns an authority which reads the PEM encoded certificate, key and CA
+// bundle from disk. It's used with spire by running the spiffe-helper which writes the
+// SVIDs of the workload and rotates them, the name of the service is the last
+// element of the SPIFFE ID.
+func NewFiles(cert, key, ca string) Authority {
+	return &files{cert: cert, key: key, ca: ca}
+}
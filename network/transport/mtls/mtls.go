@@ -0,0 +1,232 @@
+// Package mtls provisions workload certificates for mutual TLS between services.
+// Certificates are obtained from a pluggable Authority, renewed before they expire
+// and peers are verified by mapping the SANs of their certificates to service names.
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/network/transport"
+)
+
+var (
+	// DefaultRetryInterval is how long to wait before retrying a failed renewal
+	DefaultRetryInterval = time.Second * 10
+
+	// ErrNoName is returned when the manager is created without a service name
+	ErrNoName = errors.New("service name required")
+	// ErrNoCertificate is returned when the peer didn't present a certificate
+	ErrNoCertificate = errors.New("no peer certificate")
+	// ErrPeerNotAllowed is returned when the peer certificate isn't for an allowed service
+	ErrPeerNotAllowed = errors.New("peer not allowed")
+)
+
+// Authority issues workload certificates e.g the builtin CA, vault or spire
+type Authority interface {
+	// Issue a certificate for the service
+	Issue(name string) (*Certificate, error)
+	String() string
+}
+
+// Certificate is a workload certificate and the roots peers are verified against
+type Certificate struct {
+	// Certificate is the key pair, the leaf is parsed
+	Certificate tls.Certificate
+	// Roots are the CA certificates
+	Roots *x509.CertPool
+}
+
+// Manager keeps the workload certificate issued by the authority up to date
+type Manager struct {
+	authority Authority
+	opts      Options
+
+	sync.RWMutex
+	cert *Certificate
+	exit chan bool
+}
+
+// NewManager issues a certificate from the authority and renews it before it expires
+func NewManager(a Authority, opts ...Option) (*Manager, error) {
+	options := Options{
+		RetryInterval: DefaultRetryInterval,
+		Identity:      DefaultIdentity,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if len(options.Name) == 0 {
+		return nil, ErrNoName
+	}
+
+	cert, err := a.Issue(options.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		authority: a,
+		opts:      options,
+		cert:      cert,
+		exit:      make(chan bool),
+	}
+
+	go m.run()
+
+	return m, nil
+}
+
+// DefaultIdentity returns the DNS names of the certificate and the last
+// path element of its URI SANs e.g spiffe://example.org/ns/default/sa/foo is foo
+func DefaultIdentity(cert *x509.Certificate) []string {
+	names := append([]string{}, cert.DNSNames...)
+	for _, u := range cert.URIs {
+		if name := path.Base(u.Path); len(name) > 0 && name != "/" && name != "." {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// renewAt returns when the certificate should be renewed
+func (m *Manager) renewAt(cert *Certificate) time.Time {
+	leaf := cert.Certificate.Leaf
+	before := m.opts.RenewBefore
+	if before == 0 {
+		before = leaf.NotAfter.Sub(leaf.NotBefore) / 3
+	}
+	return leaf.NotAfter.Add(-before)
+}
+
+func (m *Manager) run() {
+	m.RLock()
+	wait := time.Until(m.renewAt(m.cert))
+	m.RUnlock()
+
+	for {
+		select {
+		case <-m.exit:
+			return
+		case <-time.After(wait):
+		}
+
+		cert, err := m.authority.Issue(m.opts.Name)
+		if err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error renewing certificate from %s: %v", m.authority, err)
+			}
+			wait = m.opts.RetryInterval
+			continue
+		}
+
+		m.Lock()
+		m.cert = cert
+		m.Unlock()
+
+		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+			logger.Debugf("Renewed certificate for %s, expires %v", m.opts.Name, cert.Certificate.Leaf.NotAfter)
+		}
+
+		// the authority may return the same certificate until it's rotated
+		wait = time.Until(m.renewAt(cert))
+		if wait < m.opts.RetryInterval {
+			wait = m.opts.RetryInterval
+		}
+	}
+}
+
+// Certificate returns the current certificate
+func (m *Manager) Certificate() *Certificate {
+	m.RLock()
+	defer m.RUnlock()
+	return m.cert
+}
+
+// verify the peer certificate chain against the current roots and the allowed peers
+func (m *Manager) verify(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return ErrNoCertificate
+	}
+
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs[i] = cert
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         m.Certificate().Roots,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	if _, err := certs[0].Verify(opts); err != nil {
+		return err
+	}
+
+	if len(m.opts.Peers) == 0 {
+		return nil
+	}
+
+	for _, name := range m.opts.Identity(certs[0]) {
+		for _, peer := range m.opts.Peers {
+			if name == peer {
+				return nil
+			}
+		}
+	}
+
+	return ErrPeerNotAllowed
+}
+
+// TLSConfig returns a config for both sides of a connection which presents the
+// current certificate and requires and verifies the certificate of the peer.
+// Peers are verified by service name rather than the address dialled.
+func (m *Manager) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &m.Certificate().Certificate, nil
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &m.Certificate().Certificate, nil
+		},
+		ClientAuth: tls.RequireAnyClientCert,
+		// the chain and name are checked by verify
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: m.verify,
+	}
+}
+
+// Close stops renewing the certificate
+func (m *Manager) Close() error {
+	m.Lock()
+	defer m.Unlock()
+
+	select {
+	case <-m.exit:
+	default:
+		close(m.exit)
+	}
+	return nil
+}
+
+// Certificates secures the transport with mutual TLS using the certificates of the manager
+func Certificates(m *Manager) transport.Option {
+	return func(o *transport.Options) {
+		o.Secure = true
+		o.TLSConfig = m.TLSConfig()
+	}
+}
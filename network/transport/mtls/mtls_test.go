@@ -0,0 +1,283 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/network/transport"
+	thttp "github.com/micro/go-micro/v3/network/transport/http"
+	"github.com/micro/go-micro/v3/util/pki"
+)
+
+func newCA(t *testing.T) ([]byte, []byte) {
+	pub, priv, err := pki.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, key, err := pki.CA(
+		pki.Subject(pkix.Name{CommonName: "micro"}),
+		pki.KeyPair(pub, priv),
+		pki.SerialNumber(big.NewInt(1)),
+		pki.NotBefore(time.Now().Add(-time.Minute)),
+		pki.NotAfter(time.Now().Add(time.Hour)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// handshake connects a client to a server and returns the errors of both sides
+func handshake(t *testing.T, server, client *Manager) (error, error) {
+	l, err := tls.Listen("tcp", "127.0.0.1:0", server.TLSConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			errc <- err
+			return
+		}
+		defer conn.Close()
+		errc <- conn.(*tls.Conn).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", l.Addr().String(), client.TLSConfig())
+	if err == nil {
+		// the client completes before the server verifies it
+		_, err = conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+
+	return <-errc, err
+}
+
+func TestMutualTLS(t *testing.T) {
+	cert, key := newCA(t)
+	ca := NewCA(cert, key, time.Hour)
+
+	foo, err := NewManager(ca, Name("foo"), Peers("bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer foo.Close()
+
+	bar, err := NewManager(ca, Name("bar"), Peers("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bar.Close()
+
+	if names := foo.Certificate().Certificate.Leaf.DNSNames; len(names) != 1 || names[0] != "foo" {
+		t.Fatalf("Expected the certificate to be issued for foo, got %v", names)
+	}
+
+	if err, _ := handshake(t, foo, bar); err != nil {
+		t.Fatalf("Expected the handshake to succeed, got %v", err)
+	}
+
+	// baz isn't an allowed peer of foo
+	baz, err := NewManager(ca, Name("baz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer baz.Close()
+
+	if err, _ := handshake(t, foo, baz); err == nil {
+		t.Fatal("Expected the server to reject a peer which isn't allowed")
+	}
+	if _, err := handshake(t, baz, foo); err == nil {
+		t.Fatal("Expected the client to reject a peer which isn't allowed")
+	}
+
+	// a certificate from another CA isn't trusted
+	cert2, key2 := newCA(t)
+	other, err := NewManager(NewCA(cert2, key2, time.Hour), Name("bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	if err, _ := handshake(t, foo, other); err == nil {
+		t.Fatal("Expected the server to reject a certificate from another CA")
+	}
+}
+
+func TestRenew(t *testing.T) {
+	cert, key := newCA(t)
+
+	// certificates are valid for a minute before being issued, renew after it's valid
+	m, err := NewManager(NewCA(cert, key, time.Second), Name("foo"),
+		RenewBefore(time.Second-100*time.Millisecond),
+		RetryInterval(100*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	serial := m.Certificate().Certificate.Leaf.SerialNumber
+
+	time.Sleep(time.Millisecond * 500)
+
+	if m.Certificate().Certificate.Leaf.SerialNumber.Cmp(serial) == 0 {
+		t.Fatal("Expected the certificate to be renewed")
+	}
+}
+
+func TestDefaultIdentity(t *testing.T) {
+	u, _ := url.Parse("spiffe://example.org/ns/default/sa/foo")
+	names := DefaultIdentity(&x509.Certificate{
+		DNSNames: []string{"bar"},
+		URIs:     []*url.URL{u},
+	})
+	if len(names) != 2 || names[0] != "bar" || names[1] != "foo" {
+		t.Fatalf("Expected names [bar foo], got %v", names)
+	}
+}
+
+func TestVault(t *testing.T) {
+	cert, key := newCA(t)
+
+	// issue the certificate vault would return with the builtin CA
+	issued, err := NewCA(cert, key, time.Hour).Issue("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(issued.Certificate.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/pki/issue/micro" || r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+			return
+		}
+
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["common_name"] != "foo" {
+			t.Errorf("Expected common name foo, got %v", req["common_name"])
+		}
+
+		var rsp vaultResponse
+		rsp.Data.Certificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: issued.Certificate.Certificate[0]}))
+		rsp.Data.PrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+		rsp.Data.IssuingCA = string(cert)
+		json.NewEncoder(w).Encode(rsp)
+	}))
+	defer srv.Close()
+
+	c, err := NewVault(srv.URL, "token", "pki/issue/micro").Issue("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Certificate.Leaf.Verify(x509.VerifyOptions{Roots: c.Roots}); err != nil {
+		t.Fatalf("Expected the certificate to verify against the issuing CA, got %v", err)
+	}
+
+	if _, err := NewVault(srv.URL, "bad", "pki/issue/micro").Issue("foo"); err == nil {
+		t.Fatal("Expected an error with an invalid token")
+	}
+}
+
+func TestFiles(t *testing.T) {
+	cert, key := newCA(t)
+
+	issued, err := NewCA(cert, key, time.Hour).Issue("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(issued.Certificate.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "mtls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string][]byte{
+		"svid.pem":   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: issued.Certificate.Certificate[0]}),
+		"key.pem":    pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		"bundle.pem": cert,
+	}
+	for name, b := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), b, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := NewFiles(filepath.Join(dir, "svid.pem"), filepath.Join(dir, "key.pem"), filepath.Join(dir, "bundle.pem")).Issue("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Certificate.Leaf.SerialNumber.Cmp(issued.Certificate.Leaf.SerialNumber) != 0 {
+		t.Fatal("Expected the certificate to be read from disk")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	cert, key := newCA(t)
+	ca := NewCA(cert, key, time.Hour)
+
+	foo, err := NewManager(ca, Name("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer foo.Close()
+
+	tr := thttp.NewTransport(Certificates(foo))
+
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go l.Accept(func(sock transport.Socket) {
+		defer sock.Close()
+		var m transport.Message
+		if err := sock.Recv(&m); err != nil {
+			return
+		}
+		sock.Send(&m)
+	})
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Send(&transport.Message{Body: []byte("hello")}); err != nil {
+		t.Fatal(err)
+	}
+	var m transport.Message
+	if err := c.Recv(&m); err != nil {
+		t.Fatal(err)
+	}
+	if string(m.Body) != "hello" {
+		t.Fatalf("Expected hello, got %s", m.Body)
+	}
+}
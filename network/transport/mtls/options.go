@@ -0,0 +1,60 @@
+package mtls
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+// Options of the certificate manager
+type Options struct {
+	// Name of the service the certificate is issued for
+	Name string
+	// Peers are the names of the services allowed to connect,
+	// any peer with a certificate issued by the CA if empty
+	Peers []string
+	// RenewBefore is how long before expiry the certificate is renewed,
+	// a third of its lifetime if zero
+	RenewBefore time.Duration
+	// RetryInterval is how long to wait before retrying a failed renewal
+	RetryInterval time.Duration
+	// Identity returns the service names of a peer certificate
+	Identity func(*x509.Certificate) []string
+}
+
+// Option sets an option of the manager
+type Option func(o *Options)
+
+// Name of the service to issue the certificate for
+func Name(n string) Option {
+	return func(o *Options) {
+		o.Name = n
+	}
+}
+
+// Peers restricts connections to peers with certificates for the services
+func Peers(names ...string) Option {
+	return func(o *Options) {
+		o.Peers = names
+	}
+}
+
+// RenewBefore sets how long before expiry the certificate is renewed
+func RenewBefore(d time.Duration) Option {
+	return func(o *Options) {
+		o.RenewBefore = d
+	}
+}
+
+// RetryInterval sets how long to wait before retrying a failed renewal
+func RetryInterval(d time.Duration) Option {
+	return func(o *Options) {
+		o.RetryInterval = d
+	}
+}
+
+// Identity sets the function mapping the SANs of a peer certificate to service names
+func Identity(fn func(*x509.Certificate) []string) Option {
+	return func(o *Options) {
+		o.Identity = fn
+	}
+}
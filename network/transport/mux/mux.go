@@ -0,0 +1,198 @@
+// Package mux multiplexes streams over the connections of a transport. Each address
+// is dialled once and every stream to it shares the connection, messages are split
+// into frames scheduled by priority with per stream flow control. Both sides of a
+// connection must use the mux transport.
+package mux
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/network/transport"
+)
+
+var (
+	// DefaultIdleTimeout is how long a connection without streams is kept open for reuse
+	DefaultIdleTimeout = time.Minute
+	// DefaultWindow is the bytes a stream can send before the peer has received them
+	DefaultWindow = 256 * 1024
+	// DefaultFrameSize is the maximum body size of a frame, larger messages are split
+	// so the frames of other streams can be interleaved
+	DefaultFrameSize = 16 * 1024
+
+	// ErrStreamClosed is returned when sending on a closed stream
+	ErrStreamClosed = errors.New("stream closed")
+)
+
+type muxTransport struct {
+	transport transport.Transport
+	opts      transport.Options
+
+	sync.Mutex
+	// client sessions by address
+	sessions map[string]*session
+	// addresses being dialled, closed once dialled
+	dialling map[string]chan bool
+}
+
+type muxListener struct {
+	listener transport.Listener
+}
+
+func (m *muxTransport) idleTimeout() time.Duration {
+	if m.opts.Context != nil {
+		if d, ok := m.opts.Context.Value(idleTimeoutKey{}).(time.Duration); ok {
+			return d
+		}
+	}
+	return DefaultIdleTimeout
+}
+
+func (m *muxTransport) Init(opts ...transport.Option) error {
+	m.Lock()
+	for _, o := range opts {
+		o(&m.opts)
+	}
+	m.Unlock()
+	return m.transport.Init(opts...)
+}
+
+func (m *muxTransport) Options() transport.Options {
+	return m.opts
+}
+
+// Dial opens a stream on the connection to the address, the connection is dialled if there isn't one
+func (m *muxTransport) Dial(addr string, opts ...transport.DialOption) (transport.Client, error) {
+	var options transport.DialOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	var priority int
+	if options.Context != nil {
+		if p, ok := options.Context.Value(priorityKey{}).(int); ok {
+			priority = p
+		}
+	}
+
+	for {
+		m.Lock()
+		sess, ok := m.sessions[addr]
+		wait, dialling := m.dialling[addr]
+		if !ok && !dialling {
+			m.dialling[addr] = make(chan bool)
+		}
+		m.Unlock()
+
+		if ok {
+			if st, ok := sess.dial(priority); ok {
+				return st, nil
+			}
+			// the session was closed
+			m.removeSession(addr, sess)
+			continue
+		}
+
+		// wait for the connection being dialled
+		if dialling {
+			<-wait
+			continue
+		}
+
+		sess, err := m.dial(addr, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		if st, ok := sess.dial(priority); ok {
+			return st, nil
+		}
+	}
+}
+
+// dial a connection to the address and store the session
+func (m *muxTransport) dial(addr string, opts ...transport.DialOption) (*session, error) {
+	c, err := m.transport.Dial(addr, append(opts, transport.WithStream())...)
+
+	m.Lock()
+	defer m.Unlock()
+
+	close(m.dialling[addr])
+	delete(m.dialling, addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sess := newSession(c, true)
+	sess.idle = m.idleTimeout()
+	sess.onClose = func(s *session) {
+		m.removeSession(addr, s)
+	}
+	m.sessions[addr] = sess
+
+	go sess.read()
+	go sess.write()
+
+	return sess, nil
+}
+
+func (m *muxTransport) removeSession(addr string, sess *session) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.sessions[addr] == sess {
+		delete(m.sessions, addr)
+	}
+}
+
+func (m *muxTransport) Listen(addr string, opts ...transport.ListenOption) (transport.Listener, error) {
+	l, err := m.transport.Listen(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &muxListener{listener: l}, nil
+}
+
+func (m *muxTransport) String() string {
+	return "mux"
+}
+
+func (l *muxListener) Addr() string {
+	return l.listener.Addr()
+}
+
+func (l *muxListener) Close() error {
+	return l.listener.Close()
+}
+
+// Accept calls the function for each stream opened on the connections accepted
+func (l *muxListener) Accept(fn func(transport.Socket)) error {
+	return l.listener.Accept(func(sock transport.Socket) {
+		sess := newSession(sock, false)
+		sess.accept = func(st *stream) {
+			fn(st)
+		}
+
+		go sess.write()
+
+		// serve the connection until it's closed
+		sess.read()
+	})
+}
+
+// NewTransport returns a transport which multiplexes streams over the connections of the transport
+func NewTransport(t transport.Transport, opts ...transport.Option) transport.Transport {
+	options := t.Options()
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &muxTransport{
+		transport: t,
+		opts:      options,
+		sessions:  make(map[string]*session),
+		dialling:  make(map[string]chan bool),
+	}
+}
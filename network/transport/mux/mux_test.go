@@ -0,0 +1,233 @@
+package mux
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/network/transport"
+	"github.com/micro/go-micro/v3/network/transport/memory"
+)
+
+// countTransport counts the connections dialled
+type countTransport struct {
+	transport.Transport
+	dials int32
+}
+
+func (c *countTransport) Dial(addr string, opts ...transport.DialOption) (transport.Client, error) {
+	atomic.AddInt32(&c.dials, 1)
+	return c.Transport.Dial(addr, opts...)
+}
+
+func echo(sock transport.Socket) {
+	defer sock.Close()
+	for {
+		var m transport.Message
+		if err := sock.Recv(&m); err != nil {
+			return
+		}
+		if err := sock.Send(&m); err != nil {
+			return
+		}
+	}
+}
+
+func listen(t *testing.T, tr transport.Transport, fn func(transport.Socket)) transport.Listener {
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go l.Accept(fn)
+	return l
+}
+
+func TestMux(t *testing.T) {
+	ct := &countTransport{Transport: memory.NewTransport()}
+	tr := NewTransport(ct)
+
+	l := listen(t, tr, echo)
+	defer l.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			c, err := tr.Dial(l.Addr())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer c.Close()
+
+			// larger than the frame size and window
+			body := bytes.Repeat([]byte{byte(i)}, DefaultWindow*2+i)
+
+			for j := 0; j < 3; j++ {
+				msg := &transport.Message{
+					Header: map[string]string{"Id": fmt.Sprintf("%d-%d", i, j)},
+					Body:   body,
+				}
+				if err := c.Send(msg); err != nil {
+					t.Error(err)
+					return
+				}
+
+				var rsp transport.Message
+				if err := c.Recv(&rsp); err != nil {
+					t.Error(err)
+					return
+				}
+				if rsp.Header["Id"] != msg.Header["Id"] {
+					t.Errorf("Expected message %s, got %s", msg.Header["Id"], rsp.Header["Id"])
+				}
+				if _, ok := rsp.Header[streamHeader]; ok {
+					t.Error("Expected the frame headers to be removed")
+				}
+				if !bytes.Equal(rsp.Body, body) {
+					t.Errorf("Unexpected body for message %s", msg.Header["Id"])
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&ct.dials); n != 1 {
+		t.Fatalf("Expected the streams to share 1 connection, got %d", n)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	s := newSession(nil, true)
+
+	low, _ := s.dial(0)
+	high, _ := s.dial(1)
+
+	low.pending = append(low.pending, &outbound{typ: dataFrame, body: make([]byte, DefaultFrameSize*2), priority: 0})
+	high.pending = append(high.pending, &outbound{typ: dataFrame, body: make([]byte, DefaultFrameSize*2), priority: 1})
+
+	var order []uint64
+	for f, _ := s.next(); f != nil; f, _ = s.next() {
+		if f.Header[streamHeader] == fmt.Sprintf("%d", high.id) {
+			order = append(order, high.id)
+		} else {
+			order = append(order, low.id)
+		}
+	}
+
+	expected := []uint64{high.id, high.id, low.id, low.id}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Fatalf("Expected frames %v, got %v", expected, order)
+	}
+
+	// streams of the same priority are interleaved
+	other, _ := s.dial(0)
+	low.pending = append(low.pending, &outbound{typ: dataFrame, body: make([]byte, DefaultFrameSize*2)})
+	other.pending = append(other.pending, &outbound{typ: dataFrame, body: make([]byte, DefaultFrameSize*2)})
+
+	order = nil
+	for f, _ := s.next(); f != nil; f, _ = s.next() {
+		if f.Header[streamHeader] == fmt.Sprintf("%d", low.id) {
+			order = append(order, low.id)
+		} else {
+			order = append(order, other.id)
+		}
+	}
+
+	if order[0] == order[1] || order[1] == order[2] {
+		t.Fatalf("Expected streams of the same priority to be interleaved, got %v", order)
+	}
+}
+
+func TestFlowControl(t *testing.T) {
+	tr := NewTransport(memory.NewTransport())
+
+	socks := make(chan transport.Socket, 1)
+	l := listen(t, tr, func(sock transport.Socket) {
+		socks <- sock
+	})
+	defer l.Close()
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// the first message is buffered by the peer without being read
+	if err := c.Send(&transport.Message{Body: []byte("hello")}); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := make(chan error, 1)
+	go func() {
+		sent <- c.Send(&transport.Message{Body: make([]byte, DefaultWindow*2)})
+	}()
+
+	select {
+	case <-sent:
+		t.Fatal("Expected the send to block until the peer reads")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	sock := <-socks
+	for i := 0; i < 2; i++ {
+		var m transport.Message
+		if err := sock.Recv(&m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case err := <-sent:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the send to complete once the peer read")
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	ct := &countTransport{Transport: memory.NewTransport()}
+	tr := NewTransport(ct, IdleTimeout(time.Millisecond*50))
+
+	l := listen(t, tr, echo)
+	defer l.Close()
+
+	dial := func() {
+		c, err := tr.Dial(l.Addr(), Priority(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Send(&transport.Message{Body: []byte("hello")}); err != nil {
+			t.Fatal(err)
+		}
+		var m transport.Message
+		if err := c.Recv(&m); err != nil {
+			t.Fatal(err)
+		}
+		c.Close()
+	}
+
+	dial()
+	dial()
+
+	if n := atomic.LoadInt32(&ct.dials); n != 1 {
+		t.Fatalf("Expected the connection to be reused, got %d dials", n)
+	}
+
+	time.Sleep(time.Millisecond * 200)
+	dial()
+
+	if n := atomic.LoadInt32(&ct.dials); n != 2 {
+		t.Fatalf("Expected the idle connection to be closed, got %d dials", n)
+	}
+}
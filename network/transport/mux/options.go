@@ -0,0 +1,33 @@
+package mux
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/network/transport"
+)
+
+type idleTimeoutKey struct{}
+
+type priorityKey struct{}
+
+// IdleTimeout sets how long a connection without streams is kept open for reuse
+func IdleTimeout(d time.Duration) transport.Option {
+	return func(o *transport.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, idleTimeoutKey{}, d)
+	}
+}
+
+// Priority of the stream, frames of higher priority streams are sent first.
+// The priority of a message can be overridden with the Micro-Priority header.
+func Priority(p int) transport.DialOption {
+	return func(o *transport.DialOptions) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, priorityKey{}, p)
+	}
+}
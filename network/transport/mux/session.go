@@ -0,0 +1,517 @@
+package mux
+
+import (
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/network/transport"
+)
+
+const (
+	// the stream a frame belongs to
+	streamHeader = "Micro-Mux-Stream"
+	// the type of frame; data, window or close
+	typeHeader = "Micro-Mux-Type"
+	// the priority of the stream, set on its first frame
+	priorityHeader = "Micro-Mux-Priority"
+	// set on data frames when the message continues in the next frame
+	moreHeader = "Micro-Mux-More"
+	// the window increment of a window frame
+	windowHeader = "Micro-Mux-Window"
+
+	dataFrame   = "data"
+	windowFrame = "window"
+	closeFrame  = "close"
+)
+
+// outbound is a message queued on a stream
+type outbound struct {
+	typ      string
+	header   map[string]string
+	body     []byte
+	priority int
+	// bytes of the body sent
+	offset  int
+	started bool
+	done    chan error
+}
+
+// session multiplexes streams over a single transport socket. Messages are split
+// into frames which are scheduled by the priority of their stream so a large
+// message doesn't block the other streams.
+type session struct {
+	sock   transport.Socket
+	client bool
+
+	sync.Mutex
+	cond    *sync.Cond
+	streams map[uint64]*stream
+	// streams dialled which haven't sent a frame, they're assigned
+	// an id when the first is sent so the peer sees them in order
+	dialled []*stream
+	// the last stream id opened
+	last uint64
+	// streams open locally
+	open int
+	// window updates, sent before data
+	control []*transport.Message
+	// scheduling sequence used to round robin streams of the same priority
+	seq    uint64
+	closed bool
+	err    error
+
+	// idle is how long a client session without streams is kept
+	idle time.Duration
+	// accept is called for streams opened by the peer
+	accept func(*stream)
+	// onClose is called once the session is closed
+	onClose func(*session)
+}
+
+func newSession(sock transport.Socket, client bool) *session {
+	s := &session{
+		sock:    sock,
+		client:  client,
+		streams: make(map[uint64]*stream),
+	}
+	s.cond = sync.NewCond(&s.Mutex)
+	return s
+}
+
+// newStream creates a stream, the session must be locked
+func (s *session) newStream(priority int) *stream {
+	st := &stream{
+		session:  s,
+		priority: priority,
+		window:   int64(DefaultWindow),
+	}
+	st.cond = sync.NewCond(&s.Mutex)
+	s.open++
+	return st
+}
+
+// dial opens a new stream, false if the session is closed
+func (s *session) dial(priority int) (*stream, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return nil, false
+	}
+
+	st := s.newStream(priority)
+	s.dialled = append(s.dialled, st)
+	return st, true
+}
+
+// release is called when a stream is closed locally
+func (s *session) release() {
+	s.open--
+	if !s.client || s.open > 0 || s.closed {
+		return
+	}
+
+	// close the session if no streams are opened before the idle timeout
+	time.AfterFunc(s.idle, func() {
+		s.Lock()
+		idle := s.open == 0
+		s.Unlock()
+		if idle {
+			s.close(io.EOF)
+		}
+	})
+}
+
+// undial removes the stream from the dialled streams, the session must be locked
+func (s *session) undial(st *stream) {
+	for i, c := range s.dialled {
+		if c == st {
+			s.dialled = append(s.dialled[:i], s.dialled[i+1:]...)
+			return
+		}
+	}
+}
+
+func streams(m map[uint64]*stream) []*stream {
+	list := make([]*stream, 0, len(m))
+	for _, st := range m {
+		list = append(list, st)
+	}
+	return list
+}
+
+// grant queues a window update for the stream, the session must be locked
+func (s *session) grant(st *stream, n int) {
+	if n == 0 {
+		return
+	}
+	s.control = append(s.control, &transport.Message{
+		Header: map[string]string{
+			streamHeader: strconv.FormatUint(st.id, 10),
+			typeHeader:   windowFrame,
+			windowHeader: strconv.Itoa(n),
+		},
+	})
+	s.cond.Broadcast()
+}
+
+// next returns the next frame to send and the outbound message it completes.
+// The session must be locked.
+func (s *session) next() (*transport.Message, *outbound) {
+	if len(s.control) > 0 {
+		f := s.control[0]
+		s.control = s.control[1:]
+		return f, nil
+	}
+
+	// pick the highest priority stream which can send, the least recently served first
+	var st *stream
+	pick := func(c *stream) {
+		if len(c.pending) == 0 {
+			return
+		}
+		out := c.pending[0]
+		if out.offset < len(out.body) && c.window <= 0 {
+			return
+		}
+		if st == nil {
+			st = c
+			return
+		}
+		if p := st.pending[0].priority; out.priority > p || (out.priority == p && c.served < st.served) {
+			st = c
+		}
+	}
+	for _, c := range s.streams {
+		pick(c)
+	}
+	for _, c := range s.dialled {
+		pick(c)
+	}
+
+	if st == nil {
+		return nil, nil
+	}
+
+	// assign the id of a dialled stream
+	if st.id == 0 {
+		s.last++
+		st.id = s.last
+		s.streams[st.id] = st
+		s.undial(st)
+	}
+
+	s.seq++
+	st.served = s.seq
+
+	out := st.pending[0]
+
+	header := map[string]string{}
+	if !out.started {
+		for k, v := range out.header {
+			header[k] = v
+		}
+		header[priorityHeader] = strconv.Itoa(out.priority)
+		out.started = true
+	}
+	header[streamHeader] = strconv.FormatUint(st.id, 10)
+	header[typeHeader] = out.typ
+
+	n := len(out.body) - out.offset
+	if n > DefaultFrameSize {
+		n = DefaultFrameSize
+	}
+	if int64(n) > st.window {
+		n = int(st.window)
+	}
+
+	f := &transport.Message{
+		Header: header,
+		Body:   out.body[out.offset : out.offset+n],
+	}
+	out.offset += n
+	st.window -= int64(n)
+
+	if out.offset < len(out.body) {
+		header[moreHeader] = "1"
+		return f, nil
+	}
+
+	st.pending = st.pending[1:]
+
+	// the stream is done once closed on both sides
+	if out.typ == closeFrame && st.remoteClosed {
+		delete(s.streams, st.id)
+	}
+
+	return f, out
+}
+
+// write sends the frames until the session is closed
+func (s *session) write() {
+	for {
+		s.Lock()
+		f, out := s.next()
+		for f == nil && !s.closed {
+			s.cond.Wait()
+			f, out = s.next()
+		}
+		if s.closed {
+			s.Unlock()
+			return
+		}
+		s.Unlock()
+
+		if err := s.sock.Send(f); err != nil {
+			if out != nil {
+				out.done <- err
+			}
+			s.close(err)
+			return
+		}
+
+		if out != nil {
+			out.done <- nil
+		}
+	}
+}
+
+// read receives frames and dispatches them to the streams until the session is closed
+func (s *session) read() {
+	for {
+		var m transport.Message
+		if err := s.sock.Recv(&m); err != nil {
+			s.close(err)
+			return
+		}
+
+		id, err := strconv.ParseUint(m.Header[streamHeader], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		s.Lock()
+		st, ok := s.streams[id]
+
+		switch m.Header[typeHeader] {
+		case windowFrame:
+			n, _ := strconv.Atoi(m.Header[windowHeader])
+			if ok {
+				st.window += int64(n)
+				s.cond.Broadcast()
+			}
+		case closeFrame:
+			if ok {
+				st.remoteClosed = true
+				st.cond.Broadcast()
+				if st.closed && len(st.pending) == 0 {
+					delete(s.streams, id)
+				}
+			}
+		case dataFrame:
+			var accept bool
+
+			// the peer opens streams with increasing ids, ignore frames for streams closed
+			if !ok {
+				if s.client || id <= s.last || s.closed {
+					s.Unlock()
+					continue
+				}
+				priority, _ := strconv.Atoi(m.Header[priorityHeader])
+				s.last = id
+				st = s.newStream(priority)
+				st.id = id
+				s.streams[id] = st
+				accept = true
+			}
+
+			st.recv(&m)
+
+			if accept && s.accept != nil {
+				go s.accept(st)
+			}
+		}
+
+		s.Unlock()
+	}
+}
+
+// close the session and its streams
+func (s *session) close(err error) {
+	s.Lock()
+	if s.closed {
+		s.Unlock()
+		return
+	}
+	s.closed = true
+	s.err = err
+
+	for _, st := range append(s.dialled, streams(s.streams)...) {
+		for _, out := range st.pending {
+			out.done <- err
+		}
+		st.pending = nil
+		st.cond.Broadcast()
+	}
+	s.cond.Broadcast()
+	s.Unlock()
+
+	s.sock.Close()
+
+	if s.onClose != nil {
+		s.onClose(s)
+	}
+}
+
+// stream is a logical socket multiplexed over the session
+type stream struct {
+	id       uint64
+	session  *session
+	priority int
+
+	// guarded by the session lock
+	cond *sync.Cond
+	// bytes which can be sent before the peer grants more
+	window  int64
+	pending []*outbound
+	served  uint64
+	// the message being received and the complete messages not yet read
+	partial *transport.Message
+	queue   []*transport.Message
+	// bytes received which haven't been granted back to the peer
+	owed         int
+	closed       bool
+	remoteClosed bool
+}
+
+// recv adds the data frame to the stream, the session must be locked
+func (st *stream) recv(m *transport.Message) {
+	if st.partial == nil {
+		header := make(map[string]string, len(m.Header))
+		for k, v := range m.Header {
+			switch k {
+			case streamHeader, typeHeader, priorityHeader, moreHeader:
+				continue
+			}
+			header[k] = v
+		}
+		st.partial = &transport.Message{Header: header}
+	}
+	st.partial.Body = append(st.partial.Body, m.Body...)
+
+	// frames are granted back immediately unless messages are waiting to be read,
+	// this bounds what's buffered by the window without blocking large messages
+	if len(st.queue) == 0 {
+		st.session.grant(st, len(m.Body))
+	} else {
+		st.owed += len(m.Body)
+	}
+
+	if m.Header[moreHeader] == "1" {
+		return
+	}
+
+	st.queue = append(st.queue, st.partial)
+	st.partial = nil
+	st.cond.Broadcast()
+}
+
+func (st *stream) Recv(m *transport.Message) error {
+	s := st.session
+	s.Lock()
+	defer s.Unlock()
+
+	for len(st.queue) == 0 && !st.remoteClosed && !st.closed && !s.closed {
+		st.cond.Wait()
+	}
+
+	if len(st.queue) > 0 {
+		*m = *st.queue[0]
+		st.queue[0] = nil
+		st.queue = st.queue[1:]
+
+		if len(st.queue) == 0 {
+			s.grant(st, st.owed)
+			st.owed = 0
+		}
+		return nil
+	}
+
+	if s.closed && !st.remoteClosed && s.err != io.EOF {
+		return s.err
+	}
+
+	return io.EOF
+}
+
+func (st *stream) Send(m *transport.Message) error {
+	s := st.session
+	s.Lock()
+
+	if st.closed {
+		s.Unlock()
+		return ErrStreamClosed
+	}
+	if s.closed {
+		s.Unlock()
+		return s.err
+	}
+
+	priority := st.priority
+	if v, err := strconv.Atoi(m.Header["Micro-Priority"]); err == nil {
+		priority = v
+	}
+
+	out := &outbound{
+		typ:      dataFrame,
+		header:   m.Header,
+		body:     m.Body,
+		priority: priority,
+		done:     make(chan error, 1),
+	}
+	st.pending = append(st.pending, out)
+	s.cond.Broadcast()
+	s.Unlock()
+
+	return <-out.done
+}
+
+func (st *stream) Close() error {
+	s := st.session
+	s.Lock()
+	defer s.Unlock()
+
+	if st.closed {
+		return nil
+	}
+	st.closed = true
+	st.cond.Broadcast()
+
+	if s.closed {
+		delete(s.streams, st.id)
+	} else if st.id == 0 && len(st.pending) == 0 {
+		// the peer hasn't seen the stream
+		s.undial(st)
+	} else {
+		// queued behind the pending messages so they're sent first
+		st.pending = append(st.pending, &outbound{
+			typ:      closeFrame,
+			priority: st.priority,
+			done:     make(chan error, 1),
+		})
+		s.cond.Broadcast()
+	}
+
+	s.release()
+	return nil
+}
+
+func (st *stream) Local() string {
+	return st.session.sock.Local()
+}
+
+func (st *stream) Remote() string {
+	return st.session.sock.Remote()
+}
@@ -0,0 +1,521 @@
+// Package nats provides a NATS transport. Services listen on a subject rather than a port
+// so requests flow over an existing NATS cluster without dialling between hosts. A connection
+// is made by a request to the subject of the listener, one listener of its queue group accepts
+// it and the messages of the connection are then exchanged over the inboxes of both ends.
+package nats
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/codec"
+	"github.com/micro/go-micro/v3/codec/json"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/network/transport"
+	nats "github.com/nats-io/nats.go"
+)
+
+const (
+	// controlHeader is set on the messages managing a connection
+	controlHeader = "Micro-Nats-Control"
+
+	connectControl = "connect"
+	acceptControl  = "accept"
+	closeControl   = "close"
+)
+
+var (
+	// DefaultPrefix of the subjects generated for listeners without an address
+	DefaultPrefix = "go.micro.transport."
+	// DefaultBufferSize of the connect requests received by a listener before they're accepted
+	DefaultBufferSize = 256
+	// DefaultPendingMsgs is the number of messages received by a connection held before they're
+	// read, past it the messages are dropped and the connection fails as a slow consumer
+	DefaultPendingMsgs = nats.DefaultSubPendingMsgsLimit
+	// DefaultPendingBytes is the size in bytes of the messages received by a connection held
+	// before they're read
+	DefaultPendingBytes = nats.DefaultSubPendingBytesLimit
+
+	// ErrNotAccepted is returned when the reply to a connect isn't an accept
+	ErrNotAccepted = errors.New("connection not accepted")
+)
+
+// timeoutError is returned when a dial or receive times out, it satisfies net.Error
+// so callers treat it like the timeouts of the network transports
+type timeoutError struct {
+	op   string
+	addr string
+}
+
+func (e *timeoutError) Error() string {
+	return "nats " + e.op + " " + e.addr + ": i/o timeout"
+}
+
+func (e *timeoutError) Timeout() bool {
+	return true
+}
+
+func (e *timeoutError) Temporary() bool {
+	return true
+}
+
+type natsTransport struct {
+	sync.RWMutex
+	opts  transport.Options
+	nopts nats.Options
+	conn  *nats.Conn
+	// of the connections
+	limits limits
+}
+
+type natsSocket struct {
+	conn  *nats.Conn
+	codec codec.Marshaler
+	sub   *nats.Subscription
+	recv  chan *nats.Msg
+
+	local  string
+	remote string
+
+	// for send/recv transport.Timeout
+	timeout time.Duration
+
+	once sync.Once
+	// sock exit
+	exit chan bool
+	// listener exit
+	lexit chan bool
+}
+
+// limits of the messages received by a connection before they're read
+type limits struct {
+	msgs  int
+	bytes int
+}
+
+// subscribe to the inbox of a connection. The messages are handed over as they're read,
+// nats holds the pending ones up to the limits and drops the rest as a slow consumer.
+func subscribe(conn *nats.Conn, inbox string, l limits) (*nats.Subscription, chan *nats.Msg, chan bool, error) {
+	recv := make(chan *nats.Msg)
+	exit := make(chan bool)
+
+	sub, err := conn.Subscribe(inbox, func(m *nats.Msg) {
+		select {
+		case recv <- m:
+		case <-exit:
+		}
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := sub.SetPendingLimits(l.msgs, l.bytes); err != nil {
+		sub.Unsubscribe()
+		return nil, nil, nil, err
+	}
+	return sub, recv, exit, nil
+}
+
+// unsubscribe from the inbox of a connection which wasn't established
+func unsubscribe(sub *nats.Subscription, exit chan bool) {
+	sub.Unsubscribe()
+	close(exit)
+}
+
+type natsListener struct {
+	addr  string
+	conn  *nats.Conn
+	codec codec.Marshaler
+	sub   *nats.Subscription
+	conns chan *nats.Msg
+	// of the connections accepted
+	limits limits
+
+	// for send/recv transport.Timeout
+	timeout time.Duration
+
+	once sync.Once
+	exit chan bool
+}
+
+func (s *natsSocket) Recv(m *transport.Message) error {
+	var timeout <-chan time.Time
+	if s.timeout > 0 {
+		t := time.NewTimer(s.timeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	select {
+	case <-s.exit:
+		return io.EOF
+	case <-s.lexit:
+		return errors.New("server connection closed")
+	case <-timeout:
+		return &timeoutError{op: "recv", addr: s.remote}
+	case msg := <-s.recv:
+		// messages dropped past the pending limits can't be recovered, the connection is broken
+		if n, err := s.sub.Dropped(); err == nil && n > 0 {
+			return nats.ErrSlowConsumer
+		}
+		return s.read(msg, m)
+	}
+}
+
+// read a message received by the socket
+func (s *natsSocket) read(msg *nats.Msg, m *transport.Message) error {
+	var tm transport.Message
+	if err := s.codec.Unmarshal(msg.Data, &tm); err != nil {
+		return err
+	}
+	// the other end closed the connection
+	if tm.Header[controlHeader] == closeControl {
+		s.close(false)
+		return io.EOF
+	}
+	*m = tm
+
+	return nil
+}
+
+func (s *natsSocket) Send(m *transport.Message) error {
+	select {
+	case <-s.exit:
+		return errors.New("connection closed")
+	case <-s.lexit:
+		return errors.New("server connection closed")
+	default:
+	}
+
+	b, err := s.codec.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return s.conn.Publish(s.remote, b)
+}
+
+func (s *natsSocket) Local() string {
+	return s.local
+}
+
+func (s *natsSocket) Remote() string {
+	return s.remote
+}
+
+func (s *natsSocket) Close() error {
+	s.close(true)
+	return nil
+}
+
+// close the socket, notifying the other end unless it closed the connection
+func (s *natsSocket) close(notify bool) {
+	s.once.Do(func() {
+		if notify {
+			if b, err := s.codec.Marshal(&transport.Message{
+				Header: map[string]string{controlHeader: closeControl},
+			}); err == nil {
+				s.conn.Publish(s.remote, b)
+			}
+		}
+		s.sub.Unsubscribe()
+		close(s.exit)
+	})
+}
+
+func (l *natsListener) Addr() string {
+	return l.addr
+}
+
+func (l *natsListener) Close() error {
+	var err error
+	l.once.Do(func() {
+		err = l.sub.Unsubscribe()
+		close(l.exit)
+	})
+	return err
+}
+
+func (l *natsListener) Accept(fn func(transport.Socket)) error {
+	for {
+		select {
+		case <-l.exit:
+			return nil
+		case m := <-l.conns:
+			sock, err := l.accept(m)
+			if err != nil {
+				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+					logger.Debugf("Nats transport failed to accept connection from %s: %v", m.Reply, err)
+				}
+				continue
+			}
+			go fn(sock)
+		}
+	}
+}
+
+// accept a connect request, replying from the inbox the connection is served on
+func (l *natsListener) accept(m *nats.Msg) (*natsSocket, error) {
+	var tm transport.Message
+	if err := l.codec.Unmarshal(m.Data, &tm); err != nil {
+		return nil, err
+	}
+	if tm.Header[controlHeader] != connectControl || len(m.Reply) == 0 {
+		return nil, errors.New("invalid connect request")
+	}
+
+	inbox := nats.NewInbox()
+
+	sub, recv, exit, err := subscribe(l.conn, inbox, l.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := l.codec.Marshal(&transport.Message{
+		Header: map[string]string{controlHeader: acceptControl},
+	})
+	if err != nil {
+		unsubscribe(sub, exit)
+		return nil, err
+	}
+
+	if err := l.conn.PublishMsg(&nats.Msg{Subject: m.Reply, Reply: inbox, Data: b}); err != nil {
+		unsubscribe(sub, exit)
+		return nil, err
+	}
+
+	return &natsSocket{
+		conn:    l.conn,
+		codec:   l.codec,
+		sub:     sub,
+		recv:    recv,
+		local:   l.addr,
+		remote:  m.Reply,
+		timeout: l.timeout,
+		exit:    exit,
+		lexit:   l.exit,
+	}, nil
+}
+
+// connect returns the connection to the nats cluster, connecting if need be
+func (n *natsTransport) connect() (*nats.Conn, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.conn != nil && !n.conn.IsClosed() {
+		return n.conn, nil
+	}
+
+	opts := n.nopts
+	opts.Servers = setAddrs(n.opts.Addrs)
+	opts.Secure = n.opts.Secure
+	opts.TLSConfig = n.opts.TLSConfig
+
+	// secure might not be set
+	if n.opts.TLSConfig != nil {
+		opts.Secure = true
+	}
+
+	c, err := opts.Connect()
+	if err != nil {
+		return nil, err
+	}
+	n.conn = c
+
+	return c, nil
+}
+
+func (n *natsTransport) Dial(addr string, opts ...transport.DialOption) (transport.Client, error) {
+	dopts := transport.DialOptions{
+		Timeout: transport.DefaultDialTimeout,
+	}
+
+	for _, o := range opts {
+		o(&dopts)
+	}
+
+	conn, err := n.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	inbox := nats.NewInbox()
+
+	sub, recv, exit, err := subscribe(conn, inbox, n.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := n.opts.Codec.Marshal(&transport.Message{
+		Header: map[string]string{controlHeader: connectControl},
+	})
+	if err != nil {
+		unsubscribe(sub, exit)
+		return nil, err
+	}
+
+	if err := conn.PublishMsg(&nats.Msg{Subject: addr, Reply: inbox, Data: b}); err != nil {
+		unsubscribe(sub, exit)
+		return nil, err
+	}
+
+	var timeout <-chan time.Time
+	if dopts.Timeout > 0 {
+		t := time.NewTimer(dopts.Timeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	// wait for a listener to accept the connection
+	select {
+	case <-timeout:
+		unsubscribe(sub, exit)
+		return nil, &timeoutError{op: "dial", addr: addr}
+	case m := <-recv:
+		var tm transport.Message
+		if err := n.opts.Codec.Unmarshal(m.Data, &tm); err != nil {
+			unsubscribe(sub, exit)
+			return nil, err
+		}
+		if tm.Header[controlHeader] != acceptControl || len(m.Reply) == 0 {
+			unsubscribe(sub, exit)
+			return nil, ErrNotAccepted
+		}
+
+		return &natsSocket{
+			conn:    conn,
+			codec:   n.opts.Codec,
+			sub:     sub,
+			recv:    recv,
+			local:   inbox,
+			remote:  m.Reply,
+			timeout: n.opts.Timeout,
+			exit:    exit,
+		}, nil
+	}
+}
+
+func (n *natsTransport) Listen(addr string, opts ...transport.ListenOption) (transport.Listener, error) {
+	var lopts transport.ListenOptions
+	for _, o := range opts {
+		o(&lopts)
+	}
+
+	// listeners given a network address e.g :0 rather than a subject get a unique one
+	if len(addr) == 0 || strings.Contains(addr, ":") {
+		addr = DefaultPrefix + uuid.New().String()
+	}
+
+	queue := addr
+	if lopts.Context != nil {
+		if q, ok := lopts.Context.Value(queueKey{}).(string); ok && len(q) > 0 {
+			queue = q
+		}
+	}
+
+	conn, err := n.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &natsListener{
+		addr:    addr,
+		conn:    conn,
+		codec:   n.opts.Codec,
+		conns:   make(chan *nats.Msg, DefaultBufferSize),
+		limits:  n.limits,
+		timeout: n.opts.Timeout,
+		exit:    make(chan bool),
+	}
+
+	l.sub, err = conn.ChanQueueSubscribe(addr, queue, l.conns)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (n *natsTransport) Init(opts ...transport.Option) error {
+	n.Lock()
+	defer n.Unlock()
+
+	n.setOption(opts...)
+	return nil
+}
+
+func (n *natsTransport) Options() transport.Options {
+	return n.opts
+}
+
+func (n *natsTransport) String() string {
+	return "nats"
+}
+
+func (n *natsTransport) setOption(opts ...transport.Option) {
+	for _, o := range opts {
+		o(&n.opts)
+	}
+
+	if n.opts.Codec == nil {
+		n.opts.Codec = json.Marshaler{}
+	}
+
+	if n.opts.Context == nil {
+		return
+	}
+
+	if l, ok := n.opts.Context.Value(pendingLimitsKey{}).(limits); ok {
+		n.limits = l
+	}
+
+	if nopts, ok := n.opts.Context.Value(optionsKey{}).(nats.Options); ok {
+		n.nopts = nopts
+
+		// transport.Options have higher priority than nats.Options
+		if len(n.opts.Addrs) == 0 {
+			n.opts.Addrs = nopts.Servers
+		}
+		if !n.opts.Secure {
+			n.opts.Secure = nopts.Secure
+		}
+		if n.opts.TLSConfig == nil {
+			n.opts.TLSConfig = nopts.TLSConfig
+		}
+	}
+}
+
+func setAddrs(addrs []string) []string {
+	//nolint:prealloc
+	var cAddrs []string
+	for _, addr := range addrs {
+		if len(addr) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(addr, "nats://") {
+			addr = "nats://" + addr
+		}
+		cAddrs = append(cAddrs, addr)
+	}
+	if len(cAddrs) == 0 {
+		cAddrs = []string{nats.DefaultURL}
+	}
+	return cAddrs
+}
+
+// NewTransport returns a transport over a NATS cluster
+func NewTransport(opts ...transport.Option) transport.Transport {
+	n := &natsTransport{
+		nopts: nats.GetDefaultOptions(),
+		limits: limits{
+			msgs:  DefaultPendingMsgs,
+			bytes: DefaultPendingBytes,
+		},
+	}
+	n.setOption(opts...)
+
+	return n
+}
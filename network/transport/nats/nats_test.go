@@ -0,0 +1,166 @@
+package nats
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/network/transport"
+	nats "github.com/nats-io/nats.go"
+)
+
+func TestTransport(t *testing.T) {
+	conn, err := net.DialTimeout("tcp", ":4222", time.Millisecond*100)
+	if err != nil {
+		t.Skipf("Skipping nats test, could not connect to cluster on port 4222: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Error closing test tcp connection to nats cluster")
+	}
+
+	testTransport(t, NewTransport(transport.Timeout(time.Second)))
+}
+
+func testTransport(t *testing.T, tr transport.Transport) {
+	l, err := tr.Listen(":0")
+	if err != nil {
+		t.Fatalf("Unexpected error listening: %v", err)
+	}
+	defer l.Close()
+
+	go l.Accept(func(sock transport.Socket) {
+		defer sock.Close()
+
+		for {
+			var m transport.Message
+			if err := sock.Recv(&m); err != nil {
+				return
+			}
+			if err := sock.Send(&m); err != nil {
+				return
+			}
+		}
+	})
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected error dialing: %v", err)
+	}
+
+	// stream messages over the connection
+	for _, body := range []string{"foo", "bar", "baz"} {
+		m := transport.Message{
+			Header: map[string]string{"Content-Type": "application/json"},
+			Body:   []byte(body),
+		}
+		if err := c.Send(&m); err != nil {
+			t.Fatalf("Unexpected error sending: %v", err)
+		}
+
+		var rsp transport.Message
+		if err := c.Recv(&rsp); err != nil {
+			t.Fatalf("Unexpected error receiving: %v", err)
+		}
+		if string(rsp.Body) != body || rsp.Header["Content-Type"] != "application/json" {
+			t.Fatalf("Expected %s echoed, got %+v", body, rsp)
+		}
+	}
+
+	// the server closes its end once the client closed
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// dialling a subject nobody listens on times out
+	_, err = tr.Dial(l.Addr()+".missing", transport.WithTimeout(time.Millisecond*100))
+	if nerr, ok := err.(net.Error); !ok || !nerr.Timeout() {
+		t.Fatalf("Expected a timeout dialing, got %v", err)
+	}
+
+	// receiving without a reply times out
+	l2, err := tr.Listen(":0")
+	if err != nil {
+		t.Fatalf("Unexpected error listening: %v", err)
+	}
+	defer l2.Close()
+
+	done := make(chan bool)
+	defer close(done)
+
+	go l2.Accept(func(sock transport.Socket) {
+		<-done
+		sock.Close()
+	})
+
+	c, err = tr.Dial(l2.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected error dialing: %v", err)
+	}
+	defer c.Close()
+
+	var m transport.Message
+	err = c.Recv(&m)
+	if nerr, ok := err.(net.Error); !ok || !nerr.Timeout() {
+		t.Fatalf("Expected a timeout receiving, got %v", err)
+	}
+}
+
+func TestPendingLimits(t *testing.T) {
+	tr := NewTransport().(*natsTransport)
+	if tr.limits.msgs != DefaultPendingMsgs || tr.limits.bytes != DefaultPendingBytes {
+		t.Fatalf("Expected the default pending limits, got %+v", tr.limits)
+	}
+
+	tr = NewTransport(PendingLimits(16, 1024)).(*natsTransport)
+	if tr.limits.msgs != 16 || tr.limits.bytes != 1024 {
+		t.Fatalf("Expected the pending limits to be set, got %+v", tr.limits)
+	}
+}
+
+func TestSlowConsumer(t *testing.T) {
+	conn, err := net.DialTimeout("tcp", ":4222", time.Millisecond*100)
+	if err != nil {
+		t.Skipf("Skipping nats test, could not connect to cluster on port 4222: %v", err)
+	}
+	conn.Close()
+
+	tr := NewTransport(transport.Timeout(time.Second), PendingLimits(1, -1))
+
+	l, err := tr.Listen(":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	socks := make(chan transport.Socket, 1)
+	go l.Accept(func(sock transport.Socket) {
+		socks <- sock
+	})
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	sock := <-socks
+	defer sock.Close()
+
+	// more messages than held before they're read
+	for i := 0; i < 10; i++ {
+		if err := c.Send(&transport.Message{Body: []byte(strconv.Itoa(i))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(time.Millisecond * 100)
+
+	var recvErr error
+	for i := 0; i < 10 && recvErr == nil; i++ {
+		var m transport.Message
+		recvErr = sock.Recv(&m)
+	}
+	if recvErr != nats.ErrSlowConsumer {
+		t.Fatalf("Expected %v got %v", nats.ErrSlowConsumer, recvErr)
+	}
+}
@@ -0,0 +1,45 @@
+package nats
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/network/transport"
+	nats "github.com/nats-io/nats.go"
+)
+
+type optionsKey struct{}
+type queueKey struct{}
+type pendingLimitsKey struct{}
+
+// Options accepts nats.Options
+func Options(opts nats.Options) transport.Option {
+	return func(o *transport.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, optionsKey{}, opts)
+	}
+}
+
+// Queue sets the queue group of a listener, the listeners of a group share the connections
+// made to its subject. Listeners default to a group named after the subject.
+func Queue(q string) transport.ListenOption {
+	return func(o *transport.ListenOptions) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, queueKey{}, q)
+	}
+}
+
+// PendingLimits sets the number and size in bytes of the messages received by a connection
+// held before they're read. Past them the messages are dropped and the reads of the connection
+// fail with nats.ErrSlowConsumer. A negative limit is unlimited.
+func PendingLimits(msgs, bytes int) transport.Option {
+	return func(o *transport.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, pendingLimitsKey{}, limits{msgs: msgs, bytes: bytes})
+	}
+}
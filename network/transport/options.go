@@ -23,6 +23,23 @@ type Options struct {
 	TLSConfig *tls.Config
 	// Timeout sets the timeout for Send/Recv
 	Timeout time.Duration
+	// MaxConnections is the maximum number of connections accepted
+	// by a listener at once, unlimited if zero
+	MaxConnections int
+	// ReadRate and WriteRate limit the bytes per second read and
+	// written by each connection, unlimited if zero
+	ReadRate  int64
+	WriteRate int64
+	// MaxHeaderSize is the maximum size of the headers of a message
+	// and MaxMessageSize of its body, unlimited if zero
+	MaxHeaderSize  int
+	MaxMessageSize int64
+	// KeepAlive is how long a connection is idle before the peer is probed
+	// and KeepAliveTimeout how long before a peer not responding is dead
+	KeepAlive        time.Duration
+	KeepAliveTimeout time.Duration
+	// OnConnState is called with the remote address as the state of connections changes
+	OnConnState func(addr string, state ConnState)
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -74,6 +91,51 @@ func Timeout(t time.Duration) Option {
 	}
 }
 
+// MaxConnections limits the connections accepted by a listener at once
+func MaxConnections(n int) Option {
+	return func(o *Options) {
+		o.MaxConnections = n
+	}
+}
+
+// RateLimit limits the bytes per second read and written by each connection
+func RateLimit(read, write int64) Option {
+	return func(o *Options) {
+		o.ReadRate = read
+		o.WriteRate = write
+	}
+}
+
+// MaxHeaderSize limits the size of the headers of messages
+func MaxHeaderSize(n int) Option {
+	return func(o *Options) {
+		o.MaxHeaderSize = n
+	}
+}
+
+// MaxMessageSize limits the size of the body of messages
+func MaxMessageSize(n int64) Option {
+	return func(o *Options) {
+		o.MaxMessageSize = n
+	}
+}
+
+// KeepAlive probes peers once connections are idle for the interval and
+// closes the connection if they haven't responded within the timeout
+func KeepAlive(interval, timeout time.Duration) Option {
+	return func(o *Options) {
+		o.KeepAlive = interval
+		o.KeepAliveTimeout = timeout
+	}
+}
+
+// OnConnState sets the function called as the state of connections changes
+func OnConnState(fn func(addr string, state ConnState)) Option {
+	return func(o *Options) {
+		o.OnConnState = fn
+	}
+}
+
 // Use secure communication. If TLSConfig is not specified we
 // use InsecureSkipVerify and generate a self signed cert
 func Secure(b bool) Option {
@@ -2,6 +2,7 @@
 package transport
 
 import (
+	"errors"
 	"time"
 )
 
@@ -47,4 +48,9 @@ type ListenOption func(*ListenOptions)
 
 var (
 	DefaultDialTimeout = time.Second * 5
+
+	// ErrHeaderTooLarge is returned when the headers of a message exceed the max header size
+	ErrHeaderTooLarge = errors.New("message header too large")
+	// ErrMessageTooLarge is returned when the body of a message exceeds the max message size
+	ErrMessageTooLarge = errors.New("message too large")
 )
@@ -3,11 +3,15 @@ package mucp
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/hex"
+	"io"
 
 	"github.com/micro/go-micro/v3/network/tunnel"
 	"github.com/oxtoacart/bpool"
+	"golang.org/x/crypto/hkdf"
 )
 
 var (
@@ -24,6 +28,27 @@ func hash(key []byte) []byte {
 	return sum[:]
 }
 
+// sign returns the hex encoded HMAC-SHA256 of the tunnel id, time and nonce of a connect
+func sign(key, id, ts, nonce string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(id + "/" + ts + "/" + nonce))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deriveKey returns the key of the interval derived from the token with HKDF,
+// the token itself when the keys aren't rotated
+func deriveKey(key []byte, epoch string) ([]byte, error) {
+	if len(epoch) == 0 {
+		return key, nil
+	}
+
+	derived := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, nil, []byte("micro-tunnel-key "+epoch)), derived); err != nil {
+		return nil, err
+	}
+	return derived, nil
+}
+
 // Encrypt encrypts data and returns the encrypted data
 func Encrypt(gcm cipher.AEAD, data []byte) ([]byte, error) {
 	var err error
@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/micro/go-micro/v3/logger"
 	"github.com/micro/go-micro/v3/network/transport"
+	"github.com/micro/go-micro/v3/network/tunnel"
 )
 
 type link struct {
@@ -42,6 +43,8 @@ type link struct {
 	// after sending the message. the
 	// listener waits for the connect
 	connected bool
+	// the address advertised by the remote side
+	address string
 	// the last time we received a keepalive
 	// on this link from the remote side
 	lastKeepAlive time.Time
@@ -53,6 +56,13 @@ type link struct {
 	rate float64
 	// keep an error count on the link
 	errCount int
+	// the id of the tunnel on the other side
+	node string
+	// when the link was created
+	since time.Time
+	// the traffic counters
+	sent, received           uint64
+	bytesSent, bytesReceived uint64
 }
 
 // packet send over link
@@ -91,6 +101,7 @@ func newLink(s transport.Socket) *link {
 		Socket:        s,
 		id:            uuid.New().String(),
 		lastKeepAlive: time.Now(),
+		since:         time.Now(),
 		closed:        make(chan bool),
 		channels:      make(map[string]time.Time),
 		state:         make(chan *packet, 64),
@@ -473,6 +484,13 @@ func (l *link) Send(m *transport.Message) error {
 	case err = <-p.status:
 	}
 
+	if err == nil {
+		l.Lock()
+		l.sent++
+		l.bytesSent += uint64(dataSent)
+		l.Unlock()
+	}
+
 	// create a metric with
 	// time taken, size of package, error status
 	mt := &metric{
@@ -513,9 +531,44 @@ func (l *link) Recv(m *transport.Message) error {
 		}
 		*m = *pk.message
 	}
+
+	dataRecv := len(m.Body)
+	for k, v := range m.Header {
+		dataRecv += (len(k) + len(v))
+	}
+
+	l.Lock()
+	l.received++
+	l.bytesReceived += uint64(dataRecv)
+	l.Unlock()
+
 	return nil
 }
 
+// setNode sets the id of the tunnel on the other side of the link
+func (l *link) setNode(id string) {
+	l.Lock()
+	if len(l.node) == 0 {
+		l.node = id
+	}
+	l.Unlock()
+}
+
+// Stats returns the traffic counters of the link
+func (l *link) Stats() tunnel.LinkStats {
+	l.RLock()
+	defer l.RUnlock()
+
+	return tunnel.LinkStats{
+		Node:          l.node,
+		Since:         l.since,
+		Sent:          l.sent,
+		Received:      l.received,
+		BytesSent:     l.bytesSent,
+		BytesReceived: l.bytesReceived,
+	}
+}
+
 // State can return connected, closed, error
 func (l *link) State() string {
 	select {
@@ -106,6 +106,8 @@ func (t *tunListener) process() {
 					errChan: make(chan error, 1),
 					// set the read timeout
 					readTimeout: t.session.readTimeout,
+					// rotate the keys like the listener
+					rotate: t.session.rotate,
 				}
 
 				// save the session
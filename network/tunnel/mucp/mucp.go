@@ -1,8 +1,10 @@
 package mucp
 
 import (
+	"crypto/hmac"
 	"errors"
 	"math/rand"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +22,8 @@ var (
 	KeepAliveTime = 30 * time.Second
 	// ReconnectTime defines time interval we periodically attempt to reconnect dead links
 	ReconnectTime = 5 * time.Second
+	// ConnectSkew is the maximum age of a signed connect message
+	ConnectSkew = time.Minute
 
 	// create a logger
 	log = logger.NewHelper(logger.DefaultLogger).WithFields(map[string]interface{}{"package": "tunnel"})
@@ -37,6 +41,12 @@ type tun struct {
 	// tunnel token for session encryption
 	token string
 
+	// the key the tunnel signs its id with on connect
+	key string
+
+	// the nonces of the connects seen, to refuse replays
+	nonces map[string]time.Time
+
 	// to indicate if we're connected or not
 	connected bool
 
@@ -52,6 +62,12 @@ type tun struct {
 	// outbound links
 	links map[string]*link
 
+	// sockets of the links relayed by other nodes
+	relayed map[string]*relaySocket
+
+	// the address advertised to other nodes
+	address string
+
 	// listener
 	listener transport.Listener
 }
@@ -68,10 +84,13 @@ func NewTunnel(opts ...tunnel.Option) *tun {
 		options:  options,
 		id:       options.Id,
 		token:    options.Token,
+		key:      options.Key,
+		nonces:   make(map[string]time.Time),
 		send:     make(chan *message, 128),
 		closed:   make(chan bool),
 		sessions: make(map[string]*session),
 		links:    make(map[string]*link),
+		relayed:  make(map[string]*relaySocket),
 	}
 }
 
@@ -137,12 +156,11 @@ func (t *tun) newSession(channel, sessionId string) (*session, bool, error) {
 		send:    t.send,
 		errChan: make(chan error, 1),
 		key:     []byte(t.token + channel + sessionId),
+		rotate:  t.options.KeyRotation,
 	}
-	gcm, err := newCipher(s.key)
-	if err != nil {
+	if _, err := s.cipher(s.keyEpoch()); err != nil {
 		return nil, false, err
 	}
-	s.gcm = gcm
 
 	// save session
 	t.Lock()
@@ -289,8 +307,16 @@ func (t *tun) manageLinks() {
 
 	t.RLock()
 
-	// get list of nodes from options
-	nodes := t.options.Nodes
+	// get list of nodes and relays from options
+	nodes := append([]string{}, t.options.Nodes...)
+	for _, relay := range t.options.Relays {
+		if !hasNode(nodes, relay) {
+			nodes = append(nodes, relay)
+		}
+	}
+
+	// relayed links to try to upgrade
+	punch := make(map[*link]*relaySocket)
 
 	// check the link status and purge dead links
 	for node, link := range t.links {
@@ -298,9 +324,24 @@ func (t *tun) manageLinks() {
 		switch link.State() {
 		case "closed", "error":
 			delLinks[link] = node
+			continue
 		default:
 			connected[node] = true
 		}
+
+		s, ok := relayed(link)
+		if !ok {
+			continue
+		}
+		// nothing heard from the other side
+		if time.Since(s.lastSeen()) > KeepAliveTime*2 {
+			delLinks[link] = node
+			delete(connected, node)
+			continue
+		}
+		if s.punch() {
+			punch[link] = s
+		}
 	}
 
 	t.RUnlock()
@@ -343,6 +384,11 @@ func (t *tun) manageLinks() {
 		t.Unlock()
 	}
 
+	// try to replace relayed links with direct links
+	for link, s := range punch {
+		go t.punch(link, s)
+	}
+
 	var wg sync.WaitGroup
 
 	// establish new links
@@ -356,6 +402,10 @@ func (t *tun) manageLinks() {
 			// create new link
 			// if we're using quic it should be a max 10 second handshake period
 			link, err := t.setupLink(node)
+			if err != nil && len(t.options.Relays) > 0 && !hasNode(t.options.Relays, node) {
+				// fallback to reaching the node through a relay
+				link, err = t.setupRelayLink(node)
+			}
 			if err != nil {
 				if logger.V(logger.DebugLevel, log) {
 					log.Debugf("Tunnel failed to setup node link to %s: %v", node, err)
@@ -594,10 +644,28 @@ func (t *tun) delLink(remote string) {
 	t.Unlock()
 }
 
+// delRelayedLink removes the relayed link if it was not replaced
+func (t *tun) delRelayedLink(link *link) {
+	t.Lock()
+	for id, l := range t.links {
+		if l == link {
+			delete(t.links, id)
+		}
+	}
+	t.Unlock()
+
+	link.Close()
+}
+
 // process incoming messages
 func (t *tun) listen(link *link) {
 	// remove the link on exit
 	defer func() {
+		// relayed links share the remote address of the direct links replacing them
+		if _, ok := relayed(link); ok {
+			t.delRelayedLink(link)
+			return
+		}
 		t.delLink(link.Remote())
 	}()
 
@@ -633,6 +701,35 @@ func (t *tun) listen(link *link) {
 		// the session id
 		sessionId := msg.Header["Micro-Tunnel-Session"]
 
+		// tunnels identify themselves on connect
+		if mtype == "connect" && len(id) == 0 {
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Tunnel link %s connect without a tunnel id", link.Remote())
+			}
+			return
+		}
+
+		// close links to tunnels which aren't allowed, the messages
+		// relayed for other nodes carry no tunnel id
+		if len(id) > 0 {
+			if !t.allowed(id) {
+				if logger.V(logger.DebugLevel, log) {
+					log.Debugf("Tunnel link %s from %s not allowed", link.Remote(), id)
+				}
+				return
+			}
+			// the tunnels linking to us prove their id on connect
+			if mtype == "connect" && !connected {
+				if err := t.verify(id, msg.Header); err != nil {
+					if logger.V(logger.DebugLevel, log) {
+						log.Debugf("Tunnel link %s from %s refused: %v", link.Remote(), id, err)
+					}
+					return
+				}
+			}
+			link.setNode(id)
+		}
+
 		// if its not connected throw away the link
 		// the first message we process needs to be connect
 		if !connected && mtype != "connect" {
@@ -661,6 +758,8 @@ func (t *tun) listen(link *link) {
 
 			// set to remote node
 			link.id = link.Remote()
+			// set the address advertised by the remote node
+			link.address = msg.Header["Micro-Tunnel-Address"]
 			// set as connected
 			link.connected = true
 			connected = true
@@ -672,6 +771,9 @@ func (t *tun) listen(link *link) {
 			t.links[link.Remote()] = link
 			t.Unlock()
 
+			// replace the relayed link to the node if we have one
+			t.upgrade(link.address, link)
+
 			// send back an announcement of our channels discovery
 			go t.announce("", "", link)
 			// ask for the things on the other wise
@@ -714,6 +816,15 @@ func (t *tun) listen(link *link) {
 				}
 			}
 			// otherwise its a session mapping of sorts
+		case "relay":
+			t.relay(link, msg)
+			continue
+		case "punch":
+			// the other side is dialling us so we dial it at the same time
+			if s, ok := relayed(link); ok {
+				go t.dialDirect(s)
+			}
+			continue
 		case "keepalive":
 			if logger.V(logger.DebugLevel, log) {
 				log.Debugf("Tunnel link %s received keepalive", link.Remote())
@@ -797,6 +908,9 @@ func (t *tun) listen(link *link) {
 			continue
 		}
 
+		// the interval of the key the session message was encrypted with
+		epoch := msg.Header["Micro-Tunnel-Key"]
+
 		// strip tunnel message header
 		for k := range msg.Header {
 			if strings.HasPrefix(k, "Micro-Tunnel") {
@@ -878,6 +992,7 @@ func (t *tun) listen(link *link) {
 			session:  sessionId,
 			mode:     s.mode,
 			data:     tmsg,
+			epoch:    epoch,
 			link:     link.id,
 			loopback: loopback,
 			errChan:  make(chan error, 1),
@@ -892,12 +1007,83 @@ func (t *tun) listen(link *link) {
 	}
 }
 
+// allowed returns true if the tunnel may establish links with us
+func (t *tun) allowed(id string) bool {
+	t.RLock()
+	defer t.RUnlock()
+
+	if len(t.options.Allow) == 0 || id == t.id {
+		return true
+	}
+	_, ok := t.options.Allow[id]
+	return ok
+}
+
+// verify checks the signature of the connect message of a tunnel when links are
+// restricted, the connect must be recent and not replayed
+func (t *tun) verify(id string, header map[string]string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if len(t.options.Allow) == 0 {
+		return nil
+	}
+
+	key := t.options.Allow[id]
+	if id == t.id {
+		key = t.key
+	}
+	if len(key) == 0 {
+		return tunnel.ErrNoKey
+	}
+
+	ts := header["Micro-Tunnel-Time"]
+	nonce := header["Micro-Tunnel-Nonce"]
+	if len(nonce) == 0 || !hmac.Equal([]byte(header["Micro-Tunnel-Auth"]), []byte(sign(key, id, ts, nonce))) {
+		return tunnel.ErrBadSignature
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return tunnel.ErrBadSignature
+	}
+	now := time.Now()
+	if d := now.Sub(time.Unix(unix, 0)); d > ConnectSkew || d < -ConnectSkew {
+		return tunnel.ErrConnectExpired
+	}
+
+	// forget the nonces of the expired connects
+	for n, seen := range t.nonces {
+		if now.Sub(seen) > 2*ConnectSkew {
+			delete(t.nonces, n)
+		}
+	}
+	if _, ok := t.nonces[nonce]; ok {
+		return tunnel.ErrConnectReplayed
+	}
+	t.nonces[nonce] = now
+
+	return nil
+}
+
 func (t *tun) sendMsg(method string, link *link) error {
+	header := map[string]string{
+		"Micro-Tunnel":         method,
+		"Micro-Tunnel-Id":      t.id,
+		"Micro-Tunnel-Address": t.address,
+	}
+
+	// sign the connect so tunnels restricting links can verify our id
+	if method == "connect" && len(t.key) > 0 {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		nonce := uuid.New().String()
+		header["Micro-Tunnel-Time"] = ts
+		header["Micro-Tunnel-Nonce"] = nonce
+		header["Micro-Tunnel-Auth"] = sign(t.key, t.id, ts, nonce)
+	}
+
 	return link.Send(&transport.Message{
-		Header: map[string]string{
-			"Micro-Tunnel":    method,
-			"Micro-Tunnel-Id": t.id,
-		},
+		Header: header,
 	})
 }
 
@@ -917,6 +1103,11 @@ func (t *tun) setupLink(node string) (*link, error) {
 	if logger.V(logger.DebugLevel, log) {
 		log.Debugf("Tunnel connected to %s", node)
 	}
+	return t.connectLink(c)
+}
+
+// connectLink creates a link on the dialled socket and sends the connect message
+func (t *tun) connectLink(c transport.Socket) (*link, error) {
 	// create a new link
 	link := newLink(c)
 
@@ -986,6 +1177,12 @@ func (t *tun) connect() error {
 	// save the listener
 	t.listener = l
 
+	// the address other nodes can reach us at
+	t.address = t.options.Advertise
+	if len(t.address) == 0 {
+		t.address = l.Addr()
+	}
+
 	go func() {
 		// accept inbound connections
 		err := l.Accept(func(sock transport.Socket) {
@@ -2,10 +2,12 @@ package mucp
 
 import (
 	"os"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/micro/go-micro/v3/network/transport"
 	"github.com/micro/go-micro/v3/network/tunnel"
 )
@@ -345,3 +347,193 @@ func TestReconnectTunnel(t *testing.T) {
 	// wait until done
 	wg.Wait()
 }
+
+func TestTunnelKeyRotation(t *testing.T) {
+	tunA := NewTunnel(
+		tunnel.Address("127.0.0.1:9126"),
+		tunnel.Nodes("127.0.0.1:9127"),
+		tunnel.KeyRotation(time.Minute),
+	)
+
+	tunB := NewTunnel(
+		tunnel.Address("127.0.0.1:9127"),
+		tunnel.KeyRotation(time.Minute),
+	)
+
+	if err := tunB.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer tunB.Close()
+
+	if err := tunA.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer tunA.Close()
+
+	wait := make(chan bool)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go testAccept(t, tunB, wait, &wg)
+
+	wg.Add(1)
+	go testSend(t, tunA, wait, &wg)
+
+	wg.Wait()
+
+	// keys of intervals other than the adjacent ones are rejected
+	s := &session{key: []byte("key"), rotate: time.Minute}
+	now := time.Now().UnixNano() / int64(time.Minute)
+	for epoch, valid := range map[int64]bool{now - 2: false, now - 1: true, now: true, now + 1: true, now + 2: false} {
+		if v := s.validEpoch(strconv.FormatInt(epoch, 10)); v != valid {
+			t.Fatalf("Expected interval %d valid %t got %t", epoch-now, valid, v)
+		}
+	}
+	if s.validEpoch("") {
+		t.Fatal("Expected messages without a key interval to be rejected")
+	}
+}
+
+func TestTunnelVerify(t *testing.T) {
+	tun := NewTunnel(
+		tunnel.Id("tun-b"),
+		tunnel.Allow(map[string]string{"tun-a": "key-a"}),
+	)
+
+	connect := func(id, key string, at time.Time) map[string]string {
+		ts := strconv.FormatInt(at.Unix(), 10)
+		nonce := uuid.New().String()
+		return map[string]string{
+			"Micro-Tunnel-Time":  ts,
+			"Micro-Tunnel-Nonce": nonce,
+			"Micro-Tunnel-Auth":  sign(key, id, ts, nonce),
+		}
+	}
+
+	header := connect("tun-a", "key-a", time.Now())
+	if err := tun.verify("tun-a", header); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if err := tun.verify("tun-a", header); err != tunnel.ErrConnectReplayed {
+		t.Fatalf("Expected %v got %v", tunnel.ErrConnectReplayed, err)
+	}
+	if err := tun.verify("tun-a", connect("tun-a", "key-c", time.Now())); err != tunnel.ErrBadSignature {
+		t.Fatalf("Expected %v got %v", tunnel.ErrBadSignature, err)
+	}
+	if err := tun.verify("tun-c", connect("tun-c", "key-a", time.Now())); err != tunnel.ErrNoKey {
+		t.Fatalf("Expected %v got %v", tunnel.ErrNoKey, err)
+	}
+	if err := tun.verify("tun-a", connect("tun-a", "key-a", time.Now().Add(-2*ConnectSkew))); err != tunnel.ErrConnectExpired {
+		t.Fatalf("Expected %v got %v", tunnel.ErrConnectExpired, err)
+	}
+}
+
+func TestTunnelAllow(t *testing.T) {
+	ReconnectTime = 200 * time.Millisecond
+
+	tunA := NewTunnel(
+		tunnel.Id("tun-a"),
+		tunnel.Address("127.0.0.1:9128"),
+		tunnel.Nodes("127.0.0.1:9129"),
+		tunnel.Key("key-a"),
+	)
+
+	tunB := NewTunnel(
+		tunnel.Id("tun-b"),
+		tunnel.Address("127.0.0.1:9129"),
+		tunnel.Allow(map[string]string{"tun-c": "key-c"}),
+	)
+
+	if err := tunB.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer tunB.Close()
+
+	if err := tunA.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer tunA.Close()
+
+	time.Sleep(500 * time.Millisecond)
+
+	// tun-a isn't allowed to link
+	if links := tunB.Links(); len(links) != 0 {
+		t.Fatalf("Expected no links got %d", len(links))
+	}
+
+	// allow it with the wrong key
+	tunB.Init(tunnel.Allow(map[string]string{"tun-a": "key-c", "tun-c": "key-c"}))
+	time.Sleep(time.Second)
+
+	if links := tunB.Links(); len(links) != 0 {
+		t.Fatalf("Expected no links with the wrong key got %d", len(links))
+	}
+
+	// allow it and wait for the reconnect
+	tunB.Init(tunnel.Allow(map[string]string{"tun-a": "key-a", "tun-c": "key-c"}))
+	time.Sleep(time.Second)
+
+	links := tunB.Links()
+	if len(links) != 1 {
+		t.Fatalf("Expected 1 link got %d", len(links))
+	}
+
+	stats := links[0].Stats()
+	if stats.Node != "tun-a" {
+		t.Fatalf("Expected link to tun-a got %s", stats.Node)
+	}
+	if stats.Received == 0 || stats.BytesReceived == 0 {
+		t.Fatalf("Expected traffic on the link got %+v", stats)
+	}
+}
+
+func TestTunnelConnectWithoutId(t *testing.T) {
+	tn := NewTunnel(tunnel.Address("127.0.0.1:9130"))
+	if err := tn.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer tn.Close()
+
+	c, err := tn.options.Transport.Dial("127.0.0.1:9130")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Send(&transport.Message{
+		Header: map[string]string{"Micro-Tunnel": "connect"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if links := tn.Links(); len(links) != 0 {
+		t.Fatalf("Expected the link without a tunnel id to be rejected got %d links", len(links))
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	token := []byte("token")
+
+	key, err := deriveKey(token, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(key) != string(token) {
+		t.Fatal("Expected the token as the key when not rotating")
+	}
+
+	k1, err := deriveKey(token, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2, err := deriveKey(token, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(k1) != 32 || string(k1) == string(k2) || string(k1) == string(token) {
+		t.Fatal("Expected a distinct key per interval")
+	}
+}
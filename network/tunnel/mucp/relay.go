@@ -0,0 +1,361 @@
+package mucp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/network/transport"
+)
+
+var (
+	// PunchTime defines time interval we try to upgrade relayed links to direct links
+	PunchTime = time.Minute
+
+	// ErrNoRelay is returned when there's no relay to reach a node through
+	ErrNoRelay = errors.New("no relay connected")
+)
+
+// relaySocket is the socket of a link to a node reached through a relay. The messages
+// are wrapped in relay messages addressed to the node which the relay forwards.
+type relaySocket struct {
+	// via is the link to the relay
+	via *link
+	// local and remote are the advertised addresses of the nodes
+	local  string
+	remote string
+
+	recv   chan *transport.Message
+	closed chan bool
+	once   sync.Once
+
+	sync.RWMutex
+	// observed is the address of the remote node as seen by the relay
+	observed string
+	// seen is the last time we received a message from the remote node
+	seen time.Time
+	// punched is the last time we tried to upgrade to a direct link
+	punched time.Time
+}
+
+func newRelaySocket(via *link, local, remote string) *relaySocket {
+	return &relaySocket{
+		via:     via,
+		local:   local,
+		remote:  remote,
+		recv:    make(chan *transport.Message, 128),
+		closed:  make(chan bool),
+		seen:    time.Now(),
+		punched: time.Now(),
+	}
+}
+
+// relayed returns the relay socket of a relayed link
+func relayed(l *link) (*relaySocket, bool) {
+	s, ok := l.Socket.(*relaySocket)
+	return s, ok
+}
+
+func (s *relaySocket) wrap(m *transport.Message) (*transport.Message, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return &transport.Message{
+		Header: map[string]string{
+			"Micro-Tunnel":           "relay",
+			"Micro-Tunnel-Relay-Src": s.local,
+			"Micro-Tunnel-Relay-Dst": s.remote,
+		},
+		Body: b,
+	}, nil
+}
+
+// deliver passes a message received through the relay to the link
+func (s *relaySocket) deliver(m *transport.Message, observed string) {
+	s.Lock()
+	s.seen = time.Now()
+	if len(observed) > 0 {
+		s.observed = observed
+	}
+	s.Unlock()
+
+	select {
+	case s.recv <- m:
+	case <-s.closed:
+	}
+}
+
+// candidates returns the addresses to try to dial the remote node directly at
+func (s *relaySocket) candidates() []string {
+	s.RLock()
+	defer s.RUnlock()
+
+	addrs := []string{s.remote}
+	if len(s.observed) > 0 && s.observed != s.remote {
+		addrs = append(addrs, s.observed)
+	}
+	return addrs
+}
+
+func (s *relaySocket) lastSeen() time.Time {
+	s.RLock()
+	defer s.RUnlock()
+	return s.seen
+}
+
+// punch returns true and marks the time if it's time to try to upgrade the link
+func (s *relaySocket) punch() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if time.Since(s.punched) < PunchTime {
+		return false
+	}
+	s.punched = time.Now()
+	return true
+}
+
+func (s *relaySocket) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *relaySocket) Recv(m *transport.Message) error {
+	select {
+	case msg := <-s.recv:
+		*m = *msg
+		return nil
+	case <-s.closed:
+		return io.EOF
+	}
+}
+
+func (s *relaySocket) Send(m *transport.Message) error {
+	if s.isClosed() {
+		return io.EOF
+	}
+	msg, err := s.wrap(m)
+	if err != nil {
+		return err
+	}
+	return s.via.Send(msg)
+}
+
+func (s *relaySocket) Close() error {
+	s.once.Do(func() {
+		// tell the remote node to close its side of the link
+		if msg, err := s.wrap(&transport.Message{
+			Header: map[string]string{"Micro-Tunnel": "close"},
+		}); err == nil {
+			go s.via.Send(msg)
+		}
+		close(s.closed)
+	})
+	return nil
+}
+
+func (s *relaySocket) Local() string {
+	return s.via.Local()
+}
+
+func (s *relaySocket) Remote() string {
+	return s.remote
+}
+
+func hasNode(nodes []string, node string) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+// relayLink returns a connected link to one of the relays
+func (t *tun) relayLink() *link {
+	t.RLock()
+	defer t.RUnlock()
+
+	for _, r := range t.options.Relays {
+		l, ok := t.links[r]
+		if !ok || l.State() != "connected" {
+			continue
+		}
+		if _, ok := relayed(l); ok {
+			continue
+		}
+		return l
+	}
+	return nil
+}
+
+// setupRelayLink connects to the node through a relay
+func (t *tun) setupRelayLink(node string) (*link, error) {
+	via := t.relayLink()
+	if via == nil {
+		return nil, ErrNoRelay
+	}
+	if logger.V(logger.DebugLevel, log) {
+		log.Debugf("Tunnel setting up link to %s relayed by %s", node, via.Remote())
+	}
+
+	s := newRelaySocket(via, t.address, node)
+
+	t.Lock()
+	t.relayed[node] = s
+	t.Unlock()
+
+	return t.connectLink(s)
+}
+
+// relayTo returns the direct link to the node with the advertised address
+func (t *tun) relayTo(address string) *link {
+	t.RLock()
+	defer t.RUnlock()
+
+	for node, l := range t.links {
+		if _, ok := relayed(l); ok || l.Loopback() {
+			continue
+		}
+		l.RLock()
+		match := node == address || l.address == address
+		l.RUnlock()
+		if match {
+			return l
+		}
+	}
+	return nil
+}
+
+// relay processes a relay message received on the link. The messages addressed
+// to us are passed to the relayed link of the sender, the others are forwarded
+// to the destination if we're a relay.
+func (t *tun) relay(link *link, msg *transport.Message) {
+	src := msg.Header["Micro-Tunnel-Relay-Src"]
+	dst := msg.Header["Micro-Tunnel-Relay-Dst"]
+
+	if dst != t.address {
+		if !t.options.Relay {
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Tunnel dropping message from %s to %s: not a relay", src, dst)
+			}
+			return
+		}
+
+		next := t.relayTo(dst)
+		if next == nil {
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Tunnel dropping message from %s to %s: no link", src, dst)
+			}
+			return
+		}
+
+		// pass on the address we see the sender at to help it get a direct link
+		msg.Header["Micro-Tunnel-Relay-Observed"] = link.Remote()
+		if err := next.Send(msg); err != nil {
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Tunnel failed to relay message from %s to %s: %v", src, dst, err)
+			}
+		}
+		return
+	}
+
+	m := new(transport.Message)
+	if err := json.Unmarshal(msg.Body, m); err != nil {
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("Tunnel failed to unmarshal relayed message from %s: %v", src, err)
+		}
+		return
+	}
+
+	t.Lock()
+	s, ok := t.relayed[src]
+	if !ok || s.isClosed() {
+		// the sender dialled us through the relay so we accept the link
+		s = newRelaySocket(link, t.address, src)
+		t.relayed[src] = s
+
+		l := newLink(s)
+		go t.manageLink(l)
+		go t.listen(l)
+	}
+	t.Unlock()
+
+	s.deliver(m, msg.Header["Micro-Tunnel-Relay-Observed"])
+}
+
+// dialDirect tries to dial the node of the relayed link directly and replaces the relayed
+// link if it succeeds. Both nodes dial each other at the same time to open the path through
+// NAT which succeeds where the NAT and the transport allow it.
+func (t *tun) dialDirect(s *relaySocket) {
+	for _, addr := range s.candidates() {
+		direct, err := t.setupLink(addr)
+		if err != nil {
+			continue
+		}
+
+		if !t.upgrade(s.remote, direct) {
+			t.Lock()
+			if _, ok := t.links[s.remote]; !ok {
+				t.links[s.remote] = direct
+				direct = nil
+			}
+			t.Unlock()
+
+			// we already have a direct link
+			if direct != nil {
+				direct.Close()
+			}
+		}
+		return
+	}
+}
+
+// punch asks the node of the relayed link to dial us while we dial it
+func (t *tun) punch(l *link, s *relaySocket) {
+	if err := t.sendMsg("punch", l); err != nil {
+		return
+	}
+	t.dialDirect(s)
+}
+
+// upgrade replaces the relayed link to the node with the direct link, it returns false
+// if there's no relayed link to the node
+func (t *tun) upgrade(node string, direct *link) bool {
+	if _, ok := relayed(direct); ok || len(node) == 0 {
+		return false
+	}
+
+	t.Lock()
+	old, ok := t.links[node]
+	if !ok {
+		t.Unlock()
+		return false
+	}
+	if _, ok := relayed(old); !ok {
+		t.Unlock()
+		return false
+	}
+
+	t.links[node] = direct
+	// accepted links are saved by their remote address
+	if remote := direct.Remote(); remote != node && t.links[remote] == direct {
+		delete(t.links, remote)
+	}
+	t.Unlock()
+
+	if logger.V(logger.DebugLevel, log) {
+		log.Debugf("Tunnel upgraded the relayed link to %s to a direct link", node)
+	}
+
+	old.Close()
+	return true
+}
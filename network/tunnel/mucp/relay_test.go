@@ -0,0 +1,164 @@
+package mucp
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/network/transport"
+	"github.com/micro/go-micro/v3/network/transport/grpc"
+	"github.com/micro/go-micro/v3/network/tunnel"
+)
+
+// natTransport fails to dial the blocked addresses
+type natTransport struct {
+	transport.Transport
+
+	sync.RWMutex
+	blocked map[string]bool
+}
+
+func (n *natTransport) Dial(addr string, opts ...transport.DialOption) (transport.Client, error) {
+	n.RLock()
+	blocked := n.blocked[addr]
+	n.RUnlock()
+
+	if blocked {
+		return nil, errors.New("blocked")
+	}
+	return n.Transport.Dial(addr, opts...)
+}
+
+func (n *natTransport) unblock(addr string) {
+	n.Lock()
+	delete(n.blocked, addr)
+	n.Unlock()
+}
+
+func relayedTo(tun *tun, node string) (bool, bool) {
+	tun.RLock()
+	defer tun.RUnlock()
+
+	l, ok := tun.links[node]
+	if !ok {
+		return false, false
+	}
+	_, isRelayed := relayed(l)
+	return isRelayed, true
+}
+
+func TestRelayTunnel(t *testing.T) {
+	ReconnectTime = 200 * time.Millisecond
+	PunchTime = 100 * time.Millisecond
+	defer func() {
+		PunchTime = time.Minute
+	}()
+
+	// the nodes can't dial each other
+	natA := &natTransport{
+		Transport: grpc.NewTransport(),
+		blocked:   map[string]bool{"127.0.0.1:9111": true},
+	}
+	natB := &natTransport{
+		Transport: grpc.NewTransport(),
+		blocked:   map[string]bool{"127.0.0.1:9112": true},
+	}
+
+	// the relay
+	tunR := NewTunnel(
+		tunnel.Address("127.0.0.1:9110"),
+		tunnel.Relay(true),
+	)
+
+	tunB := NewTunnel(
+		tunnel.Address("127.0.0.1:9111"),
+		tunnel.Nodes("127.0.0.1:9110"),
+		tunnel.Transport(natB),
+	)
+
+	tunA := NewTunnel(
+		tunnel.Address("127.0.0.1:9112"),
+		tunnel.Nodes("127.0.0.1:9111"),
+		tunnel.Relays("127.0.0.1:9110"),
+		tunnel.Transport(natA),
+	)
+
+	for _, tun := range []*tun{tunR, tunB, tunA} {
+		if err := tun.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer tun.Close()
+	}
+
+	// wait for the relayed link
+	var isRelayed bool
+	for i := 0; i < 50 && !isRelayed; i++ {
+		time.Sleep(100 * time.Millisecond)
+		isRelayed, _ = relayedTo(tunA, "127.0.0.1:9111")
+	}
+	if !isRelayed {
+		t.Fatal("Expected a relayed link to 127.0.0.1:9111")
+	}
+
+	tl, err := tunB.Listen("test-relay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	recv := make(chan *transport.Message, 1)
+	errs := make(chan error, 1)
+
+	go func() {
+		c, err := tl.Accept()
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer c.Close()
+
+		m := new(transport.Message)
+		if err := c.Recv(m); err != nil {
+			errs <- err
+			return
+		}
+		recv <- m
+	}()
+
+	c, err := tunA.Dial("test-relay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Send(&transport.Message{Header: map[string]string{"test": "send"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case m := <-recv:
+		if v := m.Header["test"]; v != "send" {
+			t.Fatalf("Expected message send got %s", v)
+		}
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the relayed message")
+	}
+
+	// the direct path opens up
+	natA.unblock("127.0.0.1:9111")
+	natB.unblock("127.0.0.1:9112")
+
+	for i := 0; i < 50 && isRelayed; i++ {
+		time.Sleep(100 * time.Millisecond)
+		isRelayed, _ = relayedTo(tunA, "127.0.0.1:9111")
+	}
+	if isRelayed {
+		t.Fatal("Expected the relayed link to be upgraded to a direct link")
+	}
+	if _, ok := relayedTo(tunA, "127.0.0.1:9111"); !ok {
+		t.Fatal("Expected a direct link to 127.0.0.1:9111")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/cipher"
 	"encoding/base32"
 	"io"
+	"strconv"
 	"sync"
 	"time"
 
@@ -52,6 +53,10 @@ type session struct {
 	errChan chan error
 	// key for session encryption
 	key []byte
+	// interval the key is rotated at
+	rotate time.Duration
+	// the key interval of the cipher
+	epoch string
 	// cipher for session
 	gcm cipher.AEAD
 	sync.RWMutex
@@ -77,6 +82,8 @@ type message struct {
 	link string
 	// transport data
 	data *transport.Message
+	// the key interval the data was encrypted in
+	epoch string
 	// the error channel
 	errChan chan error
 }
@@ -266,7 +273,7 @@ func (s *session) Discover() error {
 	}
 
 	// wait for announce
-	_, err := s.waitFor("announce", after())
+	msg, err := s.waitFor("announce", after())
 	if err != nil {
 		return err
 	}
@@ -274,6 +281,11 @@ func (s *session) Discover() error {
 	// set discovered
 	s.discovered = true
 
+	// open the session on the link that announced the channel
+	if len(s.link) == 0 {
+		s.link = msg.link
+	}
+
 	return nil
 }
 
@@ -335,23 +347,66 @@ func (s *session) Announce() error {
 	return s.sendMsg(msg)
 }
 
-// Send is used to send a message
-func (s *session) Send(m *transport.Message) error {
-	var err error
+// keyEpoch returns the current key interval, blank when the keys aren't rotated
+func (s *session) keyEpoch() string {
+	if s.rotate <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(time.Now().UnixNano()/int64(s.rotate), 10)
+}
+
+// validEpoch returns true if the key interval is current, the previous or the next
+// interval are accepted for messages in flight and clock skew
+func (s *session) validEpoch(epoch string) bool {
+	if s.rotate <= 0 {
+		return true
+	}
+	e, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return false
+	}
+	now := time.Now().UnixNano() / int64(s.rotate)
+	return e >= now-1 && e <= now+1
+}
 
+// cipher returns the cipher of the key for the interval
+func (s *session) cipher(epoch string) (cipher.AEAD, error) {
 	s.RLock()
 	gcm := s.gcm
+	current := s.epoch
 	s.RUnlock()
 
-	if gcm == nil {
-		gcm, err = newCipher(s.key)
-		if err != nil {
-			return err
-		}
-		s.Lock()
-		s.gcm = gcm
-		s.Unlock()
+	if gcm != nil && current == epoch {
+		return gcm, nil
+	}
+
+	key, err := deriveKey(s.key, epoch)
+	if err != nil {
+		return nil, err
 	}
+
+	gcm, err = newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Lock()
+	s.gcm = gcm
+	s.epoch = epoch
+	s.Unlock()
+
+	return gcm, nil
+}
+
+// Send is used to send a message
+func (s *session) Send(m *transport.Message) error {
+	epoch := s.keyEpoch()
+
+	gcm, err := s.cipher(epoch)
+	if err != nil {
+		return err
+	}
+
 	// encrypt the transport message payload
 	body, err := Encrypt(gcm, m.Body)
 	if err != nil {
@@ -368,7 +423,7 @@ func (s *session) Send(m *transport.Message) error {
 	// encrypt all the headers
 	for k, v := range m.Header {
 		// encrypt the transport message payload
-		val, err := Encrypt(s.gcm, []byte(v))
+		val, err := Encrypt(gcm, []byte(v))
 		if err != nil {
 			log.Debugf("failed to encrypt message header %s: %v", k, err)
 			return err
@@ -377,6 +432,11 @@ func (s *session) Send(m *transport.Message) error {
 		data.Header[k] = base32.StdEncoding.EncodeToString(val)
 	}
 
+	// tell the other side which key to decrypt with
+	if len(epoch) > 0 {
+		data.Header["Micro-Tunnel-Key"] = epoch
+	}
+
 	// create a new message
 	msg := s.newMessage("session")
 	// set the data
@@ -419,7 +479,20 @@ func (s *session) Recv(m *transport.Message) error {
 		log.Tracef("Received from recv backlog: %v", msg)
 	}
 
-	gcm, err := newCipher([]byte(s.token + s.channel + msg.session))
+	// reject messages encrypted with rotated keys
+	if !s.validEpoch(msg.epoch) {
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("message key interval %q expired", msg.epoch)
+		}
+		return tunnel.ErrKeyExpired
+	}
+
+	key, err := deriveKey([]byte(s.token+s.channel+msg.session), msg.epoch)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := newCipher(key)
 	if err != nil {
 		if logger.V(logger.ErrorLevel, log) {
 			log.Errorf("unable to create cipher: %v", err)
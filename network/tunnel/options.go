@@ -29,6 +29,18 @@ type Options struct {
 	Token string
 	// Transport listens to incoming connections
 	Transport transport.Transport
+	// Advertise is the address the tunnel is known by, the listen address if not set
+	Advertise string
+	// Relay forwards the messages of nodes which can't connect directly
+	Relay bool
+	// Relays are the nodes used to reach the nodes which can't be dialled e.g behind NAT
+	Relays []string
+	// Allow maps the ids of the tunnels which may establish links to their keys, all when empty
+	Allow map[string]string
+	// Key is the key the tunnel proves its id with when linking to other tunnels
+	Key string
+	// KeyRotation is the interval the session keys are rotated at, never when zero
+	KeyRotation time.Duration
 }
 
 type DialOption func(*DialOptions)
@@ -88,6 +100,58 @@ func Transport(t transport.Transport) Option {
 	}
 }
 
+// Advertise sets the address the tunnel is known by, it identifies the node
+// to relays so it should be unique when the node is behind NAT
+func Advertise(a string) Option {
+	return func(o *Options) {
+		o.Advertise = a
+	}
+}
+
+// Relay forwards the messages between the links of nodes which can't connect directly
+func Relay(b bool) Option {
+	return func(o *Options) {
+		o.Relay = b
+	}
+}
+
+// Relays sets the nodes to relay through when a node can't be dialled e.g because it's
+// behind NAT. The relayed links are upgraded to direct links when they can be established.
+func Relays(r ...string) Option {
+	return func(o *Options) {
+		o.Relays = r
+	}
+}
+
+// Allow sets the tunnels which may establish links as a map of their ids to their
+// keys. A tunnel proves its id on connect by signing it with its key, see Key, and
+// the links of any other tunnel are closed. All tunnels may link when none are set.
+// Only the links accepted are verified, the nodes we dial are trusted.
+func Allow(peers map[string]string) Option {
+	return func(o *Options) {
+		o.Allow = peers
+	}
+}
+
+// Key sets the key the tunnel signs its id with on connect. It must match the
+// key the other tunnels allow the id with.
+func Key(k string) Option {
+	return func(o *Options) {
+		o.Key = k
+	}
+}
+
+// KeyRotation sets the interval the session keys are rotated at. The keys are derived
+// from the token and the current interval so the nodes' clocks need to be roughly in
+// sync, messages encrypted in the previous or next interval are still accepted. Each
+// key only encrypts the messages of its interval, but every key can be derived from
+// the token so it doesn't protect the past messages should the token leak.
+func KeyRotation(d time.Duration) Option {
+	return func(o *Options) {
+		o.KeyRotation = d
+	}
+}
+
 // Listen options
 func ListenMode(m Mode) ListenOption {
 	return func(o *ListenOptions) {
@@ -36,6 +36,16 @@ var (
 	ErrReadTimeout = errors.New("read timeout")
 	// ErrDecryptingData is for when theres a nonce error
 	ErrDecryptingData = errors.New("error decrypting data")
+	// ErrKeyExpired is returned when a message was encrypted with a rotated key
+	ErrKeyExpired = errors.New("key expired")
+	// ErrNoKey is returned when a tunnel connects with an id it has no key for
+	ErrNoKey = errors.New("no key for tunnel id")
+	// ErrBadSignature is returned when a connect isn't signed with the key of its id
+	ErrBadSignature = errors.New("bad connect signature")
+	// ErrConnectExpired is returned when a connect is too old or too far in the future
+	ErrConnectExpired = errors.New("connect expired")
+	// ErrConnectReplayed is returned when the nonce of a connect was seen before
+	ErrConnectReplayed = errors.New("connect replayed")
 )
 
 // Mode of the session
@@ -78,10 +88,28 @@ type Link interface {
 	Loopback() bool
 	// State of the link: connected/closed/error
 	State() string
+	// Stats returns the traffic counters of the link
+	Stats() LinkStats
 	// honours transport socket
 	transport.Socket
 }
 
+// LinkStats are the traffic counters of a link
+type LinkStats struct {
+	// Node is the id of the tunnel on the other side of the link
+	Node string
+	// Since is when the link was established
+	Since time.Time
+	// Sent is the number of messages sent
+	Sent uint64
+	// Received is the number of messages received
+	Received uint64
+	// BytesSent is the size of the messages sent including headers
+	BytesSent uint64
+	// BytesReceived is the size of the messages received including headers
+	BytesReceived uint64
+}
+
 // The listener provides similar constructs to the transport.Listener
 type Listener interface {
 	Accept() (Session, error)
@@ -0,0 +1,266 @@
+// Package egress is a sidecar proxy for the outbound requests of applications which don't
+// use go-micro. Applications send http requests for http://service/path through the proxy,
+// e.g by setting HTTP_PROXY, and the proxy resolves the service through the registry,
+// originates mutual TLS with the workload certificate and retries failed requests.
+package egress
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/network/transport/mtls"
+	"github.com/micro/go-micro/v3/router"
+	"github.com/micro/go-micro/v3/router/registry"
+	"github.com/micro/go-micro/v3/selector/roundrobin"
+)
+
+var (
+	// ErrNotFound is returned when the host isn't a service
+	ErrNotFound = errors.New("service not found")
+	// ErrWrongIdentity is returned when the backend certificate isn't for the service
+	ErrWrongIdentity = errors.New("backend certificate not issued for the service")
+)
+
+// Proxy is a http forward proxy resolving hosts to services
+type Proxy struct {
+	options Options
+	proxy   *httputil.ReverseProxy
+
+	sync.RWMutex
+	// transports of the services, the tls config verifies the service
+	transports map[string]*http.Transport
+	// direct is used for hosts which aren't services
+	direct *http.Transport
+}
+
+func newTransport(timeout time.Duration) *http.Transport {
+	return &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConnsPerHost:   16,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+	}
+}
+
+// transport returns the transport for the backends of the service
+func (p *Proxy) transport(service string) *http.Transport {
+	p.RLock()
+	t, ok := p.transports[service]
+	p.RUnlock()
+	if ok {
+		return t
+	}
+
+	t = newTransport(p.options.Timeout)
+
+	if m := p.options.Certificates; m != nil {
+		config := m.TLSConfig()
+		verify := config.VerifyPeerCertificate
+
+		// the backend must present a certificate for the service we resolved
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, chains [][]*x509.Certificate) error {
+			if err := verify(rawCerts, chains); err != nil {
+				return err
+			}
+			cert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+			for _, name := range p.options.Identity(cert) {
+				if name == service {
+					return nil
+				}
+			}
+			return ErrWrongIdentity
+		}
+
+		t.TLSClientConfig = config
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if c, ok := p.transports[service]; ok {
+		return c
+	}
+	p.transports[service] = t
+	return t
+}
+
+// retry returns true if the request can be retried after the response
+func retry(req *http.Request, rsp *http.Response) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		// only idempotent requests are retried after being processed
+		return false
+	}
+
+	switch rsp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// RoundTrip sends the request to a backend of the service, retrying on other backends
+func (p *Proxy) RoundTrip(req *http.Request) (*http.Response, error) {
+	service := req.URL.Hostname()
+
+	routes, err := p.options.Router.Lookup(service, router.LookupNetwork("*"))
+	if err == router.ErrRouteNotFound || (err == nil && len(routes) == 0) {
+		if p.options.Passthrough {
+			return p.direct.RoundTrip(req)
+		}
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	addrs := make([]string, 0, len(routes))
+	for _, r := range routes {
+		if !seen[r.Address] {
+			seen[r.Address] = true
+			addrs = append(addrs, r.Address)
+		}
+	}
+
+	next, err := p.options.Selector.Select(addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	// buffer the body to send it again on retries
+	var body []byte
+	if req.Body != nil && p.options.Retries > 0 {
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	scheme := "http"
+	if p.options.Certificates != nil {
+		scheme = "https"
+	}
+
+	t := p.transport(service)
+
+	for i := 0; ; i++ {
+		addr := next()
+
+		out := req.Clone(req.Context())
+		out.URL.Scheme = scheme
+		out.URL.Host = addr
+		if body != nil {
+			out.Body = ioutil.NopCloser(bytes.NewReader(body))
+			out.ContentLength = int64(len(body))
+		}
+
+		rsp, err := t.RoundTrip(out)
+		p.options.Selector.Record(addr, err)
+
+		if i >= p.options.Retries {
+			return rsp, err
+		}
+
+		if err == nil && !retry(req, rsp) {
+			return rsp, nil
+		}
+
+		if err == nil {
+			rsp.Body.Close()
+		}
+
+		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+			logger.Debugf("Egress retrying request for %s after failure of %s: %v", service, addr, err)
+		}
+	}
+}
+
+// ServeHTTP proxies the request of the application
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// we originate tls so the application can't tunnel it
+	if r.Method == http.MethodConnect {
+		http.Error(w, "CONNECT not supported, send plain http requests", http.StatusMethodNotAllowed)
+		return
+	}
+	p.proxy.ServeHTTP(w, r)
+}
+
+// Close closes the idle connections to the backends
+func (p *Proxy) Close() error {
+	p.RLock()
+	defer p.RUnlock()
+
+	for _, t := range p.transports {
+		t.CloseIdleConnections()
+	}
+	p.direct.CloseIdleConnections()
+	return nil
+}
+
+// NewProxy returns an egress proxy
+func NewProxy(opts ...Option) *Proxy {
+	options := Options{
+		Retries:  DefaultRetries,
+		Timeout:  DefaultTimeout,
+		Identity: mtls.DefaultIdentity,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.Router == nil {
+		options.Router = registry.NewRouter()
+	}
+	if options.Selector == nil {
+		options.Selector = roundrobin.NewSelector()
+	}
+
+	p := &Proxy{
+		options:    options,
+		transports: make(map[string]*http.Transport),
+		direct:     newTransport(options.Timeout),
+	}
+
+	p.proxy = &httputil.ReverseProxy{
+		Director: func(r *http.Request) {
+			// requests intercepted transparently only have the host header
+			if len(r.URL.Host) == 0 {
+				r.URL.Host = r.Host
+			}
+			if len(r.URL.Scheme) == 0 {
+				r.URL.Scheme = "http"
+			}
+		},
+		Transport: p,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Egress request for %s failed: %v", r.URL.Host, err)
+			}
+
+			code := http.StatusBadGateway
+			if err == ErrNotFound {
+				code = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), code)
+		},
+	}
+
+	return p
+}
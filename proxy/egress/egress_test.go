@@ -0,0 +1,131 @@
+package egress
+
+import (
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/network/transport/mtls"
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/registry/memory"
+	"github.com/micro/go-micro/v3/router"
+	rr "github.com/micro/go-micro/v3/router/registry"
+	"github.com/micro/go-micro/v3/util/pki"
+)
+
+func newManager(t *testing.T, cert, key []byte, name string) *mtls.Manager {
+	m, err := mtls.NewManager(mtls.NewCA(cert, key, time.Hour), mtls.Name(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestEgress(t *testing.T) {
+	pub, priv, err := pki.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, key, err := pki.CA(
+		pki.Subject(pkix.Name{CommonName: "micro"}),
+		pki.KeyPair(pub, priv),
+		pki.SerialNumber(big.NewInt(1)),
+		pki.NotBefore(time.Now().Add(-time.Minute)),
+		pki.NotAfter(time.Now().Add(time.Hour)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newManager(t, cert, key, "greeter")
+	defer server.Close()
+	client := newManager(t, cert, key, "legacy")
+	defer client.Close()
+
+	// the backend requires the certificate of a service
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello " + r.TLS.PeerCertificates[0].Subject.CommonName + " " + r.URL.Path))
+	}))
+	backend.Listener = tls.NewListener(backend.Listener, server.TLSConfig())
+	backend.Start()
+	defer backend.Close()
+
+	// a backend which is down
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := l.Addr().String()
+	l.Close()
+
+	reg := memory.NewRegistry()
+	reg.Register(&registry.Service{
+		Name:    "greeter",
+		Version: "latest",
+		Nodes: []*registry.Node{
+			{Id: "greeter-1", Address: backend.Listener.Addr().String()},
+			{Id: "greeter-2", Address: down},
+		},
+	})
+	// the backend doesn't have a certificate for this service
+	reg.Register(&registry.Service{
+		Name:    "imposter",
+		Version: "latest",
+		Nodes:   []*registry.Node{{Id: "imposter-1", Address: backend.Listener.Addr().String()}},
+	})
+
+	p := NewProxy(
+		WithRouter(rr.NewRouter(router.Registry(reg))),
+		WithCertificates(client),
+	)
+	defer p.Close()
+
+	egress := httptest.NewServer(p)
+	defer egress.Close()
+
+	u, err := url.Parse(egress.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the application sends requests through the proxy
+	app := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(u)}}
+
+	testData := []struct {
+		url  string
+		code int
+		body string
+	}{
+		// retried on the backend which is up
+		{"http://greeter/foo", 200, "hello legacy /foo"},
+		{"http://greeter/bar", 200, "hello legacy /bar"},
+		{"http://greeter/baz", 200, "hello legacy /baz"},
+		{"http://imposter/foo", 502, ""},
+		{"http://unknown/foo", 404, ""},
+	}
+
+	for _, d := range testData {
+		rsp, err := app.Get(d.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(rsp.Body)
+		rsp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rsp.StatusCode != d.code {
+			t.Fatalf("Expected %s status %d got %d: %s", d.url, d.code, rsp.StatusCode, b)
+		}
+		if len(d.body) > 0 && string(b) != d.body {
+			t.Fatalf("Expected %s body %s got %s", d.url, d.body, b)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package egress
+
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/micro/go-micro/v3/network/transport/mtls"
+	"github.com/micro/go-micro/v3/router"
+	"github.com/micro/go-micro/v3/selector"
+)
+
+var (
+	// DefaultRetries is the number of times a failed request is retried
+	DefaultRetries = 1
+	// DefaultTimeout is how long to wait for the response of a backend
+	DefaultTimeout = time.Second * 30
+)
+
+type Options struct {
+	// Router resolves the services to backends
+	Router router.Router
+	// Selector picks the backend of a service
+	Selector selector.Selector
+	// Certificates originate mutual TLS to the backends, plain http if nil
+	Certificates *mtls.Manager
+	// Identity returns the service names of a backend certificate
+	Identity func(*x509.Certificate) []string
+	// Retries is the number of times a failed request is retried on another backend
+	Retries int
+	// Timeout is how long to wait for the response headers of a backend
+	Timeout time.Duration
+	// Passthrough sends requests for unknown hosts directly rather than failing
+	Passthrough bool
+}
+
+type Option func(o *Options)
+
+// WithRouter sets the router used to resolve services
+func WithRouter(r router.Router) Option {
+	return func(o *Options) {
+		o.Router = r
+	}
+}
+
+// WithSelector sets the selector used to pick a backend
+func WithSelector(s selector.Selector) Option {
+	return func(o *Options) {
+		o.Selector = s
+	}
+}
+
+// WithCertificates originates mutual TLS with the workload certificate of the manager.
+// The backend certificate must be issued for the service requested.
+func WithCertificates(m *mtls.Manager) Option {
+	return func(o *Options) {
+		o.Certificates = m
+	}
+}
+
+// WithIdentity sets the function mapping the SANs of a backend certificate to service names
+func WithIdentity(fn func(*x509.Certificate) []string) Option {
+	return func(o *Options) {
+		o.Identity = fn
+	}
+}
+
+// WithRetries sets the number of retries of a failed request
+func WithRetries(n int) Option {
+	return func(o *Options) {
+		o.Retries = n
+	}
+}
+
+// WithTimeout sets how long to wait for the response of a backend
+func WithTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
+
+// WithPassthrough sends the requests for hosts which aren't services directly
+func WithPassthrough(b bool) Option {
+	return func(o *Options) {
+		o.Passthrough = b
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/micro/go-micro/v3/proxy"
 	"github.com/micro/go-micro/v3/server"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 // Proxy will transparently proxy requests to an endpoint.
@@ -28,6 +29,9 @@ type Proxy struct {
 
 	// Endpoint to route all calls to
 	Endpoint string
+
+	// http transcoding rules
+	rules []*rule
 }
 
 // read client request and write to server
@@ -239,5 +243,12 @@ func NewProxy(opts ...proxy.Option) proxy.Proxy {
 		p.Client = grpcc.NewClient()
 	}
 
+	// set the transcoding rules
+	if options.Context != nil {
+		if fds, ok := options.Context.Value(descriptorsKey{}).([]protoreflect.FileDescriptor); ok {
+			p.rules = newRules(fds)
+		}
+	}
+
 	return p
 }
@@ -0,0 +1,22 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/proxy"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type descriptorsKey struct{}
+
+// WithDescriptors sets the proto files used to transcode http json requests to grpc
+// calls using the google.api.http annotations of their methods. The descriptors are
+// loaded with LoadDescriptorSet or taken from the generated code.
+func WithDescriptors(fds ...protoreflect.FileDescriptor) proxy.Option {
+	return func(o *proxy.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, descriptorsKey{}, fds)
+	}
+}
@@ -0,0 +1,207 @@
+package grpc
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrInvalidTemplate = errors.New("invalid path template")
+)
+
+const (
+	// segment matches a literal
+	literal = iota
+	// segment matches any single path segment
+	wildcard
+	// segment matches the rest of the path
+	deepWildcard
+)
+
+type segment struct {
+	kind  int
+	value string
+}
+
+// variable binds the path segments from start to end to a field
+type variable struct {
+	field string
+	start int
+	end   int
+}
+
+// template is a parsed google.api.http path template
+// e.g /v1/{name=messages/*}:publish
+type template struct {
+	segments  []segment
+	variables []variable
+	verb      string
+}
+
+// parseTemplate parses the path template as defined in google/api/http.proto
+//
+// Template = "/" Segments [ Verb ] ;
+// Segments = Segment { "/" Segment } ;
+// Segment  = "*" | "**" | LITERAL | Variable ;
+// Variable = "{" FieldPath [ "=" Segments ] "}" ;
+// Verb     = ":" LITERAL ;
+func parseTemplate(tpl string) (*template, error) {
+	if !strings.HasPrefix(tpl, "/") {
+		return nil, ErrInvalidTemplate
+	}
+
+	t := new(template)
+	path := tpl[1:]
+
+	// the verb follows the last segment outside of a variable
+	if i := strings.LastIndex(path, ":"); i >= 0 && !strings.Contains(path[i:], "}") {
+		t.verb = path[i+1:]
+		path = path[:i]
+	}
+
+	for len(path) > 0 {
+		var seg string
+
+		if path[0] == '{' {
+			end := strings.Index(path, "}")
+			if end < 0 {
+				return nil, ErrInvalidTemplate
+			}
+			seg, path = path[1:end], path[end+1:]
+
+			v := variable{field: seg, start: len(t.segments)}
+			parts := []string{"*"}
+
+			if i := strings.Index(seg, "="); i >= 0 {
+				v.field = seg[:i]
+				parts = strings.Split(seg[i+1:], "/")
+			}
+			if len(v.field) == 0 {
+				return nil, ErrInvalidTemplate
+			}
+
+			for _, p := range parts {
+				s, err := parseSegment(p)
+				if err != nil {
+					return nil, err
+				}
+				t.segments = append(t.segments, s)
+			}
+
+			v.end = len(t.segments)
+			t.variables = append(t.variables, v)
+		} else {
+			end := strings.Index(path, "/")
+			if end < 0 {
+				end = len(path)
+			}
+			seg, path = path[:end], path[end:]
+
+			s, err := parseSegment(seg)
+			if err != nil {
+				return nil, err
+			}
+			t.segments = append(t.segments, s)
+		}
+
+		if len(path) == 0 {
+			break
+		}
+		if path[0] != '/' || len(path) == 1 {
+			return nil, ErrInvalidTemplate
+		}
+		path = path[1:]
+	}
+
+	// ** may only be the last segment
+	for i, s := range t.segments {
+		if s.kind == deepWildcard && i != len(t.segments)-1 {
+			return nil, ErrInvalidTemplate
+		}
+	}
+
+	return t, nil
+}
+
+func parseSegment(s string) (segment, error) {
+	switch {
+	case s == "*":
+		return segment{kind: wildcard}, nil
+	case s == "**":
+		return segment{kind: deepWildcard}, nil
+	case len(s) == 0 || strings.ContainsAny(s, "{}=*"):
+		return segment{}, ErrInvalidTemplate
+	default:
+		return segment{kind: literal, value: s}, nil
+	}
+}
+
+// match matches the path against the template and returns the bound variables
+func (t *template) match(path string) (map[string]string, bool) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, false
+	}
+	path = path[1:]
+
+	if len(t.verb) > 0 {
+		if !strings.HasSuffix(path, ":"+t.verb) {
+			return nil, false
+		}
+		path = strings.TrimSuffix(path, ":"+t.verb)
+	}
+
+	var parts []string
+	if len(path) > 0 {
+		parts = strings.Split(path, "/")
+	}
+
+	// the path segment matched by each template segment
+	matched := make([][]string, len(t.segments))
+
+	for i, s := range t.segments {
+		switch s.kind {
+		case deepWildcard:
+			matched[i] = parts
+			parts = nil
+			continue
+		case literal:
+			if len(parts) == 0 || parts[0] != s.value {
+				return nil, false
+			}
+		case wildcard:
+			if len(parts) == 0 || len(parts[0]) == 0 {
+				return nil, false
+			}
+		}
+		matched[i] = parts[:1]
+		parts = parts[1:]
+	}
+
+	if len(parts) > 0 {
+		return nil, false
+	}
+
+	vars := make(map[string]string, len(t.variables))
+
+	for _, v := range t.variables {
+		var values []string
+		for _, m := range matched[v.start:v.end] {
+			values = append(values, m...)
+		}
+
+		// single segment variables are unescaped completely
+		value := strings.Join(values, "/")
+		if v.end-v.start == 1 && t.segments[v.start].kind == wildcard {
+			u, err := url.PathUnescape(value)
+			if err != nil {
+				return nil, false
+			}
+			value = u
+		}
+
+		vars[v.field] = value
+	}
+
+	return vars, true
+}
@@ -0,0 +1,416 @@
+package grpc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/codec/bytes"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/util/ctx"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// rule maps a http method and path to a grpc method as defined by
+// the google.api.http annotation of the method
+type rule struct {
+	method   string
+	template *template
+	// the request field the body is mapped to, * for the whole request
+	body string
+	// the response field written as the body, the whole response if blank
+	response string
+	// the grpc method
+	desc protoreflect.MethodDescriptor
+}
+
+// LoadDescriptorSet reads the file descriptor set generated by protoc with
+// --descriptor_set_out and --include_imports for use with WithDescriptors
+func LoadDescriptorSet(path string) ([]protoreflect.FileDescriptor, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	set := new(descriptorpb.FileDescriptorSet)
+	if err := proto.Unmarshal(b, set); err != nil {
+		return nil, err
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, err
+	}
+
+	var fds []protoreflect.FileDescriptor
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		fds = append(fds, fd)
+		return true
+	})
+	return fds, nil
+}
+
+// newRules creates the transcoding rules for the annotated methods of the services
+func newRules(files []protoreflect.FileDescriptor) []*rule {
+	var rules []*rule
+
+	for _, fd := range files {
+		services := fd.Services()
+
+		for i := 0; i < services.Len(); i++ {
+			methods := services.Get(i).Methods()
+
+			for j := 0; j < methods.Len(); j++ {
+				md := methods.Get(j)
+
+				// streams can't be transcoded
+				if md.IsStreamingClient() || md.IsStreamingServer() {
+					continue
+				}
+
+				opts, ok := md.Options().(*descriptorpb.MethodOptions)
+				if !ok || opts == nil || !proto.HasExtension(opts, annotations.E_Http) {
+					continue
+				}
+				hr, ok := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+				if !ok {
+					continue
+				}
+
+				for _, h := range append([]*annotations.HttpRule{hr}, hr.AdditionalBindings...) {
+					r, err := newRule(md, h)
+					if err != nil {
+						if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+							logger.Debugf("Proxy skipping http rule of %s: %v", md.FullName(), err)
+						}
+						continue
+					}
+					rules = append(rules, r)
+				}
+			}
+		}
+	}
+
+	return rules
+}
+
+func newRule(md protoreflect.MethodDescriptor, h *annotations.HttpRule) (*rule, error) {
+	var method, path string
+
+	switch p := h.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		method, path = http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		method, path = http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		method, path = http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		method, path = http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		method, path = http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		method, path = p.Custom.GetKind(), p.Custom.GetPath()
+	default:
+		return nil, ErrInvalidTemplate
+	}
+
+	tpl, err := parseTemplate(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule{
+		method:   method,
+		template: tpl,
+		body:     h.Body,
+		response: h.ResponseBody,
+		desc:     md,
+	}, nil
+}
+
+// field returns the field of the message by its proto or json name
+func field(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := md.Fields()
+	if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return fields.ByJSONName(name)
+}
+
+// setField sets the field at the dotted path to the value parsed from the string
+func setField(m protoreflect.Message, path []string, value string) error {
+	fd := field(m.Descriptor(), path[0])
+	if fd == nil {
+		return fmt.Errorf("unknown field %s", path[0])
+	}
+
+	if len(path) > 1 {
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return fmt.Errorf("field %s is not a message", path[0])
+		}
+		return setField(m.Mutable(fd).Message(), path[1:], value)
+	}
+
+	if fd.IsMap() {
+		return fmt.Errorf("field %s is a map", path[0])
+	}
+
+	v, err := parseValue(m, fd, value)
+	if err != nil {
+		return fmt.Errorf("invalid value for field %s: %v", path[0], err)
+	}
+
+	if fd.IsList() {
+		m.Mutable(fd).List().Append(v)
+	} else {
+		m.Set(fd, v)
+	}
+	return nil
+}
+
+func parseValue(m protoreflect.Message, fd protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BytesKind:
+		b, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			b, err = base64.URLEncoding.DecodeString(value)
+		}
+		return protoreflect.ValueOfBytes(b), err
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		i, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(i)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		i, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(i), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		i, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(i)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		i, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(i), err
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(value, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(value, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(value)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		i, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(i)), err
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		// well known types such as timestamps and wrappers are parsed from their json string
+		b, err := json.Marshal(value)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		var v protoreflect.Value
+		if fd.IsList() {
+			v = m.Mutable(fd).List().NewElement()
+		} else {
+			v = m.NewField(fd)
+		}
+		return v, protojson.Unmarshal(b, v.Message().Interface())
+	}
+
+	return protoreflect.Value{}, fmt.Errorf("unsupported kind %s", fd.Kind())
+}
+
+// match returns the rule matching the request and the path variables
+func (p *Proxy) match(r *http.Request) (*rule, map[string]string) {
+	for _, rl := range p.rules {
+		if rl.method != r.Method {
+			continue
+		}
+		if vars, ok := rl.template.match(r.URL.EscapedPath()); ok {
+			return rl, vars
+		}
+	}
+	return nil, nil
+}
+
+// newRequest creates the grpc request message from the http request
+func newRequest(rl *rule, r *http.Request, vars map[string]string) (proto.Message, error) {
+	req := dynamicpb.NewMessage(rl.desc.Input())
+
+	if len(rl.body) > 0 {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(b) > 0 {
+			if rl.body != "*" {
+				fd := field(rl.desc.Input(), rl.body)
+				if fd == nil {
+					return nil, fmt.Errorf("unknown body field %s", rl.body)
+				}
+				// map the body to the field
+				b = []byte(fmt.Sprintf(`{%q:%s}`, fd.JSONName(), b))
+			}
+
+			if err := protojson.Unmarshal(b, req); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// the path variables are set last so they can't be overridden by the body
+	for k, v := range vars {
+		if err := setField(req, strings.Split(k, "."), v); err != nil {
+			return nil, err
+		}
+	}
+
+	// the query parameters are mapped to the fields not in the body
+	if rl.body == "*" {
+		return req, nil
+	}
+
+	for k, values := range r.URL.Query() {
+		path := strings.Split(k, ".")
+		if _, ok := vars[k]; ok || path[0] == rl.body {
+			continue
+		}
+
+		for _, v := range values {
+			if err := setField(req, path, v); err != nil {
+				// ignore parameters which aren't fields e.g cache busters
+				if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+					logger.Tracef("Proxy ignoring query parameter %s: %v", k, err)
+				}
+				break
+			}
+		}
+	}
+
+	return req, nil
+}
+
+// writeResponse writes the grpc response as json
+func writeResponse(w http.ResponseWriter, rl *rule, data []byte) error {
+	rsp := dynamicpb.NewMessage(rl.desc.Output())
+	if err := proto.Unmarshal(data, rsp); err != nil {
+		return err
+	}
+
+	var b []byte
+	var err error
+
+	if len(rl.response) > 0 {
+		fd := field(rl.desc.Output(), rl.response)
+		if fd == nil {
+			return fmt.Errorf("unknown response field %s", rl.response)
+		}
+
+		// marshal the whole response and take the field
+		b, err = protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(rsp)
+		if err != nil {
+			return err
+		}
+		fields := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(b, &fields); err != nil {
+			return err
+		}
+		b = fields[fd.JSONName()]
+	} else {
+		b, err = protojson.Marshal(rsp)
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	_, err = w.Write(b)
+	return err
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	ce := errors.FromError(err)
+	if ce.Code == 0 {
+		ce = errors.InternalServerError("go.micro.proxy", err.Error()).(*errors.Error)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(ce.Code))
+
+	if _, err := w.Write([]byte(ce.Error())); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error(err)
+		}
+	}
+}
+
+// ServeHTTP transcodes http json requests to grpc calls using the google.api.http
+// annotations of the methods in the descriptors set with WithDescriptors
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rl, vars := p.match(r)
+	if rl == nil {
+		writeError(w, errors.NotFound("go.micro.proxy", "no method for %s %s", r.Method, r.URL.Path))
+		return
+	}
+
+	req, err := newRequest(rl, r, vars)
+	if err != nil {
+		writeError(w, errors.BadRequest("go.micro.proxy", err.Error()))
+		return
+	}
+
+	body, err := proto.Marshal(req)
+	if err != nil {
+		writeError(w, errors.InternalServerError("go.micro.proxy", err.Error()))
+		return
+	}
+
+	// the full grpc service name unless we have an endpoint
+	service := string(rl.desc.Parent().FullName())
+	endpoint := fmt.Sprintf("/%s/%s", service, rl.desc.Name())
+
+	var opts []client.CallOption
+
+	if len(p.Endpoint) > 0 {
+		// address:port
+		if parts := strings.Split(p.Endpoint, ":"); len(parts) > 1 {
+			opts = append(opts, client.WithAddress(p.Endpoint))
+			// use as service name
+		} else {
+			service = p.Endpoint
+		}
+	}
+
+	if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+		logger.Tracef("Proxy transcoding %s %s to %s %s", r.Method, r.URL.Path, service, endpoint)
+	}
+
+	creq := p.Client.NewRequest(service, endpoint, &bytes.Frame{Data: body}, client.WithContentType("application/grpc+proto"))
+	crsp := new(bytes.Frame)
+
+	if err := p.Client.Call(ctx.FromRequest(r), creq, crsp, opts...); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := writeResponse(w, rl, crsp.Data); err != nil {
+		writeError(w, errors.InternalServerError("go.micro.proxy", err.Error()))
+	}
+}
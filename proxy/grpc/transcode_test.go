@@ -0,0 +1,141 @@
+package grpc
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/proxy"
+	pb "github.com/micro/go-micro/v3/server/grpc/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+type testServer struct {
+	pb.UnimplementedTestServer
+}
+
+func (s *testServer) Call(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	if req.Name == "" {
+		return nil, errors.NotFound("test", "name not found")
+	}
+	return &pb.Response{Msg: "Hello " + req.Name + " " + req.Uuid}, nil
+}
+
+func TestTemplate(t *testing.T) {
+	testData := []struct {
+		template string
+		path     string
+		match    bool
+		vars     map[string]string
+	}{
+		{"/v1/messages", "/v1/messages", true, map[string]string{}},
+		{"/v1/messages", "/v1/messages/1", false, nil},
+		{"/v1/messages/{id}", "/v1/messages/1", true, map[string]string{"id": "1"}},
+		{"/v1/messages/{id}", "/v1/messages", false, nil},
+		{"/v1/messages/{id}", "/v1/messages/a%20b", true, map[string]string{"id": "a b"}},
+		{"/v1/{name=messages/*}", "/v1/messages/1", true, map[string]string{"name": "messages/1"}},
+		{"/v1/{name=messages/*}", "/v1/users/1", false, nil},
+		{"/v1/{msg.id}/*", "/v1/1/foo", true, map[string]string{"msg.id": "1"}},
+		{"/v1/{path=**}", "/v1/a/b/c", true, map[string]string{"path": "a/b/c"}},
+		{"/v1/messages/{id}:publish", "/v1/messages/1:publish", true, map[string]string{"id": "1"}},
+		{"/v1/messages/{id}:publish", "/v1/messages/1", false, nil},
+	}
+
+	for _, d := range testData {
+		tpl, err := parseTemplate(d.template)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", d.template, err)
+		}
+
+		vars, ok := tpl.match(d.path)
+		if ok != d.match {
+			t.Fatalf("Expected %s match %s to be %v", d.template, d.path, d.match)
+		}
+		if len(vars) != len(d.vars) {
+			t.Fatalf("Expected %s vars %v got %v", d.path, d.vars, vars)
+		}
+		for k, v := range d.vars {
+			if vars[k] != v {
+				t.Fatalf("Expected %s var %s to be %s got %s", d.path, k, v, vars[k])
+			}
+		}
+	}
+
+	for _, tpl := range []string{"v1/messages", "^/v1/messages/?$", "/v1/{id", "/v1/**/foo", "/v1//messages"} {
+		if _, err := parseTemplate(tpl); err != ErrInvalidTemplate {
+			t.Fatalf("Expected %s to be invalid got %v", tpl, err)
+		}
+	}
+}
+
+func TestTranscode(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// a plain grpc backend
+	srv := grpc.NewServer()
+	pb.RegisterTestServer(srv, &testServer{})
+	go srv.Serve(l)
+	defer srv.Stop()
+
+	fd, err := protoregistry.GlobalFiles.FindFileByPath("server/grpc/proto/test.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProxy(
+		proxy.WithEndpoint(l.Addr().String()),
+		WithDescriptors(fd),
+	)
+
+	handler, ok := p.(http.Handler)
+	if !ok {
+		t.Fatal("Expected the proxy to be a http handler")
+	}
+
+	testData := []struct {
+		method string
+		path   string
+		body   string
+		code   int
+		rsp    string
+	}{
+		{"POST", "/api/v0/test/call/1", `{"name":"John"}`, 200, `{"msg":"Hello John 1"}`},
+		// the path variable is set over the body
+		{"POST", "/api/v0/test/call/2", `{"name":"John","uuid":"1"}`, 200, `{"msg":"Hello John 2"}`},
+		{"POST", "/api/v0/test/call/1", `{}`, 404, "name not found"},
+		{"POST", "/api/v0/test/call/1", `{"unknown":1}`, 400, ""},
+		{"GET", "/api/v0/test/call/1", ``, 404, ""},
+		// pcre paths aren't valid templates
+		{"POST", "/api/v0/test/call/pcre/", `{"name":"John"}`, 404, ""},
+	}
+
+	for _, d := range testData {
+		req := httptest.NewRequest(d.method, d.path, strings.NewReader(d.body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != d.code {
+			t.Fatalf("Expected %s %s code %d got %d: %s", d.method, d.path, d.code, w.Code, w.Body.String())
+		}
+
+		b, err := ioutil.ReadAll(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(strings.Replace(string(b), " ", "", -1), strings.Replace(d.rsp, " ", "", -1)) {
+			t.Fatalf("Expected %s %s response %s got %s", d.method, d.path, d.rsp, b)
+		}
+	}
+}
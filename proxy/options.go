@@ -2,6 +2,8 @@
 package proxy
 
 import (
+	"context"
+
 	"github.com/micro/go-micro/v3/client"
 	"github.com/micro/go-micro/v3/router"
 )
@@ -15,6 +17,8 @@ type Options struct {
 	Router router.Router
 	// Extra links for different clients
 	Links map[string]client.Client
+	// Alternative options
+	Context context.Context
 }
 
 type Option func(o *Options)
@@ -0,0 +1,31 @@
+package policy
+
+import (
+	"github.com/micro/go-micro/v3/config"
+)
+
+type Options struct {
+	// Policy applied until loaded from the config
+	Policy Policy
+	// Config to load the policy from and watch for changes
+	Config config.Config
+	// Path of the policy in the config
+	Path []string
+}
+
+type Option func(o *Options)
+
+// WithPolicy sets a static policy
+func WithPolicy(p Policy) Option {
+	return func(o *Options) {
+		o.Policy = p
+	}
+}
+
+// WithConfig loads the policy at the path of the config and reloads it on changes
+func WithConfig(c config.Config, path ...string) Option {
+	return func(o *Options) {
+		o.Config = c
+		o.Path = path
+	}
+}
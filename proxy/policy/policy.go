@@ -0,0 +1,192 @@
+// Package policy is a traffic policy layer for proxies
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/micro/go-micro/v3/metadata"
+)
+
+const (
+	Allow = "allow"
+	Deny  = "deny"
+)
+
+var (
+	ErrInvalidAction = errors.New("invalid policy action")
+)
+
+// Policy is an ordered list of routes, the first route matching a request applies
+type Policy struct {
+	Routes []Route `json:"routes"`
+	// Default is the action when no route matches, allow if blank
+	Default string `json:"default"`
+}
+
+// Route is the policy for the requests to the matching services and endpoints
+type Route struct {
+	// Service and Endpoint are path.Match patterns, blank matches everything
+	Service  string `json:"service"`
+	Endpoint string `json:"endpoint"`
+	// Action is allow or deny, allow if blank
+	Action string `json:"action"`
+	// Request headers to change
+	Request Headers `json:"request"`
+	// Response headers to change
+	Response Headers `json:"response"`
+	// Fault to inject for chaos testing
+	Fault Fault `json:"fault"`
+}
+
+// Headers are the header changes applied in order of remove, rewrite and add
+type Headers struct {
+	Remove  []string          `json:"remove"`
+	Rewrite []Rewrite         `json:"rewrite"`
+	Add     map[string]string `json:"add"`
+}
+
+// Rewrite replaces the matches of the pattern in a header value
+type Rewrite struct {
+	Header  string `json:"header"`
+	Pattern string `json:"pattern"`
+	Replace string `json:"replace"`
+}
+
+// Fault delays or aborts a percentage of requests
+type Fault struct {
+	// Delay is a duration e.g 100ms
+	Delay        string  `json:"delay"`
+	DelayPercent float64 `json:"delay_percent"`
+	// Abort is the error code, 503 if not set
+	Abort        int32   `json:"abort"`
+	AbortPercent float64 `json:"abort_percent"`
+}
+
+// rule is a validated route
+type rule struct {
+	Route
+
+	delay    time.Duration
+	request  []*regexp.Regexp
+	response []*regexp.Regexp
+}
+
+type rules struct {
+	rules []*rule
+	deny  bool
+}
+
+func compile(p Policy) (*rules, error) {
+	rs := new(rules)
+
+	switch p.Default {
+	case "", Allow:
+	case Deny:
+		rs.deny = true
+	default:
+		return nil, ErrInvalidAction
+	}
+
+	for i, r := range p.Routes {
+		switch r.Action {
+		case "", Allow, Deny:
+		default:
+			return nil, fmt.Errorf("route %d: %v", i, ErrInvalidAction)
+		}
+
+		for _, pattern := range []string{r.Service, r.Endpoint} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("route %d: %v", i, err)
+			}
+		}
+
+		rl := &rule{Route: r}
+
+		if len(r.Fault.Delay) > 0 {
+			d, err := time.ParseDuration(r.Fault.Delay)
+			if err != nil {
+				return nil, fmt.Errorf("route %d: %v", i, err)
+			}
+			rl.delay = d
+		}
+		if rl.Fault.Abort == 0 {
+			rl.Fault.Abort = 503
+		}
+
+		var err error
+		if rl.request, err = compileRewrites(r.Request.Rewrite); err != nil {
+			return nil, fmt.Errorf("route %d: %v", i, err)
+		}
+		if rl.response, err = compileRewrites(r.Response.Rewrite); err != nil {
+			return nil, fmt.Errorf("route %d: %v", i, err)
+		}
+
+		rs.rules = append(rs.rules, rl)
+	}
+
+	return rs, nil
+}
+
+func compileRewrites(rw []Rewrite) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(rw))
+	for _, r := range rw {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+func match(pattern, value string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
+
+// match returns the first rule for the service and endpoint
+func (r *rules) match(service, endpoint string) *rule {
+	for _, rl := range r.rules {
+		if match(rl.Service, service) && match(rl.Endpoint, endpoint) {
+			return rl
+		}
+	}
+	return nil
+}
+
+func apply(md metadata.Metadata, h Headers, rewrites []*regexp.Regexp) {
+	// header keys are case insensitive
+headers:
+	for k, v := range md {
+		for _, r := range h.Remove {
+			if strings.EqualFold(k, r) {
+				delete(md, k)
+				continue headers
+			}
+		}
+		for i, rw := range h.Rewrite {
+			if strings.EqualFold(k, rw.Header) {
+				v = rewrites[i].ReplaceAllString(v, rw.Replace)
+				md[k] = v
+			}
+		}
+	}
+	for k, v := range h.Add {
+		md.Delete(k)
+		md.Set(k, v)
+	}
+}
+
+// sample returns true for the percentage of calls
+func sample(percent float64) bool {
+	return percent > 0 && rand.Float64()*100 < percent
+}
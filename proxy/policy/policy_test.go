@@ -0,0 +1,240 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/config"
+	"github.com/micro/go-micro/v3/config/source"
+	"github.com/micro/go-micro/v3/config/source/memory"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/server"
+)
+
+type testRequest struct {
+	server.Request
+	service  string
+	endpoint string
+}
+
+func (r *testRequest) Service() string {
+	return r.service
+}
+
+func (r *testRequest) Endpoint() string {
+	return r.endpoint
+}
+
+type testResponse struct {
+	server.Response
+	header map[string]string
+}
+
+func (r *testResponse) WriteHeader(hdr map[string]string) {
+	r.header = hdr
+}
+
+func (r *testResponse) Write(b []byte) error {
+	return nil
+}
+
+// testProxy records the request metadata and writes a response header
+type testProxy struct {
+	md metadata.Metadata
+}
+
+func (p *testProxy) ProcessMessage(ctx context.Context, msg server.Message) error {
+	return nil
+}
+
+func (p *testProxy) ServeRequest(ctx context.Context, req server.Request, rsp server.Response) error {
+	p.md, _ = metadata.FromContext(ctx)
+	rsp.WriteHeader(map[string]string{"Server": "test", "Secret": "value"})
+	return rsp.Write([]byte("ok"))
+}
+
+func (p *testProxy) String() string {
+	return "test"
+}
+
+func serve(p *Proxy, service, endpoint string, md metadata.Metadata) (*testResponse, error) {
+	ctx := metadata.NewContext(context.Background(), md)
+	rsp := new(testResponse)
+	err := p.ServeRequest(ctx, &testRequest{service: service, endpoint: endpoint}, rsp)
+	return rsp, err
+}
+
+func TestAllowDeny(t *testing.T) {
+	p := NewProxy(new(testProxy), WithPolicy(Policy{
+		Routes: []Route{
+			{Service: "foo", Endpoint: "Foo.Admin*", Action: Deny},
+			{Service: "foo"},
+			{Service: "bar.*", Action: Allow},
+		},
+		Default: Deny,
+	}))
+
+	testData := []struct {
+		service  string
+		endpoint string
+		allowed  bool
+	}{
+		{"foo", "Foo.Call", true},
+		{"foo", "Foo.AdminDelete", false},
+		{"bar.baz", "Bar.Call", true},
+		{"baz", "Baz.Call", false},
+	}
+
+	for _, d := range testData {
+		_, err := serve(p, d.service, d.endpoint, nil)
+		if d.allowed && err != nil {
+			t.Fatalf("Expected %s %s to be allowed got %v", d.service, d.endpoint, err)
+		}
+		if !d.allowed && errors.FromError(err).Code != 403 {
+			t.Fatalf("Expected %s %s to be denied got %v", d.service, d.endpoint, err)
+		}
+	}
+
+	if err := p.Update(Policy{Default: "maybe"}); err == nil {
+		t.Fatal("Expected an invalid policy error")
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	tp := new(testProxy)
+	p := NewProxy(tp, WithPolicy(Policy{
+		Routes: []Route{{
+			Request: Headers{
+				Remove:  []string{"authorization"},
+				Rewrite: []Rewrite{{Header: "Micro-Namespace", Pattern: "^staging$", Replace: "production"}},
+				Add:     map[string]string{"X-Proxy": "policy"},
+			},
+			Response: Headers{
+				Remove:  []string{"Secret"},
+				Rewrite: []Rewrite{{Header: "server", Pattern: "test", Replace: "proxy"}},
+				Add:     map[string]string{"X-Policy": "applied"},
+			},
+		}},
+	}))
+
+	rsp, err := serve(p, "foo", "Foo.Call", metadata.Metadata{
+		"Authorization":   "Bearer token",
+		"Micro-Namespace": "staging",
+		"Foo":             "bar",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := tp.md.Get("Authorization"); ok {
+		t.Fatal("Expected the authorization header to be removed")
+	}
+	if v, _ := tp.md.Get("Micro-Namespace"); v != "production" {
+		t.Fatalf("Expected the namespace to be rewritten got %s", v)
+	}
+	if v, _ := tp.md.Get("X-Proxy"); v != "policy" {
+		t.Fatalf("Expected the added header got %s", v)
+	}
+	if v, _ := tp.md.Get("Foo"); v != "bar" {
+		t.Fatalf("Expected the header to be passed got %s", v)
+	}
+
+	if _, ok := rsp.header["Secret"]; ok {
+		t.Fatal("Expected the secret response header to be removed")
+	}
+	if v := rsp.header["Server"]; v != "proxy" {
+		t.Fatalf("Expected the server response header to be rewritten got %s", v)
+	}
+	if v := rsp.header["X-Policy"]; v != "applied" {
+		t.Fatalf("Expected the added response header got %s", v)
+	}
+}
+
+func TestFault(t *testing.T) {
+	p := NewProxy(new(testProxy), WithPolicy(Policy{
+		Routes: []Route{
+			{Service: "slow", Fault: Fault{Delay: "50ms", DelayPercent: 100}},
+			{Service: "broken", Fault: Fault{Abort: 500, AbortPercent: 100}},
+			{Service: "flaky", Fault: Fault{AbortPercent: 50}},
+		},
+	}))
+
+	start := time.Now()
+	if _, err := serve(p, "slow", "Slow.Call", nil); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Fatalf("Expected the request to be delayed got %v", d)
+	}
+
+	if _, err := serve(p, "broken", "Broken.Call", nil); errors.FromError(err).Code != 500 {
+		t.Fatalf("Expected the request to be aborted got %v", err)
+	}
+
+	var aborted int
+	for i := 0; i < 1000; i++ {
+		_, err := serve(p, "flaky", "Flaky.Call", nil)
+		if err == nil {
+			continue
+		}
+		if errors.FromError(err).Code != 503 {
+			t.Fatalf("Expected the default abort code got %v", err)
+		}
+		aborted++
+	}
+	if aborted < 350 || aborted > 650 {
+		t.Fatalf("Expected about half the requests to be aborted got %d", aborted)
+	}
+
+	if err := p.Update(Policy{Routes: []Route{{Fault: Fault{Delay: "soon"}}}}); err == nil {
+		t.Fatal("Expected an invalid delay error")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	c, err := config.NewConfig(config.WithSource(memory.NewSource(memory.WithJSON([]byte(
+		`{"proxy": {"policy": {"routes": [{"service": "foo", "action": "deny"}]}}}`,
+	)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	p := NewProxy(new(testProxy), WithConfig(c, "proxy", "policy"))
+
+	if _, err := serve(p, "foo", "Foo.Call", nil); errors.FromError(err).Code != 403 {
+		t.Fatalf("Expected the request to be denied got %v", err)
+	}
+
+	// invalid policies are ignored
+	if err := c.Write(&source.ChangeSet{
+		Data:   []byte(`{"proxy": {"policy": {"default": "maybe"}}}`),
+		Format: "json",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := serve(p, "foo", "Foo.Call", nil); errors.FromError(err).Code != 403 {
+		t.Fatalf("Expected the request to be denied got %v", err)
+	}
+
+	if err := c.Write(&source.ChangeSet{
+		Data:   []byte(`{"proxy": {"policy": {"routes": [{"service": "foo", "action": "allow"}]}}}`),
+		Format: "json",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 50; i++ {
+		if _, err = serve(p, "foo", "Foo.Call", nil); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Expected the reloaded policy to allow the request got %v", err)
+	}
+}
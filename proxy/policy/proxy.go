@@ -0,0 +1,176 @@
+package policy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/config/reader"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/proxy"
+	"github.com/micro/go-micro/v3/server"
+)
+
+// Proxy applies the traffic policy to the requests served by the proxy it wraps
+type Proxy struct {
+	proxy.Proxy
+
+	options Options
+
+	sync.RWMutex
+	rules *rules
+}
+
+// response applies the response header changes
+type response struct {
+	server.Response
+
+	rule  *rule
+	wrote bool
+}
+
+func (r *response) WriteHeader(hdr map[string]string) {
+	md := metadata.Copy(hdr)
+	apply(md, r.rule.Response, r.rule.response)
+	r.wrote = true
+	r.Response.WriteHeader(md)
+}
+
+func (r *response) Write(b []byte) error {
+	// the added headers are sent even if the proxy doesn't write a header
+	if !r.wrote && len(r.rule.Response.Add) > 0 {
+		r.WriteHeader(map[string]string{})
+	}
+	return r.Response.Write(b)
+}
+
+// Update replaces the policy
+func (p *Proxy) Update(policy Policy) error {
+	rs, err := compile(policy)
+	if err != nil {
+		return err
+	}
+
+	p.Lock()
+	p.rules = rs
+	p.Unlock()
+	return nil
+}
+
+// ServeRequest applies the policy before serving the request with the proxy
+func (p *Proxy) ServeRequest(ctx context.Context, req server.Request, rsp server.Response) error {
+	p.RLock()
+	rs := p.rules
+	p.RUnlock()
+
+	rl := rs.match(req.Service(), req.Endpoint())
+	if rl == nil {
+		if rs.deny {
+			return errors.Forbidden("go.micro.proxy", "%s %s denied by policy", req.Service(), req.Endpoint())
+		}
+		return p.Proxy.ServeRequest(ctx, req, rsp)
+	}
+
+	if rl.Action == Deny {
+		return errors.Forbidden("go.micro.proxy", "%s %s denied by policy", req.Service(), req.Endpoint())
+	}
+
+	if rl.delay > 0 && sample(rl.Fault.DelayPercent) {
+		select {
+		case <-time.After(rl.delay):
+		case <-ctx.Done():
+			return errors.Timeout("go.micro.proxy", "%s %s timed out", req.Service(), req.Endpoint())
+		}
+	}
+
+	if sample(rl.Fault.AbortPercent) {
+		return errors.New("go.micro.proxy", "fault injected by policy", rl.Fault.Abort)
+	}
+
+	// the metadata is a copy
+	md, ok := metadata.FromContext(ctx)
+	if !ok {
+		md = make(metadata.Metadata)
+	}
+	apply(md, rl.Request, rl.request)
+
+	return p.Proxy.ServeRequest(metadata.NewContext(ctx, md), req, &response{Response: rsp, rule: rl})
+}
+
+// watch reloads the policy when the config changes
+func (p *Proxy) watch() {
+	for {
+		w, err := p.options.Config.Watch(p.options.Path...)
+		if err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Proxy failed to watch the policy: %v", err)
+			}
+			return
+		}
+
+		for {
+			v, err := w.Next()
+			if err != nil {
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("Proxy policy watcher error: %v", err)
+				}
+				break
+			}
+			p.load(v)
+		}
+
+		w.Stop()
+		time.Sleep(time.Second)
+	}
+}
+
+func (p *Proxy) load(v reader.Value) {
+	// no policy in the config
+	if b := v.Bytes(); len(b) == 0 || string(b) == "null" {
+		return
+	}
+
+	var policy Policy
+	if err := v.Scan(&policy); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Proxy failed to load the policy: %v", err)
+		}
+		return
+	}
+
+	// keep the current policy if the new one is invalid
+	if err := p.Update(policy); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Proxy failed to load the policy: %v", err)
+		}
+	}
+}
+
+// NewProxy returns a proxy applying the traffic policy to the requests served by the proxy
+func NewProxy(p proxy.Proxy, opts ...Option) *Proxy {
+	var options Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	pp := &Proxy{
+		Proxy:   p,
+		options: options,
+	}
+
+	if err := pp.Update(options.Policy); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Proxy invalid policy: %v", err)
+		}
+		pp.rules = new(rules)
+	}
+
+	if options.Config != nil {
+		pp.load(options.Config.Get(options.Path...))
+		go pp.watch()
+	}
+
+	return pp
+}
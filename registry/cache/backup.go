@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/store"
+)
+
+// the prefix of the keys written to the backup store
+const backupPrefix = "registry/cache/"
+
+// backupRecord is the value of a service written to the backup store
+type backupRecord struct {
+	Domain   string              `json:"domain"`
+	Service  string              `json:"service"`
+	Services []*registry.Service `json:"services"`
+}
+
+func backupKey(domain, service string) string {
+	return backupPrefix + domain + "/" + service
+}
+
+// backup writes the services through to the backup store, no services deletes the record
+func (c *cache) backup(domain, service string, srvs []*registry.Service) {
+	if c.opts.Backup == nil {
+		return
+	}
+
+	key := backupKey(domain, service)
+
+	if len(srvs) == 0 {
+		if err := c.opts.Backup.Delete(key); err != nil && err != store.ErrNotFound {
+			logger.Errorf("Error deleting %s from the registry backup: %v", key, err)
+		}
+		return
+	}
+
+	b, err := json.Marshal(&backupRecord{Domain: domain, Service: service, Services: srvs})
+	if err != nil {
+		logger.Errorf("Error encoding %s for the registry backup: %v", key, err)
+		return
+	}
+
+	if err := c.opts.Backup.Write(&store.Record{Key: key, Value: b}); err != nil {
+		logger.Errorf("Error writing %s to the registry backup: %v", key, err)
+	}
+}
+
+// restore seeds the cache from the backup store. The services are loaded without
+// a ttl so the registry is always asked first, the backup is only returned if
+// the registry is unavailable.
+func (c *cache) restore() {
+	if c.opts.Backup == nil {
+		return
+	}
+
+	recs, err := c.opts.Backup.Read(backupPrefix, store.ReadPrefix())
+	if err == store.ErrNotFound {
+		return
+	} else if err != nil {
+		logger.Errorf("Error reading the registry backup: %v", err)
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	for _, rec := range recs {
+		if !strings.HasPrefix(rec.Key, backupPrefix) {
+			continue
+		}
+
+		var br backupRecord
+		if err := json.Unmarshal(rec.Value, &br); err != nil {
+			logger.Errorf("Error decoding %s from the registry backup: %v", rec.Key, err)
+			continue
+		}
+		if len(br.Services) == 0 {
+			continue
+		}
+
+		if _, ok := c.services[br.Domain]; !ok {
+			c.services[br.Domain] = make(services)
+		}
+		c.services[br.Domain][br.Service] = br.Services
+	}
+
+	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Debugf("Seeded the registry cache with %d services from the backup", len(recs))
+	}
+}
@@ -4,11 +4,13 @@ package cache
 import (
 	"math"
 	"math/rand"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/micro/go-micro/v3/logger"
 	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/store"
 	util "github.com/micro/go-micro/v3/util/registry"
 )
 
@@ -23,8 +25,20 @@ type Cache interface {
 type Options struct {
 	// TTL is the cache TTL
 	TTL time.Duration
+	// Resync is the interval at which watched services are
+	// reloaded from the registry, zero disables the resync
+	Resync time.Duration
+	// Hooks are called when a cached service changes
+	Hooks []Hook
+	// Backup is written through with the cached services
+	// and used to seed the cache on startup
+	Backup store.Store
 }
 
+// Hook is called with the domain and the event when a service is
+// created, updated or deleted in the cache
+type Hook func(domain string, event *registry.Event)
+
 type Option func(o *Options)
 
 type cache struct {
@@ -49,13 +63,18 @@ type services map[string][]*registry.Service
 type ttls map[string]time.Time
 type watched map[string]bool
 
-var defaultTTL = time.Minute
+var (
+	defaultTTL    = time.Minute
+	defaultResync = time.Minute
+)
 
+// backoff returns a jittered backoff so watchers don't reconnect in lockstep
 func backoff(attempts int) time.Duration {
 	if attempts == 0 {
 		return time.Duration(0)
 	}
-	return time.Duration(math.Pow(10, float64(attempts))) * time.Millisecond
+	d := time.Duration(math.Pow(10, float64(attempts))) * time.Millisecond
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)))
 }
 
 func (c *cache) getStatus() error {
@@ -107,15 +126,19 @@ func (c *cache) del(domain, service string) {
 	}
 
 	c.Lock()
-	defer c.Unlock()
-
+	var old []*registry.Service
 	if _, ok := c.services[domain]; ok {
+		old = c.services[domain][service]
 		delete(c.services[domain], service)
 	}
 
 	if _, ok := c.ttls[domain]; ok {
 		delete(c.ttls[domain], service)
 	}
+	c.Unlock()
+
+	c.backup(domain, service, nil)
+	c.notify(domain, old, nil)
 }
 
 func (c *cache) get(domain, service string) ([]*registry.Service, error) {
@@ -202,8 +225,6 @@ func (c *cache) get(domain, service string) ([]*registry.Service, error) {
 
 func (c *cache) set(domain string, service string, srvs []*registry.Service) {
 	c.Lock()
-	defer c.Unlock()
-
 	if _, ok := c.services[domain]; !ok {
 		c.services[domain] = make(services)
 	}
@@ -211,8 +232,72 @@ func (c *cache) set(domain string, service string, srvs []*registry.Service) {
 		c.ttls[domain] = make(ttls)
 	}
 
+	old := c.services[domain][service]
 	c.services[domain][service] = srvs
 	c.ttls[domain][service] = time.Now().Add(c.opts.TTL)
+	c.Unlock()
+
+	c.backup(domain, service, srvs)
+	c.notify(domain, old, srvs)
+}
+
+// notify diffs the old and new versions of a service and calls the hooks
+func (c *cache) notify(domain string, old, srvs []*registry.Service) {
+	if len(c.opts.Hooks) == 0 {
+		return
+	}
+
+	versions := make(map[string]*registry.Service, len(old))
+	for _, s := range old {
+		versions[s.Version] = s
+	}
+
+	var events []*registry.Event
+
+	for _, s := range srvs {
+		o, ok := versions[s.Version]
+		delete(versions, s.Version)
+
+		if !ok {
+			events = append(events, &registry.Event{Type: registry.Create, Timestamp: time.Now(), Service: s})
+		} else if !equalNodes(o.Nodes, s.Nodes) {
+			events = append(events, &registry.Event{Type: registry.Update, Timestamp: time.Now(), Service: s})
+		}
+	}
+
+	for _, o := range versions {
+		events = append(events, &registry.Event{Type: registry.Delete, Timestamp: time.Now(), Service: o})
+	}
+
+	for _, e := range events {
+		for _, h := range c.opts.Hooks {
+			h(domain, e)
+		}
+	}
+}
+
+// equalNodes checks if two sets of nodes are the same regardless of order
+func equalNodes(a, b []*registry.Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	nodes := make(map[string]*registry.Node, len(a))
+	for _, n := range a {
+		nodes[n.Id] = n
+	}
+
+	for _, n := range b {
+		o, ok := nodes[n.Id]
+		if !ok || o.Address != n.Address || o.Health != n.Health || o.Weight != n.Weight {
+			return false
+		}
+		if !reflect.DeepEqual(o.Metadata, n.Metadata) {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (c *cache) update(domain string, res *registry.Result) {
@@ -223,18 +308,21 @@ func (c *cache) update(domain string, res *registry.Result) {
 	// only save watched services since the service using the cache may only depend on a handful
 	// of other services
 	c.RLock()
-	if _, ok := c.watched[res.Service.Name]; !ok {
+	if _, ok := c.watched[domain][res.Service.Name]; !ok {
 		c.RUnlock()
 		return
 	}
 
 	// we're not going to cache anything unless there was already a lookup
-	services, ok := c.services[domain][res.Service.Name]
+	cached, ok := c.services[domain][res.Service.Name]
 	if !ok {
 		c.RUnlock()
 		return
 	}
 
+	// copy the services so the hooks can diff against the cached ones
+	services := util.Copy(cached)
+
 	c.RUnlock()
 
 	if len(res.Service.Nodes) == 0 {
@@ -337,14 +425,21 @@ func (c *cache) run(domain string) {
 	c.running[domain] = true
 	c.Unlock()
 
+	// used to stop and trigger the resync
+	stop := make(chan bool)
+	trigger := make(chan bool, 1)
+
 	// reset watcher on exit
 	defer func() {
+		close(stop)
 		c.Lock()
 		c.watched[domain] = make(map[string]bool)
 		c.running[domain] = false
 		c.Unlock()
 	}()
 
+	go c.resync(domain, trigger, stop)
+
 	var a, b int
 
 	for {
@@ -380,6 +475,14 @@ func (c *cache) run(domain string) {
 			continue
 		}
 
+		// events may have been missed while the watch was down
+		if b > 0 {
+			select {
+			case trigger <- true:
+			default:
+			}
+		}
+
 		// reset a
 		a = 0
 
@@ -410,6 +513,57 @@ func (c *cache) run(domain string) {
 	}
 }
 
+// resync periodically reloads the watched services of a domain from the registry
+// so any events missed by the watcher don't leave stale nodes in the cache
+func (c *cache) resync(domain string, trigger, stop chan bool) {
+	for {
+		var tick <-chan time.Time
+		if c.opts.Resync > time.Duration(0) {
+			// jitter the interval so caches don't all hit the registry at once
+			tick = time.After(c.opts.Resync + time.Duration(rand.Int63n(int64(c.opts.Resync/10)+1)))
+		}
+
+		select {
+		case <-c.exit:
+			return
+		case <-stop:
+			return
+		case <-trigger:
+		case <-tick:
+		}
+
+		c.RLock()
+		names := make([]string, 0, len(c.watched[domain]))
+		for name := range c.watched[domain] {
+			names = append(names, name)
+		}
+		c.RUnlock()
+
+		for _, name := range names {
+			// only reload what has already been looked up
+			c.RLock()
+			_, ok := c.services[domain][name]
+			c.RUnlock()
+			if !ok {
+				continue
+			}
+
+			srvs, err := c.Registry.GetService(name, registry.GetDomain(domain))
+			if err == registry.ErrNotFound {
+				c.del(domain, name)
+				continue
+			} else if err != nil {
+				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+					logger.Debugf("rcache: resync of %s failed: %v", name, err)
+				}
+				continue
+			}
+
+			c.set(domain, name, util.Copy(srvs))
+		}
+	}
+}
+
 // watch loops the next event and calls update
 // it returns if there's an error
 func (c *cache) watch(domain string, w registry.Watcher) error {
@@ -470,6 +624,9 @@ func (c *cache) GetService(service string, opts ...registry.GetOption) ([]*regis
 		return nil, err
 	}
 
+	// the whole service is cached so the labels are matched against the copy
+	services = registry.FilterLabels(services, options.Labels)
+
 	// if there's nothing return err
 	if len(services) == 0 {
 		return nil, registry.ErrNotFound
@@ -499,14 +656,15 @@ func (c *cache) String() string {
 func New(r registry.Registry, opts ...Option) Cache {
 	rand.Seed(time.Now().UnixNano())
 	options := Options{
-		TTL: defaultTTL,
+		TTL:    defaultTTL,
+		Resync: defaultResync,
 	}
 
 	for _, o := range opts {
 		o(&options)
 	}
 
-	return &cache{
+	c := &cache{
 		Registry: r,
 		opts:     options,
 		running:  make(map[string]bool),
@@ -515,4 +673,9 @@ func New(r registry.Registry, opts ...Option) Cache {
 		ttls:     make(map[string]ttls),
 		exit:     make(chan bool),
 	}
+
+	// seed the cache with the last known services
+	c.restore()
+
+	return c
 }
@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/registry/memory"
+	"github.com/micro/go-micro/v3/store"
+	smemory "github.com/micro/go-micro/v3/store/memory"
+)
+
+// deafRegistry drops all the watch events to simulate missed updates
+type deafRegistry struct {
+	registry.Registry
+}
+
+type deafWatcher struct {
+	exit chan bool
+	once sync.Once
+}
+
+func (w *deafWatcher) Next() (*registry.Result, error) {
+	<-w.exit
+	return nil, registry.ErrWatcherStopped
+}
+
+func (w *deafWatcher) Stop() {
+	w.once.Do(func() { close(w.exit) })
+}
+
+func (d *deafRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	return &deafWatcher{exit: make(chan bool)}, nil
+}
+
+// downRegistry fails every request to simulate a registry outage
+type downRegistry struct {
+	registry.Registry
+}
+
+var errDown = errors.New("registry unavailable")
+
+func (d *downRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	return nil, errDown
+}
+
+func (d *downRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	return nil, errDown
+}
+
+func testService() *registry.Service {
+	return &registry.Service{
+		Name:    "foo",
+		Version: "1.0.0",
+		Nodes: []*registry.Node{
+			{Id: "foo-1", Address: "localhost:9999"},
+			{Id: "foo-2", Address: "localhost:9998"},
+		},
+	}
+}
+
+func TestCacheResync(t *testing.T) {
+	r := memory.NewRegistry()
+	if err := r.Register(testService()); err != nil {
+		t.Fatal(err)
+	}
+
+	var mtx sync.Mutex
+	var events []*registry.Event
+
+	c := New(&deafRegistry{r}, WithResync(50*time.Millisecond), WithHook(func(domain string, e *registry.Event) {
+		mtx.Lock()
+		events = append(events, e)
+		mtx.Unlock()
+	}))
+	defer c.Stop()
+
+	services, err := c.GetService("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || len(services[0].Nodes) != 2 {
+		t.Fatalf("Expected 1 service with 2 nodes got %+v", services)
+	}
+
+	// deregister a node, the watcher won't see it
+	srv := testService()
+	srv.Nodes = srv.Nodes[1:]
+	if err := r.Deregister(srv); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	services, err = c.GetService("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || len(services[0].Nodes) != 1 || services[0].Nodes[0].Id != "foo-1" {
+		t.Fatalf("Expected the resync to remove the dead node got %+v", services[0].Nodes)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events got %d", len(events))
+	}
+	if events[0].Type != registry.Create {
+		t.Fatalf("Expected %v got %v", registry.Create, events[0].Type)
+	}
+	if events[1].Type != registry.Update || len(events[1].Service.Nodes) != 1 {
+		t.Fatalf("Expected %v with 1 node got %v", registry.Update, events[1].Type)
+	}
+}
+
+func TestCacheWatchHooks(t *testing.T) {
+	r := memory.NewRegistry()
+	if err := r.Register(testService()); err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan *registry.Event, 10)
+
+	c := New(r, WithResync(0), WithHook(func(domain string, e *registry.Event) {
+		events <- e
+	}))
+	defer c.Stop()
+
+	if _, err := c.GetService("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if e := <-events; e.Type != registry.Create {
+		t.Fatalf("Expected %v got %v", registry.Create, e.Type)
+	}
+
+	// wait for the watcher to start
+	time.Sleep(200 * time.Millisecond)
+
+	if err := r.Deregister(testService()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case e := <-events:
+		if e.Type != registry.Delete {
+			t.Fatalf("Expected %v got %v", registry.Delete, e.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the delete event")
+	}
+}
+
+func TestCacheBackup(t *testing.T) {
+	r := memory.NewRegistry()
+	if err := r.Register(testService()); err != nil {
+		t.Fatal(err)
+	}
+
+	backup := smemory.NewStore()
+
+	c := New(r, WithBackup(backup))
+	if _, err := c.GetService("foo"); err != nil {
+		t.Fatal(err)
+	}
+	c.Stop()
+
+	recs, err := backup.Read(backupKey(registry.DefaultDomain, "foo"))
+	if err != nil {
+		t.Fatalf("Expected the service to be written to the backup: %v", err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("Expected 1 record got %d", len(recs))
+	}
+
+	// restart during an outage, the services are seeded from the backup
+	c = New(&downRegistry{r}, WithBackup(backup))
+
+	services, err := c.GetService("foo")
+	if err != nil {
+		t.Fatalf("Expected the backup to be used: %v", err)
+	}
+	if len(services) != 1 || len(services[0].Nodes) != 2 {
+		t.Fatalf("Expected 1 service with 2 nodes got %+v", services)
+	}
+
+	// unknown services still fail
+	if _, err := c.GetService("bar"); err != errDown {
+		t.Fatalf("Expected %v got %v", errDown, err)
+	}
+
+	c.Stop()
+
+	// deleting a service removes it from the backup
+	c = New(r, WithBackup(backup))
+	defer c.Stop()
+
+	c.(*cache).del(registry.DefaultDomain, "foo")
+	if _, err := backup.Read(backupKey(registry.DefaultDomain, "foo")); err != store.ErrNotFound {
+		t.Fatalf("Expected %v got %v", store.ErrNotFound, err)
+	}
+}
@@ -2,6 +2,8 @@ package cache
 
 import (
 	"time"
+
+	"github.com/micro/go-micro/v3/store"
 )
 
 // WithTTL sets the cache TTL
@@ -10,3 +12,27 @@ func WithTTL(t time.Duration) Option {
 		o.TTL = t
 	}
 }
+
+// WithResync sets the interval at which watched services are reloaded
+// from the registry to correct any missed watch events, zero disables it
+func WithResync(t time.Duration) Option {
+	return func(o *Options) {
+		o.Resync = t
+	}
+}
+
+// WithHook adds a hook which is called when a cached service changes
+func WithHook(h Hook) Option {
+	return func(o *Options) {
+		o.Hooks = append(o.Hooks, h)
+	}
+}
+
+// WithBackup writes the cached services through to the store and seeds the cache
+// from it on startup, so the last known nodes can be used if the registry is
+// unavailable when a service restarts. Use the file store to persist to disk.
+func WithBackup(s store.Store) Option {
+	return func(o *Options) {
+		o.Backup = s
+	}
+}
@@ -0,0 +1,500 @@
+// Package consul provides a consul service registry
+package consul
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/registry"
+	hash "github.com/mitchellh/hashstructure"
+)
+
+const (
+	defaultDomain = "micro"
+	// the default time consul waits before removing a critical node
+	defaultDeregisterAfter = time.Minute
+)
+
+type consulRegistry struct {
+	client  *consul.Client
+	options registry.Options
+
+	// base query options for reads
+	queryOptions *consul.QueryOptions
+
+	// tcp check interval and critical deregister timeout
+	tcpCheck        time.Duration
+	deregisterAfter time.Duration
+
+	sync.RWMutex
+	// hash of the registered nodes by node id
+	register map[string]uint64
+	// the last time the ttl check was passed by node id
+	lastChecked map[string]time.Time
+}
+
+// NewRegistry returns an initialized consul registry
+func NewRegistry(opts ...registry.Option) registry.Registry {
+	c := &consulRegistry{
+		options:     registry.Options{},
+		register:    make(map[string]uint64),
+		lastChecked: make(map[string]time.Time),
+	}
+	configure(c, opts...)
+	return c
+}
+
+func newClient(c *consulRegistry) (*consul.Client, error) {
+	config := consul.DefaultConfig()
+
+	if c.options.Context != nil {
+		if cfg, ok := c.options.Context.Value(configKey{}).(*consul.Config); ok && cfg != nil {
+			config = cfg
+		}
+	}
+
+	// use the first valid address
+	for _, address := range c.options.Addrs {
+		if len(address) == 0 {
+			continue
+		}
+		addr, port, err := net.SplitHostPort(address)
+		if ae, ok := err.(*net.AddrError); ok && ae.Err == "missing port in address" {
+			config.Address = net.JoinHostPort(address, "8500")
+			break
+		} else if err == nil {
+			config.Address = net.JoinHostPort(addr, port)
+			break
+		}
+	}
+
+	if c.options.Secure || c.options.TLSConfig != nil {
+		tlsConfig := c.options.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{
+				InsecureSkipVerify: true,
+			}
+		}
+
+		config.Scheme = "https"
+		if config.Transport != nil {
+			config.Transport.TLSClientConfig = tlsConfig
+		}
+	}
+
+	return consul.NewClient(config)
+}
+
+// configure will setup the registry with new options
+func configure(c *consulRegistry, opts ...registry.Option) error {
+	for _, o := range opts {
+		o(&c.options)
+	}
+
+	if c.options.Timeout == 0 {
+		c.options.Timeout = 5 * time.Second
+	}
+
+	c.queryOptions = &consul.QueryOptions{AllowStale: true}
+	c.tcpCheck = 0
+	c.deregisterAfter = defaultDeregisterAfter
+
+	if c.options.Context != nil {
+		if q, ok := c.options.Context.Value(queryOptionsKey{}).(*consul.QueryOptions); ok {
+			c.queryOptions = q
+		}
+		if t, ok := c.options.Context.Value(tcpCheckKey{}).(time.Duration); ok {
+			c.tcpCheck = t
+		}
+		if t, ok := c.options.Context.Value(deregisterAfterKey{}).(time.Duration); ok {
+			c.deregisterAfter = t
+		}
+	}
+
+	cli, err := newClient(c)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	c.client = cli
+	c.Unlock()
+
+	return nil
+}
+
+// query returns a copy of the base query options bound to the context
+func (c *consulRegistry) query(ctx context.Context) *consul.QueryOptions {
+	q := *c.queryOptions
+	return q.WithContext(ctx)
+}
+
+func (c *consulRegistry) Client() *consul.Client {
+	c.RLock()
+	defer c.RUnlock()
+	return c.client
+}
+
+func (c *consulRegistry) Init(opts ...registry.Option) error {
+	return configure(c, opts...)
+}
+
+func (c *consulRegistry) Options() registry.Options {
+	return c.options
+}
+
+// checkID is the id consul assigns to the single check of a service registration
+func checkID(id string) string {
+	return "service:" + id
+}
+
+func (c *consulRegistry) registerNode(s *registry.Service, node *registry.Node, opts ...registry.RegisterOption) error {
+	// parse the options
+	var options registry.RegisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = defaultDomain
+	}
+
+	// create hash of the node, service version and endpoints
+	h, err := hash.Hash(struct {
+		Version   string
+		Domain    string
+		Node      *registry.Node
+		Endpoints []*registry.Endpoint
+	}{s.Version, options.Domain, node, s.Endpoints}, nil)
+	if err != nil {
+		return err
+	}
+
+	c.RLock()
+	v, ok := c.register[node.Id]
+	lastChecked := c.lastChecked[node.Id]
+	c.RUnlock()
+
+	// the node is unchanged, heartbeat the ttl check instead of registering
+	if ok && v == h {
+		if options.TTL == time.Duration(0) {
+			return nil
+		}
+
+		// only pass the check if we're part way through the ttl
+		if time.Since(lastChecked) <= options.TTL/2 {
+			return nil
+		}
+
+		if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+			logger.Tracef("Passing ttl check for %s id %s", s.Name, node.Id)
+		}
+
+		if err := c.Client().Agent().PassTTL(checkID(node.Id), ""); err == nil {
+			c.Lock()
+			c.lastChecked[node.Id] = time.Now()
+			c.Unlock()
+			return nil
+		}
+
+		// the check is gone, the node needs to be registered again
+		if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+			logger.Tracef("Ttl check not found for %s id %s", s.Name, node.Id)
+		}
+	}
+
+	host, pt, err := net.SplitHostPort(node.Address)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(pt)
+	if err != nil {
+		return err
+	}
+
+	// add the domain to the service metadata so it can be determined when doing wildcard queries
+	meta := make(map[string]string, len(s.Metadata)+1)
+	for k, v := range s.Metadata {
+		meta[k] = v
+	}
+	meta["domain"] = options.Domain
+
+	var check *consul.AgentServiceCheck
+
+	// map the micro ttl heartbeat to a consul ttl check, otherwise fallback to tcp
+	if options.TTL > time.Duration(0) {
+		check = &consul.AgentServiceCheck{
+			TTL:                            options.TTL.String(),
+			DeregisterCriticalServiceAfter: c.deregisterAfter.String(),
+		}
+	} else if c.tcpCheck > time.Duration(0) {
+		check = &consul.AgentServiceCheck{
+			TCP:                            node.Address,
+			Interval:                       c.tcpCheck.String(),
+			DeregisterCriticalServiceAfter: c.deregisterAfter.String(),
+		}
+	}
+
+	reg := &consul.AgentServiceRegistration{
+		ID:      node.Id,
+		Name:    s.Name,
+		Tags:    encodeTags(s, node, options.Domain),
+		Meta:    meta,
+		Port:    port,
+		Address: host,
+		Check:   check,
+	}
+
+	// consul weights are used by its dns interface for load balancing
+	if node.Weight > 0 {
+		reg.Weights = &consul.AgentWeights{Passing: node.Weight, Warning: 1}
+	}
+
+	if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+		logger.Tracef("Registering %s id %s with ttl %v", s.Name, node.Id, options.TTL)
+	}
+
+	if err := c.Client().Agent().ServiceRegister(reg); err != nil {
+		return err
+	}
+
+	// a ttl check starts critical so pass it straight away
+	if options.TTL > time.Duration(0) {
+		if err := c.Client().Agent().PassTTL(checkID(node.Id), ""); err != nil {
+			return err
+		}
+	}
+
+	c.Lock()
+	c.register[node.Id] = h
+	c.lastChecked[node.Id] = time.Now()
+	c.Unlock()
+
+	return nil
+}
+
+func (c *consulRegistry) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	if len(s.Nodes) == 0 {
+		return errors.New("Require at least one node")
+	}
+
+	var gerr error
+
+	// register each node individually
+	for _, node := range s.Nodes {
+		if err := c.registerNode(s, node, opts...); err != nil {
+			gerr = err
+		}
+	}
+
+	return gerr
+}
+
+func (c *consulRegistry) Deregister(s *registry.Service, opts ...registry.DeregisterOption) error {
+	if len(s.Nodes) == 0 {
+		return errors.New("Require at least one node")
+	}
+
+	for _, node := range s.Nodes {
+		c.Lock()
+		delete(c.register, node.Id)
+		delete(c.lastChecked, node.Id)
+		c.Unlock()
+
+		if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+			logger.Tracef("Deregistering %s id %s", s.Name, node.Id)
+		}
+
+		if err := c.Client().Agent().ServiceDeregister(node.Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// healthy returns false if any of the checks for the entry are critical
+func healthy(entry *consul.ServiceEntry) bool {
+	for _, check := range entry.Checks {
+		if check.Status == consul.HealthCritical {
+			return false
+		}
+	}
+	return true
+}
+
+// toServices converts consul service entries to services grouped by version and domain
+func toServices(entries []*consul.ServiceEntry, domain string) []*registry.Service {
+	versions := make(map[string]*registry.Service)
+
+	for _, entry := range entries {
+		if entry.Service == nil || !healthy(entry) {
+			continue
+		}
+
+		version, dom, endpoints, md, health := decodeTags(entry.Service.Tags)
+		if len(dom) == 0 {
+			dom = entry.Service.Meta["domain"]
+		}
+		if len(dom) == 0 {
+			dom = defaultDomain
+		}
+		if domain != registry.WildcardDomain && dom != domain {
+			continue
+		}
+
+		// compose a key of version/domain
+		key := version + dom
+
+		svc, ok := versions[key]
+		if !ok {
+			meta := make(map[string]string, len(entry.Service.Meta))
+			for k, v := range entry.Service.Meta {
+				meta[k] = v
+			}
+			meta["domain"] = dom
+
+			svc = &registry.Service{
+				Name:      entry.Service.Service,
+				Version:   version,
+				Metadata:  meta,
+				Endpoints: endpoints,
+			}
+			versions[key] = svc
+		}
+
+		address := entry.Service.Address
+		if len(address) == 0 && entry.Node != nil {
+			address = entry.Node.Address
+		}
+		md["domain"] = dom
+
+		node := &registry.Node{
+			Id:       entry.Service.ID,
+			Address:  net.JoinHostPort(address, fmt.Sprintf("%d", entry.Service.Port)),
+			Metadata: md,
+			Health:   health,
+		}
+
+		// a weight of one is the consul default
+		if w := entry.Service.Weights.Passing; w > 1 {
+			node.Weight = w
+		}
+
+		svc.Nodes = append(svc.Nodes, node)
+	}
+
+	services := make([]*registry.Service, 0, len(versions))
+	for _, service := range versions {
+		services = append(services, service)
+	}
+
+	return services
+}
+
+func (c *consulRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	// parse the options and fallback to the default domain
+	var options registry.GetOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = defaultDomain
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.options.Timeout)
+	defer cancel()
+
+	entries, _, err := c.Client().Health().Service(name, "", false, c.query(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	services := registry.FilterLabels(toServices(entries, options.Domain), options.Labels)
+	if len(services) == 0 {
+		return nil, registry.ErrNotFound
+	}
+
+	return services, nil
+}
+
+func (c *consulRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	// parse the options
+	var options registry.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = defaultDomain
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.options.Timeout)
+	defer cancel()
+
+	rsp, _, err := c.Client().Catalog().Services(c.query(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]*registry.Service, 0, len(rsp))
+
+	for name, tags := range rsp {
+		// skip the consul service itself
+		if name == "consul" {
+			continue
+		}
+
+		// the catalog returns the tags of every instance of the service
+		if options.Domain != registry.WildcardDomain {
+			_, domain, _, _, _ := decodeTags(tags)
+			if len(domain) > 0 && !hasTag(tags, domainTag+options.Domain) {
+				continue
+			}
+			if len(domain) == 0 && options.Domain != defaultDomain {
+				continue
+			}
+		}
+
+		// the catalog tags are merged across instances so get the service to match the labels
+		if len(options.Labels) > 0 {
+			_, err := c.GetService(name, registry.GetDomain(options.Domain), registry.GetLabels(options.Labels))
+			if err == registry.ErrNotFound {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+		}
+
+		services = append(services, &registry.Service{Name: name})
+	}
+
+	// sort the services
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+
+	return services, nil
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *consulRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	return newConsulWatcher(c, opts...)
+}
+
+func (c *consulRegistry) String() string {
+	return "consul"
+}
@@ -0,0 +1,177 @@
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/micro/go-micro/v3/registry"
+)
+
+func testService() *registry.Service {
+	return &registry.Service{
+		Name:    "foo",
+		Version: "1.0.0",
+		Endpoints: []*registry.Endpoint{
+			{
+				Name:     "Foo.Bar",
+				Request:  &registry.Value{Name: "Request", Type: "Request"},
+				Metadata: map[string]string{"stream": "false"},
+			},
+		},
+		Nodes: []*registry.Node{
+			{
+				Id:       "foo-1",
+				Address:  "10.0.0.1:8080",
+				Metadata: map[string]string{"protocol": "mucp", "region": "eu"},
+			},
+		},
+	}
+}
+
+func TestEncodeTags(t *testing.T) {
+	s := testService()
+
+	tags := encodeTags(s, s.Nodes[0], "foobar")
+	version, domain, endpoints, md, _ := decodeTags(append(tags, "external"))
+
+	if version != s.Version {
+		t.Fatalf("Expected version %s got %s", s.Version, version)
+	}
+	if domain != "foobar" {
+		t.Fatalf("Expected domain foobar got %s", domain)
+	}
+	if len(endpoints) != 1 || endpoints[0].Name != "Foo.Bar" || endpoints[0].Metadata["stream"] != "false" {
+		t.Fatalf("Unexpected endpoints %+v", endpoints)
+	}
+	if md["protocol"] != "mucp" || md["region"] != "eu" {
+		t.Fatalf("Unexpected metadata %+v", md)
+	}
+	if v, ok := md["external"]; !ok || v != "" {
+		t.Fatalf("Expected plain tag to be mapped to metadata got %+v", md)
+	}
+}
+
+func testEntries() []*consul.ServiceEntry {
+	s := testService()
+	return []*consul.ServiceEntry{
+		{
+			Node: &consul.Node{Address: "10.0.0.1"},
+			Service: &consul.AgentService{
+				ID:      "foo-1",
+				Service: "foo",
+				Tags:    encodeTags(s, s.Nodes[0], defaultDomain),
+				Meta:    map[string]string{"domain": defaultDomain},
+				Port:    8080,
+			},
+			Checks: consul.HealthChecks{{Status: consul.HealthPassing}},
+		},
+		{
+			Node: &consul.Node{Address: "10.0.0.2"},
+			Service: &consul.AgentService{
+				ID:      "foo-2",
+				Service: "foo",
+				Tags:    encodeTags(s, s.Nodes[0], defaultDomain),
+				Meta:    map[string]string{"domain": defaultDomain},
+				Port:    8080,
+			},
+			Checks: consul.HealthChecks{{Status: consul.HealthCritical}},
+		},
+		{
+			Node: &consul.Node{Address: "10.0.0.3"},
+			Service: &consul.AgentService{
+				ID:      "foo-3",
+				Service: "foo",
+				Tags:    encodeTags(s, s.Nodes[0], "other"),
+				Meta:    map[string]string{"domain": "other"},
+				Port:    8080,
+			},
+			Checks: consul.HealthChecks{{Status: consul.HealthPassing}},
+		},
+	}
+}
+
+func TestToServices(t *testing.T) {
+	services := toServices(testEntries(), defaultDomain)
+	if len(services) != 1 {
+		t.Fatalf("Expected 1 service got %d", len(services))
+	}
+	if len(services[0].Nodes) != 1 {
+		t.Fatalf("Expected critical node to be filtered got %d nodes", len(services[0].Nodes))
+	}
+	if addr := services[0].Nodes[0].Address; addr != "10.0.0.1:8080" {
+		t.Fatalf("Expected address 10.0.0.1:8080 got %s", addr)
+	}
+
+	services = toServices(testEntries(), registry.WildcardDomain)
+	if len(services) != 2 {
+		t.Fatalf("Expected 2 services across domains got %d", len(services))
+	}
+}
+
+func TestGetService(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health/service/foo" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-Consul-Index", "1")
+		json.NewEncoder(w).Encode(testEntries())
+	}))
+	defer srv.Close()
+
+	r := NewRegistry(registry.Addrs(srv.Listener.Addr().String()))
+
+	services, err := r.GetService("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || services[0].Version != "1.0.0" || len(services[0].Nodes) != 1 {
+		t.Fatalf("Unexpected services %+v", services)
+	}
+
+	if _, err := r.GetService("foo", registry.GetDomain("missing")); err != registry.ErrNotFound {
+		t.Fatalf("Expected %v got %v", registry.ErrNotFound, err)
+	}
+}
+
+func TestWatcherUpdate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cw := &consulWatcher{
+		ctx:      ctx,
+		cancel:   cancel,
+		next:     make(chan *registry.Result, 10),
+		services: make(map[string][]*registry.Service),
+	}
+
+	entries := testEntries()
+	entries[1].Checks[0].Status = consul.HealthPassing
+
+	// both nodes are new
+	cw.update("foo", toServices(entries, defaultDomain))
+	if r := <-cw.next; r.Action != "create" || len(r.Service.Nodes) != 2 {
+		t.Fatalf("Expected create with 2 nodes got %s %d", r.Action, len(r.Service.Nodes))
+	}
+
+	// the second node fails its ttl check
+	entries[1].Checks[0].Status = consul.HealthCritical
+	cw.update("foo", toServices(entries, defaultDomain))
+	if r := <-cw.next; r.Action != "delete" || len(r.Service.Nodes) != 1 || r.Service.Nodes[0].Id != "foo-2" {
+		t.Fatalf("Expected delete of foo-2 got %s %+v", r.Action, r.Service.Nodes)
+	}
+
+	// the service is gone
+	cw.update("foo", nil)
+	if r := <-cw.next; r.Action != "delete" || len(r.Service.Nodes) != 1 {
+		t.Fatalf("Expected delete of the service got %s %+v", r.Action, r.Service.Nodes)
+	}
+
+	if len(cw.next) != 0 {
+		t.Fatalf("Expected no more results got %d", len(cw.next))
+	}
+}
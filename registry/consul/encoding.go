@@ -0,0 +1,116 @@
+package consul
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/micro/go-micro/v3/registry"
+)
+
+// tags are used to carry the data consul has no field for. Node metadata
+// is mapped to plain key=value tags so it can be used in consul queries,
+// everything else is prefixed and the endpoints are compressed.
+const (
+	versionTag  = "v-"
+	domainTag   = "d-"
+	healthTag   = "h-"
+	endpointTag = "e-"
+)
+
+func encode(buf []byte) string {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write(buf)
+	w.Close()
+	return hex.EncodeToString(b.Bytes())
+}
+
+func decode(d string) []byte {
+	hr, err := hex.DecodeString(d)
+	if err != nil {
+		return nil
+	}
+
+	rbuf := bytes.NewReader(hr)
+	zr, err := zlib.NewReader(rbuf)
+	if err != nil {
+		return nil
+	}
+
+	rbytes, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil
+	}
+
+	return rbytes
+}
+
+// encodeTags converts the service version, domain, endpoints and node metadata and health to tags
+func encodeTags(s *registry.Service, node *registry.Node, domain string) []string {
+	tags := []string{
+		versionTag + s.Version,
+		domainTag + domain,
+	}
+	if len(node.Health) > 0 {
+		tags = append(tags, healthTag+node.Health)
+	}
+
+	keys := make([]string, 0, len(node.Metadata))
+	for k := range node.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		tags = append(tags, k+"="+node.Metadata[k])
+	}
+
+	for _, e := range s.Endpoints {
+		b, err := json.Marshal(e)
+		if err != nil {
+			continue
+		}
+		tags = append(tags, endpointTag+encode(b))
+	}
+
+	return tags
+}
+
+// decodeTags extracts the service version, domain, endpoints and node metadata and health from tags.
+// Tags which aren't key=value pairs and weren't set by micro are mapped to metadata keys
+// with an empty value so services registered outside of micro keep their tags.
+func decodeTags(tags []string) (string, string, []*registry.Endpoint, map[string]string, string) {
+	var version, domain, health string
+	var endpoints []*registry.Endpoint
+	md := make(map[string]string)
+
+	for _, tag := range tags {
+		if parts := strings.SplitN(tag, "=", 2); len(parts) == 2 {
+			md[parts[0]] = parts[1]
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(tag, versionTag):
+			version = strings.TrimPrefix(tag, versionTag)
+		case strings.HasPrefix(tag, domainTag):
+			domain = strings.TrimPrefix(tag, domainTag)
+		case strings.HasPrefix(tag, healthTag):
+			health = strings.TrimPrefix(tag, healthTag)
+		case strings.HasPrefix(tag, endpointTag):
+			var e *registry.Endpoint
+			if err := json.Unmarshal(decode(strings.TrimPrefix(tag, endpointTag)), &e); err == nil && e != nil {
+				endpoints = append(endpoints, e)
+			}
+		default:
+			md[tag] = ""
+		}
+	}
+
+	return version, domain, endpoints, md, health
+}
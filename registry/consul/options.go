@@ -0,0 +1,67 @@
+package consul
+
+import (
+	"context"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/micro/go-micro/v3/registry"
+)
+
+type configKey struct{}
+
+type queryOptionsKey struct{}
+
+type tcpCheckKey struct{}
+
+type deregisterAfterKey struct{}
+
+// Config sets the consul client config, overriding the addresses and tls options
+func Config(c *consul.Config) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, configKey{}, c)
+	}
+}
+
+// QueryOptions sets the base query options used to read from consul
+// e.g to set the datacenter or allow stale reads
+func QueryOptions(q *consul.QueryOptions) registry.Option {
+	return func(o *registry.Options) {
+		if q == nil {
+			return
+		}
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, queryOptionsKey{}, q)
+	}
+}
+
+// TCPCheck registers a tcp health check against the node address which consul
+// will run at the given interval. It's only used for the nodes registered without
+// a ttl, the ttl check takes precedence as consul accepts a single service check
+func TCPCheck(t time.Duration) registry.Option {
+	return func(o *registry.Options) {
+		if t <= time.Duration(0) {
+			return
+		}
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, tcpCheckKey{}, t)
+	}
+}
+
+// DeregisterCriticalAfter sets how long a node may fail its health
+// checks before consul removes it. Defaults to a minute.
+func DeregisterCriticalAfter(t time.Duration) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, deregisterAfterKey{}, t)
+	}
+}
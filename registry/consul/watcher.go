@@ -0,0 +1,256 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/registry"
+)
+
+// how long a blocking query waits for a change before returning
+const waitTime = 5 * time.Minute
+
+type consulWatcher struct {
+	r      *consulRegistry
+	wo     registry.WatchOptions
+	ctx    context.Context
+	cancel func()
+	next   chan *registry.Result
+
+	sync.Mutex
+	// the last known services by name
+	services map[string][]*registry.Service
+	// cancel funcs for the per service watches
+	watches map[string]func()
+}
+
+func newConsulWatcher(r *consulRegistry, opts ...registry.WatchOption) (registry.Watcher, error) {
+	var wo registry.WatchOptions
+	for _, o := range opts {
+		o(&wo)
+	}
+	if len(wo.Domain) == 0 {
+		wo.Domain = defaultDomain
+	}
+	if wo.Domain == registry.WildcardDomain && len(wo.Service) > 0 {
+		return nil, errors.New("Cannot watch a service across domains")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cw := &consulWatcher{
+		r:        r,
+		wo:       wo,
+		ctx:      ctx,
+		cancel:   cancel,
+		next:     make(chan *registry.Result, 10),
+		services: make(map[string][]*registry.Service),
+		watches:  make(map[string]func()),
+	}
+
+	if len(wo.Service) > 0 {
+		go cw.watchService(ctx, wo.Service)
+	} else {
+		go cw.watchCatalog()
+	}
+
+	return cw, nil
+}
+
+// wait blocks for the duration unless the watcher is stopped
+func (cw *consulWatcher) wait(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// watchCatalog uses blocking queries on the catalog to start and stop service watches
+func (cw *consulWatcher) watchCatalog() {
+	var index uint64
+
+	for {
+		q := cw.r.query(cw.ctx)
+		q.WaitIndex = index
+		q.WaitTime = waitTime
+
+		rsp, meta, err := cw.r.Client().Catalog().Services(q)
+		if err != nil {
+			if !cw.wait(cw.ctx, time.Second) {
+				return
+			}
+			continue
+		}
+
+		// the index went backwards, consul has been restarted
+		if meta.LastIndex < index {
+			index = 0
+			continue
+		}
+		if meta.LastIndex == index {
+			continue
+		}
+		index = meta.LastIndex
+
+		cw.Lock()
+		// start watching new services
+		for name := range rsp {
+			if name == "consul" {
+				continue
+			}
+			if _, ok := cw.watches[name]; ok {
+				continue
+			}
+			ctx, cancel := context.WithCancel(cw.ctx)
+			cw.watches[name] = cancel
+			go cw.watchService(ctx, name)
+		}
+
+		// stop watching removed services
+		var removed []string
+		for name, cancel := range cw.watches {
+			if _, ok := rsp[name]; ok {
+				continue
+			}
+			cancel()
+			delete(cw.watches, name)
+			removed = append(removed, name)
+		}
+		cw.Unlock()
+
+		for _, name := range removed {
+			cw.update(name, nil)
+		}
+	}
+}
+
+// watchService uses blocking queries on the health endpoint of a single service
+func (cw *consulWatcher) watchService(ctx context.Context, name string) {
+	var index uint64
+
+	for {
+		q := cw.r.query(ctx)
+		q.WaitIndex = index
+		q.WaitTime = waitTime
+
+		entries, meta, err := cw.r.Client().Health().Service(name, "", false, q)
+		if err != nil {
+			if !cw.wait(ctx, time.Second) {
+				return
+			}
+			continue
+		}
+
+		if meta.LastIndex < index {
+			index = 0
+			continue
+		}
+		if meta.LastIndex == index {
+			continue
+		}
+		index = meta.LastIndex
+
+		// the service watch may have been stopped while blocking
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		cw.update(name, toServices(entries, cw.wo.Domain))
+	}
+}
+
+// update diffs the services against the last known state and sends the results
+func (cw *consulWatcher) update(name string, services []*registry.Service) {
+	cw.Lock()
+	old := cw.services[name]
+	if len(services) == 0 {
+		delete(cw.services, name)
+	} else {
+		cw.services[name] = services
+	}
+	cw.Unlock()
+
+	key := func(s *registry.Service) string {
+		return s.Version + s.Metadata["domain"]
+	}
+
+	oldServices := make(map[string]*registry.Service, len(old))
+	for _, s := range old {
+		oldServices[key(s)] = s
+	}
+
+	var results []*registry.Result
+
+	for _, s := range services {
+		o, ok := oldServices[key(s)]
+		if !ok {
+			results = append(results, &registry.Result{Action: "create", Service: s})
+			continue
+		}
+		delete(oldServices, key(s))
+
+		// any nodes which have gone are sent as a delete
+		nodes := make(map[string]*registry.Node, len(s.Nodes))
+		for _, n := range s.Nodes {
+			nodes[n.Id] = n
+		}
+
+		var removed []*registry.Node
+		var changed bool
+
+		for _, n := range o.Nodes {
+			cur, ok := nodes[n.Id]
+			if !ok {
+				removed = append(removed, n)
+				continue
+			}
+			if !reflect.DeepEqual(cur, n) {
+				changed = true
+			}
+			delete(nodes, n.Id)
+		}
+
+		if len(removed) > 0 {
+			rs := *o
+			rs.Nodes = removed
+			results = append(results, &registry.Result{Action: "delete", Service: &rs})
+		}
+
+		if changed || len(nodes) > 0 || !reflect.DeepEqual(s.Endpoints, o.Endpoints) {
+			results = append(results, &registry.Result{Action: "update", Service: s})
+		}
+	}
+
+	// the versions which no longer exist
+	for _, o := range oldServices {
+		results = append(results, &registry.Result{Action: "delete", Service: o})
+	}
+
+	for _, r := range results {
+		select {
+		case cw.next <- r:
+		case <-cw.ctx.Done():
+			return
+		}
+	}
+}
+
+func (cw *consulWatcher) Next() (*registry.Result, error) {
+	select {
+	case <-cw.ctx.Done():
+		return nil, registry.ErrWatcherStopped
+	case r := <-cw.next:
+		return r, nil
+	}
+}
+
+func (cw *consulWatcher) Stop() {
+	cw.cancel()
+}
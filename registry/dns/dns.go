@@ -0,0 +1,444 @@
+// Package dns provides a read only registry which resolves services from dns SRV and A records
+package dns
+
+import (
+	"errors"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/registry"
+	util "github.com/micro/go-micro/v3/util/registry"
+	"github.com/miekg/dns"
+)
+
+const (
+	defaultDomain = "micro"
+	// kubernetes headless services where the domain is the namespace
+	defaultTemplate = "{service}.{domain}.svc.cluster.local"
+	// the port used for A records when there's no SRV record
+	defaultPort = 8080
+	// the lowest ttl we cache records for so zero ttls don't cause a query per lookup
+	minTTL = 5 * time.Second
+)
+
+var (
+	// ErrNoNameservers is returned when there are no nameservers to query
+	ErrNoNameservers = errors.New("no nameservers")
+)
+
+type dnsRegistry struct {
+	options registry.Options
+	client  *dns.Client
+
+	// the naming scheme, port for A records and the known services
+	template    string
+	port        int
+	services    []string
+	nameservers []string
+
+	sync.RWMutex
+	// resolved services keyed by dns name
+	cache map[string]*record
+}
+
+// record is a resolved service cached until the ttl of its dns records expires
+type record struct {
+	name     string
+	domain   string
+	services []*registry.Service
+	expires  time.Time
+}
+
+// NewRegistry returns a registry which resolves services using dns
+func NewRegistry(opts ...registry.Option) registry.Registry {
+	d := &dnsRegistry{
+		cache: make(map[string]*record),
+	}
+	configure(d, opts...)
+	return d
+}
+
+// configure will setup the registry with new options
+func configure(d *dnsRegistry, opts ...registry.Option) error {
+	for _, o := range opts {
+		o(&d.options)
+	}
+
+	if d.options.Timeout == 0 {
+		d.options.Timeout = 5 * time.Second
+	}
+
+	template := defaultTemplate
+	port := defaultPort
+	var services []string
+
+	if d.options.Context != nil {
+		if t, ok := d.options.Context.Value(templateKey{}).(string); ok && len(t) > 0 {
+			template = t
+		}
+		if p, ok := d.options.Context.Value(portKey{}).(int); ok && p > 0 {
+			port = p
+		}
+		if s, ok := d.options.Context.Value(servicesKey{}).([]string); ok {
+			services = s
+		}
+	}
+
+	var nameservers []string
+
+	// use the registry addresses otherwise the system resolvers
+	for _, addr := range d.options.Addrs {
+		if len(addr) == 0 {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, "53")
+		}
+		nameservers = append(nameservers, addr)
+	}
+
+	if len(nameservers) == 0 {
+		if cfg, err := dns.ClientConfigFromFile("/etc/resolv.conf"); err == nil {
+			for _, s := range cfg.Servers {
+				nameservers = append(nameservers, net.JoinHostPort(s, cfg.Port))
+			}
+		}
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	d.client = &dns.Client{Timeout: d.options.Timeout}
+	d.template = template
+	d.port = port
+	d.services = services
+	d.nameservers = nameservers
+	d.cache = make(map[string]*record)
+
+	return nil
+}
+
+// name returns the fully qualified dns name of the service
+func (d *dnsRegistry) name(service, domain string) string {
+	d.RLock()
+	template := d.template
+	d.RUnlock()
+
+	r := strings.NewReplacer("{service}", service, "{domain}", domain)
+	return dns.Fqdn(r.Replace(template))
+}
+
+// exchange sends the query to each nameserver until one answers
+func (d *dnsRegistry) exchange(name string, qtype uint16) (*dns.Msg, error) {
+	d.RLock()
+	client := d.client
+	nameservers := d.nameservers
+	d.RUnlock()
+
+	if len(nameservers) == 0 {
+		return nil, ErrNoNameservers
+	}
+
+	m := new(dns.Msg)
+	m.SetQuestion(name, qtype)
+
+	var gerr error
+
+	for _, ns := range nameservers {
+		rsp, _, err := client.Exchange(m, ns)
+		if err != nil {
+			gerr = err
+			continue
+		}
+		if rsp.Rcode != dns.RcodeSuccess && rsp.Rcode != dns.RcodeNameError {
+			gerr = errors.New(dns.RcodeToString[rsp.Rcode])
+			continue
+		}
+		return rsp, nil
+	}
+
+	return nil, gerr
+}
+
+// addrs returns the A and AAAA records in the records along with the lowest ttl
+func addrs(rrs []dns.RR) (map[string][]string, uint32) {
+	ips := make(map[string][]string)
+	ttl := uint32(math.MaxUint32)
+
+	for _, rr := range rrs {
+		switch r := rr.(type) {
+		case *dns.A:
+			ips[r.Hdr.Name] = append(ips[r.Hdr.Name], r.A.String())
+		case *dns.AAAA:
+			ips[r.Hdr.Name] = append(ips[r.Hdr.Name], r.AAAA.String())
+		default:
+			continue
+		}
+		if rr.Header().Ttl < ttl {
+			ttl = rr.Header().Ttl
+		}
+	}
+
+	return ips, ttl
+}
+
+// resolveHost queries the A and AAAA records of the host, following any cnames
+// returned by a recursive resolver, along with the lowest ttl
+func (d *dnsRegistry) resolveHost(host string) ([]string, uint32, error) {
+	var ips []string
+	ttl := uint32(math.MaxUint32)
+
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		rsp, err := d.exchange(host, qtype)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		found, t := addrs(rsp.Answer)
+		for _, addrs := range found {
+			ips = append(ips, addrs...)
+		}
+		if t < ttl {
+			ttl = t
+		}
+	}
+
+	return ips, ttl, nil
+}
+
+// lookup resolves the nodes of a service from its SRV records falling back to A records
+func (d *dnsRegistry) lookup(service, domain string) ([]*registry.Service, time.Duration, error) {
+	name := d.name(service, domain)
+
+	rsp, err := d.exchange(name, dns.TypeSRV)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// the addresses of the targets are usually in the additional section
+	extra, ttl := addrs(rsp.Extra)
+
+	var nodes []string
+
+	for _, rr := range rsp.Answer {
+		srv, ok := rr.(*dns.SRV)
+		if !ok {
+			continue
+		}
+		if srv.Hdr.Ttl < ttl {
+			ttl = srv.Hdr.Ttl
+		}
+
+		ips, ok := extra[srv.Target]
+		if !ok {
+			var t uint32
+			ips, t, err = d.resolveHost(srv.Target)
+			if err != nil {
+				return nil, 0, err
+			}
+			if t < ttl {
+				ttl = t
+			}
+		}
+
+		for _, ip := range ips {
+			nodes = append(nodes, net.JoinHostPort(ip, strconv.Itoa(int(srv.Port))))
+		}
+	}
+
+	// no SRV records so use the A records of the name with the default port
+	if len(nodes) == 0 {
+		ips, t, err := d.resolveHost(name)
+		if err != nil {
+			return nil, 0, err
+		}
+		if t < ttl {
+			ttl = t
+		}
+
+		d.RLock()
+		port := strconv.Itoa(d.port)
+		d.RUnlock()
+
+		for _, ip := range ips {
+			nodes = append(nodes, net.JoinHostPort(ip, port))
+		}
+	}
+
+	if len(nodes) == 0 {
+		return nil, 0, registry.ErrNotFound
+	}
+
+	sort.Strings(nodes)
+
+	svc := &registry.Service{
+		Name:     service,
+		Metadata: map[string]string{"domain": domain},
+	}
+
+	for _, addr := range nodes {
+		svc.Nodes = append(svc.Nodes, &registry.Node{
+			Id:       service + "-" + addr,
+			Address:  addr,
+			Metadata: map[string]string{"domain": domain},
+		})
+	}
+
+	expiry := time.Duration(ttl) * time.Second
+	if expiry < minTTL {
+		expiry = minTTL
+	}
+
+	return []*registry.Service{svc}, expiry, nil
+}
+
+func (d *dnsRegistry) Init(opts ...registry.Option) error {
+	return configure(d, opts...)
+}
+
+func (d *dnsRegistry) Options() registry.Options {
+	return d.options
+}
+
+// Register is a noop, services are registered by the platform e.g kubernetes
+func (d *dnsRegistry) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	return nil
+}
+
+// Deregister is a noop, services are deregistered by the platform e.g kubernetes
+func (d *dnsRegistry) Deregister(s *registry.Service, opts ...registry.DeregisterOption) error {
+	return nil
+}
+
+func (d *dnsRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	var options registry.GetOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	// dns can't query across domains so the wildcard uses the default
+	if len(options.Domain) == 0 || options.Domain == registry.WildcardDomain {
+		options.Domain = defaultDomain
+	}
+
+	services, err := d.resolve(name, options.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	// only return the nodes matching the labels
+	if len(options.Labels) > 0 {
+		services = registry.FilterLabels(services, options.Labels)
+		if len(services) == 0 {
+			return nil, registry.ErrNotFound
+		}
+	}
+
+	return services, nil
+}
+
+// resolve returns the cached service if the records haven't expired otherwise it's looked up
+func (d *dnsRegistry) resolve(name, domain string) ([]*registry.Service, error) {
+	key := d.name(name, domain)
+
+	d.RLock()
+	rec, ok := d.cache[key]
+	d.RUnlock()
+
+	if ok && time.Now().Before(rec.expires) {
+		return util.Copy(rec.services), nil
+	}
+
+	services, ttl, err := d.lookup(name, domain)
+	if err == registry.ErrNotFound {
+		d.Lock()
+		delete(d.cache, key)
+		d.Unlock()
+		return nil, err
+	} else if err != nil {
+		// serve the stale records rather than failing if the nameservers are unavailable
+		if ok {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Failed to resolve %s, using stale records: %v", key, err)
+			}
+			return util.Copy(rec.services), nil
+		}
+		return nil, err
+	}
+
+	d.Lock()
+	d.cache[key] = &record{
+		name:     name,
+		domain:   domain,
+		services: services,
+		expires:  time.Now().Add(ttl),
+	}
+	d.Unlock()
+
+	return util.Copy(services), nil
+}
+
+// known returns the configured services and those which have been resolved in the domain
+func (d *dnsRegistry) known(domain string) []string {
+	d.RLock()
+	defer d.RUnlock()
+
+	names := make(map[string]bool)
+	for _, name := range d.services {
+		names[name] = true
+	}
+	for _, rec := range d.cache {
+		if domain == registry.WildcardDomain || rec.domain == domain {
+			names[rec.name] = true
+		}
+	}
+
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+
+	return list
+}
+
+// ListServices returns the configured services and those which have been resolved.
+// Only the names are returned, use GetService to resolve the nodes.
+func (d *dnsRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	var options registry.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = defaultDomain
+	}
+
+	var services []*registry.Service
+	for _, name := range d.known(options.Domain) {
+		// resolve the service to match the labels
+		if len(options.Labels) > 0 {
+			_, err := d.GetService(name, registry.GetDomain(options.Domain), registry.GetLabels(options.Labels))
+			if err == registry.ErrNotFound {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		services = append(services, &registry.Service{Name: name})
+	}
+
+	return services, nil
+}
+
+func (d *dnsRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	return newWatcher(d, opts...)
+}
+
+func (d *dnsRegistry) String() string {
+	return "dns"
+}
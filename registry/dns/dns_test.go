@@ -0,0 +1,198 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/miekg/dns"
+)
+
+type testServer struct {
+	*dns.Server
+
+	sync.Mutex
+	queries int
+}
+
+func newTestServer(t *testing.T) *testServer {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := &testServer{}
+
+	started := make(chan bool)
+
+	ts.Server = &dns.Server{
+		PacketConn:        pc,
+		Handler:           dns.HandlerFunc(ts.serve),
+		NotifyStartedFunc: func() { close(started) },
+	}
+
+	go ts.ActivateAndServe()
+	<-started
+
+	return ts
+}
+
+func (ts *testServer) serve(w dns.ResponseWriter, r *dns.Msg) {
+	ts.Lock()
+	ts.queries++
+	ts.Unlock()
+
+	m := new(dns.Msg)
+	m.SetReply(r)
+
+	q := r.Question[0]
+
+	switch {
+	// foo has SRV records with the addresses in the additional section
+	case q.Name == "foo.micro.svc.cluster.local." && q.Qtype == dns.TypeSRV:
+		for i, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+			target := "foo-" + string(rune('0'+i)) + ".foo.micro.svc.cluster.local."
+			m.Answer = append(m.Answer, &dns.SRV{
+				Hdr:    dns.RR_Header{Name: q.Name, Rrtype: dns.TypeSRV, Class: dns.ClassINET, Ttl: 60},
+				Port:   9090,
+				Target: target,
+			})
+			m.Extra = append(m.Extra, &dns.A{
+				Hdr: dns.RR_Header{Name: target, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.ParseIP(ip),
+			})
+		}
+	// bar only has A records
+	case q.Name == "bar.micro.svc.cluster.local." && q.Qtype == dns.TypeA:
+		m.Answer = append(m.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 0},
+			A:   net.ParseIP("10.0.1.1"),
+		})
+	case q.Name == "bar.micro.svc.cluster.local.":
+	default:
+		m.Rcode = dns.RcodeNameError
+	}
+
+	w.WriteMsg(m)
+}
+
+func (ts *testServer) count() int {
+	ts.Lock()
+	defer ts.Unlock()
+	return ts.queries
+}
+
+func TestGetService(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Shutdown()
+
+	r := NewRegistry(registry.Addrs(ts.PacketConn.LocalAddr().String()))
+
+	services, err := r.GetService("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || len(services[0].Nodes) != 2 {
+		t.Fatalf("Expected 1 service with 2 nodes got %+v", services)
+	}
+	if addr := services[0].Nodes[0].Address; addr != "10.0.0.1:9090" {
+		t.Fatalf("Expected address 10.0.0.1:9090 got %s", addr)
+	}
+
+	// the second lookup is served from the cache
+	queries := ts.count()
+	if _, err := r.GetService("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if ts.count() != queries {
+		t.Fatalf("Expected the records to be cached, %d queries made", ts.count()-queries)
+	}
+
+	// bar falls back to A records with the default port
+	services, err = r.GetService("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || len(services[0].Nodes) != 1 || services[0].Nodes[0].Address != "10.0.1.1:8080" {
+		t.Fatalf("Expected bar to resolve to 10.0.1.1:8080 got %+v", services)
+	}
+
+	if _, err := r.GetService("baz"); err != registry.ErrNotFound {
+		t.Fatalf("Expected %v got %v", registry.ErrNotFound, err)
+	}
+
+	list, err := r.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "bar" || list[1].Name != "foo" {
+		t.Fatalf("Expected the resolved services to be listed got %+v", list)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Shutdown()
+
+	r := NewRegistry(
+		registry.Addrs(ts.PacketConn.LocalAddr().String()),
+		Template("{service}.{domain}.svc.cluster.local"),
+		Port(9000),
+	)
+
+	services, err := r.GetService("bar", registry.GetDomain("micro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if services[0].Nodes[0].Address != "10.0.1.1:9000" {
+		t.Fatalf("Expected the configured port got %s", services[0].Nodes[0].Address)
+	}
+
+	if _, err := r.GetService("bar", registry.GetDomain("other")); err != registry.ErrNotFound {
+		t.Fatalf("Expected %v got %v", registry.ErrNotFound, err)
+	}
+}
+
+func TestWatcherUpdate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dw := &dnsWatcher{
+		ctx:      ctx,
+		cancel:   cancel,
+		next:     make(chan *registry.Result, 10),
+		services: make(map[string]*registry.Service),
+	}
+
+	svc := func(addrs ...string) *registry.Service {
+		s := &registry.Service{Name: "foo"}
+		for _, addr := range addrs {
+			s.Nodes = append(s.Nodes, &registry.Node{Id: "foo-" + addr, Address: addr})
+		}
+		return s
+	}
+
+	expect := func(action string, nodes int) {
+		select {
+		case r := <-dw.next:
+			if r.Action != action || len(r.Service.Nodes) != nodes {
+				t.Fatalf("Expected %s with %d nodes got %s %d", action, nodes, r.Action, len(r.Service.Nodes))
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for %s", action)
+		}
+	}
+
+	dw.update("foo", svc("10.0.0.1:8080", "10.0.0.2:8080"))
+	expect("create", 2)
+
+	dw.update("foo", svc("10.0.0.2:8080", "10.0.0.3:8080"))
+	expect("delete", 1)
+	expect("update", 2)
+
+	dw.update("foo", nil)
+	expect("delete", 2)
+}
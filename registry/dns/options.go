@@ -0,0 +1,49 @@
+package dns
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/registry"
+)
+
+type templateKey struct{}
+
+type portKey struct{}
+
+type servicesKey struct{}
+
+// Template sets the naming scheme used to build the dns name of a service. The
+// {service} and {domain} placeholders are replaced with the service name and
+// domain. Defaults to {service}.{domain}.svc.cluster.local which resolves
+// kubernetes headless services where the domain is the namespace. Use
+// {service}.service.consul to resolve from consul dns.
+func Template(t string) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, templateKey{}, t)
+	}
+}
+
+// Port sets the port of nodes resolved from A and AAAA records when there
+// are no SRV records for the service. Defaults to 8080.
+func Port(p int) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, portKey{}, p)
+	}
+}
+
+// Services sets the names of the services to return when listing and to
+// resolve when watching. DNS has no way to enumerate the services in a zone.
+func Services(names ...string) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, servicesKey{}, names)
+	}
+}
@@ -0,0 +1,141 @@
+package dns
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/registry"
+)
+
+// how often the services are resolved, lookups are cached so
+// the nameservers are only queried when the records expire
+const pollInterval = minTTL
+
+type dnsWatcher struct {
+	r      *dnsRegistry
+	wo     registry.WatchOptions
+	ctx    context.Context
+	cancel func()
+	next   chan *registry.Result
+
+	// the last known nodes by service name
+	services map[string]*registry.Service
+}
+
+func newWatcher(r *dnsRegistry, opts ...registry.WatchOption) (registry.Watcher, error) {
+	var wo registry.WatchOptions
+	for _, o := range opts {
+		o(&wo)
+	}
+	if len(wo.Domain) == 0 || wo.Domain == registry.WildcardDomain {
+		wo.Domain = defaultDomain
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	dw := &dnsWatcher{
+		r:        r,
+		wo:       wo,
+		ctx:      ctx,
+		cancel:   cancel,
+		next:     make(chan *registry.Result, 10),
+		services: make(map[string]*registry.Service),
+	}
+
+	go dw.run()
+
+	return dw, nil
+}
+
+// run resolves the watched services until the watcher is stopped
+func (dw *dnsWatcher) run() {
+	t := time.NewTicker(pollInterval)
+	defer t.Stop()
+
+	for {
+		names := []string{dw.wo.Service}
+		if len(dw.wo.Service) == 0 {
+			names = dw.r.known(dw.wo.Domain)
+		}
+
+		for _, name := range names {
+			services, err := dw.r.GetService(name, registry.GetDomain(dw.wo.Domain))
+			if err == registry.ErrNotFound {
+				dw.update(name, nil)
+			} else if err == nil && len(services) > 0 {
+				dw.update(name, services[0])
+			}
+		}
+
+		select {
+		case <-dw.ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// update diffs the service against the last known nodes and sends the results
+func (dw *dnsWatcher) update(name string, service *registry.Service) {
+	old, ok := dw.services[name]
+	if service == nil {
+		delete(dw.services, name)
+	} else {
+		dw.services[name] = service
+	}
+
+	var results []*registry.Result
+
+	switch {
+	case !ok && service == nil:
+		return
+	case !ok:
+		results = append(results, &registry.Result{Action: "create", Service: service})
+	case service == nil:
+		results = append(results, &registry.Result{Action: "delete", Service: old})
+	default:
+		nodes := make(map[string]bool, len(service.Nodes))
+		for _, n := range service.Nodes {
+			nodes[n.Id] = true
+		}
+
+		// any nodes which have gone are sent as a delete
+		var removed []*registry.Node
+		for _, n := range old.Nodes {
+			if !nodes[n.Id] {
+				removed = append(removed, n)
+			}
+			delete(nodes, n.Id)
+		}
+
+		if len(removed) > 0 {
+			rs := *old
+			rs.Nodes = removed
+			results = append(results, &registry.Result{Action: "delete", Service: &rs})
+		}
+		if len(nodes) > 0 {
+			results = append(results, &registry.Result{Action: "update", Service: service})
+		}
+	}
+
+	for _, r := range results {
+		select {
+		case dw.next <- r:
+		case <-dw.ctx.Done():
+			return
+		}
+	}
+}
+
+func (dw *dnsWatcher) Next() (*registry.Result, error) {
+	select {
+	case <-dw.ctx.Done():
+		return nil, registry.ErrWatcherStopped
+	case r := <-dw.next:
+		return r, nil
+	}
+}
+
+func (dw *dnsWatcher) Stop() {
+	dw.cancel()
+}
@@ -524,6 +524,14 @@ func (e *etcdRegistry) GetService(name string, opts ...registry.GetOption) ([]*r
 		services = append(services, service)
 	}
 
+	// only return the nodes matching the labels
+	if len(options.Labels) > 0 {
+		services = registry.FilterLabels(services, options.Labels)
+		if len(services) == 0 {
+			return nil, registry.ErrNotFound
+		}
+	}
+
 	return services, nil
 }
 
@@ -586,6 +594,9 @@ func (e *etcdRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Se
 		services = append(services, service)
 	}
 
+	// only return the services with nodes matching the labels
+	services = registry.FilterLabels(services, options.Labels)
+
 	// sort the services
 	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
 
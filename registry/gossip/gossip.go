@@ -0,0 +1,539 @@
+// Package gossip provides a registry which shares services between the members of a
+// cluster using the SWIM gossip protocol. Unlike mdns it works across subnets, a new
+// member only needs to reach one existing member to join.
+//
+// Each member is authoritative for the services it registers. Changes are pushed to
+// the other members straight away and the full state is exchanged with a random member
+// every push/pull interval, so a missed update converges within that interval. The
+// services of a member which fails are removed once it's declared dead, which takes
+// SuspicionMult * log(N+1) * ProbeInterval.
+package gossip
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/memberlist"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/registry/memory"
+	util "github.com/micro/go-micro/v3/util/registry"
+)
+
+const (
+	defaultDomain = "micro"
+	// the memberlist default is 30 seconds
+	defaultPushPullInterval = 10 * time.Second
+	// how often to retry joining the cluster while we're the only member
+	joinInterval = 10 * time.Second
+)
+
+// registration is a service registered in a domain
+type registration struct {
+	Domain  string            `json:"domain"`
+	Service *registry.Service `json:"service"`
+}
+
+// state is the services registered by a member
+type state struct {
+	Member string `json:"member"`
+	// Version orders the states of a member, it's the time of the last change
+	Version  int64                    `json:"version"`
+	Services map[string]*registration `json:"services"`
+}
+
+// gossip is the state exchanged with the other members
+type gossip struct {
+	States []*state `json:"states"`
+}
+
+type gossipRegistry struct {
+	options registry.Options
+	// the merged view of the services in the cluster
+	store registry.Registry
+
+	sync.RWMutex
+	list *memberlist.Memberlist
+	exit chan bool
+	// the services we registered
+	local *state
+	// the services registered by the other members
+	members map[string]*state
+}
+
+// NewRegistry returns a gossip registry, the addresses are the members to join
+func NewRegistry(opts ...registry.Option) registry.Registry {
+	g := &gossipRegistry{
+		store:   memory.NewRegistry(),
+		local:   &state{Member: uuid.New().String(), Services: make(map[string]*registration)},
+		members: make(map[string]*state),
+	}
+	if err := configure(g, opts...); err != nil {
+		logger.Errorf("Error configuring the gossip registry: %v", err)
+	}
+	return g
+}
+
+// logWriter sends the memberlist logs to the micro logger
+type logWriter struct{}
+
+func (logWriter) Write(b []byte) (int, error) {
+	if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+		logger.Trace(strings.TrimSpace(string(b)))
+	}
+	return len(b), nil
+}
+
+// configure will setup the registry with new options, leaving the cluster and joining again
+func configure(g *gossipRegistry, opts ...registry.Option) error {
+	for _, o := range opts {
+		o(&g.options)
+	}
+
+	if g.options.Timeout == 0 {
+		g.options.Timeout = 5 * time.Second
+	}
+
+	cfg := memberlist.DefaultLANConfig()
+	cfg.PushPullInterval = defaultPushPullInterval
+	cfg.BindPort = 0
+
+	if ctx := g.options.Context; ctx != nil {
+		if c, ok := ctx.Value(configKey{}).(*memberlist.Config); ok && c != nil {
+			cfg = c
+		}
+		if addr, ok := ctx.Value(addressKey{}).(string); ok && len(addr) > 0 {
+			host, port, err := splitHostPort(addr)
+			if err != nil {
+				return err
+			}
+			cfg.BindAddr = host
+			cfg.BindPort = port
+		}
+		if addr, ok := ctx.Value(advertiseKey{}).(string); ok && len(addr) > 0 {
+			host, port, err := splitHostPort(addr)
+			if err != nil {
+				return err
+			}
+			cfg.AdvertiseAddr = host
+			cfg.AdvertisePort = port
+		}
+		if k, ok := ctx.Value(secretKey{}).([]byte); ok && len(k) > 0 {
+			cfg.SecretKey = k
+		}
+		if d, ok := ctx.Value(pushPullKey{}).(time.Duration); ok && d > 0 {
+			cfg.PushPullInterval = d
+		}
+	}
+
+	// the advertised port follows the bind port unless it's set
+	if cfg.AdvertisePort == memberlist.DefaultLANConfig().AdvertisePort {
+		cfg.AdvertisePort = cfg.BindPort
+	}
+
+	cfg.Name = g.local.Member
+	cfg.Delegate = &delegate{g}
+	cfg.Events = &eventDelegate{g}
+	cfg.LogOutput = logWriter{}
+
+	g.Lock()
+	defer g.Unlock()
+
+	// leave the cluster we're in
+	if g.list != nil {
+		close(g.exit)
+		g.list.Leave(g.options.Timeout)
+		g.list.Shutdown()
+		g.list = nil
+	}
+
+	list, err := memberlist.Create(cfg)
+	if err != nil {
+		return err
+	}
+
+	g.list = list
+	g.exit = make(chan bool)
+
+	go g.join(list, g.exit)
+
+	return nil
+}
+
+func splitHostPort(addr string) (string, int, error) {
+	host, pt, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(pt)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, port, nil
+}
+
+// join the members in the registry addresses, retrying while we're on our own
+func (g *gossipRegistry) join(list *memberlist.Memberlist, exit chan bool) {
+	var seeds []string
+	for _, addr := range g.options.Addrs {
+		if len(addr) > 0 {
+			seeds = append(seeds, addr)
+		}
+	}
+	if len(seeds) == 0 {
+		return
+	}
+
+	t := time.NewTicker(joinInterval)
+	defer t.Stop()
+
+	for {
+		if list.NumMembers() < 2 {
+			if _, err := list.Join(seeds); err != nil {
+				logger.Errorf("Error joining the gossip cluster %v: %v", seeds, err)
+			}
+		}
+
+		select {
+		case <-exit:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// key of a registration in the state
+func key(domain string, s *registry.Service) string {
+	return domain + "/" + s.Name + "/" + s.Version
+}
+
+// without returns the nodes which aren't in the del list
+func without(nodes, del []*registry.Node) []*registry.Node {
+	ids := make(map[string]bool, len(del))
+	for _, n := range del {
+		ids[n.Id] = true
+	}
+
+	var remaining []*registry.Node
+	for _, n := range nodes {
+		if !ids[n.Id] {
+			remaining = append(remaining, n)
+		}
+	}
+	return remaining
+}
+
+// encode the local state to send to the other members
+func (g *gossipRegistry) encode(states ...*state) []byte {
+	b, err := json.Marshal(&gossip{States: states})
+	if err != nil {
+		logger.Errorf("Error encoding the gossip state: %v", err)
+		return nil
+	}
+	return b
+}
+
+// send the message to the other members
+func (g *gossipRegistry) send(b []byte, members ...*memberlist.Node) {
+	g.RLock()
+	list := g.list
+	g.RUnlock()
+
+	if list == nil || b == nil {
+		return
+	}
+
+	if len(members) == 0 {
+		members = list.Members()
+	}
+
+	for _, m := range members {
+		if m.Name == g.local.Member {
+			continue
+		}
+		// state rarely fits in a gossip packet so it's sent over tcp
+		go func(m *memberlist.Node) {
+			if err := list.SendReliable(m, b); err != nil && logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Error sending the gossip state to %s: %v", m.Address(), err)
+			}
+		}(m)
+	}
+}
+
+// alive returns true if the member hasn't been declared dead
+func (g *gossipRegistry) alive(name string) bool {
+	g.RLock()
+	list := g.list
+	g.RUnlock()
+
+	if list == nil {
+		return false
+	}
+
+	for _, m := range list.Members() {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// apply a members state if it's newer than the one we have
+func (g *gossipRegistry) apply(s *state) {
+	if s == nil || s.Member == g.local.Member {
+		return
+	}
+
+	// a stale state of a dead member may be passed on by another member
+	if !g.alive(s.Member) {
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	cur, ok := g.members[s.Member]
+	if ok && cur.Version >= s.Version {
+		return
+	}
+	g.members[s.Member] = s
+
+	var old map[string]*registration
+	if ok {
+		old = cur.Services
+	}
+
+	g.sync(old, s.Services)
+}
+
+// remove the services of a member which has left or died
+func (g *gossipRegistry) remove(name string) {
+	g.Lock()
+	defer g.Unlock()
+
+	cur, ok := g.members[name]
+	if !ok {
+		return
+	}
+	delete(g.members, name)
+
+	g.sync(cur.Services, nil)
+}
+
+// sync updates the store with the changes between the old and new registrations of a member
+func (g *gossipRegistry) sync(old, cur map[string]*registration) {
+	for k, o := range old {
+		removed := o.Service.Nodes
+		if n, ok := cur[k]; ok {
+			removed = without(o.Service.Nodes, n.Service.Nodes)
+		}
+		if len(removed) == 0 {
+			continue
+		}
+
+		srv := *o.Service
+		srv.Nodes = removed
+		if err := g.store.Deregister(&srv, registry.DeregisterDomain(o.Domain)); err != nil {
+			logger.Errorf("Error deregistering %s: %v", srv.Name, err)
+		}
+	}
+
+	for k, n := range cur {
+		if o, ok := old[k]; ok && reflect.DeepEqual(o, n) {
+			continue
+		}
+		if err := g.store.Register(n.Service, registry.RegisterDomain(n.Domain)); err != nil {
+			logger.Errorf("Error registering %s: %v", n.Service.Name, err)
+		}
+	}
+}
+
+func (g *gossipRegistry) Init(opts ...registry.Option) error {
+	return configure(g, opts...)
+}
+
+func (g *gossipRegistry) Options() registry.Options {
+	return g.options
+}
+
+func (g *gossipRegistry) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	if len(s.Nodes) == 0 {
+		return errors.New("Require at least one node")
+	}
+
+	var options registry.RegisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = defaultDomain
+	}
+
+	srv := util.CopyService(s)
+	k := key(options.Domain, srv)
+
+	g.Lock()
+	// keep the nodes we previously registered
+	cur, ok := g.local.Services[k]
+	if ok {
+		srv.Nodes = append(srv.Nodes, without(cur.Service.Nodes, srv.Nodes)...)
+	}
+
+	// services are registered on an interval, only gossip changes
+	if ok && reflect.DeepEqual(cur.Service, srv) {
+		g.Unlock()
+		return nil
+	}
+
+	g.local.Services[k] = &registration{Domain: options.Domain, Service: srv}
+	g.local.Version = time.Now().UnixNano()
+	b := g.encode(g.local)
+	g.Unlock()
+
+	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Debugf("Gossiping registration of %s in domain %s", s.Name, options.Domain)
+	}
+
+	if err := g.store.Register(s, registry.RegisterDomain(options.Domain)); err != nil {
+		return err
+	}
+
+	g.send(b)
+
+	return nil
+}
+
+func (g *gossipRegistry) Deregister(s *registry.Service, opts ...registry.DeregisterOption) error {
+	if len(s.Nodes) == 0 {
+		return errors.New("Require at least one node")
+	}
+
+	var options registry.DeregisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = defaultDomain
+	}
+
+	k := key(options.Domain, s)
+
+	g.Lock()
+	cur, ok := g.local.Services[k]
+	if !ok {
+		g.Unlock()
+		return g.store.Deregister(s, registry.DeregisterDomain(options.Domain))
+	}
+
+	if nodes := without(cur.Service.Nodes, s.Nodes); len(nodes) > 0 {
+		srv := *cur.Service
+		srv.Nodes = nodes
+		g.local.Services[k] = &registration{Domain: options.Domain, Service: &srv}
+	} else {
+		delete(g.local.Services, k)
+	}
+
+	g.local.Version = time.Now().UnixNano()
+	b := g.encode(g.local)
+	g.Unlock()
+
+	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Debugf("Gossiping deregistration of %s in domain %s", s.Name, options.Domain)
+	}
+
+	err := g.store.Deregister(s, registry.DeregisterDomain(options.Domain))
+
+	g.send(b)
+
+	return err
+}
+
+func (g *gossipRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	return g.store.GetService(name, opts...)
+}
+
+func (g *gossipRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	return g.store.ListServices(opts...)
+}
+
+func (g *gossipRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	return g.store.Watch(opts...)
+}
+
+func (g *gossipRegistry) String() string {
+	return "gossip"
+}
+
+// delegate hooks the registry state into memberlist
+type delegate struct {
+	g *gossipRegistry
+}
+
+func (d *delegate) NodeMeta(limit int) []byte {
+	return nil
+}
+
+// NotifyMsg receives the state a member sent after it changed
+func (d *delegate) NotifyMsg(b []byte) {
+	var msg gossip
+	if err := json.Unmarshal(b, &msg); err != nil {
+		logger.Errorf("Error decoding the gossip state: %v", err)
+		return
+	}
+	for _, s := range msg.States {
+		d.g.apply(s)
+	}
+}
+
+func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte {
+	return nil
+}
+
+// LocalState sends all the states we know about during a push/pull
+func (d *delegate) LocalState(join bool) []byte {
+	d.g.RLock()
+	defer d.g.RUnlock()
+
+	states := []*state{d.g.local}
+	for _, s := range d.g.members {
+		states = append(states, s)
+	}
+
+	return d.g.encode(states...)
+}
+
+func (d *delegate) MergeRemoteState(b []byte, join bool) {
+	d.NotifyMsg(b)
+}
+
+// eventDelegate is notified of members joining and leaving
+type eventDelegate struct {
+	g *gossipRegistry
+}
+
+// NotifyJoin sends our state to the new member, it's called with the memberlist locked
+func (e *eventDelegate) NotifyJoin(n *memberlist.Node) {
+	if n.Name == e.g.local.Member {
+		return
+	}
+
+	e.g.RLock()
+	b := e.g.encode(e.g.local)
+	e.g.RUnlock()
+
+	e.g.send(b, n)
+}
+
+// NotifyLeave removes the services of the member, it's called with the memberlist locked
+func (e *eventDelegate) NotifyLeave(n *memberlist.Node) {
+	go e.g.remove(n.Name)
+}
+
+func (e *eventDelegate) NotifyUpdate(n *memberlist.Node) {}
@@ -0,0 +1,120 @@
+package gossip
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/registry"
+)
+
+func newTestRegistry(t *testing.T, opts ...registry.Option) *gossipRegistry {
+	opts = append([]registry.Option{Address("127.0.0.1:0")}, opts...)
+	g := NewRegistry(opts...).(*gossipRegistry)
+	if g.list == nil {
+		t.Fatal("Expected the memberlist to be created")
+	}
+	return g
+}
+
+func shutdown(g *gossipRegistry) {
+	g.Lock()
+	defer g.Unlock()
+	close(g.exit)
+	g.list.Shutdown()
+}
+
+func addr(g *gossipRegistry) string {
+	n := g.list.LocalNode()
+	return fmt.Sprintf("%s:%d", n.Addr, n.Port)
+}
+
+// eventually retries the check until it passes or times out
+func eventually(t *testing.T, msg string, fn func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if fn() {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal(msg)
+}
+
+func TestGossipRegistry(t *testing.T) {
+	r1 := newTestRegistry(t)
+	defer shutdown(r1)
+
+	srv := &registry.Service{
+		Name:    "foo",
+		Version: "1.0.0",
+		Nodes:   []*registry.Node{{Id: "foo-1", Address: "10.0.0.1:8080"}},
+	}
+
+	// register before the second member joins so it's synced on join
+	if err := r1.Register(srv); err != nil {
+		t.Fatal(err)
+	}
+
+	r2 := newTestRegistry(t, registry.Addrs(addr(r1)))
+	defer shutdown(r2)
+
+	eventually(t, "Expected the service to be gossiped to the new member", func() bool {
+		services, err := r2.GetService("foo")
+		return err == nil && len(services) == 1 && len(services[0].Nodes) == 1
+	})
+
+	// register a service after joining
+	bar := &registry.Service{
+		Name:    "bar",
+		Version: "1.0.0",
+		Nodes:   []*registry.Node{{Id: "bar-1", Address: "10.0.0.2:8080"}},
+	}
+	if err := r2.Register(bar, registry.RegisterDomain("other")); err != nil {
+		t.Fatal(err)
+	}
+
+	eventually(t, "Expected the service to be gossiped to the existing member", func() bool {
+		_, err := r1.GetService("bar", registry.GetDomain("other"))
+		return err == nil
+	})
+
+	if err := r1.Deregister(srv); err != nil {
+		t.Fatal(err)
+	}
+
+	eventually(t, "Expected the deregistration to be gossiped", func() bool {
+		_, err := r2.GetService("foo")
+		return err == registry.ErrNotFound
+	})
+
+	// the services of a member are removed when it leaves
+	r2.list.Leave(time.Second)
+
+	eventually(t, "Expected the services of the member to be removed when it left", func() bool {
+		_, err := r1.GetService("bar", registry.GetDomain("other"))
+		return err == registry.ErrNotFound
+	})
+}
+
+func TestGossipEncryption(t *testing.T) {
+	key := []byte("0123456789abcdef")
+
+	r1 := newTestRegistry(t, SecretKey(key))
+	defer shutdown(r1)
+
+	// a member without the key can't join
+	r2 := newTestRegistry(t)
+	defer shutdown(r2)
+
+	if _, err := r2.list.Join([]string{addr(r1)}); err == nil {
+		t.Fatal("Expected a member without the key to fail to join")
+	}
+
+	r3 := newTestRegistry(t, SecretKey(key), registry.Addrs(addr(r1)))
+	defer shutdown(r3)
+
+	eventually(t, "Expected a member with the key to join", func() bool {
+		return r1.list.NumMembers() == 2
+	})
+}
@@ -0,0 +1,72 @@
+package gossip
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"github.com/micro/go-micro/v3/registry"
+)
+
+type configKey struct{}
+
+type addressKey struct{}
+
+type advertiseKey struct{}
+
+type secretKey struct{}
+
+type pushPullKey struct{}
+
+// Config sets the base memberlist config, the name, delegates and
+// addresses are overridden by the registry
+func Config(c *memberlist.Config) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, configKey{}, c)
+	}
+}
+
+// Address to bind to for gossip traffic, host:port. Defaults to a random port.
+func Address(addr string) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, addressKey{}, addr)
+	}
+}
+
+// Advertise sets the address advertised to the other members, host:port
+func Advertise(addr string) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, advertiseKey{}, addr)
+	}
+}
+
+// SecretKey encrypts the gossip traffic with AES. The key must be 16, 24 or 32
+// bytes long and shared by all the members of the cluster.
+func SecretKey(k []byte) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, secretKey{}, k)
+	}
+}
+
+// PushPullInterval sets how often the full state is synced with a random member.
+// It is the upper bound on how long a missed update takes to converge.
+func PushPullInterval(d time.Duration) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, pushPullKey{}, d)
+	}
+}
@@ -0,0 +1,164 @@
+package registry
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalidLabelSelector is returned when a label selector can't be parsed
+	ErrInvalidLabelSelector = errors.New("invalid label selector")
+)
+
+// Operator of a label requirement
+type Operator string
+
+const (
+	// LabelEquals requires the label to have the value
+	LabelEquals Operator = "="
+	// LabelNotEquals requires the label to be missing or have a different value
+	LabelNotEquals Operator = "!="
+	// LabelExists requires the label to be set
+	LabelExists Operator = "exists"
+	// LabelNotExists requires the label not to be set
+	LabelNotExists Operator = "!"
+)
+
+// Requirement is a single condition of a label selector
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Value    string
+}
+
+// LabelSelector matches the metadata of services and nodes. All the requirements must match.
+type LabelSelector []Requirement
+
+// ParseLabelSelector parses a comma separated list of requirements e.g region=eu, tier!=canary.
+// A key on its own requires the label to be set and !key requires it not to be.
+func ParseLabelSelector(s string) (LabelSelector, error) {
+	var sel LabelSelector
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+
+		var req Requirement
+
+		switch {
+		case strings.Contains(part, "!="):
+			kv := strings.SplitN(part, "!=", 2)
+			req = Requirement{Key: kv[0], Operator: LabelNotEquals, Value: kv[1]}
+		case strings.Contains(part, "=="):
+			kv := strings.SplitN(part, "==", 2)
+			req = Requirement{Key: kv[0], Operator: LabelEquals, Value: kv[1]}
+		case strings.Contains(part, "="):
+			kv := strings.SplitN(part, "=", 2)
+			req = Requirement{Key: kv[0], Operator: LabelEquals, Value: kv[1]}
+		case strings.HasPrefix(part, "!"):
+			req = Requirement{Key: part[1:], Operator: LabelNotExists}
+		default:
+			req = Requirement{Key: part, Operator: LabelExists}
+		}
+
+		req.Key = strings.TrimSpace(req.Key)
+		req.Value = strings.TrimSpace(req.Value)
+
+		if len(req.Key) == 0 || strings.ContainsAny(req.Key, "=! ") {
+			return nil, ErrInvalidLabelSelector
+		}
+
+		sel = append(sel, req)
+	}
+
+	return sel, nil
+}
+
+// Matches returns true if the metadata satisfies all the requirements
+func (l LabelSelector) Matches(md map[string]string) bool {
+	for _, req := range l {
+		v, ok := md[req.Key]
+
+		switch req.Operator {
+		case LabelEquals:
+			if !ok || v != req.Value {
+				return false
+			}
+		case LabelNotEquals:
+			if ok && v == req.Value {
+				return false
+			}
+		case LabelExists:
+			if !ok {
+				return false
+			}
+		case LabelNotExists:
+			if ok {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// String returns the selector in the format accepted by ParseLabelSelector
+func (l LabelSelector) String() string {
+	parts := make([]string, 0, len(l))
+
+	for _, req := range l {
+		switch req.Operator {
+		case LabelExists:
+			parts = append(parts, req.Key)
+		case LabelNotExists:
+			parts = append(parts, "!"+req.Key)
+		default:
+			parts = append(parts, req.Key+string(req.Operator)+req.Value)
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// FilterLabels returns the services with only the nodes matching the selector. The node
+// metadata is matched merged over the service metadata. Services without any matching
+// nodes are removed. The services aren't modified, copies are returned.
+func FilterLabels(services []*Service, l LabelSelector) []*Service {
+	if len(l) == 0 {
+		return services
+	}
+
+	var filtered []*Service
+
+	for _, service := range services {
+		var nodes []*Node
+
+		for _, node := range service.Nodes {
+			md := make(map[string]string, len(service.Metadata)+len(node.Metadata))
+			for k, v := range service.Metadata {
+				md[k] = v
+			}
+			for k, v := range node.Metadata {
+				md[k] = v
+			}
+
+			if l.Matches(md) {
+				nodes = append(nodes, node)
+			}
+		}
+
+		if len(nodes) == 0 {
+			continue
+		}
+
+		srv := *service
+		srv.Nodes = nodes
+		filtered = append(filtered, &srv)
+	}
+
+	return filtered
+}
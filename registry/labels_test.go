@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"testing"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	testData := []struct {
+		selector string
+		metadata map[string]string
+		match    bool
+	}{
+		{"region=eu", map[string]string{"region": "eu"}, true},
+		{"region==eu", map[string]string{"region": "eu"}, true},
+		{"region=eu", map[string]string{"region": "us"}, false},
+		{"region=eu, tier=canary", map[string]string{"region": "eu", "tier": "canary"}, true},
+		{"region=eu, tier=canary", map[string]string{"region": "eu"}, false},
+		{"tier!=canary", map[string]string{"tier": "stable"}, true},
+		{"tier!=canary", map[string]string{}, true},
+		{"tier!=canary", map[string]string{"tier": "canary"}, false},
+		{"tier", map[string]string{"tier": ""}, true},
+		{"!tier", map[string]string{"tier": ""}, false},
+		{"", map[string]string{}, true},
+	}
+
+	for _, d := range testData {
+		sel, err := ParseLabelSelector(d.selector)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", d.selector, err)
+		}
+		if m := sel.Matches(d.metadata); m != d.match {
+			t.Errorf("Expected %q to match %v: %v got %v", d.selector, d.metadata, d.match, m)
+		}
+	}
+
+	for _, s := range []string{"=eu", "!", "region eu=1"} {
+		if _, err := ParseLabelSelector(s); err != ErrInvalidLabelSelector {
+			t.Errorf("Expected %v parsing %q got %v", ErrInvalidLabelSelector, s, err)
+		}
+	}
+
+	sel, _ := ParseLabelSelector("region = eu, tier!=canary, gpu, !spot")
+	if s := sel.String(); s != "region=eu,tier!=canary,gpu,!spot" {
+		t.Errorf("Unexpected string %s", s)
+	}
+}
+
+func TestFilterLabels(t *testing.T) {
+	services := []*Service{
+		{
+			Name:     "foo",
+			Version:  "1.0.0",
+			Metadata: map[string]string{"region": "eu"},
+			Nodes: []*Node{
+				{Id: "foo-1", Metadata: map[string]string{"tier": "canary"}},
+				{Id: "foo-2", Metadata: map[string]string{"tier": "stable"}},
+				{Id: "foo-3", Metadata: map[string]string{"tier": "canary", "region": "us"}},
+			},
+		},
+		{
+			Name:     "foo",
+			Version:  "2.0.0",
+			Metadata: map[string]string{"region": "us"},
+			Nodes: []*Node{
+				{Id: "foo-4", Metadata: map[string]string{"tier": "canary"}},
+			},
+		},
+	}
+
+	sel, _ := ParseLabelSelector("region=eu, tier=canary")
+	filtered := FilterLabels(services, sel)
+
+	if len(filtered) != 1 || len(filtered[0].Nodes) != 1 || filtered[0].Nodes[0].Id != "foo-1" {
+		t.Fatalf("Expected only foo-1 to match got %+v", filtered)
+	}
+	if len(services[0].Nodes) != 3 {
+		t.Fatal("Expected the services not to be modified")
+	}
+}
@@ -32,11 +32,16 @@ type mdnsTxt struct {
 	Version   string
 	Endpoints []*registry.Endpoint
 	Metadata  map[string]string
+	Health    string `json:",omitempty"`
+	Weight    int    `json:",omitempty"`
 }
 
 type mdnsEntry struct {
 	id   string
 	node *mdns.Server
+	// the advertised health and weight of the node
+	health string
+	weight int
 }
 
 // services are a key/value map, with the service name as a key and the value being a
@@ -223,11 +228,18 @@ func registerService(service *registry.Service, entries []*mdnsEntry, options re
 	for _, node := range service.Nodes {
 		var seen bool
 
-		for _, entry := range entries {
-			if node.Id == entry.id {
-				seen = true
+		for i, entry := range entries {
+			if node.Id != entry.id {
+				continue
+			}
+			// announce the node again if its health or weight changed
+			if entry.health != node.Health || entry.weight != node.Weight {
+				entry.node.Shutdown()
+				entries = append(entries[:i], entries[i+1:]...)
 				break
 			}
+			seen = true
+			break
 		}
 
 		// this node has already been registered, continue
@@ -240,6 +252,8 @@ func registerService(service *registry.Service, entries []*mdnsEntry, options re
 			Version:   service.Version,
 			Endpoints: service.Endpoints,
 			Metadata:  node.Metadata,
+			Health:    node.Health,
+			Weight:    node.Weight,
 		})
 
 		if err != nil {
@@ -278,7 +292,7 @@ func registerService(service *registry.Service, entries []*mdnsEntry, options re
 			continue
 		}
 
-		entries = append(entries, &mdnsEntry{id: node.Id, node: srv})
+		entries = append(entries, &mdnsEntry{id: node.Id, node: srv, health: node.Health, weight: node.Weight})
 	}
 
 	return entries, lastError
@@ -493,6 +507,8 @@ func (m *mdnsRegistry) GetService(service string, opts ...registry.GetOption) ([
 					Id:       strings.TrimSuffix(e.Name, "."+p.Service+"."+p.Domain+"."),
 					Address:  fmt.Sprintf("%s:%d", addr, e.Port),
 					Metadata: txt.Metadata,
+					Health:   txt.Health,
+					Weight:   txt.Weight,
 				})
 
 				serviceMap[txt.Version] = s
@@ -518,6 +534,14 @@ func (m *mdnsRegistry) GetService(service string, opts ...registry.GetOption) ([
 		services = append(services, service)
 	}
 
+	// only return the nodes matching the labels
+	if len(options.Labels) > 0 {
+		services = registry.FilterLabels(services, options.Labels)
+		if len(services) == 0 {
+			return nil, registry.ErrNotFound
+		}
+	}
+
 	return services, nil
 }
 
@@ -580,6 +604,21 @@ func (m *mdnsRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Se
 	// wait till done
 	<-done
 
+	// the list query only returns names so get each service to match the labels
+	if len(options.Labels) > 0 {
+		var matched []*registry.Service
+		for _, service := range services {
+			srvs, err := m.GetService(service.Name, registry.GetDomain(options.Domain), registry.GetLabels(options.Labels))
+			if err == registry.ErrNotFound {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			matched = append(matched, srvs...)
+		}
+		services = matched
+	}
+
 	return services, nil
 }
 
@@ -739,6 +778,8 @@ func (m *mdnsWatcher) Next() (*registry.Result, error) {
 				Id:       strings.TrimSuffix(e.Name, suffix),
 				Address:  fmt.Sprintf("%s:%d", addr, e.Port),
 				Metadata: txt.Metadata,
+				Health:   txt.Health,
+				Weight:   txt.Weight,
 			})
 
 			return &registry.Result{
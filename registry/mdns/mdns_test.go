@@ -104,6 +104,12 @@ func TestMDNS(t *testing.T) {
 		if node.Address != service.Nodes[0].Address {
 			t.Fatalf("Expected node address %s got %s", service.Nodes[0].Address, node.Address)
 		}
+
+		// no node matches the labels
+		missing := registry.LabelSelector{{Key: "missing", Operator: registry.LabelExists}}
+		if _, err := r.GetService(service.Name, registry.GetLabels(missing)); err != registry.ErrNotFound {
+			t.Fatalf("Expected %v when no node matches the labels, got %v", registry.ErrNotFound, err)
+		}
 	}
 
 	services, err := r.ListServices()
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/util/fault"
+)
+
+// SetFaults sets the faults simulated when sending events to watchers, the events are delayed
+// and each watcher may miss them, so tests can verify how clients behave when discovery misbehaves
+func (m *Registry) SetFaults(f fault.Faults) {
+	m.Lock()
+	m.faults = f
+	m.Unlock()
+}
+
+// Partition splits the views and nodes, identified by their ids, into groups which can't see
+// each other until the partition is healed
+func (m *Registry) Partition(groups ...[]string) {
+	m.partition.Split(groups...)
+}
+
+// Heal the partition so every view sees every node
+func (m *Registry) Heal() {
+	m.partition.Heal()
+}
+
+// View returns the registry as seen by the node with the id, it only returns the nodes it
+// can reach when the registry is partitioned
+func (m *Registry) View(id string) registry.Registry {
+	return &view{Registry: m, id: id}
+}
+
+// view of the registry from a node
+type view struct {
+	*Registry
+	id string
+}
+
+func (v *view) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	srvs, err := v.Registry.GetService(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	srvs = v.filter(srvs)
+	if len(srvs) == 0 {
+		return nil, registry.ErrNotFound
+	}
+	return srvs, nil
+}
+
+func (v *view) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	srvs, err := v.Registry.ListServices(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return v.filter(srvs), nil
+}
+
+func (v *view) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	w, err := v.Registry.Watch(opts...)
+	if err != nil {
+		return nil, err
+	}
+	w.(*Watcher).view = v
+	return w, nil
+}
+
+// filter the nodes the view can't reach, leaving out the services none of whose nodes it can
+func (v *view) filter(srvs []*registry.Service) []*registry.Service {
+	var result []*registry.Service
+	for _, s := range srvs {
+		if s = v.reachable(s); s != nil {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// reachable returns a copy of the service with the nodes the view can reach
+func (v *view) reachable(s *registry.Service) *registry.Service {
+	if len(s.Nodes) == 0 {
+		return s
+	}
+
+	var nodes []*registry.Node
+	for _, n := range s.Nodes {
+		if v.partition.Reachable(v.id, n.Id) {
+			nodes = append(nodes, n)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+	if len(nodes) == len(s.Nodes) {
+		return s
+	}
+
+	cp := *s
+	cp.Nodes = nodes
+	return &cp
+}
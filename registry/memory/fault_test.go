@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/util/fault"
+)
+
+func TestRegistryFaults(t *testing.T) {
+	r := NewRegistry().(*Registry)
+
+	if err := r.Register(&registry.Service{
+		Name:    "foo",
+		Version: "latest",
+		Nodes: []*registry.Node{
+			{Id: "foo-1", Address: "10.0.0.1:8080"},
+			{Id: "foo-2", Address: "10.0.0.2:8080"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := func(v registry.Registry) int {
+		srvs, err := v.GetService("foo")
+		if err == registry.ErrNotFound {
+			return 0
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		var n int
+		for _, s := range srvs {
+			n += len(s.Nodes)
+		}
+		return n
+	}
+
+	// the views only see the nodes of their side of the partition
+	r.Partition([]string{"client-1", "foo-1"}, []string{"client-2", "foo-2"})
+	if n := nodes(r.View("client-1")); n != 1 {
+		t.Fatalf("Expected client-1 to see 1 node, got %d", n)
+	}
+	if n := nodes(r.View("client-2")); n != 1 {
+		t.Fatalf("Expected client-2 to see 1 node, got %d", n)
+	}
+	if n := nodes(r.View("client-3")); n != 2 {
+		t.Fatalf("Expected client-3 outside the partition to see 2 nodes, got %d", n)
+	}
+	if n := nodes(r); n != 2 {
+		t.Fatalf("Expected the registry to see 2 nodes, got %d", n)
+	}
+
+	// watchers of a view only get the nodes it reaches, the events
+	// of the registration are sent before the watcher is created
+	time.Sleep(sendEventTime * 5)
+	w, err := r.View("client-1").Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	if err := r.Register(&registry.Service{
+		Name:    "foo",
+		Version: "latest",
+		Nodes:   []*registry.Node{{Id: "foo-3", Address: "10.0.0.3:8080"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Service.Nodes) != 1 || res.Service.Nodes[0].Id != "foo-3" {
+		t.Fatalf("Expected the event of foo-3, got %+v", res.Service.Nodes)
+	}
+
+	r.Heal()
+	if n := nodes(r.View("client-2")); n != 3 {
+		t.Fatalf("Expected client-2 to see 3 nodes once healed, got %d", n)
+	}
+
+	// events are delayed
+	r.SetFaults(fault.Faults{Delay: time.Millisecond * 50})
+	start := time.Now()
+	if err := r.Deregister(&registry.Service{
+		Name:    "foo",
+		Version: "latest",
+		Nodes:   []*registry.Node{{Id: "foo-3"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < time.Millisecond*50 {
+		t.Fatalf("Expected the event to be delayed, got it after %v", d)
+	}
+
+	// dropped events are never sent
+	r.SetFaults(fault.Faults{Drop: 1})
+	if err := r.Deregister(&registry.Service{
+		Name:    "foo",
+		Version: "latest",
+		Nodes:   []*registry.Node{{Id: "foo-2"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan *registry.Result, 1)
+	go func() {
+		if res, err := w.Next(); err == nil {
+			events <- res
+		}
+	}()
+	select {
+	case res := <-events:
+		t.Fatalf("Expected the event to be dropped, got %+v", res)
+	case <-time.After(time.Millisecond * 100):
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/micro/go-micro/v3/logger"
 	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/util/fault"
 )
 
 var (
@@ -37,6 +38,10 @@ type Registry struct {
 	// records is a KV map with domain name as the key and a services map as the value
 	records  map[string]services
 	watchers map[string]*Watcher
+
+	// faults simulated for tests
+	faults    fault.Faults
+	partition fault.Partition
 }
 
 // services is a KV map with service name as the key and a map of records as the value
@@ -97,13 +102,22 @@ func (m *Registry) ttlPrune() {
 
 func (m *Registry) sendEvent(r *registry.Result) {
 	m.RLock()
+	faults := m.faults
 	watchers := make([]*Watcher, 0, len(m.watchers))
 	for _, w := range m.watchers {
 		watchers = append(watchers, w)
 	}
 	m.RUnlock()
 
+	if faults.Delay > 0 {
+		time.Sleep(faults.Delay)
+	}
+
 	for _, w := range watchers {
+		if faults.Dropped() {
+			continue
+		}
+
 		select {
 		case <-w.exit:
 			m.Lock()
@@ -199,11 +213,29 @@ func (m *Registry) Register(s *registry.Service, opts ...registry.RegisterOption
 		go m.sendEvent(&registry.Result{Action: "create", Service: s})
 	}
 
-	var addedNodes bool
+	var addedNodes, updatedMetadata bool
+
+	// merge the metadata of the version e.g the traffic set by a runtime
+	rec := srvs[s.Name][s.Version]
+	if rec.Metadata == nil {
+		rec.Metadata = make(map[string]string)
+	}
+	for k, v := range s.Metadata {
+		if cur, ok := rec.Metadata[k]; !ok || cur != v {
+			rec.Metadata[k] = v
+			updatedMetadata = true
+		}
+	}
 
 	for _, n := range s.Nodes {
 		// check if already exists
-		if _, ok := srvs[s.Name][s.Version].Nodes[n.Id]; ok {
+		if cur, ok := srvs[s.Name][s.Version].Nodes[n.Id]; ok {
+			// the node may have changed its health or weight
+			if cur.Health != n.Health || cur.Weight != n.Weight {
+				cur.Health = n.Health
+				cur.Weight = n.Weight
+				addedNodes = true
+			}
 			continue
 		}
 
@@ -223,6 +255,8 @@ func (m *Registry) Register(s *registry.Service, opts ...registry.RegisterOption
 				Id:       n.Id,
 				Address:  n.Address,
 				Metadata: metadata,
+				Health:   n.Health,
+				Weight:   n.Weight,
 			},
 			TTL:      options.TTL,
 			LastSeen: time.Now(),
@@ -237,6 +271,10 @@ func (m *Registry) Register(s *registry.Service, opts ...registry.RegisterOption
 		}
 		go m.sendEvent(&registry.Result{Action: "update", Service: s})
 	} else {
+		if updatedMetadata {
+			go m.sendEvent(&registry.Result{Action: "update", Service: s})
+		}
+
 		// refresh TTL and timestamp
 		for _, n := range s.Nodes {
 			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
@@ -387,6 +425,14 @@ func (m *Registry) GetService(name string, opts ...registry.GetOption) ([]*regis
 		i++
 	}
 
+	// only return the nodes matching the labels
+	if len(options.Labels) > 0 {
+		result = registry.FilterLabels(result, options.Labels)
+		if len(result) == 0 {
+			return nil, registry.ErrNotFound
+		}
+	}
+
 	return result, nil
 }
 
@@ -431,13 +477,13 @@ func (m *Registry) ListServices(opts ...registry.ListOption) ([]*registry.Servic
 	// serialize the result, each version counts as an individual service
 	var result []*registry.Service
 
-	for domain, service := range services {
+	for _, service := range services {
 		for _, version := range service {
-			result = append(result, recordToService(version, domain))
+			result = append(result, recordToService(version, options.Domain))
 		}
 	}
 
-	return result, nil
+	return registry.FilterLabels(result, options.Labels), nil
 }
 
 func (m *Registry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
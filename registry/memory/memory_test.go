@@ -280,3 +280,92 @@ func TestMemoryWildcard(t *testing.T) {
 		t.Errorf("Expected 2 records, got %v", len(recs))
 	}
 }
+
+func TestMemoryHealth(t *testing.T) {
+	m := NewRegistry()
+	testSrv := &registry.Service{
+		Name:    "foo",
+		Version: "1.0.0",
+		Nodes: []*registry.Node{
+			{Id: "foo-1", Address: "localhost:9999", Weight: 10},
+		},
+	}
+
+	w, err := m.Watch()
+	if err != nil {
+		t.Fatalf("Watch err: %v", err)
+	}
+	defer w.Stop()
+
+	if err := m.Register(testSrv); err != nil {
+		t.Fatalf("Register err: %v", err)
+	}
+
+	// mark the node as draining
+	draining := &registry.Service{
+		Name:    "foo",
+		Version: "1.0.0",
+		Nodes: []*registry.Node{
+			{Id: "foo-1", Address: "localhost:9999", Weight: 10, Health: registry.HealthDraining},
+		},
+	}
+	if err := m.Register(draining); err != nil {
+		t.Fatalf("Register err: %v", err)
+	}
+
+	// events are sent async so can arrive in any order
+	actions := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		res, err := w.Next()
+		if err != nil {
+			t.Fatalf("Next err: %v", err)
+		}
+		actions[res.Action] = true
+	}
+	if !actions["create"] || !actions["update"] {
+		t.Errorf("Expected create and update, got %v", actions)
+	}
+
+	recs, err := m.GetService(testSrv.Name)
+	if err != nil {
+		t.Fatalf("Get err: %v", err)
+	}
+	if node := recs[0].Nodes[0]; node.Health != registry.HealthDraining || node.Weight != 10 {
+		t.Errorf("Expected draining node with weight 10, got %v %v", node.Health, node.Weight)
+	}
+}
+
+func TestMemoryLabels(t *testing.T) {
+	m := NewRegistry()
+	testSrv := &registry.Service{
+		Name:    "foo",
+		Version: "1.0.0",
+		Nodes: []*registry.Node{
+			{Id: "foo-1", Address: "localhost:9999", Metadata: map[string]string{"region": "eu"}},
+			{Id: "foo-2", Address: "localhost:9998", Metadata: map[string]string{"region": "us"}},
+		},
+	}
+
+	if err := m.Register(testSrv); err != nil {
+		t.Fatalf("Register err: %v", err)
+	}
+
+	eu, _ := registry.ParseLabelSelector("region=eu")
+	recs, err := m.GetService(testSrv.Name, registry.GetLabels(eu))
+	if err != nil {
+		t.Fatalf("Get err: %v", err)
+	}
+	if len(recs) != 1 || len(recs[0].Nodes) != 1 || recs[0].Nodes[0].Id != "foo-1" {
+		t.Errorf("Expected only foo-1, got %+v", recs)
+	}
+
+	ap, _ := registry.ParseLabelSelector("region=ap")
+	if _, err := m.GetService(testSrv.Name, registry.GetLabels(ap)); err != registry.ErrNotFound {
+		t.Errorf("Expected %v, got %v", registry.ErrNotFound, err)
+	}
+	if recs, err := m.ListServices(registry.ListLabels(ap)); err != nil {
+		t.Errorf("List err: %v", err)
+	} else if len(recs) != 0 {
+		t.Errorf("Expected 0 records, got %v", len(recs))
+	}
+}
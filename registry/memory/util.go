@@ -80,6 +80,8 @@ func recordToService(r *record, domain string) *registry.Service {
 			Id:       n.Id,
 			Address:  n.Address,
 			Metadata: metadata,
+			Health:   n.Health,
+			Weight:   n.Weight,
 		}
 		i++
 	}
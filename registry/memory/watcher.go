@@ -11,6 +11,8 @@ type Watcher struct {
 	wo   registry.WatchOptions
 	res  chan *registry.Result
 	exit chan bool
+	// view the events are filtered by when the registry is partitioned
+	view *view
 }
 
 func (m *Watcher) Next() (*registry.Result, error) {
@@ -25,6 +27,15 @@ func (m *Watcher) Next() (*registry.Result, error) {
 				continue
 			}
 
+			// only the nodes the view reaches are sent
+			if m.view != nil {
+				srv := m.view.reachable(r.Service)
+				if srv == nil {
+					continue
+				}
+				r = &registry.Result{Action: r.Action, Service: srv}
+			}
+
 			// extract domain from service metadata
 			var domain string
 			if r.Service.Metadata != nil && len(r.Service.Metadata["domain"]) > 0 {
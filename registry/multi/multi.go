@@ -0,0 +1,230 @@
+// Package multi provides a registry federating several registries, e.g. mdns for the services
+// run locally and etcd for the ones of a shared environment, so both can be discovered at once
+package multi
+
+import (
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/registry"
+)
+
+// Options of the federated registry
+type Options struct {
+	// Precedence makes a service be looked up in the registries in order, the first
+	// one knowing the service is used. Otherwise the services of all are merged.
+	Precedence bool
+	// RegisterAll registers the services with every registry rather than the first
+	RegisterAll bool
+}
+
+// Option sets an option of the federated registry
+type Option func(o *Options)
+
+// Precedence looks services up in the registries in order rather than merging them
+func Precedence() Option {
+	return func(o *Options) {
+		o.Precedence = true
+	}
+}
+
+// RegisterAll registers the services with every registry rather than the first
+func RegisterAll() Option {
+	return func(o *Options) {
+		o.RegisterAll = true
+	}
+}
+
+type multi struct {
+	opts       Options
+	registries []registry.Registry
+}
+
+// NewRegistry returns a registry federating the registries. Services are registered with the
+// first registry and, unless Precedence is set, the services found in every registry are merged.
+// A registry failing to answer is skipped so the others can still be used.
+func NewRegistry(registries []registry.Registry, opts ...Option) registry.Registry {
+	var options Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &multi{
+		opts:       options,
+		registries: registries,
+	}
+}
+
+func (m *multi) Init(opts ...registry.Option) error {
+	for _, r := range m.registries {
+		if err := r.Init(opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Options returns the options of the first registry
+func (m *multi) Options() registry.Options {
+	if len(m.registries) == 0 {
+		return registry.Options{}
+	}
+	return m.registries[0].Options()
+}
+
+// registering returns the registries services are registered with
+func (m *multi) registering() []registry.Registry {
+	if m.opts.RegisterAll || len(m.registries) == 0 {
+		return m.registries
+	}
+	return m.registries[:1]
+}
+
+func (m *multi) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	for _, r := range m.registering() {
+		if err := r.Register(s, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *multi) Deregister(s *registry.Service, opts ...registry.DeregisterOption) error {
+	for _, r := range m.registering() {
+		if err := r.Deregister(s, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *multi) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	var services []*registry.Service
+	var lastErr error
+
+	for _, r := range m.registries {
+		srvs, err := r.GetService(name, opts...)
+		if err == registry.ErrNotFound || (err == nil && len(srvs) == 0) {
+			continue
+		} else if err != nil {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Registry %s failed to get %s: %v", r.String(), name, err)
+			}
+			lastErr = err
+			continue
+		}
+
+		if m.opts.Precedence {
+			return srvs, nil
+		}
+		services = merge(services, srvs)
+	}
+
+	if len(services) > 0 {
+		return services, nil
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, registry.ErrNotFound
+}
+
+func (m *multi) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	var services []*registry.Service
+	var lastErr error
+	var answered bool
+
+	// the names of the services listed by a registry with precedence
+	seen := make(map[string]bool)
+
+	for _, r := range m.registries {
+		srvs, err := r.ListServices(opts...)
+		if err != nil {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Registry %s failed to list services: %v", r.String(), err)
+			}
+			lastErr = err
+			continue
+		}
+		answered = true
+
+		if !m.opts.Precedence {
+			services = merge(services, srvs)
+			continue
+		}
+
+		names := make(map[string]bool)
+		for _, s := range srvs {
+			if seen[s.Name] {
+				continue
+			}
+			names[s.Name] = true
+			services = append(services, s)
+		}
+		for name := range names {
+			seen[name] = true
+		}
+	}
+
+	if !answered && lastErr != nil {
+		return nil, lastErr
+	}
+	return services, nil
+}
+
+func (m *multi) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	watchers := make([]registry.Watcher, 0, len(m.registries))
+
+	for _, r := range m.registries {
+		w, err := r.Watch(opts...)
+		if err != nil {
+			for _, w := range watchers {
+				w.Stop()
+			}
+			return nil, err
+		}
+		watchers = append(watchers, w)
+	}
+
+	return newWatcher(watchers), nil
+}
+
+func (m *multi) String() string {
+	return "multi"
+}
+
+// merge adds the services to the list, the nodes of the same version of a service are combined
+func merge(list, services []*registry.Service) []*registry.Service {
+	for _, s := range services {
+		var found bool
+
+		for i, l := range list {
+			if l.Name != s.Name || l.Version != s.Version {
+				continue
+			}
+			found = true
+
+			// copy the service rather than modifying the one returned by the registry
+			srv := *l
+			srv.Nodes = append([]*registry.Node{}, l.Nodes...)
+			for _, n := range s.Nodes {
+				var dup bool
+				for _, ln := range srv.Nodes {
+					if ln.Id == n.Id {
+						dup = true
+						break
+					}
+				}
+				if !dup {
+					srv.Nodes = append(srv.Nodes, n)
+				}
+			}
+			list[i] = &srv
+			break
+		}
+
+		if !found {
+			list = append(list, s)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,128 @@
+package multi
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/registry/memory"
+)
+
+func service(name, version string, nodes ...string) *registry.Service {
+	s := &registry.Service{Name: name, Version: version}
+	for _, n := range nodes {
+		s.Nodes = append(s.Nodes, &registry.Node{Id: n, Address: n + ":8080"})
+	}
+	return s
+}
+
+func nodes(services []*registry.Service) map[string]bool {
+	ids := make(map[string]bool)
+	for _, s := range services {
+		for _, n := range s.Nodes {
+			ids[n.Id] = true
+		}
+	}
+	return ids
+}
+
+func TestMulti(t *testing.T) {
+	local := memory.NewRegistry()
+	shared := memory.NewRegistry()
+
+	local.Register(service("foo", "1", "foo-local"))
+	shared.Register(service("foo", "1", "foo-shared"))
+	shared.Register(service("bar", "1", "bar-shared"))
+
+	// the services of the registries are merged
+	r := NewRegistry([]registry.Registry{local, shared})
+
+	srvs, err := r.GetService("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := nodes(srvs); len(srvs) != 1 || !ids["foo-local"] || !ids["foo-shared"] {
+		t.Fatalf("Expected the nodes of both registries, got %v", ids)
+	}
+
+	srvs, err = r.GetService("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := nodes(srvs); !ids["bar-shared"] {
+		t.Fatalf("Expected the nodes of the shared registry, got %v", ids)
+	}
+
+	if _, err := r.GetService("baz"); err != registry.ErrNotFound {
+		t.Fatalf("Expected %v got %v", registry.ErrNotFound, err)
+	}
+
+	list, err := r.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 services got %d", len(list))
+	}
+
+	// the first registry knowing a service takes precedence
+	r = NewRegistry([]registry.Registry{local, shared}, Precedence())
+
+	srvs, err = r.GetService("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := nodes(srvs); !ids["foo-local"] || ids["foo-shared"] {
+		t.Fatalf("Expected the nodes of the local registry, got %v", ids)
+	}
+
+	srvs, err = r.GetService("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := nodes(srvs); !ids["bar-shared"] {
+		t.Fatalf("Expected the nodes of the shared registry, got %v", ids)
+	}
+
+	// services are registered with the first registry
+	if err := r.Register(service("baz", "1", "baz-local")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := local.GetService("baz"); err != nil {
+		t.Fatalf("Expected baz to be registered locally, got %v", err)
+	}
+	if _, err := shared.GetService("baz"); err != registry.ErrNotFound {
+		t.Fatalf("Expected baz not to be registered with the shared registry, got %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	local := memory.NewRegistry()
+	shared := memory.NewRegistry()
+
+	r := NewRegistry([]registry.Registry{local, shared})
+
+	w, err := r.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go local.Register(service("foo", "1", "foo-local"))
+	go shared.Register(service("bar", "1", "bar-shared"))
+
+	seen := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		res, err := w.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[res.Service.Name] = true
+	}
+	if !seen["foo"] || !seen["bar"] {
+		t.Fatalf("Expected the events of both registries, got %v", seen)
+	}
+
+	w.Stop()
+	if _, err := w.Next(); err != registry.ErrWatcherStopped {
+		t.Fatalf("Expected %v got %v", registry.ErrWatcherStopped, err)
+	}
+}
@@ -0,0 +1,67 @@
+package multi
+
+import (
+	"sync"
+
+	"github.com/micro/go-micro/v3/registry"
+)
+
+type result struct {
+	res *registry.Result
+	err error
+}
+
+// watcher forwards the results of the watchers of every registry
+type watcher struct {
+	watchers []registry.Watcher
+	results  chan result
+	exit     chan bool
+	once     sync.Once
+}
+
+func newWatcher(watchers []registry.Watcher) *watcher {
+	w := &watcher{
+		watchers: watchers,
+		results:  make(chan result),
+		exit:     make(chan bool),
+	}
+	for _, rw := range watchers {
+		go w.run(rw)
+	}
+	return w
+}
+
+func (w *watcher) run(rw registry.Watcher) {
+	for {
+		res, err := rw.Next()
+
+		select {
+		case w.results <- result{res, err}:
+		case <-w.exit:
+			return
+		}
+
+		// the error is returned to the caller which is expected to stop the watcher
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (w *watcher) Next() (*registry.Result, error) {
+	select {
+	case r := <-w.results:
+		return r.res, r.err
+	case <-w.exit:
+		return nil, registry.ErrWatcherStopped
+	}
+}
+
+func (w *watcher) Stop() {
+	w.once.Do(func() {
+		close(w.exit)
+		for _, rw := range w.watchers {
+			rw.Stop()
+		}
+	})
+}
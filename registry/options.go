@@ -46,12 +46,16 @@ type GetOptions struct {
 	Context context.Context
 	// Domain to scope the request to
 	Domain string
+	// Labels the nodes must match
+	Labels LabelSelector
 }
 
 type ListOptions struct {
 	Context context.Context
 	// Domain to scope the request to
 	Domain string
+	// Labels the nodes must match
+	Labels LabelSelector
 }
 
 // Addrs is the registry addresses to use
@@ -142,6 +146,13 @@ func GetDomain(d string) GetOption {
 	}
 }
 
+// GetLabels only returns the nodes matching the label selector
+func GetLabels(l LabelSelector) GetOption {
+	return func(o *GetOptions) {
+		o.Labels = l
+	}
+}
+
 func ListContext(ctx context.Context) ListOption {
 	return func(o *ListOptions) {
 		o.Context = ctx
@@ -153,3 +164,10 @@ func ListDomain(d string) ListOption {
 		o.Domain = d
 	}
 }
+
+// ListLabels only returns the services with nodes matching the label selector
+func ListLabels(l LabelSelector) ListOption {
+	return func(o *ListOptions) {
+		o.Labels = l
+	}
+}
@@ -3,6 +3,8 @@ package registry
 
 import (
 	"errors"
+
+	"github.com/micro/go-micro/v3/selector"
 )
 
 const (
@@ -10,6 +12,22 @@ const (
 	WildcardDomain = "*"
 	// DefaultDomain to use if none was provided in options
 	DefaultDomain = "micro"
+
+	// HealthUp indicates the node is serving requests
+	HealthUp = "up"
+	// HealthDown indicates the node is not serving requests
+	HealthDown = "down"
+	// HealthDraining indicates the node is finishing in flight requests and should not be sent new ones
+	HealthDraining = "draining"
+
+	// MetadataTraffic is the key of the version metadata holding the percentage
+	// of requests routed to the version, it's set by runtimes when deploying
+	MetadataTraffic = "traffic"
+)
+
+var (
+	// DefaultWeight is the weight of a node which doesn't specify one
+	DefaultWeight = selector.DefaultWeight
 )
 
 var (
@@ -45,6 +63,10 @@ type Node struct {
 	Id       string            `json:"id"`
 	Address  string            `json:"address"`
 	Metadata map[string]string `json:"metadata"`
+	// Health of the node, blank is treated as up
+	Health string `json:"health,omitempty"`
+	// Weight of the node relative to the others, zero is treated as the default
+	Weight int `json:"weight,omitempty"`
 }
 
 type Endpoint struct {
@@ -124,26 +124,42 @@ func (r *rtr) manageRoute(route router.Route, action string) error {
 	return nil
 }
 
+// available returns false if the node is down or draining and shouldn't be routed to
+func available(node *registry.Node) bool {
+	return node.Health != registry.HealthDown && node.Health != registry.HealthDraining
+}
+
 // createRoutes turns a service into a list routes basically converting nodes to routes
 func (r *rtr) createRoutes(service *registry.Service, network string) []router.Route {
 	var routes []router.Route
 
 	for _, node := range service.Nodes {
-		routes = append(routes, router.Route{
-			Service:  service.Name,
-			Address:  node.Address,
-			Gateway:  "",
-			Network:  network,
-			Router:   r.options.Id,
-			Link:     router.DefaultLink,
-			Metric:   router.DefaultMetric,
-			Metadata: node.Metadata,
-		})
+		// skip nodes which aren't accepting new requests
+		if !available(node) {
+			continue
+		}
+
+		routes = append(routes, r.createRoute(service, node, network))
 	}
 
 	return routes
 }
 
+// createRoute turns a service node into a route
+func (r *rtr) createRoute(service *registry.Service, node *registry.Node, network string) router.Route {
+	return router.Route{
+		Service:  service.Name,
+		Address:  node.Address,
+		Gateway:  "",
+		Network:  network,
+		Router:   r.options.Id,
+		Link:     router.DefaultLink,
+		Metric:   router.DefaultMetric,
+		Weight:   node.Weight,
+		Metadata: node.Metadata,
+	}
+}
+
 // manageServiceRoutes applies action to all routes of the service.
 // It returns error of the action fails with error.
 func (r *rtr) manageRoutes(service *registry.Service, action, network string) error {
@@ -156,7 +172,7 @@ func (r *rtr) manageRoutes(service *registry.Service, action, network string) er
 	// if its a delete action and there's no nodes
 	// it means we need to wipe out all the routes
 	// for that service
-	if action == "delete" && len(routes) == 0 {
+	if action == "delete" && len(service.Nodes) == 0 {
 		// delete the service entirely
 		r.table.deleteService(service.Name, network)
 		return nil
@@ -170,6 +186,22 @@ func (r *rtr) manageRoutes(service *registry.Service, action, network string) er
 		}
 	}
 
+	if action == "delete" {
+		return nil
+	}
+
+	// remove the routes of nodes which are down or draining
+	for _, node := range service.Nodes {
+		if available(node) {
+			continue
+		}
+		route := r.createRoute(service, node, network)
+		logger.Tracef("Deleting route %v of %s node domain: %v", route, node.Health, network)
+		if err := r.manageRoute(route, "delete"); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
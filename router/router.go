@@ -80,6 +80,8 @@ type Route struct {
 	Link string
 	// Metric is the route cost metric
 	Metric int64
+	// Weight is the relative weight of the node, zero if it has none
+	Weight int
 	// Metadata for the route
 	Metadata map[string]string
 }
@@ -0,0 +1,260 @@
+// Package autoscale adjusts the replicas of runtime services based on their metrics
+package autoscale
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/runtime"
+)
+
+var (
+	// ErrNotSupported is returned when the runtime can't scale services
+	ErrNotSupported = errors.New("runtime does not support scaling")
+	// ErrInvalidReplicas is returned when the replica bounds are invalid
+	ErrInvalidReplicas = errors.New("invalid replica bounds")
+	// ErrInvalidTarget is returned when the target is not positive
+	ErrInvalidTarget = errors.New("target must be positive")
+	// ErrNoMetric is returned when the controller is started without a metric
+	ErrNoMetric = errors.New("metric required")
+)
+
+// Metric is the load of a service, values from the debug stats or a metrics
+// provider are compared against the target of the service to decide its replicas
+type Metric interface {
+	// Read the value of the metric per replica of the service
+	Read(*runtime.Service) (float64, error)
+}
+
+// MetricFunc is an adapter to use a function as a Metric
+type MetricFunc func(*runtime.Service) (float64, error)
+
+// Read calls fn(s)
+func (fn MetricFunc) Read(s *runtime.Service) (float64, error) {
+	return fn(s)
+}
+
+// Controller periodically reads the metric of each service and scales it
+// between its min and max replicas
+type Controller struct {
+	sync.RWMutex
+	opts Options
+
+	scaler   runtime.Scaler
+	services map[string]*target
+	running  bool
+	exit     chan bool
+}
+
+type target struct {
+	service *runtime.Service
+	opts    ScaleOptions
+	// scaled is the last time the service was scaled
+	scaled time.Time
+}
+
+func key(s *runtime.Service, namespace string) string {
+	return fmt.Sprintf("%v/%v:%v", namespace, s.Name, s.Version)
+}
+
+// Scale adds the service to the controller or replaces its options
+func (c *Controller) Scale(s *runtime.Service, opts ...ScaleOption) error {
+	if c.scaler == nil {
+		return ErrNotSupported
+	}
+
+	options := ScaleOptions{
+		Namespace: "default",
+		Min:       1,
+		Max:       1,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.Min < 1 || options.Max < options.Min {
+		return ErrInvalidReplicas
+	}
+	if options.Target <= 0 {
+		return ErrInvalidTarget
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	k := key(s, options.Namespace)
+	if t, ok := c.services[k]; ok {
+		t.opts = options
+		return nil
+	}
+
+	c.services[k] = &target{
+		service: s,
+		opts:    options,
+	}
+
+	return nil
+}
+
+// Remove stops scaling the service, its replicas are left as they are
+func (c *Controller) Remove(s *runtime.Service, opts ...ScaleOption) error {
+	options := ScaleOptions{
+		Namespace: "default",
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	c.Lock()
+	delete(c.services, key(s, options.Namespace))
+	c.Unlock()
+
+	return nil
+}
+
+// desired returns the number of replicas for the value of the metric
+func (c *Controller) desired(opts ScaleOptions, current int, value float64) int {
+	replicas := current
+
+	// scale proportionally to the difference from the target
+	if ratio := value / opts.Target; math.Abs(ratio-1) > c.opts.Tolerance {
+		replicas = int(math.Ceil(float64(current) * ratio))
+	}
+
+	if replicas < opts.Min {
+		replicas = opts.Min
+	}
+	if replicas > opts.Max {
+		replicas = opts.Max
+	}
+
+	return replicas
+}
+
+// check reads the metric of each service and scales the ones outside their target
+func (c *Controller) check() {
+	c.RLock()
+	targets := make([]*target, 0, len(c.services))
+	for _, t := range c.services {
+		targets = append(targets, t)
+	}
+	c.RUnlock()
+
+	for _, t := range targets {
+		c.RLock()
+		opts := t.opts
+		scaled := t.scaled
+		c.RUnlock()
+
+		current, err := c.scaler.Replicas(t.service, runtime.ReadNamespace(opts.Namespace))
+		if err != nil {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Autoscale failed to get the replicas of %s: %v", t.service.Name, err)
+			}
+			continue
+		}
+
+		value, err := c.opts.Metric.Read(t.service)
+		if err != nil {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Autoscale failed to read the metric of %s: %v", t.service.Name, err)
+			}
+			continue
+		}
+
+		replicas := c.desired(opts, current, value)
+
+		switch {
+		case replicas == current:
+			continue
+		case replicas > current && time.Since(scaled) < c.opts.UpCooldown:
+			continue
+		case replicas < current && time.Since(scaled) < c.opts.DownCooldown:
+			continue
+		}
+
+		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+			logger.Infof("Autoscale scaling %s from %d to %d replicas, metric %.2f target %.2f", t.service.Name, current, replicas, value, opts.Target)
+		}
+
+		if err := c.scaler.Scale(t.service, replicas, runtime.UpdateNamespace(opts.Namespace)); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Autoscale failed to scale %s: %v", t.service.Name, err)
+			}
+			continue
+		}
+
+		c.Lock()
+		t.scaled = time.Now()
+		c.Unlock()
+	}
+}
+
+func (c *Controller) run(exit chan bool) {
+	t := time.NewTicker(c.opts.Interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			c.check()
+		case <-exit:
+			return
+		}
+	}
+}
+
+// Start checking the services
+func (c *Controller) Start() error {
+	if c.scaler == nil {
+		return ErrNotSupported
+	}
+	if c.opts.Metric == nil {
+		return ErrNoMetric
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if c.running {
+		return nil
+	}
+
+	c.running = true
+	c.exit = make(chan bool)
+	go c.run(c.exit)
+
+	return nil
+}
+
+// Stop checking the services
+func (c *Controller) Stop() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.running {
+		return nil
+	}
+
+	c.running = false
+	close(c.exit)
+
+	return nil
+}
+
+// NewController returns a controller scaling services in the runtime
+func NewController(opts ...Option) *Controller {
+	options := newOptions(opts...)
+
+	scaler, _ := options.Runtime.(runtime.Scaler)
+
+	return &Controller{
+		opts:     options,
+		scaler:   scaler,
+		services: make(map[string]*target),
+	}
+}
@@ -0,0 +1,99 @@
+package autoscale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/debug/stats"
+	"github.com/micro/go-micro/v3/runtime"
+)
+
+type testRuntime struct {
+	runtime.Runtime
+	replicas int
+	scaled   int
+}
+
+func (r *testRuntime) Replicas(s *runtime.Service, opts ...runtime.ReadOption) (int, error) {
+	return r.replicas, nil
+}
+
+func (r *testRuntime) Scale(s *runtime.Service, replicas int, opts ...runtime.UpdateOption) error {
+	r.replicas = replicas
+	r.scaled++
+	return nil
+}
+
+func TestController(t *testing.T) {
+	var value float64
+	metric := MetricFunc(func(s *runtime.Service) (float64, error) {
+		return value, nil
+	})
+
+	r := &testRuntime{replicas: 1}
+	c := NewController(WithRuntime(r), WithMetric(metric), Cooldown(0, time.Hour))
+
+	svc := &runtime.Service{Name: "foo"}
+	if err := c.Scale(svc, Replicas(2, 1), Target(10)); err != ErrInvalidReplicas {
+		t.Fatalf("Expected invalid replicas got %v", err)
+	}
+	if err := c.Scale(svc, Replicas(1, 5)); err != ErrInvalidTarget {
+		t.Fatalf("Expected invalid target got %v", err)
+	}
+	if err := c.Scale(svc, Replicas(2, 5), Target(10)); err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		value    float64
+		replicas int
+	}{
+		// below the min replicas
+		{10, 2},
+		// scaled proportionally to the target
+		{25, 5},
+		// capped at the max replicas
+		{100, 5},
+		// within the cooldown of the last scale
+		{2, 5},
+	}
+
+	for _, d := range testData {
+		value = d.value
+		c.check()
+		if r.replicas != d.replicas {
+			t.Fatalf("Expected %d replicas for %v got %d", d.replicas, d.value, r.replicas)
+		}
+	}
+
+	// scaled down once the cooldown has passed
+	c.opts.DownCooldown = 0
+	c.check()
+	if r.replicas != 2 {
+		t.Fatalf("Expected the min replicas got %d", r.replicas)
+	}
+
+	// within the tolerance of the target
+	scaled := r.scaled
+	value = 10.5
+	c.check()
+	if r.scaled != scaled {
+		t.Fatal("Expected the service not to be scaled")
+	}
+
+	if err := NewController(WithMetric(metric)).Scale(svc, Target(1)); err != ErrNotSupported {
+		t.Fatalf("Expected scaling not to be supported got %v", err)
+	}
+}
+
+func TestRequestRate(t *testing.T) {
+	prev := &stats.Stat{Timestamp: 100, Requests: 50}
+	cur := &stats.Stat{Timestamp: 110, Requests: 250}
+
+	if v := RequestRate(prev, cur); v != 20 {
+		t.Fatalf("Expected 20 requests per second got %v", v)
+	}
+	if v := RequestRate(nil, cur); v != 0 {
+		t.Fatalf("Expected no rate without a previous stat got %v", v)
+	}
+}
@@ -0,0 +1,110 @@
+package autoscale
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/v3/runtime"
+)
+
+type Options struct {
+	// Runtime the services are scaled in, it must implement runtime.Scaler
+	Runtime runtime.Runtime
+	// Metric the replicas are scaled on
+	Metric Metric
+	// Interval between checks of the metric
+	Interval time.Duration
+	// UpCooldown is the minimum time between scaling a service up
+	UpCooldown time.Duration
+	// DownCooldown is the minimum time between scaling a service down
+	DownCooldown time.Duration
+	// Tolerance of the difference between the metric and the target
+	// within which the service is not scaled e.g 0.1 for 10%
+	Tolerance float64
+}
+
+type Option func(o *Options)
+
+func newOptions(opts ...Option) Options {
+	options := Options{
+		Interval:     30 * time.Second,
+		UpCooldown:   time.Minute,
+		DownCooldown: 5 * time.Minute,
+		Tolerance:    0.1,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
+
+// WithRuntime sets the runtime the services are scaled in
+func WithRuntime(r runtime.Runtime) Option {
+	return func(o *Options) {
+		o.Runtime = r
+	}
+}
+
+// WithMetric sets the metric the replicas are scaled on
+func WithMetric(m Metric) Option {
+	return func(o *Options) {
+		o.Metric = m
+	}
+}
+
+// Interval between checks of the metric
+func Interval(d time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = d
+	}
+}
+
+// Cooldown sets the minimum time after scaling a service before it's scaled up or down again
+func Cooldown(up, down time.Duration) Option {
+	return func(o *Options) {
+		o.UpCooldown = up
+		o.DownCooldown = down
+	}
+}
+
+// Tolerance of the difference between the metric and the target within which the service is not scaled
+func Tolerance(t float64) Option {
+	return func(o *Options) {
+		o.Tolerance = t
+	}
+}
+
+type ScaleOptions struct {
+	// Namespace the service is running in
+	Namespace string
+	// Min is the minimum number of replicas
+	Min int
+	// Max is the maximum number of replicas
+	Max int
+	// Target value of the metric per replica
+	Target float64
+}
+
+type ScaleOption func(o *ScaleOptions)
+
+// ScaleNamespace sets the namespace the service is running in
+func ScaleNamespace(ns string) ScaleOption {
+	return func(o *ScaleOptions) {
+		o.Namespace = ns
+	}
+}
+
+// Replicas sets the bounds of the number of replicas
+func Replicas(min, max int) ScaleOption {
+	return func(o *ScaleOptions) {
+		o.Min = min
+		o.Max = max
+	}
+}
+
+// Target sets the value of the metric per replica, the service is scaled up when
+// the metric is above the target and down when it's below
+func Target(v float64) ScaleOption {
+	return func(o *ScaleOptions) {
+		o.Target = v
+	}
+}
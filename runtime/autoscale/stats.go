@@ -0,0 +1,61 @@
+package autoscale
+
+import (
+	"context"
+	"errors"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/debug/handler"
+	"github.com/micro/go-micro/v3/debug/stats"
+	"github.com/micro/go-micro/v3/runtime"
+)
+
+// ErrNoStats is returned when a service has no stats recorded
+var ErrNoStats = errors.New("no stats")
+
+// Value derives a metric from the current stats snapshot of a replica and
+// the one recorded before it, prev is nil if there's only one snapshot
+type Value func(prev, cur *stats.Stat) float64
+
+// RequestRate is the number of requests per second between the snapshots
+func RequestRate(prev, cur *stats.Stat) float64 {
+	if prev == nil || cur.Timestamp <= prev.Timestamp || cur.Requests < prev.Requests {
+		return 0
+	}
+	return float64(cur.Requests-prev.Requests) / float64(cur.Timestamp-prev.Timestamp)
+}
+
+// Memory is the memory usage in bytes
+func Memory(prev, cur *stats.Stat) float64 {
+	return float64(cur.Memory)
+}
+
+// Threads is the number of go routines
+func Threads(prev, cur *stats.Stat) float64 {
+	return float64(cur.Threads)
+}
+
+// Stats reads the metric from the Debug.Stats endpoint of the service,
+// it must register the debug handler with stats enabled
+func Stats(c client.Client, v Value) Metric {
+	return MetricFunc(func(s *runtime.Service) (float64, error) {
+		req := c.NewRequest(s.Name, "Debug.Stats", &handler.StatsRequest{})
+		rsp := new(handler.StatsResponse)
+		if err := c.Call(context.Background(), req, rsp); err != nil {
+			return 0, err
+		}
+
+		// the last stat is the current snapshot
+		if len(rsp.Stats) == 0 {
+			return 0, ErrNoStats
+		}
+		cur := rsp.Stats[len(rsp.Stats)-1]
+
+		var prev *stats.Stat
+		if len(rsp.Stats) > 1 {
+			prev = rsp.Stats[len(rsp.Stats)-2]
+		}
+
+		return v(prev, cur), nil
+	})
+}
@@ -0,0 +1,107 @@
+package runtime
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/registry"
+)
+
+var (
+	// ErrNoRegistry is returned when a canary is deployed without a registry to split the traffic in
+	ErrNoRegistry = errors.New("registry required to split the traffic")
+	// ErrNoPreviousVersion is returned when there's no version of the service to deploy alongside
+	ErrNoPreviousVersion = errors.New("no previous version of the service")
+)
+
+// Deploy runs the version of the service alongside its other versions and shifts the
+// traffic to it using the blue green or canary strategy of the options. The runtime
+// starts the version with create, which returns once the version is healthy so it can
+// be verified before any traffic is sent to it. The versions which no longer receive
+// traffic are deleted.
+func Deploy(r Runtime, reg registry.Registry, s *Service, create func() error, opts UpdateOptions) error {
+	percent := 100
+	if opts.Strategy == Canary && opts.Canary < 100 {
+		percent = opts.Canary
+	}
+	if percent < 0 {
+		percent = 0
+	}
+	if percent < 100 && reg == nil {
+		return ErrNoRegistry
+	}
+
+	services, err := r.Read(ReadService(s.Name), ReadNamespace(opts.Namespace))
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+	var previous []*Service
+	for _, srv := range services {
+		if srv.Version == s.Version {
+			exists = true
+			continue
+		}
+		previous = append(previous, srv)
+	}
+
+	// the canary may already be running
+	if !exists {
+		if len(previous) == 0 {
+			return ErrNoPreviousVersion
+		}
+		if err := create(); err != nil {
+			return err
+		}
+	}
+
+	if reg != nil {
+		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+			logger.Infof("Runtime routing %d%% of the traffic of %s to version %s", percent, s.Name, s.Version)
+		}
+
+		if err := setTraffic(reg, s, percent, opts.Namespace); err != nil {
+			return err
+		}
+
+		// split the rest of the traffic between the previous versions
+		for i, srv := range previous {
+			share := (100 - percent) / len(previous)
+			if i == 0 {
+				share += (100 - percent) % len(previous)
+			}
+			if err := setTraffic(reg, srv, share, opts.Namespace); err != nil {
+				return err
+			}
+		}
+	}
+
+	if percent < 100 {
+		return nil
+	}
+
+	// the previous versions no longer receive traffic
+	for _, srv := range previous {
+		if err := r.Delete(srv, DeleteNamespace(opts.Namespace)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setTraffic sets the percentage of the requests routed to the version in its registry metadata
+func setTraffic(reg registry.Registry, s *Service, percent int, namespace string) error {
+	var opts []registry.RegisterOption
+	if len(namespace) > 0 && namespace != "default" {
+		opts = append(opts, registry.RegisterDomain(namespace))
+	}
+
+	return reg.Register(&registry.Service{
+		Name:     s.Name,
+		Version:  s.Version,
+		Metadata: map[string]string{registry.MetadataTraffic: strconv.Itoa(percent)},
+	}, opts...)
+}
@@ -0,0 +1,699 @@
+// Package docker is a runtime which runs services as docker containers. It sits between
+// the local runtime, which runs processes, and the kubernetes runtime for clusters.
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/micro/go-micro/v3/build"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/runtime"
+)
+
+// defaultNamespace to use if not provided as an option
+const defaultNamespace = "default"
+
+// labels of the containers, the service metadata is stored with the metadata prefix
+const (
+	labelType      = "micro.type"
+	labelName      = "micro.name"
+	labelVersion   = "micro.version"
+	labelNamespace = "micro.namespace"
+	labelSource    = "micro.source"
+	labelMetadata  = "micro.metadata."
+)
+
+var (
+	// ErrNoImage is returned when neither the service nor the runtime set an image
+	ErrNoImage = errors.New("image required to run the service")
+	// ErrNotFound is returned when there's no container for the service
+	ErrNotFound = errors.New("service not found")
+
+	// characters which aren't valid in container names
+	nameRegex = regexp.MustCompile("[^a-zA-Z0-9_.-]+")
+)
+
+type dockerRuntime struct {
+	sync.RWMutex
+	// options configure runtime
+	options runtime.Options
+	// client of the docker daemon
+	client *docker.Client
+	// indicates if we're running
+	running bool
+	// used to stop the runtime
+	closed chan bool
+}
+
+// containerName returns the name of the container of the service
+func containerName(s *runtime.Service, namespace string) string {
+	name := []string{"micro", namespace, s.Name}
+	if len(s.Version) > 0 {
+		name = append(name, s.Version)
+	}
+	return nameRegex.ReplaceAllString(strings.Join(name, "-"), "-")
+}
+
+// restartPolicy restarts failed services up to the retries, always if not set
+func restartPolicy(retries int) docker.RestartPolicy {
+	if retries > 0 {
+		return docker.RestartOnFailure(retries)
+	}
+	return docker.RestartUnlessStopped()
+}
+
+// setEnv sets the values in the environment, replacing existing values
+func setEnv(env []string, values map[string]string) []string {
+	for k, v := range values {
+		kv := k + "=" + v
+
+		var set bool
+		for i, e := range env {
+			if strings.HasPrefix(e, k+"=") {
+				env[i] = kv
+				set = true
+			}
+		}
+		if !set {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+func (d *dockerRuntime) network() string {
+	if d.options.Context == nil {
+		return ""
+	}
+	name, _ := d.options.Context.Value(networkKey{}).(string)
+	return name
+}
+
+func (d *dockerRuntime) auth() docker.AuthConfiguration {
+	if d.options.Context == nil {
+		return docker.AuthConfiguration{}
+	}
+	auth, _ := d.options.Context.Value(authKey{}).(docker.AuthConfiguration)
+	return auth
+}
+
+// pull pulls the latest image, using the local image if the pull fails
+// e.g when offline. The image is returned with its tag.
+func (d *dockerRuntime) pull(image string) (string, error) {
+	repo, tag := docker.ParseRepositoryTag(image)
+	if len(tag) == 0 {
+		tag = "latest"
+	}
+	image = repo + ":" + tag
+
+	err := d.client.PullImage(docker.PullImageOptions{
+		Repository: repo,
+		Tag:        tag,
+	}, d.auth())
+	if err == nil {
+		return image, nil
+	}
+
+	if _, ierr := d.client.InspectImage(image); ierr != nil {
+		return "", err
+	}
+
+	if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+		logger.Warnf("Runtime failed to pull image %s, using the local image: %v", image, err)
+	}
+	return image, nil
+}
+
+// buildImage is the name of the image built from the source of the service
+func buildImage(s *runtime.Service) string {
+	version := s.Version
+	if len(version) == 0 {
+		version = "latest"
+	}
+	return fmt.Sprintf("micro/%s:%s", s.Name, version)
+}
+
+// build packages the source into an image with the runtime builder
+func (d *dockerRuntime) build(s *runtime.Service, source string, output io.Writer) (string, error) {
+	if output == nil {
+		output = ioutil.Discard
+	}
+
+	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Debugf("Runtime building service %s with %s", s.Name, d.options.Build)
+	}
+
+	pkg, err := d.options.Build.Package(buildImage(s), &build.Source{Path: source}, build.PackageOutput(output))
+	if err != nil {
+		return "", err
+	}
+	return pkg.Path, nil
+}
+
+// list returns the containers with the labels
+func (d *dockerRuntime) list(labels map[string]string) ([]docker.APIContainers, error) {
+	filters := make([]string, 0, len(labels))
+	for k, v := range labels {
+		filters = append(filters, k+"="+v)
+	}
+
+	return d.client.ListContainers(docker.ListContainersOptions{
+		All:     true,
+		Filters: map[string][]string{"label": filters},
+	})
+}
+
+// serviceLabels returns the labels selecting the containers of the service
+func serviceLabels(s *runtime.Service, namespace string) map[string]string {
+	labels := map[string]string{
+		labelNamespace: namespace,
+		labelName:      s.Name,
+	}
+	if len(s.Version) > 0 {
+		labels[labelVersion] = s.Version
+	}
+	return labels
+}
+
+// attach copies the output of the container to the writer
+func (d *dockerRuntime) attach(id string, w io.Writer) {
+	err := d.client.Logs(docker.LogsOptions{
+		Container:    id,
+		OutputStream: w,
+		ErrorStream:  w,
+		Follow:       true,
+		Stdout:       true,
+		Stderr:       true,
+	})
+	if err != nil && logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Debugf("Runtime stopped copying the output of container %s: %v", id, err)
+	}
+}
+
+// Init initializes runtime options
+func (d *dockerRuntime) Init(opts ...runtime.Option) error {
+	d.Lock()
+	defer d.Unlock()
+
+	for _, o := range opts {
+		o(&d.options)
+	}
+
+	return nil
+}
+
+// Create pulls the image and starts a container for the service
+func (d *dockerRuntime) Create(s *runtime.Service, opts ...runtime.CreateOption) error {
+	d.Lock()
+	defer d.Unlock()
+
+	options := runtime.CreateOptions{
+		Type:      d.options.Type,
+		Namespace: defaultNamespace,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// default type if it doesn't exist
+	if len(options.Type) == 0 {
+		options.Type = d.options.Type
+	}
+
+	// default the source if it doesn't exist
+	if len(s.Source) == 0 {
+		s.Source = d.options.Source
+	}
+
+	var err error
+
+	image := options.Image
+	switch {
+	case len(image) > 0:
+		image, err = d.pull(image)
+	case d.options.Build != nil && len(s.Source) > 0:
+		image, err = d.build(s, s.Source, options.Output)
+	case len(d.options.Image) > 0:
+		image, err = d.pull(d.options.Image)
+	default:
+		return ErrNoImage
+	}
+	if err != nil {
+		return err
+	}
+
+	// add the service metadata first so it can't override the labels of the runtime
+	labels := make(map[string]string, len(s.Metadata)+5)
+	for k, v := range s.Metadata {
+		labels[labelMetadata+k] = v
+	}
+	labels[labelType] = options.Type
+	labels[labelName] = s.Name
+	labels[labelVersion] = s.Version
+	labels[labelNamespace] = options.Namespace
+	labels[labelSource] = s.Source
+
+	// docker has no secrets outside of swarm so they're passed in the environment
+	env := setEnv(append([]string{}, options.Env...), options.Secrets)
+
+	config := &docker.Config{
+		Image:  image,
+		Env:    env,
+		Labels: labels,
+	}
+	if len(options.Command) > 0 {
+		config.Entrypoint = options.Command
+	}
+	if len(options.Args) > 0 {
+		config.Cmd = options.Args
+	}
+
+	hostConfig := &docker.HostConfig{
+		RestartPolicy: restartPolicy(options.Retries),
+		NetworkMode:   d.network(),
+	}
+	// the disk limit isn't applied as it's only supported by some storage drivers
+	if r := options.Resources; r != nil {
+		if r.CPU > 0 {
+			// the quota of the millicpu in each period of 100ms
+			hostConfig.CPUPeriod = 100000
+			hostConfig.CPUQuota = int64(r.CPU) * 100
+		}
+		hostConfig.Memory = int64(r.Mem) << 20
+	}
+	if r := options.Requests; r != nil {
+		hostConfig.MemoryReservation = int64(r.Mem) << 20
+	}
+
+	c, err := d.client.CreateContainer(docker.CreateContainerOptions{
+		Name:       containerName(s, options.Namespace),
+		Config:     config,
+		HostConfig: hostConfig,
+	})
+	if err == docker.ErrContainerAlreadyExists {
+		return runtime.ErrAlreadyExists
+	} else if err != nil {
+		return err
+	}
+
+	if err := d.client.StartContainer(c.ID, nil); err != nil {
+		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+			logger.Debugf("Runtime failed to start container %s: %v", c.ID, err)
+		}
+		return err
+	}
+
+	if options.Output != nil {
+		go d.attach(c.ID, options.Output)
+	}
+
+	return nil
+}
+
+// newService returns the service run by the container
+func newService(c *docker.Container) *runtime.Service {
+	labels := c.Config.Labels
+
+	s := &runtime.Service{
+		Name:     labels[labelName],
+		Version:  labels[labelVersion],
+		Source:   labels[labelSource],
+		Metadata: make(map[string]string),
+	}
+
+	for k, v := range labels {
+		if strings.HasPrefix(k, labelMetadata) {
+			s.Metadata[strings.TrimPrefix(k, labelMetadata)] = v
+		}
+	}
+
+	s.Metadata["type"] = labels[labelType]
+	s.Metadata["status"] = c.State.StateString()
+	if !c.State.StartedAt.IsZero() {
+		s.Metadata["started"] = c.State.StartedAt.Format(time.RFC3339)
+	}
+
+	// report why the container stopped
+	if len(c.State.Error) > 0 {
+		s.Metadata["status"] = "error"
+		s.Metadata["error"] = c.State.Error
+	} else if !c.State.Running && c.State.ExitCode != 0 {
+		s.Metadata["status"] = "error"
+		s.Metadata["error"] = fmt.Sprintf("exited with code %d", c.State.ExitCode)
+	}
+
+	return s
+}
+
+// Read returns the services run by the containers
+func (d *dockerRuntime) Read(opts ...runtime.ReadOption) ([]*runtime.Service, error) {
+	d.RLock()
+	defer d.RUnlock()
+
+	options := runtime.ReadOptions{
+		Namespace: defaultNamespace,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	labels := map[string]string{
+		labelNamespace: options.Namespace,
+	}
+	if len(options.Service) > 0 {
+		labels[labelName] = options.Service
+	}
+	if len(options.Version) > 0 {
+		labels[labelVersion] = options.Version
+	}
+	if len(options.Type) > 0 {
+		labels[labelType] = options.Type
+	}
+
+	containers, err := d.list(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]*runtime.Service, 0, len(containers))
+	for _, c := range containers {
+		// the list doesn't include the state of the container
+		ci, err := d.client.InspectContainer(c.ID)
+		if _, ok := err.(*docker.NoSuchContainer); ok {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		services = append(services, newService(ci))
+	}
+
+	return services, nil
+}
+
+// recreate replaces the container with one running the latest image. Docker doesn't
+// support rolling updates of containers so the service is briefly unavailable.
+func (d *dockerRuntime) recreate(id string, s *runtime.Service, options runtime.UpdateOptions) error {
+	c, err := d.client.InspectContainer(id)
+	if err != nil {
+		return err
+	}
+
+	// rebuild the image from the source if it was built by the runtime
+	built := &runtime.Service{
+		Name:    c.Config.Labels[labelName],
+		Version: c.Config.Labels[labelVersion],
+		Source:  c.Config.Labels[labelSource],
+	}
+
+	var image string
+	switch {
+	case len(options.Image) > 0:
+		image, err = d.pull(options.Image)
+	case d.options.Build != nil && len(built.Source) > 0 && c.Config.Image == buildImage(built):
+		image, err = d.build(built, built.Source, nil)
+	default:
+		image, err = d.pull(c.Config.Image)
+	}
+	if err != nil {
+		return err
+	}
+
+	config := c.Config
+	config.Image = image
+	// the hostname is generated from the container id
+	config.Hostname = ""
+	config.Env = setEnv(config.Env, options.Secrets)
+	for k, v := range s.Metadata {
+		config.Labels[labelMetadata+k] = v
+	}
+
+	if err := d.client.RemoveContainer(docker.RemoveContainerOptions{ID: c.ID, Force: true}); err != nil {
+		return err
+	}
+
+	nc, err := d.client.CreateContainer(docker.CreateContainerOptions{
+		Name:       strings.TrimPrefix(c.Name, "/"),
+		Config:     config,
+		HostConfig: c.HostConfig,
+	})
+	if err != nil {
+		return err
+	}
+
+	return d.client.StartContainer(nc.ID, nil)
+}
+
+// Update recreates the containers of the service with the latest image
+func (d *dockerRuntime) Update(s *runtime.Service, opts ...runtime.UpdateOption) error {
+	d.Lock()
+	defer d.Unlock()
+
+	options := runtime.UpdateOptions{
+		Namespace: defaultNamespace,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	containers, err := d.list(serviceLabels(s, options.Namespace))
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		return ErrNotFound
+	}
+
+	for _, c := range containers {
+		if err := d.recreate(c.ID, s, options); err != nil {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Runtime failed to recreate container %s: %v", c.ID, err)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the containers of the service
+func (d *dockerRuntime) Delete(s *runtime.Service, opts ...runtime.DeleteOption) error {
+	d.Lock()
+	defer d.Unlock()
+
+	options := runtime.DeleteOptions{
+		Namespace: defaultNamespace,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	containers, err := d.list(serviceLabels(s, options.Namespace))
+	if err != nil {
+		return err
+	}
+
+	for _, c := range containers {
+		if err := d.client.RemoveContainer(docker.RemoveContainerOptions{ID: c.ID, Force: true}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Exec runs the command in the container of the service
+func (d *dockerRuntime) Exec(s *runtime.Service, cmd []string, opts ...runtime.ExecOption) error {
+	options := runtime.ExecOptions{
+		Namespace: defaultNamespace,
+		Context:   context.Background(),
+		Stdout:    ioutil.Discard,
+		Stderr:    ioutil.Discard,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if len(cmd) == 0 {
+		return runtime.ErrNoCommand
+	}
+
+	containers, err := d.list(serviceLabels(s, options.Namespace))
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		return ErrNotFound
+	}
+
+	exec, err := d.client.CreateExec(docker.CreateExecOptions{
+		Container:    containers[0].ID,
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+		Context:      options.Context,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := d.client.StartExec(exec.ID, docker.StartExecOptions{
+		OutputStream: options.Stdout,
+		ErrorStream:  options.Stderr,
+		Context:      options.Context,
+	}); err != nil {
+		return err
+	}
+
+	status, err := d.client.InspectExec(exec.ID)
+	if err != nil {
+		return err
+	}
+	if status.ExitCode != 0 {
+		return &runtime.ExitError{Code: status.ExitCode}
+	}
+
+	return nil
+}
+
+// run runs the runtime management loop
+func (d *dockerRuntime) run(events <-chan runtime.Event) {
+	for {
+		select {
+		case event := <-events:
+			// NOTE: we only handle Update events for now
+			if event.Type != runtime.Update || event.Service == nil {
+				continue
+			}
+
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Runtime updating service %s", event.Service.Name)
+			}
+
+			namespace := defaultNamespace
+			if event.Options != nil && len(event.Options.Namespace) > 0 {
+				namespace = event.Options.Namespace
+			}
+
+			if err := d.Update(event.Service, runtime.UpdateNamespace(namespace)); err != nil {
+				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+					logger.Debugf("Runtime failed to update service %s: %v", event.Service.Name, err)
+				}
+			}
+		case <-d.closed:
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Runtime stopped")
+			}
+			return
+		}
+	}
+}
+
+// Start starts the runtime
+func (d *dockerRuntime) Start() error {
+	d.Lock()
+	defer d.Unlock()
+
+	// already running
+	if d.running {
+		return nil
+	}
+
+	// set running
+	d.running = true
+	d.closed = make(chan bool)
+
+	var events <-chan runtime.Event
+	if d.options.Scheduler != nil {
+		var err error
+		events, err = d.options.Scheduler.Notify()
+		if err != nil {
+			// TODO: should we bail here?
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Runtime failed to start update notifier")
+			}
+		}
+	}
+
+	go d.run(events)
+
+	return nil
+}
+
+// Stop stops the runtime, the containers keep running
+func (d *dockerRuntime) Stop() error {
+	d.Lock()
+	defer d.Unlock()
+
+	if !d.running {
+		return nil
+	}
+
+	close(d.closed)
+	d.running = false
+
+	// stop the scheduler
+	if d.options.Scheduler != nil {
+		return d.options.Scheduler.Close()
+	}
+
+	return nil
+}
+
+// String implements stringer interface
+func (d *dockerRuntime) String() string {
+	return "docker"
+}
+
+// CreateNamespace is a noop, namespaces are labels of the containers
+func (d *dockerRuntime) CreateNamespace(ns string) error {
+	return nil
+}
+
+// DeleteNamespace is a noop, namespaces are labels of the containers
+func (d *dockerRuntime) DeleteNamespace(ns string) error {
+	return nil
+}
+
+// NewRuntime returns a runtime which runs services as docker containers
+func NewRuntime(opts ...runtime.Option) runtime.Runtime {
+	// get default options
+	options := runtime.Options{
+		Type: "service",
+	}
+
+	// apply requested options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	var addr string
+	if options.Context != nil {
+		addr, _ = options.Context.Value(endpointKey{}).(string)
+	}
+
+	var client *docker.Client
+	var err error
+	if len(addr) > 0 {
+		client, err = docker.NewClient(addr)
+	} else {
+		client, err = docker.NewClientFromEnv()
+	}
+	if err != nil {
+		logger.Fatalf("Runtime failed to create docker client: %v", err)
+	}
+
+	return &dockerRuntime{
+		options: options,
+		client:  client,
+		closed:  make(chan bool),
+	}
+}
@@ -0,0 +1,162 @@
+package docker
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+	dtesting "github.com/fsouza/go-dockerclient/testing"
+	dbuild "github.com/micro/go-micro/v3/build/docker"
+	"github.com/micro/go-micro/v3/runtime"
+)
+
+func TestRuntime(t *testing.T) {
+	srv, err := dtesting.NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	r := NewRuntime(Endpoint(srv.URL()), runtime.WithImage("micro/foo"))
+	d := r.(*dockerRuntime)
+
+	svc := &runtime.Service{
+		Name:     "go.micro.service.foo",
+		Version:  "latest",
+		Metadata: map[string]string{"owner": "micro"},
+	}
+
+	if err := r.Create(svc,
+		runtime.CreateNamespace("foo"),
+		runtime.WithSecret("TOKEN", "secret"),
+		runtime.WithRetries(3),
+		runtime.ResourceLimits(&runtime.Resources{CPU: 250, Mem: 128}),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Create(svc, runtime.CreateNamespace("foo")); err != runtime.ErrAlreadyExists {
+		t.Fatalf("Expected the service to already exist got %v", err)
+	}
+
+	c, err := d.client.InspectContainer("micro-foo-go.micro.service.foo-latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Config.Image != "micro/foo:latest" {
+		t.Fatalf("Expected the tagged image got %s", c.Config.Image)
+	}
+	if p := c.HostConfig.RestartPolicy; p.Name != "on-failure" || p.MaximumRetryCount != 3 {
+		t.Fatalf("Expected the on failure restart policy got %+v", p)
+	}
+	if c.HostConfig.CPUQuota != 25000 || c.HostConfig.Memory != 128<<20 {
+		t.Fatalf("Expected the resource limits got %d %d", c.HostConfig.CPUQuota, c.HostConfig.Memory)
+	}
+
+	services, err := r.Read(runtime.ReadNamespace("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("Expected 1 service got %d", len(services))
+	}
+	if s := services[0]; s.Name != svc.Name || s.Version != svc.Version || s.Metadata["owner"] != "micro" || s.Metadata["status"] != "running" {
+		t.Fatalf("Expected the running service got %+v", s)
+	}
+
+	// other namespaces don't see the service
+	if services, _ := r.Read(); len(services) != 0 {
+		t.Fatalf("Expected no services in the default namespace got %d", len(services))
+	}
+
+	if err := r.Update(svc, runtime.UpdateNamespace("foo"), runtime.UpdateImage("micro/foo:v2"), runtime.UpdateSecret("TOKEN", "rotated")); err != nil {
+		t.Fatal(err)
+	}
+
+	nc, err := d.client.InspectContainer("micro-foo-go.micro.service.foo-latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nc.ID == c.ID {
+		t.Fatal("Expected the container to be recreated")
+	}
+	if nc.Config.Image != "micro/foo:v2" || !nc.State.Running {
+		t.Fatalf("Expected the updated image to be running got %s", nc.Config.Image)
+	}
+	var tokens []string
+	for _, e := range nc.Config.Env {
+		if len(e) > 6 && e[:6] == "TOKEN=" {
+			tokens = append(tokens, e)
+		}
+	}
+	if len(tokens) != 1 || tokens[0] != "TOKEN=rotated" {
+		t.Fatalf("Expected the rotated secret got %v", tokens)
+	}
+
+	if err := r.Exec(svc, []string{"ls"}, runtime.ExecNamespace("foo")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Exec(svc, nil, runtime.ExecNamespace("foo")); err != runtime.ErrNoCommand {
+		t.Fatalf("Expected a command to be required got %v", err)
+	}
+
+	if err := r.Update(&runtime.Service{Name: "bar"}); err != ErrNotFound {
+		t.Fatalf("Expected the service not to be found got %v", err)
+	}
+
+	if err := r.Delete(svc, runtime.DeleteNamespace("foo")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.client.InspectContainer(nc.ID); err == nil {
+		t.Fatal("Expected the container to be removed")
+	} else if _, ok := err.(*docker.NoSuchContainer); !ok {
+		t.Fatalf("Expected no such container got %v", err)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	srv, err := dtesting.NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	dir, err := ioutil.TempDir("", "micro-build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRuntime(Endpoint(srv.URL()), runtime.WithBuild(dbuild.NewBuild(dbuild.Endpoint(srv.URL()))))
+	d := r.(*dockerRuntime)
+
+	svc := &runtime.Service{Name: "foo", Version: "latest", Source: dir}
+	out := new(bytes.Buffer)
+
+	if err := r.Create(svc, runtime.WithOutput(out)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Successfully built") {
+		t.Fatalf("Expected the build logs got %q", out.String())
+	}
+
+	c, err := d.client.InspectContainer("micro-default-foo-latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Config.Image != "micro/foo:latest" {
+		t.Fatalf("Expected the built image got %s", c.Config.Image)
+	}
+
+	// the image is rebuilt from the source on update
+	if err := r.Update(svc); err != nil {
+		t.Fatal(err)
+	}
+}
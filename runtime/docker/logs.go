@@ -0,0 +1,124 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strconv"
+	"sync"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/micro/go-micro/v3/runtime"
+)
+
+type logStream struct {
+	stream chan runtime.Log
+	cancel context.CancelFunc
+
+	sync.Mutex
+	stop chan bool
+	err  error
+}
+
+func (l *logStream) Error() error {
+	l.Lock()
+	defer l.Unlock()
+	return l.err
+}
+
+func (l *logStream) Chan() chan runtime.Log {
+	return l.stream
+}
+
+func (l *logStream) Stop() error {
+	l.Lock()
+	defer l.Unlock()
+
+	select {
+	case <-l.stop:
+		return nil
+	default:
+		close(l.stop)
+		l.cancel()
+	}
+	return nil
+}
+
+// read sends the lines of the logs until they end or the stream is stopped
+func (l *logStream) read(r io.Reader) {
+	defer close(l.stream)
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		select {
+		case l.stream <- runtime.Log{Message: s.Text()}:
+		case <-l.stop:
+			return
+		}
+	}
+
+	l.Lock()
+	l.err = s.Err()
+	l.Unlock()
+}
+
+// Logs returns the logs of the container of the service
+func (d *dockerRuntime) Logs(s *runtime.Service, opts ...runtime.LogsOption) (runtime.Logs, error) {
+	options := runtime.LogsOptions{
+		Namespace: defaultNamespace,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	containers, err := d.list(serviceLabels(s, options.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) == 0 {
+		return nil, ErrNotFound
+	}
+
+	tail := "all"
+	if options.Count > 0 {
+		tail = strconv.FormatInt(options.Count, 10)
+	}
+
+	var since int64
+	if !options.Since.IsZero() {
+		since = options.Since.Unix()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &logStream{
+		stream: make(chan runtime.Log),
+		stop:   make(chan bool),
+		cancel: cancel,
+	}
+
+	r, w := io.Pipe()
+
+	go func() {
+		err := d.client.Logs(docker.LogsOptions{
+			Context:      ctx,
+			Container:    containers[0].ID,
+			OutputStream: w,
+			ErrorStream:  w,
+			Follow:       options.Stream,
+			Stdout:       true,
+			Stderr:       true,
+			Tail:         tail,
+			Since:        since,
+		})
+		w.CloseWithError(err)
+	}()
+
+	go func() {
+		stream.read(r)
+		// stop the request when the reader is done
+		stream.Stop()
+		r.Close()
+	}()
+
+	return stream, nil
+}
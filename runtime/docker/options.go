@@ -0,0 +1,45 @@
+package docker
+
+import (
+	"context"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/micro/go-micro/v3/runtime"
+)
+
+type endpointKey struct{}
+
+type networkKey struct{}
+
+type authKey struct{}
+
+// Endpoint of the docker daemon e.g unix:///var/run/docker.sock,
+// the DOCKER_HOST environment variable is used by default
+func Endpoint(addr string) runtime.Option {
+	return func(o *runtime.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, endpointKey{}, addr)
+	}
+}
+
+// Network the containers are attached to, the default bridge if not set
+func Network(name string) runtime.Option {
+	return func(o *runtime.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, networkKey{}, name)
+	}
+}
+
+// Auth sets the registry credentials used to pull images
+func Auth(auth docker.AuthConfiguration) runtime.Option {
+	return func(o *runtime.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, authKey{}, auth)
+	}
+}
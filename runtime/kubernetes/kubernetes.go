@@ -4,6 +4,7 @@ package kubernetes
 import (
 	"encoding/base64"
 	"fmt"
+	"io/ioutil"
 	"strings"
 	"sync"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/micro/go-micro/v3/logger"
 	log "github.com/micro/go-micro/v3/logger"
 	"github.com/micro/go-micro/v3/runtime"
+	"github.com/micro/go-micro/v3/util/kubernetes/api"
 	"github.com/micro/go-micro/v3/util/kubernetes/client"
 )
 
@@ -233,8 +235,16 @@ func (k *kubernetes) getService(labels map[string]string, opts ...client.GetOpti
 				svc.Status(status, nil)
 			}
 
-			// save deployment
-			svc.kdeploy = &kdep
+			// report a rollout in progress over the status of the pods
+			if done, err := rollout(&kdep); err != nil {
+				svc.Status("error", err)
+			} else if !done {
+				svc.Status("updating", nil)
+			}
+
+			// save a copy of the deployment, the loop variable is reused
+			dep := kdep
+			svc.kdeploy = &dep
 		}
 	}
 
@@ -375,6 +385,63 @@ func (k *kubernetes) Logs(s *runtime.Service, options ...runtime.LogsOption) (ru
 	return stream, nil
 }
 
+// Exec runs the command in a running pod of the service
+func (k *kubernetes) Exec(s *runtime.Service, cmd []string, opts ...runtime.ExecOption) error {
+	options := runtime.ExecOptions{
+		Namespace: client.DefaultNamespace,
+		Stdout:    ioutil.Discard,
+		Stderr:    ioutil.Discard,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if len(cmd) == 0 {
+		return runtime.ErrNoCommand
+	}
+
+	labels := map[string]string{
+		"name": client.Format(s.Name),
+	}
+	if len(s.Version) > 0 {
+		labels["version"] = s.Version
+	}
+
+	pods := new(client.PodList)
+	if err := k.client.Get(&client.Resource{
+		Kind:  "pod",
+		Value: pods,
+	}, client.GetLabels(labels), client.GetNamespace(options.Namespace)); err != nil {
+		return err
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status == nil || pod.Status.Phase != "Running" {
+			continue
+		}
+
+		execOpts := []client.ExecOption{
+			client.ExecNamespace(options.Namespace),
+			client.ExecCommand(cmd...),
+			client.ExecOutput(options.Stdout, options.Stderr),
+		}
+		if options.Context != nil {
+			execOpts = append(execOpts, client.ExecContext(options.Context))
+		}
+
+		code, err := k.client.Exec(&client.Resource{Kind: "pod", Name: pod.Metadata.Name}, execOpts...)
+		if err != nil {
+			return err
+		}
+		if code != 0 {
+			return &runtime.ExitError{Code: code}
+		}
+		return nil
+	}
+
+	return ErrNotRunning
+}
+
 type kubeStream struct {
 	// the k8s log stream
 	stream chan runtime.Log
@@ -522,6 +589,13 @@ func (k *kubernetes) Update(s *runtime.Service, opts ...runtime.UpdateOption) er
 		o(&options)
 	}
 
+	// run the version alongside the others
+	if options.Strategy == runtime.BlueGreen || options.Strategy == runtime.Canary {
+		return runtime.Deploy(k, k.options.Registry, s, func() error {
+			return k.createVersion(s, options)
+		}, options)
+	}
+
 	labels := map[string]string{}
 
 	if len(s.Name) > 0 {
@@ -552,13 +626,221 @@ func (k *kubernetes) Update(s *runtime.Service, opts ...runtime.UpdateOption) er
 			service.kdeploy.Metadata.Annotations[k] = v
 		}
 
+		// roll out the new image
+		if len(options.Image) > 0 {
+			for i := range service.kdeploy.Spec.Template.PodSpec.Containers {
+				service.kdeploy.Spec.Template.PodSpec.Containers[i].Image = options.Image
+			}
+		}
+
+		// update the credentials and reference any new keys
+		if len(options.Secrets) > 0 {
+			if err := k.updateCredentials(service.Service, options); err != nil {
+				if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+					logger.Warnf("Error updating auth credentials for service: %v", err)
+				}
+				return err
+			}
+			service.referenceCredentials(options.Secrets)
+		}
+
 		// update build time annotation
+		if service.kdeploy.Spec.Template.Metadata.Annotations == nil {
+			service.kdeploy.Spec.Template.Metadata.Annotations = make(map[string]string)
+		}
 		service.kdeploy.Spec.Template.Metadata.Annotations["updated"] = fmt.Sprintf("%d", time.Now().Unix())
 
-		// update the service
+		// update the service, the pods are replaced by a rolling update
 		if err := service.Update(k.client, client.UpdateNamespace(options.Namespace)); err != nil {
 			return err
 		}
+
+		// set the status of the service once the rollout completes
+		if options.Wait > 0 {
+			if err := service.Wait(k.client, options.Wait, client.GetNamespace(options.Namespace)); err != nil {
+				return err
+			}
+		}
+
+		// report the status of the rollout to the caller
+		if s.Metadata == nil {
+			s.Metadata = make(map[string]string)
+		}
+		for _, key := range []string{"status", "error", "lastStatusUpdate"} {
+			if v, ok := service.Metadata[key]; ok {
+				s.Metadata[key] = v
+			} else {
+				delete(s.Metadata, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// createVersion creates a deployment of the version of the service with the pods of
+// another version, it returns once the rollout of the version completes
+func (k *kubernetes) createVersion(s *runtime.Service, options runtime.UpdateOptions) error {
+	services, err := k.getService(map[string]string{"name": client.Format(s.Name)}, client.GetNamespace(options.Namespace))
+	if err != nil {
+		return err
+	}
+
+	var previous *service
+	for _, srv := range services {
+		if srv.Version != s.Version && srv.kdeploy != nil {
+			previous = srv
+			break
+		}
+	}
+	if previous == nil {
+		return runtime.ErrNoPreviousVersion
+	}
+
+	if len(s.Source) == 0 {
+		s.Source = previous.Source
+	}
+
+	version := newService(s, runtime.CreateOptions{
+		Type:      k.options.Type,
+		Namespace: options.Namespace,
+		Image:     options.Image,
+	})
+
+	// run the pods of the previous version, the credentials are per version
+	// so the secrets referenced by the previous version must be set again
+	spec := *previous.kdeploy.Spec.Template.PodSpec
+	spec.Containers = make([]client.Container, len(previous.kdeploy.Spec.Template.PodSpec.Containers))
+	for i, c := range previous.kdeploy.Spec.Template.PodSpec.Containers {
+		if len(options.Image) > 0 {
+			c.Image = options.Image
+		}
+
+		c.Env = make([]client.EnvVar, 0, len(c.Env))
+		for _, env := range previous.kdeploy.Spec.Template.PodSpec.Containers[i].Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == credentialsName(previous.Service) {
+				if _, ok := options.Secrets[env.Name]; !ok {
+					return fmt.Errorf("secret %s of the previous version required", env.Name)
+				}
+				env = client.EnvVar{
+					Name: env.Name,
+					ValueFrom: &client.EnvVarSource{
+						SecretKeyRef: &client.SecretKeySelector{Name: credentialsName(s), Key: env.ValueFrom.SecretKeyRef.Key},
+					},
+				}
+			}
+			c.Env = append(c.Env, env)
+		}
+
+		spec.Containers[i] = c
+	}
+	version.kdeploy.Spec.Template.PodSpec = &spec
+	version.kdeploy.Spec.Replicas = previous.kdeploy.Spec.Replicas
+	version.referenceCredentials(options.Secrets)
+
+	if len(options.Secrets) > 0 {
+		if err := k.createCredentials(s, runtime.CreateOptions{Secrets: options.Secrets, Namespace: options.Namespace}); err != nil {
+			return err
+		}
+	}
+
+	if err := version.Start(k.client, client.CreateNamespace(options.Namespace)); err != nil {
+		return err
+	}
+
+	// verify the rollout of the version before it's sent traffic
+	wait := options.Wait
+	if wait == 0 {
+		wait = DefaultRolloutTimeout
+	}
+	if err := version.Wait(k.client, wait, client.GetNamespace(options.Namespace)); err != nil {
+		k.Delete(s, runtime.DeleteNamespace(options.Namespace))
+		return err
+	}
+
+	return nil
+}
+
+// getDeployments returns the deployments of the service
+func (k *kubernetes) getDeployments(s *runtime.Service, namespace string) ([]client.Deployment, error) {
+	labels := map[string]string{
+		"name": client.Format(s.Name),
+	}
+	if len(s.Version) > 0 {
+		labels["version"] = s.Version
+	}
+
+	depList := new(client.DeploymentList)
+	r := &client.Resource{
+		Kind:  "deployment",
+		Value: depList,
+	}
+	if err := k.client.Get(r, client.GetNamespace(namespace), client.GetLabels(labels)); err != nil {
+		return nil, err
+	}
+	if len(depList.Items) == 0 {
+		return nil, runtime.ErrNotFound
+	}
+
+	return depList.Items, nil
+}
+
+// Replicas returns the number of replicas of the service deployment
+func (k *kubernetes) Replicas(s *runtime.Service, opts ...runtime.ReadOption) (int, error) {
+	options := runtime.ReadOptions{
+		Namespace: client.DefaultNamespace,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	deployments, err := k.getDeployments(s, options.Namespace)
+	if err != nil {
+		return 0, err
+	}
+
+	var replicas int
+	for _, d := range deployments {
+		if d.Spec != nil {
+			replicas += d.Spec.Replicas
+		}
+	}
+
+	return replicas, nil
+}
+
+// Scale sets the replicas of the service deployment, the pods are not restarted
+func (k *kubernetes) Scale(s *runtime.Service, replicas int, opts ...runtime.UpdateOption) error {
+	if replicas < 1 {
+		return runtime.ErrInvalidScale
+	}
+
+	options := runtime.UpdateOptions{
+		Namespace: client.DefaultNamespace,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	deployments, err := k.getDeployments(s, options.Namespace)
+	if err != nil {
+		return err
+	}
+
+	for i := range deployments {
+		d := &deployments[i]
+		if d.Spec == nil {
+			d.Spec = new(client.DeploymentSpec)
+		}
+		d.Spec.Replicas = replicas
+
+		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+			logger.Debugf("Runtime scaling deployment %s to %d replicas", d.Metadata.Name, replicas)
+		}
+
+		if err := k.client.Update(deploymentResource(d), client.UpdateNamespace(options.Namespace)); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -685,21 +967,25 @@ func (k *kubernetes) getImage(s *runtime.Service, options runtime.CreateOptions)
 
 	return ""
 }
-func (k *kubernetes) createCredentials(service *runtime.Service, options runtime.CreateOptions) error {
-	data := make(map[string]string, len(options.Secrets))
-	for key, value := range options.Secrets {
+func newCredentials(service *runtime.Service, secrets map[string]string, namespace string) *client.Secret {
+	data := make(map[string]string, len(secrets))
+	for key, value := range secrets {
 		data[key] = base64.StdEncoding.EncodeToString([]byte(value))
 	}
 
 	// construct the k8s secret object
-	secret := &client.Secret{
+	return &client.Secret{
 		Type: "Opaque",
 		Data: data,
 		Metadata: &client.Metadata{
 			Name:      credentialsName(service),
-			Namespace: options.Namespace,
+			Namespace: namespace,
 		},
 	}
+}
+
+func (k *kubernetes) createCredentials(service *runtime.Service, options runtime.CreateOptions) error {
+	secret := newCredentials(service, options.Secrets, options.Namespace)
 
 	// crete the secret in kubernetes
 	name := credentialsName(service)
@@ -708,6 +994,23 @@ func (k *kubernetes) createCredentials(service *runtime.Service, options runtime
 	}, client.CreateNamespace(options.Namespace))
 }
 
+// updateCredentials merges the secrets into the credentials, creating them if they don't exist
+func (k *kubernetes) updateCredentials(service *runtime.Service, options runtime.UpdateOptions) error {
+	secret := newCredentials(service, options.Secrets, options.Namespace)
+
+	name := credentialsName(service)
+	err := k.client.Update(&client.Resource{
+		Kind: "secret", Name: name, Value: secret,
+	}, client.UpdateNamespace(options.Namespace))
+	if err != api.ErrNotFound {
+		return err
+	}
+
+	return k.client.Create(&client.Resource{
+		Kind: "secret", Name: name, Value: secret,
+	}, client.CreateNamespace(options.Namespace))
+}
+
 func credentialsName(service *runtime.Service) string {
 	name := fmt.Sprintf("%v-%v-credentials", service.Name, service.Version)
 	return client.SerializeResourceName(name)
@@ -21,8 +21,27 @@ type klog struct {
 	options     runtime.LogsOptions
 }
 
-func (k *klog) podLogs(podName string, stream *kubeStream) error {
+// params returns the query params of the pod logs
+func (k *klog) params() map[string]string {
 	p := make(map[string]string)
+
+	if !k.options.Since.IsZero() {
+		p["sinceTime"] = k.options.Since.UTC().Format(time.RFC3339)
+	}
+
+	if k.options.Count != 0 {
+		p["tailLines"] = strconv.Itoa(int(k.options.Count))
+	}
+
+	if k.options.Stream {
+		p["follow"] = "true"
+	}
+
+	return p
+}
+
+func (k *klog) podLogs(podName string, stream *kubeStream) error {
+	p := k.params()
 	p["follow"] = "true"
 
 	opts := []client.LogOption{
@@ -113,22 +132,8 @@ func (k *klog) Read() ([]runtime.Log, error) {
 	var records []runtime.Log
 
 	for _, pod := range pods {
-		logParams := make(map[string]string)
-
-		//if !opts.Since.Equal(time.Time{}) {
-		//	logParams["sinceSeconds"] = strconv.Itoa(int(time.Since(opts.Since).Seconds()))
-		//}
-
-		if k.options.Count != 0 {
-			logParams["tailLines"] = strconv.Itoa(int(k.options.Count))
-		}
-
-		if k.options.Stream == true {
-			logParams["follow"] = "true"
-		}
-
 		opts := []client.LogOption{
-			client.LogParams(logParams),
+			client.LogParams(k.params()),
 			client.LogNamespace(k.options.Namespace),
 		}
 
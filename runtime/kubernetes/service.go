@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -12,6 +13,19 @@ import (
 	"github.com/micro/go-micro/v3/util/kubernetes/client"
 )
 
+var (
+	// PollInterval is how often the rollout status is checked while waiting
+	PollInterval = time.Second
+	// DefaultRolloutTimeout is how long a new version has to roll out when deployed alongside others
+	DefaultRolloutTimeout = 5 * time.Minute
+	// ErrRolloutFailed is returned when the deployment stops making progress
+	ErrRolloutFailed = errors.New("rollout failed")
+	// ErrRolloutTimeout is returned when the rollout didn't complete in time
+	ErrRolloutTimeout = errors.New("timed out waiting for rollout")
+	// ErrNotRunning is returned when there's no running pod to exec in
+	ErrNotRunning = errors.New("no running pods for the service")
+)
+
 type service struct {
 	// service to manage
 	*runtime.Service
@@ -103,20 +117,33 @@ func newService(s *runtime.Service, c runtime.CreateOptions) *service {
 		kdeploy.Spec.Template.PodSpec.Containers[0].Args = c.Args
 	}
 
-	// apply resource limits
-	if c.Resources != nil {
-		resLimits := &client.ResourceLimits{}
-		if c.Resources.CPU > 0 {
-			resLimits.CPU = fmt.Sprintf("%vm", c.Resources.CPU)
-		}
-		if c.Resources.Mem > 0 {
-			resLimits.Memory = fmt.Sprintf("%vMi", c.Resources.Mem)
-		}
-		if c.Resources.Disk > 0 {
-			resLimits.EphemeralStorage = fmt.Sprintf("%vMi", c.Resources.Disk)
+	// set the whole environment from the secrets
+	for _, name := range c.EnvFrom {
+		kdeploy.Spec.Template.PodSpec.Containers[0].EnvFrom = append(kdeploy.Spec.Template.PodSpec.Containers[0].EnvFrom, client.EnvFromSource{
+			SecretRef: &client.SecretEnvSource{Name: name},
+		})
+	}
+
+	// apply resource limits and requests
+	if c.Resources != nil || c.Requests != nil {
+		kdeploy.Spec.Template.PodSpec.Containers[0].Resources = &client.ResourceRequirements{
+			Limits:   resourceLimits(c.Resources),
+			Requests: resourceLimits(c.Requests),
 		}
+	}
 
-		kdeploy.Spec.Template.PodSpec.Containers[0].Resources = &client.ResourceRequirements{Limits: resLimits}
+	// override the default probes of the service port
+	port := kdeploy.Spec.Template.PodSpec.Containers[0].Ports[0].ContainerPort
+	if c.Liveness != nil {
+		kdeploy.Spec.Template.PodSpec.Containers[0].LivenessProbe = newProbe(c.Liveness, port)
+	}
+	if c.Readiness != nil {
+		kdeploy.Spec.Template.PodSpec.Containers[0].ReadinessProbe = newProbe(c.Readiness, port)
+	}
+
+	// secrets used to pull the image from private registries
+	for _, name := range c.PullSecrets {
+		kdeploy.Spec.Template.PodSpec.ImagePullSecrets = append(kdeploy.Spec.Template.PodSpec.ImagePullSecrets, client.ImagePullSecret{Name: name})
 	}
 
 	return &service{
@@ -126,6 +153,85 @@ func newService(s *runtime.Service, c runtime.CreateOptions) *service {
 	}
 }
 
+// resourceLimits converts the runtime resources to kubernetes quantities
+func resourceLimits(r *runtime.Resources) *client.ResourceLimits {
+	if r == nil {
+		return nil
+	}
+
+	limits := &client.ResourceLimits{}
+	if r.CPU > 0 {
+		limits.CPU = fmt.Sprintf("%vm", r.CPU)
+	}
+	if r.Mem > 0 {
+		limits.Memory = fmt.Sprintf("%vMi", r.Mem)
+	}
+	if r.Disk > 0 {
+		limits.EphemeralStorage = fmt.Sprintf("%vMi", r.Disk)
+	}
+	return limits
+}
+
+// newProbe returns a http probe if a path is set, otherwise a tcp probe
+func newProbe(p *runtime.Probe, port int) *client.Probe {
+	if p.Port > 0 {
+		port = p.Port
+	}
+
+	probe := &client.Probe{
+		PeriodSeconds:       int(p.Interval.Seconds()),
+		InitialDelaySeconds: int(p.Delay.Seconds()),
+		FailureThreshold:    p.Failures,
+	}
+	if probe.PeriodSeconds == 0 {
+		probe.PeriodSeconds = 10
+	}
+
+	if len(p.Path) > 0 {
+		probe.HTTPGet = &client.HTTPGetAction{Path: p.Path, Port: port}
+	} else {
+		probe.TCPSocket = &client.TCPSocketAction{Port: port}
+	}
+	return probe
+}
+
+// rollout returns true once all the replicas of the deployment run the latest spec
+func rollout(d *client.Deployment) (bool, error) {
+	if d.Status == nil {
+		return false, nil
+	}
+
+	for _, c := range d.Status.Conditions {
+		if c.Type == "Progressing" && c.Reason == "ProgressDeadlineExceeded" {
+			return false, fmt.Errorf("%v: %s", ErrRolloutFailed, c.Message)
+		}
+	}
+
+	// the controller hasn't seen the update yet
+	if d.Metadata != nil && d.Metadata.Generation > d.Status.ObservedGeneration {
+		return false, nil
+	}
+
+	replicas := 1
+	if d.Spec != nil && d.Spec.Replicas > 0 {
+		replicas = d.Spec.Replicas
+	}
+
+	switch {
+	case d.Status.UpdatedReplicas < replicas:
+		// new pods are still being created
+		return false, nil
+	case d.Status.Replicas > d.Status.UpdatedReplicas:
+		// old pods are still being terminated
+		return false, nil
+	case d.Status.AvailableReplicas < d.Status.UpdatedReplicas:
+		// new pods aren't ready yet
+		return false, nil
+	}
+
+	return true, nil
+}
+
 func deploymentResource(d *client.Deployment) *client.Resource {
 	return &client.Resource{
 		Name:  d.Metadata.Name,
@@ -212,9 +318,78 @@ func (s *service) Update(k client.Client, opts ...client.UpdateOption) error {
 		return err
 	}
 
+	s.Status("updating", nil)
+
 	return nil
 }
 
+// referenceCredentials sets the keys of the credentials missing from the environment
+func (s *service) referenceCredentials(secrets map[string]string) {
+	container := &s.kdeploy.Spec.Template.PodSpec.Containers[0]
+
+	set := make(map[string]bool, len(container.Env))
+	for _, env := range container.Env {
+		set[env.Name] = true
+	}
+
+	for key := range secrets {
+		if set[key] {
+			continue
+		}
+		container.Env = append(container.Env, client.EnvVar{
+			Name: key,
+			ValueFrom: &client.EnvVarSource{
+				SecretKeyRef: &client.SecretKeySelector{
+					Name: credentialsName(s.Service),
+					Key:  key,
+				},
+			},
+		})
+	}
+}
+
+// Wait waits for the rollout of the deployment to complete
+func (s *service) Wait(k client.Client, timeout time.Duration, opts ...client.GetOption) error {
+	opts = append(opts, client.GetLabels(s.kdeploy.Metadata.Labels))
+
+	t := time.NewTicker(PollInterval)
+	defer t.Stop()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		deployed := new(client.DeploymentList)
+		if err := k.Get(&client.Resource{Kind: "deployment", Value: deployed}, opts...); err != nil {
+			s.Status("error", err)
+			return err
+		}
+
+		for _, d := range deployed.Items {
+			if d.Metadata.Name != s.kdeploy.Metadata.Name {
+				continue
+			}
+
+			done, err := rollout(&d)
+			if err != nil {
+				s.Status("error", err)
+				return err
+			}
+			if done {
+				s.Status("running", nil)
+				return nil
+			}
+		}
+
+		select {
+		case <-t.C:
+		case <-deadline.C:
+			s.Status("error", ErrRolloutTimeout)
+			return ErrRolloutTimeout
+		}
+	}
+}
+
 func (s *service) Status(status string, err error) {
 	s.Metadata["lastStatusUpdate"] = time.Now().Format(time.RFC3339)
 	if err == nil {
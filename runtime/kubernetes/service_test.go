@@ -0,0 +1,120 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/runtime"
+	"github.com/micro/go-micro/v3/util/kubernetes/client"
+)
+
+// testClient returns the deployment status of each call to get in turn
+type testClient struct {
+	client.Client
+	name   string
+	status []*client.DeploymentStatus
+}
+
+func (c *testClient) Get(r *client.Resource, opts ...client.GetOption) error {
+	status := c.status[0]
+	if len(c.status) > 1 {
+		c.status = c.status[1:]
+	}
+
+	r.Value.(*client.DeploymentList).Items = []client.Deployment{{
+		Metadata: &client.Metadata{Name: c.name, Generation: 2},
+		Spec:     &client.DeploymentSpec{Replicas: 2},
+		Status:   status,
+	}}
+	return nil
+}
+
+func TestNewService(t *testing.T) {
+	s := newService(&runtime.Service{Name: "go.micro.service.foo", Version: "latest"}, runtime.CreateOptions{
+		Type:        "service",
+		Namespace:   "default",
+		Resources:   &runtime.Resources{CPU: 500, Mem: 256},
+		Requests:    &runtime.Resources{CPU: 100},
+		Liveness:    &runtime.Probe{Path: "/health", Delay: time.Minute},
+		Readiness:   &runtime.Probe{Port: 9090, Interval: 5 * time.Second, Failures: 2},
+		PullSecrets: []string{"registry"},
+		EnvFrom:     []string{"foo-env"},
+	})
+
+	spec := s.kdeploy.Spec.Template.PodSpec
+	c := spec.Containers[0]
+
+	if r := c.Resources; r.Limits.CPU != "500m" || r.Limits.Memory != "256Mi" || r.Requests.CPU != "100m" || len(r.Requests.Memory) > 0 {
+		t.Fatalf("Expected the resources got %+v %+v", r.Limits, r.Requests)
+	}
+	if p := c.LivenessProbe; p.HTTPGet == nil || p.HTTPGet.Path != "/health" || p.HTTPGet.Port != 8080 || p.InitialDelaySeconds != 60 || p.PeriodSeconds != 10 {
+		t.Fatalf("Expected a http liveness probe of the service port got %+v", p)
+	}
+	if p := c.ReadinessProbe; p.TCPSocket == nil || p.TCPSocket.Port != 9090 || p.PeriodSeconds != 5 || p.FailureThreshold != 2 {
+		t.Fatalf("Expected a tcp readiness probe got %+v", p)
+	}
+	if len(spec.ImagePullSecrets) != 1 || spec.ImagePullSecrets[0].Name != "registry" {
+		t.Fatalf("Expected the image pull secret got %+v", spec.ImagePullSecrets)
+	}
+	if len(c.EnvFrom) != 1 || c.EnvFrom[0].SecretRef.Name != "foo-env" {
+		t.Fatalf("Expected the env from secret got %+v", c.EnvFrom)
+	}
+
+	// new keys of the credentials are referenced once
+	s.referenceCredentials(map[string]string{"FOO": "bar"})
+	s.referenceCredentials(map[string]string{"FOO": "baz"})
+
+	var refs int
+	for _, env := range s.kdeploy.Spec.Template.PodSpec.Containers[0].Env {
+		if env.Name == "FOO" {
+			refs++
+		}
+	}
+	if refs != 1 {
+		t.Fatalf("Expected the credentials to be referenced once got %d", refs)
+	}
+}
+
+func TestWait(t *testing.T) {
+	PollInterval = time.Millisecond
+	defer func() {
+		PollInterval = time.Second
+	}()
+
+	s := newService(&runtime.Service{Name: "foo", Version: "latest"}, runtime.CreateOptions{Type: "service"})
+	name := s.kdeploy.Metadata.Name
+
+	// the controller observes the update, creates a new pod, then terminates the old
+	k := &testClient{name: name, status: []*client.DeploymentStatus{
+		{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 0, AvailableReplicas: 2},
+		{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2},
+		{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+		{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+	}}
+
+	if err := s.Wait(k, time.Second); err != nil {
+		t.Fatalf("Expected the rollout to complete got %v", err)
+	}
+	if len(k.status) != 1 {
+		t.Fatalf("Expected to wait for all the replicas to be updated")
+	}
+	if s.Metadata["status"] != "running" {
+		t.Fatalf("Expected the running status got %s", s.Metadata["status"])
+	}
+
+	k = &testClient{name: name, status: []*client.DeploymentStatus{{
+		ObservedGeneration: 2,
+		Conditions:         []client.DeploymentCondition{{Type: "Progressing", Reason: "ProgressDeadlineExceeded"}},
+	}}}
+	if err := s.Wait(k, time.Second); err == nil {
+		t.Fatal("Expected the rollout to fail")
+	}
+	if s.Metadata["status"] != "error" {
+		t.Fatalf("Expected the error status got %s", s.Metadata["status"])
+	}
+
+	k = &testClient{name: name, status: []*client.DeploymentStatus{{ObservedGeneration: 1}}}
+	if err := s.Wait(k, 10*time.Millisecond); err != ErrRolloutTimeout {
+		t.Fatalf("Expected the rollout to time out got %v", err)
+	}
+}
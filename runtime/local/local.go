@@ -1,17 +1,21 @@
 package local
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/hpcloud/tail"
+	"github.com/micro/go-micro/v3/build"
 	"github.com/micro/go-micro/v3/logger"
 	"github.com/micro/go-micro/v3/runtime"
 	"github.com/micro/go-micro/v3/runtime/local/source/git"
@@ -171,18 +175,25 @@ func (r *localRuntime) run(events <-chan runtime.Event) {
 			// check running services
 			r.RLock()
 			for _, sevices := range r.namespaces {
-				for _, service := range sevices {
-					if !service.ShouldStart() {
+				for _, srv := range sevices {
+					// wait for the dependencies to be running
+					if !dependenciesRunning(sevices, srv) {
 						continue
 					}
 
-					// TODO: check service error
-					if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-						logger.Debugf("Runtime starting %s", service.Name)
-					}
-					if err := service.Start(); err != nil {
+					for _, service := range append([]*service{srv}, srv.replicas...) {
+						if !service.ShouldStart() {
+							continue
+						}
+
+						// TODO: check service error
 						if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-							logger.Debugf("Runtime error starting %s: %v", service.Name, err)
+							logger.Debugf("Runtime starting %s", service.Name)
+						}
+						if err := service.Start(); err != nil {
+							if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+								logger.Debugf("Runtime error starting %s: %v", service.Name, err)
+							}
 						}
 					}
 				}
@@ -280,6 +291,16 @@ func (r *localRuntime) Create(s *runtime.Service, opts ...runtime.CreateOption)
 	if err != nil {
 		return err
 	}
+	// build the source into a package to run
+	var pkg *build.Package
+	if r.options.Build != nil && len(s.Source) > 0 && len(options.Command) == 0 {
+		pkg, err = r.build(s, s.Source, options.Output)
+		if err != nil {
+			return err
+		}
+		options.Command = []string{pkg.Path}
+	}
+
 	r.Lock()
 	defer r.Unlock()
 
@@ -310,6 +331,8 @@ func (r *localRuntime) Create(s *runtime.Service, opts ...runtime.CreateOption)
 
 	// create new service
 	service := newService(s, options)
+	service.pkg = pkg
+	service.createOpts = opts
 
 	f, err := os.OpenFile(logFile(service.Name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -321,16 +344,46 @@ func (r *localRuntime) Create(s *runtime.Service, opts ...runtime.CreateOption)
 	} else {
 		service.output = f
 	}
+	// save service
+	r.namespaces[options.Namespace][serviceKey(s)] = service
+
+	// the runtime starts the service once its dependencies are running
+	if !dependenciesRunning(r.namespaces[options.Namespace], service) {
+		service.Lock()
+		if service.Metadata == nil {
+			service.Metadata = make(map[string]string)
+		}
+		service.Status("waiting", nil)
+		service.Unlock()
+		return nil
+	}
+
 	// start the service
 	if err := service.Start(); err != nil {
+		delete(r.namespaces[options.Namespace], serviceKey(s))
 		return err
 	}
-	// save service
-	r.namespaces[options.Namespace][serviceKey(s)] = service
 
 	return nil
 }
 
+// dependenciesRunning returns true if a service with the name of each dependency is running
+func dependenciesRunning(services map[string]*service, s *service) bool {
+	for _, name := range s.options.Dependencies {
+		var running bool
+		for _, srv := range services {
+			if srv.Name == name && srv.Running() {
+				running = true
+				break
+			}
+		}
+		if !running {
+			return false
+		}
+	}
+	return true
+}
+
 // exists returns whether the given file or directory exists
 func exists(path string) (bool, error) {
 	_, err := os.Stat(path)
@@ -379,6 +432,11 @@ func (r *localRuntime) Logs(s *runtime.Service, options ...runtime.LogsOption) (
 	if offset > size {
 		offset = size
 	}
+	// the lines aren't timestamped, but if the file hasn't been
+	// written since then there are no existing lines to show
+	if !lopts.Since.IsZero() && fi.ModTime().Before(lopts.Since) {
+		offset = 0
+	}
 	offset *= -1
 
 	t, err := tail.TailFile(fpath, tail.Config{Follow: lopts.Stream, Location: &tail.SeekInfo{
@@ -408,6 +466,43 @@ func (r *localRuntime) Logs(s *runtime.Service, options ...runtime.LogsOption) (
 	return ret, nil
 }
 
+// Exec runs the command in the directory and environment of the service
+func (r *localRuntime) Exec(s *runtime.Service, cmd []string, opts ...runtime.ExecOption) error {
+	options := runtime.ExecOptions{
+		Namespace: defaultNamespace,
+		Context:   context.Background(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if len(cmd) == 0 {
+		return runtime.ErrNoCommand
+	}
+
+	r.RLock()
+	service, ok := r.namespaces[options.Namespace][serviceKey(s)]
+	r.RUnlock()
+	if !ok {
+		return errors.New("Service not found")
+	}
+
+	c := exec.CommandContext(options.Context, cmd[0], cmd[1:]...)
+	c.Dir = service.Exec.Dir
+	c.Env = append(os.Environ(), service.Exec.Env...)
+	c.Stdout = options.Stdout
+	c.Stderr = options.Stderr
+
+	if err := c.Run(); err != nil {
+		if e, ok := err.(*exec.ExitError); ok {
+			return &runtime.ExitError{Code: e.ExitCode()}
+		}
+		return err
+	}
+
+	return nil
+}
+
 type logStream struct {
 	tail    *tail.Tail
 	service string
@@ -493,15 +588,22 @@ func (r *localRuntime) Update(s *runtime.Service, opts ...runtime.UpdateOption)
 	for _, o := range opts {
 		o(&options)
 	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = defaultNamespace
+	}
+
+	// run the version alongside the others
+	if options.Strategy == runtime.BlueGreen || options.Strategy == runtime.Canary {
+		return runtime.Deploy(r, r.options.Registry, s, func() error {
+			return r.createVersion(s, options)
+		}, options)
+	}
+
 	err := r.checkoutSourceIfNeeded(s, options.Secrets)
 	if err != nil {
 		return err
 	}
 
-	if len(options.Namespace) == 0 {
-		options.Namespace = defaultNamespace
-	}
-
 	r.Lock()
 	srvs, ok := r.namespaces[options.Namespace]
 	r.Unlock()
@@ -509,19 +611,121 @@ func (r *localRuntime) Update(s *runtime.Service, opts ...runtime.UpdateOption)
 		return errors.New("Service not found")
 	}
 
+	var replicas []*service
+
 	r.Lock()
 	service, ok := srvs[serviceKey(s)]
+	if ok {
+		replicas = append(replicas, service)
+		replicas = append(replicas, service.replicas...)
+	}
 	r.Unlock()
 	if !ok {
 		return errors.New("Service not found")
 	}
 
-	if err := service.Stop(); err != nil && err.Error() != "no such process" {
-		logger.Errorf("Error stopping service %s: %s", service.Name, err)
+	// stop every replica of the service
+	for _, service := range replicas {
+		if err := service.Stop(); err != nil && err.Error() != "no such process" {
+			logger.Errorf("Error stopping service %s: %s", service.Name, err)
+			return err
+		}
+	}
+
+	// rebuild the package from the updated source
+	if service.pkg != nil {
+		src := s.Source
+		if len(src) == 0 {
+			src = service.Source
+		}
+		if _, err := r.build(s, src, service.options.Output); err != nil {
+			return err
+		}
+	}
+
+	for _, service := range replicas {
+		if err := service.Start(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createVersion runs the version of the service with the options of another version,
+// it returns once the version has kept running for the wait of the options
+func (r *localRuntime) createVersion(s *runtime.Service, options runtime.UpdateOptions) error {
+	var previous *service
+
+	r.RLock()
+	for _, srv := range r.namespaces[options.Namespace] {
+		if srv.Name == s.Name && srv.Version != s.Version {
+			previous = srv
+			break
+		}
+	}
+	r.RUnlock()
+
+	if previous == nil {
+		return runtime.ErrNoPreviousVersion
+	}
+
+	if len(s.Source) == 0 {
+		s.Source = previous.Source
+	}
+
+	opts := append([]runtime.CreateOption{}, previous.createOpts...)
+	opts = append(opts, runtime.CreateNamespace(options.Namespace))
+	for key, value := range options.Secrets {
+		opts = append(opts, runtime.WithSecret(key, value))
+	}
+
+	if err := r.Create(s, opts...); err != nil {
 		return err
 	}
 
-	return service.Start()
+	r.RLock()
+	service := r.namespaces[options.Namespace][serviceKey(s)]
+	r.RUnlock()
+
+	// verify the version keeps running before it's sent traffic
+	deadline := time.Now().Add(options.Wait)
+	for {
+		if !service.Running() {
+			r.Delete(s, runtime.DeleteNamespace(options.Namespace))
+			return fmt.Errorf("version %s of %s is not running: %v", s.Version, s.Name, service.Error())
+		}
+		if time.Now().After(deadline) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// build packages the source of the service with the runtime builder,
+// the build logs are written to the log file of the service
+func (r *localRuntime) build(s *runtime.Service, src string, output io.Writer) (*build.Package, error) {
+	f, err := os.OpenFile(logFile(s.Name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var w io.Writer = f
+	if output != nil {
+		w = io.MultiWriter(output, f)
+	}
+
+	name := s.Name
+	if len(s.Version) > 0 {
+		name = fmt.Sprintf("%v-%v", s.Name, strings.ReplaceAll(s.Version, "/", "-"))
+	}
+
+	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Debugf("Runtime building service %s with %s", s.Name, r.options.Build)
+	}
+
+	return r.options.Build.Package(name, &build.Source{Path: sourceDir(s.Name, src)}, build.PackageOutput(w))
 }
 
 // Delete removes the service from the runtime and stops it
@@ -551,6 +755,14 @@ func (r *localRuntime) Delete(s *runtime.Service, opts ...runtime.DeleteOption)
 		return nil
 	}
 
+	// stop the replicas
+	for _, replica := range service.replicas {
+		if err := replica.Stop(); err != nil && err.Error() != "no such process" {
+			return err
+		}
+	}
+	service.replicas = nil
+
 	// check if running
 	if !service.Running() {
 		delete(srvs, service.key())
@@ -567,6 +779,79 @@ func (r *localRuntime) Delete(s *runtime.Service, opts ...runtime.DeleteOption)
 	return nil
 }
 
+// Replicas returns the number of processes running the service
+func (r *localRuntime) Replicas(s *runtime.Service, opts ...runtime.ReadOption) (int, error) {
+	var options runtime.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = defaultNamespace
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	service, ok := r.namespaces[options.Namespace][serviceKey(s)]
+	if !ok {
+		return 0, runtime.ErrNotFound
+	}
+
+	return len(service.replicas) + 1, nil
+}
+
+// Scale starts or stops processes of the service until it has the number of replicas
+func (r *localRuntime) Scale(s *runtime.Service, replicas int, opts ...runtime.UpdateOption) error {
+	if replicas < 1 {
+		return runtime.ErrInvalidScale
+	}
+
+	var options runtime.UpdateOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = defaultNamespace
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	service, ok := r.namespaces[options.Namespace][serviceKey(s)]
+	if !ok {
+		return runtime.ErrNotFound
+	}
+
+	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Debugf("Runtime scaling service %s from %d to %d replicas", s.Name, len(service.replicas)+1, replicas)
+	}
+
+	for len(service.replicas)+1 < replicas {
+		replica := service.replica()
+		if err := replica.Start(); err != nil {
+			return err
+		}
+		service.replicas = append(service.replicas, replica)
+	}
+
+	for len(service.replicas)+1 > replicas {
+		replica := service.replicas[len(service.replicas)-1]
+		service.replicas = service.replicas[:len(service.replicas)-1]
+		if err := replica.Stop(); err != nil && err.Error() != "no such process" {
+			return err
+		}
+	}
+
+	service.Lock()
+	if service.Metadata == nil {
+		service.Metadata = make(map[string]string)
+	}
+	service.Metadata["replicas"] = strconv.Itoa(replicas)
+	service.Unlock()
+
+	return nil
+}
+
 // Start starts the runtime
 func (r *localRuntime) Start() error {
 	r.Lock()
@@ -622,6 +907,9 @@ func (r *localRuntime) Stop() error {
 				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 					logger.Debugf("Runtime stopping %s", service.Name)
 				}
+				for _, replica := range service.replicas {
+					replica.Stop()
+				}
 				service.Stop()
 			}
 		}
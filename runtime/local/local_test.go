@@ -1,10 +1,18 @@
 package local
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/micro/go-micro/v3/build"
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/registry/memory"
+	"github.com/micro/go-micro/v3/runtime"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,3 +39,200 @@ func TestEntrypoint(t *testing.T) {
 	assert.Error(t, err, "Expected entrypoint to return an error when no main.go files exist")
 	assert.Equal(t, "", result, "Expected entrypoint to not return a result")
 }
+
+func TestExec(t *testing.T) {
+	r := NewRuntime().(*localRuntime)
+
+	svc := &runtime.Service{Name: "foo", Version: "latest"}
+	r.namespaces[defaultNamespace] = map[string]*service{
+		serviceKey(svc): newService(svc, runtime.CreateOptions{Env: []string{"FOO=bar"}}),
+	}
+
+	// the command runs in the environment of the service
+	out := new(bytes.Buffer)
+	err := r.Exec(svc, []string{"sh", "-c", "echo $FOO"}, runtime.ExecOutput(out, nil))
+	assert.Nil(t, err, "Expected the command to succeed")
+	assert.Equal(t, "bar\n", out.String(), "Expected the command to have the service environment")
+
+	err = r.Exec(svc, []string{"sh", "-c", "exit 3"})
+	assert.Equal(t, &runtime.ExitError{Code: 3}, err, "Expected the exit code of the command")
+
+	err = r.Exec(svc, nil)
+	assert.Equal(t, runtime.ErrNoCommand, err, "Expected a command to be required")
+
+	err = r.Exec(&runtime.Service{Name: "bar"}, []string{"true"})
+	assert.Error(t, err, "Expected an error for an unknown service")
+}
+
+func TestScale(t *testing.T) {
+	r := NewRuntime().(*localRuntime)
+
+	svc := &runtime.Service{Name: "foo", Version: "latest"}
+	srv := newService(svc, runtime.CreateOptions{Command: []string{"sleep"}, Args: []string{"30"}})
+	assert.Nil(t, srv.Start(), "Expected the service to start")
+	r.namespaces[defaultNamespace] = map[string]*service{serviceKey(svc): srv}
+	defer r.Delete(svc)
+
+	err := r.Scale(svc, 3)
+	assert.Nil(t, err, "Expected the service to scale up")
+	replicas, _ := r.Replicas(svc)
+	assert.Equal(t, 3, replicas, "Expected 3 replicas")
+	for _, replica := range srv.replicas {
+		assert.True(t, replica.Running(), "Expected the replicas to be running")
+	}
+	assert.Equal(t, "3", svc.Metadata["replicas"], "Expected the replicas in the metadata")
+
+	stopped := srv.replicas[1]
+	err = r.Scale(svc, 2)
+	assert.Nil(t, err, "Expected the service to scale down")
+	replicas, _ = r.Replicas(svc)
+	assert.Equal(t, 2, replicas, "Expected 2 replicas")
+	assert.False(t, stopped.Running(), "Expected the last replica to be stopped")
+
+	err = r.Scale(svc, 0)
+	assert.Equal(t, runtime.ErrInvalidScale, err, "Expected at least 1 replica")
+
+	_, err = r.Replicas(&runtime.Service{Name: "bar"})
+	assert.Equal(t, runtime.ErrNotFound, err, "Expected an error for an unknown service")
+}
+
+func TestRestartPolicy(t *testing.T) {
+	testData := []struct {
+		policy  runtime.RestartPolicy
+		command string
+		restart bool
+	}{
+		{runtime.RestartNever, "exit 1", false},
+		{runtime.RestartOnFailure, "exit 1", true},
+		{runtime.RestartOnFailure, "exit 0", false},
+		{runtime.RestartAlways, "exit 0", true},
+		{"", "exit 0", true},
+	}
+
+	for _, d := range testData {
+		svc := &runtime.Service{Name: "foo", Version: "latest"}
+		srv := newService(svc, runtime.CreateOptions{
+			Command: []string{"sh"},
+			Args:    []string{"-c", d.command},
+			Restart: d.policy,
+			Retries: 1,
+		})
+		assert.Nil(t, srv.Start(), "Expected the service to start")
+
+		for srv.Running() {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		// wait for the backoff
+		time.Sleep(200 * time.Millisecond)
+		assert.Equal(t, d.restart, srv.ShouldStart(), "Unexpected restart for %q policy after %q", d.policy, d.command)
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	foo := newService(&runtime.Service{Name: "foo"}, runtime.CreateOptions{Dependencies: []string{"bar"}})
+	bar := newService(&runtime.Service{Name: "bar"}, runtime.CreateOptions{Command: []string{"sleep"}, Args: []string{"30"}})
+	services := map[string]*service{foo.key(): foo, bar.key(): bar}
+
+	assert.False(t, dependenciesRunning(services, foo), "Expected foo to wait for bar")
+	assert.True(t, dependenciesRunning(services, bar), "Expected bar to have no dependencies")
+
+	assert.Nil(t, bar.Start(), "Expected bar to start")
+	defer bar.Stop()
+	assert.True(t, dependenciesRunning(services, foo), "Expected foo to start once bar is running")
+}
+
+// testBuild writes a script running the service to the source path
+type testBuild struct{}
+
+func (b *testBuild) Package(name string, src *build.Source, opts ...build.PackageOption) (*build.Package, error) {
+	options := build.NewPackageOptions(opts...)
+	fmt.Fprintf(options.Output, "building %s\n", name)
+
+	path := filepath.Join(src.Path, name)
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\nsleep 30\n"), 0755); err != nil {
+		return nil, err
+	}
+	return &build.Package{Name: name, Path: path, Source: src}, nil
+}
+
+func (b *testBuild) Remove(p *build.Package) error {
+	return os.Remove(p.Path)
+}
+
+func (b *testBuild) String() string {
+	return "test"
+}
+
+func TestBuild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "micro-build")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	LogDir = dir
+	defer func() {
+		LogDir = filepath.Join(os.TempDir(), "micro", "logs")
+	}()
+
+	r := NewRuntime(runtime.WithBuild(new(testBuild))).(*localRuntime)
+	svc := &runtime.Service{Name: "foo", Version: "latest"}
+
+	pkg, err := r.build(svc, dir, nil)
+	assert.Nil(t, err, "Expected the source to build")
+	assert.Equal(t, filepath.Join(dir, "foo-latest"), pkg.Path, "Expected the package of the service")
+
+	// the build logs are streamed with the service logs
+	logs, err := r.Logs(svc, runtime.LogsCount(1))
+	assert.Nil(t, err)
+	defer logs.Stop()
+
+	select {
+	case l := <-logs.Chan():
+		assert.Equal(t, "building foo-latest", l.Message, "Expected the build logs")
+	case <-time.After(time.Second):
+		t.Fatal("Expected the build logs")
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	reg := memory.NewRegistry()
+	r := NewRuntime(runtime.WithRegistry(reg)).(*localRuntime)
+
+	v1 := &runtime.Service{Name: "foo", Version: "v1"}
+	assert.Nil(t, r.Create(v1, runtime.WithCommand("sleep"), runtime.WithArgs("30")), "Expected the service to be created")
+
+	traffic := func() map[string]string {
+		srvs, err := reg.GetService("foo")
+		assert.Nil(t, err)
+		versions := make(map[string]string)
+		for _, srv := range srvs {
+			versions[srv.Version] = srv.Metadata[registry.MetadataTraffic]
+		}
+		return versions
+	}
+
+	// the canary runs alongside the previous version
+	v2 := &runtime.Service{Name: "foo", Version: "v2"}
+	err := r.Update(v2, runtime.UpdateCanary(10))
+	assert.Nil(t, err, "Expected the canary to be deployed")
+	defer r.Delete(v2)
+
+	srvs, _ := r.Read(runtime.ReadService("foo"))
+	assert.Len(t, srvs, 2, "Expected both versions to be running")
+	assert.Equal(t, map[string]string{"v1": "90", "v2": "10"}, traffic(), "Expected the traffic to be split")
+
+	// the cutover switches the traffic and removes the previous version
+	err = r.Update(v2, runtime.UpdateStrategy(runtime.BlueGreen))
+	assert.Nil(t, err, "Expected the cutover to complete")
+
+	srvs, _ = r.Read(runtime.ReadService("foo"))
+	assert.Len(t, srvs, 1, "Expected only the new version to be running")
+	assert.Equal(t, "v2", srvs[0].Version)
+	assert.Equal(t, "100", traffic()["v2"], "Expected the new version to receive all the traffic")
+
+	err = r.Update(&runtime.Service{Name: "bar", Version: "v1"}, runtime.UpdateStrategy(runtime.BlueGreen))
+	assert.Equal(t, runtime.ErrNoPreviousVersion, err, "Expected a version to deploy alongside")
+
+	err = NewRuntime().Update(v2, runtime.UpdateCanary(10))
+	assert.Equal(t, runtime.ErrNoRegistry, err, "Expected a registry to split the traffic")
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/micro/go-micro/v3/runtime"
 	"github.com/micro/go-micro/v3/runtime/local/process"
 	proc "github.com/micro/go-micro/v3/runtime/local/process/os"
+	"github.com/micro/go-micro/v3/util/backoff"
 )
 
 type service struct {
@@ -26,6 +27,8 @@ type service struct {
 
 	retries    int
 	maxRetries int
+	// exited is when the process last exited
+	exited time.Time
 
 	// output for logs
 	output io.Writer
@@ -38,6 +41,15 @@ type service struct {
 	Exec *process.Binary
 	// process pid
 	PID *process.PID
+
+	// options the service was created with
+	options runtime.CreateOptions
+	// createOpts are passed to create to run other versions of the service
+	createOpts []runtime.CreateOption
+	// pkg is the package built from the source if any
+	pkg *build.Package
+	// replicas are the additional processes running the service
+	replicas []*service
 }
 
 func newService(s *runtime.Service, c runtime.CreateOptions) *service {
@@ -48,26 +60,7 @@ func newService(s *runtime.Service, c runtime.CreateOptions) *service {
 	exec = strings.Join(c.Command, " ")
 	args = c.Args
 
-	dir := s.Source
-
-	// For uploaded packages, we upload the whole repo
-	// so the correct working directory to do a `go run .`
-	// needs to include the relative path from the repo root
-	// which is the service name.
-	//
-	// Could use a better upload check.
-	if strings.Contains(s.Source, "uploads") {
-		// There are two cases to consider here:
-		// a., if the uploaded code comes from a repo - in this case
-		// the service name is the relative path.
-		// b., if the uploaded code comes from a non repo folder -
-		// in this case the service name is the folder name.
-		// Because of this, we only append the service name to the source in
-		// case `a`
-		if ex, err := exists(filepath.Join(s.Source, s.Name)); err == nil && ex {
-			dir = filepath.Join(s.Source, s.Name)
-		}
-	}
+	dir := sourceDir(s.Name, s.Source)
 
 	return &service{
 		Service: s,
@@ -85,7 +78,44 @@ func newService(s *runtime.Service, c runtime.CreateOptions) *service {
 		output:     c.Output,
 		updated:    time.Now(),
 		maxRetries: c.Retries,
+		options:    c,
+	}
+}
+
+// replica returns a new process of the service, it has its own copy
+// of the service so the status of each process is tracked separately
+func (s *service) replica() *service {
+	srv := newService(&runtime.Service{
+		Name:     s.Name,
+		Version:  s.Version,
+		Source:   s.Source,
+		Metadata: make(map[string]string),
+	}, s.options)
+	srv.output = s.output
+	return srv
+}
+
+// sourceDir returns the directory of the service source
+func sourceDir(name, source string) string {
+	// For uploaded packages, we upload the whole repo
+	// so the correct working directory to do a `go run .`
+	// needs to include the relative path from the repo root
+	// which is the service name.
+	//
+	// Could use a better upload check.
+	if strings.Contains(source, "uploads") {
+		// There are two cases to consider here:
+		// a., if the uploaded code comes from a repo - in this case
+		// the service name is the relative path.
+		// b., if the uploaded code comes from a non repo folder -
+		// in this case the service name is the folder name.
+		// Because of this, we only append the service name to the source in
+		// case `a`
+		if ex, err := exists(filepath.Join(source, name)); err == nil && ex {
+			return filepath.Join(source, name)
+		}
 	}
+	return source
 }
 
 func (s *service) streamOutput() {
@@ -97,7 +127,23 @@ func (s *service) shouldStart() bool {
 	if s.running {
 		return false
 	}
-	return s.retries <= s.maxRetries
+
+	// not started yet or stopped by the runtime
+	if s.exited.IsZero() {
+		return true
+	}
+
+	switch s.options.Restart {
+	case runtime.RestartNever:
+		return false
+	case runtime.RestartOnFailure:
+		if s.err == nil || s.retries > s.maxRetries {
+			return false
+		}
+	}
+
+	// back off after consecutive failures
+	return time.Since(s.exited) >= backoff.Do(s.retries)
 }
 
 func (s *service) key() string {
@@ -128,7 +174,7 @@ func (s *service) Start() error {
 	// reset
 	s.err = nil
 	s.closed = make(chan bool)
-	s.retries = 0
+	s.exited = time.Time{}
 
 	if s.Metadata == nil {
 		s.Metadata = make(map[string]string)
@@ -188,6 +234,7 @@ func (s *service) Stop() error {
 		close(s.closed)
 		s.running = false
 		s.retries = 0
+		s.exited = time.Time{}
 		if s.PID == nil {
 			return nil
 		}
@@ -234,6 +281,13 @@ func (s *service) Wait() {
 		return
 	}
 
+	// stopped by the runtime rather than exiting
+	select {
+	case <-s.closed:
+		return
+	default:
+	}
+
 	// save the error
 	if err != nil {
 		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
@@ -245,9 +299,11 @@ func (s *service) Wait() {
 
 		s.err = err
 	} else {
+		s.retries = 0
 		s.Status("done", nil)
 	}
 
 	// no longer running
 	s.running = false
+	s.exited = time.Now()
 }
@@ -3,8 +3,11 @@ package runtime
 import (
 	"context"
 	"io"
+	"time"
 
+	"github.com/micro/go-micro/v3/build"
 	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/registry"
 )
 
 type Option func(o *Options)
@@ -21,6 +24,13 @@ type Options struct {
 	Image string
 	// Client to use when making requests
 	Client client.Client
+	// Build packages the source of services before they're run
+	Build build.Build
+	// Registry the traffic of the versions of a service is split in
+	Registry registry.Registry
+	// Other options for implementations of the interface
+	// can be stored in a context
+	Context context.Context
 }
 
 // WithSource sets the base image / repository
@@ -30,6 +40,20 @@ func WithSource(src string) Option {
 	}
 }
 
+// WithBuild sets the builder packaging the source of services
+func WithBuild(b build.Build) Option {
+	return func(o *Options) {
+		o.Build = b
+	}
+}
+
+// WithRegistry sets the registry used to split the traffic between versions of a service
+func WithRegistry(r registry.Registry) Option {
+	return func(o *Options) {
+		o.Registry = r
+	}
+}
+
 // WithScheduler specifies a scheduler for updates
 func WithScheduler(n Scheduler) Option {
 	return func(o *Options) {
@@ -76,6 +100,11 @@ type CreateOptions struct {
 	Type string
 	// Retries before failing deploy
 	Retries int
+	// Restart policy of the service, it's always restarted by default
+	Restart RestartPolicy
+	// Dependencies are the names of the services which must be running
+	// before the service is started
+	Dependencies []string
 	// Specify the image to use
 	Image string
 	// Namespace to create the service in
@@ -86,6 +115,16 @@ type CreateOptions struct {
 	Secrets map[string]string
 	// Resources to allocate the service
 	Resources *Resources
+	// Requests are the resources reserved for the service
+	Requests *Resources
+	// Liveness probe, the service is restarted when it fails
+	Liveness *Probe
+	// Readiness probe, the service isn't sent requests when it fails
+	Readiness *Probe
+	// PullSecrets are the names of the secrets used to pull the image
+	PullSecrets []string
+	// EnvFrom are the names of the secrets whose keys are set in the environment
+	EnvFrom []string
 }
 
 // ReadOptions queries runtime services
@@ -164,6 +203,20 @@ func WithRetries(retries int) CreateOption {
 	}
 }
 
+// WithRestartPolicy sets whether the service is restarted when it exits
+func WithRestartPolicy(p RestartPolicy) CreateOption {
+	return func(o *CreateOptions) {
+		o.Restart = p
+	}
+}
+
+// WithDependencies sets the services which must be running before the service is started
+func WithDependencies(services ...string) CreateOption {
+	return func(o *CreateOptions) {
+		o.Dependencies = append(o.Dependencies, services...)
+	}
+}
+
 // WithEnv sets the created service environment
 func WithEnv(env []string) CreateOption {
 	return func(o *CreateOptions) {
@@ -185,6 +238,41 @@ func ResourceLimits(r *Resources) CreateOption {
 	}
 }
 
+// ResourceRequests sets the resources reserved for the service
+func ResourceRequests(r *Resources) CreateOption {
+	return func(o *CreateOptions) {
+		o.Requests = r
+	}
+}
+
+// LivenessProbe sets the check restarting the service when it fails
+func LivenessProbe(p *Probe) CreateOption {
+	return func(o *CreateOptions) {
+		o.Liveness = p
+	}
+}
+
+// ReadinessProbe sets the check stopping requests to the service when it fails
+func ReadinessProbe(p *Probe) CreateOption {
+	return func(o *CreateOptions) {
+		o.Readiness = p
+	}
+}
+
+// WithPullSecrets sets the secrets used to pull the image
+func WithPullSecrets(secrets ...string) CreateOption {
+	return func(o *CreateOptions) {
+		o.PullSecrets = append(o.PullSecrets, secrets...)
+	}
+}
+
+// WithEnvFromSecret sets the environment from all the keys of the secrets
+func WithEnvFromSecret(secrets ...string) CreateOption {
+	return func(o *CreateOptions) {
+		o.EnvFrom = append(o.EnvFrom, secrets...)
+	}
+}
+
 // ReadService returns services with the given name
 func ReadService(service string) ReadOption {
 	return func(o *ReadOptions) {
@@ -229,6 +317,14 @@ type UpdateOptions struct {
 	Context context.Context
 	// Secrets to use
 	Secrets map[string]string
+	// Image to roll the service out with
+	Image string
+	// Wait is how long to wait for the rollout to complete, zero returns once it starts
+	Wait time.Duration
+	// Strategy used to deploy the version of the service, it's updated in place by default
+	Strategy Strategy
+	// Canary is the percentage of the traffic the version receives with the canary strategy
+	Canary int
 }
 
 // WithSecret sets a secret to provide the service with
@@ -249,6 +345,35 @@ func UpdateNamespace(ns string) UpdateOption {
 	}
 }
 
+// UpdateImage sets the image to roll the service out with
+func UpdateImage(img string) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.Image = img
+	}
+}
+
+// UpdateWait waits for the rollout to complete for up to the duration
+func UpdateWait(d time.Duration) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.Wait = d
+	}
+}
+
+// UpdateStrategy sets how the version of the service is deployed
+func UpdateStrategy(s Strategy) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.Strategy = s
+	}
+}
+
+// UpdateCanary deploys the version alongside the others with the percentage of the traffic
+func UpdateCanary(percent int) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.Strategy = Canary
+		o.Canary = percent
+	}
+}
+
 // UpdateContext sets the context
 func UpdateContext(ctx context.Context) UpdateOption {
 	return func(o *UpdateOptions) {
@@ -288,6 +413,8 @@ type LogsOptions struct {
 	Count int64
 	// Stream new lines?
 	Stream bool
+	// Since only shows the lines written after the time
+	Since time.Time
 	// Namespace the service is running in
 	Namespace string
 	// Specify the context to use
@@ -308,6 +435,13 @@ func LogsStream(stream bool) LogsOption {
 	}
 }
 
+// LogsSince only shows the lines written after the time
+func LogsSince(t time.Time) LogsOption {
+	return func(l *LogsOptions) {
+		l.Since = t
+	}
+}
+
 // LogsNamespace sets the namespace
 func LogsNamespace(ns string) LogsOption {
 	return func(o *LogsOptions) {
@@ -321,3 +455,40 @@ func LogsContext(ctx context.Context) LogsOption {
 		o.Context = ctx
 	}
 }
+
+// ExecOption configures the command run in a service
+type ExecOption func(o *ExecOptions)
+
+// ExecOptions configure the command run in a service
+type ExecOptions struct {
+	// Stdout of the command, discarded if not set
+	Stdout io.Writer
+	// Stderr of the command, discarded if not set
+	Stderr io.Writer
+	// Namespace the service is running in
+	Namespace string
+	// Specify the context to use, cancelling it stops the command
+	Context context.Context
+}
+
+// ExecOutput sets the writers of the output of the command
+func ExecOutput(stdout, stderr io.Writer) ExecOption {
+	return func(o *ExecOptions) {
+		o.Stdout = stdout
+		o.Stderr = stderr
+	}
+}
+
+// ExecNamespace sets the namespace
+func ExecNamespace(ns string) ExecOption {
+	return func(o *ExecOptions) {
+		o.Namespace = ns
+	}
+}
+
+// ExecContext sets the context
+func ExecContext(ctx context.Context) ExecOption {
+	return func(o *ExecOptions) {
+		o.Context = ctx
+	}
+}
@@ -3,13 +3,26 @@ package runtime
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
 var (
 	ErrAlreadyExists = errors.New("already exists")
+	ErrNoCommand     = errors.New("command required")
+	ErrNotFound      = errors.New("service not found")
+	ErrInvalidScale  = errors.New("replicas must be at least 1")
 )
 
+// ExitError is returned by Exec when the command exits with a non zero code
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit code %d", e.Code)
+}
+
 // Runtime is a service runtime manager
 type Runtime interface {
 	// Init initializes runtime
@@ -24,6 +37,8 @@ type Runtime interface {
 	Delete(*Service, ...DeleteOption) error
 	// Logs returns the logs for a service
 	Logs(*Service, ...LogsOption) (Logs, error)
+	// Exec runs a command in the service
+	Exec(*Service, []string, ...ExecOption) error
 	// Start starts the runtime
 	Start() error
 	// Stop shuts down the runtime
@@ -36,6 +51,40 @@ type Runtime interface {
 	DeleteNamespace(string) error
 }
 
+// RestartPolicy decides whether a service is restarted when it exits
+type RestartPolicy string
+
+const (
+	// RestartAlways restarts the service whenever it exits
+	RestartAlways RestartPolicy = "always"
+	// RestartOnFailure restarts the service when it exits with an error
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartNever leaves the service stopped once it exits
+	RestartNever RestartPolicy = "never"
+)
+
+// Strategy is how a new version of a service is deployed
+type Strategy string
+
+const (
+	// RollingUpdate replaces the version of the service in place
+	RollingUpdate Strategy = "rolling"
+	// BlueGreen runs the version alongside the others and switches all the
+	// traffic to it once it's healthy, the other versions are then deleted
+	BlueGreen Strategy = "blue-green"
+	// Canary runs the version alongside the others with a percentage of the traffic
+	// so it can be verified, a blue green update of the version completes the cutover
+	Canary Strategy = "canary"
+)
+
+// Scaler is implemented by runtimes which can run multiple replicas of a service
+type Scaler interface {
+	// Replicas returns the number of replicas the service is scaled to
+	Replicas(*Service, ...ReadOption) (int, error)
+	// Scale the service to the number of replicas without restarting it
+	Scale(*Service, int, ...UpdateOption) error
+}
+
 // Logs returns a log stream
 type Logs interface {
 	Error() error
@@ -121,3 +170,17 @@ type Resources struct {
 	// e.g. 128 MiB of memory would be passed as 128
 	Disk int
 }
+
+// Probe checks the health of a service
+type Probe struct {
+	// Path of the http health endpoint, the port is dialled if blank
+	Path string
+	// Port to check, the service port if not set
+	Port int
+	// Delay before the first check
+	Delay time.Duration
+	// Interval between checks
+	Interval time.Duration
+	// Failures is the number of failed checks before the service is unhealthy
+	Failures int
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/sync"
+	"github.com/micro/go-micro/v3/sync/memory"
+)
+
+type Options struct {
+	// Node is the id of this instance recorded in the history
+	Node string
+	// Store the execution history is kept in
+	Store store.Store
+	// Sync used to lock the runs so each is executed once across the instances
+	Sync sync.Sync
+	// LockWait is how long to wait for the lock of a run held by another instance
+	LockWait time.Duration
+	// MaxCatchUp limits the number of missed runs executed when catching up
+	MaxCatchUp int
+}
+
+type Option func(o *Options)
+
+// Node id of the instance
+func Node(id string) Option {
+	return func(o *Options) {
+		o.Node = id
+	}
+}
+
+// Store for the execution history, it must be shared by the instances
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// Sync to lock the runs with, it must be shared by the instances
+func Sync(s sync.Sync) Option {
+	return func(o *Options) {
+		o.Sync = s
+	}
+}
+
+// LockWait for a run locked by another instance
+func LockWait(d time.Duration) Option {
+	return func(o *Options) {
+		o.LockWait = d
+	}
+}
+
+// MaxCatchUp is the maximum number of missed runs to execute
+func MaxCatchUp(n int) Option {
+	return func(o *Options) {
+		o.MaxCatchUp = n
+	}
+}
+
+func newOptions(opts ...Option) Options {
+	options := Options{
+		Node:       uuid.New().String(),
+		Store:      store.DefaultStore,
+		LockWait:   time.Second,
+		MaxCatchUp: 100,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.Sync == nil {
+		options.Sync = memory.NewSync()
+	}
+	return options
+}
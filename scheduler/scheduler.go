@@ -0,0 +1,348 @@
+// Package scheduler runs cron jobs once across the instances of a service. Each run is
+// locked with the sync and recorded in the store so only one instance executes it.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/store"
+	msync "github.com/micro/go-micro/v3/sync"
+	"github.com/micro/go-micro/v3/util/jitter"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	// ErrMissingName is returned when registering a job without a name
+	ErrMissingName = errors.New("missing job name")
+	// ErrMissingRun is returned when registering a job without a run func
+	ErrMissingRun = errors.New("missing job run func")
+	// ErrDuplicateJob is returned when registering a job with the name of another
+	ErrDuplicateJob = errors.New("job already registered")
+)
+
+// CatchUp is the policy for the runs missed while no instance was running
+type CatchUp int
+
+const (
+	// CatchUpNone skips the missed runs
+	CatchUpNone CatchUp = iota
+	// CatchUpLatest executes the most recent missed run
+	CatchUpLatest
+	// CatchUpAll executes every missed run up to the MaxCatchUp option
+	CatchUpAll
+)
+
+// Job executed on a schedule
+type Job struct {
+	// Name of the job, unique across the service
+	Name string
+	// Schedule is a standard cron spec e.g "*/5 * * * *" or a descriptor e.g "@hourly" or "@every 1m"
+	Schedule string
+	// Run the job, the context is cancelled when the timeout is reached or the scheduler stops
+	Run func(ctx context.Context) error
+	// CatchUp policy for the missed runs
+	CatchUp CatchUp
+	// Jitter is the maximum random delay before running so the instances don't contend at once
+	Jitter time.Duration
+	// Timeout of a run, zero is no timeout
+	Timeout time.Duration
+}
+
+// Run of a job recorded in the history
+type Run struct {
+	// Job name
+	Job string `json:"job"`
+	// Scheduled is the time the run was scheduled for
+	Scheduled time.Time `json:"scheduled"`
+	// Started is when the run started
+	Started time.Time `json:"started"`
+	// Finished is when the run finished, zero while running
+	Finished time.Time `json:"finished,omitempty"`
+	// Node is the instance which executed the run
+	Node string `json:"node"`
+	// Error returned by the run if any
+	Error string `json:"error,omitempty"`
+}
+
+type job struct {
+	*Job
+	schedule cron.Schedule
+}
+
+// Scheduler executes the registered jobs on their schedules
+type Scheduler struct {
+	opts Options
+
+	sync.Mutex
+	jobs    map[string]*job
+	running bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// key of a run in the store and of its lock
+func key(name string, t time.Time) string {
+	return fmt.Sprintf("scheduler/%s/%020d", name, t.Unix())
+}
+
+// runs returns the scheduled times of the recorded runs of the job in order
+func (s *Scheduler) runs(name string) ([]time.Time, error) {
+	prefix := "scheduler/" + name + "/"
+	keys, err := s.opts.Store.List(store.ListPrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	times := make([]time.Time, 0, len(keys))
+	for _, k := range keys {
+		ts, err := strconv.ParseInt(strings.TrimPrefix(k, prefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		times = append(times, time.Unix(ts, 0))
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times, nil
+}
+
+// save the run in the history
+func (s *Scheduler) save(r *Run) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.opts.Store.Write(&store.Record{Key: key(r.Job, r.Scheduled), Value: b})
+}
+
+// execute the run of the job scheduled at t unless another instance has
+func (s *Scheduler) execute(ctx context.Context, j *job, t time.Time) {
+	if j.Jitter > 0 {
+		select {
+		case <-time.After(jitter.Do(j.Jitter)):
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	id := key(j.Name, t)
+	lopts := []msync.LockOption{msync.LockWait(s.opts.LockWait)}
+	if j.Timeout > 0 {
+		lopts = append(lopts, msync.LockTTL(j.Timeout+s.opts.LockWait))
+	} else {
+		// the lock is renewed while the run lasts so it's released if the instance dies
+		lopts = append(lopts, msync.LockRenew())
+	}
+
+	// another instance is executing the run
+	if err := s.opts.Sync.Lock(id, lopts...); err != nil {
+		return
+	}
+	defer s.opts.Sync.Unlock(id)
+
+	// another instance has executed the run
+	if recs, err := s.opts.Store.Read(id); err == nil && len(recs) > 0 {
+		return
+	} else if err != nil && err != store.ErrNotFound {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error reading run %s of job %s: %v", t, j.Name, err)
+		}
+		return
+	}
+
+	// the run is recorded before it starts so it isn't executed again
+	r := &Run{Job: j.Name, Scheduled: t, Started: time.Now(), Node: s.opts.Node}
+	if err := s.save(r); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error recording run %s of job %s: %v", t, j.Name, err)
+		}
+		return
+	}
+
+	rctx, cancel := ctx, context.CancelFunc(func() {})
+	if j.Timeout > 0 {
+		rctx, cancel = context.WithTimeout(ctx, j.Timeout)
+	}
+	err := j.Run(rctx)
+	cancel()
+
+	r.Finished = time.Now()
+	if err != nil {
+		r.Error = err.Error()
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Job %s run %s failed: %v", j.Name, t, err)
+		}
+	}
+
+	if err := s.save(r); err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+		logger.Errorf("Error recording run %s of job %s: %v", t, j.Name, err)
+	}
+}
+
+// catchUp executes the runs missed since the last recorded run
+func (s *Scheduler) catchUp(ctx context.Context, j *job) {
+	if j.CatchUp == CatchUpNone {
+		return
+	}
+
+	times, err := s.runs(j.Name)
+	if err != nil || len(times) == 0 {
+		return
+	}
+
+	var missed []time.Time
+	now := time.Now()
+	for t := j.schedule.Next(times[len(times)-1]); !t.After(now); t = j.schedule.Next(t) {
+		missed = append(missed, t)
+		// keep the most recent
+		if len(missed) > s.opts.MaxCatchUp {
+			missed = missed[1:]
+		}
+	}
+
+	if j.CatchUp == CatchUpLatest && len(missed) > 1 {
+		missed = missed[len(missed)-1:]
+	}
+
+	for _, t := range missed {
+		if ctx.Err() != nil {
+			return
+		}
+		s.execute(ctx, j, t)
+	}
+}
+
+// run the job on its schedule, a run which overruns the next skips it
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	s.catchUp(ctx, j)
+
+	for {
+		next := j.schedule.Next(time.Now())
+		t := time.NewTimer(time.Until(next))
+
+		select {
+		case <-t.C:
+			s.execute(ctx, j, next)
+		case <-ctx.Done():
+			t.Stop()
+			return
+		}
+	}
+}
+
+// Register a job, it's scheduled straight away if the scheduler is running
+func (s *Scheduler) Register(j *Job) error {
+	if len(j.Name) == 0 {
+		return ErrMissingName
+	}
+	if j.Run == nil {
+		return ErrMissingRun
+	}
+
+	sched, err := cron.ParseStandard(j.Schedule)
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.jobs[j.Name]; ok {
+		return ErrDuplicateJob
+	}
+
+	jb := &job{Job: j, schedule: sched}
+	s.jobs[j.Name] = jb
+
+	if s.running {
+		s.wg.Add(1)
+		go s.run(s.ctx, jb)
+	}
+
+	return nil
+}
+
+// History returns the most recent runs of the job, the latest first, a limit of zero is unlimited
+func (s *Scheduler) History(name string, limit uint) ([]*Run, error) {
+	times, err := s.runs(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []*Run
+	for i := len(times) - 1; i >= 0; i-- {
+		if limit > 0 && uint(len(history)) == limit {
+			break
+		}
+
+		recs, err := s.opts.Store.Read(key(name, times[i]))
+		if err == store.ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		var r *Run
+		if err := json.Unmarshal(recs[0].Value, &r); err != nil {
+			continue
+		}
+		history = append(history, r)
+	}
+
+	return history, nil
+}
+
+// Start scheduling the jobs
+func (s *Scheduler) Start() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.running {
+		return nil
+	}
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.run(s.ctx, j)
+	}
+
+	s.running = true
+
+	return nil
+}
+
+// Stop scheduling the jobs, the running jobs are cancelled and waited for
+func (s *Scheduler) Stop() error {
+	s.Lock()
+	if !s.running {
+		s.Unlock()
+		return nil
+	}
+	s.cancel()
+	s.running = false
+	s.Unlock()
+
+	s.wg.Wait()
+
+	return nil
+}
+
+// New returns a scheduler, register the jobs and start it
+func New(opts ...Option) *Scheduler {
+	return &Scheduler{
+		opts: newOptions(opts...),
+		jobs: make(map[string]*job),
+	}
+}
@@ -0,0 +1,166 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/store/memory"
+	msync "github.com/micro/go-micro/v3/sync"
+	smemory "github.com/micro/go-micro/v3/sync/memory"
+)
+
+func TestRegister(t *testing.T) {
+	s := New(Store(memory.NewStore()))
+	run := func(context.Context) error { return nil }
+
+	testCases := []struct {
+		Name  string
+		Job   *Job
+		Error bool
+	}{
+		{"MissingName", &Job{Schedule: "@hourly", Run: run}, true},
+		{"MissingRun", &Job{Name: "foo", Schedule: "@hourly"}, true},
+		{"InvalidSchedule", &Job{Name: "foo", Schedule: "foo", Run: run}, true},
+		{"Valid", &Job{Name: "foo", Schedule: "*/5 * * * *", Run: run}, false},
+		{"Duplicate", &Job{Name: "foo", Schedule: "@hourly", Run: run}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if err := s.Register(tc.Job); (err != nil) != tc.Error {
+				t.Fatalf("Unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestExactlyOnce(t *testing.T) {
+	st := memory.NewStore()
+	sy := smemory.NewSync()
+
+	var calls int32
+	job := func() *Job {
+		return &Job{
+			Name:     "foo",
+			Schedule: "@every 1s",
+			Run: func(context.Context) error {
+				atomic.AddInt32(&calls, 1)
+				return nil
+			},
+		}
+	}
+
+	// two instances share the store and sync
+	var schedulers []*Scheduler
+	for _, node := range []string{"a", "b"} {
+		s := New(Node(node), Store(st), Sync(sy), LockWait(time.Millisecond*100))
+		if err := s.Register(job()); err != nil {
+			t.Fatal(err)
+		}
+		s.Start()
+		schedulers = append(schedulers, s)
+	}
+
+	time.Sleep(time.Millisecond * 2500)
+	for _, s := range schedulers {
+		s.Stop()
+	}
+
+	history, err := schedulers[0].History("foo", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) < 2 || int32(len(history)) != atomic.LoadInt32(&calls) {
+		t.Fatalf("Expected each run to be executed once, got %v runs and %v calls", len(history), calls)
+	}
+	for i, r := range history {
+		if r.Finished.IsZero() || len(r.Node) == 0 {
+			t.Fatalf("Unexpected run %+v", r)
+		}
+		if i > 0 && !r.Scheduled.Before(history[i-1].Scheduled) {
+			t.Fatalf("Expected the latest run first, got %+v", history)
+		}
+	}
+
+	if history, _ := schedulers[1].History("foo", 1); len(history) != 1 {
+		t.Fatalf("Expected the history to be limited, got %v", len(history))
+	}
+}
+
+func TestCatchUp(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		CatchUp CatchUp
+		Runs    int32
+	}{
+		{"None", CatchUpNone, 0},
+		{"Latest", CatchUpLatest, 1},
+		{"All", CatchUpAll, 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			s := New(Store(memory.NewStore()))
+
+			// the last run was over 5 hours ago
+			last := time.Now().Add(-time.Hour*5 - time.Minute)
+			if err := s.save(&Run{Job: "foo", Scheduled: last, Started: last, Finished: last}); err != nil {
+				t.Fatal(err)
+			}
+
+			var calls int32
+			s.Register(&Job{
+				Name:     "foo",
+				Schedule: "@every 1h",
+				CatchUp:  tc.CatchUp,
+				Run: func(context.Context) error {
+					atomic.AddInt32(&calls, 1)
+					return nil
+				},
+			})
+			s.Start()
+
+			deadline := time.Now().Add(time.Second)
+			for atomic.LoadInt32(&calls) < tc.Runs && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond * 10)
+			}
+			time.Sleep(time.Millisecond * 50)
+			s.Stop()
+
+			if c := atomic.LoadInt32(&calls); c != tc.Runs {
+				t.Fatalf("Expected %v runs, got %v", tc.Runs, c)
+			}
+		})
+	}
+}
+
+// lockSync records the options of the locks
+type lockSync struct {
+	msync.Sync
+	opts msync.LockOptions
+}
+
+func (l *lockSync) Lock(id string, opts ...msync.LockOption) error {
+	l.opts = msync.NewLockOptions(opts...)
+	return l.Sync.Lock(id, opts...)
+}
+
+func TestLockExpiry(t *testing.T) {
+	sy := &lockSync{Sync: smemory.NewSync()}
+	s := New(Store(memory.NewStore()), Sync(sy))
+
+	// the lock of a run without a timeout is released if the instance dies
+	j := &job{Job: &Job{Name: "foo", Run: func(context.Context) error { return nil }}}
+	s.execute(context.Background(), j, time.Now())
+	if !sy.opts.Renew && sy.opts.TTL <= 0 {
+		t.Fatalf("Expected the lock to expire, got %+v", sy.opts)
+	}
+
+	j.Timeout = time.Minute
+	s.execute(context.Background(), j, time.Now().Add(time.Minute))
+	if sy.opts.TTL < time.Minute {
+		t.Fatalf("Expected the lock to outlive the timeout, got %+v", sy.opts)
+	}
+}
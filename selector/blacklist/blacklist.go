@@ -0,0 +1,164 @@
+// Package blacklist provides a selector which stops selecting the nodes that
+// failed with connection errors until they recover
+package blacklist
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	merrors "github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/selector"
+)
+
+type node struct {
+	// consecutive failures of the node
+	failures int
+	// time until which the node isn't selected
+	expiry time.Time
+}
+
+type blacklist struct {
+	selector.Selector
+	opts Options
+
+	sync.Mutex
+	nodes map[string]*node
+}
+
+// NewSelector wraps the selector so the nodes whose calls failed because they're
+// unavailable are blacklisted. A node is blacklisted for a period doubling with every
+// consecutive failure, after which it's selected once more to probe whether it recovered.
+// A successful call re-admits it.
+func NewSelector(s selector.Selector, opts ...Option) selector.Selector {
+	options := Options{
+		Base:        DefaultBase,
+		Max:         DefaultMax,
+		Unavailable: IsUnavailable,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &blacklist{
+		Selector: s,
+		opts:     options,
+		nodes:    make(map[string]*node),
+	}
+}
+
+// backoff returns how long a node is blacklisted for after the failures
+func (b *blacklist) backoff(failures int) time.Duration {
+	d := b.opts.Base
+	for i := 1; i < failures && d < b.opts.Max; i++ {
+		d *= 2
+	}
+	if d > b.opts.Max {
+		d = b.opts.Max
+	}
+	return d
+}
+
+// filter returns the routes which aren't blacklisted
+func (b *blacklist) filter(routes []string) []string {
+	b.Lock()
+	defer b.Unlock()
+
+	if len(b.nodes) == 0 {
+		return routes
+	}
+
+	now := time.Now()
+	available := make([]string, 0, len(routes))
+
+	for _, route := range routes {
+		n, ok := b.nodes[route]
+		if !ok {
+			available = append(available, route)
+			continue
+		}
+		if now.Before(n.expiry) {
+			continue
+		}
+		// admit the node to probe whether it recovered, pushing back the expiry
+		// so it's only probed once per period until the outcome is recorded
+		n.expiry = now.Add(b.backoff(n.failures))
+		available = append(available, route)
+	}
+
+	return available
+}
+
+func (b *blacklist) Select(routes []string, opts ...selector.SelectOption) (selector.Next, error) {
+	available := b.filter(routes)
+
+	// every node is blacklisted, rather than refusing the call let it be
+	// attempted so the caller gets the actual error
+	if len(available) == 0 {
+		available = routes
+	}
+
+	return b.Selector.Select(available, opts...)
+}
+
+func (b *blacklist) Record(addr string, err error) error {
+	b.Lock()
+	switch {
+	case err == nil:
+		delete(b.nodes, addr)
+	case b.opts.Unavailable(err):
+		n, ok := b.nodes[addr]
+		if !ok {
+			n = new(node)
+			b.nodes[addr] = n
+		}
+		n.failures++
+		n.expiry = time.Now().Add(b.backoff(n.failures))
+	}
+	b.Unlock()
+
+	return b.Selector.Record(addr, err)
+}
+
+func (b *blacklist) Reset() error {
+	b.Lock()
+	b.nodes = make(map[string]*node)
+	b.Unlock()
+
+	return b.Selector.Reset()
+}
+
+func (b *blacklist) String() string {
+	return "blacklist"
+}
+
+// IsUnavailable reports whether the error means the node couldn't be reached
+// or timed out, which are the errors blacklisting a node by default
+func IsUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var nerr net.Error
+	if errors.As(err, &nerr) && nerr.Timeout() {
+		return true
+	}
+
+	e := merrors.FromError(err)
+	if e.Code == 408 {
+		return true
+	}
+
+	// the clients report failures to connect as internal server errors
+	detail := strings.ToLower(e.Detail)
+	return strings.Contains(detail, "connection error") ||
+		strings.Contains(detail, "connection refused") ||
+		strings.Contains(detail, "error sending request")
+}
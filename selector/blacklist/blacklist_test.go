@@ -0,0 +1,86 @@
+package blacklist
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	merrors "github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/selector"
+	"github.com/micro/go-micro/v3/selector/roundrobin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlacklist(t *testing.T) {
+	selector.Tests(t, NewSelector(roundrobin.NewSelector()))
+
+	r1 := "127.0.0.1:8000"
+	r2 := "127.0.0.1:8001"
+	routes := []string{r1, r2}
+
+	s := NewSelector(roundrobin.NewSelector(), Base(time.Millisecond*50), Max(time.Millisecond*100))
+
+	selected := func() map[string]bool {
+		next, err := s.Select(routes)
+		assert.Nil(t, err, "Error should be nil")
+		nodes := map[string]bool{}
+		for i := 0; i < 4; i++ {
+			nodes[next()] = true
+		}
+		return nodes
+	}
+
+	// errors which aren't about availability don't blacklist the node
+	s.Record(r1, merrors.BadRequest("go.micro.client", "bad request"))
+	assert.True(t, selected()[r1], "Expected r1 to be selected")
+
+	// a connection error does
+	s.Record(r1, merrors.InternalServerError("go.micro.client", "connection error: refused"))
+	assert.False(t, selected()[r1], "Expected r1 to be blacklisted")
+
+	// once the period passed it's probed a single time
+	time.Sleep(time.Millisecond * 60)
+	assert.True(t, selected()[r1], "Expected r1 to be probed")
+	assert.False(t, selected()[r1], "Expected r1 to be probed only once")
+
+	// failing the probe doubles the period
+	s.Record(r1, merrors.Timeout("go.micro.client", "timeout"))
+	time.Sleep(time.Millisecond * 60)
+	assert.False(t, selected()[r1], "Expected r1 to still be blacklisted")
+	time.Sleep(time.Millisecond * 60)
+
+	// a successful probe re-admits it
+	assert.True(t, selected()[r1], "Expected r1 to be probed")
+	s.Record(r1, nil)
+	assert.True(t, selected()[r1], "Expected r1 to be re-admitted")
+	assert.True(t, selected()[r1], "Expected r1 to be re-admitted")
+
+	// when every node is blacklisted they're all selected
+	s.Record(r1, merrors.Timeout("go.micro.client", "timeout"))
+	s.Record(r2, merrors.Timeout("go.micro.client", "timeout"))
+	nodes := selected()
+	assert.True(t, nodes[r1] && nodes[r2], "Expected every node to be selected")
+
+	// resetting clears the blacklist
+	s.Record(r1, merrors.Timeout("go.micro.client", "timeout"))
+	s.Reset()
+	assert.True(t, selected()[r1], "Expected r1 to be selected after a reset")
+}
+
+func TestIsUnavailable(t *testing.T) {
+	testData := []struct {
+		err         error
+		unavailable bool
+	}{
+		{nil, false},
+		{errors.New("dial tcp 127.0.0.1:8000: connect: connection refused"), true},
+		{merrors.Timeout("go.micro.client", "request timeout"), true},
+		{merrors.InternalServerError("go.micro.client", "Error sending request: unavailable"), true},
+		{merrors.InternalServerError("go.micro.service", "boom"), false},
+		{merrors.NotFound("go.micro.service", "not found"), false},
+	}
+
+	for _, d := range testData {
+		assert.Equal(t, d.unavailable, IsUnavailable(d.err), "Unexpected result for %v", d.err)
+	}
+}
@@ -0,0 +1,47 @@
+package blacklist
+
+import (
+	"time"
+)
+
+var (
+	// DefaultBase is how long a node is blacklisted after its first failure
+	DefaultBase = time.Second
+	// DefaultMax caps how long a node is blacklisted for
+	DefaultMax = time.Minute
+)
+
+// Options of the blacklist
+type Options struct {
+	// Base is how long a node is blacklisted after its first failure,
+	// it's doubled for every consecutive failure
+	Base time.Duration
+	// Max caps how long a node is blacklisted for
+	Max time.Duration
+	// Unavailable reports whether an error means the node is unavailable
+	Unavailable func(error) bool
+}
+
+// Option sets an option of the blacklist
+type Option func(o *Options)
+
+// Base sets how long a node is blacklisted after its first failure
+func Base(d time.Duration) Option {
+	return func(o *Options) {
+		o.Base = d
+	}
+}
+
+// Max caps how long a node is blacklisted for
+func Max(d time.Duration) Option {
+	return func(o *Options) {
+		o.Max = d
+	}
+}
+
+// Unavailable sets the func reporting whether an error means the node is unavailable
+func Unavailable(fn func(error) bool) Option {
+	return func(o *Options) {
+		o.Unavailable = fn
+	}
+}
@@ -7,7 +7,11 @@ type Options struct{}
 type Option func(*Options)
 
 // SelectOptions used to configure selection
-type SelectOptions struct{}
+type SelectOptions struct {
+	// Weights of the routes keyed by address, routes without a
+	// weight are treated as having the default weight
+	Weights map[string]int
+}
 
 // SelectOption updates the select options
 type SelectOption func(*SelectOptions)
@@ -21,3 +25,18 @@ func NewSelectOptions(opts ...SelectOption) SelectOptions {
 
 	return options
 }
+
+// Weights sets the relative weight of each route
+func Weights(w map[string]int) SelectOption {
+	return func(o *SelectOptions) {
+		o.Weights = w
+	}
+}
+
+// Weight returns the weight of the route, falling back to the default
+func (o SelectOptions) Weight(route string) int {
+	if w, ok := o.Weights[route]; ok && w > 0 {
+		return w
+	}
+	return DefaultWeight
+}
@@ -14,6 +14,13 @@ func (r *random) Select(routes []string, opts ...selector.SelectOption) (selecto
 		return nil, selector.ErrNoneAvailable
 	}
 
+	options := selector.NewSelectOptions(opts...)
+
+	// pick routes in proportion to their weight
+	if len(options.Weights) > 0 {
+		return weighted(routes, options), nil
+	}
+
 	// return the next func
 	return func() string {
 		// if there is only one route provided we'll select it
@@ -26,6 +33,27 @@ func (r *random) Select(routes []string, opts ...selector.SelectOption) (selecto
 	}, nil
 }
 
+// weighted returns a next func which selects routes at random in proportion to their weight
+func weighted(routes []string, options selector.SelectOptions) selector.Next {
+	var total int
+	weights := make([]int, len(routes))
+	for i, route := range routes {
+		weights[i] = options.Weight(route)
+		total += weights[i]
+	}
+
+	return func() string {
+		n := rand.Intn(total)
+		for i, w := range weights {
+			if n < w {
+				return routes[i]
+			}
+			n -= w
+		}
+		return routes[len(routes)-1]
+	}
+}
+
 func (r *random) Record(addr string, err error) error {
 	return nil
 }
@@ -9,3 +9,22 @@ import (
 func TestRandom(t *testing.T) {
 	selector.Tests(t, NewSelector())
 }
+
+func TestRandomWeighted(t *testing.T) {
+	r1 := "127.0.0.1:8000"
+	r2 := "127.0.0.1:8001"
+
+	next, err := NewSelector().Select([]string{r1, r2}, selector.Weights(map[string]int{r1: 1, r2: 9}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		counts[next()]++
+	}
+
+	if counts[r2] < counts[r1]*4 {
+		t.Fatalf("Expected the heavier route to be selected more often got %v", counts)
+	}
+}
@@ -16,6 +16,13 @@ func (r *roundrobin) Select(routes []string, opts ...selector.SelectOption) (sel
 		return nil, selector.ErrNoneAvailable
 	}
 
+	options := selector.NewSelectOptions(opts...)
+
+	// spread the routes in proportion to their weight
+	if len(options.Weights) > 0 {
+		return weighted(routes, options), nil
+	}
+
 	var i int
 
 	return func() string {
@@ -26,6 +33,30 @@ func (r *roundrobin) Select(routes []string, opts ...selector.SelectOption) (sel
 	}, nil
 }
 
+// weighted returns a next func using smooth weighted round robin, as done by nginx, so
+// heavier routes are interleaved with the others rather than selected in bursts
+func weighted(routes []string, options selector.SelectOptions) selector.Next {
+	var total int
+	weights := make([]int, len(routes))
+	current := make([]int, len(routes))
+	for i, route := range routes {
+		weights[i] = options.Weight(route)
+		total += weights[i]
+	}
+
+	return func() string {
+		best := 0
+		for i := range routes {
+			current[i] += weights[i]
+			if current[i] > current[best] {
+				best = i
+			}
+		}
+		current[best] -= total
+		return routes[best]
+	}
+}
+
 func (r *roundrobin) Record(addr string, err error) error { return nil }
 
 func (r *roundrobin) Reset() error { return nil }
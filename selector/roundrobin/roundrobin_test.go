@@ -38,3 +38,21 @@ func TestRoundRobin(t *testing.T) {
 	assert.Equal(t, r3, n3, "Expected route to be r3")
 
 }
+
+func TestRoundRobinWeighted(t *testing.T) {
+	r1 := "127.0.0.1:8000"
+	r2 := "127.0.0.1:8001"
+	r3 := "127.0.0.1:8002"
+
+	// r3 has no weight so uses the default
+	weights := map[string]int{r1: 200, r2: 100}
+
+	next, err := NewSelector().Select([]string{r1, r2, r3}, selector.Weights(weights))
+	assert.Nil(t, err, "Error should be nil")
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, next())
+	}
+	assert.Equal(t, []string{r1, r2, r3, r1}, got, "Expected the routes to be interleaved by weight")
+}
@@ -8,6 +8,8 @@ import (
 var (
 	// ErrNoneAvailable is returned by select when no routes were provided to select from
 	ErrNoneAvailable = errors.New("none available")
+	// DefaultWeight is the weight of a route which doesn't specify one
+	DefaultWeight = 100
 )
 
 // Selector selects a route from a pool
@@ -0,0 +1,48 @@
+package subset
+
+import (
+	"time"
+)
+
+var (
+	// DefaultSize is the number of nodes in the subset
+	DefaultSize = 20
+	// DefaultRotation is how often the subset is rotated
+	DefaultRotation = time.Hour
+)
+
+// Options of the subset
+type Options struct {
+	// Size is the number of nodes in the subset
+	Size int
+	// Rotation is how often the subset is rotated, zero never rotates it
+	Rotation time.Duration
+	// Seed the subset is derived from, it's random by default so
+	// the clients are spread evenly across the nodes
+	Seed string
+}
+
+// Option sets an option of the subset
+type Option func(o *Options)
+
+// Size sets the number of nodes in the subset
+func Size(n int) Option {
+	return func(o *Options) {
+		o.Size = n
+	}
+}
+
+// Rotation sets how often the subset is rotated
+func Rotation(d time.Duration) Option {
+	return func(o *Options) {
+		o.Rotation = d
+	}
+}
+
+// Seed sets the seed the subset is derived from, the clients with the same
+// seed select the same subset
+func Seed(s string) Option {
+	return func(o *Options) {
+		o.Seed = s
+	}
+}
@@ -0,0 +1,103 @@
+// Package subset provides a selector which only selects from a stable subset of the
+// nodes, bounding the connections and health tracking of each client in large fleets
+package subset
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/selector"
+)
+
+type subset struct {
+	selector.Selector
+	opts Options
+
+	// offset of the rotations so the clients don't all rotate at once
+	offset time.Duration
+}
+
+// NewSelector wraps the selector so it only selects from a subset of the nodes. The
+// subset is chosen by rendezvous hashing the nodes with the seed of the client, so it
+// only changes by the nodes joining or leaving it, and it's rotated periodically so
+// the load of the clients is shuffled across the fleet.
+func NewSelector(s selector.Selector, opts ...Option) selector.Selector {
+	options := Options{
+		Size:     DefaultSize,
+		Rotation: DefaultRotation,
+		Seed:     uuid.New().String(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	sub := &subset{
+		Selector: s,
+		opts:     options,
+	}
+	if options.Rotation > 0 {
+		sub.offset = time.Duration(hash(options.Seed) % uint64(options.Rotation))
+	}
+
+	return sub
+}
+
+// epoch returns the current rotation of the subset
+func (s *subset) epoch() string {
+	if s.opts.Rotation <= 0 {
+		return ""
+	}
+	return strconv.FormatInt((time.Now().UnixNano()+int64(s.offset))/int64(s.opts.Rotation), 10)
+}
+
+// filter returns the subset of the routes
+func (s *subset) filter(routes []string) []string {
+	if s.opts.Size <= 0 || len(routes) <= s.opts.Size {
+		return routes
+	}
+
+	prefix := s.opts.Seed + "/" + s.epoch() + "/"
+
+	type score struct {
+		route string
+		value uint64
+	}
+	scores := make([]score, len(routes))
+	for i, route := range routes {
+		scores[i] = score{route, hash(prefix + route)}
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].value != scores[j].value {
+			return scores[i].value > scores[j].value
+		}
+		return scores[i].route < scores[j].route
+	})
+
+	subset := make([]string, s.opts.Size)
+	for i := range subset {
+		subset[i] = scores[i].route
+	}
+	return subset
+}
+
+func (s *subset) Select(routes []string, opts ...selector.SelectOption) (selector.Next, error) {
+	return s.Selector.Select(s.filter(routes), opts...)
+}
+
+func (s *subset) String() string {
+	return "subset"
+}
+
+// hash returns the fnv hash of the string, finalised as in splitmix64 so strings
+// differing by their last bytes score far apart
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
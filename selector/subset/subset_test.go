@@ -0,0 +1,67 @@
+package subset
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/selector"
+	"github.com/micro/go-micro/v3/selector/roundrobin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubset(t *testing.T) {
+	selector.Tests(t, NewSelector(roundrobin.NewSelector()))
+
+	routes := make([]string, 1000)
+	for i := range routes {
+		routes[i] = fmt.Sprintf("10.0.%d.%d:8080", i/256, i%256)
+	}
+
+	selected := func(s selector.Selector, routes []string) map[string]bool {
+		next, err := s.Select(routes)
+		assert.Nil(t, err, "Error should be nil")
+		nodes := map[string]bool{}
+		for i := 0; i < 20; i++ {
+			nodes[next()] = true
+		}
+		return nodes
+	}
+
+	s := NewSelector(roundrobin.NewSelector(), Size(10), Seed("client-1"), Rotation(0))
+
+	// the subset is bounded and stable
+	first := selected(s, routes)
+	assert.Len(t, first, 10, "Expected the subset to have 10 nodes")
+	assert.Equal(t, first, selected(s, routes), "Expected the same subset")
+
+	// the same seed selects the same subset, others spread the clients
+	assert.Equal(t, first, selected(NewSelector(roundrobin.NewSelector(), Size(10), Seed("client-1"), Rotation(0)), routes))
+	assert.NotEqual(t, first, selected(NewSelector(roundrobin.NewSelector(), Size(10), Seed("client-2")), routes))
+
+	// a node leaving the subset is replaced by one node only
+	var removed string
+	for route := range first {
+		removed = route
+		break
+	}
+	var remaining []string
+	for _, route := range routes {
+		if route != removed {
+			remaining = append(remaining, route)
+		}
+	}
+	var changed int
+	for route := range selected(s, remaining) {
+		if !first[route] {
+			changed++
+		}
+	}
+	assert.Equal(t, 1, changed, "Expected a single node to replace the removed one")
+
+	// the subset is rotated
+	s = NewSelector(roundrobin.NewSelector(), Size(10), Rotation(50*time.Millisecond))
+	first = selected(s, routes)
+	time.Sleep(60 * time.Millisecond)
+	assert.NotEqual(t, first, selected(s, routes), "Expected the subset to be rotated")
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/debug/trace"
+	"github.com/micro/go-micro/v3/logger"
+)
+
+// ErrorTranslator maps an error returned by a handler to the error returned to the caller
+// e.g to strip internal details from it. The account of the caller is in the context.
+type ErrorTranslator func(ctx context.Context, req Request, err error) error
+
+// TranslateErrors sets the translator of the errors returned to callers, the full
+// error is logged along with the trace of the request when it's changed
+func TranslateErrors(t ErrorTranslator) Option {
+	return WrapHandler(func(fn HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req Request, rsp interface{}) error {
+			err := fn(ctx, req, rsp)
+			if err == nil {
+				return nil
+			}
+
+			terr := t(ctx, req, err)
+			if terr != err && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				traceID, _, _ := trace.FromContext(ctx)
+				logger.Errorf("Error in %s %s translated for the caller, trace %s: %v", req.Service(), req.Endpoint(), traceID, err)
+			}
+
+			return terr
+		}
+	})
+}
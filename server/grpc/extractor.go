@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/util/schema"
 )
 
 func extractValue(v reflect.Type, d int) *registry.Value {
@@ -103,6 +104,14 @@ func extractEndpoint(method reflect.Method) *registry.Endpoint {
 		}
 	}
 
+	// advertise the schema of proto messages to check the compatibility of callers
+	if v := schema.DescribeType(reqType); len(v) > 0 {
+		ep.Metadata[schema.RequestMetadata] = v
+	}
+	if v := schema.DescribeType(rspType); len(v) > 0 {
+		ep.Metadata[schema.ResponseMetadata] = v
+	}
+
 	return ep
 }
 
@@ -15,9 +15,10 @@ import (
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/micro/go-micro/v3/auth"
 	"github.com/micro/go-micro/v3/broker"
+	"github.com/micro/go-micro/v3/debug/report"
 	"github.com/micro/go-micro/v3/errors"
-	pberr "github.com/micro/go-micro/v3/errors/proto"
 	"github.com/micro/go-micro/v3/logger"
 	meta "github.com/micro/go-micro/v3/metadata"
 	"github.com/micro/go-micro/v3/registry"
@@ -32,6 +33,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
@@ -64,6 +66,10 @@ type grpcServer struct {
 
 	// registry service instance
 	rsvc *registry.Service
+	// tracks the re-registration
+	heartbeat server.Heartbeat
+	// enforces the scopes of the endpoints
+	guard server.Guard
 }
 
 func init() {
@@ -87,6 +93,10 @@ func newGRPCServer(opts ...server.Option) server.Server {
 		wg:          wait(options.Context),
 	}
 
+	// enforce the scopes declared by the endpoints before any other wrapper
+	guard := srv.guard.Wrapper(func() auth.Auth { return srv.Options().Auth })
+	srv.opts.HdlrWrappers = append([]server.HandlerWrapper{guard}, srv.opts.HdlrWrappers...)
+
 	// configure the grpc server
 	srv.configure()
 
@@ -121,11 +131,18 @@ func (g *grpcServer) configure(opts ...server.Option) {
 
 	g.wg = wait(g.opts.Context)
 
-	maxMsgSize := g.getMaxMsgSize()
+	maxRecvSize := g.getMaxMsgSize()
+	maxSendSize := maxRecvSize
+	if g.opts.MaxRecvSize > 0 {
+		maxRecvSize = g.opts.MaxRecvSize
+	}
+	if g.opts.MaxSendSize > 0 {
+		maxSendSize = g.opts.MaxSendSize
+	}
 
 	gopts := []grpc.ServerOption{
-		grpc.MaxRecvMsgSize(maxMsgSize),
-		grpc.MaxSendMsgSize(maxMsgSize),
+		grpc.MaxRecvMsgSize(maxRecvSize),
+		grpc.MaxSendMsgSize(maxSendSize),
 		grpc.UnknownServiceHandler(g.handler),
 	}
 
@@ -133,12 +150,70 @@ func (g *grpcServer) configure(opts ...server.Option) {
 		gopts = append(gopts, grpc.Creds(creds))
 	}
 
+	if g.opts.Context != nil {
+		if chain, ok := g.opts.Context.Value(unaryInterceptorsKey{}).([]grpc.UnaryServerInterceptor); ok && len(chain) > 0 {
+			gopts = append(gopts, grpc.UnaryInterceptor(chainUnary(chain)))
+		}
+		if chain, ok := g.opts.Context.Value(streamInterceptorsKey{}).([]grpc.StreamServerInterceptor); ok && len(chain) > 0 {
+			gopts = append(gopts, grpc.StreamInterceptor(chainStream(chain)))
+		}
+	}
+
 	if opts := g.getGrpcOptions(); opts != nil {
 		gopts = append(gopts, opts...)
 	}
 
 	g.rsvc = nil
 	g.srv = grpc.NewServer(gopts...)
+
+	// register the native services on the new server
+	if g.opts.Context != nil {
+		if fns, ok := g.opts.Context.Value(registerServerKey{}).([]func(*grpc.Server)); ok {
+			for _, fn := range fns {
+				fn(g.srv)
+			}
+		}
+	}
+}
+
+// chainUnary returns an interceptor calling the interceptors in order
+func chainUnary(chain []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		next := handler
+		for i := len(chain) - 1; i >= 0; i-- {
+			interceptor, h := chain[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, h)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// chainStream returns an interceptor calling the interceptors in order
+func chainStream(chain []grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		next := handler
+		for i := len(chain) - 1; i >= 0; i-- {
+			interceptor, h := chain[i], next
+			next = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, h)
+			}
+		}
+		return next(srv, ss)
+	}
+}
+
+// NativeServer returns the underlying gRPC server of a micro gRPC server, or nil for other servers.
+// Services registered on it are lost when the server is reconfigured, use RegisterServer to keep them.
+func NativeServer(s server.Server) *grpc.Server {
+	g, ok := s.(*grpcServer)
+	if !ok {
+		return nil
+	}
+	g.RLock()
+	defer g.RUnlock()
+	return g.srv
 }
 
 func (g *grpcServer) getMaxMsgSize() int {
@@ -186,6 +261,21 @@ func (g *grpcServer) getListener() net.Listener {
 	return nil
 }
 
+// reportPanic reports a panic recovered serving the stream along with its metadata
+func (g *grpcServer) reportPanic(stream grpc.ServerStream, r interface{}) {
+	ctx := stream.Context()
+	if gmd, ok := metadata.FromIncomingContext(ctx); ok {
+		md := meta.Metadata{}
+		for k, v := range gmd {
+			md[k] = strings.Join(v, ", ")
+		}
+		ctx = meta.NewContext(ctx, md)
+	}
+
+	method, _ := grpc.MethodFromServerStream(stream)
+	report.Panic(ctx, g.opts.Name, method, r)
+}
+
 func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -193,6 +283,7 @@ func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) (err err
 				logger.Error("panic recovered: ", r)
 				logger.Error(string(debug.Stack()))
 			}
+			g.reportPanic(stream, r)
 			err = errors.InternalServerError(g.opts.Name, "panic recovered: %v", r)
 		} else if err != nil {
 			if logger.V(logger.InfoLevel, logger.DefaultLogger) {
@@ -245,7 +336,7 @@ func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) (err err
 	delete(md, "timeout")
 
 	// create new context
-	ctx := meta.NewContext(stream.Context(), md)
+	ctx := meta.NewIncomingContext(stream.Context(), md)
 
 	// get peer from context
 	if p, ok := peer.FromContext(stream.Context()); ok {
@@ -407,18 +498,8 @@ func (g *grpcServer) processRequest(stream grpc.ServerStream, service *service,
 			var errStatus *status.Status
 			switch verr := appErr.(type) {
 			case *errors.Error:
-				perr := &pberr.Error{
-					Id:     verr.Id,
-					Code:   verr.Code,
-					Detail: verr.Detail,
-					Status: verr.Status,
-				}
-
 				// micro.Error now proto based and we can attach it to grpc status
-				statusCode = microError(verr)
-				statusDesc = verr.Error()
-
-				errStatus, err = status.New(statusCode, statusDesc).WithDetails(perr)
+				errStatus, err = mgrpc.ToStatus(verr)
 				if err != nil {
 					return err
 				}
@@ -488,16 +569,8 @@ func (g *grpcServer) processStream(stream grpc.ServerStream, service *service, m
 		var errStatus *status.Status
 		switch verr := appErr.(type) {
 		case *errors.Error:
-			perr := &pberr.Error{
-				Id:     verr.Id,
-				Code:   verr.Code,
-				Detail: verr.Detail,
-				Status: verr.Status,
-			}
 			// micro.Error now proto based and we can attach it to grpc status
-			statusCode = microError(verr)
-			statusDesc = verr.Error()
-			errStatus, err = status.New(statusCode, statusDesc).WithDetails(perr)
+			errStatus, err = mgrpc.ToStatus(verr)
 			if err != nil {
 				return err
 			}
@@ -548,6 +621,11 @@ func (g *grpcServer) Options() server.Options {
 
 func (g *grpcServer) Init(opts ...server.Option) error {
 	g.configure(opts...)
+
+	// grant the rules of the registered handlers should the auth have been set
+	if a := g.Options().Auth; a != nil {
+		return g.guard.Grant(a)
+	}
 	return nil
 }
 
@@ -560,6 +638,11 @@ func (g *grpcServer) Handle(h server.Handler) error {
 		return err
 	}
 
+	// grant the rules for the scopes declared by the endpoints
+	if err := g.guard.Handle(g.opts.Auth, g.opts.Name, h); err != nil {
+		return err
+	}
+
 	g.handlers[h.Name()] = h
 	return nil
 }
@@ -592,6 +675,25 @@ func (g *grpcServer) Subscribe(sb server.Subscriber) error {
 	return nil
 }
 
+// register registers the server, recording the outcome in the heartbeat. The
+// registration is rebuilt when the health advertised for the node changed.
+func (g *grpcServer) register() error {
+	err := g.Register()
+	if !g.heartbeat.Done(g.Options(), err) {
+		return err
+	}
+
+	g.Lock()
+	g.rsvc = nil
+	g.Unlock()
+
+	// advertise the new health right away if the registry is reachable
+	if err == nil {
+		err = g.Register()
+	}
+	return err
+}
+
 func (g *grpcServer) Register() error {
 	g.RLock()
 	rsvc := g.rsvc
@@ -677,6 +779,8 @@ func (g *grpcServer) Register() error {
 		Id:       config.Name + "-" + config.Id,
 		Address:  mnet.HostPort(addr, port),
 		Metadata: md,
+		Health:   g.heartbeat.Health(config),
+		Weight:   config.Weight,
 	}
 
 	node.Metadata["broker"] = config.Broker.String()
@@ -921,7 +1025,7 @@ func (g *grpcServer) Start() error {
 	}
 
 	// announce self to the world
-	if err := g.Register(); err != nil {
+	if err := g.register(); err != nil {
 		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 			logger.Errorf("Server register error: %v", err)
 		}
@@ -937,12 +1041,14 @@ func (g *grpcServer) Start() error {
 	}()
 
 	go func() {
-		t := new(time.Ticker)
+		var t *time.Timer
+		var tc <-chan time.Time
 
 		// only process if it exists
-		if g.opts.RegisterInterval > time.Duration(0) {
-			// new ticker
-			t = time.NewTicker(g.opts.RegisterInterval)
+		if d := g.heartbeat.Next(g.Options()); d > time.Duration(0) {
+			// new timer, reset after every registration
+			t = time.NewTimer(d)
+			tc = t.C
 		}
 
 		// return error chan
@@ -952,14 +1058,19 @@ func (g *grpcServer) Start() error {
 		for {
 			select {
 			// register self on interval
-			case <-t.C:
-				if err := g.Register(); err != nil {
+			case <-tc:
+				if err := g.register(); err != nil {
 					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 						logger.Error("Server register error: ", err)
 					}
 				}
+				// the next registration is sooner while the registry is failing
+				t.Reset(g.heartbeat.Next(g.Options()))
 			// wait for exit
 			case ch = <-g.exit:
+				if t != nil {
+					t.Stop()
+				}
 				break Loop
 			}
 		}
@@ -18,6 +18,8 @@ import (
 	gsrv "github.com/micro/go-micro/v3/server/grpc"
 	pb "github.com/micro/go-micro/v3/server/grpc/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
 )
 
@@ -301,3 +303,65 @@ func TestGRPCServerWithPanicHandler(t *testing.T) {
 		t.Fatal("this must return error, as handler should be panic")
 	}
 }
+
+func TestGRPCServerNative(t *testing.T) {
+	var unary, stream []string
+
+	s := gsrv.NewServer(
+		server.Name("foo"),
+		server.Registry(rmemory.NewRegistry()),
+		server.Broker(bmemory.NewBroker()),
+		gsrv.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			unary = append(unary, info.FullMethod)
+			return handler(ctx, req)
+		}),
+		gsrv.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			stream = append(stream, "first")
+			return handler(srv, ss)
+		}, func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			stream = append(stream, "second")
+			return handler(srv, ss)
+		}),
+		gsrv.RegisterServer(func(srv *grpc.Server) {
+			healthpb.RegisterHealthServer(srv, health.NewServer())
+		}),
+	)
+
+	pb.RegisterTestHandler(s, &testServer{})
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer s.Stop()
+
+	if gsrv.NativeServer(s) == nil {
+		t.Fatal("Expected the native server")
+	}
+
+	cc, err := grpc.Dial(s.Options().Address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+	defer cc.Close()
+
+	// the native service is served alongside the micro handlers
+	rsp, err := healthpb.NewHealthClient(cc).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("error calling the health service: %v", err)
+	}
+	if rsp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Expected the service to be serving got %v", rsp.Status)
+	}
+	if len(unary) != 1 || unary[0] != "/grpc.health.v1.Health/Check" {
+		t.Fatalf("Expected the unary interceptor to be called got %v", unary)
+	}
+
+	// micro handlers are served as streams
+	var trsp pb.Response
+	if err := cc.Invoke(context.Background(), "/test.Test/Call", &pb.Request{Name: "John"}, &trsp); err != nil {
+		t.Fatalf("error calling server: %v", err)
+	}
+	if len(stream) != 2 || stream[0] != "first" || stream[1] != "second" {
+		t.Fatalf("Expected the stream interceptors to be called in order got %v", stream)
+	}
+}
@@ -19,6 +19,9 @@ type netListener struct{}
 type maxMsgSizeKey struct{}
 type maxConnKey struct{}
 type tlsAuth struct{}
+type unaryInterceptorsKey struct{}
+type streamInterceptorsKey struct{}
+type registerServerKey struct{}
 
 // gRPC Codec to be used to encode/decode requests for a given content type
 func Codec(contentType string, c encoding.Codec) server.Option {
@@ -55,10 +58,49 @@ func Options(opts ...grpc.ServerOption) server.Option {
 	return setServerOption(grpcOptions{}, opts)
 }
 
-//
+// UnaryInterceptor adds interceptors to the unary methods of the gRPC services registered
+// on the native server. Micro handlers are served as streams, wrap them with a
+// HandlerWrapper or a StreamInterceptor instead.
+func UnaryInterceptor(i ...grpc.UnaryServerInterceptor) server.Option {
+	return func(o *server.Options) {
+		var chain []grpc.UnaryServerInterceptor
+		if o.Context != nil {
+			chain, _ = o.Context.Value(unaryInterceptorsKey{}).([]grpc.UnaryServerInterceptor)
+		}
+		chain = append(append([]grpc.UnaryServerInterceptor{}, chain...), i...)
+		setServerOption(unaryInterceptorsKey{}, chain)(o)
+	}
+}
+
+// StreamInterceptor adds interceptors to the streams of the server, these include
+// the requests to micro handlers which are served as streams
+func StreamInterceptor(i ...grpc.StreamServerInterceptor) server.Option {
+	return func(o *server.Options) {
+		var chain []grpc.StreamServerInterceptor
+		if o.Context != nil {
+			chain, _ = o.Context.Value(streamInterceptorsKey{}).([]grpc.StreamServerInterceptor)
+		}
+		chain = append(append([]grpc.StreamServerInterceptor{}, chain...), i...)
+		setServerOption(streamInterceptorsKey{}, chain)(o)
+	}
+}
+
+// RegisterServer registers gRPC services which aren't micro handlers e.g health,
+// reflection or third party generated services on the native server. The function
+// is called again with the new server whenever the server is reconfigured.
+func RegisterServer(fn func(*grpc.Server)) server.Option {
+	return func(o *server.Options) {
+		var fns []func(*grpc.Server)
+		if o.Context != nil {
+			fns, _ = o.Context.Value(registerServerKey{}).([]func(*grpc.Server))
+		}
+		fns = append(append([]func(*grpc.Server){}, fns...), fn)
+		setServerOption(registerServerKey{}, fns)(o)
+	}
+}
+
 // MaxMsgSize set the maximum message in bytes the server can receive and
 // send.  Default maximum message size is 4 MB.
-//
 func MaxMsgSize(s int) server.Option {
 	return setServerOption(maxMsgSizeKey{}, s)
 }
@@ -75,6 +117,10 @@ func newOptions(opt ...server.Option) server.Options {
 		Version:          server.DefaultVersion,
 		RegisterInterval: server.DefaultRegisterInterval,
 		RegisterTTL:      server.DefaultRegisterTTL,
+		RegisterJitter:   server.DefaultRegisterJitter,
+		RegisterFailures: server.DefaultRegisterFailures,
+		HdlrWrappers:     []server.HandlerWrapper{server.MetricsHandlerWrapper, server.TraceHandlerWrapper},
+		SubWrappers:      []server.SubscriberWrapper{server.MetricsSubscriberWrapper, server.TraceSubscriberWrapper},
 	}
 
 	for _, o := range opt {
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/micro/go-micro/v3/broker"
+	"github.com/micro/go-micro/v3/debug/report"
 	"github.com/micro/go-micro/v3/errors"
 	"github.com/micro/go-micro/v3/logger"
 	"github.com/micro/go-micro/v3/metadata"
@@ -175,6 +176,7 @@ func (g *grpcServer) createSubHandler(sb *subscriber, opts server.Options) broke
 					logger.Error("panic recovered: ", r)
 					logger.Error(string(debug.Stack()))
 				}
+				report.Panic(metadata.NewIncomingContext(context.Background(), msg.Header), g.opts.Name, sb.topic, r)
 				err = errors.InternalServerError(g.opts.Name+".subscriber", "panic recovered: %v", r)
 			}
 		}()
@@ -199,7 +201,7 @@ func (g *grpcServer) createSubHandler(sb *subscriber, opts server.Options) broke
 			hdr[k] = v
 		}
 		delete(hdr, "Content-Type")
-		ctx := metadata.NewContext(context.Background(), hdr)
+		ctx := metadata.NewIncomingContext(context.Background(), hdr)
 
 		results := make(chan error, len(sb.handlers))
 
@@ -270,6 +272,7 @@ func (g *grpcServer) createSubHandler(sb *subscriber, opts server.Options) broke
 		}
 		if len(errors) > 0 {
 			err = fmt.Errorf("subscriber error: %s", strings.Join(errors, "\n"))
+			report.Error(ctx, g.opts.Name, sb.topic, err)
 		}
 
 		return err
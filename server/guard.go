@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/metadata"
+)
+
+const (
+	// ScopesKey is the key of the endpoint metadata listing the scopes required to call it
+	ScopesKey = "scopes"
+	// ScopesPriority of the rules granting the scopes of an endpoint, the rule denying
+	// everyone else access to the endpoint is one lower
+	ScopesPriority = 100
+)
+
+// EndpointScopes is a Handler option declaring the scopes an account needs to call the endpoint,
+// any of them grants access. The generated handler registration sets them from the proto options.
+func EndpointScopes(name string, scopes ...string) HandlerOption {
+	return func(o *HandlerOptions) {
+		md := make(map[string]string)
+		for k, v := range o.Metadata[name] {
+			md[k] = v
+		}
+		md[ScopesKey] = strings.Join(scopes, ",")
+		o.Metadata[name] = md
+	}
+}
+
+// ScopeRules returns the auth rules enforcing the scopes declared by the endpoints of the handler
+func ScopeRules(service string, h Handler) []*auth.Rule {
+	var rules []*auth.Rule
+
+	for _, e := range h.Endpoints() {
+		var scopes []string
+		for _, s := range strings.Split(e.Metadata[ScopesKey], ",") {
+			if s = strings.TrimSpace(s); len(s) > 0 {
+				scopes = append(scopes, s)
+			}
+		}
+		if len(scopes) == 0 {
+			continue
+		}
+
+		res := &auth.Resource{Type: "service", Name: service, Endpoint: e.Name}
+
+		for _, s := range scopes {
+			rules = append(rules, &auth.Rule{
+				ID:       fmt.Sprintf("%s:%s:%s", service, e.Name, s),
+				Scope:    s,
+				Resource: res,
+				Access:   auth.AccessGranted,
+				Priority: ScopesPriority,
+			})
+		}
+
+		rules = append(rules, &auth.Rule{
+			ID:       fmt.Sprintf("%s:%s", service, e.Name),
+			Scope:    auth.ScopePublic,
+			Resource: res,
+			Access:   auth.AccessDenied,
+			Priority: ScopesPriority - 1,
+		})
+	}
+
+	return rules
+}
+
+// Guard enforces the scopes declared by the endpoints of the handlers of a server. The rules
+// are granted when the handlers are registered, keeping the policy next to the API definition,
+// and again when the auth of the server is set. The zero value is ready to use.
+type Guard struct {
+	sync.RWMutex
+	// endpoints declaring scopes
+	endpoints map[string]bool
+	// rules of the registered handlers by id
+	rules map[string]*auth.Rule
+}
+
+// Handle grants the rules for the scopes declared by the endpoints of the handler
+func (g *Guard) Handle(a auth.Auth, service string, h Handler) error {
+	rules := ScopeRules(service, h)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	g.Lock()
+	if g.endpoints == nil {
+		g.endpoints = make(map[string]bool)
+		g.rules = make(map[string]*auth.Rule)
+	}
+	for _, r := range rules {
+		g.endpoints[r.Resource.Endpoint] = true
+		g.rules[r.ID] = r
+	}
+	g.Unlock()
+
+	if a == nil {
+		return nil
+	}
+	return grant(a, rules)
+}
+
+// Grant the rules of the registered handlers, it's called when the auth of the server is set
+func (g *Guard) Grant(a auth.Auth) error {
+	g.RLock()
+	rules := make([]*auth.Rule, 0, len(g.rules))
+	for _, r := range g.rules {
+		rules = append(rules, r)
+	}
+	g.RUnlock()
+
+	if len(rules) == 0 {
+		return nil
+	}
+	return grant(a, rules)
+}
+
+// grant the rules which aren't granted yet, rules are never revoked so the endpoints
+// aren't left without their deny rule while another instance registers them
+func grant(a auth.Auth, rules []*auth.Rule) error {
+	existing, err := a.Rules()
+	if err != nil {
+		return err
+	}
+
+	granted := make(map[string]bool, len(existing))
+	for _, r := range existing {
+		granted[r.ID] = true
+	}
+
+	for _, r := range rules {
+		if granted[r.ID] {
+			continue
+		}
+		if err := a.Grant(r); err != nil {
+			return err
+		}
+		granted[r.ID] = true
+	}
+
+	return nil
+}
+
+// Wrapper returns the handler wrapper verifying the account making a request has a
+// scope required by the endpoint. The account is read from the context or inspected
+// from the bearer token in the metadata. The endpoints declaring scopes are refused
+// when the server has no auth to verify them.
+func (g *Guard) Wrapper(fn func() auth.Auth) HandlerWrapper {
+	return func(h HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req Request, rsp interface{}) error {
+			g.RLock()
+			scoped := g.endpoints[req.Endpoint()]
+			g.RUnlock()
+
+			if !scoped {
+				return h(ctx, req, rsp)
+			}
+
+			a := fn()
+			if a == nil {
+				return errors.Forbidden(req.Service(), "Forbidden call made to %s, no auth to verify its scopes", req.Endpoint())
+			}
+
+			acc, ok := auth.AccountFromContext(ctx)
+			if !ok {
+				if header, ok := metadata.Get(ctx, "Authorization"); ok && strings.HasPrefix(header, auth.BearerScheme) {
+					if acc, _ = a.Inspect(strings.TrimPrefix(header, auth.BearerScheme)); acc != nil {
+						ctx = auth.ContextWithAccount(ctx, acc)
+					}
+				}
+			}
+
+			res := &auth.Resource{Type: "service", Name: req.Service(), Endpoint: req.Endpoint()}
+			if err := a.Verify(acc, res); err != nil {
+				if acc == nil {
+					return errors.Unauthorized(req.Service(), "Unauthorized call made to %s", req.Endpoint())
+				}
+				return errors.Forbidden(req.Service(), "Forbidden call made to %s by %s", req.Endpoint(), acc.ID)
+			}
+
+			return h(ctx, req, rsp)
+		}
+	}
+}
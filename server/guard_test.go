@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/registry"
+)
+
+type testAuth struct {
+	auth.Auth
+	rules    []*auth.Rule
+	accounts map[string]*auth.Account
+}
+
+func (a *testAuth) Grant(r *auth.Rule) error {
+	a.rules = append(a.rules, r)
+	return nil
+}
+
+func (a *testAuth) Revoke(r *auth.Rule) error {
+	var rules []*auth.Rule
+	for _, rule := range a.rules {
+		if rule.ID != r.ID {
+			rules = append(rules, rule)
+		}
+	}
+	a.rules = rules
+	return nil
+}
+
+func (a *testAuth) Rules(opts ...auth.RulesOption) ([]*auth.Rule, error) {
+	return a.rules, nil
+}
+
+func (a *testAuth) Verify(acc *auth.Account, res *auth.Resource, opts ...auth.VerifyOption) error {
+	return auth.VerifyAccess(a.rules, acc, res)
+}
+
+func (a *testAuth) Inspect(token string) (*auth.Account, error) {
+	if acc, ok := a.accounts[token]; ok {
+		return acc, nil
+	}
+	return nil, auth.ErrInvalidToken
+}
+
+type testHandler struct {
+	opts HandlerOptions
+}
+
+func (h *testHandler) Name() string {
+	return "Test"
+}
+
+func (h *testHandler) Handler() interface{} {
+	return h
+}
+
+func (h *testHandler) Options() HandlerOptions {
+	return h.opts
+}
+
+func (h *testHandler) Endpoints() []*registry.Endpoint {
+	var eps []*registry.Endpoint
+	for _, name := range []string{"Test.Read", "Test.Write"} {
+		eps = append(eps, &registry.Endpoint{Name: name, Metadata: h.opts.Metadata[name]})
+	}
+	return eps
+}
+
+type testRequest struct {
+	Request
+	endpoint string
+}
+
+func (r *testRequest) Service() string {
+	return "test"
+}
+
+func (r *testRequest) Endpoint() string {
+	return r.endpoint
+}
+
+func TestGuard(t *testing.T) {
+	a := &testAuth{
+		accounts: map[string]*auth.Account{
+			"admin": {ID: "admin", Scopes: []string{"admin"}},
+			"user":  {ID: "user", Scopes: []string{"user"}},
+		},
+	}
+	// everyone can call the endpoints without scopes
+	a.Grant(&auth.Rule{ID: "public", Scope: auth.ScopePublic, Resource: &auth.Resource{Type: "*", Name: "*", Endpoint: "*"}})
+
+	opts := HandlerOptions{Metadata: make(map[string]map[string]string)}
+	EndpointMetadata("Test.Write", map[string]string{"method": "POST"})(&opts)
+	EndpointScopes("Test.Write", "admin", "ops")(&opts)
+
+	if md := opts.Metadata["Test.Write"]; md["method"] != "POST" || md[ScopesKey] != "admin,ops" {
+		t.Fatalf("Expected the scopes to be merged into the metadata, got %v", md)
+	}
+
+	var g Guard
+	h := &testHandler{opts: opts}
+
+	// registering twice doesn't duplicate the rules
+	for i := 0; i < 2; i++ {
+		if err := g.Handle(a, "test", h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(a.rules) != 4 {
+		t.Fatalf("Expected 4 rules, got %d", len(a.rules))
+	}
+
+	fn := g.Wrapper(func() auth.Auth { return a })(func(ctx context.Context, req Request, rsp interface{}) error {
+		return nil
+	})
+
+	call := func(endpoint, token string) error {
+		ctx := context.Background()
+		if len(token) > 0 {
+			ctx = metadata.Set(ctx, "Authorization", auth.BearerScheme+token)
+		}
+		return fn(ctx, &testRequest{endpoint: endpoint}, nil)
+	}
+
+	if err := call("Test.Read", ""); err != nil {
+		t.Fatalf("Expected an endpoint without scopes to be public, got %v", err)
+	}
+	if err := call("Test.Write", "admin"); err != nil {
+		t.Fatalf("Expected the admin to call the endpoint, got %v", err)
+	}
+	if err := call("Test.Write", "user"); errors.FromError(err).Code != 403 {
+		t.Fatalf("Expected the user to be forbidden, got %v", err)
+	}
+	if err := call("Test.Write", ""); errors.FromError(err).Code != 401 {
+		t.Fatalf("Expected an anonymous call to be unauthorized, got %v", err)
+	}
+
+	// the scoped endpoints are refused without auth
+	noAuth := g.Wrapper(func() auth.Auth { return nil })(func(ctx context.Context, req Request, rsp interface{}) error {
+		return nil
+	})
+	if err := noAuth(context.Background(), &testRequest{endpoint: "Test.Write"}, nil); errors.FromError(err).Code != 403 {
+		t.Fatalf("Expected the scoped endpoint to be forbidden without auth, got %v", err)
+	}
+	if err := noAuth(context.Background(), &testRequest{endpoint: "Test.Read"}, nil); err != nil {
+		t.Fatalf("Expected an endpoint without scopes to be public without auth, got %v", err)
+	}
+
+	// the rules are granted to the auth set after the handlers were registered
+	b := &testAuth{}
+	if err := g.Grant(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Grant(b); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.rules) != 3 {
+		t.Fatalf("Expected 3 rules, got %d", len(b.rules))
+	}
+}
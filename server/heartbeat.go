@@ -0,0 +1,135 @@
+package server
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/util/backoff"
+)
+
+// Heartbeat tracks the re-registration of a server. Registrations happen on a jittered
+// interval and failing ones are retried with exponential backoff. A server failing to
+// register RegisterFailures times in a row is demoted and advertised as down until it
+// registers that many times in a row again, so a flapping registry doesn't make the node
+// come and go. The zero value is ready to use.
+type Heartbeat struct {
+	sync.Mutex
+	// consecutive failed registrations
+	failures int
+	// consecutive successful registrations while demoted
+	successes int
+	// advertised as down
+	demoted bool
+	// registration expired in the registry
+	lost bool
+	// time of the last successful registration
+	last time.Time
+}
+
+// Next returns the delay until the next registration
+func (h *Heartbeat) Next(opts Options) time.Duration {
+	h.Lock()
+	failures := h.failures
+	h.Unlock()
+
+	d := opts.RegisterInterval
+	if d <= 0 {
+		return d
+	}
+
+	// retry sooner than the interval while the registry is failing
+	if failures > 0 {
+		if b := backoff.Do(failures); b < d {
+			d = b
+		}
+	}
+
+	if opts.RegisterJitter > 0 {
+		j := time.Duration(float64(d) * opts.RegisterJitter)
+		if j > 0 {
+			d = d - j + time.Duration(rand.Int63n(int64(2*j)))
+		}
+	}
+
+	return d
+}
+
+// Health returns the health to advertise in the registry, down while demoted
+func (h *Heartbeat) Health(opts Options) string {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.demoted {
+		return registry.HealthDown
+	}
+	return opts.Health
+}
+
+// Done records the outcome of a registration. It returns true when the advertised
+// health changed, in which case the server has to rebuild its registration.
+func (h *Heartbeat) Done(opts Options, err error) bool {
+	h.Lock()
+
+	var changed, lost, recovered bool
+
+	threshold := opts.RegisterFailures
+	if threshold <= 0 {
+		threshold = DefaultRegisterFailures
+	}
+
+	if err != nil {
+		h.failures++
+		h.successes = 0
+
+		if !h.demoted && h.failures >= threshold {
+			h.demoted = true
+			changed = true
+		}
+
+		// the registration expires in the registry once the ttl passed
+		if !h.lost && !h.last.IsZero() && opts.RegisterTTL > 0 && time.Since(h.last) > opts.RegisterTTL {
+			h.lost = true
+			lost = true
+		}
+	} else {
+		h.failures = 0
+		h.last = time.Now()
+
+		if h.demoted {
+			h.successes++
+			if h.successes >= threshold {
+				h.demoted = false
+				h.successes = 0
+				changed = true
+			}
+		}
+
+		if h.lost {
+			h.lost = false
+			recovered = true
+		}
+	}
+
+	demoted := h.demoted
+	h.Unlock()
+
+	if changed && logger.V(logger.WarnLevel, logger.DefaultLogger) {
+		if demoted {
+			logger.Warnf("Server %s-%s failed to register %d times, advertising it as down", opts.Name, opts.Id, threshold)
+		} else {
+			logger.Warnf("Server %s-%s registered %d times, advertising it as up", opts.Name, opts.Id, threshold)
+		}
+	}
+
+	if lost && opts.RegisterLost != nil {
+		opts.RegisterLost(err)
+	}
+	if recovered && opts.RegisterRecovered != nil {
+		opts.RegisterRecovered()
+	}
+
+	return changed
+}
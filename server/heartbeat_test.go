@@ -0,0 +1,90 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/registry"
+)
+
+func TestHeartbeatDemote(t *testing.T) {
+	var lost, recovered int
+
+	opts := Options{
+		Health:            registry.HealthUp,
+		RegisterInterval:  time.Second,
+		RegisterTTL:       time.Millisecond,
+		RegisterFailures:  2,
+		RegisterLost:      func(error) { lost++ },
+		RegisterRecovered: func() { recovered++ },
+	}
+
+	var h Heartbeat
+	regErr := errors.New("registry unavailable")
+
+	if h.Done(opts, nil) {
+		t.Fatal("Expected the health to be unchanged on success")
+	}
+	if d := h.Next(opts); d != time.Second {
+		t.Fatalf("Expected the interval, got %v", d)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+
+	if h.Done(opts, regErr) {
+		t.Fatal("Expected the node not to be demoted after one failure")
+	}
+	if lost != 1 {
+		t.Fatalf("Expected the registration to be lost once, got %d", lost)
+	}
+	if d := h.Next(opts); d >= time.Second {
+		t.Fatalf("Expected a backoff shorter than the interval, got %v", d)
+	}
+
+	if !h.Done(opts, regErr) {
+		t.Fatal("Expected the node to be demoted")
+	}
+	if health := h.Health(opts); health != registry.HealthDown {
+		t.Fatalf("Expected health %s, got %s", registry.HealthDown, health)
+	}
+	if lost != 1 {
+		t.Fatalf("Expected the loss to be reported once, got %d", lost)
+	}
+
+	// a flapping registry keeps the node down
+	h.Done(opts, nil)
+	h.Done(opts, regErr)
+	if h.Done(opts, nil) {
+		t.Fatal("Expected the node to stay down while the registry flaps")
+	}
+	if health := h.Health(opts); health != registry.HealthDown {
+		t.Fatalf("Expected health %s, got %s", registry.HealthDown, health)
+	}
+
+	if !h.Done(opts, nil) {
+		t.Fatal("Expected the node to be promoted")
+	}
+	if health := h.Health(opts); health != registry.HealthUp {
+		t.Fatalf("Expected health %s, got %s", registry.HealthUp, health)
+	}
+	if recovered != 1 {
+		t.Fatalf("Expected the registration to be recovered once, got %d", recovered)
+	}
+}
+
+func TestHeartbeatJitter(t *testing.T) {
+	opts := Options{
+		RegisterInterval: time.Second,
+		RegisterJitter:   0.1,
+	}
+
+	var h Heartbeat
+
+	for i := 0; i < 100; i++ {
+		d := h.Next(opts)
+		if d < 900*time.Millisecond || d > 1100*time.Millisecond {
+			t.Fatalf("Expected the interval within 10%%, got %v", d)
+		}
+	}
+}
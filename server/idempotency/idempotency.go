@@ -0,0 +1,227 @@
+// Package idempotency replays the responses of idempotent handlers to requests retried with the
+// same idempotency key, similar to the idempotency keys of the Stripe API. Clients set the key in
+// the metadata of the request and the response or client error of the first request is returned
+// for the duplicates within the ttl.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/codec"
+	jsonCodec "github.com/micro/go-micro/v3/codec/json"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/server"
+	"github.com/micro/go-micro/v3/store"
+)
+
+var (
+	// Header of the metadata the idempotency key is read from
+	Header = "Idempotency-Key"
+	// DefaultTTL the responses are replayed for
+	DefaultTTL = time.Hour * 24
+	// DefaultTimeout after which a request still in progress can be retried
+	DefaultTimeout = time.Minute
+)
+
+// record of a request, the response is set once it completes
+type record struct {
+	// Token of the request which wrote the record
+	Token string `json:"token"`
+	// Hash of the request body to reject a key reused for another request
+	Hash     string          `json:"hash"`
+	Done     bool            `json:"done"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+type wrapper struct {
+	opts      Options
+	endpoints map[string]bool
+	marshaler codec.Marshaler
+}
+
+// WithKey sets the idempotency key of the requests made with the context
+func WithKey(ctx context.Context, key string) context.Context {
+	return metadata.Set(ctx, Header, key)
+}
+
+// NewHandlerWrapper returns a handler wrapper which replays the responses of the idempotent
+// endpoints, pass it to the server with server.WrapHandler
+func NewHandlerWrapper(opts ...Option) server.HandlerWrapper {
+	options := Options{
+		TTL:     DefaultTTL,
+		Timeout: DefaultTimeout,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	w := &wrapper{
+		opts:      options,
+		endpoints: make(map[string]bool),
+		marshaler: jsonCodec.Marshaler{},
+	}
+	for _, e := range options.Endpoints {
+		w.endpoints[e] = true
+	}
+
+	return w.wrap
+}
+
+func (w *wrapper) store() store.Store {
+	if w.opts.Store != nil {
+		return w.opts.Store
+	}
+	return store.DefaultStore
+}
+
+// key of the record, the keys are scoped to the account making the request
+func (w *wrapper) key(ctx context.Context, req server.Request, key string) string {
+	account := "public"
+	if acc, ok := auth.AccountFromContext(ctx); ok {
+		account = acc.ID
+	}
+	return strings.Join([]string{"idempotency", req.Service(), req.Endpoint(), account, key}, "/")
+}
+
+func (w *wrapper) hash(req server.Request) string {
+	b, err := w.marshaler.Marshal(req.Body())
+	if err != nil {
+		return ""
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func (w *wrapper) wrap(fn server.HandlerFunc) server.HandlerFunc {
+	return func(ctx context.Context, req server.Request, rsp interface{}) error {
+		if req.Stream() || (len(w.endpoints) > 0 && !w.endpoints[req.Endpoint()]) {
+			return fn(ctx, req, rsp)
+		}
+		key, ok := metadata.Get(ctx, Header)
+		if !ok || len(key) == 0 {
+			return fn(ctx, req, rsp)
+		}
+
+		rec := &record{
+			Token: uuid.New().String(),
+			Hash:  w.hash(req),
+		}
+		k := w.key(ctx, req, key)
+
+		// the record is written before the request is processed so concurrent duplicates are rejected
+		b, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		err = w.store().Write(&store.Record{Key: k, Value: b, Expiry: w.opts.Timeout}, store.WriteIfNotExists())
+		if err == store.ErrConflict {
+			return w.replay(req, k, rec, rsp)
+		} else if err != nil {
+			return err
+		}
+
+		err = fn(ctx, req, rsp)
+
+		// client errors are replayed, any other error can be retried
+		if verr, ok := err.(*errors.Error); err != nil && (!ok || verr.Code < 400 || verr.Code >= 500) {
+			w.release(k, rec.Token)
+			return err
+		}
+
+		rec.Done = true
+		if err != nil {
+			rec.Error = err.Error()
+		} else if rec.Response, err = w.marshaler.Marshal(rsp); err != nil {
+			w.release(k, rec.Token)
+			return err
+		}
+
+		if serr := w.complete(k, rec); serr != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error storing the response of idempotency key %s: %v", key, serr)
+			}
+		}
+
+		if len(rec.Error) > 0 {
+			return errors.Parse(rec.Error)
+		}
+		return nil
+	}
+}
+
+// replay the response of the request which wrote the record
+func (w *wrapper) replay(req server.Request, key string, rec *record, rsp interface{}) error {
+	recs, err := w.store().Read(key)
+	if err == store.ErrNotFound {
+		return errors.Conflict(req.Service(), "request with the idempotency key was retried, try again")
+	} else if err != nil {
+		return err
+	}
+
+	var stored *record
+	if err := json.Unmarshal(recs[0].Value, &stored); err != nil {
+		return err
+	}
+
+	if stored.Hash != rec.Hash {
+		return errors.BadRequest(req.Service(), "idempotency key was used for a different request")
+	}
+	if !stored.Done {
+		return errors.Conflict(req.Service(), "request with the idempotency key is in progress")
+	}
+	if len(stored.Error) > 0 {
+		return errors.Parse(stored.Error)
+	}
+	return w.marshaler.Unmarshal(stored.Response, rsp)
+}
+
+// complete writes the response if the record is still the one written by the request
+func (w *wrapper) complete(key string, rec *record) error {
+	recs, err := w.store().Read(key)
+	if err != nil {
+		return err
+	}
+
+	var stored *record
+	if err := json.Unmarshal(recs[0].Value, &stored); err != nil {
+		return err
+	}
+	if stored.Token != rec.Token {
+		return store.ErrConflict
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return w.store().Write(&store.Record{
+		Key:     key,
+		Value:   b,
+		Expiry:  w.opts.TTL,
+		Version: recs[0].Version,
+	}, store.WriteIfMatch())
+}
+
+// release deletes the record so the request can be retried
+func (w *wrapper) release(key, token string) {
+	recs, err := w.store().Read(key)
+	if err != nil {
+		return
+	}
+
+	var stored *record
+	if err := json.Unmarshal(recs[0].Value, &stored); err != nil || stored.Token != token {
+		return
+	}
+	w.store().Delete(key)
+}
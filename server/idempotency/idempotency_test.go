@@ -0,0 +1,95 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/v3/codec"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/server"
+	"github.com/micro/go-micro/v3/store/memory"
+)
+
+type testRequest struct {
+	endpoint string
+	body     map[string]string
+}
+
+func (r *testRequest) Service() string           { return "test" }
+func (r *testRequest) Method() string            { return r.endpoint }
+func (r *testRequest) Endpoint() string          { return r.endpoint }
+func (r *testRequest) ContentType() string       { return "application/json" }
+func (r *testRequest) Header() map[string]string { return nil }
+func (r *testRequest) Body() interface{}         { return r.body }
+func (r *testRequest) Read() ([]byte, error)     { return nil, nil }
+func (r *testRequest) Codec() codec.Reader       { return nil }
+func (r *testRequest) Stream() bool              { return false }
+
+type testResponse struct {
+	Calls int
+}
+
+func TestHandlerWrapper(t *testing.T) {
+	var calls int
+	h := NewHandlerWrapper(Store(memory.NewStore()), Endpoints("Foo.Create"))(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		calls++
+		if req.Body().(map[string]string)["fail"] == "client" {
+			return errors.BadRequest("test", "invalid")
+		} else if req.Body().(map[string]string)["fail"] == "server" {
+			return errors.InternalServerError("test", "failed")
+		}
+		rsp.(*testResponse).Calls = calls
+		return nil
+	})
+
+	ctx := WithKey(context.Background(), "foo")
+	req := &testRequest{endpoint: "Foo.Create", body: map[string]string{"name": "foo"}}
+
+	// the duplicate gets the response of the first request
+	for i := 0; i < 2; i++ {
+		rsp := new(testResponse)
+		if err := h(ctx, req, rsp); err != nil {
+			t.Fatal(err)
+		}
+		if rsp.Calls != 1 {
+			t.Fatalf("Expected the response of the first call, got %v", rsp)
+		}
+	}
+
+	// the key can't be reused for another request
+	other := &testRequest{endpoint: "Foo.Create", body: map[string]string{"name": "bar"}}
+	if err := h(ctx, other, new(testResponse)); err == nil || errors.FromError(err).Code != 400 {
+		t.Fatalf("Expected a bad request, got %v", err)
+	}
+
+	// client errors are replayed
+	failed := &testRequest{endpoint: "Foo.Create", body: map[string]string{"fail": "client"}}
+	for i := 0; i < 2; i++ {
+		if err := h(WithKey(context.Background(), "bar"), failed, new(testResponse)); errors.FromError(err).Code != 400 {
+			t.Fatalf("Expected a bad request, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("Expected 2 calls, got %d", calls)
+	}
+
+	// server errors can be retried
+	failed = &testRequest{endpoint: "Foo.Create", body: map[string]string{"fail": "server"}}
+	for i := 0; i < 2; i++ {
+		if err := h(WithKey(context.Background(), "baz"), failed, new(testResponse)); errors.FromError(err).Code != 500 {
+			t.Fatalf("Expected an internal server error, got %v", err)
+		}
+	}
+	if calls != 4 {
+		t.Fatalf("Expected 4 calls, got %d", calls)
+	}
+
+	// requests without a key and other endpoints aren't replayed
+	for i := 0; i < 2; i++ {
+		h(context.Background(), req, new(testResponse))
+		h(ctx, &testRequest{endpoint: "Foo.List"}, new(testResponse))
+	}
+	if calls != 8 {
+		t.Fatalf("Expected 8 calls, got %d", calls)
+	}
+}
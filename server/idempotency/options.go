@@ -0,0 +1,48 @@
+package idempotency
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+type Options struct {
+	// Store the responses are kept in, the default store if not set
+	Store store.Store
+	// TTL the responses are replayed for
+	TTL time.Duration
+	// Timeout after which a request still in progress e.g because the node crashed can be retried
+	Timeout time.Duration
+	// Endpoints which are idempotent, all the endpoints are if none are set
+	Endpoints []string
+}
+
+type Option func(o *Options)
+
+// Store the responses are kept in, it must support conditional writes and expiry
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// TTL the responses are replayed for
+func TTL(d time.Duration) Option {
+	return func(o *Options) {
+		o.TTL = d
+	}
+}
+
+// Timeout after which a request still in progress can be retried
+func Timeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
+
+// Endpoints marks the endpoints as idempotent e.g Payments.Create
+func Endpoints(e ...string) Option {
+	return func(o *Options) {
+		o.Endpoints = e
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/metrics"
+)
+
+var (
+	// RequestMetric is the timing metric of the requests served tagged with the service,
+	// endpoint and result. The count of the timing is the rate of requests and errors.
+	RequestMetric = "server.request"
+	// MessageMetric is the timing metric of the messages processed by subscribers
+	// tagged with the topic and result
+	MessageMetric = "server.message"
+)
+
+// MetricsHandlerWrapper reports the requests served to the default metrics reporter,
+// it's the first wrapper of the handlers of the servers
+func MetricsHandlerWrapper(fn HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, req Request, rsp interface{}) error {
+		started := time.Now()
+		err := fn(ctx, req, rsp)
+
+		metrics.DefaultReporter.Timing(RequestMetric, time.Since(started), metrics.Tags{
+			"service":  req.Service(),
+			"endpoint": req.Endpoint(),
+			"result":   metrics.Result(err),
+		})
+		return err
+	}
+}
+
+// MetricsSubscriberWrapper reports the messages processed to the default metrics reporter,
+// it's the first wrapper of the subscribers of the servers
+func MetricsSubscriberWrapper(fn SubscriberFunc) SubscriberFunc {
+	return func(ctx context.Context, msg Message) error {
+		started := time.Now()
+		err := fn(ctx, msg)
+
+		metrics.DefaultReporter.Timing(MessageMetric, time.Since(started), metrics.Tags{
+			"topic":  msg.Topic(),
+			"result": metrics.Result(err),
+		})
+		return err
+	}
+}
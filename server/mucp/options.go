@@ -14,6 +14,10 @@ func newOptions(opt ...server.Option) server.Options {
 		Metadata:         map[string]string{},
 		RegisterInterval: server.DefaultRegisterInterval,
 		RegisterTTL:      server.DefaultRegisterTTL,
+		RegisterJitter:   server.DefaultRegisterJitter,
+		RegisterFailures: server.DefaultRegisterFailures,
+		HdlrWrappers:     []server.HandlerWrapper{server.MetricsHandlerWrapper, server.TraceHandlerWrapper},
+		SubWrappers:      []server.SubscriberWrapper{server.MetricsSubscriberWrapper, server.TraceSubscriberWrapper},
 	}
 
 	for _, o := range opt {
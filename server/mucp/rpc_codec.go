@@ -11,7 +11,9 @@ import (
 	"github.com/micro/go-micro/v3/codec/jsonrpc"
 	"github.com/micro/go-micro/v3/codec/proto"
 	"github.com/micro/go-micro/v3/codec/protorpc"
+	merrors "github.com/micro/go-micro/v3/errors"
 	"github.com/micro/go-micro/v3/network/transport"
+	"github.com/micro/go-micro/v3/server"
 	"github.com/oxtoacart/bpool"
 	"github.com/pkg/errors"
 )
@@ -27,6 +29,13 @@ type rpcCodec struct {
 	// check if we're the first
 	sync.RWMutex
 	first chan bool
+
+	// error decompressing the pre-loaded message
+	err error
+
+	// max size of the request and response bodies
+	maxSend int
+	maxRecv int
 }
 
 type readWriteCloser struct {
@@ -164,7 +173,7 @@ func setupProtocol(msg *transport.Message) codec.NewCodec {
 	return nil
 }
 
-func newRpcCodec(req *transport.Message, socket transport.Socket, c codec.NewCodec) codec.Codec {
+func newRpcCodec(req *transport.Message, socket transport.Socket, c codec.NewCodec, opts server.Options) codec.Codec {
 	rwc := &readWriteCloser{
 		rbuf: bufferPool.Get(),
 		wbuf: bufferPool.Get(),
@@ -177,13 +186,18 @@ func newRpcCodec(req *transport.Message, socket transport.Socket, c codec.NewCod
 		socket:   socket,
 		protocol: "mucp",
 		first:    make(chan bool),
+		maxSend:  opts.MaxSendSize,
+		maxRecv:  opts.MaxRecvSize,
 	}
 
 	// if grpc pre-load the buffer
 	// TODO: remove this terrible hack
 	switch r.codec.String() {
 	case "grpc":
-		// write the body
+		// decompress and write the body
+		if r.err = r.checkSize(req); r.err == nil {
+			r.err = r.decompress(req)
+		}
 		rwc.rbuf.Write(req.Body)
 		// set the protocol
 		r.protocol = "grpc"
@@ -195,6 +209,38 @@ func newRpcCodec(req *transport.Message, socket transport.Socket, c codec.NewCod
 	return r
 }
 
+// checkSize returns an error if the request exceeds the max receive size
+func (c *rpcCodec) checkSize(msg *transport.Message) error {
+	if c.maxRecv > 0 && len(msg.Body) > c.maxRecv {
+		return merrors.PayloadTooLarge("go.micro.server", "request of %d bytes exceeds the max receive size of %d bytes", len(msg.Body), c.maxRecv)
+	}
+	return nil
+}
+
+// decompress the body of a message compressed by the client, the max receive
+// size applies to the decompressed body
+func (c *rpcCodec) decompress(msg *transport.Message) error {
+	enc, ok := msg.Header[codec.ContentEncoding]
+	if !ok {
+		return nil
+	}
+
+	limit := codec.MaxDecompressedSize
+	if c.maxRecv > 0 && int64(c.maxRecv) < limit {
+		limit = int64(c.maxRecv)
+	}
+
+	b, err := codec.DecompressLimit(enc, msg.Body, limit)
+	if err == codec.ErrDecompressedSize {
+		return merrors.PayloadTooLarge("go.micro.server", "request exceeds the max receive size of %d bytes once decompressed", limit)
+	} else if err != nil {
+		return errors.Wrap(err, enc)
+	}
+	msg.Body = b
+	delete(msg.Header, codec.ContentEncoding)
+	return nil
+}
+
 func (c *rpcCodec) ReadHeader(r *codec.Message, t codec.MessageType) error {
 	// the initial message
 	m := codec.Message{
@@ -212,6 +258,13 @@ func (c *rpcCodec) ReadHeader(r *codec.Message, t codec.MessageType) error {
 		if err := c.socket.Recv(&tm); err != nil {
 			return err
 		}
+		if err := c.checkSize(&tm); err != nil {
+			return err
+		}
+		// decompress the body before decoding it
+		if err := c.decompress(&tm); err != nil {
+			return err
+		}
 		// reset the read buffer
 		c.buf.rbuf.Reset()
 
@@ -228,6 +281,9 @@ func (c *rpcCodec) ReadHeader(r *codec.Message, t codec.MessageType) error {
 		// set req
 		c.req = &tm
 	default:
+		if c.err != nil {
+			return c.err
+		}
 		// we need to lock here to prevent race conditions
 		// and we make use of a channel otherwise because
 		// this does not result in a context switch
@@ -321,8 +377,15 @@ func (c *rpcCodec) Write(r *codec.Message, b interface{}) error {
 			return err
 		}
 	} else {
-		// set the body
-		body = c.buf.wbuf.Bytes()
+		// set the body, copied as the buffer is reused by the next write
+		body = append([]byte(nil), c.buf.wbuf.Bytes()...)
+	}
+
+	// replace a response exceeding the max send size by the error
+	if c.maxSend > 0 && len(body) > c.maxSend {
+		m.Error = merrors.PayloadTooLarge("go.micro.server", "response of %d bytes exceeds the max send size of %d bytes", len(body), c.maxSend).Error()
+		m.Header["Micro-Error"] = m.Error
+		body = nil
 	}
 
 	// Set content type if theres content
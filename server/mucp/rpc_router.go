@@ -19,6 +19,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/micro/go-micro/v3/codec"
+	"github.com/micro/go-micro/v3/debug/report"
 	merrors "github.com/micro/go-micro/v3/errors"
 	"github.com/micro/go-micro/v3/server"
 )
@@ -384,6 +385,10 @@ func (router *router) readHeader(cc codec.Reader) (service *service, mtype *meth
 		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			return
 		}
+		// errors such as a request too large are returned as is
+		if _, ok := err.(*merrors.Error); ok {
+			return
+		}
 		err = errors.New("rpc: router cannot decode request: " + err.Error())
 		return
 	}
@@ -515,6 +520,7 @@ func (router *router) ProcessMessage(ctx context.Context, msg server.Message) (e
 		if r := recover(); r != nil {
 			log.Errorf("panic recovered: %v", r)
 			log.Error(string(debug.Stack()))
+			report.Panic(ctx, router.name, msg.Topic(), r)
 			err = merrors.InternalServerError("go.micro.server", "panic recovered: %v", r)
 		}
 	}()
@@ -611,6 +617,7 @@ func (router *router) ProcessMessage(ctx context.Context, msg server.Message) (e
 	// if no errors just return
 	if len(errResults) > 0 {
 		err = merrors.InternalServerError("go.micro.server", "subscriber error: %v", strings.Join(errResults, "\n"))
+		report.Error(ctx, router.name, msg.Topic(), err)
 	}
 
 	return err
@@ -12,9 +12,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/micro/go-micro/v3/auth"
 	"github.com/micro/go-micro/v3/broker"
 	"github.com/micro/go-micro/v3/codec"
 	raw "github.com/micro/go-micro/v3/codec/bytes"
+	"github.com/micro/go-micro/v3/debug/report"
 	"github.com/micro/go-micro/v3/logger"
 	"github.com/micro/go-micro/v3/metadata"
 	"github.com/micro/go-micro/v3/network/transport"
@@ -44,6 +46,10 @@ type rpcServer struct {
 	wg *sync.WaitGroup
 
 	rsvc *registry.Service
+	// tracks the re-registration
+	heartbeat server.Heartbeat
+	// enforces the scopes of the endpoints
+	guard server.Guard
 }
 
 var (
@@ -64,17 +70,25 @@ func wait(ctx context.Context) *sync.WaitGroup {
 func newServer(opts ...server.Option) server.Server {
 	options := newOptions(opts...)
 	router := newRpcRouter()
-	router.hdlrWrappers = options.HdlrWrappers
-	router.subWrappers = options.SubWrappers
+	router.name = options.Name
 
-	return &rpcServer{
-		opts:        options,
+	s := &rpcServer{
 		router:      router,
 		handlers:    make(map[string]server.Handler),
 		subscribers: make(map[server.Subscriber][]broker.Subscriber),
 		exit:        make(chan chan error),
 		wg:          wait(options.Context),
 	}
+
+	// enforce the scopes declared by the endpoints before any other wrapper
+	guard := s.guard.Wrapper(func() auth.Auth { return s.Options().Auth })
+	options.HdlrWrappers = append([]server.HandlerWrapper{guard}, options.HdlrWrappers...)
+
+	router.hdlrWrappers = options.HdlrWrappers
+	router.subWrappers = options.SubWrappers
+	s.opts = options
+
+	return s
 }
 
 // HandleEvent handles inbound messages to the service directly
@@ -107,7 +121,7 @@ func (s *rpcServer) HandleEvent(msg *broker.Message) error {
 	}
 
 	// create context
-	ctx := metadata.NewContext(context.Background(), hdr)
+	ctx := metadata.NewIncomingContext(context.Background(), hdr)
 
 	// TODO: inspect message header
 	// Micro-Service means a request
@@ -147,10 +161,11 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 	// streams are multiplexed on Micro-Stream or Micro-Id header
 	pool := socket.NewPool()
 
-	// get global waitgroup
-	s.Lock()
+	// get global waitgroup and the options of the connection
+	s.RLock()
 	gg := s.wg
-	s.Unlock()
+	opts := s.opts
+	s.RUnlock()
 
 	// waitgroup to wait for processing to finish
 	wg := &waitGroup{
@@ -173,6 +188,7 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 				log.Error("panic recovered: ", r)
 				log.Error(string(debug.Stack()))
 			}
+			report.Panic(context.Background(), opts.Name, "", r)
 		}
 	}()
 
@@ -278,7 +294,7 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		hdr["Remote"] = sock.Remote()
 
 		// create new context with the metadata
-		ctx := metadata.NewContext(context.Background(), hdr)
+		ctx := metadata.NewIncomingContext(context.Background(), hdr)
 
 		// set the timeout from the header if we have it
 		if len(to) > 0 {
@@ -319,7 +335,7 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		}
 
 		// create a new rpc codec based on the pseudo socket and codec
-		rcodec := newRpcCodec(&msg, psock, cf)
+		rcodec := newRpcCodec(&msg, psock, cf, opts)
 		// check the protocol as well
 		protocol := rcodec.String()
 
@@ -386,6 +402,7 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 						log.Error("panic recovered: ", r)
 						log.Error(string(debug.Stack()))
 					}
+					report.Panic(ctx, request.Service(), request.Endpoint(), r)
 				}
 			}()
 
@@ -415,6 +432,7 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 				if r := recover(); r != nil {
 					log.Error("panic recovered: ", r)
 					log.Error(string(debug.Stack()))
+					report.Panic(ctx, request.Service(), request.Endpoint(), r)
 				}
 			}()
 
@@ -467,6 +485,7 @@ func (s *rpcServer) Init(opts ...server.Option) error {
 	// update router if its the default
 	if s.opts.Router == nil {
 		r := newRpcRouter()
+		r.name = s.opts.Name
 		r.hdlrWrappers = s.opts.HdlrWrappers
 		r.serviceMap = s.router.serviceMap
 		r.subWrappers = s.opts.SubWrappers
@@ -475,6 +494,11 @@ func (s *rpcServer) Init(opts ...server.Option) error {
 
 	s.rsvc = nil
 
+	// grant the rules of the registered handlers should the auth have been set
+	if s.opts.Auth != nil {
+		return s.guard.Grant(s.opts.Auth)
+	}
+
 	return nil
 }
 
@@ -490,6 +514,11 @@ func (s *rpcServer) Handle(h server.Handler) error {
 		return err
 	}
 
+	// grant the rules for the scopes declared by the endpoints
+	if err := s.guard.Handle(s.opts.Auth, s.opts.Name, h); err != nil {
+		return err
+	}
+
 	s.handlers[h.Name()] = h
 
 	return nil
@@ -511,6 +540,25 @@ func (s *rpcServer) Subscribe(sb server.Subscriber) error {
 	return nil
 }
 
+// register registers the server, recording the outcome in the heartbeat. The
+// registration is rebuilt when the health advertised for the node changed.
+func (s *rpcServer) register() error {
+	err := s.Register()
+	if !s.heartbeat.Done(s.Options(), err) {
+		return err
+	}
+
+	s.Lock()
+	s.rsvc = nil
+	s.Unlock()
+
+	// advertise the new health right away if the registry is reachable
+	if err == nil {
+		err = s.Register()
+	}
+	return err
+}
+
 func (s *rpcServer) Register() error {
 	s.RLock()
 	rsvc := s.rsvc
@@ -601,6 +649,8 @@ func (s *rpcServer) Register() error {
 		Id:       config.Name + "-" + config.Id,
 		Address:  addr,
 		Metadata: md,
+		Health:   s.heartbeat.Health(config),
+		Weight:   config.Weight,
 	}
 
 	node.Metadata["transport"] = config.Transport.String()
@@ -855,7 +905,7 @@ func (s *rpcServer) Start() error {
 		}
 	} else {
 		// announce self to the world
-		if err = s.Register(); err != nil {
+		if err = s.register(); err != nil {
 			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 				log.Errorf("Server %s-%s register error: %s", config.Name, config.Id, err)
 			}
@@ -893,12 +943,14 @@ func (s *rpcServer) Start() error {
 	}()
 
 	go func() {
-		t := new(time.Ticker)
+		var t *time.Timer
+		var tc <-chan time.Time
 
 		// only process if it exists
-		if s.opts.RegisterInterval > time.Duration(0) {
-			// new ticker
-			t = time.NewTicker(s.opts.RegisterInterval)
+		if d := s.heartbeat.Next(s.Options()); d > time.Duration(0) {
+			// new timer, reset after every registration
+			t = time.NewTimer(d)
+			tc = t.C
 		}
 
 		// return error chan
@@ -908,7 +960,7 @@ func (s *rpcServer) Start() error {
 		for {
 			select {
 			// register self on interval
-			case <-t.C:
+			case <-tc:
 				s.RLock()
 				registered := s.registered
 				s.RUnlock()
@@ -927,16 +979,21 @@ func (s *rpcServer) Start() error {
 					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 						log.Errorf("Server %s-%s register check error: %s", config.Name, config.Id, rerr)
 					}
+					t.Reset(s.heartbeat.Next(s.Options()))
 					continue
 				}
-				if err := s.Register(); err != nil {
+				if err := s.register(); err != nil {
 					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 						log.Errorf("Server %s-%s register error: %s", config.Name, config.Id, err)
 					}
 				}
+				// the next registration is sooner while the registry is failing
+				t.Reset(s.heartbeat.Next(s.Options()))
 			// wait for exit
 			case ch = <-s.exit:
-				t.Stop()
+				if t != nil {
+					t.Stop()
+				}
 				close(exit)
 				break Loop
 			}
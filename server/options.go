@@ -40,6 +40,23 @@ type Options struct {
 	RegisterTTL time.Duration
 	// The interval on which to register
 	RegisterInterval time.Duration
+	// RegisterJitter randomises the interval by the fraction either way
+	RegisterJitter float64
+	// RegisterFailures is the number of consecutive failed registrations
+	// after which the node is advertised as down
+	RegisterFailures int
+	// RegisterLost is called when the registration expired in the registry
+	RegisterLost func(error)
+	// RegisterRecovered is called when a lost registration is recovered
+	RegisterRecovered func()
+	// Health of the node advertised in the registry e.g draining during a deploy
+	Health string
+	// Weight of the node advertised in the registry
+	Weight int
+	// MaxSendSize is the max size in bytes of the responses, unlimited if zero
+	MaxSendSize int
+	// MaxRecvSize is the max size in bytes of the requests, unlimited if zero
+	MaxRecvSize int
 
 	// The router for requests
 	Router Router
@@ -58,6 +75,10 @@ func newOptions(opt ...Option) Options {
 		Metadata:         map[string]string{},
 		RegisterInterval: DefaultRegisterInterval,
 		RegisterTTL:      DefaultRegisterTTL,
+		RegisterJitter:   DefaultRegisterJitter,
+		RegisterFailures: DefaultRegisterFailures,
+		HdlrWrappers:     []HandlerWrapper{MetricsHandlerWrapper, TraceHandlerWrapper},
+		SubWrappers:      []SubscriberWrapper{MetricsSubscriberWrapper, TraceSubscriberWrapper},
 	}
 
 	for _, o := range opt {
@@ -199,6 +220,37 @@ func Metadata(md map[string]string) Option {
 	}
 }
 
+// Health sets the health of the node advertised in the registry. Calling
+// Init with a new value updates the registration on the next interval.
+func Health(h string) Option {
+	return func(o *Options) {
+		o.Health = h
+	}
+}
+
+// Weight sets the weight of the node relative to others of the service
+func Weight(w int) Option {
+	return func(o *Options) {
+		o.Weight = w
+	}
+}
+
+// MaxSendSize limits the size in bytes of the responses, a larger response
+// is replaced by a PayloadTooLarge error
+func MaxSendSize(n int) Option {
+	return func(o *Options) {
+		o.MaxSendSize = n
+	}
+}
+
+// MaxRecvSize limits the size in bytes of the requests, a larger request
+// is rejected with a PayloadTooLarge error
+func MaxRecvSize(n int) Option {
+	return func(o *Options) {
+		o.MaxRecvSize = n
+	}
+}
+
 // RegisterCheck run func before registry service
 func RegisterCheck(fn func(context.Context) error) Option {
 	return func(o *Options) {
@@ -220,6 +272,37 @@ func RegisterInterval(t time.Duration) Option {
 	}
 }
 
+// RegisterJitter randomises the register interval by the fraction either way
+// so the nodes of a service don't all register at once
+func RegisterJitter(f float64) Option {
+	return func(o *Options) {
+		o.RegisterJitter = f
+	}
+}
+
+// RegisterFailures sets the number of consecutive failed registrations after which
+// the node is advertised as down, and successful ones after which it's up again
+func RegisterFailures(n int) Option {
+	return func(o *Options) {
+		o.RegisterFailures = n
+	}
+}
+
+// RegisterLost sets a func called when the registration expired in the registry
+// because the server failed to register for longer than the ttl
+func RegisterLost(fn func(error)) Option {
+	return func(o *Options) {
+		o.RegisterLost = fn
+	}
+}
+
+// RegisterRecovered sets a func called when a lost registration is recovered
+func RegisterRecovered(fn func()) Option {
+	return func(o *Options) {
+		o.RegisterRecovered = fn
+	}
+}
+
 // TLSConfig specifies a *tls.Config
 func TLSConfig(t *tls.Config) Option {
 	return func(o *Options) {
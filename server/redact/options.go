@@ -0,0 +1,24 @@
+package redact
+
+type Options struct {
+	// Namespaces of the callers which get the full errors
+	Namespaces []string
+	// Scopes of the callers which get the full errors, callers with any of them are trusted
+	Scopes []string
+}
+
+type Option func(o *Options)
+
+// Namespaces trusts the callers with accounts issued by the namespaces
+func Namespaces(ns ...string) Option {
+	return func(o *Options) {
+		o.Namespaces = append(o.Namespaces, ns...)
+	}
+}
+
+// Scopes trusts the callers with any of the scopes
+func Scopes(s ...string) Option {
+	return func(o *Options) {
+		o.Scopes = append(o.Scopes, s...)
+	}
+}
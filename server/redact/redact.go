@@ -0,0 +1,113 @@
+// Package redact sanitizes the errors returned to untrusted callers of a service, such as the
+// requests coming through the api gateway, so they don't leak stack traces or internal addresses
+package redact
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/server"
+)
+
+var (
+	// Replacement of the redacted parts of the error details
+	Replacement = "[redacted]"
+
+	// frame matches the lines of a stack trace
+	frame = regexp.MustCompile(`^(goroutine \d+ |\s+\S+\.go:\d+)`)
+	// address matches ip addresses and internal hostnames with an optional port
+	address = regexp.MustCompile(`\b(\d{1,3}(\.\d{1,3}){3}|localhost|[\w-]+(\.[\w-]+)*\.(local|internal|svc|cluster\.local|lan))(:\d+)?\b`)
+)
+
+type redactor struct {
+	opts       Options
+	namespaces map[string]bool
+}
+
+// NewTranslator returns an error translator which redacts the errors returned to the callers
+// outside the trusted namespaces or without any of the trusted scopes, pass it to the server
+// with server.TranslateErrors. Server errors are replaced with their status and the details
+// of client errors are stripped of stack traces and internal addresses.
+func NewTranslator(opts ...Option) server.ErrorTranslator {
+	var options Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	r := &redactor{
+		opts:       options,
+		namespaces: make(map[string]bool),
+	}
+	for _, ns := range options.Namespaces {
+		r.namespaces[ns] = true
+	}
+
+	return r.translate
+}
+
+// trusted returns true if the caller gets the full error
+func (r *redactor) trusted(ctx context.Context) bool {
+	acc, ok := auth.AccountFromContext(ctx)
+	if !ok {
+		return false
+	}
+	if r.namespaces[acc.Issuer] {
+		return true
+	}
+	for _, s := range r.opts.Scopes {
+		for _, as := range acc.Scopes {
+			if s == as {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *redactor) translate(ctx context.Context, req server.Request, err error) error {
+	if r.trusted(ctx) {
+		return err
+	}
+
+	verr := errors.FromError(err)
+	if verr.Code == 0 {
+		// not a micro error so it's unexpected
+		verr.Code = http.StatusInternalServerError
+	}
+
+	rerr := &errors.Error{
+		Id:         verr.Id,
+		Code:       verr.Code,
+		Status:     http.StatusText(int(verr.Code)),
+		Violations: verr.Violations,
+		Retry:      verr.Retry,
+	}
+	if len(rerr.Id) == 0 {
+		rerr.Id = req.Service()
+	}
+
+	if verr.Code >= http.StatusInternalServerError {
+		rerr.Detail = rerr.Status
+	} else {
+		rerr.Detail = Detail(verr.Detail)
+	}
+
+	return rerr
+}
+
+// Detail strips the stack trace and internal addresses from the detail of an error
+func Detail(detail string) string {
+	lines := strings.Split(detail, "\n")
+	for i, l := range lines {
+		if frame.MatchString(l) {
+			lines = lines[:i]
+			break
+		}
+	}
+
+	return address.ReplaceAllString(strings.TrimSpace(strings.Join(lines, "\n")), Replacement)
+}
@@ -0,0 +1,72 @@
+package redact
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/codec"
+	"github.com/micro/go-micro/v3/errors"
+)
+
+type testRequest struct{}
+
+func (r *testRequest) Service() string           { return "test" }
+func (r *testRequest) Method() string            { return "Foo.Bar" }
+func (r *testRequest) Endpoint() string          { return "Foo.Bar" }
+func (r *testRequest) ContentType() string       { return "application/json" }
+func (r *testRequest) Header() map[string]string { return nil }
+func (r *testRequest) Body() interface{}         { return nil }
+func (r *testRequest) Read() ([]byte, error)     { return nil, nil }
+func (r *testRequest) Codec() codec.Reader       { return nil }
+func (r *testRequest) Stream() bool              { return false }
+
+func TestTranslator(t *testing.T) {
+	translate := NewTranslator(Namespaces("micro"), Scopes("admin"))
+	req := &testRequest{}
+
+	// server errors are replaced with their status
+	err := translate(context.Background(), req, fmt.Errorf("dial tcp 10.0.0.1:5432: connection refused"))
+	verr, ok := err.(*errors.Error)
+	if !ok || verr.Code != 500 || verr.Detail != "Internal Server Error" || verr.Id != "test" {
+		t.Fatalf("Expected an internal server error got %v", err)
+	}
+
+	// client errors keep their detail without the internals
+	berr := errors.BadRequest("test", "invalid name from db.prod.internal:5432\ngoroutine 1 [running]:\nmain.main()\n\t/src/main.go:12 +0x1d").(*errors.Error).
+		AddViolation("name", "must be set")
+	verr = translate(context.Background(), req, berr).(*errors.Error)
+	if verr.Code != 400 || verr.Detail != "invalid name from [redacted]" {
+		t.Fatalf("Expected the redacted detail got %q", verr.Detail)
+	}
+	if len(verr.Violations) != 1 {
+		t.Fatalf("Expected the violations to be kept got %v", verr.Violations)
+	}
+
+	// trusted callers get the full error
+	for _, acc := range []*auth.Account{{Issuer: "micro"}, {Issuer: "foo", Scopes: []string{"admin"}}} {
+		ctx := auth.ContextWithAccount(context.Background(), acc)
+		if err := translate(ctx, req, berr); err != berr {
+			t.Fatalf("Expected the full error for %v got %v", acc, err)
+		}
+	}
+}
+
+func TestDetail(t *testing.T) {
+	testData := []struct {
+		detail string
+		expect string
+	}{
+		{"not found", "not found"},
+		{"lookup localhost: no such host", "lookup [redacted]: no such host"},
+		{"call to 192.168.1.2 failed", "call to [redacted] failed"},
+		{"timeout from foo.default.svc.cluster.local:8080", "timeout from [redacted]"},
+	}
+
+	for _, d := range testData {
+		if v := Detail(d.detail); v != d.expect {
+			t.Fatalf("Expected %q got %q", d.expect, v)
+		}
+	}
+}
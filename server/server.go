@@ -140,4 +140,6 @@ var (
 	DefaultRegisterCheck    = func(context.Context) error { return nil }
 	DefaultRegisterInterval = time.Second * 30
 	DefaultRegisterTTL      = time.Second * 90
+	DefaultRegisterJitter   = 0.1
+	DefaultRegisterFailures = 3
 )
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/micro/go-micro/v3/debug/trace"
+)
+
+// finish records the error if any and finishes the span
+func finish(t trace.Tracer, span *trace.Span, err error) {
+	if err != nil {
+		span.Metadata["error"] = err.Error()
+	}
+	t.Finish(span)
+}
+
+// tracer returns the tracer, or the default tracer if nil
+func tracer(t trace.Tracer) trace.Tracer {
+	if t != nil {
+		return t
+	}
+	return trace.DefaultTracer
+}
+
+// TraceHandlerWrapper traces the requests served with the default tracer, it's a
+// default wrapper of the handlers of the servers and does nothing until a tracer is set
+func TraceHandlerWrapper(fn HandlerFunc) HandlerFunc {
+	return NewTraceHandlerWrapper(nil)(fn)
+}
+
+// NewTraceHandlerWrapper returns a wrapper which traces the requests served with the tracer
+func NewTraceHandlerWrapper(t trace.Tracer) HandlerWrapper {
+	return func(fn HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req Request, rsp interface{}) error {
+			tr := tracer(t)
+			newCtx, span := tr.Start(ctx, fmt.Sprintf("%s.%s", req.Service(), req.Endpoint()))
+			if span == nil {
+				return fn(ctx, req, rsp)
+			}
+			span.Type = trace.SpanTypeRequestInbound
+			span.Service = req.Service()
+
+			err := fn(newCtx, req, rsp)
+			finish(tr, span, err)
+			return err
+		}
+	}
+}
+
+// TraceSubscriberWrapper traces the messages processed with the default tracer, it's a
+// default wrapper of the subscribers of the servers and does nothing until a tracer is set
+func TraceSubscriberWrapper(fn SubscriberFunc) SubscriberFunc {
+	return NewTraceSubscriberWrapper(nil)(fn)
+}
+
+// NewTraceSubscriberWrapper returns a wrapper which traces the messages processed with the tracer
+func NewTraceSubscriberWrapper(t trace.Tracer) SubscriberWrapper {
+	return func(fn SubscriberFunc) SubscriberFunc {
+		return func(ctx context.Context, msg Message) error {
+			tr := tracer(t)
+			newCtx, span := tr.Start(ctx, "Sub from "+msg.Topic())
+			if span == nil {
+				return fn(ctx, msg)
+			}
+			span.Type = trace.SpanTypeRequestInbound
+
+			err := fn(newCtx, msg)
+			finish(tr, span, err)
+			return err
+		}
+	}
+}
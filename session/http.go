@@ -0,0 +1,56 @@
+package session
+
+import (
+	"net/http"
+)
+
+// SetCookie sets the cookie holding the session id, expiring with the session
+func (s *Sessions) SetCookie(w http.ResponseWriter, r *http.Request, sess *Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.opts.Cookie,
+		Value:    sess.Id,
+		Path:     "/",
+		Expires:  sess.Expiry,
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearCookie removes the cookie holding the session id
+func (s *Sessions) ClearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.opts.Cookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+}
+
+// Handler wraps a http handler, such as the api server's, so the session of the
+// cookie is refreshed and held by the context of the request. Requests without a
+// valid session are passed on without one and their cookie is cleared.
+func (s *Sessions) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie(s.opts.Cookie)
+		if err != nil || len(c.Value) == 0 {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		sess, err := s.Refresh(c.Value)
+		if err == ErrNotFound {
+			s.ClearCookie(w)
+			h.ServeHTTP(w, r)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// slide the expiry of the cookie along with the session's
+		s.SetCookie(w, r, sess)
+		h.ServeHTTP(w, r.WithContext(NewContext(r.Context(), sess)))
+	})
+}
@@ -0,0 +1,58 @@
+package session
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+var (
+	// DefaultTTL is how long a session lives without being refreshed
+	DefaultTTL = time.Hour * 24
+	// DefaultCookie is the name of the cookie holding the session id
+	DefaultCookie = "micro-session"
+)
+
+// Options of the sessions
+type Options struct {
+	// Store the sessions are kept in
+	Store store.Store
+	// TTL is how long a session lives without being refreshed
+	TTL time.Duration
+	// Limit of concurrent sessions per account, the oldest are destroyed
+	// when it's reached. Zero means unlimited.
+	Limit int
+	// Cookie is the name of the cookie holding the session id
+	Cookie string
+}
+
+// Option sets an option of the sessions
+type Option func(o *Options)
+
+// Store sets the store the sessions are kept in
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// TTL sets how long a session lives without being refreshed
+func TTL(d time.Duration) Option {
+	return func(o *Options) {
+		o.TTL = d
+	}
+}
+
+// Limit sets the number of concurrent sessions per account
+func Limit(n int) Option {
+	return func(o *Options) {
+		o.Limit = n
+	}
+}
+
+// Cookie sets the name of the cookie holding the session id
+func Cookie(name string) Option {
+	return func(o *Options) {
+		o.Cookie = name
+	}
+}
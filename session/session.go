@@ -0,0 +1,212 @@
+// Package session provides server side sessions kept in the store. The expiry of a
+// session slides forward every time it's refreshed.
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/store"
+)
+
+var (
+	// Prefix of the keys of the sessions in the store
+	Prefix = "session/"
+
+	// ErrNotFound is returned when a session doesn't exist or expired
+	ErrNotFound = errors.New("session not found")
+)
+
+// Session of an account
+type Session struct {
+	// Id of the session, as set in the cookie
+	Id string `json:"id"`
+	// Account the session belongs to
+	Account string `json:"account"`
+	// Metadata of the session
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Created is when the session was created
+	Created time.Time `json:"created"`
+	// Expiry is when the session expires unless refreshed
+	Expiry time.Time `json:"-"`
+}
+
+// Sessions are kept in the store
+type Sessions struct {
+	opts Options
+}
+
+// NewSessions returns sessions kept in the store
+func NewSessions(opts ...Option) *Sessions {
+	options := Options{
+		Store:  store.DefaultStore,
+		TTL:    DefaultTTL,
+		Cookie: DefaultCookie,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return &Sessions{opts: options}
+}
+
+// Options returns the options of the sessions
+func (s *Sessions) Options() Options {
+	return s.opts
+}
+
+func sessionKey(id string) string {
+	return Prefix + id
+}
+
+func accountKey(account, id string) string {
+	return Prefix + "account/" + account + "/" + id
+}
+
+// Create a session for the account. If the account reached the limit of
+// concurrent sessions its oldest sessions are destroyed.
+func (s *Sessions) Create(account string, md map[string]string) (*Session, error) {
+	if s.opts.Limit > 0 {
+		sessions, err := s.List(account)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i <= len(sessions)-s.opts.Limit; i++ {
+			if err := s.Destroy(sessions[i].Id); err != nil && err != ErrNotFound {
+				return nil, err
+			}
+		}
+	}
+
+	sess := &Session{
+		Id:       uuid.New().String(),
+		Account:  account,
+		Metadata: md,
+		Created:  time.Now(),
+		Expiry:   time.Now().Add(s.opts.TTL),
+	}
+
+	b, err := json.Marshal(sess)
+	if err != nil {
+		return nil, err
+	}
+
+	// the account key is written first so a session is never left out of the list
+	if err := s.opts.Store.Write(&store.Record{
+		Key:    accountKey(account, sess.Id),
+		Value:  []byte(sess.Id),
+		Expiry: s.opts.TTL,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.opts.Store.Write(&store.Record{
+		Key:    sessionKey(sess.Id),
+		Value:  b,
+		Expiry: s.opts.TTL,
+	}); err != nil {
+		return nil, err
+	}
+
+	return sess, nil
+}
+
+// Get the session, ErrNotFound is returned if it doesn't exist or expired
+func (s *Sessions) Get(id string) (*Session, error) {
+	recs, err := s.opts.Store.Read(sessionKey(id))
+	if err == store.ErrNotFound || (err == nil && len(recs) == 0) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	sess := new(Session)
+	if err := json.Unmarshal(recs[0].Value, sess); err != nil {
+		return nil, err
+	}
+	if recs[0].Expiry > 0 {
+		sess.Expiry = time.Now().Add(recs[0].Expiry)
+	}
+
+	return sess, nil
+}
+
+// Refresh slides the expiry of the session forward by the ttl
+func (s *Sessions) Refresh(id string) (*Session, error) {
+	sess, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.Expire(s.opts.Store, accountKey(sess.Account, id), s.opts.TTL); err != nil && err != store.ErrNotFound {
+		return nil, err
+	}
+
+	if err := store.Expire(s.opts.Store, sessionKey(id), s.opts.TTL); err == store.ErrNotFound {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	sess.Expiry = time.Now().Add(s.opts.TTL)
+	return sess, nil
+}
+
+// Destroy the session
+func (s *Sessions) Destroy(id string) error {
+	sess, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.opts.Store.Delete(sessionKey(id)); err != nil && err != store.ErrNotFound {
+		return err
+	}
+
+	if err := s.opts.Store.Delete(accountKey(sess.Account, id)); err != nil && err != store.ErrNotFound {
+		return err
+	}
+
+	return nil
+}
+
+// List the sessions of the account, oldest first
+func (s *Sessions) List(account string) ([]*Session, error) {
+	recs, err := s.opts.Store.Read(accountKey(account, ""), store.ReadPrefix())
+	if err != nil && err != store.ErrNotFound {
+		return nil, err
+	}
+
+	sessions := make([]*Session, 0, len(recs))
+	for _, r := range recs {
+		sess, err := s.Get(string(r.Value))
+		if err == ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Created.Before(sessions[j].Created)
+	})
+
+	return sessions, nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a context holding the session
+func NewContext(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// FromContext returns the session held by the context
+func FromContext(ctx context.Context) (*Session, bool) {
+	s, ok := ctx.Value(contextKey{}).(*Session)
+	return s, ok
+}
@@ -0,0 +1,112 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/store/memory"
+)
+
+func TestSessions(t *testing.T) {
+	s := NewSessions(Store(memory.NewStore()), TTL(time.Millisecond*100), Limit(2))
+
+	first, err := s.Create("alice", map[string]string{"agent": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sess, err := s.Get(first.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess.Account != "alice" || sess.Metadata["agent"] != "test" {
+		t.Fatalf("Unexpected session %+v", sess)
+	}
+
+	// refreshing slides the expiry
+	time.Sleep(time.Millisecond * 60)
+	if _, err := s.Refresh(first.Id); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 60)
+	if _, err := s.Get(first.Id); err != nil {
+		t.Fatalf("Expected the refreshed session, got %v", err)
+	}
+
+	// the oldest session is destroyed when the limit is reached
+	second, err := s.Create("alice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	third, err := s.Create("alice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(first.Id); err != ErrNotFound {
+		t.Fatalf("Expected %v got %v", ErrNotFound, err)
+	}
+
+	sessions, err := s.List("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].Id != second.Id || sessions[1].Id != third.Id {
+		t.Fatalf("Unexpected sessions %+v", sessions)
+	}
+
+	if err := s.Destroy(second.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Refresh(second.Id); err != ErrNotFound {
+		t.Fatalf("Expected %v got %v", ErrNotFound, err)
+	}
+
+	// sessions expire unless refreshed
+	time.Sleep(time.Millisecond * 150)
+	if _, err := s.Get(third.Id); err != ErrNotFound {
+		t.Fatalf("Expected %v got %v", ErrNotFound, err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	s := NewSessions(Store(memory.NewStore()))
+
+	sess, err := s.Create("alice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got *Session
+	h := s.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = FromContext(r.Context())
+	}))
+
+	// a valid session is held by the context and its cookie refreshed
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: DefaultCookie, Value: sess.Id})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if got == nil || got.Id != sess.Id {
+		t.Fatalf("Expected session %v got %+v", sess.Id, got)
+	}
+	if c := w.Result().Cookies(); len(c) != 1 || c[0].Value != sess.Id {
+		t.Fatalf("Expected the cookie to be refreshed, got %v", c)
+	}
+
+	// an unknown session is cleared
+	got = nil
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: DefaultCookie, Value: "unknown"})
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if got != nil {
+		t.Fatalf("Expected no session got %+v", got)
+	}
+	if c := w.Result().Cookies(); len(c) != 1 || c[0].MaxAge >= 0 {
+		t.Fatalf("Expected the cookie to be cleared, got %v", c)
+	}
+}
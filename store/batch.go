@@ -0,0 +1,246 @@
+package store
+
+import (
+	"sync"
+)
+
+// table the changes of a transaction are applied to
+type table struct {
+	database, table string
+}
+
+// change to a key, a nil record is a delete
+type change struct {
+	key    string
+	record *Record
+	// the conditions of the write
+	ifMatch, ifNotExists bool
+}
+
+type batchTx struct {
+	store Store
+
+	sync.Mutex
+	done bool
+	// the latest change to each key in each table
+	changes map[table]map[string]*change
+}
+
+// NewTx returns a best-effort transaction for stores which don't support transactions.
+// The changes are buffered and applied on commit using BatchWrite and BatchDelete. If
+// applying them fails the previous values are restored where possible. Only reads of
+// a single key see the buffered changes.
+func NewTx(s Store) Tx {
+	return &batchTx{
+		store:   s,
+		changes: make(map[table]map[string]*change),
+	}
+}
+
+func (b *batchTx) set(t table, c *change) error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.done {
+		return ErrTxDone
+	}
+
+	if _, ok := b.changes[t]; !ok {
+		b.changes[t] = make(map[string]*change)
+	}
+	b.changes[t][c.key] = c
+
+	return nil
+}
+
+func (b *batchTx) Read(key string, opts ...ReadOption) ([]*Record, error) {
+	var options ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	b.Lock()
+	if b.done {
+		b.Unlock()
+		return nil, ErrTxDone
+	}
+	c, ok := b.changes[table{options.Database, options.Table}][key]
+	b.Unlock()
+
+	if !ok || options.Prefix || options.Suffix {
+		return b.store.Read(key, opts...)
+	}
+
+	if c.record == nil {
+		return nil, ErrNotFound
+	}
+
+	return []*Record{copyRecord(c.record)}, nil
+}
+
+func (b *batchTx) Write(r *Record, opts ...WriteOption) error {
+	var options WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return b.set(table{options.Database, options.Table}, &change{
+		key:         r.Key,
+		record:      copyRecord(r),
+		ifMatch:     options.IfMatch,
+		ifNotExists: options.IfNotExists,
+	})
+}
+
+func (b *batchTx) Delete(key string, opts ...DeleteOption) error {
+	var options DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return b.set(table{options.Database, options.Table}, &change{key: key})
+}
+
+func (b *batchTx) Commit() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.done {
+		return ErrTxDone
+	}
+	b.done = true
+
+	// the previous values of the tables which have been changed
+	var applied []map[string]*Record
+	var tables []table
+
+	for t, changes := range b.changes {
+		previous, err := b.apply(t, changes)
+		if err != nil {
+			// undo the changes made to the other tables
+			for i, prev := range applied {
+				b.restore(tables[i], prev)
+			}
+			return err
+		}
+		applied = append(applied, previous)
+		tables = append(tables, t)
+	}
+
+	return nil
+}
+
+// apply the changes to the table returning the previous values, a nil record
+// means the key didn't exist. The table is restored if the changes fail.
+func (b *batchTx) apply(t table, changes map[string]*change) (map[string]*Record, error) {
+	previous := make(map[string]*Record, len(changes))
+
+	// the writes are grouped by their conditions
+	var writes, matches, absent []*Record
+	var deletes []string
+
+	for key, c := range changes {
+		recs, err := b.store.Read(key, ReadFrom(t.database, t.table))
+		if err == ErrNotFound || (err == nil && len(recs) == 0) {
+			previous[key] = nil
+		} else if err != nil {
+			return nil, err
+		} else {
+			previous[key] = recs[0]
+		}
+
+		switch {
+		case c.record == nil:
+			deletes = append(deletes, key)
+		case c.ifNotExists:
+			absent = append(absent, c.record)
+		case c.ifMatch:
+			matches = append(matches, c.record)
+		default:
+			writes = append(writes, c.record)
+		}
+	}
+
+	// the conditional writes are applied first as they're the most likely to fail
+	batches := []struct {
+		recs []*Record
+		opts []WriteOption
+	}{
+		{absent, []WriteOption{WriteTo(t.database, t.table), WriteIfNotExists()}},
+		{matches, []WriteOption{WriteTo(t.database, t.table), WriteIfMatch()}},
+		{writes, []WriteOption{WriteTo(t.database, t.table)}},
+	}
+
+	// the previous values of the keys which have been changed so only they're restored
+	applied := make(map[string]*Record, len(changes))
+
+	for _, batch := range batches {
+		if len(batch.recs) == 0 {
+			continue
+		}
+		if err := b.store.BatchWrite(batch.recs, batch.opts...); err != nil {
+			b.restore(t, applied)
+			return nil, err
+		}
+		for _, rec := range batch.recs {
+			applied[rec.Key] = previous[rec.Key]
+		}
+	}
+
+	if len(deletes) > 0 {
+		if err := b.store.BatchDelete(deletes, DeleteFrom(t.database, t.table)); err != nil {
+			b.restore(t, applied)
+			return nil, err
+		}
+	}
+
+	return previous, nil
+}
+
+// restore the previous values of the table
+func (b *batchTx) restore(t table, previous map[string]*Record) {
+	var writes []*Record
+	var deletes []string
+
+	for key, rec := range previous {
+		if rec == nil {
+			deletes = append(deletes, key)
+		} else {
+			writes = append(writes, rec)
+		}
+	}
+
+	if len(writes) > 0 {
+		b.store.BatchWrite(writes, WriteTo(t.database, t.table))
+	}
+	if len(deletes) > 0 {
+		b.store.BatchDelete(deletes, DeleteFrom(t.database, t.table))
+	}
+}
+
+func (b *batchTx) Rollback() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.done {
+		return ErrTxDone
+	}
+
+	b.done = true
+	b.changes = nil
+
+	return nil
+}
+
+func copyRecord(r *Record) *Record {
+	rec := &Record{
+		Key:      r.Key,
+		Value:    make([]byte, len(r.Value)),
+		Metadata: make(map[string]interface{}, len(r.Metadata)),
+		Expiry:   r.Expiry,
+		Version:  r.Version,
+	}
+	copy(rec.Value, r.Value)
+	for k, v := range r.Metadata {
+		rec.Metadata[k] = v
+	}
+	return rec
+}
@@ -0,0 +1,50 @@
+// Package blob is an interface for storing large objects such as files and images.
+// Unlike the store the objects are streamed rather than held in memory.
+package blob
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when an object doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidKey is returned when a key can't be used as an object name
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrNotSupported is returned when the implementation doesn't support the operation
+	ErrNotSupported = errors.New("not supported")
+)
+
+// Blob is a large object storage interface
+type Blob interface {
+	// Init initialises the blob store
+	Init(...Option) error
+	// Options returns the current options
+	Options() Options
+	// Put streams the contents of the reader to the object, replacing it if it exists
+	Put(key string, r io.Reader, opts ...PutOption) error
+	// Get returns the contents of the object which must be closed by the caller
+	Get(key string) (io.ReadCloser, *Object, error)
+	// Delete the object, deleting an object which doesn't exist isn't an error
+	Delete(key string) error
+	// List the objects sorted by key
+	List(opts ...ListOption) ([]*Object, error)
+	// URL returns a presigned url which gives temporary access to the object
+	URL(key string, opts ...URLOption) (string, error)
+	// String returns the name of the implementation
+	String() string
+}
+
+// Object describes a stored object
+type Object struct {
+	// The key of the object
+	Key string `json:"key"`
+	// The size of the object in bytes
+	Size int64 `json:"size"`
+	// The content type of the object, it may not be set when listing
+	ContentType string `json:"content_type,omitempty"`
+	// The time the object was last modified
+	Updated time.Time `json:"updated"`
+}
@@ -0,0 +1,265 @@
+// Package file is a local disk backed blob store
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/micro/go-micro/v3/store/blob"
+)
+
+var (
+	// DefaultBucket is used if no bucket is provided
+	DefaultBucket = "micro"
+	// DefaultDir is the default directory the buckets are stored in
+	DefaultDir = filepath.Join(os.TempDir(), "micro", "blob")
+
+	// the directories of the objects and their metadata in a bucket
+	dataDir = "data"
+	metaDir = "meta"
+)
+
+type fileBlob struct {
+	sync.RWMutex
+	options blob.Options
+	dir     string
+}
+
+// metadata stored alongside the object
+type metadata struct {
+	ContentType string `json:"content_type"`
+}
+
+// NewBlob returns a blob store which keeps the objects on the local disk
+func NewBlob(opts ...blob.Option) blob.Blob {
+	f := &fileBlob{
+		options: blob.Options{
+			Bucket: DefaultBucket,
+		},
+	}
+	f.configure(opts...)
+	return f
+}
+
+func (f *fileBlob) configure(opts ...blob.Option) error {
+	f.Lock()
+	defer f.Unlock()
+
+	for _, o := range opts {
+		o(&f.options)
+	}
+
+	if len(f.options.Bucket) == 0 {
+		f.options.Bucket = DefaultBucket
+	}
+
+	dir := DefaultDir
+	if f.options.Context != nil {
+		if d, ok := f.options.Context.Value(dirKey{}).(string); ok && len(d) > 0 {
+			dir = d
+		}
+	}
+	f.dir = filepath.Join(dir, f.options.Bucket)
+
+	return os.MkdirAll(filepath.Join(f.dir, dataDir), 0700)
+}
+
+// paths returns the paths of the object and its metadata
+func (f *fileBlob) paths(key string) (string, string, error) {
+	// keys must be relative paths which don't leave the bucket
+	if len(key) == 0 || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", "", blob.ErrInvalidKey
+	}
+
+	f.RLock()
+	dir := f.dir
+	f.RUnlock()
+
+	name := filepath.FromSlash(key)
+	return filepath.Join(dir, dataDir, name), filepath.Join(dir, metaDir, name), nil
+}
+
+// write the contents of the reader to the file, the file is only replaced once fully written
+func write(name string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".tmp-")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+// contentType returns the content type saved with the object
+func contentType(name string) string {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return blob.DefaultContentType
+	}
+	var md metadata
+	if err := json.Unmarshal(b, &md); err != nil || len(md.ContentType) == 0 {
+		return blob.DefaultContentType
+	}
+	return md.ContentType
+}
+
+func (f *fileBlob) Init(opts ...blob.Option) error {
+	return f.configure(opts...)
+}
+
+func (f *fileBlob) Options() blob.Options {
+	f.RLock()
+	defer f.RUnlock()
+	return f.options
+}
+
+func (f *fileBlob) Put(key string, r io.Reader, opts ...blob.PutOption) error {
+	options := blob.NewPutOptions(opts...)
+
+	data, meta, err := f.paths(key)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(&metadata{ContentType: options.ContentType})
+	if err != nil {
+		return err
+	}
+
+	if err := write(data, r); err != nil {
+		return err
+	}
+
+	return write(meta, bytes.NewReader(b))
+}
+
+func (f *fileBlob) Get(key string) (io.ReadCloser, *blob.Object, error) {
+	data, meta, err := f.paths(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(data)
+	if os.IsNotExist(err) {
+		return nil, nil, blob.ErrNotFound
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, nil, blob.ErrNotFound
+	}
+
+	return file, &blob.Object{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: contentType(meta),
+		Updated:     info.ModTime(),
+	}, nil
+}
+
+func (f *fileBlob) Delete(key string) error {
+	data, meta, err := f.paths(key)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range []string{data, meta} {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *fileBlob) List(opts ...blob.ListOption) ([]*blob.Object, error) {
+	var options blob.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	f.RLock()
+	dir := f.dir
+	f.RUnlock()
+
+	root := filepath.Join(dir, dataDir)
+
+	var objects []*blob.Object
+
+	err := filepath.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// skip the directories and any partially written files
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".tmp-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+
+		if !strings.HasPrefix(key, options.Prefix) {
+			return nil
+		}
+
+		objects = append(objects, &blob.Object{
+			Key:         key,
+			Size:        info.Size(),
+			ContentType: contentType(filepath.Join(dir, metaDir, rel)),
+			Updated:     info.ModTime(),
+		})
+
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+
+	if options.Limit > 0 && options.Limit < uint(len(objects)) {
+		objects = objects[:options.Limit]
+	}
+
+	return objects, nil
+}
+
+// URL isn't supported as there's no server to sign urls for
+func (f *fileBlob) URL(key string, opts ...blob.URLOption) (string, error) {
+	return "", blob.ErrNotSupported
+}
+
+func (f *fileBlob) String() string {
+	return "file"
+}
@@ -0,0 +1,87 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v3/store/blob"
+)
+
+func TestFileBlob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := NewBlob(Dir(dir), blob.Bucket("test"))
+
+	if _, _, err := b.Get("foo"); err != blob.ErrNotFound {
+		t.Fatalf("Expected %v got %v", blob.ErrNotFound, err)
+	}
+
+	for _, key := range []string{"a/1.txt", "a/2.json", "b/1.txt"} {
+		var opts []blob.PutOption
+		if strings.HasSuffix(key, ".json") {
+			opts = append(opts, blob.ContentType("application/json"))
+		}
+		if err := b.Put(key, strings.NewReader("data "+key), opts...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, obj, err := b.Get("a/2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "data a/2.json" {
+		t.Fatalf("Unexpected data %s", data)
+	}
+	if obj.Key != "a/2.json" || obj.Size != int64(len(data)) || obj.ContentType != "application/json" || obj.Updated.IsZero() {
+		t.Fatalf("Unexpected object %+v", obj)
+	}
+
+	objs, err := b.List(blob.ListPrefix("a/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 || objs[0].Key != "a/1.txt" || objs[0].ContentType != blob.DefaultContentType || objs[1].Key != "a/2.json" {
+		t.Fatalf("Unexpected objects %+v", objs)
+	}
+
+	objs, err = b.List(blob.ListLimit(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 1 || objs[0].Key != "a/1.txt" {
+		t.Fatalf("Unexpected objects %+v", objs)
+	}
+
+	if err := b.Delete("a/1.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete("a/1.txt"); err != nil {
+		t.Fatalf("Expected deleting a missing object to succeed got %v", err)
+	}
+	if _, _, err := b.Get("a/1.txt"); err != blob.ErrNotFound {
+		t.Fatalf("Expected %v got %v", blob.ErrNotFound, err)
+	}
+
+	// keys can't escape the bucket
+	for _, key := range []string{"", "../foo", "/etc/passwd", "a/../../foo", "a//b"} {
+		if err := b.Put(key, strings.NewReader("")); err != blob.ErrInvalidKey {
+			t.Fatalf("Expected %v for %q got %v", blob.ErrInvalidKey, key, err)
+		}
+	}
+
+	if _, err := b.URL("a/2.json"); err != blob.ErrNotSupported {
+		t.Fatalf("Expected %v got %v", blob.ErrNotSupported, err)
+	}
+}
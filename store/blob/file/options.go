@@ -0,0 +1,19 @@
+package file
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/store/blob"
+)
+
+type dirKey struct{}
+
+// Dir sets the directory the buckets are stored in, defaults to DefaultDir
+func Dir(d string) blob.Option {
+	return func(o *blob.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, dirKey{}, d)
+	}
+}
@@ -0,0 +1,339 @@
+// Package gcs is a google cloud storage backed blob store using the json api
+package gcs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/store/blob"
+	"golang.org/x/oauth2/google"
+)
+
+var (
+	// DefaultBucket is used if no bucket is provided
+	DefaultBucket = "micro"
+	// DefaultEndpoint is the google cloud storage api
+	DefaultEndpoint = "https://storage.googleapis.com"
+
+	// ErrNoSigningKey is returned when urls are requested without a service account key
+	ErrNoSigningKey = errors.New("no service account key to sign urls")
+
+	// the scope needed to read and write objects
+	scope = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+type gcsBlob struct {
+	sync.RWMutex
+	options  blob.Options
+	client   *http.Client
+	endpoint string
+
+	// the service account used to sign urls
+	accessID   string
+	privateKey []byte
+}
+
+// object is the resource returned by the api
+type object struct {
+	Name        string    `json:"name"`
+	Size        string    `json:"size"`
+	ContentType string    `json:"contentType"`
+	Updated     time.Time `json:"updated"`
+}
+
+type objectList struct {
+	Items         []*object `json:"items"`
+	NextPageToken string    `json:"nextPageToken"`
+}
+
+// NewBlob returns a blob store backed by google cloud storage
+func NewBlob(opts ...blob.Option) blob.Blob {
+	g := &gcsBlob{
+		options: blob.Options{
+			Bucket: DefaultBucket,
+		},
+	}
+
+	// best-effort configure the blob store
+	if err := g.configure(opts...); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error("Error configuring blob store ", err)
+		}
+	}
+
+	return g
+}
+
+func (g *gcsBlob) configure(opts ...blob.Option) error {
+	g.Lock()
+	defer g.Unlock()
+
+	for _, o := range opts {
+		o(&g.options)
+	}
+
+	if len(g.options.Bucket) == 0 {
+		g.options.Bucket = DefaultBucket
+	}
+
+	var credentials, endpoint string
+
+	if ctx := g.options.Context; ctx != nil {
+		credentials, _ = ctx.Value(credentialsKey{}).(string)
+		endpoint, _ = ctx.Value(endpointKey{}).(string)
+	}
+
+	ctx := context.Background()
+
+	var client *http.Client
+	var accessID string
+	var privateKey []byte
+
+	switch {
+	case len(credentials) > 0:
+		b, err := ioutil.ReadFile(credentials)
+		if err != nil {
+			return err
+		}
+		// the key of the service account is needed to sign urls
+		cfg, err := google.JWTConfigFromJSON(b, scope)
+		if err != nil {
+			return err
+		}
+		client = cfg.Client(ctx)
+		accessID = cfg.Email
+		privateKey = cfg.PrivateKey
+	case len(endpoint) > 0:
+		// emulators don't require authentication
+		client = http.DefaultClient
+	default:
+		c, err := google.DefaultClient(ctx, scope)
+		if err != nil {
+			return err
+		}
+		client = c
+	}
+
+	if len(endpoint) == 0 {
+		endpoint = DefaultEndpoint
+	}
+
+	g.client = client
+	g.endpoint = strings.TrimSuffix(endpoint, "/")
+	g.accessID = accessID
+	g.privateKey = privateKey
+
+	return nil
+}
+
+// do sends the request to the api path, an error is returned for unexpected status codes
+func (g *gcsBlob) do(method, path string, query url.Values, body io.Reader, header http.Header) (*http.Response, error) {
+	g.RLock()
+	client := g.client
+	endpoint := g.endpoint
+	bucket := g.options.Bucket
+	g.RUnlock()
+
+	if client == nil {
+		return nil, errors.New("client not initialised")
+	}
+
+	u := endpoint + strings.Replace(path, "{bucket}", url.PathEscape(bucket), 1)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	rsp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsp.StatusCode == http.StatusNotFound {
+		rsp.Body.Close()
+		return nil, blob.ErrNotFound
+	}
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		defer rsp.Body.Close()
+		b, _ := ioutil.ReadAll(io.LimitReader(rsp.Body, 1024))
+		return nil, fmt.Errorf("gcs: %s %s", rsp.Status, strings.TrimSpace(string(b)))
+	}
+
+	return rsp, nil
+}
+
+func (g *gcsBlob) Init(opts ...blob.Option) error {
+	return g.configure(opts...)
+}
+
+func (g *gcsBlob) Options() blob.Options {
+	g.RLock()
+	defer g.RUnlock()
+	return g.options
+}
+
+// Put streams the object to a media upload
+func (g *gcsBlob) Put(key string, r io.Reader, opts ...blob.PutOption) error {
+	if len(key) == 0 {
+		return blob.ErrInvalidKey
+	}
+
+	options := blob.NewPutOptions(opts...)
+
+	query := url.Values{}
+	query.Set("uploadType", "media")
+	query.Set("name", key)
+
+	header := http.Header{}
+	header.Set("Content-Type", options.ContentType)
+
+	rsp, err := g.do(http.MethodPost, "/upload/storage/v1/b/{bucket}/o", query, r, header)
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, rsp.Body)
+	return rsp.Body.Close()
+}
+
+func (g *gcsBlob) Get(key string) (io.ReadCloser, *blob.Object, error) {
+	if len(key) == 0 {
+		return nil, nil, blob.ErrInvalidKey
+	}
+
+	query := url.Values{}
+	query.Set("alt", "media")
+
+	rsp, err := g.do(http.MethodGet, "/storage/v1/b/{bucket}/o/"+url.PathEscape(key), query, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	obj := &blob.Object{
+		Key:         key,
+		Size:        rsp.ContentLength,
+		ContentType: rsp.Header.Get("Content-Type"),
+	}
+
+	// the length isn't set if the object is transcoded
+	if s := rsp.Header.Get("X-Goog-Stored-Content-Length"); len(s) > 0 {
+		obj.Size, _ = strconv.ParseInt(s, 10, 64)
+	}
+	if t, err := http.ParseTime(rsp.Header.Get("Last-Modified")); err == nil {
+		obj.Updated = t
+	}
+
+	return rsp.Body, obj, nil
+}
+
+func (g *gcsBlob) Delete(key string) error {
+	if len(key) == 0 {
+		return blob.ErrInvalidKey
+	}
+
+	rsp, err := g.do(http.MethodDelete, "/storage/v1/b/{bucket}/o/"+url.PathEscape(key), nil, nil, nil)
+	if err == blob.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return rsp.Body.Close()
+}
+
+func (g *gcsBlob) List(opts ...blob.ListOption) ([]*blob.Object, error) {
+	var options blob.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	query := url.Values{}
+	if len(options.Prefix) > 0 {
+		query.Set("prefix", options.Prefix)
+	}
+	if options.Limit > 0 && options.Limit < 1000 {
+		query.Set("maxResults", strconv.Itoa(int(options.Limit)))
+	}
+
+	var objects []*blob.Object
+
+	for {
+		rsp, err := g.do(http.MethodGet, "/storage/v1/b/{bucket}/o", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var list objectList
+		err = json.NewDecoder(rsp.Body).Decode(&list)
+		rsp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range list.Items {
+			size, _ := strconv.ParseInt(item.Size, 10, 64)
+			objects = append(objects, &blob.Object{
+				Key:         item.Name,
+				Size:        size,
+				ContentType: item.ContentType,
+				Updated:     item.Updated,
+			})
+			if options.Limit > 0 && uint(len(objects)) >= options.Limit {
+				return objects, nil
+			}
+		}
+
+		if len(list.NextPageToken) == 0 {
+			return objects, nil
+		}
+		query.Set("pageToken", list.NextPageToken)
+	}
+}
+
+// URL returns a v4 signed url, the blob store must be configured with a service account key
+func (g *gcsBlob) URL(key string, opts ...blob.URLOption) (string, error) {
+	if len(key) == 0 {
+		return "", blob.ErrInvalidKey
+	}
+
+	options := blob.NewURLOptions(opts...)
+
+	switch options.Method {
+	case http.MethodGet, http.MethodPut, http.MethodDelete:
+	default:
+		return "", blob.ErrNotSupported
+	}
+
+	g.RLock()
+	bucket := g.options.Bucket
+	accessID := g.accessID
+	privateKey := g.privateKey
+	g.RUnlock()
+
+	if len(privateKey) == 0 {
+		return "", ErrNoSigningKey
+	}
+
+	return signURL(accessID, privateKey, bucket, key, options, time.Now())
+}
+
+func (g *gcsBlob) String() string {
+	return "gcs"
+}
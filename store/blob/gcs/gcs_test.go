@@ -0,0 +1,175 @@
+package gcs
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/store/blob"
+)
+
+// fakeServer implements the parts of the json api used by the blob store
+type fakeServer struct {
+	sync.Mutex
+	objects map[string]*object
+	data    map[string][]byte
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	path := r.URL.EscapedPath()
+
+	switch {
+	case r.Method == http.MethodPost && path == "/upload/storage/v1/b/test/o":
+		b, _ := ioutil.ReadAll(r.Body)
+		name := r.URL.Query().Get("name")
+		f.data[name] = b
+		f.objects[name] = &object{
+			Name:        name,
+			Size:        strconv.Itoa(len(b)),
+			ContentType: r.Header.Get("Content-Type"),
+			Updated:     time.Now(),
+		}
+		json.NewEncoder(w).Encode(f.objects[name])
+	case r.Method == http.MethodGet && path == "/storage/v1/b/test/o":
+		var names []string
+		for name := range f.objects {
+			if strings.HasPrefix(name, r.URL.Query().Get("prefix")) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		var list objectList
+		for _, name := range names {
+			list.Items = append(list.Items, f.objects[name])
+		}
+		json.NewEncoder(w).Encode(&list)
+	case strings.HasPrefix(path, "/storage/v1/b/test/o/"):
+		name, _ := url.PathUnescape(strings.TrimPrefix(path, "/storage/v1/b/test/o/"))
+		obj, ok := f.objects[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.objects, name)
+			delete(f.data, name)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", obj.ContentType)
+		w.Header().Set("Last-Modified", obj.Updated.UTC().Format(http.TimeFormat))
+		w.Write(f.data[name])
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+func TestGCSBlob(t *testing.T) {
+	srv := httptest.NewServer(&fakeServer{
+		objects: make(map[string]*object),
+		data:    make(map[string][]byte),
+	})
+	defer srv.Close()
+
+	b := NewBlob(blob.Bucket("test"), Endpoint(srv.URL))
+
+	if _, _, err := b.Get("a/1.txt"); err != blob.ErrNotFound {
+		t.Fatalf("Expected %v got %v", blob.ErrNotFound, err)
+	}
+
+	if err := b.Put("a/1.txt", strings.NewReader("hello"), blob.ContentType("text/plain")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Put("b/2.txt", strings.NewReader("world")); err != nil {
+		t.Fatal(err)
+	}
+
+	r, obj, err := b.Get("a/1.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(r)
+	r.Close()
+	if string(data) != "hello" || obj.Size != 5 || obj.ContentType != "text/plain" || obj.Updated.IsZero() {
+		t.Fatalf("Unexpected object %+v with data %s", obj, data)
+	}
+
+	objs, err := b.List(blob.ListPrefix("a/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 1 || objs[0].Key != "a/1.txt" || objs[0].Size != 5 {
+		t.Fatalf("Unexpected objects %+v", objs)
+	}
+
+	if err := b.Delete("a/1.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete("a/1.txt"); err != nil {
+		t.Fatalf("Expected deleting a missing object to succeed got %v", err)
+	}
+
+	if _, err := b.URL("b/2.txt"); err != ErrNoSigningKey {
+		t.Fatalf("Expected %v got %v", ErrNoSigningKey, err)
+	}
+}
+
+func TestSignURL(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	options := blob.NewURLOptions(blob.URLExpiry(time.Hour))
+
+	u, err := signURL("test@example.iam.gserviceaccount.com", pemKey, "test", "a b/c.txt", options, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := "X-Goog-Algorithm=GOOG4-RSA-SHA256" +
+		"&X-Goog-Credential=test%40example.iam.gserviceaccount.com%2F20200102%2Fauto%2Fstorage%2Fgoog4_request" +
+		"&X-Goog-Date=20200102T030405Z&X-Goog-Expires=3600&X-Goog-SignedHeaders=host"
+
+	prefix := "https://storage.googleapis.com/test/a%20b/c.txt?" + query + "&X-Goog-Signature="
+	if !strings.HasPrefix(u, prefix) {
+		t.Fatalf("Expected the url to start with %s got %s", prefix, u)
+	}
+
+	canonical := "GET\n/test/a%20b/c.txt\n" + query + "\nhost:storage.googleapis.com\n\nhost\nUNSIGNED-PAYLOAD"
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := "GOOG4-RSA-SHA256\n20200102T030405Z\n20200102/auto/storage/goog4_request\n" + hex.EncodeToString(hash[:])
+	digest := sha256.Sum256([]byte(stringToSign))
+
+	sig, err := hex.DecodeString(strings.TrimPrefix(u, prefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+		t.Fatalf("Invalid signature: %v", err)
+	}
+
+	if _, err := signURL("test", pemKey, "test", "foo", blob.NewURLOptions(blob.URLExpiry(8*24*time.Hour)), now); err == nil {
+		t.Fatal("Expected an error for an expiry over 7 days")
+	}
+}
@@ -0,0 +1,33 @@
+package gcs
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/store/blob"
+)
+
+type credentialsKey struct{}
+
+type endpointKey struct{}
+
+// CredentialsFile sets the service account key file, otherwise the application
+// default credentials are used. The key is also used to sign urls.
+func CredentialsFile(path string) blob.Option {
+	return func(o *blob.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, credentialsKey{}, path)
+	}
+}
+
+// Endpoint sets the url of the storage api e.g for an emulator. Requests aren't
+// authenticated unless a credentials file is also provided.
+func Endpoint(e string) blob.Option {
+	return func(o *blob.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, endpointKey{}, e)
+	}
+}
@@ -0,0 +1,142 @@
+package gcs
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/micro/go-micro/v3/store/blob"
+)
+
+const (
+	// the host signed urls are issued for
+	signingHost = "storage.googleapis.com"
+	// the longest a v4 signed url can be valid for
+	maxExpiry = 7 * 24 * time.Hour
+)
+
+// escape percent encodes everything but the unreserved characters as required by v4 signing
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func parseKey(key []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(key)
+	if block != nil {
+		key = block.Bytes
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(key)
+	if err != nil {
+		return x509.ParsePKCS1PrivateKey(key)
+	}
+
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an rsa key")
+	}
+
+	return rsaKey, nil
+}
+
+// signURL returns a url signed with the GOOG4-RSA-SHA256 scheme
+func signURL(accessID string, privateKey []byte, bucket, key string, options blob.URLOptions, now time.Time) (string, error) {
+	if options.Expiry <= 0 || options.Expiry > maxExpiry {
+		return "", fmt.Errorf("expiry must be between 0 and %v", maxExpiry)
+	}
+
+	rsaKey, err := parseKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	now = now.UTC()
+	timestamp := now.Format("20060102T150405Z")
+	credentialScope := now.Format("20060102") + "/auto/storage/goog4_request"
+
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = escape(s)
+	}
+	path := "/" + escape(bucket) + "/" + strings.Join(segments, "/")
+
+	headers := map[string]string{"host": signingHost}
+	if len(options.ContentType) > 0 {
+		headers["content-type"] = options.ContentType
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	params := map[string]string{
+		"X-Goog-Algorithm":     "GOOG4-RSA-SHA256",
+		"X-Goog-Credential":    accessID + "/" + credentialScope,
+		"X-Goog-Date":          timestamp,
+		"X-Goog-Expires":       fmt.Sprintf("%d", int64(options.Expiry/time.Second)),
+		"X-Goog-SignedHeaders": signedHeaders,
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	query := make([]string, 0, len(keys))
+	for _, k := range keys {
+		query = append(query, escape(k)+"="+escape(params[k]))
+	}
+	canonicalQuery := strings.Join(query, "&")
+
+	canonicalRequest := strings.Join([]string{
+		options.Method,
+		path,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"GOOG4-RSA-SHA256",
+		timestamp,
+		credentialScope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	digest := sha256.Sum256([]byte(stringToSign))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return "https://" + signingHost + path + "?" + canonicalQuery + "&X-Goog-Signature=" + hex.EncodeToString(signature), nil
+}
@@ -0,0 +1,137 @@
+package blob
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+var (
+	// DefaultURLExpiry is how long presigned urls are valid for
+	DefaultURLExpiry = 15 * time.Minute
+	// DefaultContentType is used when no content type is provided
+	DefaultContentType = "application/octet-stream"
+)
+
+// Options contains configuration for the Blob
+type Options struct {
+	// Bucket the objects are stored in
+	Bucket string
+	// Context should contain all implementation specific options, using context.WithValue.
+	Context context.Context
+}
+
+// Option sets values in Options
+type Option func(o *Options)
+
+// Bucket the objects are stored in
+func Bucket(b string) Option {
+	return func(o *Options) {
+		o.Bucket = b
+	}
+}
+
+// WithContext sets the blob context, for any extra configuration
+func WithContext(c context.Context) Option {
+	return func(o *Options) {
+		o.Context = c
+	}
+}
+
+// PutOptions configures an individual Put operation
+type PutOptions struct {
+	// ContentType of the object
+	ContentType string
+}
+
+// PutOption sets values in PutOptions
+type PutOption func(o *PutOptions)
+
+// ContentType of the object, defaults to DefaultContentType
+func ContentType(ct string) PutOption {
+	return func(o *PutOptions) {
+		o.ContentType = ct
+	}
+}
+
+// NewPutOptions returns the put options with the defaults set
+func NewPutOptions(opts ...PutOption) PutOptions {
+	options := PutOptions{
+		ContentType: DefaultContentType,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
+
+// ListOptions configures an individual List operation
+type ListOptions struct {
+	// Prefix returns the objects with keys prefixed with it
+	Prefix string
+	// Limit limits the number of returned objects
+	Limit uint
+}
+
+// ListOption sets values in ListOptions
+type ListOption func(o *ListOptions)
+
+// ListPrefix returns the objects with keys prefixed with p
+func ListPrefix(p string) ListOption {
+	return func(o *ListOptions) {
+		o.Prefix = p
+	}
+}
+
+// ListLimit limits the number of returned objects to l
+func ListLimit(l uint) ListOption {
+	return func(o *ListOptions) {
+		o.Limit = l
+	}
+}
+
+// URLOptions configures a presigned url
+type URLOptions struct {
+	// Method the url can be used with, GET to download or PUT to upload
+	Method string
+	// Expiry is how long the url is valid for
+	Expiry time.Duration
+	// ContentType the client must upload with when the method is PUT
+	ContentType string
+}
+
+// URLOption sets values in URLOptions
+type URLOption func(o *URLOptions)
+
+// URLMethod sets the method the url can be used with, defaults to GET
+func URLMethod(m string) URLOption {
+	return func(o *URLOptions) {
+		o.Method = m
+	}
+}
+
+// URLExpiry sets how long the url is valid for, defaults to DefaultURLExpiry
+func URLExpiry(d time.Duration) URLOption {
+	return func(o *URLOptions) {
+		o.Expiry = d
+	}
+}
+
+// URLContentType sets the content type the client must upload with
+func URLContentType(ct string) URLOption {
+	return func(o *URLOptions) {
+		o.ContentType = ct
+	}
+}
+
+// NewURLOptions returns the url options with the defaults set
+func NewURLOptions(opts ...URLOption) URLOptions {
+	options := URLOptions{
+		Method: http.MethodGet,
+		Expiry: DefaultURLExpiry,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
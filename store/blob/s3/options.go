@@ -0,0 +1,48 @@
+package s3
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/store/blob"
+)
+
+type regionKey struct{}
+
+type endpointKey struct{}
+
+type credentialsKey struct{}
+
+type credentials struct {
+	id, secret string
+}
+
+// Region of the bucket, defaults to the AWS_REGION environment variable
+func Region(r string) blob.Option {
+	return func(o *blob.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, regionKey{}, r)
+	}
+}
+
+// Endpoint sets the url of an s3 compatible service such as minio. Path style
+// addressing is used so the bucket doesn't need to be resolvable in dns.
+func Endpoint(e string) blob.Option {
+	return func(o *blob.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, endpointKey{}, e)
+	}
+}
+
+// Credentials sets the access key, otherwise the default aws credential chain is used
+func Credentials(id, secret string) blob.Option {
+	return func(o *blob.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, credentialsKey{}, &credentials{id, secret})
+	}
+}
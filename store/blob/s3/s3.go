@@ -0,0 +1,257 @@
+// Package s3 is an amazon s3 backed blob store
+package s3
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awscreds "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/store/blob"
+)
+
+var (
+	// DefaultBucket is used if no bucket is provided
+	DefaultBucket = "micro"
+)
+
+type s3Blob struct {
+	sync.RWMutex
+	options  blob.Options
+	client   *s3.S3
+	uploader *s3manager.Uploader
+}
+
+// NewBlob returns a blob store backed by s3
+func NewBlob(opts ...blob.Option) blob.Blob {
+	s := &s3Blob{
+		options: blob.Options{
+			Bucket: DefaultBucket,
+		},
+	}
+
+	// best-effort configure the blob store
+	if err := s.configure(opts...); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error("Error configuring blob store ", err)
+		}
+	}
+
+	return s
+}
+
+func (s *s3Blob) configure(opts ...blob.Option) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, o := range opts {
+		o(&s.options)
+	}
+
+	if len(s.options.Bucket) == 0 {
+		s.options.Bucket = DefaultBucket
+	}
+
+	config := aws.NewConfig()
+
+	if ctx := s.options.Context; ctx != nil {
+		if r, ok := ctx.Value(regionKey{}).(string); ok && len(r) > 0 {
+			config = config.WithRegion(r)
+		}
+		if e, ok := ctx.Value(endpointKey{}).(string); ok && len(e) > 0 {
+			config = config.WithEndpoint(e).WithS3ForcePathStyle(true)
+		}
+		if c, ok := ctx.Value(credentialsKey{}).(*credentials); ok {
+			config = config.WithCredentials(awscreds.NewStaticCredentials(c.id, c.secret, ""))
+		}
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return err
+	}
+
+	s.client = s3.New(sess)
+	s.uploader = s3manager.NewUploaderWithClient(s.client)
+
+	return nil
+}
+
+func (s *s3Blob) get() (*s3.S3, *s3manager.Uploader, string) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.client, s.uploader, s.options.Bucket
+}
+
+// isNotFound checks if the error is returned for a missing key
+func isNotFound(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case s3.ErrCodeNoSuchKey, "NotFound":
+			return true
+		}
+	}
+	return false
+}
+
+func (s *s3Blob) Init(opts ...blob.Option) error {
+	return s.configure(opts...)
+}
+
+func (s *s3Blob) Options() blob.Options {
+	s.RLock()
+	defer s.RUnlock()
+	return s.options
+}
+
+// Put uploads the object, large objects are uploaded in parts so the reader is never buffered in full
+func (s *s3Blob) Put(key string, r io.Reader, opts ...blob.PutOption) error {
+	if len(key) == 0 {
+		return blob.ErrInvalidKey
+	}
+
+	options := blob.NewPutOptions(opts...)
+	_, uploader, bucket := s.get()
+
+	_, err := uploader.Upload(&s3manager.UploadInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        r,
+		ContentType: aws.String(options.ContentType),
+	})
+	return err
+}
+
+func (s *s3Blob) Get(key string) (io.ReadCloser, *blob.Object, error) {
+	if len(key) == 0 {
+		return nil, nil, blob.ErrInvalidKey
+	}
+
+	client, _, bucket := s.get()
+
+	rsp, err := client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if isNotFound(err) {
+		return nil, nil, blob.ErrNotFound
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	return rsp.Body, &blob.Object{
+		Key:         key,
+		Size:        aws.Int64Value(rsp.ContentLength),
+		ContentType: aws.StringValue(rsp.ContentType),
+		Updated:     aws.TimeValue(rsp.LastModified),
+	}, nil
+}
+
+func (s *s3Blob) Delete(key string) error {
+	if len(key) == 0 {
+		return blob.ErrInvalidKey
+	}
+
+	client, _, bucket := s.get()
+
+	_, err := client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// List the objects, the content type isn't returned by s3 when listing
+func (s *s3Blob) List(opts ...blob.ListOption) ([]*blob.Object, error) {
+	var options blob.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	client, _, bucket := s.get()
+
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+	}
+	if len(options.Prefix) > 0 {
+		input.Prefix = aws.String(options.Prefix)
+	}
+	if options.Limit > 0 && options.Limit < 1000 {
+		input.MaxKeys = aws.Int64(int64(options.Limit))
+	}
+
+	var objects []*blob.Object
+
+	err := client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, last bool) bool {
+		for _, obj := range page.Contents {
+			objects = append(objects, &blob.Object{
+				Key:     aws.StringValue(obj.Key),
+				Size:    aws.Int64Value(obj.Size),
+				Updated: aws.TimeValue(obj.LastModified),
+			})
+			if options.Limit > 0 && uint(len(objects)) >= options.Limit {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// URL returns a presigned url for the object using the credentials of the client
+func (s *s3Blob) URL(key string, opts ...blob.URLOption) (string, error) {
+	if len(key) == 0 {
+		return "", blob.ErrInvalidKey
+	}
+
+	options := blob.NewURLOptions(opts...)
+	client, _, bucket := s.get()
+
+	var req interface {
+		Presign(expire time.Duration) (string, error)
+	}
+
+	switch options.Method {
+	case http.MethodGet:
+		req, _ = client.GetObjectRequest(&s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+	case http.MethodPut:
+		input := &s3.PutObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		}
+		if len(options.ContentType) > 0 {
+			input.ContentType = aws.String(options.ContentType)
+		}
+		req, _ = client.PutObjectRequest(input)
+	case http.MethodDelete:
+		req, _ = client.DeleteObjectRequest(&s3.DeleteObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+	default:
+		return "", blob.ErrNotSupported
+	}
+
+	return req.Presign(options.Expiry)
+}
+
+func (s *s3Blob) String() string {
+	return "s3"
+}
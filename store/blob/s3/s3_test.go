@@ -0,0 +1,46 @@
+package s3
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/store/blob"
+)
+
+func TestS3URL(t *testing.T) {
+	b := NewBlob(
+		blob.Bucket("test"),
+		Region("eu-west-1"),
+		Endpoint("http://localhost:9000"),
+		Credentials("id", "secret"),
+	)
+
+	// presigning doesn't make any requests
+	u, err := b.URL("a/b.txt", blob.URLMethod(http.MethodPut), blob.URLExpiry(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := url.Parse(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Host != "localhost:9000" || parsed.Path != "/test/a/b.txt" {
+		t.Fatalf("Unexpected url %s", u)
+	}
+
+	query := parsed.Query()
+	if query.Get("X-Amz-Expires") != "3600" || len(query.Get("X-Amz-Signature")) == 0 {
+		t.Fatalf("Expected a signed url valid for an hour got %s", u)
+	}
+	if !strings.HasPrefix(query.Get("X-Amz-Credential"), "id/") {
+		t.Fatalf("Expected the url to be signed with the credentials got %s", u)
+	}
+
+	if _, err := b.URL("a/b.txt", blob.URLMethod(http.MethodPost)); err != blob.ErrNotSupported {
+		t.Fatalf("Expected %v got %v", blob.ErrNotSupported, err)
+	}
+}
@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"time"
+
 	"github.com/micro/go-micro/v3/store"
 	"github.com/micro/go-micro/v3/store/memory"
 )
@@ -48,18 +50,19 @@ func (c *cache) Options() store.Options {
 }
 
 // Read takes a single key name and optional ReadOptions. It returns matching []*Record or an error.
+// Records written through the cache are read from the backing store the first time so the version is known.
 func (c *cache) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
 	recs, err := c.m.Read(key, opts...)
 	if err != nil && err != store.ErrNotFound {
 		return nil, err
 	}
-	if len(recs) > 0 {
+	if len(recs) > 0 && versioned(recs) {
 		return recs, nil
 	}
 	recs, err = c.b.Read(key, opts...)
 	if err == nil {
 		for _, rec := range recs {
-			if err := c.m.Write(rec); err != nil {
+			if err := c.m.Write(rec, memory.PreserveVersion()); err != nil {
 				return nil, err
 			}
 		}
@@ -67,14 +70,43 @@ func (c *cache) Read(key string, opts ...store.ReadOption) ([]*store.Record, err
 	return recs, err
 }
 
+// versioned checks the version of the cached records is known
+func versioned(recs []*store.Record) bool {
+	for _, rec := range recs {
+		if rec.Version == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// unversioned returns copies of the records without a version to cache the written records,
+// the version is only known by the backing store
+func unversioned(recs []*store.Record) []*store.Record {
+	cp := make([]*store.Record, len(recs))
+	for i, r := range recs {
+		rec := *r
+		rec.Version = 0
+		cp[i] = &rec
+	}
+	return cp
+}
+
+// conditional checks if the write options have conditions the backing store must check
+func conditional(opts []store.WriteOption) bool {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return options.IfMatch || options.IfNotExists
+}
+
 // Write() writes a record to the store, and returns an error if the record was not written.
 // If the write succeeds in writing to memory but fails to write through to file, you'll receive an error
 // but the value may still reside in memory so appropriate action should be taken.
+// Conditional writes are checked by the backing store and the record is removed from memory.
 func (c *cache) Write(r *store.Record, opts ...store.WriteOption) error {
-	if err := c.m.Write(r, opts...); err != nil {
-		return err
-	}
-	return c.b.Write(r, opts...)
+	return c.BatchWrite([]*store.Record{r}, opts...)
 }
 
 // Delete removes the record with the corresponding key from the store.
@@ -87,6 +119,56 @@ func (c *cache) Delete(key string, opts ...store.DeleteOption) error {
 	return c.b.Delete(key, opts...)
 }
 
+// BatchWrite writes the records to memory and then through to the backing store.
+// If the backing store fails the records may still reside in memory.
+// Conditional writes are checked by the backing store and the records are removed from memory.
+func (c *cache) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	if conditional(opts) {
+		if err := c.b.BatchWrite(recs, opts...); err != nil {
+			return err
+		}
+
+		var options store.WriteOptions
+		for _, o := range opts {
+			o(&options)
+		}
+
+		keys := make([]string, len(recs))
+		for i, r := range recs {
+			keys[i] = r.Key
+		}
+		return c.m.BatchDelete(keys, store.DeleteFrom(options.Database, options.Table))
+	}
+
+	if err := c.m.BatchWrite(unversioned(recs), append(opts, memory.PreserveVersion())...); err != nil {
+		return err
+	}
+	return c.b.BatchWrite(recs, opts...)
+}
+
+// BatchDelete removes the records from memory and then from the backing store.
+// If the backing store fails the records may still be removed from memory.
+func (c *cache) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	if err := c.m.BatchDelete(keys, opts...); err != nil {
+		return err
+	}
+	return c.b.BatchDelete(keys, opts...)
+}
+
+// Expire sets the expiry of the record in the backing store and removes it from memory
+// so the new expiry is read through
+func (c *cache) Expire(key string, ttl time.Duration, opts ...store.ExpireOption) error {
+	if err := store.Expire(c.b, key, ttl, opts...); err != nil {
+		return err
+	}
+
+	var options store.ExpireOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return c.m.Delete(key, store.DeleteFrom(options.Database, options.Table))
+}
+
 // List returns any keys that match, or an empty list with no error if none matched.
 func (c *cache) List(opts ...store.ListOption) ([]string, error) {
 	keys, err := c.m.List(opts...)
@@ -104,7 +186,7 @@ func (c *cache) List(opts ...store.ListOption) ([]string, error) {
 				return nil, err
 			}
 			for _, r := range recs {
-				if err := c.m.Write(r); err != nil {
+				if err := c.m.Write(r, memory.PreserveVersion()); err != nil {
 					return nil, err
 				}
 			}
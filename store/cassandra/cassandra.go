@@ -0,0 +1,500 @@
+// Package cassandra implements the cassandra store
+package cassandra
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/store"
+)
+
+// DefaultDatabase and DefaultTable are used if none are provided,
+// DefaultNodes are used if there are no nodes.
+var (
+	DefaultDatabase = "micro"
+	DefaultTable    = "micro"
+	DefaultNodes    = []string{"127.0.0.1"}
+)
+
+var (
+	// ErrMultiplePartitions is returned when a conditional batch write spans partitions,
+	// lightweight transactions are limited to a single partition
+	ErrMultiplePartitions = errors.New("conditional batch writes must be in a single partition")
+
+	re = regexp.MustCompile("[^a-zA-Z0-9]+")
+
+	statements = map[string]string{
+		"read":             "SELECT key, value, metadata, version, TTL(value) FROM %s.%s WHERE partition = ? AND key = ?;",
+		"readPartition":    "SELECT key, value, metadata, version, TTL(value) FROM %s.%s WHERE partition = ? AND key >= ?;",
+		"readAll":          "SELECT key, value, metadata, version, TTL(value) FROM %s.%s;",
+		"version":          "SELECT version FROM %s.%s WHERE partition = ? AND key = ?;",
+		"write":            "INSERT INTO %s.%s (partition, key, value, metadata, version) VALUES (?, ?, ?, ?, ?) USING TTL ?;",
+		"writeIfNotExists": "INSERT INTO %s.%s (partition, key, value, metadata, version) VALUES (?, ?, ?, ?, ?) IF NOT EXISTS USING TTL ?;",
+		"writeIfMatch":     "UPDATE %s.%s USING TTL ? SET value = ?, metadata = ?, version = ? WHERE partition = ? AND key = ? IF version = ?;",
+		"delete":           "DELETE FROM %s.%s WHERE partition = ? AND key = ?;",
+	}
+)
+
+type cassandraStore struct {
+	options store.Options
+	session *gocql.Session
+
+	// the replication factor of the keyspaces and length of the key prefix in the partition key
+	replication int
+	length      int
+
+	sync.RWMutex
+	// known tables
+	tables map[string]bool
+}
+
+// NewStore returns a cassandra store, the nodes are the hosts of the cluster. Each database
+// is a keyspace and each table is a table of the keyspace, they're created if they don't exist.
+func NewStore(opts ...store.Option) store.Store {
+	options := store.Options{
+		Database: DefaultDatabase,
+		Table:    DefaultTable,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	s := &cassandraStore{
+		options: options,
+		tables:  make(map[string]bool),
+	}
+
+	// best-effort configure the store
+	if err := s.configure(); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error("Error configuring store ", err)
+		}
+	}
+
+	return s
+}
+
+func (s *cassandraStore) configure() error {
+	nodes := s.options.Nodes
+	if len(nodes) == 0 {
+		nodes = DefaultNodes
+	}
+
+	cluster := gocql.NewCluster(nodes...)
+	cluster.Consistency = gocql.Quorum
+
+	s.replication = 1
+	s.length = 0
+
+	if ctx := s.options.Context; ctx != nil {
+		if c, ok := ctx.Value(credentialsKey{}).(*credentials); ok {
+			cluster.Authenticator = gocql.PasswordAuthenticator{
+				Username: c.username,
+				Password: c.password,
+			}
+		}
+		if n, ok := ctx.Value(replicationKey{}).(int); ok && n > 0 {
+			s.replication = n
+		}
+		if n, ok := ctx.Value(partitionKey{}).(int); ok && n > 0 {
+			s.length = n
+		}
+	}
+
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return err
+	}
+
+	if s.session != nil {
+		s.session.Close()
+	}
+	s.session = session
+
+	s.Lock()
+	s.tables = make(map[string]bool)
+	s.Unlock()
+
+	return s.createTable(s.options.Database, s.options.Table)
+}
+
+func (s *cassandraStore) getDB(database, table string) (string, string) {
+	if len(database) == 0 {
+		database = s.options.Database
+	}
+	if len(table) == 0 {
+		table = s.options.Table
+	}
+
+	// keyspaces and tables must only contain letters, numbers and underscores
+	database = re.ReplaceAllString(database, "_")
+	table = re.ReplaceAllString(table, "_")
+
+	return database, table
+}
+
+// createTable creates the keyspace and table if they don't exist
+func (s *cassandraStore) createTable(database, table string) error {
+	if s.session == nil {
+		return errors.New("Session not initialised")
+	}
+
+	database, table = s.getDB(database, table)
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.tables[database+":"+table] {
+		return nil
+	}
+
+	err := s.session.Query(fmt.Sprintf(
+		"CREATE KEYSPACE IF NOT EXISTS %s WITH replication = {'class': 'SimpleStrategy', 'replication_factor': %d};",
+		database, s.replication)).Exec()
+	if err != nil {
+		return err
+	}
+
+	err = s.session.Query(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+		partition text,
+		key text,
+		value blob,
+		metadata text,
+		version bigint,
+		PRIMARY KEY ((partition), key)
+	);`, database, table)).Exec()
+	if err != nil {
+		return err
+	}
+
+	s.tables[database+":"+table] = true
+	return nil
+}
+
+// statement returns the query for the database and table
+func (s *cassandraStore) statement(database, table, query string) string {
+	database, table = s.getDB(database, table)
+	return fmt.Sprintf(statements[query], database, table)
+}
+
+// partition returns the partition key of the key
+func (s *cassandraStore) partition(key string) string {
+	if len(key) > s.length {
+		return key[:s.length]
+	}
+	return key
+}
+
+// ttl returns the expiry in seconds, rounded up so records don't expire early
+func ttl(expiry time.Duration) int {
+	if expiry <= 0 {
+		return 0
+	}
+	return int((expiry + time.Second - 1) / time.Second)
+}
+
+// scan the records of the iterator
+func scan(iter *gocql.Iter) ([]*store.Record, error) {
+	var records []*store.Record
+
+	var key, metadata string
+	var value []byte
+	var version int64
+	var expiry *int
+
+	for iter.Scan(&key, &value, &metadata, &version, &expiry) {
+		rec := &store.Record{
+			Key:      key,
+			Value:    value,
+			Metadata: make(map[string]interface{}),
+			Version:  uint64(version),
+		}
+		if len(metadata) > 0 {
+			if err := json.Unmarshal([]byte(metadata), &rec.Metadata); err != nil {
+				iter.Close()
+				return nil, err
+			}
+		}
+		if expiry != nil && *expiry > 0 {
+			rec.Expiry = time.Duration(*expiry) * time.Second
+		}
+		records = append(records, rec)
+
+		// scan allocates new values for each row
+		value = nil
+		expiry = nil
+	}
+
+	return records, iter.Close()
+}
+
+// list returns the sorted records matching the filters. A single partition is read if the
+// prefix determines it otherwise the table is scanned.
+func (s *cassandraStore) list(database, table, prefix, suffix string, limit, offset uint) ([]*store.Record, error) {
+	var iter *gocql.Iter
+
+	if s.length == 0 || len(prefix) >= s.length {
+		iter = s.session.Query(s.statement(database, table, "readPartition"), s.partition(prefix), prefix).Iter()
+	} else {
+		iter = s.session.Query(s.statement(database, table, "readAll")).Iter()
+	}
+
+	recs, err := scan(iter)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*store.Record, 0, len(recs))
+	for _, r := range recs {
+		if !strings.HasPrefix(r.Key, prefix) || !strings.HasSuffix(r.Key, suffix) {
+			continue
+		}
+		records = append(records, r)
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].Key < records[j].Key })
+
+	return store.Paginate(records, limit, offset), nil
+}
+
+// version returns the current version of the key, zero if it doesn't exist
+func (s *cassandraStore) version(database, table, key string) (int64, error) {
+	var version int64
+	err := s.session.Query(s.statement(database, table, "version"), s.partition(key), key).Scan(&version)
+	if err == gocql.ErrNotFound {
+		return 0, nil
+	}
+	return version, err
+}
+
+// write returns the statement and arguments to write the record
+func (s *cassandraStore) write(r *store.Record, options store.WriteOptions) (string, []interface{}, error) {
+	md, err := json.Marshal(r.Metadata)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch {
+	case options.IfNotExists, options.IfMatch && r.Version == 0:
+		return s.statement(options.Database, options.Table, "writeIfNotExists"),
+			[]interface{}{s.partition(r.Key), r.Key, r.Value, string(md), int64(1), ttl(r.Expiry)}, nil
+	case options.IfMatch:
+		return s.statement(options.Database, options.Table, "writeIfMatch"),
+			[]interface{}{ttl(r.Expiry), r.Value, string(md), int64(r.Version + 1), s.partition(r.Key), r.Key, int64(r.Version)}, nil
+	}
+
+	// unconditional writes increment the version they read
+	version, err := s.version(options.Database, options.Table, r.Key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return s.statement(options.Database, options.Table, "write"),
+		[]interface{}{s.partition(r.Key), r.Key, r.Value, string(md), version + 1, ttl(r.Expiry)}, nil
+}
+
+func (s *cassandraStore) Init(opts ...store.Option) error {
+	for _, o := range opts {
+		o(&s.options)
+	}
+	// reconfigure
+	return s.configure()
+}
+
+func (s *cassandraStore) Options() store.Options {
+	return s.options
+}
+
+func (s *cassandraStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the table if not exists
+	if err := s.createTable(options.Database, options.Table); err != nil {
+		return nil, err
+	}
+
+	if options.Prefix || options.Suffix {
+		var prefix, suffix string
+		if options.Prefix {
+			prefix = key
+		}
+		if options.Suffix {
+			suffix = key
+		}
+		return s.list(options.Database, options.Table, prefix, suffix, options.Limit, options.Offset)
+	}
+
+	recs, err := scan(s.session.Query(s.statement(options.Database, options.Table, "read"), s.partition(key), key).Iter())
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, store.ErrNotFound
+	}
+	return recs, nil
+}
+
+// Write the record, unconditional writes aren't lightweight transactions so the version
+// may not be incremented by concurrent writes
+func (s *cassandraStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the table if not exists
+	if err := s.createTable(options.Database, options.Table); err != nil {
+		return err
+	}
+
+	stmt, args, err := s.write(r, options)
+	if err != nil {
+		return err
+	}
+
+	if !options.IfMatch && !options.IfNotExists {
+		return s.session.Query(stmt, args...).Exec()
+	}
+
+	applied, err := s.session.Query(stmt, args...).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return store.ErrConflict
+	}
+	return nil
+}
+
+func (s *cassandraStore) Delete(key string, opts ...store.DeleteOption) error {
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the table if not exists
+	if err := s.createTable(options.Database, options.Table); err != nil {
+		return err
+	}
+
+	return s.session.Query(s.statement(options.Database, options.Table, "delete"), s.partition(key), key).Exec()
+}
+
+// BatchWrite writes the records in a logged batch, conditional writes are a lightweight
+// transaction which must be in a single partition
+func (s *cassandraStore) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	if len(recs) == 0 {
+		return nil
+	}
+
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the table if not exists
+	if err := s.createTable(options.Database, options.Table); err != nil {
+		return err
+	}
+
+	conditional := options.IfMatch || options.IfNotExists
+	batch := s.session.NewBatch(gocql.LoggedBatch)
+
+	for _, r := range recs {
+		if conditional && s.partition(r.Key) != s.partition(recs[0].Key) {
+			return ErrMultiplePartitions
+		}
+
+		stmt, args, err := s.write(r, options)
+		if err != nil {
+			return err
+		}
+		batch.Query(stmt, args...)
+	}
+
+	if !conditional {
+		return s.session.ExecuteBatch(batch)
+	}
+
+	applied, iter, err := s.session.MapExecuteBatchCAS(batch, map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if iter != nil {
+		iter.Close()
+	}
+	if !applied {
+		return store.ErrConflict
+	}
+	return nil
+}
+
+// BatchDelete deletes the keys in a logged batch
+func (s *cassandraStore) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the table if not exists
+	if err := s.createTable(options.Database, options.Table); err != nil {
+		return err
+	}
+
+	batch := s.session.NewBatch(gocql.LoggedBatch)
+	for _, key := range keys {
+		batch.Query(s.statement(options.Database, options.Table, "delete"), s.partition(key), key)
+	}
+
+	return s.session.ExecuteBatch(batch)
+}
+
+func (s *cassandraStore) List(opts ...store.ListOption) ([]string, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the table if not exists
+	if err := s.createTable(options.Database, options.Table); err != nil {
+		return nil, err
+	}
+
+	recs, err := s.list(options.Database, options.Table, options.Prefix, options.Suffix, options.Limit, options.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(recs))
+	for i, r := range recs {
+		keys[i] = r.Key
+	}
+	return keys, nil
+}
+
+func (s *cassandraStore) Close() error {
+	if s.session != nil {
+		s.session.Close()
+	}
+	return nil
+}
+
+func (s *cassandraStore) String() string {
+	return "cassandra"
+}
@@ -0,0 +1,56 @@
+package cassandra
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPartition(t *testing.T) {
+	testCases := []struct {
+		length    int
+		key       string
+		partition string
+	}{
+		{0, "foo", ""},
+		{2, "foo", "fo"},
+		{3, "foo", "foo"},
+		{5, "foo", "foo"},
+	}
+
+	for _, tc := range testCases {
+		s := &cassandraStore{length: tc.length}
+		if p := s.partition(tc.key); p != tc.partition {
+			t.Errorf("Expected partition %q for key %q with length %d, got %q", tc.partition, tc.key, tc.length, p)
+		}
+	}
+}
+
+func TestTTL(t *testing.T) {
+	testCases := []struct {
+		expiry time.Duration
+		ttl    int
+	}{
+		{0, 0},
+		{-time.Second, 0},
+		{time.Millisecond, 1},
+		{time.Second, 1},
+		{1500 * time.Millisecond, 2},
+	}
+
+	for _, tc := range testCases {
+		if v := ttl(tc.expiry); v != tc.ttl {
+			t.Errorf("Expected ttl %d for expiry %v, got %d", tc.ttl, tc.expiry, v)
+		}
+	}
+}
+
+func TestStatement(t *testing.T) {
+	s := &cassandraStore{}
+	s.options.Database = "default"
+	s.options.Table = "micro"
+
+	stmt := s.statement("my-db", "", "delete")
+	if stmt != "DELETE FROM my_db.micro WHERE partition = ? AND key = ?;" {
+		t.Fatalf("Unexpected statement %q", stmt)
+	}
+}
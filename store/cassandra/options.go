@@ -0,0 +1,51 @@
+package cassandra
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+type credentialsKey struct{}
+
+type replicationKey struct{}
+
+type partitionKey struct{}
+
+type credentials struct {
+	username, password string
+}
+
+// Credentials to authenticate with
+func Credentials(username, password string) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, credentialsKey{}, &credentials{username, password})
+	}
+}
+
+// ReplicationFactor of the keyspaces created for the databases, defaults to 1
+func ReplicationFactor(n int) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, replicationKey{}, n)
+	}
+}
+
+// PartitionPrefixLength sets how many characters of the key are the partition key. By default
+// each table is a single partition, which is efficient to read by prefix but limits the size of
+// the table to that of a partition. Including a prefix of the key spreads the records across
+// partitions, prefix reads of at least n characters still read a single partition while shorter
+// prefixes scan the table. The length of a table can't be changed once records are written.
+func PartitionPrefixLength(n int) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, partitionKey{}, n)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -27,12 +28,18 @@ var (
 	re = regexp.MustCompile("[^a-zA-Z0-9]+")
 
 	statements = map[string]string{
-		"list":       "SELECT key, value, metadata, expiry FROM %s.%s WHERE key LIKE $1 ORDER BY key DESC LIMIT $2 OFFSET $3;",
-		"read":       "SELECT key, value, metadata, expiry FROM %s.%s WHERE key = $1;",
-		"readMany":   "SELECT key, value, metadata, expiry FROM %s.%s WHERE key LIKE $1;",
-		"readOffset": "SELECT key, value, metadata, expiry FROM %s.%s WHERE key LIKE $1 ORDER BY key DESC LIMIT $2 OFFSET $3;",
-		"write":      "INSERT INTO %s.%s(key, value, metadata, expiry) VALUES ($1, $2::bytea, $3, $4) ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, metadata = EXCLUDED.metadata, expiry = EXCLUDED.expiry;",
-		"delete":     "DELETE FROM %s.%s WHERE key = $1;",
+		"list":       "SELECT key, value, metadata, expiry, version FROM %s.%s WHERE key LIKE $1 ORDER BY key DESC LIMIT $2 OFFSET $3;",
+		"read":       "SELECT key, value, metadata, expiry, version FROM %s.%s WHERE key = $1;",
+		"readMany":   "SELECT key, value, metadata, expiry, version FROM %s.%s WHERE key LIKE $1;",
+		"readOffset": "SELECT key, value, metadata, expiry, version FROM %s.%s WHERE key LIKE $1 ORDER BY key DESC LIMIT $2 OFFSET $3;",
+		"write":      "INSERT INTO %s.%s AS t(key, value, metadata, expiry, version) VALUES ($1, $2::bytea, $3, $4, 1) ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, metadata = EXCLUDED.metadata, expiry = EXCLUDED.expiry, version = t.version + 1;",
+		// only replace an existing record if it has expired
+		"writeIfNotExists": "INSERT INTO %s.%s AS t(key, value, metadata, expiry, version) VALUES ($1, $2::bytea, $3, $4, 1) ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, metadata = EXCLUDED.metadata, expiry = EXCLUDED.expiry, version = t.version + 1 WHERE t.expiry IS NOT NULL AND t.expiry < now();",
+		"writeIfMatch":     "UPDATE %s.%s SET value = $2::bytea, metadata = $3, expiry = $4, version = version + 1 WHERE key = $1 AND version = $5 AND (expiry IS NULL OR expiry > now());",
+		"delete":           "DELETE FROM %s.%s WHERE key = $1;",
+		"expire":           "UPDATE %s.%s SET expiry = $2 WHERE key = $1 AND (expiry IS NULL OR expiry > now());",
+		// an expired counter starts again from zero
+		"increment": "INSERT INTO %s.%s AS t(key, value, metadata, expiry, version) VALUES ($1, convert_to($2::INT8::STRING, 'UTF8'), $3, NULL, 1) ON CONFLICT (key) DO UPDATE SET value = CASE WHEN t.expiry IS NOT NULL AND t.expiry < now() THEN EXCLUDED.value ELSE convert_to((convert_from(t.value, 'UTF8')::INT8 + $2::INT8)::STRING, 'UTF8') END, expiry = CASE WHEN t.expiry IS NOT NULL AND t.expiry < now() THEN NULL ELSE t.expiry END, version = t.version + 1 RETURNING value;",
 	}
 )
 
@@ -105,12 +112,19 @@ func (s *sqlStore) initDB(database, table string) error {
 		value bytea,
 		metadata JSONB,
 		expiry timestamp with time zone,
+		version INT NOT NULL DEFAULT 0,
 		CONSTRAINT %s_pkey PRIMARY KEY (key)
 	);`, database, table, table))
 	if err != nil {
 		return errors.Wrap(err, "Couldn't create table")
 	}
 
+	// Add the version to tables created before records were versioned
+	_, err = s.db.Exec(fmt.Sprintf(`ALTER TABLE %s.%s ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 0;`, database, table))
+	if err != nil {
+		return errors.Wrap(err, "Couldn't add version column")
+	}
+
 	// Create Index
 	_, err = s.db.Exec(fmt.Sprintf(`CREATE INDEX IF NOT EXISTS "%s" ON %s.%s USING btree ("key");`, "key_index_"+table, database, table))
 	if err != nil {
@@ -165,16 +179,28 @@ func (s *sqlStore) configure() error {
 	return s.initDB(database, table)
 }
 
-func (s *sqlStore) prepare(database, table, query string) (*sql.Stmt, error) {
+// preparer prepares the statements of the store or a transaction
+type preparer func(database, table, query string) (*sql.Stmt, error)
+
+// statement returns the query for the database and table
+func (s *sqlStore) statement(database, table, query string) (string, error) {
 	st, ok := statements[query]
 	if !ok {
-		return nil, errors.New("unsupported statement")
+		return "", errors.New("unsupported statement")
 	}
 
 	// get DB
 	database, table = s.getDB(database, table)
 
-	q := fmt.Sprintf(st, database, table)
+	return fmt.Sprintf(st, database, table), nil
+}
+
+func (s *sqlStore) prepare(database, table, query string) (*sql.Stmt, error) {
+	q, err := s.statement(database, table, query)
+	if err != nil {
+		return nil, err
+	}
+
 	stmt, err := s.db.Prepare(q)
 	if err != nil {
 		return nil, err
@@ -265,7 +291,7 @@ func (s *sqlStore) rowToRecord(row *sql.Row) (*store.Record, error) {
 	record := &store.Record{}
 	metadata := make(Metadata)
 
-	if err := row.Scan(&record.Key, &record.Value, &metadata, &timehelper); err != nil {
+	if err := row.Scan(&record.Key, &record.Value, &metadata, &timehelper, &record.Version); err != nil {
 		if err == sql.ErrNoRows {
 			return record, store.ErrNotFound
 		}
@@ -295,7 +321,7 @@ func (s *sqlStore) rowsToRecords(rows *sql.Rows) ([]*store.Record, error) {
 		record := &store.Record{}
 		metadata := make(Metadata)
 
-		if err := rows.Scan(&record.Key, &record.Value, &metadata, &timehelper); err != nil {
+		if err := rows.Scan(&record.Key, &record.Value, &metadata, &timehelper, &record.Version); err != nil {
 			return records, err
 		}
 
@@ -329,11 +355,16 @@ func (s *sqlStore) Read(key string, opts ...store.ReadOption) ([]*store.Record,
 		return nil, err
 	}
 
+	return s.get(s.prepare, key, options)
+}
+
+// get reads a single key or many records if the prefix or suffix options are set
+func (s *sqlStore) get(prepare preparer, key string, options store.ReadOptions) ([]*store.Record, error) {
 	if options.Prefix || options.Suffix {
-		return s.read(key, options)
+		return s.read(prepare, key, options)
 	}
 
-	st, err := s.prepare(options.Database, options.Table, "read")
+	st, err := prepare(options.Database, options.Table, "read")
 	if err != nil {
 		return nil, err
 	}
@@ -349,7 +380,7 @@ func (s *sqlStore) Read(key string, opts ...store.ReadOption) ([]*store.Record,
 }
 
 // Read Many records
-func (s *sqlStore) read(key string, options store.ReadOptions) ([]*store.Record, error) {
+func (s *sqlStore) read(prepare preparer, key string, options store.ReadOptions) ([]*store.Record, error) {
 	pattern := "%"
 	if options.Prefix {
 		pattern = key + pattern
@@ -363,7 +394,7 @@ func (s *sqlStore) read(key string, options store.ReadOptions) ([]*store.Record,
 	var err error
 
 	if options.Limit != 0 {
-		st, err = s.prepare(options.Database, options.Table, "readOffset")
+		st, err = prepare(options.Database, options.Table, "readOffset")
 		if err != nil {
 			return nil, err
 		}
@@ -371,7 +402,7 @@ func (s *sqlStore) read(key string, options store.ReadOptions) ([]*store.Record,
 
 		rows, err = st.Query(pattern, options.Limit, options.Offset)
 	} else {
-		st, err = s.prepare(options.Database, options.Table, "readMany")
+		st, err = prepare(options.Database, options.Table, "readMany")
 		if err != nil {
 			return nil, err
 		}
@@ -416,7 +447,19 @@ func (s *sqlStore) Write(r *store.Record, opts ...store.WriteOption) error {
 		return err
 	}
 
-	st, err := s.prepare(options.Database, options.Table, "write")
+	return s.write(s.prepare, r, options)
+}
+
+func (s *sqlStore) write(prepare preparer, r *store.Record, options store.WriteOptions) error {
+	query := "write"
+	switch {
+	case options.IfNotExists, options.IfMatch && r.Version == 0:
+		query = "writeIfNotExists"
+	case options.IfMatch:
+		query = "writeIfMatch"
+	}
+
+	st, err := prepare(options.Database, options.Table, query)
 	if err != nil {
 		return err
 	}
@@ -427,21 +470,32 @@ func (s *sqlStore) Write(r *store.Record, opts ...store.WriteOption) error {
 		metadata[k] = v
 	}
 
-	var expiry time.Time
+	var expiry interface{}
 	if r.Expiry != 0 {
 		expiry = time.Now().Add(r.Expiry)
 	}
 
-	if expiry.IsZero() {
-		_, err = st.Exec(r.Key, r.Value, metadata, nil)
-	} else {
-		_, err = st.Exec(r.Key, r.Value, metadata, expiry)
+	args := []interface{}{r.Key, r.Value, metadata, expiry}
+	if query == "writeIfMatch" {
+		args = append(args, r.Version)
 	}
 
+	result, err := st.Exec(args...)
 	if err != nil {
 		return errors.Wrap(err, "Couldn't insert record "+r.Key)
 	}
 
+	// the conditional writes don't change any rows if the condition isn't met
+	if query != "write" {
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return store.ErrConflict
+		}
+	}
+
 	return nil
 }
 
@@ -457,7 +511,11 @@ func (s *sqlStore) Delete(key string, opts ...store.DeleteOption) error {
 		return err
 	}
 
-	st, err := s.prepare(options.Database, options.Table, "delete")
+	return s.delete(s.prepare, key, options)
+}
+
+func (s *sqlStore) delete(prepare preparer, key string, options store.DeleteOptions) error {
+	st, err := prepare(options.Database, options.Table, "delete")
 	if err != nil {
 		return err
 	}
@@ -476,6 +534,112 @@ func (s *sqlStore) Delete(key string, opts ...store.DeleteOption) error {
 	return nil
 }
 
+// BatchWrite writes the records in a transaction
+func (s *sqlStore) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	return store.Transaction(s, func(tx store.Tx) error {
+		for _, r := range recs {
+			if err := tx.Write(r, opts...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// BatchDelete deletes the keys in a transaction
+func (s *sqlStore) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	return store.Transaction(s, func(tx store.Tx) error {
+		for _, key := range keys {
+			if err := tx.Delete(key, opts...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Begin starts a sql transaction
+func (s *sqlStore) Begin() (store.Tx, error) {
+	if s.db == nil {
+		return nil, errors.New("Database connection not initialised")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlTx{store: s, tx: tx}, nil
+}
+
+// Expire sets the expiry of the record without changing the version
+func (s *sqlStore) Expire(key string, ttl time.Duration, opts ...store.ExpireOption) error {
+	var options store.ExpireOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the db if not exists
+	if err := s.createDB(options.Database, options.Table); err != nil {
+		return err
+	}
+
+	st, err := s.prepare(options.Database, options.Table, "expire")
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	var expiry interface{}
+	if ttl != 0 {
+		expiry = time.Now().Add(ttl)
+	}
+
+	res, err := st.Exec(key, expiry)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// Increment the counter atomically
+func (s *sqlStore) Increment(key string, delta int64, opts ...store.IncrementOption) (int64, error) {
+	var options store.IncrementOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the db if not exists
+	if err := s.createDB(options.Database, options.Table); err != nil {
+		return 0, err
+	}
+
+	st, err := s.prepare(options.Database, options.Table, "increment")
+	if err != nil {
+		return 0, err
+	}
+	defer st.Close()
+
+	var value []byte
+	if err := st.QueryRow(key, delta, make(Metadata)).Scan(&value); err != nil {
+		// the value of the record can't be cast to an integer
+		if strings.Contains(err.Error(), "could not parse") || strings.Contains(err.Error(), "invalid input syntax") {
+			return 0, store.ErrNotCounter
+		}
+		return 0, err
+	}
+
+	return strconv.ParseInt(string(value), 10, 64)
+}
+
 func (s *sqlStore) Options() store.Options {
 	return s.options
 }
@@ -0,0 +1,115 @@
+package cockroach
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/micro/go-micro/v3/store"
+)
+
+// expression returns the sql expression of the indexed field as jsonb. The values of
+// the table must be json to query an index of a json path.
+func expression(idx store.Index) string {
+	if path, ok := idx.Path(); ok {
+		return fmt.Sprintf("(convert_from(value, 'UTF8')::JSONB #> %s)", pq.QuoteLiteral("{"+strings.Join(path, ",")+"}"))
+	}
+	return fmt.Sprintf("(metadata->%s)", pq.QuoteLiteral(idx.Field))
+}
+
+// Query the records by the value of an index. Equality queries of metadata fields use the
+// inverted index of the metadata, other queries are filtered by the database.
+func (s *sqlStore) Query(index string, opts ...store.QueryOption) ([]*store.Record, error) {
+	idx, ok := s.options.Index(index)
+	if !ok {
+		return nil, store.ErrUnknownIndex
+	}
+
+	var options store.QueryOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the db if not exists
+	if err := s.createDB(options.Database, options.Table); err != nil {
+		return nil, err
+	}
+
+	database, table := s.getDB(options.Database, options.Table)
+	expr := expression(idx)
+
+	conds := []string{"(expiry IS NULL OR expiry > now())"}
+	var args []interface{}
+
+	// arg adds the value as a jsonb argument of the query
+	arg := func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, string(b))
+		return fmt.Sprintf("$%d::JSONB", len(args)), nil
+	}
+
+	// only values which can be indexed can be queried
+	for _, v := range []interface{}{options.Equals, options.Min, options.Max} {
+		if _, err := store.EncodeValue(v); v != nil && err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Equals != nil {
+		if _, isPath := idx.Path(); !isPath {
+			p, err := arg(map[string]interface{}{idx.Field: options.Equals})
+			if err != nil {
+				return nil, err
+			}
+			conds = append(conds, "metadata @> "+p)
+		} else {
+			p, err := arg(options.Equals)
+			if err != nil {
+				return nil, err
+			}
+			conds = append(conds, expr+" = "+p)
+		}
+	}
+	if options.Min != nil {
+		p, err := arg(options.Min)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, expr+" >= "+p)
+	}
+	if options.Max != nil {
+		p, err := arg(options.Max)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, expr+" < "+p)
+	}
+
+	q := fmt.Sprintf("SELECT key, value, metadata, expiry, version FROM %s.%s WHERE %s ORDER BY %s, key",
+		database, table, strings.Join(conds, " AND "), expr)
+	if options.Limit > 0 {
+		q += fmt.Sprintf(" LIMIT %d", options.Limit)
+	}
+	if options.Offset > 0 {
+		q += fmt.Sprintf(" OFFSET %d", options.Offset)
+	}
+
+	rows, err := s.db.Query(q+";", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records, err := s.rowsToRecords(rows)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []*store.Record{}
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,81 @@
+package cockroach
+
+import (
+	"database/sql"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+// sqlTx is a transaction using the same statements as the store
+type sqlTx struct {
+	store *sqlStore
+	tx    *sql.Tx
+}
+
+func (t *sqlTx) prepare(database, table, query string) (*sql.Stmt, error) {
+	q, err := t.store.statement(database, table, query)
+	if err != nil {
+		return nil, err
+	}
+	return t.tx.Prepare(q)
+}
+
+func (t *sqlTx) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the db if not exists
+	if err := t.store.createDB(options.Database, options.Table); err != nil {
+		return nil, err
+	}
+
+	return t.store.get(t.prepare, key, options)
+}
+
+func (t *sqlTx) Write(r *store.Record, opts ...store.WriteOption) error {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the db if not exists
+	if err := t.store.createDB(options.Database, options.Table); err != nil {
+		return err
+	}
+
+	return t.store.write(t.prepare, r, options)
+}
+
+func (t *sqlTx) Delete(key string, opts ...store.DeleteOption) error {
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the db if not exists
+	if err := t.store.createDB(options.Database, options.Table); err != nil {
+		return err
+	}
+
+	return t.store.delete(t.prepare, key, options)
+}
+
+func (t *sqlTx) Commit() error {
+	if err := t.tx.Commit(); err == sql.ErrTxDone {
+		return store.ErrTxDone
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *sqlTx) Rollback() error {
+	if err := t.tx.Rollback(); err == sql.ErrTxDone {
+		return store.ErrTxDone
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
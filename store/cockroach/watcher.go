@@ -0,0 +1,163 @@
+package cockroach
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+// change is the value of a changefeed row with the updated and diff options
+type change struct {
+	After  *column `json:"after"`
+	Before *column `json:"before"`
+}
+
+// column is a row of the table encoded as json by the changefeed
+type column struct {
+	Key      string                 `json:"key"`
+	Value    string                 `json:"value"`
+	Metadata map[string]interface{} `json:"metadata"`
+	Expiry   *string                `json:"expiry"`
+	Version  uint64                 `json:"version"`
+}
+
+type sqlWatcher struct {
+	cancel context.CancelFunc
+	events chan *store.Event
+	// the reason the changefeed stopped, set before events is closed
+	err error
+}
+
+// decodeBytes decodes a bytea encoded as json, which is hex with a \x prefix
+func decodeBytes(v string) []byte {
+	if strings.HasPrefix(v, `\x`) {
+		if b, err := hex.DecodeString(v[2:]); err == nil {
+			return b
+		}
+	}
+	return []byte(v)
+}
+
+// record converts the changed row to a record
+func (c *column) record() *store.Record {
+	rec := &store.Record{
+		Key:      c.Key,
+		Value:    decodeBytes(c.Value),
+		Metadata: c.Metadata,
+		Version:  c.Version,
+	}
+	if rec.Metadata == nil {
+		rec.Metadata = make(map[string]interface{})
+	}
+	if c.Expiry != nil {
+		if t, err := time.Parse(time.RFC3339Nano, *c.Expiry); err == nil {
+			rec.Expiry = time.Until(t)
+		}
+	}
+	return rec
+}
+
+// Watch the table using a core changefeed, rangefeeds must be enabled by setting the cluster
+// setting kv.rangefeed.enabled to true. Records expire lazily so they're deleted when read
+// after their expiry.
+func (s *sqlStore) Watch(opts ...store.WatchOption) (store.Watcher, error) {
+	var options store.WatchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// create the db if not exists
+	if err := s.createDB(options.Database, options.Table); err != nil {
+		return nil, err
+	}
+
+	database, table := s.getDB(options.Database, options.Table)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// changefeeds can't be prepared
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("EXPERIMENTAL CHANGEFEED FOR %s.%s WITH updated, diff;", database, table))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	w := &sqlWatcher{
+		cancel: cancel,
+		events: make(chan *store.Event),
+		err:    store.ErrWatcherStopped,
+	}
+
+	go func() {
+		defer close(w.events)
+		defer rows.Close()
+
+		for rows.Next() {
+			var tbl string
+			var key, value []byte
+
+			if err := rows.Scan(&tbl, &key, &value); err != nil {
+				w.err = err
+				return
+			}
+
+			var c change
+			if err := json.Unmarshal(value, &c); err != nil {
+				w.err = err
+				return
+			}
+
+			ev := &store.Event{Timestamp: time.Now()}
+
+			switch {
+			case c.After == nil && c.Before == nil:
+				continue
+			case c.After == nil:
+				ev.Type = store.Delete
+				ev.Record = &store.Record{Key: c.Before.Key}
+			case c.Before == nil:
+				ev.Type = store.Create
+				ev.Record = c.After.record()
+			default:
+				ev.Type = store.Update
+				ev.Record = c.After.record()
+			}
+
+			// changefeeds can't be filtered so the prefix is matched here
+			if !strings.HasPrefix(ev.Record.Key, options.Prefix) {
+				continue
+			}
+
+			select {
+			case w.events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		// the error is the cancelled context when stopped
+		if err := rows.Err(); err != nil && ctx.Err() == nil {
+			w.err = err
+		}
+	}()
+
+	return w, nil
+}
+
+// Next returns the next change, the error the changefeed failed with is returned once it stops
+func (w *sqlWatcher) Next() (*store.Event, error) {
+	ev, ok := <-w.events
+	if !ok {
+		return nil, w.err
+	}
+	return ev, nil
+}
+
+func (w *sqlWatcher) Stop() {
+	w.cancel()
+}
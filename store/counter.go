@@ -0,0 +1,127 @@
+package store
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+)
+
+var (
+	// ErrNotCounter is returned when incrementing a record the value of which isn't an integer
+	ErrNotCounter = errors.New("value is not a counter")
+	// DefaultIncrementRetries is how many times stores which aren't Counters retry an increment
+	// when the record is written in the meantime
+	DefaultIncrementRetries = 10
+)
+
+// Counter is implemented by stores which natively increment the value of a record atomically.
+// The value of a counter is its decimal representation so it can be read like any other record.
+type Counter interface {
+	// Increment adds the delta to the counter and returns its new value, a counter which
+	// doesn't exist starts at zero. ErrNotCounter is returned if the value isn't an integer.
+	Increment(key string, delta int64, opts ...IncrementOption) (int64, error)
+}
+
+// Increment adds the delta to the counter and returns its new value, a counter which doesn't
+// exist starts at zero. Stores which aren't Counters read the record and write it if it hasn't
+// changed, ErrConflict is returned if it keeps being written in the meantime.
+func Increment(s Store, key string, delta int64, opts ...IncrementOption) (int64, error) {
+	if c, ok := s.(Counter); ok {
+		return c.Increment(key, delta, opts...)
+	}
+
+	var options IncrementOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	for i := 0; i < DefaultIncrementRetries; i++ {
+		recs, err := s.Read(key, ReadFrom(options.Database, options.Table))
+		if err != nil && err != ErrNotFound {
+			return 0, err
+		}
+
+		// a new counter must not exist and an existing one must not have changed,
+		// which requires the version to be known
+		rec := &Record{Key: key}
+		wopts := []WriteOption{WriteTo(options.Database, options.Table), WriteIfNotExists()}
+
+		var value int64
+		if len(recs) > 0 {
+			rec = recs[0]
+			if value, err = strconv.ParseInt(string(rec.Value), 10, 64); err != nil {
+				return 0, ErrNotCounter
+			}
+
+			wopts = wopts[:1]
+			if rec.Version > 0 {
+				wopts = append(wopts, WriteIfMatch())
+			}
+		}
+
+		value += delta
+		rec.Value = []byte(strconv.FormatInt(value, 10))
+
+		err = s.Write(rec, wopts...)
+		if err == ErrConflict {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+
+		return value, nil
+	}
+
+	return 0, ErrConflict
+}
+
+// Decrement subtracts the delta from the counter and returns its new value
+func Decrement(s Store, key string, delta int64, opts ...IncrementOption) (int64, error) {
+	return Increment(s, key, -delta, opts...)
+}
+
+// Sequence allocates monotonically increasing ids from a counter, e.g for order numbers. The ids
+// are reserved in blocks so the store is only written once per block. The ids are unique across
+// the sequences of the same counter but each allocates from its own block, so they're only in
+// order within a sequence and the unused ids of a block are skipped when it's discarded.
+type Sequence struct {
+	store Store
+	key   string
+	block int64
+	opts  []IncrementOption
+
+	sync.Mutex
+	// next id and the last id of the block
+	next, last int64
+}
+
+// NewSequence returns a sequence allocating ids from the counter in blocks of the size
+func NewSequence(s Store, key string, block int64, opts ...IncrementOption) *Sequence {
+	if block < 1 {
+		block = 1
+	}
+	return &Sequence{
+		store: s,
+		key:   key,
+		block: block,
+		opts:  opts,
+	}
+}
+
+// Next returns the next id, the first id of a counter which doesn't exist is 1
+func (q *Sequence) Next() (int64, error) {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.next == 0 || q.next > q.last {
+		last, err := Increment(q.store, q.key, q.block, q.opts...)
+		if err != nil {
+			return 0, err
+		}
+		q.next, q.last = last-q.block+1, last
+	}
+
+	id := q.next
+	q.next++
+	return id, nil
+}
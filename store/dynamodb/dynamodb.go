@@ -0,0 +1,648 @@
+// Package dynamodb implements the dynamodb store
+package dynamodb
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awscreds "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/store"
+)
+
+// DefaultDatabase and DefaultTable are used if none are provided,
+// DefaultTableName is the dynamodb table used if none is provided.
+var (
+	DefaultDatabase  = "micro"
+	DefaultTable     = "micro"
+	DefaultTableName = "micro"
+)
+
+// the attributes of the items. The partition key is the database and table, followed by
+// a prefix of the key if configured, and the sort key is the key so keys can be queried
+// by prefix. The expiry is in milliseconds, the ttl is in seconds for dynamodb to remove
+// the expired items.
+const (
+	attrPartition = "pk"
+	attrKey       = "sk"
+	attrValue     = "value"
+	attrMetadata  = "metadata"
+	attrVersion   = "version"
+	attrExpiry    = "expiry"
+	attrTTL       = "ttl"
+)
+
+type dynamoStore struct {
+	sync.RWMutex
+	options store.Options
+	client  dynamodbiface.DynamoDBAPI
+
+	// the dynamodb table and the length of the key prefix in the partition key
+	table  string
+	length int
+}
+
+// NewStore returns a dynamodb store. The first node is used as the endpoint if set, e.g
+// for dynamodb local. The table is created if it doesn't exist.
+func NewStore(opts ...store.Option) store.Store {
+	options := store.Options{
+		Database: DefaultDatabase,
+		Table:    DefaultTable,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	d := &dynamoStore{options: options}
+
+	// best-effort configure the store
+	if err := d.configure(); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error("Error configuring store ", err)
+		}
+	}
+
+	return d
+}
+
+func (d *dynamoStore) configure() error {
+	d.Lock()
+	defer d.Unlock()
+
+	config := aws.NewConfig()
+	table := DefaultTableName
+	length := 0
+
+	if len(d.options.Nodes) > 0 {
+		config = config.WithEndpoint(d.options.Nodes[0])
+	}
+
+	if ctx := d.options.Context; ctx != nil {
+		if r, ok := ctx.Value(regionKey{}).(string); ok && len(r) > 0 {
+			config = config.WithRegion(r)
+		}
+		if c, ok := ctx.Value(credentialsKey{}).(*credentials); ok {
+			config = config.WithCredentials(awscreds.NewStaticCredentials(c.id, c.secret, ""))
+		}
+		if t, ok := ctx.Value(tableNameKey{}).(string); ok && len(t) > 0 {
+			table = t
+		}
+		if n, ok := ctx.Value(partitionKey{}).(int); ok && n > 0 {
+			length = n
+		}
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return err
+	}
+
+	d.client = dynamodb.New(sess)
+	d.table = table
+	d.length = length
+
+	return d.createTable()
+}
+
+// createTable creates the table if it doesn't exist and enables the ttl
+func (d *dynamoStore) createTable() error {
+	_, err := d.client.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(d.table)})
+	if err == nil {
+		return nil
+	}
+	if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != dynamodb.ErrCodeResourceNotFoundException {
+		return err
+	}
+
+	_, err = d.client.CreateTable(&dynamodb.CreateTableInput{
+		TableName:   aws.String(d.table),
+		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{AttributeName: aws.String(attrPartition), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
+			{AttributeName: aws.String(attrKey), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{AttributeName: aws.String(attrPartition), KeyType: aws.String(dynamodb.KeyTypeHash)},
+			{AttributeName: aws.String(attrKey), KeyType: aws.String(dynamodb.KeyTypeRange)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := d.client.WaitUntilTableExists(&dynamodb.DescribeTableInput{TableName: aws.String(d.table)}); err != nil {
+		return err
+	}
+
+	_, err = d.client.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(d.table),
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: aws.String(attrTTL),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	return err
+}
+
+func (d *dynamoStore) get() (dynamodbiface.DynamoDBAPI, string, int) {
+	d.RLock()
+	defer d.RUnlock()
+	return d.client, d.table, d.length
+}
+
+// namespace returns the database and table prefix of the partition keys
+func (d *dynamoStore) namespace(database, table string) string {
+	if len(database) == 0 {
+		database = d.options.Database
+	}
+	if len(table) == 0 {
+		table = d.options.Table
+	}
+	return database + "/" + table
+}
+
+// partition returns the partition key of the key in the namespace
+func partition(namespace, key string, length int) string {
+	if length == 0 {
+		return namespace
+	}
+	if len(key) > length {
+		key = key[:length]
+	}
+	return namespace + "/" + key
+}
+
+// itemKey returns the primary key of the item of the key
+func itemKey(namespace, key string, length int) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		attrPartition: {S: aws.String(partition(namespace, key, length))},
+		attrKey:       {S: aws.String(key)},
+	}
+}
+
+func millis(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
+
+// isConflict checks if the error is a failed condition
+func isConflict(err error) bool {
+	switch e := err.(type) {
+	case *dynamodb.TransactionCanceledException:
+		for _, r := range e.CancellationReasons {
+			if aws.StringValue(r.Code) == "ConditionalCheckFailed" {
+				return true
+			}
+		}
+	case awserr.Error:
+		return e.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+	}
+	return false
+}
+
+// update is an update of an item which can be used individually or in a transaction
+type update struct {
+	key        map[string]*dynamodb.AttributeValue
+	expression string
+	condition  string
+	names      map[string]*string
+	values     map[string]*dynamodb.AttributeValue
+}
+
+// newUpdate returns the update writing the record if the conditions of the options are met.
+// The version is incremented by the update so it's atomic.
+func newUpdate(namespace string, length int, r *store.Record, options store.WriteOptions) (*update, error) {
+	md, err := json.Marshal(r.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	u := &update{
+		key: itemKey(namespace, r.Key, length),
+		names: map[string]*string{
+			"#value":    aws.String(attrValue),
+			"#metadata": aws.String(attrMetadata),
+			"#version":  aws.String(attrVersion),
+		},
+		values: map[string]*dynamodb.AttributeValue{
+			":value":    {B: r.Value},
+			":metadata": {S: aws.String(string(md))},
+			":one":      {N: aws.String("1")},
+		},
+	}
+
+	set := "SET #value = :value, #metadata = :metadata"
+	remove := ""
+
+	u.names["#expiry"] = aws.String(attrExpiry)
+	u.names["#ttl"] = aws.String(attrTTL)
+
+	if r.Expiry > 0 {
+		expires := now.Add(r.Expiry)
+		set += ", #expiry = :expiry, #ttl = :ttl"
+		u.values[":expiry"] = &dynamodb.AttributeValue{N: aws.String(millis(expires))}
+		// round up so the item isn't removed before it expires
+		u.values[":ttl"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(expires.Add(time.Second-1).Unix(), 10))}
+	} else {
+		remove = " REMOVE #expiry, #ttl"
+	}
+
+	u.expression = set + remove + " ADD #version :one"
+
+	// expired items which haven't been removed yet don't exist
+	absent := "(attribute_not_exists(#value) OR #expiry <= :now)"
+	current := "(attribute_not_exists(#expiry) OR #expiry > :now)"
+
+	switch {
+	case options.IfNotExists, options.IfMatch && r.Version == 0:
+		u.condition = absent
+	case options.IfMatch:
+		u.condition = "#version = :expected AND " + current
+		u.values[":expected"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatUint(r.Version, 10))}
+	}
+
+	if len(u.condition) > 0 {
+		u.values[":now"] = &dynamodb.AttributeValue{N: aws.String(millis(now))}
+	}
+
+	return u, nil
+}
+
+// record converts the item to a record, nil is returned if it has expired
+func record(item map[string]*dynamodb.AttributeValue) (*store.Record, error) {
+	rec := &store.Record{
+		Key:      aws.StringValue(item[attrKey].S),
+		Metadata: make(map[string]interface{}),
+	}
+
+	if v, ok := item[attrExpiry]; ok && v.N != nil {
+		ms, err := strconv.ParseInt(*v.N, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		expiry := time.Until(time.Unix(0, ms*int64(time.Millisecond)))
+		if expiry <= 0 {
+			return nil, nil
+		}
+		rec.Expiry = expiry
+	}
+
+	if v, ok := item[attrValue]; ok {
+		rec.Value = v.B
+	}
+	if v, ok := item[attrMetadata]; ok && v.S != nil {
+		if err := json.Unmarshal([]byte(*v.S), &rec.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := item[attrVersion]; ok && v.N != nil {
+		rec.Version, _ = strconv.ParseUint(*v.N, 10, 64)
+	}
+
+	return rec, nil
+}
+
+// items returns the items of the namespace with the key prefix. A single partition is queried if
+// the prefix determines it otherwise the table is scanned.
+func (d *dynamoStore) items(namespace, prefix string, keysOnly bool) ([]map[string]*dynamodb.AttributeValue, error) {
+	client, table, length := d.get()
+
+	names := map[string]*string{
+		"#pk": aws.String(attrPartition),
+		"#sk": aws.String(attrKey),
+	}
+	values := map[string]*dynamodb.AttributeValue{}
+
+	var projection *string
+	if keysOnly {
+		names["#expiry"] = aws.String(attrExpiry)
+		projection = aws.String("#pk, #sk, #expiry")
+	}
+
+	var items []map[string]*dynamodb.AttributeValue
+
+	if length == 0 || len(prefix) >= length {
+		cond := "#pk = :pk"
+		values[":pk"] = &dynamodb.AttributeValue{S: aws.String(partition(namespace, prefix, length))}
+		if len(prefix) > 0 {
+			cond += " AND begins_with(#sk, :prefix)"
+			values[":prefix"] = &dynamodb.AttributeValue{S: aws.String(prefix)}
+		}
+
+		err := client.QueryPages(&dynamodb.QueryInput{
+			TableName:                 aws.String(table),
+			KeyConditionExpression:    aws.String(cond),
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: values,
+			ProjectionExpression:      projection,
+			ConsistentRead:            aws.Bool(true),
+		}, func(out *dynamodb.QueryOutput, last bool) bool {
+			items = append(items, out.Items...)
+			return true
+		})
+		return items, err
+	}
+
+	// the prefix spans partitions so the table is scanned
+	filter := "begins_with(#pk, :pk) AND begins_with(#sk, :prefix)"
+	values[":pk"] = &dynamodb.AttributeValue{S: aws.String(namespace + "/" + prefix)}
+	values[":prefix"] = &dynamodb.AttributeValue{S: aws.String(prefix)}
+
+	err := client.ScanPages(&dynamodb.ScanInput{
+		TableName:                 aws.String(table),
+		FilterExpression:          aws.String(filter),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ProjectionExpression:      projection,
+		ConsistentRead:            aws.Bool(true),
+	}, func(out *dynamodb.ScanOutput, last bool) bool {
+		items = append(items, out.Items...)
+		return true
+	})
+	return items, err
+}
+
+// list returns the sorted records of the namespace matching the filters
+func (d *dynamoStore) list(namespace, prefix, suffix string, limit, offset uint, keysOnly bool) ([]*store.Record, error) {
+	items, err := d.items(namespace, prefix, keysOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*store.Record, 0, len(items))
+	for _, item := range items {
+		rec, err := record(item)
+		if err != nil {
+			return nil, err
+		}
+		if rec == nil || !strings.HasSuffix(rec.Key, suffix) {
+			continue
+		}
+		records = append(records, rec)
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].Key < records[j].Key })
+
+	return store.Paginate(records, limit, offset), nil
+}
+
+func (d *dynamoStore) Init(opts ...store.Option) error {
+	d.Lock()
+	for _, o := range opts {
+		o(&d.options)
+	}
+	d.Unlock()
+	// reconfigure
+	return d.configure()
+}
+
+func (d *dynamoStore) Options() store.Options {
+	d.RLock()
+	defer d.RUnlock()
+	return d.options
+}
+
+func (d *dynamoStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	namespace := d.namespace(options.Database, options.Table)
+
+	if options.Prefix || options.Suffix {
+		var prefix, suffix string
+		if options.Prefix {
+			prefix = key
+		}
+		if options.Suffix {
+			suffix = key
+		}
+		return d.list(namespace, prefix, suffix, options.Limit, options.Offset, false)
+	}
+
+	client, table, length := d.get()
+
+	out, err := client.GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String(table),
+		Key:            itemKey(namespace, key, length),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Item) == 0 {
+		return nil, store.ErrNotFound
+	}
+
+	rec, err := record(out.Item)
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		return nil, store.ErrNotFound
+	}
+
+	return []*store.Record{rec}, nil
+}
+
+func (d *dynamoStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	client, table, length := d.get()
+
+	u, err := newUpdate(d.namespace(options.Database, options.Table), length, r, options)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(table),
+		Key:                       u.key,
+		UpdateExpression:          aws.String(u.expression),
+		ExpressionAttributeNames:  u.names,
+		ExpressionAttributeValues: u.values,
+	}
+	if len(u.condition) > 0 {
+		input.ConditionExpression = aws.String(u.condition)
+	}
+
+	if _, err := client.UpdateItem(input); err != nil {
+		if isConflict(err) {
+			return store.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *dynamoStore) Delete(key string, opts ...store.DeleteOption) error {
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	client, table, length := d.get()
+
+	_, err := client.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(table),
+		Key:       itemKey(d.namespace(options.Database, options.Table), key, length),
+	})
+	return err
+}
+
+// BatchWrite writes the records in a transaction, dynamodb limits the number of records
+// in a transaction
+func (d *dynamoStore) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	if len(recs) == 0 {
+		return nil
+	}
+
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	client, table, length := d.get()
+	namespace := d.namespace(options.Database, options.Table)
+
+	items := make([]*dynamodb.TransactWriteItem, len(recs))
+
+	for i, r := range recs {
+		u, err := newUpdate(namespace, length, r, options)
+		if err != nil {
+			return err
+		}
+
+		items[i] = &dynamodb.TransactWriteItem{
+			Update: &dynamodb.Update{
+				TableName:                 aws.String(table),
+				Key:                       u.key,
+				UpdateExpression:          aws.String(u.expression),
+				ExpressionAttributeNames:  u.names,
+				ExpressionAttributeValues: u.values,
+			},
+		}
+		if len(u.condition) > 0 {
+			items[i].Update.ConditionExpression = aws.String(u.condition)
+		}
+	}
+
+	if _, err := client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: items}); err != nil {
+		if isConflict(err) {
+			return store.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// BatchDelete deletes the keys in a transaction, dynamodb limits the number of keys in a transaction
+func (d *dynamoStore) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	client, table, length := d.get()
+	namespace := d.namespace(options.Database, options.Table)
+
+	items := make([]*dynamodb.TransactWriteItem, len(keys))
+	for i, key := range keys {
+		items[i] = &dynamodb.TransactWriteItem{
+			Delete: &dynamodb.Delete{
+				TableName: aws.String(table),
+				Key:       itemKey(namespace, key, length),
+			},
+		}
+	}
+
+	_, err := client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: items})
+	return err
+}
+
+// Expire sets the expiry of the item without changing the version
+func (d *dynamoStore) Expire(key string, ttl time.Duration, opts ...store.ExpireOption) error {
+	var options store.ExpireOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	client, table, length := d.get()
+	now := time.Now()
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(table),
+		Key:                 itemKey(d.namespace(options.Database, options.Table), key, length),
+		ConditionExpression: aws.String("attribute_exists(#value) AND (attribute_not_exists(#expiry) OR #expiry > :now)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#value":  aws.String(attrValue),
+			"#expiry": aws.String(attrExpiry),
+			"#ttl":    aws.String(attrTTL),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":now": {N: aws.String(millis(now))},
+		},
+	}
+
+	if ttl > 0 {
+		expires := now.Add(ttl)
+		input.UpdateExpression = aws.String("SET #expiry = :expiry, #ttl = :ttl")
+		input.ExpressionAttributeValues[":expiry"] = &dynamodb.AttributeValue{N: aws.String(millis(expires))}
+		input.ExpressionAttributeValues[":ttl"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(expires.Add(time.Second-1).Unix(), 10))}
+	} else {
+		input.UpdateExpression = aws.String("REMOVE #expiry, #ttl")
+	}
+
+	if _, err := client.UpdateItem(input); err != nil {
+		if isConflict(err) {
+			return store.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *dynamoStore) List(opts ...store.ListOption) ([]string, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	recs, err := d.list(d.namespace(options.Database, options.Table), options.Prefix, options.Suffix, options.Limit, options.Offset, true)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(recs))
+	for i, r := range recs {
+		keys[i] = r.Key
+	}
+	return keys, nil
+}
+
+func (d *dynamoStore) Close() error {
+	return nil
+}
+
+func (d *dynamoStore) String() string {
+	return "dynamodb"
+}
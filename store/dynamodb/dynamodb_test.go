@@ -0,0 +1,96 @@
+package dynamodb
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/micro/go-micro/v3/store"
+)
+
+func TestPartition(t *testing.T) {
+	tcs := []struct {
+		key    string
+		length int
+		expect string
+	}{
+		{"users/alice", 0, "micro/micro"},
+		{"users/alice", 3, "micro/micro/use"},
+		{"ab", 3, "micro/micro/ab"},
+	}
+	for _, tc := range tcs {
+		if p := partition("micro/micro", tc.key, tc.length); p != tc.expect {
+			t.Fatalf("Expected %s got %s", tc.expect, p)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	rec := &store.Record{Key: "foo", Value: []byte("bar"), Expiry: time.Minute, Version: 3}
+
+	u, err := newUpdate("micro/micro", 0, rec, store.WriteOptions{IfMatch: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.StringValue(u.key[attrPartition].S) != "micro/micro" || aws.StringValue(u.key[attrKey].S) != "foo" {
+		t.Fatalf("Unexpected key %v", u.key)
+	}
+	if !strings.Contains(u.expression, "#expiry = :expiry") || !strings.HasSuffix(u.expression, "ADD #version :one") {
+		t.Fatalf("Unexpected update expression %s", u.expression)
+	}
+	if !strings.HasPrefix(u.condition, "#version = :expected") || aws.StringValue(u.values[":expected"].N) != "3" {
+		t.Fatalf("Unexpected condition %s", u.condition)
+	}
+
+	// every placeholder must be used by the expressions
+	exprs := u.expression + " " + u.condition
+	for name := range u.names {
+		if !strings.Contains(exprs, name) {
+			t.Fatalf("Unused name %s", name)
+		}
+	}
+	for value := range u.values {
+		if !strings.Contains(exprs, value) {
+			t.Fatalf("Unused value %s", value)
+		}
+	}
+
+	// unconditional writes without an expiry remove it
+	u, err = newUpdate("micro/micro", 0, &store.Record{Key: "foo"}, store.WriteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(u.expression, "REMOVE #expiry, #ttl") || len(u.condition) > 0 || u.values[":now"] != nil {
+		t.Fatalf("Unexpected update %+v", u)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	item := map[string]*dynamodb.AttributeValue{
+		attrPartition: {S: aws.String("micro/micro")},
+		attrKey:       {S: aws.String("foo")},
+		attrValue:     {B: []byte("bar")},
+		attrMetadata:  {S: aws.String(`{"baz":"qux"}`)},
+		attrVersion:   {N: aws.String("2")},
+		attrExpiry:    {N: aws.String(millis(time.Now().Add(time.Minute)))},
+	}
+
+	rec, err := record(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Key != "foo" || string(rec.Value) != "bar" || rec.Metadata["baz"] != "qux" || rec.Version != 2 {
+		t.Fatalf("Unexpected record %+v", rec)
+	}
+	if rec.Expiry <= 0 || rec.Expiry > time.Minute {
+		t.Fatalf("Unexpected expiry %v", rec.Expiry)
+	}
+
+	// expired items which haven't been removed by dynamodb are skipped
+	item[attrExpiry] = &dynamodb.AttributeValue{N: aws.String(millis(time.Now().Add(-time.Second)))}
+	if rec, err := record(item); err != nil || rec != nil {
+		t.Fatalf("Expected the expired item to be skipped got %v %v", rec, err)
+	}
+}
@@ -0,0 +1,63 @@
+package dynamodb
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+type regionKey struct{}
+
+type credentialsKey struct{}
+
+type tableNameKey struct{}
+
+type partitionKey struct{}
+
+type credentials struct {
+	id, secret string
+}
+
+// Region of the table, defaults to the AWS_REGION environment variable
+func Region(r string) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, regionKey{}, r)
+	}
+}
+
+// Credentials sets the access key, otherwise the default aws credential chain is used
+func Credentials(id, secret string) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, credentialsKey{}, &credentials{id, secret})
+	}
+}
+
+// TableName of the dynamodb table the records of every database and table are stored in
+func TableName(t string) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, tableNameKey{}, t)
+	}
+}
+
+// PartitionPrefixLength sets how many characters of the key are included in the partition key.
+// By default each database and table is a single partition, which is efficient to read by
+// prefix but limits the throughput of the table to that of a partition. Including a prefix of
+// the key spreads the records across partitions, prefix reads of at least n characters still
+// query a single partition while shorter prefixes scan the dynamodb table.
+func PartitionPrefixLength(n int) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, partitionKey{}, n)
+	}
+}
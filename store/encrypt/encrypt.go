@@ -0,0 +1,244 @@
+// Package encrypt is a store which encrypts the values of the records of another store
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+var (
+	// KeyID is the metadata key of the id of the key a record is encrypted with
+	KeyID = "encryption-key-id"
+)
+
+var (
+	// ErrNoKeys is returned when the store is used without keys
+	ErrNoKeys = errors.New("no encryption keys")
+	// ErrUnknownKey is returned when a key doesn't exist
+	ErrUnknownKey = errors.New("unknown encryption key")
+	// ErrDecrypt is returned when a value can't be decrypted
+	ErrDecrypt = errors.New("unable to decrypt value")
+	// ErrNotEncrypted is returned when rotating the keys of a store which isn't encrypted
+	ErrNotEncrypted = errors.New("not an encrypted store")
+)
+
+// encryptStore encrypts the values with AES-GCM. The nonce is prepended to the value
+// and the record key is authenticated so values can't be swapped between keys. The
+// metadata isn't encrypted so it can still be indexed.
+type encryptStore struct {
+	s       store.Store
+	options store.Options
+}
+
+// NewStore returns a store which encrypts the values of the records before writing them to
+// the store and decrypts them when read. Records without a key id are read as they are so
+// existing records can be encrypted by writing them again or with Rotate.
+func NewStore(s store.Store, opts ...store.Option) store.Store {
+	e := &encryptStore{s: s}
+	for _, o := range opts {
+		o(&e.options)
+	}
+	return e
+}
+
+func (e *encryptStore) keys() (Keys, error) {
+	if e.options.Context == nil {
+		return nil, ErrNoKeys
+	}
+	k, ok := e.options.Context.Value(keysKey{}).(Keys)
+	if !ok {
+		return nil, ErrNoKeys
+	}
+	return k, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt returns a copy of the record with the value encrypted with the current key
+func (e *encryptStore) encrypt(r *store.Record) (*store.Record, error) {
+	keys, err := e.keys()
+	if err != nil {
+		return nil, err
+	}
+	id, key, err := keys.Current()
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	rec := *r
+	rec.Value = gcm.Seal(nonce, nonce, r.Value, []byte(r.Key))
+	rec.Metadata = make(map[string]interface{}, len(r.Metadata)+1)
+	for k, v := range r.Metadata {
+		rec.Metadata[k] = v
+	}
+	rec.Metadata[KeyID] = id
+
+	return &rec, nil
+}
+
+// decrypt the value of the record in place, records without a key id aren't encrypted
+func (e *encryptStore) decrypt(r *store.Record) error {
+	id, ok := r.Metadata[KeyID].(string)
+	if !ok {
+		return nil
+	}
+
+	keys, err := e.keys()
+	if err != nil {
+		return err
+	}
+	key, err := keys.Get(id)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	if len(r.Value) < gcm.NonceSize() {
+		return ErrDecrypt
+	}
+	nonce, ciphertext := r.Value[:gcm.NonceSize()], r.Value[gcm.NonceSize():]
+
+	value, err := gcm.Open(nil, nonce, ciphertext, []byte(r.Key))
+	if err != nil {
+		return ErrDecrypt
+	}
+
+	r.Value = value
+	delete(r.Metadata, KeyID)
+
+	return nil
+}
+
+func (e *encryptStore) Init(opts ...store.Option) error {
+	for _, o := range opts {
+		o(&e.options)
+	}
+	return e.s.Init(opts...)
+}
+
+func (e *encryptStore) Options() store.Options {
+	return e.options
+}
+
+func (e *encryptStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	recs, err := e.s.Read(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range recs {
+		if err := e.decrypt(r); err != nil {
+			return nil, err
+		}
+	}
+	return recs, nil
+}
+
+func (e *encryptStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	rec, err := e.encrypt(r)
+	if err != nil {
+		return err
+	}
+	return e.s.Write(rec, opts...)
+}
+
+func (e *encryptStore) Delete(key string, opts ...store.DeleteOption) error {
+	return e.s.Delete(key, opts...)
+}
+
+func (e *encryptStore) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	encrypted := make([]*store.Record, len(recs))
+	for i, r := range recs {
+		rec, err := e.encrypt(r)
+		if err != nil {
+			return err
+		}
+		encrypted[i] = rec
+	}
+	return e.s.BatchWrite(encrypted, opts...)
+}
+
+func (e *encryptStore) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	return e.s.BatchDelete(keys, opts...)
+}
+
+func (e *encryptStore) List(opts ...store.ListOption) ([]string, error) {
+	return e.s.List(opts...)
+}
+
+func (e *encryptStore) Close() error {
+	return e.s.Close()
+}
+
+func (e *encryptStore) String() string {
+	return "encrypt"
+}
+
+// Rotate encrypts the records of the table which aren't encrypted with the current key with it.
+// The records are written if they haven't changed since they were read, records written in the
+// meantime are already encrypted with the current key.
+func Rotate(s store.Store, database, table string) error {
+	e, ok := s.(*encryptStore)
+	if !ok {
+		return ErrNotEncrypted
+	}
+
+	keys, err := e.keys()
+	if err != nil {
+		return err
+	}
+	current, _, err := keys.Current()
+	if err != nil {
+		return err
+	}
+
+	// read the records as they're stored to check the key they're encrypted with
+	recs, err := e.s.Read("", store.ReadPrefix(), store.ReadFrom(database, table))
+	if err != nil && err != store.ErrNotFound {
+		return err
+	}
+
+	for _, r := range recs {
+		if id, _ := r.Metadata[KeyID].(string); id == current {
+			continue
+		}
+		if err := e.decrypt(r); err != nil {
+			return err
+		}
+
+		// versions aren't known by every store in which case the record is overwritten
+		var opts []store.WriteOption
+		if r.Version > 0 {
+			opts = append(opts, store.WriteIfMatch())
+		}
+		opts = append(opts, store.WriteTo(database, table))
+
+		if err := e.Write(r, opts...); err != nil && err != store.ErrConflict {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package encrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/micro/go-micro/v3/config"
+	"github.com/micro/go-micro/v3/config/source/memory"
+	"github.com/micro/go-micro/v3/store"
+	mstore "github.com/micro/go-micro/v3/store/memory"
+)
+
+var (
+	key1 = []byte("0123456789abcdef0123456789abcdef")
+	key2 = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func TestEncryptStore(t *testing.T) {
+	m := mstore.NewStore()
+	keys := map[string][]byte{"1": key1}
+	s := NewStore(m, WithKeys(StaticKeys("1", keys)))
+
+	rec := &store.Record{Key: "foo", Value: []byte("bar"), Metadata: map[string]interface{}{"baz": "qux"}}
+	if err := s.Write(rec); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rec.Metadata[KeyID]; ok {
+		t.Fatal("Expected the written record not to be modified")
+	}
+
+	// the value is encrypted in the underlying store
+	raw, err := m.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw[0].Value, []byte("bar")) || raw[0].Metadata[KeyID] != "1" {
+		t.Fatalf("Expected the value to be encrypted with key 1 got %+v", raw[0])
+	}
+
+	recs, err := s.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(recs[0].Value) != "bar" || recs[0].Metadata["baz"] != "qux" {
+		t.Fatalf("Unexpected record %+v", recs[0])
+	}
+	if _, ok := recs[0].Metadata[KeyID]; ok {
+		t.Fatal("Expected the key id to be removed from the metadata")
+	}
+
+	// values can't be moved to another key
+	raw[0].Key = "moved"
+	if err := m.Write(raw[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Read("moved"); err != ErrDecrypt {
+		t.Fatalf("Expected %v got %v", ErrDecrypt, err)
+	}
+	m.Delete("moved")
+
+	// plaintext records are read as they are
+	if err := m.Write(&store.Record{Key: "plain", Value: []byte("text")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// rotate to a new key, the old key is kept to decrypt the existing records
+	keys["2"] = key2
+	s = NewStore(m, WithKeys(StaticKeys("2", keys)))
+
+	recs, err = s.Read("", store.ReadPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 || string(recs[0].Value) != "bar" || string(recs[1].Value) != "text" {
+		t.Fatalf("Unexpected records %+v", recs)
+	}
+
+	if err := Rotate(s, "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err = m.Read("", store.ReadPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range raw {
+		if r.Metadata[KeyID] != "2" {
+			t.Fatalf("Expected %s to be encrypted with key 2 got %v", r.Key, r.Metadata[KeyID])
+		}
+	}
+
+	// the old key can be removed once rotated
+	s = NewStore(m, WithKeys(StaticKeys("2", map[string][]byte{"2": key2})))
+	recs, err = s.Read("plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(recs[0].Value) != "text" {
+		t.Fatalf("Unexpected record %+v", recs[0])
+	}
+
+	s = NewStore(m)
+	if err := s.Write(rec); err != ErrNoKeys {
+		t.Fatalf("Expected %v got %v", ErrNoKeys, err)
+	}
+}
+
+func TestConfigKeys(t *testing.T) {
+	data := []byte(`{"store": {"encryption": {"current": "1", "keys": {"1": "` + base64.StdEncoding.EncodeToString(key1) + `"}}}}`)
+
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Load(memory.NewSource(memory.WithJSON(data))); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := ConfigKeys(c, "store", "encryption")
+
+	id, key, err := keys.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "1" || !bytes.Equal(key, key1) {
+		t.Fatalf("Unexpected key %s %s", id, key)
+	}
+	if _, err := keys.Get("2"); err != ErrUnknownKey {
+		t.Fatalf("Expected %v got %v", ErrUnknownKey, err)
+	}
+}
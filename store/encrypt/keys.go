@@ -0,0 +1,86 @@
+package encrypt
+
+import (
+	"encoding/base64"
+
+	"github.com/micro/go-micro/v3/config/reader"
+)
+
+// Keys provides the encryption keys, usually from a secrets provider. Keys are
+// rotated by changing the current key while the previous keys can still be got
+// to decrypt the records which haven't been rotated.
+type Keys interface {
+	// Current returns the id and key the records are encrypted with
+	Current() (string, []byte, error)
+	// Get returns the key with the id
+	Get(id string) ([]byte, error)
+}
+
+type staticKeys struct {
+	current string
+	keys    map[string][]byte
+}
+
+// StaticKeys returns the keys, current is the id of the key records are encrypted with
+func StaticKeys(current string, keys map[string][]byte) Keys {
+	return &staticKeys{
+		current: current,
+		keys:    keys,
+	}
+}
+
+func (s *staticKeys) Current() (string, []byte, error) {
+	key, err := s.Get(s.current)
+	if err != nil {
+		return "", nil, err
+	}
+	return s.current, key, nil
+}
+
+func (s *staticKeys) Get(id string) ([]byte, error) {
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+type configKeys struct {
+	values reader.Values
+	path   []string
+}
+
+// ConfigKeys returns the keys at the path of the config, they're read every time so
+// the keys can be rotated by changing the config. The keys are base64 encoded e.g
+//
+//	{"current": "2", "keys": {"1": "...", "2": "..."}}
+func ConfigKeys(v reader.Values, path ...string) Keys {
+	return &configKeys{
+		values: v,
+		path:   path,
+	}
+}
+
+func (c *configKeys) get(path ...string) string {
+	return c.values.Get(append(append([]string{}, c.path...), path...)...).String("")
+}
+
+func (c *configKeys) Current() (string, []byte, error) {
+	id := c.get("current")
+	if len(id) == 0 {
+		return "", nil, ErrUnknownKey
+	}
+	key, err := c.Get(id)
+	if err != nil {
+		return "", nil, err
+	}
+	return id, key, nil
+}
+
+func (c *configKeys) Get(id string) ([]byte, error) {
+	enc := c.get("keys", id)
+	if len(enc) == 0 {
+		return nil, ErrUnknownKey
+	}
+	return base64.StdEncoding.DecodeString(enc)
+}
@@ -0,0 +1,19 @@
+package encrypt
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+type keysKey struct{}
+
+// WithKeys sets the keys the records are encrypted with
+func WithKeys(k Keys) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, keysKey{}, k)
+	}
+}
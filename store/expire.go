@@ -0,0 +1,45 @@
+package store
+
+import (
+	"time"
+)
+
+// Expirable is implemented by stores which can change the expiry of a record without writing it
+type Expirable interface {
+	// Expire sets the expiry of the record to the ttl from now, a ttl of zero removes the expiry.
+	// The version of the record isn't changed. ErrNotFound is returned if the record doesn't exist.
+	Expire(key string, ttl time.Duration, opts ...ExpireOption) error
+}
+
+// Expire sets the expiry of the record to the ttl from now, a ttl of zero removes the expiry.
+// Stores which aren't Expirable read the record and write it with the new expiry, ErrConflict
+// is returned if the record is written in the meantime.
+func Expire(s Store, key string, ttl time.Duration, opts ...ExpireOption) error {
+	if e, ok := s.(Expirable); ok {
+		return e.Expire(key, ttl, opts...)
+	}
+
+	var options ExpireOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	recs, err := s.Read(key, ReadFrom(options.Database, options.Table))
+	if err != nil {
+		return err
+	}
+	if len(recs) == 0 {
+		return ErrNotFound
+	}
+
+	rec := recs[0]
+	rec.Expiry = ttl
+
+	// only write the record if it hasn't changed, which requires the version to be known
+	wopts := []WriteOption{WriteTo(options.Database, options.Table)}
+	if rec.Version > 0 {
+		wopts = append(wopts, WriteIfMatch())
+	}
+
+	return s.Write(rec, wopts...)
+}
@@ -48,6 +48,7 @@ type record struct {
 	Value     []byte
 	Metadata  map[string]interface{}
 	ExpiresAt time.Time
+	Version   uint64
 }
 
 func key(database, table string) string {
@@ -197,6 +198,7 @@ func (m *fileStore) get(db *bolt.DB, k string) (*store.Record, error) {
 	newRecord.Key = storedRecord.Key
 	newRecord.Value = storedRecord.Value
 	newRecord.Metadata = make(map[string]interface{})
+	newRecord.Version = storedRecord.Version
 
 	for k, v := range storedRecord.Metadata {
 		newRecord.Metadata[k] = v
@@ -212,11 +214,68 @@ func (m *fileStore) get(db *bolt.DB, k string) (*store.Record, error) {
 	return newRecord, nil
 }
 
-func (m *fileStore) set(db *bolt.DB, r *store.Record) error {
+func (m *fileStore) set(db *bolt.DB, r *store.Record, options store.WriteOptions) error {
+	return m.batch(db, []*store.Record{r}, nil, options)
+}
+
+// batch writes the records and deletes the keys in a single bolt transaction.
+// The records are only written if the conditions of the options are met.
+func (m *fileStore) batch(db *bolt.DB, recs []*store.Record, keys []string, options store.WriteOptions) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(dataBucket))
+		if err != nil {
+			return err
+		}
+
+		versions := make([]uint64, len(recs))
+
+		for i, r := range recs {
+			var exists bool
+			var version uint64
+
+			if v := b.Get([]byte(r.Key)); v != nil {
+				stored := &record{}
+				if err := json.Unmarshal(v, stored); err != nil {
+					return err
+				}
+				if stored.ExpiresAt.IsZero() || stored.ExpiresAt.After(time.Now()) {
+					exists = true
+					version = stored.Version
+				}
+			}
+
+			if options.IfNotExists && exists {
+				return store.ErrConflict
+			}
+			if options.IfMatch && version != r.Version {
+				return store.ErrConflict
+			}
+
+			versions[i] = version + 1
+		}
+
+		for i, r := range recs {
+			if err := b.Put([]byte(r.Key), encode(r, versions[i])); err != nil {
+				return err
+			}
+		}
+
+		for _, key := range keys {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func encode(r *store.Record, version uint64) []byte {
 	// copy the incoming record and then
 	// convert the expiry in to a hard timestamp
 	item := &record{}
 	item.Key = r.Key
+	item.Version = version
 	item.Value = r.Value
 	item.Metadata = make(map[string]interface{})
 
@@ -231,17 +290,7 @@ func (m *fileStore) set(db *bolt.DB, r *store.Record) error {
 	// marshal the data
 	data, _ := json.Marshal(item)
 
-	return db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(dataBucket))
-		if b == nil {
-			var err error
-			b, err = tx.CreateBucketIfNotExists([]byte(dataBucket))
-			if err != nil {
-				return err
-			}
-		}
-		return b.Put([]byte(r.Key), data)
-	})
+	return data
 }
 
 func (f *fileStore) Close() error {
@@ -322,22 +371,84 @@ func (m *fileStore) Write(r *store.Record, opts ...store.WriteOption) error {
 	}
 	defer db.Close()
 
-	if len(opts) > 0 {
-		// Copy the record before applying options, or the incoming record will be mutated
-		newRecord := store.Record{}
-		newRecord.Key = r.Key
-		newRecord.Value = r.Value
-		newRecord.Metadata = make(map[string]interface{})
-		newRecord.Expiry = r.Expiry
+	return m.set(db, r, writeOpts)
+}
 
-		for k, v := range r.Metadata {
-			newRecord.Metadata[k] = v
-		}
+// BatchWrite writes the records in a single transaction
+func (m *fileStore) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	var writeOpts store.WriteOptions
+	for _, o := range opts {
+		o(&writeOpts)
+	}
 
-		return m.set(db, &newRecord)
+	db, err := m.getDB(writeOpts.Database, writeOpts.Table)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return m.batch(db, recs, nil, writeOpts)
+}
+
+// BatchDelete deletes the keys in a single transaction
+func (m *fileStore) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	var deleteOptions store.DeleteOptions
+	for _, o := range opts {
+		o(&deleteOptions)
+	}
+
+	db, err := m.getDB(deleteOptions.Database, deleteOptions.Table)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return m.batch(db, nil, keys, store.WriteOptions{})
+}
+
+// Expire sets the expiry of the record in place without changing its version
+func (m *fileStore) Expire(key string, ttl time.Duration, opts ...store.ExpireOption) error {
+	var expireOptions store.ExpireOptions
+	for _, o := range opts {
+		o(&expireOptions)
+	}
+
+	db, err := m.getDB(expireOptions.Database, expireOptions.Table)
+	if err != nil {
+		return err
 	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dataBucket))
+		if b == nil {
+			return store.ErrNotFound
+		}
 
-	return m.set(db, r)
+		v := b.Get([]byte(key))
+		if v == nil {
+			return store.ErrNotFound
+		}
+
+		stored := &record{}
+		if err := json.Unmarshal(v, stored); err != nil {
+			return err
+		}
+		if !stored.ExpiresAt.IsZero() && stored.ExpiresAt.Before(time.Now()) {
+			return store.ErrNotFound
+		}
+
+		stored.ExpiresAt = time.Time{}
+		if ttl != 0 {
+			stored.ExpiresAt = time.Now().Add(ttl)
+		}
+
+		data, err := json.Marshal(stored)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
 }
 
 func (m *fileStore) Options() store.Options {
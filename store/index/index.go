@@ -0,0 +1,265 @@
+// Package index maintains the secondary indexes of a KV store as index keys so the
+// records can be queried without scanning them.
+package index
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+var (
+	// TableSuffix is appended to the table of the records to get the table of their index keys
+	TableSuffix = "_index"
+)
+
+// indexStore writes an index key for every indexed value of a record in the same transaction
+// as the record. The keys are name/value/key where the value is encoded by store.EncodeValue
+// so a list of the keys returns the records in the order of the values.
+type indexStore struct {
+	s       store.Store
+	options store.Options
+}
+
+// NewStore returns a store which maintains the indexes declared by the store.Indexes option.
+// Records written before an index was declared aren't indexed until they're written again.
+func NewStore(s store.Store, opts ...store.Option) store.Store {
+	options := s.Options()
+	for _, o := range opts {
+		o(&options)
+	}
+	return &indexStore{
+		s:       s,
+		options: options,
+	}
+}
+
+// table returns the database and table of the index keys of the records in the table
+func (i *indexStore) table(database, table string) (string, string) {
+	if len(database) == 0 {
+		database = i.options.Database
+	}
+	if len(table) == 0 {
+		table = i.options.Table
+	}
+	return database, table + TableSuffix
+}
+
+// keys returns the index keys of the record
+func (i *indexStore) keys(r *store.Record) map[string]bool {
+	keys := make(map[string]bool)
+	for _, idx := range i.options.Indexes {
+		v, ok := idx.Value(r)
+		if !ok {
+			continue
+		}
+		enc, err := store.EncodeValue(v)
+		if err != nil {
+			continue
+		}
+		keys[idx.Name+"/"+enc+"/"+r.Key] = true
+	}
+	return keys
+}
+
+// previous returns the index keys of the current record of the key
+func (i *indexStore) previous(tx store.Tx, key string, database, table string) (map[string]bool, error) {
+	recs, err := tx.Read(key, store.ReadFrom(database, table))
+	if err == store.ErrNotFound || len(recs) == 0 {
+		return map[string]bool{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return i.keys(recs[0]), nil
+}
+
+func (i *indexStore) Init(opts ...store.Option) error {
+	for _, o := range opts {
+		o(&i.options)
+	}
+	return i.s.Init(opts...)
+}
+
+func (i *indexStore) Options() store.Options {
+	return i.options
+}
+
+func (i *indexStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	return i.s.Read(key, opts...)
+}
+
+func (i *indexStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	return i.BatchWrite([]*store.Record{r}, opts...)
+}
+
+func (i *indexStore) Delete(key string, opts ...store.DeleteOption) error {
+	return i.BatchDelete([]string{key}, opts...)
+}
+
+// BatchWrite writes the records and their index keys in a transaction, the index keys
+// of the values the records previously had are removed
+func (i *indexStore) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	database, table := i.table(options.Database, options.Table)
+
+	return store.Transaction(i.s, func(tx store.Tx) error {
+		for _, r := range recs {
+			old, err := i.previous(tx, r.Key, options.Database, options.Table)
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Write(r, opts...); err != nil {
+				return err
+			}
+
+			// the index keys expire with the record
+			for key := range i.keys(r) {
+				delete(old, key)
+				if err := tx.Write(&store.Record{Key: key, Expiry: r.Expiry}, store.WriteTo(database, table)); err != nil {
+					return err
+				}
+			}
+
+			for key := range old {
+				if err := tx.Delete(key, store.DeleteFrom(database, table)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// BatchDelete deletes the records and their index keys in a transaction
+func (i *indexStore) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	database, table := i.table(options.Database, options.Table)
+
+	return store.Transaction(i.s, func(tx store.Tx) error {
+		for _, key := range keys {
+			old, err := i.previous(tx, key, options.Database, options.Table)
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Delete(key, opts...); err != nil {
+				return err
+			}
+
+			for k := range old {
+				if err := tx.Delete(k, store.DeleteFrom(database, table)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Query lists the index keys matching the query and reads their records. Index keys which
+// no longer match their record, e.g. left by concurrent writes, are skipped.
+func (i *indexStore) Query(index string, opts ...store.QueryOption) ([]*store.Record, error) {
+	idx, ok := i.options.Index(index)
+	if !ok {
+		return nil, store.ErrUnknownIndex
+	}
+
+	var options store.QueryOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	var min, max string
+	var err error
+
+	prefix := idx.Name + "/"
+
+	if options.Equals != nil {
+		eq, err := store.EncodeValue(options.Equals)
+		if err != nil {
+			return nil, err
+		}
+		prefix += eq + "/"
+	}
+	if options.Min != nil {
+		if min, err = store.EncodeValue(options.Min); err != nil {
+			return nil, err
+		}
+	}
+	if options.Max != nil {
+		if max, err = store.EncodeValue(options.Max); err != nil {
+			return nil, err
+		}
+	}
+
+	database, table := i.table(options.Database, options.Table)
+
+	keys, err := i.s.List(store.ListFrom(database, table), store.ListPrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+
+	var records []*store.Record
+
+	for _, key := range keys {
+		parts := strings.SplitN(strings.TrimPrefix(key, idx.Name+"/"), "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value, k := parts[0], parts[1]
+
+		if len(min) > 0 && value < min {
+			continue
+		}
+		if len(max) > 0 && value >= max {
+			continue
+		}
+
+		recs, err := i.s.Read(k, store.ReadFrom(options.Database, options.Table))
+		if err == store.ErrNotFound || len(recs) == 0 {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		v, ok := idx.Value(recs[0])
+		if !ok {
+			continue
+		}
+		if enc, err := store.EncodeValue(v); err != nil || enc != value {
+			continue
+		}
+
+		records = append(records, recs[0])
+
+		// stop reading once the page is full
+		if options.Limit > 0 && uint(len(records)) >= options.Offset+options.Limit {
+			break
+		}
+	}
+
+	return store.Paginate(records, options.Limit, options.Offset), nil
+}
+
+func (i *indexStore) List(opts ...store.ListOption) ([]string, error) {
+	return i.s.List(opts...)
+}
+
+func (i *indexStore) Close() error {
+	return i.s.Close()
+}
+
+func (i *indexStore) String() string {
+	return "index"
+}
@@ -0,0 +1,108 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/store/memory"
+)
+
+func keys(recs []*store.Record) []string {
+	keys := make([]string, len(recs))
+	for i, r := range recs {
+		keys[i] = r.Key
+	}
+	return keys
+}
+
+func expect(t *testing.T, recs []*store.Record, err error, want ...string) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := keys(recs)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v got %v", want, got)
+		}
+	}
+}
+
+func TestIndexStore(t *testing.T) {
+	s := NewStore(memory.NewStore(), store.Indexes(
+		store.Index{Name: "age", Field: "age"},
+		store.Index{Name: "email", Field: "$.email"},
+	))
+	defer s.Close()
+
+	users := []struct {
+		key   string
+		age   int
+		email string
+	}{
+		{"alice", 30, "alice@example.com"},
+		{"bob", 25, "bob@example.com"},
+		{"carol", 30, "carol@example.com"},
+		{"dave", -5, "dave@example.com"},
+	}
+	for _, u := range users {
+		err := s.Write(&store.Record{
+			Key:      u.key,
+			Value:    []byte(`{"email": "` + u.email + `"}`),
+			Metadata: map[string]interface{}{"age": u.age},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recs, err := store.Query(s, "age", store.QueryEquals(30))
+	expect(t, recs, err, "alice", "carol")
+
+	recs, err = store.Query(s, "email", store.QueryEquals("bob@example.com"))
+	expect(t, recs, err, "bob")
+
+	// ranges are ordered by the value then the key
+	recs, err = store.Query(s, "age", store.QueryRange(-10, 30))
+	expect(t, recs, err, "dave", "bob")
+
+	recs, err = store.Query(s, "age", store.QueryRange(25, nil))
+	expect(t, recs, err, "bob", "alice", "carol")
+
+	recs, err = store.Query(s, "age", store.QueryRange(nil, nil), store.QueryLimit(2), store.QueryOffset(1))
+	expect(t, recs, err, "bob", "alice")
+
+	// the old value is removed from the index when the record is written
+	if err := s.Write(&store.Record{Key: "alice", Value: []byte(`{}`), Metadata: map[string]interface{}{"age": 31}}); err != nil {
+		t.Fatal(err)
+	}
+	recs, err = store.Query(s, "age", store.QueryEquals(30))
+	expect(t, recs, err, "carol")
+	recs, err = store.Query(s, "email", store.QueryEquals("alice@example.com"))
+	expect(t, recs, err)
+
+	if err := s.Delete("carol"); err != nil {
+		t.Fatal(err)
+	}
+	recs, err = store.Query(s, "age", store.QueryEquals(30))
+	expect(t, recs, err)
+
+	// only the record keys are listed from the table
+	listed, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 3 {
+		t.Fatalf("Expected 3 keys got %v", listed)
+	}
+
+	if _, err := store.Query(s, "missing"); err != store.ErrUnknownIndex {
+		t.Fatalf("Expected %v got %v", store.ErrUnknownIndex, err)
+	}
+	if _, err := store.Query(s, "age", store.QueryEquals([]string{"invalid"})); err != store.ErrInvalidValue {
+		t.Fatalf("Expected %v got %v", store.ErrInvalidValue, err)
+	}
+}
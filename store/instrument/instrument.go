@@ -0,0 +1,173 @@
+// Package instrument is a store which reports the latency and errors of the operations
+// of another store and logs the slow operations
+package instrument
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/metrics"
+	"github.com/micro/go-micro/v3/store"
+)
+
+var (
+	// DefaultSlowThreshold is the duration after which operations are logged as slow
+	DefaultSlowThreshold = time.Second
+	// MetricName is the timing metric of the operations
+	MetricName = "store.operation"
+)
+
+type instrumentStore struct {
+	s       store.Store
+	options store.Options
+
+	reporter metrics.Reporter
+	slow     time.Duration
+}
+
+// NewStore returns a store which reports the duration of every operation as a timing metric tagged
+// with the operation, database, table and result. Not found isn't reported as a failure.
+func NewStore(s store.Store, opts ...store.Option) store.Store {
+	i := &instrumentStore{s: s}
+	i.configure(opts...)
+	return i
+}
+
+func (i *instrumentStore) configure(opts ...store.Option) {
+	for _, o := range opts {
+		o(&i.options)
+	}
+
+	i.reporter = metrics.DefaultReporter
+	i.slow = DefaultSlowThreshold
+
+	if i.options.Context != nil {
+		if r, ok := i.options.Context.Value(reporterKey{}).(metrics.Reporter); ok {
+			i.reporter = r
+		}
+		if d, ok := i.options.Context.Value(slowKey{}).(time.Duration); ok {
+			i.slow = d
+		}
+	}
+}
+
+// report the result of the operation started at the time
+func (i *instrumentStore) report(op, database, table string, started time.Time, err error) {
+	d := time.Since(started)
+
+	opts := i.s.Options()
+	if len(database) == 0 {
+		database = opts.Database
+	}
+	if len(table) == 0 {
+		table = opts.Table
+	}
+
+	result := "success"
+	if err != nil && err != store.ErrNotFound {
+		result = "failure"
+	}
+
+	i.reporter.Timing(MetricName, d, metrics.Tags{
+		"store":     i.s.String(),
+		"operation": op,
+		"database":  database,
+		"table":     table,
+		"result":    result,
+	})
+
+	if i.slow > 0 && d >= i.slow {
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Slow %s store %s of %s/%s took %v", i.s.String(), op, database, table, d)
+		}
+	}
+}
+
+func (i *instrumentStore) Init(opts ...store.Option) error {
+	i.configure(opts...)
+	return i.s.Init(opts...)
+}
+
+func (i *instrumentStore) Options() store.Options {
+	return i.options
+}
+
+func (i *instrumentStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	started := time.Now()
+	recs, err := i.s.Read(key, opts...)
+	i.report("read", options.Database, options.Table, started, err)
+	return recs, err
+}
+
+func (i *instrumentStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	started := time.Now()
+	err := i.s.Write(r, opts...)
+	i.report("write", options.Database, options.Table, started, err)
+	return err
+}
+
+func (i *instrumentStore) Delete(key string, opts ...store.DeleteOption) error {
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	started := time.Now()
+	err := i.s.Delete(key, opts...)
+	i.report("delete", options.Database, options.Table, started, err)
+	return err
+}
+
+func (i *instrumentStore) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	started := time.Now()
+	err := i.s.BatchWrite(recs, opts...)
+	i.report("batch_write", options.Database, options.Table, started, err)
+	return err
+}
+
+func (i *instrumentStore) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	started := time.Now()
+	err := i.s.BatchDelete(keys, opts...)
+	i.report("batch_delete", options.Database, options.Table, started, err)
+	return err
+}
+
+func (i *instrumentStore) List(opts ...store.ListOption) ([]string, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	started := time.Now()
+	keys, err := i.s.List(opts...)
+	i.report("list", options.Database, options.Table, started, err)
+	return keys, err
+}
+
+func (i *instrumentStore) Close() error {
+	return i.s.Close()
+}
+
+func (i *instrumentStore) String() string {
+	return i.s.String()
+}
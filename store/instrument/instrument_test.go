@@ -0,0 +1,71 @@
+package instrument
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/metrics"
+	"github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/store/memory"
+)
+
+type timing struct {
+	id   string
+	tags metrics.Tags
+}
+
+type testReporter struct {
+	sync.Mutex
+	timings []timing
+}
+
+func (r *testReporter) Count(id string, value int64, tags metrics.Tags) error {
+	return nil
+}
+
+func (r *testReporter) Gauge(id string, value float64, tags metrics.Tags) error {
+	return nil
+}
+
+func (r *testReporter) Timing(id string, value time.Duration, tags metrics.Tags) error {
+	r.Lock()
+	defer r.Unlock()
+	r.timings = append(r.timings, timing{id, tags})
+	return nil
+}
+
+func TestInstrumentStore(t *testing.T) {
+	r := new(testReporter)
+	s := NewStore(memory.NewStore(), Reporter(r))
+
+	if err := s.Write(&store.Record{Key: "foo"}, store.WriteTo("db", "table")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Read("missing"); err != store.ErrNotFound {
+		t.Fatalf("Expected %v got %v", store.ErrNotFound, err)
+	}
+	if err := s.Write(&store.Record{Key: "foo"}, store.WriteTo("db", "table"), store.WriteIfNotExists()); err != store.ErrConflict {
+		t.Fatalf("Expected %v got %v", store.ErrConflict, err)
+	}
+
+	expected := []metrics.Tags{
+		{"store": "memory", "operation": "write", "database": "db", "table": "table", "result": "success"},
+		{"store": "memory", "operation": "read", "database": "micro", "table": "micro", "result": "success"},
+		{"store": "memory", "operation": "write", "database": "db", "table": "table", "result": "failure"},
+	}
+
+	if len(r.timings) != len(expected) {
+		t.Fatalf("Expected %d timings got %d", len(expected), len(r.timings))
+	}
+	for i, tags := range expected {
+		if r.timings[i].id != MetricName {
+			t.Fatalf("Expected %s got %s", MetricName, r.timings[i].id)
+		}
+		for k, v := range tags {
+			if r.timings[i].tags[k] != v {
+				t.Fatalf("Expected %s to be %s got %s", k, v, r.timings[i].tags[k])
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package instrument
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/metrics"
+	"github.com/micro/go-micro/v3/store"
+)
+
+type reporterKey struct{}
+
+type slowKey struct{}
+
+// Reporter the metrics of the operations are reported to, the default reporter if not set
+func Reporter(r metrics.Reporter) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, reporterKey{}, r)
+	}
+}
+
+// SlowThreshold logs the operations which take longer than d, zero disables the logging
+func SlowThreshold(d time.Duration) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, slowKey{}, d)
+	}
+}
@@ -20,7 +20,8 @@ func NewStore(opts ...store.Option) store.Store {
 			Database: "micro",
 			Table:    "micro",
 		},
-		stores: map[string]*cache.Cache{}, // cache.New(cache.NoExpiration, 5*time.Minute),
+		stores:   map[string]*cache.Cache{}, // cache.New(cache.NoExpiration, 5*time.Minute),
+		watchers: map[string]*memoryWatcher{},
 	}
 	for _, o := range opts {
 		o(&s.options)
@@ -33,6 +34,12 @@ type memoryStore struct {
 	options store.Options
 
 	stores map[string]*cache.Cache
+
+	// serialises the writes so versions are checked and incremented atomically
+	writeMtx sync.Mutex
+
+	watchMtx sync.RWMutex
+	watchers map[string]*memoryWatcher
 }
 
 type storeRecord struct {
@@ -40,6 +47,7 @@ type storeRecord struct {
 	value     []byte
 	metadata  map[string]interface{}
 	expiresAt time.Time
+	version   uint64
 }
 
 func (m *memoryStore) prefix(database, table string) string {
@@ -59,7 +67,10 @@ func (m *memoryStore) getStore(prefix string) *cache.Cache {
 	if store == nil {
 		m.Lock()
 		if m.stores[prefix] == nil {
-			m.stores[prefix] = cache.New(cache.NoExpiration, 5*time.Minute)
+			c := cache.New(cache.NoExpiration, m.cleanup())
+			// called when the records are deleted or removed by the janitor once expired
+			c.OnEvicted(m.evicted(prefix))
+			m.stores[prefix] = c
 		}
 		store = m.stores[prefix]
 		m.Unlock()
@@ -67,6 +78,16 @@ func (m *memoryStore) getStore(prefix string) *cache.Cache {
 	return store
 }
 
+// cleanup returns how often the expired records are removed
+func (m *memoryStore) cleanup() time.Duration {
+	if m.options.Context != nil {
+		if d, ok := m.options.Context.Value(cleanupKey{}).(time.Duration); ok && d > 0 {
+			return d
+		}
+	}
+	return 5 * time.Minute
+}
+
 func (m *memoryStore) get(prefix, key string) (*store.Record, error) {
 	var storedRecord *storeRecord
 	r, found := m.getStore(prefix).Get(key)
@@ -85,6 +106,8 @@ func (m *memoryStore) get(prefix, key string) (*store.Record, error) {
 	newRecord.Value = make([]byte, len(storedRecord.value))
 	newRecord.Metadata = make(map[string]interface{})
 
+	newRecord.Version = storedRecord.version
+
 	// copy the value into the new record
 	copy(newRecord.Value, storedRecord.value)
 
@@ -101,11 +124,68 @@ func (m *memoryStore) get(prefix, key string) (*store.Record, error) {
 	return newRecord, nil
 }
 
-func (m *memoryStore) set(prefix string, r *store.Record) {
+// write the records if all the conditions of the options are met
+func (m *memoryStore) write(prefix string, recs []*store.Record, options store.WriteOptions) error {
+	var preserve bool
+	if options.Context != nil {
+		preserve, _ = options.Context.Value(preserveVersionKey{}).(bool)
+	}
+
+	m.writeMtx.Lock()
+	defer m.writeMtx.Unlock()
+
+	// check all the conditions first so either all the records are written or none are
+	versions := make([]uint64, len(recs))
+	exists := make([]bool, len(recs))
+
+	for i, r := range recs {
+		var found bool
+		var version uint64
+
+		if v, ok := m.getStore(prefix).Get(r.Key); ok {
+			if sr, ok := v.(*storeRecord); ok {
+				found = true
+				version = sr.version
+			}
+		}
+
+		if options.IfNotExists && found {
+			return store.ErrConflict
+		}
+		if options.IfMatch && version != r.Version {
+			return store.ErrConflict
+		}
+
+		exists[i] = found
+
+		if preserve {
+			versions[i] = r.Version
+		} else {
+			versions[i] = version + 1
+		}
+	}
+
+	for i, r := range recs {
+		m.set(prefix, r, versions[i])
+
+		typ := store.Create
+		if exists[i] {
+			typ = store.Update
+		}
+		if rec, err := m.get(prefix, r.Key); err == nil {
+			m.emit(prefix, typ, rec)
+		}
+	}
+
+	return nil
+}
+
+func (m *memoryStore) set(prefix string, r *store.Record, version uint64) {
 	// copy the incoming record and then
 	// convert the expiry in to a hard timestamp
 	i := &storeRecord{}
 	i.key = r.Key
+	i.version = version
 	i.value = make([]byte, len(r.Value))
 	i.metadata = make(map[string]interface{})
 
@@ -229,27 +309,8 @@ func (m *memoryStore) Write(r *store.Record, opts ...store.WriteOption) error {
 
 	prefix := m.prefix(writeOpts.Database, writeOpts.Table)
 
-	if len(opts) > 0 {
-		// Copy the record before applying options, or the incoming record will be mutated
-		newRecord := store.Record{}
-		newRecord.Key = r.Key
-		newRecord.Value = make([]byte, len(r.Value))
-		newRecord.Metadata = make(map[string]interface{})
-		copy(newRecord.Value, r.Value)
-		newRecord.Expiry = r.Expiry
-
-		for k, v := range r.Metadata {
-			newRecord.Metadata[k] = v
-		}
-
-		m.set(prefix, &newRecord)
-		return nil
-	}
-
-	// set
-	m.set(prefix, r)
-
-	return nil
+	// the record is copied when set so it isn't mutated
+	return m.write(prefix, []*store.Record{r}, writeOpts)
 }
 
 func (m *memoryStore) Delete(key string, opts ...store.DeleteOption) error {
@@ -259,7 +320,53 @@ func (m *memoryStore) Delete(key string, opts ...store.DeleteOption) error {
 	}
 
 	prefix := m.prefix(deleteOptions.Database, deleteOptions.Table)
+
+	m.writeMtx.Lock()
 	m.delete(prefix, key)
+	m.writeMtx.Unlock()
+
+	return nil
+}
+
+// BatchWrite writes the records, either all the records are written or none are
+func (m *memoryStore) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	writeOpts := store.WriteOptions{}
+	for _, o := range opts {
+		o(&writeOpts)
+	}
+
+	prefix := m.prefix(writeOpts.Database, writeOpts.Table)
+	return m.write(prefix, recs, writeOpts)
+}
+
+func (m *memoryStore) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	for _, key := range keys {
+		if err := m.Delete(key, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Expire sets the expiry of the record without changing its version
+func (m *memoryStore) Expire(key string, ttl time.Duration, opts ...store.ExpireOption) error {
+	var options store.ExpireOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	prefix := m.prefix(options.Database, options.Table)
+
+	m.writeMtx.Lock()
+	defer m.writeMtx.Unlock()
+
+	r, err := m.get(prefix, key)
+	if err != nil {
+		return err
+	}
+	r.Expiry = ttl
+
+	m.set(prefix, r, r.Version)
 	return nil
 }
 
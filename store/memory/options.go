@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+type preserveVersionKey struct{}
+
+type cleanupKey struct{}
+
+// PreserveVersion writes the records with the version they already have rather than
+// incrementing it. It's used to cache the records read from another store.
+func PreserveVersion() store.WriteOption {
+	return func(o *store.WriteOptions) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, preserveVersionKey{}, true)
+	}
+}
+
+// CleanupInterval sets how often the expired records are removed, which is when watchers
+// are notified of the expiry. Defaults to 5 minutes.
+func CleanupInterval(d time.Duration) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, cleanupKey{}, d)
+	}
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/store"
+)
+
+type memoryWatcher struct {
+	id      string
+	prefix  string
+	options store.WatchOptions
+	exit    chan bool
+	once    sync.Once
+
+	sync.Mutex
+	// events which haven't been returned by Next
+	events []*store.Event
+	// notifies Next of new events
+	notify chan bool
+}
+
+// Watch the records of the store for changes. Expired records are emitted when they're
+// removed by the janitor, see CleanupInterval.
+func (m *memoryStore) Watch(opts ...store.WatchOption) (store.Watcher, error) {
+	var options store.WatchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	w := &memoryWatcher{
+		id:      uuid.New().String(),
+		prefix:  m.prefix(options.Database, options.Table),
+		options: options,
+		exit:    make(chan bool),
+		notify:  make(chan bool, 1),
+	}
+
+	m.watchMtx.Lock()
+	m.watchers[w.id] = w
+	m.watchMtx.Unlock()
+
+	go func() {
+		<-w.exit
+		m.watchMtx.Lock()
+		delete(m.watchers, w.id)
+		m.watchMtx.Unlock()
+	}()
+
+	return w, nil
+}
+
+// emit sends the event to the watchers of the table
+func (m *memoryStore) emit(prefix string, typ store.EventType, r *store.Record) {
+	m.watchMtx.RLock()
+	defer m.watchMtx.RUnlock()
+
+	if len(m.watchers) == 0 {
+		return
+	}
+
+	ev := &store.Event{
+		Type:      typ,
+		Record:    r,
+		Timestamp: time.Now(),
+	}
+
+	for _, w := range m.watchers {
+		if w.prefix != prefix || !strings.HasPrefix(r.Key, w.options.Prefix) {
+			continue
+		}
+		w.push(ev)
+	}
+}
+
+// evicted emits the deletes of the table, records which are evicted after their
+// expiry are emitted as expired
+func (m *memoryStore) evicted(prefix string) func(string, interface{}) {
+	return func(key string, v interface{}) {
+		typ := store.Delete
+		if r, ok := v.(*storeRecord); ok && !r.expiresAt.IsZero() && !r.expiresAt.After(time.Now()) {
+			typ = store.Expired
+		}
+		m.emit(prefix, typ, &store.Record{Key: key})
+	}
+}
+
+func (w *memoryWatcher) push(ev *store.Event) {
+	w.Lock()
+	w.events = append(w.events, ev)
+	w.Unlock()
+
+	select {
+	case w.notify <- true:
+	default:
+	}
+}
+
+func (w *memoryWatcher) Next() (*store.Event, error) {
+	for {
+		w.Lock()
+		if len(w.events) > 0 {
+			ev := w.events[0]
+			w.events = w.events[1:]
+			w.Unlock()
+			return ev, nil
+		}
+		w.Unlock()
+
+		select {
+		case <-w.exit:
+			return nil, store.ErrWatcherStopped
+		case <-w.notify:
+		}
+	}
+}
+
+func (w *memoryWatcher) Stop() {
+	w.once.Do(func() {
+		close(w.exit)
+	})
+}
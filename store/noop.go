@@ -26,6 +26,14 @@ func (n *noopStore) Delete(key string, opts ...DeleteOption) error {
 	return nil
 }
 
+func (n *noopStore) BatchWrite(recs []*Record, opts ...WriteOption) error {
+	return nil
+}
+
+func (n *noopStore) BatchDelete(keys []string, opts ...DeleteOption) error {
+	return nil
+}
+
 func (n *noopStore) List(opts ...ListOption) ([]string, error) {
 	return []string{}, nil
 }
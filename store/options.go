@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"time"
 )
 
 // Options contains configuration for the Store
@@ -14,6 +15,8 @@ type Options struct {
 	Database string
 	// Table is analagous to a table in database backends or a key prefix in KV backends
 	Table string
+	// Indexes are the secondary indexes of the records which can be queried, if supported.
+	Indexes []Index
 	// Context should contain all implementation specific options, using context.WithValue.
 	Context context.Context
 }
@@ -44,6 +47,13 @@ func Table(t string) Option {
 	}
 }
 
+// Indexes declares the secondary indexes of the records, see Query
+func Indexes(i ...Index) Option {
+	return func(o *Options) {
+		o.Indexes = i
+	}
+}
+
 // WithContext sets the stores context, for any extra configuration
 func WithContext(c context.Context) Option {
 	return func(o *Options) {
@@ -107,6 +117,12 @@ func ReadOffset(o uint) ReadOption {
 // If Expiry and TTL are set TTL takes precedence
 type WriteOptions struct {
 	Database, Table string
+	// IfMatch only writes the record if its version matches the stored version
+	IfMatch bool
+	// IfNotExists only writes the record if the key doesn't exist
+	IfNotExists bool
+	// Context should contain all implementation specific options, using context.WithValue.
+	Context context.Context
 }
 
 // WriteOption sets values in WriteOptions
@@ -120,6 +136,21 @@ func WriteTo(database, table string) WriteOption {
 	}
 }
 
+// WriteIfMatch only writes the record if its version matches the stored version, ErrConflict
+// is returned otherwise. Use it to read, modify and write a record without a lock.
+func WriteIfMatch() WriteOption {
+	return func(w *WriteOptions) {
+		w.IfMatch = true
+	}
+}
+
+// WriteIfNotExists only writes the record if the key doesn't exist, ErrConflict is returned otherwise
+func WriteIfNotExists() WriteOption {
+	return func(w *WriteOptions) {
+		w.IfNotExists = true
+	}
+}
+
 // DeleteOptions configures an individual Delete operation
 type DeleteOptions struct {
 	Database, Table string
@@ -188,3 +219,126 @@ func ListOffset(o uint) ListOption {
 		l.Offset = o
 	}
 }
+
+// WatchOptions configures a Watch
+type WatchOptions struct {
+	// Watch the following
+	Database, Table string
+	// Prefix only watches the keys which are prefixed with it
+	Prefix string
+	// Interval is how often the records are polled by stores which can't watch natively
+	Interval time.Duration
+}
+
+// WatchOption sets values in WatchOptions
+type WatchOption func(w *WatchOptions)
+
+// WatchFrom the database and table
+func WatchFrom(database, table string) WatchOption {
+	return func(w *WatchOptions) {
+		w.Database = database
+		w.Table = table
+	}
+}
+
+// WatchPrefix only watches the keys which are prefixed with p
+func WatchPrefix(p string) WatchOption {
+	return func(w *WatchOptions) {
+		w.Prefix = p
+	}
+}
+
+// WatchInterval sets how often the records are polled by stores which can't watch natively
+func WatchInterval(d time.Duration) WatchOption {
+	return func(w *WatchOptions) {
+		w.Interval = d
+	}
+}
+
+// QueryOptions configures a Query
+type QueryOptions struct {
+	// Query the following
+	Database, Table string
+	// Equals returns the records with the indexed value
+	Equals interface{}
+	// Min and Max return the records with indexed values from Min inclusive to Max exclusive,
+	// either may be nil to leave the range unbounded
+	Min, Max interface{}
+	// Limit limits the number of returned records
+	Limit uint
+	// Offset when combined with Limit supports pagination
+	Offset uint
+}
+
+// QueryOption sets values in QueryOptions
+type QueryOption func(q *QueryOptions)
+
+// QueryFrom the database and table
+func QueryFrom(database, table string) QueryOption {
+	return func(q *QueryOptions) {
+		q.Database = database
+		q.Table = table
+	}
+}
+
+// QueryEquals returns the records with the indexed value
+func QueryEquals(v interface{}) QueryOption {
+	return func(q *QueryOptions) {
+		q.Equals = v
+	}
+}
+
+// QueryRange returns the records with indexed values from min inclusive to max exclusive,
+// either may be nil to leave the range unbounded
+func QueryRange(min, max interface{}) QueryOption {
+	return func(q *QueryOptions) {
+		q.Min = min
+		q.Max = max
+	}
+}
+
+// QueryLimit limits the number of returned records to l
+func QueryLimit(l uint) QueryOption {
+	return func(q *QueryOptions) {
+		q.Limit = l
+	}
+}
+
+// QueryOffset starts returning records from o. Use in conjunction with Limit for pagination.
+func QueryOffset(o uint) QueryOption {
+	return func(q *QueryOptions) {
+		q.Offset = o
+	}
+}
+
+// ExpireOptions configures an individual Expire operation
+type ExpireOptions struct {
+	Database, Table string
+}
+
+// ExpireOption sets values in ExpireOptions
+type ExpireOption func(e *ExpireOptions)
+
+// ExpireFrom the database and table
+func ExpireFrom(database, table string) ExpireOption {
+	return func(e *ExpireOptions) {
+		e.Database = database
+		e.Table = table
+	}
+}
+
+// IncrementOptions configures an individual Increment operation
+type IncrementOptions struct {
+	Database, Table string
+}
+
+// IncrementOption sets values in IncrementOptions
+type IncrementOption func(i *IncrementOptions)
+
+// IncrementFrom the database and table
+func IncrementFrom(database, table string) IncrementOption {
+	return func(i *IncrementOptions) {
+		i.Database = database
+		i.Table = table
+	}
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+)
+
+type pollWatcher struct {
+	store   Store
+	options WatchOptions
+	exit    chan bool
+	once    sync.Once
+
+	// the records seen by the last poll
+	records map[string]*Record
+
+	sync.Mutex
+	// events which haven't been returned by Next
+	events []*Event
+	// notifies Next of new events
+	notify chan bool
+}
+
+// Poll returns a watcher which reads the records every interval and compares them to the previous
+// read. It works with any store but changes made and reverted between polls aren't seen.
+func Poll(s Store, opts ...WatchOption) (Watcher, error) {
+	options := WatchOptions{
+		Interval: DefaultWatchInterval,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	w := &pollWatcher{
+		store:   s,
+		options: options,
+		exit:    make(chan bool),
+		notify:  make(chan bool, 1),
+	}
+
+	// the changes are relative to when the watch started
+	records, err := w.read()
+	if err != nil {
+		return nil, err
+	}
+	w.records = records
+
+	go w.run()
+
+	return w, nil
+}
+
+func (w *pollWatcher) read() (map[string]*Record, error) {
+	recs, err := w.store.Read(w.options.Prefix, ReadPrefix(), ReadFrom(w.options.Database, w.options.Table))
+	if err != nil && err != ErrNotFound {
+		return nil, err
+	}
+
+	records := make(map[string]*Record, len(recs))
+	for _, r := range recs {
+		records[r.Key] = r
+	}
+	return records, nil
+}
+
+// changed compares the versions of the records if they're known otherwise the values
+func changed(a, b *Record) bool {
+	if a.Version > 0 && b.Version > 0 {
+		return a.Version != b.Version
+	}
+	return !bytes.Equal(a.Value, b.Value)
+}
+
+func (w *pollWatcher) poll() {
+	records, err := w.read()
+	if err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error polling the %s store: %v", w.store.String(), err)
+		}
+		return
+	}
+
+	now := time.Now()
+	var events []*Event
+
+	for key, rec := range records {
+		prev, ok := w.records[key]
+		if !ok {
+			events = append(events, &Event{Type: Create, Record: rec, Timestamp: now})
+		} else if changed(prev, rec) {
+			events = append(events, &Event{Type: Update, Record: rec, Timestamp: now})
+		}
+	}
+
+	for key := range w.records {
+		if _, ok := records[key]; !ok {
+			events = append(events, &Event{Type: Delete, Record: &Record{Key: key}, Timestamp: now})
+		}
+	}
+
+	w.records = records
+
+	if len(events) == 0 {
+		return
+	}
+
+	w.Lock()
+	w.events = append(w.events, events...)
+	w.Unlock()
+
+	select {
+	case w.notify <- true:
+	default:
+	}
+}
+
+func (w *pollWatcher) run() {
+	t := time.NewTicker(w.options.Interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-w.exit:
+			return
+		case <-t.C:
+			w.poll()
+		}
+	}
+}
+
+func (w *pollWatcher) Next() (*Event, error) {
+	for {
+		w.Lock()
+		if len(w.events) > 0 {
+			ev := w.events[0]
+			w.events = w.events[1:]
+			w.Unlock()
+			return ev, nil
+		}
+		w.Unlock()
+
+		select {
+		case <-w.exit:
+			return nil, ErrWatcherStopped
+		case <-w.notify:
+		}
+	}
+}
+
+func (w *pollWatcher) Stop() {
+	w.once.Do(func() {
+		close(w.exit)
+	})
+}
@@ -0,0 +1,237 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrUnknownIndex is returned when querying an index which hasn't been declared
+	ErrUnknownIndex = errors.New("unknown index")
+	// ErrInvalidValue is returned when a value can't be indexed, only strings, numbers and bools can be
+	ErrInvalidValue = errors.New("invalid index value")
+)
+
+// Index is a secondary index of the records
+type Index struct {
+	// Name of the index used to query it
+	Name string
+	// Field is the metadata key which is indexed or a json path of the value
+	// prefixed with $. e.g $.user.email
+	Field string
+}
+
+// Queryable is implemented by stores which can natively query their indexes
+type Queryable interface {
+	// Query the records by the value of an index
+	Query(index string, opts ...QueryOption) ([]*Record, error)
+}
+
+// Index returns the declared index with the name
+func (o Options) Index(name string) (Index, bool) {
+	for _, i := range o.Indexes {
+		if i.Name == name {
+			return i, true
+		}
+	}
+	return Index{}, false
+}
+
+// Path returns the json path of the value if the field is one
+func (i Index) Path() ([]string, bool) {
+	if !strings.HasPrefix(i.Field, "$.") {
+		return nil, false
+	}
+	return strings.Split(i.Field[2:], "."), true
+}
+
+// Value returns the indexed value of the record, false if the record doesn't have one
+func (i Index) Value(r *Record) (interface{}, bool) {
+	path, ok := i.Path()
+	if !ok {
+		return normalize(r.Metadata[i.Field])
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(r.Value, &v); err != nil {
+		return nil, false
+	}
+	for _, p := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		v = m[p]
+	}
+
+	return normalize(v)
+}
+
+// normalize converts the numbers to float64 so they're compared the same as json numbers
+func normalize(v interface{}) (interface{}, bool) {
+	switch t := v.(type) {
+	case string, bool, float64:
+		return t, true
+	case float32:
+		return float64(t), true
+	case int:
+		return float64(t), true
+	case int8:
+		return float64(t), true
+	case int16:
+		return float64(t), true
+	case int32:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case uint:
+		return float64(t), true
+	case uint8:
+		return float64(t), true
+	case uint16:
+		return float64(t), true
+	case uint32:
+		return float64(t), true
+	case uint64:
+		return float64(t), true
+	case json.Number:
+		f, err := t.Float64()
+		return f, err == nil
+	default:
+		return nil, false
+	}
+}
+
+// EncodeValue encodes the value so the encoded values sort in the same order as the values.
+// Strings sort before numbers which sort before bools, the same as jsonb. The encoding is hex
+// so it can be used in keys.
+func EncodeValue(v interface{}) (string, error) {
+	v, ok := normalize(v)
+	if !ok {
+		return "", ErrInvalidValue
+	}
+
+	var b []byte
+
+	switch t := v.(type) {
+	case bool:
+		b = []byte{3, 0}
+		if t {
+			b[1] = 1
+		}
+	case float64:
+		// flip the sign bit of positive numbers and all the bits of negative numbers
+		// so the bytes sort in numeric order
+		bits := math.Float64bits(t)
+		if bits&(1<<63) == 0 {
+			bits ^= 1 << 63
+		} else {
+			bits = ^bits
+		}
+		b = make([]byte, 9)
+		b[0] = 2
+		binary.BigEndian.PutUint64(b[1:], bits)
+	case string:
+		b = append([]byte{1}, t...)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Matches returns true if the value matches the conditions of the query
+func (q QueryOptions) Matches(v interface{}) bool {
+	enc, err := EncodeValue(v)
+	if err != nil {
+		return false
+	}
+
+	if q.Equals != nil {
+		eq, err := EncodeValue(q.Equals)
+		if err != nil || enc != eq {
+			return false
+		}
+	}
+	if q.Min != nil {
+		min, err := EncodeValue(q.Min)
+		if err != nil || enc < min {
+			return false
+		}
+	}
+	if q.Max != nil {
+		max, err := EncodeValue(q.Max)
+		if err != nil || enc >= max {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Query the records of the store by the value of an index ordered by the value and then the key.
+// Stores which aren't Queryable are scanned, use the index package to maintain indexes in KV stores.
+func Query(s Store, index string, opts ...QueryOption) ([]*Record, error) {
+	if q, ok := s.(Queryable); ok {
+		return q.Query(index, opts...)
+	}
+
+	idx, ok := s.Options().Index(index)
+	if !ok {
+		return nil, ErrUnknownIndex
+	}
+
+	var options QueryOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	recs, err := s.Read("", ReadPrefix(), ReadFrom(options.Database, options.Table))
+	if err != nil && err != ErrNotFound {
+		return nil, err
+	}
+
+	type match struct {
+		value  string
+		record *Record
+	}
+	var matches []match
+
+	for _, r := range recs {
+		v, ok := idx.Value(r)
+		if !ok || !options.Matches(v) {
+			continue
+		}
+		enc, _ := EncodeValue(v)
+		matches = append(matches, match{enc, r})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].value == matches[j].value {
+			return matches[i].record.Key < matches[j].record.Key
+		}
+		return matches[i].value < matches[j].value
+	})
+
+	records := make([]*Record, 0, len(matches))
+	for _, m := range matches {
+		records = append(records, m.record)
+	}
+
+	return Paginate(records, options.Limit, options.Offset), nil
+}
+
+// Paginate returns the records from the offset up to the limit, a limit of zero is unlimited
+func Paginate(recs []*Record, limit, offset uint) []*Record {
+	if offset >= uint(len(recs)) {
+		return []*Record{}
+	}
+	recs = recs[offset:]
+	if limit > 0 && limit < uint(len(recs)) {
+		recs = recs[:limit]
+	}
+	return recs
+}
@@ -0,0 +1,59 @@
+package quota
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+// Limits of a table, zero is unlimited
+type Limits struct {
+	// Records is the maximum number of records
+	Records int64
+	// Bytes is the maximum total size of the keys and values of the records
+	Bytes int64
+}
+
+type limitsKey struct{}
+
+type defaultLimitsKey struct{}
+
+type refreshKey struct{}
+
+// Limit sets the limits of the table of the database
+func Limit(database, table string, l Limits) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		limits, _ := o.Context.Value(limitsKey{}).(map[string]Limits)
+		cp := make(map[string]Limits, len(limits)+1)
+		for k, v := range limits {
+			cp[k] = v
+		}
+		cp[database+"/"+table] = l
+		o.Context = context.WithValue(o.Context, limitsKey{}, cp)
+	}
+}
+
+// DefaultLimits sets the limits of the tables without their own limits
+func DefaultLimits(l Limits) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, defaultLimitsKey{}, l)
+	}
+}
+
+// RefreshInterval sets how often the usage of a table is recounted. The usage is updated by
+// the writes and deletes in between, the recount includes expired records and other writers.
+func RefreshInterval(d time.Duration) store.Option {
+	return func(o *store.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, refreshKey{}, d)
+	}
+}
@@ -0,0 +1,346 @@
+// Package quota is a store which limits the number of records and bytes of the tables of
+// another store so a single tenant can't exhaust a shared store
+package quota
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+var (
+	// ErrQuotaExceeded is returned when a write would exceed the limits of the table
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// DefaultRefreshInterval is how often the usage of a table is recounted
+	DefaultRefreshInterval = time.Minute
+)
+
+// usage of a table, locked while a write of the table checks and updates it
+type usage struct {
+	sync.Mutex
+	records int64
+	bytes   int64
+	counted time.Time
+	// set while the table is being recounted in the background
+	counting bool
+}
+
+type quotaStore struct {
+	s store.Store
+
+	// protects the options and the usage of the tables
+	sync.RWMutex
+	options  store.Options
+	limits   map[string]Limits
+	defaults Limits
+	refresh  time.Duration
+	usage    map[string]*usage
+}
+
+// NewStore returns a store which enforces the limits of the tables on writes. The usage is
+// counted by this store so writes by other stores are only seen when it's recounted.
+func NewStore(s store.Store, opts ...store.Option) store.Store {
+	q := &quotaStore{
+		s:     s,
+		usage: make(map[string]*usage),
+	}
+	q.configure(opts...)
+	return q
+}
+
+func (q *quotaStore) configure(opts ...store.Option) {
+	for _, o := range opts {
+		o(&q.options)
+	}
+
+	q.limits = nil
+	q.defaults = Limits{}
+	q.refresh = DefaultRefreshInterval
+
+	if q.options.Context != nil {
+		if l, ok := q.options.Context.Value(limitsKey{}).(map[string]Limits); ok {
+			q.limits = l
+		}
+		if l, ok := q.options.Context.Value(defaultLimitsKey{}).(Limits); ok {
+			q.defaults = l
+		}
+		if d, ok := q.options.Context.Value(refreshKey{}).(time.Duration); ok && d > 0 {
+			q.refresh = d
+		}
+	}
+}
+
+// table returns the database and table with the defaults of the store
+func (q *quotaStore) table(database, table string) (string, string) {
+	opts := q.s.Options()
+	if len(database) == 0 {
+		database = opts.Database
+	}
+	if len(table) == 0 {
+		table = opts.Table
+	}
+	return database, table
+}
+
+// limit returns the limits of the table and its usage, the usage of unlimited tables is nil
+func (q *quotaStore) limit(database, table string) (Limits, *usage) {
+	name := database + "/" + table
+
+	q.RLock()
+	l, ok := q.limits[name]
+	if !ok {
+		l = q.defaults
+	}
+	u := q.usage[name]
+	q.RUnlock()
+
+	if l.Records == 0 && l.Bytes == 0 {
+		return l, nil
+	}
+	if u != nil {
+		return l, u
+	}
+
+	q.Lock()
+	defer q.Unlock()
+	if u = q.usage[name]; u == nil {
+		u = new(usage)
+		q.usage[name] = u
+	}
+	return l, u
+}
+
+func size(r *store.Record) int64 {
+	return int64(len(r.Key) + len(r.Value))
+}
+
+// count reads the whole table and returns its number of records and bytes
+func (q *quotaStore) count(database, table string) (int64, int64, error) {
+	recs, err := q.s.Read("", store.ReadPrefix(), store.ReadFrom(database, table))
+	if err != nil && err != store.ErrNotFound {
+		return 0, 0, err
+	}
+
+	var records, bytes int64
+	for _, r := range recs {
+		records++
+		bytes += size(r)
+	}
+	return records, bytes, nil
+}
+
+// recount counts the table the first time it's written, the usage is updated by the writes and
+// deletes after that and recounted in the background once it's older than the refresh interval
+// to pick up expired records and other writers. The usage must be locked.
+func (q *quotaStore) recount(database, table string, u *usage) error {
+	if u.counted.IsZero() {
+		records, bytes, err := q.count(database, table)
+		if err != nil {
+			return err
+		}
+		u.records, u.bytes, u.counted = records, bytes, time.Now()
+		return nil
+	}
+
+	q.RLock()
+	refresh := q.refresh
+	q.RUnlock()
+
+	if u.counting || time.Since(u.counted) < refresh {
+		return nil
+	}
+
+	u.counting = true
+	go func() {
+		records, bytes, err := q.count(database, table)
+
+		u.Lock()
+		defer u.Unlock()
+		u.counting = false
+		if err != nil {
+			return
+		}
+		u.records, u.bytes, u.counted = records, bytes, time.Now()
+	}()
+
+	return nil
+}
+
+// current returns the size of the current record of the key, false if it doesn't exist
+func (q *quotaStore) current(key, database, table string) (int64, bool, error) {
+	recs, err := q.s.Read(key, store.ReadFrom(database, table))
+	if err == store.ErrNotFound || (err == nil && len(recs) == 0) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	return size(recs[0]), true, nil
+}
+
+func (q *quotaStore) Init(opts ...store.Option) error {
+	q.Lock()
+	q.configure(opts...)
+	q.usage = make(map[string]*usage)
+	q.Unlock()
+
+	return q.s.Init(opts...)
+}
+
+func (q *quotaStore) Options() store.Options {
+	q.RLock()
+	defer q.RUnlock()
+	return q.options
+}
+
+func (q *quotaStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	return q.s.Read(key, opts...)
+}
+
+func (q *quotaStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	return q.write([]*store.Record{r}, opts, func() error {
+		return q.s.Write(r, opts...)
+	})
+}
+
+// BatchWrite writes the records if the table stays within its limits, ErrQuotaExceeded is
+// returned otherwise. Records replacing existing records only count the change in size.
+func (q *quotaStore) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	return q.write(recs, opts, func() error {
+		return q.s.BatchWrite(recs, opts...)
+	})
+}
+
+// write checks the records against the limits of the table and updates its usage once
+// they're written by the write func. The writes of a table are serialised so the limits
+// are checked against its current usage, the unlimited tables are written straight away.
+func (q *quotaStore) write(recs []*store.Record, opts []store.WriteOption, write func() error) error {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	database, table := q.table(options.Database, options.Table)
+
+	limit, u := q.limit(database, table)
+	if u == nil {
+		return write()
+	}
+
+	u.Lock()
+	defer u.Unlock()
+
+	if err := q.recount(database, table, u); err != nil {
+		return err
+	}
+
+	var records, bytes int64
+	seen := make(map[string]bool, len(recs))
+
+	// the last write of a key in the batch replaces the others
+	for i := len(recs) - 1; i >= 0; i-- {
+		r := recs[i]
+		if seen[r.Key] {
+			continue
+		}
+		seen[r.Key] = true
+
+		prev, exists, err := q.current(r.Key, database, table)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			records++
+		}
+		bytes += size(r) - prev
+	}
+
+	if limit.Records > 0 && records > 0 && u.records+records > limit.Records {
+		return ErrQuotaExceeded
+	}
+	if limit.Bytes > 0 && bytes > 0 && u.bytes+bytes > limit.Bytes {
+		return ErrQuotaExceeded
+	}
+
+	if err := write(); err != nil {
+		return err
+	}
+
+	u.records += records
+	u.bytes += bytes
+
+	return nil
+}
+
+func (q *quotaStore) Delete(key string, opts ...store.DeleteOption) error {
+	return q.delete([]string{key}, opts, func() error {
+		return q.s.Delete(key, opts...)
+	})
+}
+
+func (q *quotaStore) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	return q.delete(keys, opts, func() error {
+		return q.s.BatchDelete(keys, opts...)
+	})
+}
+
+// delete removes the records with the delete func and frees up their usage of the table
+func (q *quotaStore) delete(keys []string, opts []store.DeleteOption, del func() error) error {
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	database, table := q.table(options.Database, options.Table)
+
+	_, u := q.limit(database, table)
+	if u == nil {
+		return del()
+	}
+
+	u.Lock()
+	defer u.Unlock()
+
+	if err := q.recount(database, table, u); err != nil {
+		return err
+	}
+
+	var records, bytes int64
+	seen := make(map[string]bool, len(keys))
+
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		prev, exists, err := q.current(key, database, table)
+		if err != nil {
+			return err
+		}
+		if exists {
+			records++
+			bytes += prev
+		}
+	}
+
+	if err := del(); err != nil {
+		return err
+	}
+
+	u.records -= records
+	u.bytes -= bytes
+
+	return nil
+}
+
+func (q *quotaStore) List(opts ...store.ListOption) ([]string, error) {
+	return q.s.List(opts...)
+}
+
+func (q *quotaStore) Close() error {
+	return q.s.Close()
+}
+
+func (q *quotaStore) String() string {
+	return q.s.String()
+}
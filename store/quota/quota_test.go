@@ -0,0 +1,91 @@
+package quota
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/store/memory"
+)
+
+func TestQuotaStore(t *testing.T) {
+	m := memory.NewStore()
+	s := NewStore(m,
+		Limit("micro", "small", Limits{Records: 2, Bytes: 10}),
+		DefaultLimits(Limits{Records: 1}),
+		RefreshInterval(time.Hour),
+	)
+
+	small := store.WriteTo("micro", "small")
+
+	if err := s.Write(&store.Record{Key: "a", Value: []byte("123")}, small); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(&store.Record{Key: "b", Value: []byte("123")}, small); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(&store.Record{Key: "c", Value: []byte("1")}, small); err != ErrQuotaExceeded {
+		t.Fatalf("Expected %v got %v", ErrQuotaExceeded, err)
+	}
+
+	// replacing a record only counts the change in size
+	if err := s.Write(&store.Record{Key: "a", Value: []byte("1234")}, small); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(&store.Record{Key: "a", Value: []byte("1234567")}, small); err != ErrQuotaExceeded {
+		t.Fatalf("Expected %v got %v", ErrQuotaExceeded, err)
+	}
+
+	// deletes free up the quota
+	if err := s.Delete("b", store.DeleteFrom("micro", "small")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(&store.Record{Key: "c", Value: []byte("1")}, small); err != nil {
+		t.Fatal(err)
+	}
+
+	// the default limits apply to the other tables
+	if err := s.Write(&store.Record{Key: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.BatchWrite([]*store.Record{{Key: "b"}, {Key: "c"}}); err != ErrQuotaExceeded {
+		t.Fatalf("Expected %v got %v", ErrQuotaExceeded, err)
+	}
+	if _, err := m.Read("b"); err != store.ErrNotFound {
+		t.Fatal("Expected the batch not to be written")
+	}
+
+	// records written to the store directly are counted by a new store
+	if err := m.Write(&store.Record{Key: "d", Value: []byte("1")}, small); err != nil {
+		t.Fatal(err)
+	}
+	s = NewStore(m, Limit("micro", "small", Limits{Records: 3}))
+	if err := s.Write(&store.Record{Key: "e"}, small); err != ErrQuotaExceeded {
+		t.Fatalf("Expected %v got %v", ErrQuotaExceeded, err)
+	}
+}
+
+// batchless fails the batch writes so the tests can tell the writes apart
+type batchless struct {
+	store.Store
+}
+
+func (b *batchless) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	return errors.New("batch write")
+}
+
+func TestQuotaStoreWrite(t *testing.T) {
+	s := NewStore(&batchless{memory.NewStore()}, Limit("micro", "small", Limits{Records: 1}))
+
+	// the writes of both the unlimited and limited tables are passed to write
+	if err := s.Write(&store.Record{Key: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(&store.Record{Key: "a"}, store.WriteTo("micro", "small")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(&store.Record{Key: "b"}, store.WriteTo("micro", "small")); err != ErrQuotaExceeded {
+		t.Fatalf("Expected %v got %v", ErrQuotaExceeded, err)
+	}
+}
@@ -0,0 +1,26 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/store"
+)
+
+type compareKey struct{}
+
+// compare is the value a write is conditional on
+type compare struct {
+	value []byte
+}
+
+// CompareAndSwap only writes the record if the current value of the key matches the
+// value, atomically. A nil value requires the key not to exist. ErrConflict is returned
+// if the value doesn't match.
+func CompareAndSwap(value []byte) store.WriteOption {
+	return func(o *store.WriteOptions) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, compareKey{}, &compare{value: value})
+	}
+}
@@ -0,0 +1,480 @@
+// Package redis implements the redis store
+package redis
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/store"
+)
+
+// DefaultDatabase and DefaultTable are used if none are provided,
+// DefaultAddress is used if there are no nodes.
+var (
+	DefaultDatabase = "micro"
+	DefaultTable    = "micro"
+	DefaultAddress  = "127.0.0.1:6379"
+)
+
+var (
+	// ErrConflict is returned when a conditional write doesn't match the current record
+	ErrConflict = store.ErrConflict
+)
+
+const (
+	// the number of keys requested per SCAN
+	scanCount = 100
+
+	// the write modes of the script
+	writeAlways   = "0"
+	writeIfAbsent = "1"
+	writeIfEqual  = "2"
+	writeIfMatch  = "3"
+)
+
+// records are stored as hashes so the value, metadata and version are written together,
+// all the fields are set by every write so the hash is updated in place rather than deleted.
+// The script checks the conditions of all the records before replacing any of them so
+// either all the records are written or none are. Each record has five arguments, the
+// write mode, the expected value or version, the value, the metadata and the ttl.
+var writeScript = redis.NewScript(`
+for i = 1, #KEYS do
+	local a = (i - 1) * 5
+	local mode = ARGV[a + 1]
+	if mode == '1' then
+		if redis.call('EXISTS', KEYS[i]) == 1 then
+			return 0
+		end
+	elseif mode == '2' then
+		if redis.call('HGET', KEYS[i], 'value') ~= ARGV[a + 2] then
+			return 0
+		end
+	elseif mode == '3' then
+		if (redis.call('HGET', KEYS[i], 'version') or '0') ~= ARGV[a + 2] then
+			return 0
+		end
+	end
+end
+for i = 1, #KEYS do
+	local a = (i - 1) * 5
+	local version = tonumber(redis.call('HGET', KEYS[i], 'version') or '0') + 1
+	redis.call('HSET', KEYS[i], 'value', ARGV[a + 3], 'metadata', ARGV[a + 4], 'version', version)
+	if tonumber(ARGV[a + 5]) > 0 then
+		redis.call('PEXPIRE', KEYS[i], ARGV[a + 5])
+	else
+		redis.call('PERSIST', KEYS[i])
+	end
+end
+return 1
+`)
+
+// expireScript sets the expiry of the key if it exists, a ttl of zero removes the expiry
+var expireScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+if tonumber(ARGV[1]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+else
+	redis.call('PERSIST', KEYS[1])
+end
+return 1
+`)
+
+// incrementScript increments the value of the counter and its version, HINCRBY fails if the
+// value isn't an integer
+var incrementScript = redis.NewScript(`
+local value = redis.call('HINCRBY', KEYS[1], 'value', ARGV[1])
+redis.call('HINCRBY', KEYS[1], 'version', 1)
+return value
+`)
+
+type redisStore struct {
+	options store.Options
+	client  *redis.Client
+}
+
+// NewStore returns a redis store. The nodes are either an address or a redis:// url.
+func NewStore(opts ...store.Option) store.Store {
+	options := store.Options{
+		Database: DefaultDatabase,
+		Table:    DefaultTable,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	s := &redisStore{options: options}
+
+	// best-effort configure the store
+	if err := s.configure(); err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error("Error configuring store ", err)
+		}
+	}
+
+	return s
+}
+
+func (r *redisStore) configure() error {
+	addr := DefaultAddress
+	if len(r.options.Nodes) > 0 {
+		addr = r.options.Nodes[0]
+	}
+
+	var opts *redis.Options
+
+	if strings.HasPrefix(addr, "redis://") || strings.HasPrefix(addr, "rediss://") {
+		o, err := redis.ParseURL(addr)
+		if err != nil {
+			return err
+		}
+		opts = o
+	} else {
+		opts = &redis.Options{Addr: addr}
+	}
+
+	client := redis.NewClient(opts)
+
+	if r.client != nil {
+		r.client.Close()
+	}
+	r.client = client
+
+	return client.Ping().Err()
+}
+
+// prefix returns the key prefix of the database and table
+func (r *redisStore) prefix(database, table string) string {
+	if len(database) == 0 {
+		database = r.options.Database
+	}
+	if len(table) == 0 {
+		table = r.options.Table
+	}
+	return database + "/" + table + "/"
+}
+
+// escape the glob characters of a SCAN pattern
+var escaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// keys returns the sorted keys in the prefix matching the filters without the prefix
+func (r *redisStore) keys(prefix, prefixFilter, suffixFilter string, limit, offset uint) ([]string, error) {
+	match := escaper.Replace(prefix+prefixFilter) + "*" + escaper.Replace(suffixFilter)
+
+	var keys []string
+	var cursor uint64
+
+	for {
+		found, next, err := r.client.Scan(cursor, match, scanCount).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range found {
+			keys = append(keys, strings.TrimPrefix(k, prefix))
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	sort.Strings(keys)
+
+	// keys may be returned more than once by SCAN
+	unique := keys[:0]
+	for i, k := range keys {
+		if i > 0 && keys[i-1] == k {
+			continue
+		}
+		unique = append(unique, k)
+	}
+	keys = unique
+
+	if offset > 0 {
+		if offset >= uint(len(keys)) {
+			return []string{}, nil
+		}
+		keys = keys[offset:]
+	}
+	if limit > 0 && limit < uint(len(keys)) {
+		keys = keys[:limit]
+	}
+
+	return keys, nil
+}
+
+// read the records of the keys, records which have expired since they were listed are skipped
+func (r *redisStore) read(prefix string, keys []string) ([]*store.Record, error) {
+	pipe := r.client.Pipeline()
+
+	hashes := make([]*redis.StringStringMapCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+
+	for i, k := range keys {
+		hashes[i] = pipe.HGetAll(prefix + k)
+		ttls[i] = pipe.PTTL(prefix + k)
+	}
+
+	if _, err := pipe.Exec(); err != nil {
+		return nil, err
+	}
+
+	records := make([]*store.Record, 0, len(keys))
+
+	for i, k := range keys {
+		fields := hashes[i].Val()
+		if len(fields) == 0 {
+			continue
+		}
+
+		rec := &store.Record{
+			Key:      k,
+			Value:    []byte(fields["value"]),
+			Metadata: make(map[string]interface{}),
+		}
+
+		if md := fields["metadata"]; len(md) > 0 {
+			if err := json.Unmarshal([]byte(md), &rec.Metadata); err != nil {
+				return nil, err
+			}
+		}
+
+		if v := fields["version"]; len(v) > 0 {
+			rec.Version, _ = strconv.ParseUint(v, 10, 64)
+		}
+
+		// a negative ttl means the key has no expiry
+		if ttl := ttls[i].Val(); ttl > 0 {
+			rec.Expiry = ttl
+		}
+
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+// encode returns the metadata and expiry of the record to store
+func encode(rec *store.Record) ([]byte, time.Duration, error) {
+	md, err := json.Marshal(rec.Metadata)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var ttl time.Duration
+	if rec.Expiry > 0 {
+		// redis expiry has millisecond precision
+		ttl = rec.Expiry.Truncate(time.Millisecond)
+		if ttl == 0 {
+			ttl = time.Millisecond
+		}
+	}
+
+	return md, ttl, nil
+}
+
+func (r *redisStore) Init(opts ...store.Option) error {
+	for _, o := range opts {
+		o(&r.options)
+	}
+	// reconfigure
+	return r.configure()
+}
+
+func (r *redisStore) Options() store.Options {
+	return r.options
+}
+
+func (r *redisStore) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var options store.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	prefix := r.prefix(options.Database, options.Table)
+
+	if !options.Prefix && !options.Suffix {
+		records, err := r.read(prefix, []string{key})
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, store.ErrNotFound
+		}
+		return records, nil
+	}
+
+	var prefixFilter, suffixFilter string
+	if options.Prefix {
+		prefixFilter = key
+	}
+	if options.Suffix {
+		suffixFilter = key
+	}
+
+	keys, err := r.keys(prefix, prefixFilter, suffixFilter, options.Limit, options.Offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return []*store.Record{}, nil
+	}
+
+	return r.read(prefix, keys)
+}
+
+func (r *redisStore) Write(rec *store.Record, opts ...store.WriteOption) error {
+	return r.BatchWrite([]*store.Record{rec}, opts...)
+}
+
+// BatchWrite writes the records atomically, the conditions of the options are checked for every record
+func (r *redisStore) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	if len(recs) == 0 {
+		return nil
+	}
+
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	prefix := r.prefix(options.Database, options.Table)
+
+	var cas *compare
+	if options.Context != nil {
+		cas, _ = options.Context.Value(compareKey{}).(*compare)
+	}
+
+	keys := make([]string, 0, len(recs))
+	args := make([]interface{}, 0, len(recs)*5)
+
+	for _, rec := range recs {
+		md, ttl, err := encode(rec)
+		if err != nil {
+			return err
+		}
+
+		mode := writeAlways
+		var expected []byte
+
+		switch {
+		case options.IfNotExists:
+			mode = writeIfAbsent
+		case options.IfMatch:
+			mode = writeIfMatch
+			expected = []byte(strconv.FormatUint(rec.Version, 10))
+		case cas != nil && cas.value == nil:
+			mode = writeIfAbsent
+		case cas != nil:
+			mode = writeIfEqual
+			expected = cas.value
+		}
+
+		keys = append(keys, prefix+rec.Key)
+		args = append(args, mode, expected, rec.Value, md, strconv.FormatInt(int64(ttl/time.Millisecond), 10))
+	}
+
+	ok, err := writeScript.Run(r.client, keys, args...).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+// BatchDelete deletes the keys in a single command
+func (r *redisStore) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	prefix := r.prefix(options.Database, options.Table)
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = prefix + key
+	}
+
+	return r.client.Del(prefixed...).Err()
+}
+
+func (r *redisStore) Delete(key string, opts ...store.DeleteOption) error {
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	prefix := r.prefix(options.Database, options.Table)
+	return r.client.Del(prefix + key).Err()
+}
+
+// Expire sets the expiry of the key without changing the version
+func (r *redisStore) Expire(key string, ttl time.Duration, opts ...store.ExpireOption) error {
+	var options store.ExpireOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	_, ttl, _ = encode(&store.Record{Expiry: ttl})
+
+	prefix := r.prefix(options.Database, options.Table)
+	ok, err := expireScript.Run(r.client, []string{prefix + key}, strconv.FormatInt(int64(ttl/time.Millisecond), 10)).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// Increment the counter atomically
+func (r *redisStore) Increment(key string, delta int64, opts ...store.IncrementOption) (int64, error) {
+	var options store.IncrementOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	prefix := r.prefix(options.Database, options.Table)
+	value, err := incrementScript.Run(r.client, []string{prefix + key}, strconv.FormatInt(delta, 10)).Int64()
+	if err != nil && strings.Contains(err.Error(), "not an integer") {
+		return 0, store.ErrNotCounter
+	}
+	return value, err
+}
+
+func (r *redisStore) List(opts ...store.ListOption) ([]string, error) {
+	var options store.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	prefix := r.prefix(options.Database, options.Table)
+	return r.keys(prefix, options.Prefix, options.Suffix, options.Limit, options.Offset)
+}
+
+func (r *redisStore) Close() error {
+	if r.client != nil {
+		return r.client.Close()
+	}
+	return nil
+}
+
+func (r *redisStore) String() string {
+	return "redis"
+}
@@ -0,0 +1,354 @@
+package redis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/micro/go-micro/v3/store"
+)
+
+func newTestStore(t *testing.T) (*miniredis.Miniredis, store.Store) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mr, NewStore(store.Nodes(mr.Addr()))
+}
+
+func TestRedisStore(t *testing.T) {
+	mr, s := newTestStore(t)
+	defer mr.Close()
+	defer s.Close()
+
+	if _, err := s.Read("foo"); err != store.ErrNotFound {
+		t.Fatalf("Expected %v got %v", store.ErrNotFound, err)
+	}
+
+	rec := &store.Record{
+		Key:      "foo",
+		Value:    []byte("bar"),
+		Metadata: map[string]interface{}{"baz": "qux"},
+	}
+	if err := s.Write(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := s.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || string(recs[0].Value) != "bar" || recs[0].Expiry != 0 {
+		t.Fatalf("Unexpected records %+v", recs)
+	}
+	if !reflect.DeepEqual(recs[0].Metadata, rec.Metadata) {
+		t.Fatalf("Expected metadata %v got %v", rec.Metadata, recs[0].Metadata)
+	}
+
+	// records in other tables are isolated
+	if _, err := s.Read("foo", store.ReadFrom("micro", "other")); err != store.ErrNotFound {
+		t.Fatalf("Expected %v got %v", store.ErrNotFound, err)
+	}
+
+	if err := s.Delete("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Read("foo"); err != store.ErrNotFound {
+		t.Fatalf("Expected %v got %v", store.ErrNotFound, err)
+	}
+}
+
+func TestRedisStoreExpiry(t *testing.T) {
+	mr, s := newTestStore(t)
+	defer mr.Close()
+	defer s.Close()
+
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("bar"), Expiry: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := s.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recs[0].Expiry <= 0 || recs[0].Expiry > time.Minute {
+		t.Fatalf("Expected an expiry of up to a minute got %v", recs[0].Expiry)
+	}
+
+	mr.FastForward(time.Minute)
+
+	if _, err := s.Read("foo"); err != store.ErrNotFound {
+		t.Fatalf("Expected %v got %v", store.ErrNotFound, err)
+	}
+}
+
+func TestRedisStoreList(t *testing.T) {
+	mr, s := newTestStore(t)
+	defer mr.Close()
+	defer s.Close()
+
+	for _, k := range []string{"a/1", "a/2", "a/3", "b/1", "b*"} {
+		if err := s.Write(&store.Record{Key: k, Value: []byte(k)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		opts []store.ListOption
+		keys []string
+	}{
+		{nil, []string{"a/1", "a/2", "a/3", "b*", "b/1"}},
+		{[]store.ListOption{store.ListPrefix("a/")}, []string{"a/1", "a/2", "a/3"}},
+		{[]store.ListOption{store.ListSuffix("/1")}, []string{"a/1", "b/1"}},
+		{[]store.ListOption{store.ListPrefix("b*")}, []string{"b*"}},
+		{[]store.ListOption{store.ListPrefix("a/"), store.ListLimit(2), store.ListOffset(1)}, []string{"a/2", "a/3"}},
+		{[]store.ListOption{store.ListPrefix("c")}, []string{}},
+	}
+
+	for _, tt := range tests {
+		keys, err := s.List(tt.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(keys) != len(tt.keys) || (len(keys) > 0 && !reflect.DeepEqual(keys, tt.keys)) {
+			t.Fatalf("Expected %v got %v", tt.keys, keys)
+		}
+	}
+
+	recs, err := s.Read("a/", store.ReadPrefix(), store.ReadLimit(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 || recs[0].Key != "a/1" || string(recs[1].Value) != "a/2" {
+		t.Fatalf("Unexpected records %+v", recs)
+	}
+}
+
+func TestRedisStoreCompareAndSwap(t *testing.T) {
+	mr, s := newTestStore(t)
+	defer mr.Close()
+	defer s.Close()
+
+	// create only if the key doesn't exist
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("1")}, CompareAndSwap(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("2")}, CompareAndSwap(nil)); err != ErrConflict {
+		t.Fatalf("Expected %v got %v", ErrConflict, err)
+	}
+
+	// swap only if the value matches
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("2")}, CompareAndSwap([]byte("0"))); err != ErrConflict {
+		t.Fatalf("Expected %v got %v", ErrConflict, err)
+	}
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("2")}, CompareAndSwap([]byte("1"))); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := s.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(recs[0].Value) != "2" {
+		t.Fatalf("Expected 2 got %s", recs[0].Value)
+	}
+}
+
+func TestRedisStoreIncrement(t *testing.T) {
+	mr, s := newTestStore(t)
+	defer mr.Close()
+	defer s.Close()
+
+	if v, err := store.Increment(s, "counter", 3); err != nil || v != 3 {
+		t.Fatalf("Expected 3 got %v (%v)", v, err)
+	}
+	if v, err := store.Decrement(s, "counter", 5); err != nil || v != -2 {
+		t.Fatalf("Expected -2 got %v (%v)", v, err)
+	}
+
+	recs, err := s.Read("counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(recs[0].Value) != "-2" || recs[0].Version != 2 {
+		t.Fatalf("Expected value -2 at version 2 got %s at %d", recs[0].Value, recs[0].Version)
+	}
+
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Increment(s, "foo", 1); err != store.ErrNotCounter {
+		t.Fatalf("Expected %v got %v", store.ErrNotCounter, err)
+	}
+}
+
+func TestRedisStoreBatch(t *testing.T) {
+	mr, s := newTestStore(t)
+	defer mr.Close()
+	defer s.Close()
+
+	recs := []*store.Record{
+		{Key: "a", Value: []byte("1"), Expiry: time.Minute},
+		{Key: "b", Value: []byte("2"), Metadata: map[string]interface{}{"foo": "bar"}},
+	}
+	if err := s.BatchWrite(recs); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := s.Read("", store.ReadPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 || read[0].Expiry <= 0 || read[1].Expiry != 0 || read[1].Metadata["foo"] != "bar" {
+		t.Fatalf("Unexpected records %+v", read)
+	}
+
+	if err := s.BatchDelete([]string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("Expected no keys got %v", keys)
+	}
+}
+
+func TestRedisStoreVersion(t *testing.T) {
+	mr, s := newTestStore(t)
+	defer mr.Close()
+	defer s.Close()
+
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("1")}, store.WriteIfNotExists()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("2")}, store.WriteIfNotExists()); err != store.ErrConflict {
+		t.Fatalf("Expected %v got %v", store.ErrConflict, err)
+	}
+
+	recs, err := s.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recs[0].Version != 1 {
+		t.Fatalf("Expected version 1 got %d", recs[0].Version)
+	}
+
+	stale := recs[0]
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("2")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(stale, store.WriteIfMatch()); err != store.ErrConflict {
+		t.Fatalf("Expected %v got %v", store.ErrConflict, err)
+	}
+
+	// a conflict in a batch writes none of the records
+	err = s.BatchWrite([]*store.Record{
+		{Key: "bar", Value: []byte("1")},
+		{Key: "foo", Value: []byte("3"), Version: 2},
+		{Key: "baz", Value: []byte("1"), Version: 1},
+	}, store.WriteIfMatch())
+	if err != store.ErrConflict {
+		t.Fatalf("Expected %v got %v", store.ErrConflict, err)
+	}
+	if keys, _ := s.List(); len(keys) != 1 {
+		t.Fatalf("Expected only foo to exist got %v", keys)
+	}
+
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("3"), Version: 2}, store.WriteIfMatch()); err != nil {
+		t.Fatal(err)
+	}
+	recs, err = s.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(recs[0].Value) != "3" || recs[0].Version != 3 {
+		t.Fatalf("Unexpected record %+v", recs[0])
+	}
+}
+
+func TestRedisStoreWatch(t *testing.T) {
+	mr, s := newTestStore(t)
+	defer mr.Close()
+	defer s.Close()
+
+	w, err := s.(store.Watchable).Watch(store.WatchPrefix("f"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// miniredis doesn't send keyspace notifications so they're published by the test
+	notify := func(key, event string) {
+		mr.Publish("__keyspace@0__:micro/micro/"+key, event)
+	}
+
+	expect := func(typ store.EventType, value string) {
+		ev, err := w.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.Type != typ || ev.Record.Key != "foo" || string(ev.Record.Value) != value {
+			t.Fatalf("Expected %s of foo got %s of %+v", typ, ev.Type, ev.Record)
+		}
+	}
+
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+	// keys outside the prefix aren't watched
+	notify("baz", "hset")
+	notify("foo", "hset")
+	expect(store.Create, "bar")
+
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("baz")}); err != nil {
+		t.Fatal(err)
+	}
+	notify("foo", "hset")
+	expect(store.Update, "baz")
+
+	notify("foo", "del")
+	expect(store.Delete, "")
+
+	notify("foo", "expired")
+	expect(store.Expired, "")
+
+	w.Stop()
+	if _, err := w.Next(); err != store.ErrWatcherStopped {
+		t.Fatalf("Expected %v got %v", store.ErrWatcherStopped, err)
+	}
+}
+
+func TestRedisStoreExpire(t *testing.T) {
+	mr, s := newTestStore(t)
+	defer mr.Close()
+	defer s.Close()
+
+	if err := store.Expire(s, "foo", time.Second); err != store.ErrNotFound {
+		t.Fatalf("Expected %v got %v", store.ErrNotFound, err)
+	}
+
+	if err := s.Write(&store.Record{Key: "foo", Value: []byte("bar"), Expiry: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Expire(s, "foo", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("micro/micro/foo"); ttl != time.Minute {
+		t.Fatalf("Expected a ttl of a minute got %v", ttl)
+	}
+
+	if err := store.Expire(s, "foo", 0); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := s.Read("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recs[0].Expiry != 0 || recs[0].Version != 1 {
+		t.Fatalf("Expected no expiry and the version to be unchanged got %+v", recs[0])
+	}
+}
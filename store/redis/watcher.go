@@ -0,0 +1,159 @@
+package redis
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/store"
+)
+
+var (
+	// ErrNotifyDisabled is returned by Watch when the keyspace notifications the watcher
+	// depends on aren't enabled, see notify-keyspace-events in the redis config
+	ErrNotifyDisabled = errors.New("keyspace notifications are not enabled, set notify-keyspace-events to Kghx")
+)
+
+type redisWatcher struct {
+	store  *redisStore
+	pubsub *redis.PubSub
+	// the channel prefix and key prefix which are trimmed from the channels
+	channel string
+	prefix  string
+
+	events chan *store.Event
+	exit   chan bool
+	once   sync.Once
+}
+
+// notifying returns true if the flags enable the keyspace notifications of writes, deletes and expiry
+func notifying(flags string) bool {
+	if !strings.Contains(flags, "K") {
+		return false
+	}
+	// A is an alias for all the event classes
+	if strings.Contains(flags, "A") {
+		return true
+	}
+	for _, f := range "ghx" {
+		if !strings.ContainsRune(flags, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// Watch the records using keyspace notifications. The notifications must be enabled by setting
+// notify-keyspace-events to include Kghx. Writes are read when they're notified so intermediate
+// values of a key written in quick succession may not be returned.
+func (r *redisStore) Watch(opts ...store.WatchOption) (store.Watcher, error) {
+	var options store.WatchOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// CONFIG is commonly disabled by hosted redis so assume it's configured if it can't be checked
+	if cfg, err := r.client.ConfigGet("notify-keyspace-events").Result(); err == nil {
+		if len(cfg) == 2 {
+			if flags, ok := cfg[1].(string); ok && !notifying(flags) {
+				return nil, ErrNotifyDisabled
+			}
+		}
+	} else if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Debugf("Unable to check the keyspace notifications are enabled: %v", err)
+	}
+
+	prefix := r.prefix(options.Database, options.Table)
+	channel := "__keyspace@" + strconv.Itoa(r.client.Options().DB) + "__:"
+
+	pubsub := r.client.PSubscribe(channel + escaper.Replace(prefix+options.Prefix) + "*")
+
+	// wait for the subscription so no changes are missed once Watch returns
+	if _, err := pubsub.Receive(); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	w := &redisWatcher{
+		store:   r,
+		pubsub:  pubsub,
+		channel: channel,
+		prefix:  prefix,
+		events:  make(chan *store.Event),
+		exit:    make(chan bool),
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+// event returns the store event of the notification, nil if it should be ignored
+func (w *redisWatcher) event(msg *redis.Message) (*store.Event, error) {
+	key := strings.TrimPrefix(strings.TrimPrefix(msg.Channel, w.channel), w.prefix)
+
+	switch msg.Payload {
+	case "hset":
+		recs, err := w.store.read(w.prefix, []string{key})
+		if err != nil {
+			return nil, err
+		}
+		// deleted since it was written
+		if len(recs) == 0 {
+			return nil, nil
+		}
+		typ := store.Update
+		if recs[0].Version == 1 {
+			typ = store.Create
+		}
+		return &store.Event{Type: typ, Record: recs[0], Timestamp: time.Now()}, nil
+	case "del", "evicted":
+		return &store.Event{Type: store.Delete, Record: &store.Record{Key: key}, Timestamp: time.Now()}, nil
+	case "expired":
+		return &store.Event{Type: store.Expired, Record: &store.Record{Key: key}, Timestamp: time.Now()}, nil
+	}
+
+	return nil, nil
+}
+
+func (w *redisWatcher) run() {
+	// the channel is closed when the pubsub is closed
+	for msg := range w.pubsub.Channel() {
+		ev, err := w.event(msg)
+		if err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error reading the changed record %s: %v", msg.Channel, err)
+			}
+			continue
+		}
+		if ev == nil {
+			continue
+		}
+
+		select {
+		case w.events <- ev:
+		case <-w.exit:
+			return
+		}
+	}
+}
+
+func (w *redisWatcher) Next() (*store.Event, error) {
+	select {
+	case ev := <-w.events:
+		return ev, nil
+	case <-w.exit:
+		return nil, store.ErrWatcherStopped
+	}
+}
+
+func (w *redisWatcher) Stop() {
+	w.once.Do(func() {
+		close(w.exit)
+		w.pubsub.Close()
+	})
+}
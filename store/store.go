@@ -10,6 +10,8 @@ import (
 var (
 	// ErrNotFound is returned when a key doesn't exist
 	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a conditional write doesn't match the stored record
+	ErrConflict = errors.New("conflict")
 	// DefaultStore is the memory store.
 	DefaultStore Store = new(noopStore)
 )
@@ -26,6 +28,10 @@ type Store interface {
 	Write(r *Record, opts ...WriteOption) error
 	// Delete removes the record with the corresponding key from the store.
 	Delete(key string, opts ...DeleteOption) error
+	// BatchWrite writes the records to the store. Where supported by the implementation either all the records are written or none are.
+	BatchWrite(recs []*Record, opts ...WriteOption) error
+	// BatchDelete removes the records with the corresponding keys from the store. Where supported by the implementation either all the records are removed or none are.
+	BatchDelete(keys []string, opts ...DeleteOption) error
 	// List returns any keys that match, or an empty list with no error if none matched.
 	List(opts ...ListOption) ([]string, error)
 	// Close the store
@@ -44,4 +50,7 @@ type Record struct {
 	Metadata map[string]interface{} `json:"metadata"`
 	// Time to expire a record: TODO: change to timestamp
 	Expiry time.Duration `json:"expiry,omitempty"`
+	// Version of the record, set by the store when read and incremented on every write.
+	// A version of zero means the record doesn't exist or the version is unknown.
+	Version uint64 `json:"version,omitempty"`
 }
@@ -3,6 +3,7 @@
 package test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -154,6 +155,11 @@ func runStoreTest(s store.Store, t *testing.T) {
 	suffixPrefixExpiryTests(s, t)
 	readTests(s, t)
 	listTests(s, t)
+	batchTests(s, t)
+	transactionTests(s, t)
+	conditionalTests(s, t)
+	expireTests(s, t)
+	counterTests(s, t)
 
 }
 
@@ -329,6 +335,121 @@ func expiryTests(s store.Store, t *testing.T) {
 	}
 }
 
+func expireTests(s store.Store, t *testing.T) {
+	if err := store.Expire(s, "Refreshed", time.Second); err != store.ErrNotFound {
+		t.Fatalf("Expected %v got %v", store.ErrNotFound, err)
+	}
+
+	if err := s.Write(&store.Record{Key: "Refreshed", Value: []byte("World"), Expiry: 100 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	// extend the expiry before the record expires
+	if err := store.Expire(s, "Refreshed", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	recs, err := s.Read("Refreshed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(recs[0].Value) != "World" || recs[0].Expiry < 30*time.Second {
+		t.Fatalf("Expected the expiry to be extended got %v", recs[0].Expiry)
+	}
+
+	// remove the expiry
+	if err := store.Expire(s, "Refreshed", 0); err != nil {
+		t.Fatal(err)
+	}
+	recs, err = s.Read("Refreshed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recs[0].Expiry != 0 {
+		t.Fatalf("Expected no expiry got %v", recs[0].Expiry)
+	}
+
+	s.Delete("Refreshed")
+}
+
+func counterTests(s store.Store, t *testing.T) {
+	if v, err := store.Increment(s, "Counter", 5); err != nil || v != 5 {
+		t.Fatalf("Expected a new counter to be 5 got %v (%v)", v, err)
+	}
+	if v, err := store.Decrement(s, "Counter", 2); err != nil || v != 3 {
+		t.Fatalf("Expected the counter to be 3 got %v (%v)", v, err)
+	}
+
+	// the counter is read like any other record
+	recs, err := s.Read("Counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(recs[0].Value) != "3" {
+		t.Fatalf("Expected the value 3 got %s", recs[0].Value)
+	}
+
+	// concurrent increments don't race
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			_, err := store.Increment(s, "Counter", 1)
+			errs <- err
+		}()
+	}
+	var failed int
+	for i := 0; i < 10; i++ {
+		if err := <-errs; err == store.ErrConflict {
+			failed++
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, _ := store.Increment(s, "Counter", 0); v != int64(13-failed) {
+		t.Fatalf("Expected the counter to be %d got %d", 13-failed, v)
+	}
+
+	if err := s.Write(&store.Record{Key: "NotCounter", Value: []byte("foo")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Increment(s, "NotCounter", 1); err != store.ErrNotCounter {
+		t.Fatalf("Expected %v got %v", store.ErrNotCounter, err)
+	}
+
+	// sequences allocate unique ids in blocks
+	seq1 := store.NewSequence(s, "Sequence", 10)
+	seq2 := store.NewSequence(s, "Sequence", 10)
+	ids := make(map[int64]bool)
+	var last int64
+	for i := 0; i < 25; i++ {
+		id, err := seq1.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= last {
+			t.Fatalf("Expected the ids to increase, got %d after %d", id, last)
+		}
+		last = id
+		ids[id] = true
+
+		if id, err = seq2.Next(); err != nil {
+			t.Fatal(err)
+		}
+		if ids[id] {
+			t.Fatalf("Expected unique ids, got %d twice", id)
+		}
+		ids[id] = true
+	}
+	if recs, _ := s.Read("Sequence"); len(recs) == 0 || string(recs[0].Value) != "60" {
+		t.Fatalf("Expected 6 blocks to be reserved got %v", recs)
+	}
+
+	s.Delete("Counter")
+	s.Delete("NotCounter")
+	s.Delete("Sequence")
+}
+
 func suffixPrefixExpiryTests(s store.Store, t *testing.T) {
 	// Write 3 records with various expiry and get with Prefix
 	records := []*store.Record{
@@ -440,3 +561,350 @@ func suffixPrefixExpiryTests(s store.Store, t *testing.T) {
 		}
 	}
 }
+
+func batchTests(s store.Store, t *testing.T) {
+	var recs []*store.Record
+	var keys []string
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("Batch%d", i)
+		recs = append(recs, &store.Record{Key: key, Value: []byte("bar")})
+		keys = append(keys, key)
+	}
+
+	if err := s.BatchWrite(recs); err != nil {
+		t.Fatalf("Error writing batch %s", err)
+	}
+
+	results, err := s.Read("Batch", store.ReadPrefix())
+	if err != nil {
+		t.Fatalf("Error reading batch %s", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 records got %d", len(results))
+	}
+
+	if err := s.BatchDelete(keys); err != nil {
+		t.Fatalf("Error deleting batch %s", err)
+	}
+
+	keys, err = s.List(store.ListPrefix("Batch"))
+	if err != nil {
+		t.Fatalf("Error listing records %s", err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("Expected the batch to be deleted got %v", keys)
+	}
+}
+
+func transactionTests(s store.Store, t *testing.T) {
+	if err := s.Write(&store.Record{Key: "TxDelete", Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := store.Begin(s)
+	if err != nil {
+		t.Fatalf("Error starting transaction %s", err)
+	}
+	if err := tx.Write(&store.Record{Key: "TxWrite", Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Delete("TxDelete"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the changes are visible in the transaction
+	if recs, err := tx.Read("TxWrite"); err != nil || string(recs[0].Value) != "bar" {
+		t.Fatalf("Expected the write to be visible in the transaction got %v %v", recs, err)
+	}
+	if _, err := tx.Read("TxDelete"); err != store.ErrNotFound {
+		t.Fatalf("Expected %v got %v", store.ErrNotFound, err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != store.ErrTxDone {
+		t.Fatalf("Expected %v got %v", store.ErrTxDone, err)
+	}
+
+	if _, err := s.Read("TxWrite"); err != store.ErrNotFound {
+		t.Fatalf("Expected the rolled back write to be discarded got %v", err)
+	}
+	if _, err := s.Read("TxDelete"); err != nil {
+		t.Fatalf("Expected the rolled back delete to be discarded got %v", err)
+	}
+
+	// commit the changes
+	err = store.Transaction(s, func(tx store.Tx) error {
+		if err := tx.Write(&store.Record{Key: "TxWrite", Value: []byte("bar")}); err != nil {
+			return err
+		}
+		return tx.Delete("TxDelete")
+	})
+	if err != nil {
+		t.Fatalf("Error committing transaction %s", err)
+	}
+
+	if _, err := s.Read("TxWrite"); err != nil {
+		t.Fatalf("Expected the write to be committed got %v", err)
+	}
+	if _, err := s.Read("TxDelete"); err != store.ErrNotFound {
+		t.Fatalf("Expected the delete to be committed got %v", err)
+	}
+
+	s.Delete("TxWrite")
+}
+
+func conditionalTests(s store.Store, t *testing.T) {
+	if err := s.Write(&store.Record{Key: "Cond", Value: []byte("1")}, store.WriteIfNotExists()); err != nil {
+		t.Fatalf("Error creating record %s", err)
+	}
+	if err := s.Write(&store.Record{Key: "Cond", Value: []byte("2")}, store.WriteIfNotExists()); err != store.ErrConflict {
+		t.Fatalf("Expected %v got %v", store.ErrConflict, err)
+	}
+
+	recs, err := s.Read("Cond")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := recs[0]
+	if rec.Version == 0 {
+		t.Fatal("Expected the record to have a version")
+	}
+
+	// a concurrent writer changes the record
+	if err := s.Write(&store.Record{Key: "Cond", Value: []byte("3")}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec.Value = []byte("4")
+	if err := s.Write(rec, store.WriteIfMatch()); err != store.ErrConflict {
+		t.Fatalf("Expected %v got %v", store.ErrConflict, err)
+	}
+
+	// read, modify and write again
+	recs, err = s.Read("Cond")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec = recs[0]
+	if string(rec.Value) != "3" {
+		t.Fatalf("Expected the conflicting write to be rejected got %s", rec.Value)
+	}
+
+	rec.Value = []byte("4")
+	if err := s.Write(rec, store.WriteIfMatch()); err != nil {
+		t.Fatalf("Error writing matching version %s", err)
+	}
+
+	recs, err = s.Read("Cond")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(recs[0].Value) != "4" || recs[0].Version == rec.Version {
+		t.Fatalf("Expected the value and version to change got %s %d", recs[0].Value, recs[0].Version)
+	}
+
+	// a conflict in a batch writes none of the records
+	err = s.BatchWrite([]*store.Record{
+		{Key: "CondNew", Value: []byte("1")},
+		{Key: "Cond", Value: []byte("5")},
+	}, store.WriteIfNotExists())
+	if err != store.ErrConflict {
+		t.Fatalf("Expected %v got %v", store.ErrConflict, err)
+	}
+	if _, err := s.Read("CondNew"); err != store.ErrNotFound {
+		t.Fatalf("Expected the batch not to be written got %v", err)
+	}
+
+	s.Delete("Cond")
+}
+
+// failingStore fails to delete the "deleted" key to check the transaction is undone
+type failingStore struct {
+	store.Store
+}
+
+var errFailed = errors.New("failed")
+
+func (f *failingStore) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	for _, key := range keys {
+		if key == "deleted" {
+			return errFailed
+		}
+	}
+	return f.Store.BatchDelete(keys, opts...)
+}
+
+func TestStoreTransactionRestore(t *testing.T) {
+	s := &failingStore{memory.NewStore()}
+	defer s.Close()
+
+	if err := s.Write(&store.Record{Key: "existing", Value: []byte("old")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(&store.Record{Key: "deleted", Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := store.Transaction(s, func(tx store.Tx) error {
+		tx.Write(&store.Record{Key: "existing", Value: []byte("new")})
+		tx.Write(&store.Record{Key: "created", Value: []byte("new")})
+		return tx.Delete("deleted")
+	})
+	if err != errFailed {
+		t.Fatalf("Expected %v got %v", errFailed, err)
+	}
+
+	// the writes are undone when the delete fails
+	recs, err := s.Read("existing")
+	if err != nil || string(recs[0].Value) != "old" {
+		t.Fatalf("Expected the previous value to be restored got %v %v", recs, err)
+	}
+	if _, err := s.Read("created"); err != store.ErrNotFound {
+		t.Fatalf("Expected the created record to be removed got %v", err)
+	}
+}
+
+// next returns the next event of the watcher failing the test if there isn't one
+func next(t *testing.T, w store.Watcher) *store.Event {
+	ch := make(chan *store.Event, 1)
+	go func() {
+		ev, err := w.Next()
+		if err != nil {
+			t.Error(err)
+		}
+		ch <- ev
+	}()
+
+	select {
+	case ev := <-ch:
+		if ev == nil {
+			t.FailNow()
+		}
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for an event")
+	}
+	return nil
+}
+
+func TestStoreWatch(t *testing.T) {
+	// cockroach requires rangefeeds to be enabled for changefeeds
+	tcs := []struct {
+		name    string
+		s       store.Store
+		cleanup func(db string, s store.Store)
+	}{
+		{name: "file", s: file.NewStore(store.Table("watch")), cleanup: fileStoreCleanup},
+		{name: "memory", s: memory.NewStore(), cleanup: memoryCleanup},
+		{name: "cache", s: cache.NewStore(memory.NewStore()), cleanup: cacheCleanup},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			defer tc.cleanup(file.DefaultDatabase, tc.s)
+
+			w, err := store.Watch(tc.s, store.WatchPrefix("Watch"), store.WatchInterval(10*time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Stop()
+
+			expect := func(typ store.EventType, value string) {
+				ev := next(t, w)
+				if ev.Type != typ || ev.Record.Key != "Watched" || string(ev.Record.Value) != value {
+					t.Fatalf("Expected %s of Watched got %s of %s", typ, ev.Type, spew.Sdump(ev.Record))
+				}
+			}
+
+			// records outside the prefix aren't watched
+			if err := tc.s.Write(&store.Record{Key: "Unwatched", Value: []byte("foo")}); err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.s.Write(&store.Record{Key: "Watched", Value: []byte("foo")}); err != nil {
+				t.Fatal(err)
+			}
+			expect(store.Create, "foo")
+
+			if err := tc.s.Write(&store.Record{Key: "Watched", Value: []byte("bar")}); err != nil {
+				t.Fatal(err)
+			}
+			expect(store.Update, "bar")
+
+			if err := tc.s.Delete("Watched"); err != nil {
+				t.Fatal(err)
+			}
+			expect(store.Delete, "")
+
+			w.Stop()
+			if _, err := w.Next(); err != store.ErrWatcherStopped {
+				t.Fatalf("Expected %v got %v", store.ErrWatcherStopped, err)
+			}
+
+			tc.s.Delete("Unwatched")
+		})
+	}
+}
+
+func TestStoreWatchExpired(t *testing.T) {
+	s := memory.NewStore(memory.CleanupInterval(10 * time.Millisecond))
+	defer s.Close()
+
+	w, err := store.Watch(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	if err := s.Write(&store.Record{Key: "Session", Expiry: 20 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	if ev := next(t, w); ev.Type != store.Create {
+		t.Fatalf("Expected %s got %s", store.Create, ev.Type)
+	}
+	if ev := next(t, w); ev.Type != store.Expired || ev.Record.Key != "Session" {
+		t.Fatalf("Expected Session to have %s got %s of %s", store.Expired, ev.Type, ev.Record.Key)
+	}
+}
+
+func TestStoreQuery(t *testing.T) {
+	// stores which can't query natively are scanned
+	indexes := store.Indexes(store.Index{Name: "count", Field: "count"})
+	tcs := []struct {
+		name    string
+		s       store.Store
+		cleanup func(db string, s store.Store)
+	}{
+		{name: "file", s: file.NewStore(store.Table("query"), indexes), cleanup: fileStoreCleanup},
+		{name: "memory", s: memory.NewStore(indexes), cleanup: memoryCleanup},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			defer tc.cleanup(file.DefaultDatabase, tc.s)
+
+			for i, key := range []string{"QueryC", "QueryA", "QueryB", "QueryD"} {
+				rec := &store.Record{Key: key, Metadata: map[string]interface{}{"count": i % 3}}
+				if err := tc.s.Write(rec); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			recs, err := store.Query(tc.s, "count", store.QueryRange(0, 2), store.QueryLimit(3))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var keys []string
+			for _, r := range recs {
+				keys = append(keys, r.Key)
+			}
+			if strings.Join(keys, ",") != "QueryC,QueryD,QueryA" {
+				t.Fatalf("Unexpected query results %v", keys)
+			}
+
+			if _, err := store.Query(tc.s, "missing"); err != store.ErrUnknownIndex {
+				t.Fatalf("Expected %v got %v", store.ErrUnknownIndex, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"errors"
+)
+
+var (
+	// ErrTxDone is returned when a transaction is used after it has been committed or rolled back
+	ErrTxDone = errors.New("transaction has already been committed or rolled back")
+)
+
+// Tx is a store transaction. The changes are applied when it's committed.
+type Tx interface {
+	// Read the records, the changes made in the transaction are visible
+	Read(key string, opts ...ReadOption) ([]*Record, error)
+	// Write a record in the transaction
+	Write(r *Record, opts ...WriteOption) error
+	// Delete a record in the transaction
+	Delete(key string, opts ...DeleteOption) error
+	// Commit applies the changes
+	Commit() error
+	// Rollback discards the changes
+	Rollback() error
+}
+
+// Transactional is implemented by stores which natively support transactions
+type Transactional interface {
+	// Begin starts a transaction
+	Begin() (Tx, error)
+}
+
+// Begin starts a transaction on the store. Stores which aren't Transactional
+// get a best-effort transaction, see NewTx.
+func Begin(s Store) (Tx, error) {
+	if t, ok := s.(Transactional); ok {
+		return t.Begin()
+	}
+	return NewTx(s), nil
+}
+
+// Transaction runs the function in a transaction, the changes are committed
+// if it returns nil otherwise they're rolled back
+func Transaction(s Store, fn func(tx Tx) error) error {
+	tx, err := Begin(s)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
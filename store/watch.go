@@ -0,0 +1,76 @@
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrWatcherStopped is returned when a watcher is stopped
+	ErrWatcherStopped = errors.New("watcher stopped")
+	// DefaultWatchInterval is how often the records are polled when the store can't watch natively
+	DefaultWatchInterval = 10 * time.Second
+)
+
+// EventType defines the store event type
+type EventType int
+
+const (
+	// Create is emitted when a record is written for a key which didn't exist
+	Create EventType = iota
+	// Delete is emitted when a record is deleted, or expires in stores which don't emit Expired
+	Delete
+	// Update is emitted when an existing record is written
+	Update
+	// Expired is emitted when a record expires by stores which are notified of the expiry
+	Expired
+)
+
+// String returns human readable event type
+func (t EventType) String() string {
+	switch t {
+	case Create:
+		return "create"
+	case Delete:
+		return "delete"
+	case Update:
+		return "update"
+	case Expired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a change to a record
+type Event struct {
+	// Type of the change
+	Type EventType
+	// Record which changed, only the key is set for deletes
+	Record *Record
+	// Timestamp of the change
+	Timestamp time.Time
+}
+
+// Watcher returns the changes to the watched records
+type Watcher interface {
+	// Next is a blocking call which returns the next change
+	Next() (*Event, error)
+	// Stop the watcher
+	Stop()
+}
+
+// Watchable is implemented by stores which can natively watch for changes
+type Watchable interface {
+	// Watch the records for changes
+	Watch(opts ...WatchOption) (Watcher, error)
+}
+
+// Watch the records of the store for changes. Stores which aren't Watchable
+// are polled, see Poll.
+func Watch(s Store, opts ...WatchOption) (Watcher, error) {
+	if w, ok := s.(Watchable); ok {
+		return w.Watch(opts...)
+	}
+	return Poll(s, opts...)
+}
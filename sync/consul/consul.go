@@ -0,0 +1,223 @@
+// Package consul is a consul implementation of sync using sessions and the kv store
+package consul
+
+import (
+	"context"
+	"errors"
+	"log"
+	"path"
+	gosync "sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/consul/api"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+type consulSync struct {
+	options sync.Options
+	client  *api.Client
+
+	mtx   gosync.Mutex
+	locks map[string]*consulLock
+	reads map[string][]*consulLock
+}
+
+type consulLeader struct {
+	id     string
+	l      *api.Lock
+	lease  *sync.Lease
+	once   gosync.Once
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type consulObserver struct {
+	id     string
+	key    string
+	client *api.Client
+	index  uint64
+	last   *sync.Lease
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (c *consulSync) key(kind, id string) string {
+	return path.Join("micro/sync", kind, c.options.Prefix+id)
+}
+
+// ttl formats the duration as a session ttl, consul requires at least 10 seconds
+func ttl(d time.Duration) string {
+	if d < time.Second*10 {
+		d = time.Second * 10
+	}
+	return d.String()
+}
+
+func (c *consulSync) Init(opts ...sync.Option) error {
+	for _, o := range opts {
+		o(&c.options)
+	}
+	return nil
+}
+
+func (c *consulSync) Options() sync.Options {
+	return c.options
+}
+
+func (c *consulSync) Leader(id string, opts ...sync.LeaderOption) (sync.Leader, error) {
+	options := sync.LeaderOptions{
+		Node: uuid.New().String(),
+		TTL:  time.Second * 15,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	key := c.key("leader", id)
+	l, err := c.client.LockOpts(&api.LockOptions{
+		Key:        key,
+		Value:      []byte(options.Node),
+		SessionTTL: ttl(options.TTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lost, err := l.Lock(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// the lock index increases every time the key is locked
+	pair, _, err := c.client.KV().Get(key, nil)
+	if err != nil {
+		l.Unlock()
+		return nil, err
+	}
+	if pair == nil {
+		l.Unlock()
+		return nil, errors.New("leader key not found")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-lost:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	return &consulLeader{
+		id: id,
+		l:  l,
+		lease: &sync.Lease{
+			Id:    id,
+			Node:  options.Node,
+			Token: pair.LockIndex,
+		},
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+func (l *consulLeader) Resign() error {
+	var err error
+	l.once.Do(func() {
+		l.cancel()
+		err = l.l.Unlock()
+	})
+	return err
+}
+
+func (l *consulLeader) Status() chan bool {
+	ch := make(chan bool, 1)
+	go func() {
+		<-l.ctx.Done()
+		ch <- true
+		close(ch)
+	}()
+	return ch
+}
+
+func (l *consulLeader) Lease() *sync.Lease {
+	lease := *l.lease
+	return &lease
+}
+
+func (l *consulLeader) Context() context.Context {
+	return l.ctx
+}
+
+func (c *consulSync) ObserveLeader(id string) (sync.Observer, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &consulObserver{
+		id:     id,
+		key:    c.key("leader", id),
+		client: c.client,
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+func (o *consulObserver) Next() (*sync.Lease, error) {
+	for {
+		// block until the key changes after the first read
+		qopts := (&api.QueryOptions{WaitIndex: o.index}).WithContext(o.ctx)
+		pair, meta, err := o.client.KV().Get(o.key, qopts)
+		if o.ctx.Err() != nil {
+			return nil, sync.ErrObserverStopped
+		} else if err != nil {
+			return nil, err
+		}
+		o.index = meta.LastIndex
+
+		lease := &sync.Lease{Id: o.id}
+		if pair != nil && len(pair.Session) > 0 {
+			lease.Node = string(pair.Value)
+			lease.Token = pair.LockIndex
+		}
+
+		if o.last == nil || o.last.Node != lease.Node || o.last.Token != lease.Token {
+			o.last = lease
+			return lease, nil
+		}
+	}
+}
+
+func (o *consulObserver) Stop() {
+	o.cancel()
+}
+
+func (c *consulSync) String() string {
+	return "consul"
+}
+
+// NewSync returns a consul sync, the first node is used as the address of the agent
+func NewSync(opts ...sync.Option) sync.Sync {
+	var options sync.Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	config := api.DefaultConfig()
+	for _, addr := range options.Nodes {
+		if len(addr) > 0 {
+			config.Address = addr
+			break
+		}
+	}
+
+	c, err := api.NewClient(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return &consulSync{
+		options: options,
+		client:  c,
+		locks:   make(map[string]*consulLock),
+		reads:   make(map[string][]*consulLock),
+	}
+}
@@ -0,0 +1,148 @@
+package consul
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	gosync "sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/consul/api"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+// consulSemaphore uses a consul semaphore for every permit, each holding its own session
+type consulSemaphore struct {
+	c      *consulSync
+	prefix string
+	limit  int
+	opts   sync.SemaphoreOptions
+
+	mtx     gosync.Mutex
+	permits map[string]*api.Semaphore
+}
+
+// consulRateLimiter counts the events of each window in a key updated with check-and-set
+type consulRateLimiter struct {
+	c        *consulSync
+	id       string
+	limit    int
+	interval time.Duration
+}
+
+func (c *consulSync) Semaphore(id string, limit int, opts ...sync.SemaphoreOption) (sync.Semaphore, error) {
+	var options sync.SemaphoreOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return &consulSemaphore{
+		c:       c,
+		prefix:  c.key("semaphore", id),
+		limit:   limit,
+		opts:    options,
+		permits: make(map[string]*api.Semaphore),
+	}, nil
+}
+
+func (s *consulSemaphore) Acquire(ctx context.Context) (string, error) {
+	sem, err := s.c.client.SemaphoreOpts(&api.SemaphoreOptions{
+		Prefix:     s.prefix,
+		Limit:      s.limit,
+		SessionTTL: ttl(s.opts.TTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// stop waiting for the semaphore once the context is done
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			close(stop)
+		case <-done:
+		}
+	}()
+
+	lost, err := sem.Acquire(stop)
+	if err != nil {
+		return "", err
+	}
+	if lost == nil {
+		return "", ctx.Err()
+	}
+
+	permit := uuid.New().String()
+	s.mtx.Lock()
+	s.permits[permit] = sem
+	s.mtx.Unlock()
+
+	return permit, nil
+}
+
+func (s *consulSemaphore) Release(permit string) error {
+	s.mtx.Lock()
+	sem, ok := s.permits[permit]
+	delete(s.permits, permit)
+	s.mtx.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return sem.Release()
+}
+
+func (c *consulSync) RateLimiter(id string, limit int, interval time.Duration) (sync.RateLimiter, error) {
+	return &consulRateLimiter{c: c, id: id, limit: limit, interval: interval}, nil
+}
+
+func (r *consulRateLimiter) Allow() (bool, error) {
+	w, _ := sync.Window(time.Now(), r.interval)
+	key := r.c.key("rate", fmt.Sprintf("%s/%d", r.id, w))
+	kv := r.c.client.KV()
+
+	for {
+		pair, _, err := kv.Get(key, nil)
+		if err != nil {
+			return false, err
+		}
+
+		// a modify index of 0 only writes the key if it doesn't exist
+		var count int
+		var index uint64
+		if pair != nil {
+			count, err = strconv.Atoi(string(pair.Value))
+			if err != nil {
+				return false, err
+			}
+			if count >= r.limit {
+				return false, nil
+			}
+			index = pair.ModifyIndex
+		}
+
+		ok, _, err := kv.CAS(&api.KVPair{
+			Key:         key,
+			Value:       []byte(strconv.Itoa(count + 1)),
+			ModifyIndex: index,
+		}, nil)
+		if err != nil {
+			return false, err
+		} else if !ok {
+			continue
+		}
+
+		// the kv store has no expiry, remove the count of the previous window
+		if index == 0 {
+			kv.Delete(r.c.key("rate", fmt.Sprintf("%s/%d", r.id, w-1)), nil)
+		}
+		return true, nil
+	}
+}
+
+func (r *consulRateLimiter) Wait(ctx context.Context) error {
+	return sync.WaitAllow(ctx, r.interval, r.Allow)
+}
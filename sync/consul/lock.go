@@ -0,0 +1,213 @@
+package consul
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/consul/api"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+// consulLock is a key acquired with a session for each holder of a lock, a holder waits
+// for the keys created before its own which it conflicts with to be deleted
+type consulLock struct {
+	key     string
+	session string
+	// done stops the renewal of the session
+	done chan struct{}
+}
+
+func (c *consulSync) lockPrefix(id string) string {
+	return c.key("lock", id) + "/"
+}
+
+// close stops the renewal and destroys the session which deletes the key
+func (l *consulLock) close(c *api.Client) error {
+	if l.done != nil {
+		close(l.done)
+	}
+	if _, err := c.KV().Delete(l.key, nil); err != nil {
+		return err
+	}
+	_, err := c.Session().Destroy(l.session, nil)
+	return err
+}
+
+func (c *consulSync) acquire(id string, read bool, opts ...sync.LockOption) (*consulLock, error) {
+	options := sync.NewLockOptions(opts...)
+
+	var deadline time.Time
+	if options.Wait > 0 {
+		deadline = time.Now().Add(options.Wait)
+	}
+
+	// the session of a renewed lock is renewed until it's released, otherwise it's
+	// invalidated after the ttl which deletes the key
+	sid, _, err := c.client.Session().Create(&api.SessionEntry{
+		Name:     "micro sync lock " + id,
+		TTL:      ttl(options.TTL),
+		Behavior: api.SessionBehaviorDelete,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &consulLock{key: c.lockPrefix(id) + uuid.New().String(), session: sid}
+	h := &sync.LockHolder{
+		Id:       id,
+		Owner:    options.Owner,
+		Metadata: options.Metadata,
+		Read:     read,
+		Acquired: time.Now(),
+	}
+
+	if options.Renew || options.TTL <= 0 {
+		l.done = make(chan struct{})
+		go c.client.Session().RenewPeriodic(ttl(options.TTL), sid, nil, l.done)
+	} else {
+		h.Expires = h.Acquired.Add(options.TTL)
+	}
+
+	b, err := json.Marshal(h)
+	if err != nil {
+		l.close(c.client)
+		return nil, err
+	}
+	if _, _, err := c.client.KV().Acquire(&api.KVPair{Key: l.key, Value: b, Session: sid}, nil); err != nil {
+		l.close(c.client)
+		return nil, err
+	}
+
+	pair, _, err := c.client.KV().Get(l.key, nil)
+	if err != nil || pair == nil {
+		l.close(c.client)
+		if err == nil {
+			err = errors.New("lock key not found")
+		}
+		return nil, err
+	}
+
+	var index uint64
+	for {
+		q := &api.QueryOptions{WaitIndex: index}
+		if !deadline.IsZero() {
+			q.WaitTime = time.Until(deadline)
+		}
+
+		pairs, meta, err := c.client.KV().List(c.lockPrefix(id), q)
+		if err != nil {
+			l.close(c.client)
+			return nil, err
+		}
+
+		// readers only wait for writers created before them
+		var blocked bool
+		for _, p := range pairs {
+			if p.CreateIndex >= pair.CreateIndex {
+				continue
+			}
+			var other *sync.LockHolder
+			if err := json.Unmarshal(p.Value, &other); err != nil {
+				continue
+			}
+			if !read || !other.Read {
+				blocked = true
+				break
+			}
+		}
+
+		if !blocked {
+			return l, nil
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			l.close(c.client)
+			return nil, sync.ErrLockTimeout
+		}
+
+		index = meta.LastIndex
+	}
+}
+
+func (c *consulSync) Lock(id string, opts ...sync.LockOption) error {
+	l, err := c.acquire(id, false, opts...)
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	c.locks[id] = l
+	c.mtx.Unlock()
+
+	return nil
+}
+
+func (c *consulSync) Unlock(id string) error {
+	c.mtx.Lock()
+	l, ok := c.locks[id]
+	delete(c.locks, id)
+	c.mtx.Unlock()
+
+	if !ok {
+		return errors.New("lock not found")
+	}
+	return l.close(c.client)
+}
+
+func (c *consulSync) RLock(id string, opts ...sync.LockOption) error {
+	l, err := c.acquire(id, true, opts...)
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	c.reads[id] = append(c.reads[id], l)
+	c.mtx.Unlock()
+
+	return nil
+}
+
+func (c *consulSync) RUnlock(id string) error {
+	c.mtx.Lock()
+	reads := c.reads[id]
+	if len(reads) == 0 {
+		c.mtx.Unlock()
+		return errors.New("lock not found")
+	}
+	l := reads[len(reads)-1]
+	if len(reads) == 1 {
+		delete(c.reads, id)
+	} else {
+		c.reads[id] = reads[:len(reads)-1]
+	}
+	c.mtx.Unlock()
+
+	return l.close(c.client)
+}
+
+func (c *consulSync) Holders(id string) ([]*sync.LockHolder, error) {
+	pairs, _, err := c.client.KV().List(c.lockPrefix(id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	holders := make([]*sync.LockHolder, 0, len(pairs))
+	for _, p := range pairs {
+		var h *sync.LockHolder
+		if err := json.Unmarshal(p.Value, &h); err != nil {
+			return nil, err
+		}
+		holders = append(holders, h)
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		return holders[i].Acquired.Before(holders[j].Acquired)
+	})
+	return holders, nil
+}
+
+func (c *consulSync) ForceUnlock(id string) error {
+	_, err := c.client.KV().DeleteTree(c.lockPrefix(id), nil)
+	return err
+}
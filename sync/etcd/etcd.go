@@ -3,7 +3,6 @@ package etcd
 
 import (
 	"context"
-	"errors"
 	"log"
 	"path"
 	"strings"
@@ -11,6 +10,7 @@ import (
 
 	client "github.com/coreos/etcd/clientv3"
 	cc "github.com/coreos/etcd/clientv3/concurrency"
+	"github.com/google/uuid"
 	"github.com/micro/go-micro/v3/sync"
 )
 
@@ -21,123 +21,161 @@ type etcdSync struct {
 
 	mtx   gosync.Mutex
 	locks map[string]*etcdLock
+	reads map[string][]*etcdLock
 }
 
-type etcdLock struct {
-	s *cc.Session
-	m *cc.Mutex
+type etcdLeader struct {
+	opts   sync.LeaderOptions
+	s      *cc.Session
+	e      *cc.Election
+	id     string
+	lease  *sync.Lease
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
-type etcdLeader struct {
-	opts sync.LeaderOptions
-	s    *cc.Session
-	e    *cc.Election
-	id   string
+type etcdObserver struct {
+	id     string
+	s      *cc.Session
+	ch     <-chan client.GetResponse
+	cancel context.CancelFunc
+}
+
+func (e *etcdSync) key(id string) string {
+	return path.Join(e.path, strings.Replace(e.options.Prefix+id, "/", "-", -1))
 }
 
 func (e *etcdSync) Leader(id string, opts ...sync.LeaderOption) (sync.Leader, error) {
-	var options sync.LeaderOptions
+	options := sync.LeaderOptions{
+		Node: uuid.New().String(),
+	}
 	for _, o := range opts {
 		o(&options)
 	}
 
-	// make path
-	path := path.Join(e.path, strings.Replace(e.options.Prefix+id, "/", "-", -1))
+	var sopts []cc.SessionOption
+	if options.TTL > 0 {
+		sopts = append(sopts, cc.WithTTL(int(options.TTL.Seconds())))
+	}
 
-	s, err := cc.NewSession(e.client)
+	s, err := cc.NewSession(e.client, sopts...)
 	if err != nil {
 		return nil, err
 	}
 
-	l := cc.NewElection(s, path)
+	l := cc.NewElection(s, e.key(id))
 
-	if err := l.Campaign(context.TODO(), id); err != nil {
+	if err := l.Campaign(context.TODO(), options.Node); err != nil {
+		s.Close()
 		return nil, err
 	}
 
-	return &etcdLeader{
+	ctx, cancel := context.WithCancel(context.Background())
+
+	leader := &etcdLeader{
 		opts: options,
+		s:    s,
 		e:    l,
 		id:   id,
-	}, nil
-}
-
-func (e *etcdLeader) Status() chan bool {
-	ch := make(chan bool, 1)
-	ech := e.e.Observe(context.Background())
+		// the revision the leader key was created at increases with every leader
+		lease: &sync.Lease{
+			Id:    id,
+			Node:  options.Node,
+			Token: uint64(l.Rev()),
+		},
+		ctx:    ctx,
+		cancel: cancel,
+	}
 
+	// leadership is lost when the session expires or another node is elected
 	go func() {
-		for r := range ech {
-			if string(r.Kvs[0].Value) != e.id {
-				ch <- true
-				close(ch)
+		defer cancel()
+
+		ech := l.Observe(ctx)
+		for {
+			select {
+			case <-s.Done():
 				return
+			case r, ok := <-ech:
+				if !ok || len(r.Kvs) == 0 || r.Kvs[0].CreateRevision != l.Rev() {
+					return
+				}
 			}
 		}
 	}()
 
+	return leader, nil
+}
+
+func (e *etcdLeader) Status() chan bool {
+	ch := make(chan bool, 1)
+	go func() {
+		<-e.ctx.Done()
+		ch <- true
+		close(ch)
+	}()
 	return ch
 }
 
-func (e *etcdLeader) Resign() error {
-	return e.e.Resign(context.Background())
+func (e *etcdLeader) Lease() *sync.Lease {
+	l := *e.lease
+	return &l
 }
 
-func (e *etcdSync) Init(opts ...sync.Option) error {
-	for _, o := range opts {
-		o(&e.options)
-	}
-	return nil
+func (e *etcdLeader) Context() context.Context {
+	return e.ctx
 }
 
-func (e *etcdSync) Options() sync.Options {
-	return e.options
+func (e *etcdLeader) Resign() error {
+	defer e.s.Close()
+	defer e.cancel()
+	return e.e.Resign(context.Background())
 }
 
-func (e *etcdSync) Lock(id string, opts ...sync.LockOption) error {
-	var options sync.LockOptions
-	for _, o := range opts {
-		o(&options)
+func (e *etcdSync) ObserveLeader(id string) (sync.Observer, error) {
+	s, err := cc.NewSession(e.client)
+	if err != nil {
+		return nil, err
 	}
 
-	// make path
-	path := path.Join(e.path, strings.Replace(e.options.Prefix+id, "/", "-", -1))
+	ctx, cancel := context.WithCancel(context.Background())
 
-	var sopts []cc.SessionOption
-	if options.TTL > 0 {
-		sopts = append(sopts, cc.WithTTL(int(options.TTL.Seconds())))
-	}
+	return &etcdObserver{
+		id:     id,
+		s:      s,
+		ch:     cc.NewElection(s, e.key(id)).Observe(ctx),
+		cancel: cancel,
+	}, nil
+}
 
-	s, err := cc.NewSession(e.client, sopts...)
-	if err != nil {
-		return err
+func (o *etcdObserver) Next() (*sync.Lease, error) {
+	r, ok := <-o.ch
+	if !ok {
+		return nil, sync.ErrObserverStopped
 	}
 
-	m := cc.NewMutex(s, path)
-
-	if err := m.Lock(context.TODO()); err != nil {
-		return err
+	lease := &sync.Lease{Id: o.id}
+	if len(r.Kvs) > 0 {
+		lease.Node = string(r.Kvs[0].Value)
+		lease.Token = uint64(r.Kvs[0].CreateRevision)
 	}
+	return lease, nil
+}
+
+func (o *etcdObserver) Stop() {
+	o.cancel()
+	o.s.Close()
+}
 
-	e.mtx.Lock()
-	e.locks[id] = &etcdLock{
-		s: s,
-		m: m,
+func (e *etcdSync) Init(opts ...sync.Option) error {
+	for _, o := range opts {
+		o(&e.options)
 	}
-	e.mtx.Unlock()
 	return nil
 }
 
-func (e *etcdSync) Unlock(id string) error {
-	e.mtx.Lock()
-	defer e.mtx.Unlock()
-	v, ok := e.locks[id]
-	if !ok {
-		return errors.New("lock not found")
-	}
-	err := v.m.Unlock(context.Background())
-	delete(e.locks, id)
-	return err
+func (e *etcdSync) Options() sync.Options {
+	return e.options
 }
 
 func (e *etcdSync) String() string {
@@ -175,5 +213,6 @@ func NewSync(opts ...sync.Option) sync.Sync {
 		client:  c,
 		options: options,
 		locks:   make(map[string]*etcdLock),
+		reads:   make(map[string][]*etcdLock),
 	}
 }
@@ -0,0 +1,172 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	gosync "sync"
+	"time"
+
+	client "github.com/coreos/etcd/clientv3"
+	cc "github.com/coreos/etcd/clientv3/concurrency"
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+// etcdSemaphore writes a key for every waiter under the prefix, the holders of the
+// permits are the first keys created up to the limit
+type etcdSemaphore struct {
+	e      *etcdSync
+	prefix string
+	limit  int
+	opts   sync.SemaphoreOptions
+
+	mtx      gosync.Mutex
+	sessions map[string]*cc.Session
+}
+
+type etcdRateLimiter struct {
+	e        *etcdSync
+	id       string
+	limit    int
+	interval time.Duration
+}
+
+func (e *etcdSync) Semaphore(id string, limit int, opts ...sync.SemaphoreOption) (sync.Semaphore, error) {
+	var options sync.SemaphoreOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return &etcdSemaphore{
+		e:        e,
+		prefix:   e.key("semaphore-" + id),
+		limit:    limit,
+		opts:     options,
+		sessions: make(map[string]*cc.Session),
+	}, nil
+}
+
+func (s *etcdSemaphore) Acquire(ctx context.Context) (string, error) {
+	// the permit is released when the session closes or its lease expires
+	var sopts []cc.SessionOption
+	if s.opts.TTL > 0 {
+		sopts = append(sopts, cc.WithTTL(int(math.Ceil(s.opts.TTL.Seconds()))))
+	}
+	sess, err := cc.NewSession(s.e.client, sopts...)
+	if err != nil {
+		return "", err
+	}
+
+	permit := uuid.New().String()
+	key := path.Join(s.prefix, permit)
+	if _, err := s.e.client.Put(ctx, key, "", client.WithLease(sess.Lease())); err != nil {
+		sess.Close()
+		return "", err
+	}
+
+	for {
+		rsp, err := s.e.client.Get(ctx, s.prefix+"/",
+			client.WithPrefix(),
+			client.WithSort(client.SortByCreateRevision, client.SortAscend),
+			client.WithLimit(int64(s.limit)),
+		)
+		if err != nil {
+			sess.Close()
+			return "", err
+		}
+
+		for _, kv := range rsp.Kvs {
+			if string(kv.Key) == key {
+				s.mtx.Lock()
+				s.sessions[permit] = sess
+				s.mtx.Unlock()
+				return permit, nil
+			}
+		}
+
+		// wait for a permit to be released
+		wctx, cancel := context.WithCancel(ctx)
+		wch := s.e.client.Watch(wctx, s.prefix+"/",
+			client.WithPrefix(),
+			client.WithRev(rsp.Header.Revision+1),
+			client.WithFilterPut(),
+		)
+		select {
+		case <-wch:
+		case <-ctx.Done():
+		}
+		cancel()
+
+		if err := ctx.Err(); err != nil {
+			sess.Close()
+			return "", err
+		}
+	}
+}
+
+func (s *etcdSemaphore) Release(permit string) error {
+	s.mtx.Lock()
+	sess, ok := s.sessions[permit]
+	delete(s.sessions, permit)
+	s.mtx.Unlock()
+
+	if !ok {
+		return nil
+	}
+	// closing the session revokes the lease of the key
+	return sess.Close()
+}
+
+func (e *etcdSync) RateLimiter(id string, limit int, interval time.Duration) (sync.RateLimiter, error) {
+	return &etcdRateLimiter{e: e, id: id, limit: limit, interval: interval}, nil
+}
+
+func (r *etcdRateLimiter) Allow() (bool, error) {
+	w, _ := sync.Window(time.Now(), r.interval)
+	key := r.e.key(fmt.Sprintf("rate-%s-%d", r.id, w))
+	ctx := context.Background()
+
+	for {
+		rsp, err := r.e.client.Get(ctx, key)
+		if err != nil {
+			return false, err
+		}
+
+		var txn client.Txn
+		if len(rsp.Kvs) == 0 {
+			// the count expires after the window
+			lease, err := r.e.client.Grant(ctx, int64(math.Ceil((r.interval * 2).Seconds())))
+			if err != nil {
+				return false, err
+			}
+			txn = r.e.client.Txn(ctx).
+				If(client.Compare(client.CreateRevision(key), "=", 0)).
+				Then(client.OpPut(key, "1", client.WithLease(lease.ID)))
+		} else {
+			count, err := strconv.Atoi(string(rsp.Kvs[0].Value))
+			if err != nil {
+				return false, err
+			}
+			if count >= r.limit {
+				return false, nil
+			}
+			txn = r.e.client.Txn(ctx).
+				If(client.Compare(client.ModRevision(key), "=", rsp.Kvs[0].ModRevision)).
+				Then(client.OpPut(key, strconv.Itoa(count+1), client.WithIgnoreLease()))
+		}
+
+		tr, err := txn.Commit()
+		if err != nil {
+			return false, err
+		}
+		if tr.Succeeded {
+			return true, nil
+		}
+	}
+}
+
+func (r *etcdRateLimiter) Wait(ctx context.Context) error {
+	return sync.WaitAllow(ctx, r.interval, r.Allow)
+}
@@ -0,0 +1,214 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	client "github.com/coreos/etcd/clientv3"
+	cc "github.com/coreos/etcd/clientv3/concurrency"
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+// etcdLock is a key written for each holder of a lock, a holder waits for the keys
+// created before its own which it conflicts with to be deleted
+type etcdLock struct {
+	key   string
+	lease client.LeaseID
+	// session keeping the lease alive of a renewed lock
+	s *cc.Session
+}
+
+func (e *etcdSync) lockPrefix(id string) string {
+	return e.key("lock-"+id) + "/"
+}
+
+// close deletes the key of the lock and its lease
+func (l *etcdLock) close(c *client.Client) error {
+	if _, err := c.Delete(context.Background(), l.key); err != nil {
+		return err
+	}
+	if l.s != nil {
+		return l.s.Close()
+	}
+	_, err := c.Revoke(context.Background(), l.lease)
+	return err
+}
+
+func (e *etcdSync) acquire(id string, read bool, opts ...sync.LockOption) (*etcdLock, error) {
+	options := sync.NewLockOptions(opts...)
+
+	ctx := context.Background()
+	if options.Wait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Wait)
+		defer cancel()
+	}
+
+	// the lease of a renewed lock is kept alive by a session, otherwise it expires after the ttl
+	l := &etcdLock{key: e.lockPrefix(id) + uuid.New().String()}
+	h := &sync.LockHolder{
+		Id:       id,
+		Owner:    options.Owner,
+		Metadata: options.Metadata,
+		Read:     read,
+		Acquired: time.Now(),
+	}
+
+	if options.Renew || options.TTL <= 0 {
+		var sopts []cc.SessionOption
+		if options.TTL > 0 {
+			sopts = append(sopts, cc.WithTTL(int(math.Ceil(options.TTL.Seconds()))))
+		}
+		s, err := cc.NewSession(e.client, sopts...)
+		if err != nil {
+			return nil, err
+		}
+		l.s = s
+		l.lease = s.Lease()
+	} else {
+		rsp, err := e.client.Grant(context.Background(), int64(math.Ceil(options.TTL.Seconds())))
+		if err != nil {
+			return nil, err
+		}
+		l.lease = rsp.ID
+		h.Expires = h.Acquired.Add(options.TTL)
+	}
+
+	b, err := json.Marshal(h)
+	if err != nil {
+		l.close(e.client)
+		return nil, err
+	}
+	put, err := e.client.Put(context.Background(), l.key, string(b), client.WithLease(l.lease))
+	if err != nil {
+		l.close(e.client)
+		return nil, err
+	}
+
+	for {
+		rsp, err := e.client.Get(ctx, e.lockPrefix(id), client.WithPrefix(), client.WithSort(client.SortByCreateRevision, client.SortAscend))
+		if err != nil {
+			l.close(e.client)
+			if ctx.Err() != nil {
+				return nil, sync.ErrLockTimeout
+			}
+			return nil, err
+		}
+
+		// find the last key created before ours which we conflict with, readers only wait for writers
+		var blocker string
+		for _, kv := range rsp.Kvs {
+			if kv.CreateRevision >= put.Header.Revision {
+				break
+			}
+			var other *sync.LockHolder
+			if err := json.Unmarshal(kv.Value, &other); err != nil {
+				continue
+			}
+			if !read || !other.Read {
+				blocker = string(kv.Key)
+			}
+		}
+
+		if len(blocker) == 0 {
+			return l, nil
+		}
+
+		wctx, cancel := context.WithCancel(ctx)
+		wch := e.client.Watch(wctx, blocker, client.WithRev(rsp.Header.Revision+1), client.WithFilterPut())
+		select {
+		case <-wch:
+		case <-ctx.Done():
+		}
+		cancel()
+
+		if ctx.Err() != nil {
+			l.close(e.client)
+			return nil, sync.ErrLockTimeout
+		}
+	}
+}
+
+func (e *etcdSync) Lock(id string, opts ...sync.LockOption) error {
+	l, err := e.acquire(id, false, opts...)
+	if err != nil {
+		return err
+	}
+
+	e.mtx.Lock()
+	e.locks[id] = l
+	e.mtx.Unlock()
+	return nil
+}
+
+func (e *etcdSync) Unlock(id string) error {
+	e.mtx.Lock()
+	l, ok := e.locks[id]
+	delete(e.locks, id)
+	e.mtx.Unlock()
+
+	if !ok {
+		return errors.New("lock not found")
+	}
+	return l.close(e.client)
+}
+
+func (e *etcdSync) RLock(id string, opts ...sync.LockOption) error {
+	l, err := e.acquire(id, true, opts...)
+	if err != nil {
+		return err
+	}
+
+	e.mtx.Lock()
+	e.reads[id] = append(e.reads[id], l)
+	e.mtx.Unlock()
+	return nil
+}
+
+func (e *etcdSync) RUnlock(id string) error {
+	e.mtx.Lock()
+	reads := e.reads[id]
+	if len(reads) == 0 {
+		e.mtx.Unlock()
+		return errors.New("lock not found")
+	}
+	l := reads[len(reads)-1]
+	if len(reads) == 1 {
+		delete(e.reads, id)
+	} else {
+		e.reads[id] = reads[:len(reads)-1]
+	}
+	e.mtx.Unlock()
+
+	return l.close(e.client)
+}
+
+func (e *etcdSync) Holders(id string) ([]*sync.LockHolder, error) {
+	rsp, err := e.client.Get(context.Background(), e.lockPrefix(id), client.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	holders := make([]*sync.LockHolder, 0, len(rsp.Kvs))
+	for _, kv := range rsp.Kvs {
+		var h *sync.LockHolder
+		if err := json.Unmarshal(kv.Value, &h); err != nil {
+			return nil, err
+		}
+		holders = append(holders, h)
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		return holders[i].Acquired.Before(holders[j].Acquired)
+	})
+	return holders, nil
+}
+
+func (e *etcdSync) ForceUnlock(id string) error {
+	_, err := e.client.Delete(context.Background(), e.lockPrefix(id), client.WithPrefix())
+	return err
+}
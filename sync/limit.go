@@ -0,0 +1,113 @@
+package sync
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/micro/go-micro/v3/logger"
+)
+
+// localPrefix marks the permits of the local semaphore of a fallback
+const localPrefix = "local:"
+
+// Window returns the fixed window of the interval the time falls in and when the next window starts
+func Window(t time.Time, interval time.Duration) (int64, time.Time) {
+	w := t.UnixNano() / int64(interval)
+	return w, time.Unix(0, (w+1)*int64(interval))
+}
+
+// WaitAllow blocks until allow returns true, trying again at the start of each window
+func WaitAllow(ctx context.Context, interval time.Duration, allow func() (bool, error)) error {
+	for {
+		ok, err := allow()
+		if err != nil {
+			return err
+		} else if ok {
+			return nil
+		}
+
+		_, next := Window(time.Now(), interval)
+		t := time.NewTimer(time.Until(next))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+type fallbackSemaphore struct {
+	s     Semaphore
+	local Semaphore
+}
+
+type fallbackRateLimiter struct {
+	r     RateLimiter
+	local RateLimiter
+}
+
+// FallbackSemaphore returns a semaphore which uses the local semaphore when the distributed one
+// fails e.g because its backend is unavailable. The limit of the local semaphore should be the
+// share of the limit of a single node.
+func FallbackSemaphore(s, local Semaphore) Semaphore {
+	return &fallbackSemaphore{s: s, local: local}
+}
+
+func (f *fallbackSemaphore) Acquire(ctx context.Context) (string, error) {
+	permit, err := f.s.Acquire(ctx)
+	if err == nil || ctx.Err() != nil {
+		return permit, err
+	}
+
+	if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+		logger.Warnf("Error acquiring semaphore, falling back to the local semaphore: %v", err)
+	}
+
+	permit, err = f.local.Acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	return localPrefix + permit, nil
+}
+
+func (f *fallbackSemaphore) Release(permit string) error {
+	if strings.HasPrefix(permit, localPrefix) {
+		return f.local.Release(strings.TrimPrefix(permit, localPrefix))
+	}
+	return f.s.Release(permit)
+}
+
+// FallbackRateLimiter returns a rate limiter which uses the local rate limiter when the distributed
+// one fails e.g because its backend is unavailable. The limit of the local rate limiter should be
+// the share of the limit of a single node.
+func FallbackRateLimiter(r, local RateLimiter) RateLimiter {
+	return &fallbackRateLimiter{r: r, local: local}
+}
+
+func (f *fallbackRateLimiter) Allow() (bool, error) {
+	ok, err := f.r.Allow()
+	if err == nil {
+		return ok, nil
+	}
+
+	if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+		logger.Warnf("Error checking rate limit, falling back to the local rate limiter: %v", err)
+	}
+
+	return f.local.Allow()
+}
+
+func (f *fallbackRateLimiter) Wait(ctx context.Context) error {
+	err := f.r.Wait(ctx)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+		logger.Warnf("Error waiting for rate limit, falling back to the local rate limiter: %v", err)
+	}
+
+	return f.local.Wait(ctx)
+}
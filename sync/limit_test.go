@@ -0,0 +1,58 @@
+package sync_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/sync"
+	"github.com/micro/go-micro/v3/sync/memory"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+type failing struct{}
+
+func (failing) Acquire(ctx context.Context) (string, error) { return "", errUnavailable }
+func (failing) Release(permit string) error                 { return errUnavailable }
+func (failing) Allow() (bool, error)                        { return false, errUnavailable }
+func (failing) Wait(ctx context.Context) error              { return errUnavailable }
+
+func TestFallback(t *testing.T) {
+	s := memory.NewSync()
+
+	local, err := s.Semaphore("foo", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sem := sync.FallbackSemaphore(failing{}, local)
+
+	p, err := sem.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(p, "local:") {
+		t.Fatalf("Expected a local permit, got %v", p)
+	}
+	if err := sem.Release(p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := local.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	lr, err := s.RateLimiter("foo", 1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := sync.FallbackRateLimiter(failing{}, lr)
+
+	if ok, err := r.Allow(); err != nil || !ok {
+		t.Fatalf("Expected to be allowed, got %v %v", ok, err)
+	}
+	if ok, err := r.Allow(); err != nil || ok {
+		t.Fatalf("Expected to be limited, got %v %v", ok, err)
+	}
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+// semaphore permits of an id, changed is closed and replaced when a permit is released
+type semaphore struct {
+	permits map[string]time.Time
+	changed chan bool
+}
+
+type memorySemaphore struct {
+	m     *memorySync
+	id    string
+	limit int
+	opts  sync.SemaphoreOptions
+}
+
+// window count of a rate limiter
+type window struct {
+	window int64
+	count  int
+}
+
+type memoryRateLimiter struct {
+	m        *memorySync
+	id       string
+	limit    int
+	interval time.Duration
+}
+
+func (m *memorySync) Semaphore(id string, limit int, opts ...sync.SemaphoreOption) (sync.Semaphore, error) {
+	var options sync.SemaphoreOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	m.mtx.Lock()
+	if _, ok := m.semaphores[id]; !ok {
+		m.semaphores[id] = &semaphore{
+			permits: make(map[string]time.Time),
+			changed: make(chan bool),
+		}
+	}
+	m.mtx.Unlock()
+
+	return &memorySemaphore{m: m, id: id, limit: limit, opts: options}, nil
+}
+
+func (s *memorySemaphore) Acquire(ctx context.Context) (string, error) {
+	for {
+		s.m.mtx.Lock()
+		sem := s.m.semaphores[s.id]
+
+		// release the expired permits and find the next to expire
+		var next time.Time
+		for p, exp := range sem.permits {
+			if exp.IsZero() {
+				continue
+			}
+			if time.Now().After(exp) {
+				delete(sem.permits, p)
+				continue
+			}
+			if next.IsZero() || exp.Before(next) {
+				next = exp
+			}
+		}
+
+		if len(sem.permits) < s.limit {
+			permit := uuid.New().String()
+			var exp time.Time
+			if s.opts.TTL > 0 {
+				exp = time.Now().Add(s.opts.TTL)
+			}
+			sem.permits[permit] = exp
+			s.m.mtx.Unlock()
+			return permit, nil
+		}
+
+		changed := sem.changed
+		s.m.mtx.Unlock()
+
+		// wait for a permit to be released or expire
+		var t *time.Timer
+		var expired <-chan time.Time
+		if !next.IsZero() {
+			t = time.NewTimer(time.Until(next))
+			expired = t.C
+		}
+
+		select {
+		case <-changed:
+		case <-expired:
+		case <-ctx.Done():
+		}
+
+		if t != nil {
+			t.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+	}
+}
+
+func (s *memorySemaphore) Release(permit string) error {
+	s.m.mtx.Lock()
+	defer s.m.mtx.Unlock()
+
+	sem := s.m.semaphores[s.id]
+	if _, ok := sem.permits[permit]; !ok {
+		return nil
+	}
+	delete(sem.permits, permit)
+
+	close(sem.changed)
+	sem.changed = make(chan bool)
+
+	return nil
+}
+
+func (m *memorySync) RateLimiter(id string, limit int, interval time.Duration) (sync.RateLimiter, error) {
+	return &memoryRateLimiter{m: m, id: id, limit: limit, interval: interval}, nil
+}
+
+func (r *memoryRateLimiter) Allow() (bool, error) {
+	w, _ := sync.Window(time.Now(), r.interval)
+
+	r.m.mtx.Lock()
+	defer r.m.mtx.Unlock()
+
+	cur, ok := r.m.windows[r.id]
+	if !ok || cur.window != w {
+		cur = &window{window: w}
+		r.m.windows[r.id] = cur
+	}
+
+	if cur.count >= r.limit {
+		return false, nil
+	}
+	cur.count++
+	return true, nil
+}
+
+func (r *memoryRateLimiter) Wait(ctx context.Context) error {
+	return sync.WaitAllow(ctx, r.interval, r.Allow)
+}
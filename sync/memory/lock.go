@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+// memoryLock holds the holders of a lock, changed is closed and replaced when one is released
+type memoryLock struct {
+	holders map[string]*sync.LockHolder
+	changed chan bool
+}
+
+// expire releases the expired holders and returns when the next holder expires
+func (l *memoryLock) expire() time.Time {
+	var next time.Time
+	for k, h := range l.holders {
+		if h.Expires.IsZero() {
+			continue
+		}
+		if time.Now().After(h.Expires) {
+			delete(l.holders, k)
+			continue
+		}
+		if next.IsZero() || h.Expires.Before(next) {
+			next = h.Expires
+		}
+	}
+	return next
+}
+
+// available returns true if the lock can be acquired, readers only wait for a writer
+func (l *memoryLock) available(read bool) bool {
+	for _, h := range l.holders {
+		if !read || !h.Read {
+			return false
+		}
+	}
+	return true
+}
+
+// release the holders matching the func, at most one unless all is set
+func (m *memorySync) release(id string, all bool, fn func(h *sync.LockHolder) bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	lk, ok := m.locks[id]
+	if !ok {
+		return
+	}
+
+	var released bool
+	for k, h := range lk.holders {
+		if !fn(h) {
+			continue
+		}
+		delete(lk.holders, k)
+		released = true
+		if !all {
+			break
+		}
+	}
+
+	if !released {
+		return
+	}
+	if len(lk.holders) == 0 {
+		delete(m.locks, id)
+	}
+	close(lk.changed)
+	lk.changed = make(chan bool)
+}
+
+func (m *memorySync) acquire(id string, read bool, opts ...sync.LockOption) error {
+	options := sync.NewLockOptions(opts...)
+
+	var wait <-chan time.Time
+	if options.Wait > 0 {
+		t := time.NewTimer(options.Wait)
+		defer t.Stop()
+		wait = t.C
+	}
+
+	for {
+		m.mtx.Lock()
+		lk, ok := m.locks[id]
+		if !ok {
+			lk = &memoryLock{
+				holders: make(map[string]*sync.LockHolder),
+				changed: make(chan bool),
+			}
+			m.locks[id] = lk
+		}
+
+		next := lk.expire()
+		if lk.available(read) {
+			h := &sync.LockHolder{
+				Id:       id,
+				Owner:    options.Owner,
+				Metadata: options.Metadata,
+				Read:     read,
+				Acquired: time.Now(),
+			}
+			// a renewed lock is held until it's released as its holder is the process itself
+			if options.TTL > 0 && !options.Renew {
+				h.Expires = h.Acquired.Add(options.TTL)
+			}
+			lk.holders[uuid.New().String()] = h
+			m.mtx.Unlock()
+			return nil
+		}
+
+		changed := lk.changed
+		m.mtx.Unlock()
+
+		// wait for a holder to release the lock or expire
+		var t *time.Timer
+		var expired <-chan time.Time
+		if !next.IsZero() {
+			t = time.NewTimer(time.Until(next))
+			expired = t.C
+		}
+
+		var timeout bool
+		select {
+		case <-changed:
+		case <-expired:
+		case <-wait:
+			timeout = true
+		}
+
+		if t != nil {
+			t.Stop()
+		}
+		if timeout {
+			return sync.ErrLockTimeout
+		}
+	}
+}
+
+func (m *memorySync) Lock(id string, opts ...sync.LockOption) error {
+	return m.acquire(id, false, opts...)
+}
+
+func (m *memorySync) Unlock(id string) error {
+	m.release(id, false, func(h *sync.LockHolder) bool { return !h.Read })
+	return nil
+}
+
+func (m *memorySync) RLock(id string, opts ...sync.LockOption) error {
+	return m.acquire(id, true, opts...)
+}
+
+func (m *memorySync) RUnlock(id string) error {
+	m.release(id, false, func(h *sync.LockHolder) bool { return h.Read })
+	return nil
+}
+
+func (m *memorySync) Holders(id string) ([]*sync.LockHolder, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	lk, ok := m.locks[id]
+	if !ok {
+		return nil, nil
+	}
+	lk.expire()
+
+	holders := make([]*sync.LockHolder, 0, len(lk.holders))
+	for _, h := range lk.holders {
+		hc := *h
+		holders = append(holders, &hc)
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		return holders[i].Acquired.Before(holders[j].Acquired)
+	})
+	return holders, nil
+}
+
+func (m *memorySync) ForceUnlock(id string) error {
+	m.release(id, true, func(h *sync.LockHolder) bool { return true })
+	return nil
+}
@@ -2,189 +2,185 @@
 package memory
 
 import (
+	"context"
 	gosync "sync"
-	"time"
 
+	"github.com/google/uuid"
 	"github.com/micro/go-micro/v3/sync"
 )
 
 type memorySync struct {
 	options sync.Options
 
-	mtx   gosync.RWMutex
-	locks map[string]*memoryLock
-}
-
-type memoryLock struct {
-	id      string
-	time    time.Time
-	ttl     time.Duration
-	release chan bool
+	mtx       gosync.RWMutex
+	locks     map[string]*memoryLock
+	elections map[string]*election
+	// semaphores and rate limiter windows of the ids
+	semaphores map[string]*semaphore
+	windows    map[string]*window
 }
 
 type memoryLeader struct {
 	opts   sync.LeaderOptions
 	id     string
-	resign func(id string) error
-	status chan bool
+	lease  *sync.Lease
+	resign func() error
+	ctx    context.Context
+}
+
+// election of a leader, changed is closed and replaced when the leader changes
+type election struct {
+	lease   *sync.Lease
+	token   uint64
+	changed chan bool
+}
+
+type memoryObserver struct {
+	m    *memorySync
+	id   string
+	seen bool
+	last *sync.Lease
+	exit chan bool
+	once gosync.Once
 }
 
 func (m *memoryLeader) Resign() error {
-	return m.resign(m.id)
+	return m.resign()
 }
 
 func (m *memoryLeader) Status() chan bool {
-	return m.status
+	ch := make(chan bool, 1)
+	go func() {
+		<-m.ctx.Done()
+		ch <- true
+		close(ch)
+	}()
+	return ch
 }
 
-func (m *memorySync) Leader(id string, opts ...sync.LeaderOption) (sync.Leader, error) {
-	var once gosync.Once
-	var options sync.LeaderOptions
-	for _, o := range opts {
-		o(&options)
-	}
-
-	// acquire a lock for the id
-	if err := m.Lock(id); err != nil {
-		return nil, err
-	}
+func (m *memoryLeader) Lease() *sync.Lease {
+	l := *m.lease
+	return &l
+}
 
-	// return the leader
-	return &memoryLeader{
-		opts: options,
-		id:   id,
-		resign: func(id string) error {
-			once.Do(func() {
-				m.Unlock(id)
-			})
-			return nil
-		},
-		// TODO: signal when Unlock is called
-		status: make(chan bool, 1),
-	}, nil
+func (m *memoryLeader) Context() context.Context {
+	return m.ctx
 }
 
-func (m *memorySync) Init(opts ...sync.Option) error {
-	for _, o := range opts {
-		o(&m.options)
+// election returns the election of the id, the lock must be held
+func (m *memorySync) election(id string) *election {
+	e, ok := m.elections[id]
+	if !ok {
+		e = &election{changed: make(chan bool)}
+		m.elections[id] = e
 	}
-	return nil
+	return e
 }
 
-func (m *memorySync) Options() sync.Options {
-	return m.options
+// set the lease of the election and notify the candidates and observers
+func (e *election) set(l *sync.Lease) {
+	e.lease = l
+	close(e.changed)
+	e.changed = make(chan bool)
 }
 
-func (m *memorySync) Lock(id string, opts ...sync.LockOption) error {
-	// lock our access
-	m.mtx.Lock()
-
-	var options sync.LockOptions
+func (m *memorySync) Leader(id string, opts ...sync.LeaderOption) (sync.Leader, error) {
+	options := sync.LeaderOptions{
+		Node: uuid.New().String(),
+	}
 	for _, o := range opts {
 		o(&options)
 	}
 
-	lk, ok := m.locks[id]
-	if !ok {
-		m.locks[id] = &memoryLock{
-			id:      id,
-			time:    time.Now(),
-			ttl:     options.TTL,
-			release: make(chan bool),
-		}
-		// unlock
+	// wait for the election to have no leader
+	m.mtx.Lock()
+	e := m.election(id)
+	for e.lease != nil {
+		changed := e.changed
 		m.mtx.Unlock()
-		return nil
+		<-changed
+		m.mtx.Lock()
 	}
 
+	e.token++
+	lease := &sync.Lease{Id: id, Node: options.Node, Token: e.token}
+	e.set(lease)
 	m.mtx.Unlock()
 
-	// set wait time
-	var wait <-chan time.Time
-	var ttl <-chan time.Time
+	ctx, cancel := context.WithCancel(context.Background())
+	var once gosync.Once
 
-	// decide if we should wait
-	if options.Wait > time.Duration(0) {
-		wait = time.After(options.Wait)
-	}
+	// return the leader
+	return &memoryLeader{
+		opts:  options,
+		id:    id,
+		lease: lease,
+		ctx:   ctx,
+		resign: func() error {
+			once.Do(func() {
+				m.mtx.Lock()
+				if e.lease == lease {
+					e.set(nil)
+				}
+				m.mtx.Unlock()
+				cancel()
+			})
+			return nil
+		},
+	}, nil
+}
 
-	// check the ttl of the lock
-	if lk.ttl > time.Duration(0) {
-		// time lived for the lock
-		live := time.Since(lk.time)
-
-		// set a timer for the leftover ttl
-		if live > lk.ttl {
-			// release the lock if it expired
-			_ = m.Unlock(id)
-		} else {
-			ttl = time.After(live)
-		}
-	}
+func (m *memorySync) ObserveLeader(id string) (sync.Observer, error) {
+	return &memoryObserver{
+		m:    m,
+		id:   id,
+		exit: make(chan bool),
+	}, nil
+}
 
-lockLoop:
+func (o *memoryObserver) Next() (*sync.Lease, error) {
 	for {
-		// wait for the lock to be released
-		select {
-		case <-lk.release:
-			m.mtx.Lock()
-
-			// someone locked before us
-			lk, ok = m.locks[id]
-			if ok {
-				m.mtx.Unlock()
-				continue
-			}
-
-			// got chance to lock
-			m.locks[id] = &memoryLock{
-				id:      id,
-				time:    time.Now(),
-				ttl:     options.TTL,
-				release: make(chan bool),
+		o.m.mtx.Lock()
+		e := o.m.election(o.id)
+		lease, changed := e.lease, e.changed
+		o.m.mtx.Unlock()
+
+		// return the current lease first and then on every change
+		if !o.seen || lease != o.last {
+			o.seen = true
+			o.last = lease
+			l := sync.Lease{Id: o.id}
+			if lease != nil {
+				l = *lease
 			}
+			return &l, nil
+		}
 
-			m.mtx.Unlock()
-
-			break lockLoop
-		case <-ttl:
-			// ttl exceeded
-			_ = m.Unlock(id)
-			// TODO: check the ttl again above
-			ttl = nil
-			// try acquire
-			continue
-		case <-wait:
-			return sync.ErrLockTimeout
+		select {
+		case <-changed:
+		case <-o.exit:
+			return nil, sync.ErrObserverStopped
 		}
 	}
-
-	return nil
 }
 
-func (m *memorySync) Unlock(id string) error {
-	m.mtx.Lock()
-	defer m.mtx.Unlock()
-
-	lk, ok := m.locks[id]
-	// no lock exists
-	if !ok {
-		return nil
-	}
-
-	// delete the lock
-	delete(m.locks, id)
+func (o *memoryObserver) Stop() {
+	o.once.Do(func() {
+		close(o.exit)
+	})
+}
 
-	select {
-	case <-lk.release:
-		return nil
-	default:
-		close(lk.release)
+func (m *memorySync) Init(opts ...sync.Option) error {
+	for _, o := range opts {
+		o(&m.options)
 	}
-
 	return nil
 }
 
+func (m *memorySync) Options() sync.Options {
+	return m.options
+}
+
 func (m *memorySync) String() string {
 	return "memory"
 }
@@ -196,7 +192,10 @@ func NewSync(opts ...sync.Option) sync.Sync {
 	}
 
 	return &memorySync{
-		options: options,
-		locks:   make(map[string]*memoryLock),
+		options:    options,
+		locks:      make(map[string]*memoryLock),
+		elections:  make(map[string]*election),
+		semaphores: make(map[string]*semaphore),
+		windows:    make(map[string]*window),
 	}
 }
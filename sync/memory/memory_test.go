@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/sync"
+)
+
+func TestLeader(t *testing.T) {
+	s := NewSync()
+
+	o, err := s.ObserveLeader("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Stop()
+
+	// no leader yet
+	if l, err := o.Next(); err != nil || len(l.Node) > 0 {
+		t.Fatalf("Expected no leader, got %+v %v", l, err)
+	}
+
+	a, err := s.Leader("foo", sync.LeaderNode("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, _ := o.Next(); l.Node != "a" || l.Token != a.Lease().Token {
+		t.Fatalf("Expected leader a, got %+v", l)
+	}
+
+	// b waits for a to resign
+	elected := make(chan sync.Leader)
+	go func() {
+		b, _ := s.Leader("foo", sync.LeaderNode("b"))
+		elected <- b
+	}()
+
+	select {
+	case <-elected:
+		t.Fatal("Expected b to wait for a to resign")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	a.Resign()
+	select {
+	case <-a.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the context of a to be cancelled")
+	}
+
+	b := <-elected
+	if b.Lease().Token <= a.Lease().Token {
+		t.Fatalf("Expected the token to increase, got %v after %v", b.Lease().Token, a.Lease().Token)
+	}
+
+	for {
+		l, err := o.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.Node == "b" {
+			break
+		}
+	}
+
+	o.Stop()
+	if _, err := o.Next(); err != sync.ErrObserverStopped {
+		t.Fatalf("Expected the observer to be stopped, got %v", err)
+	}
+}
+
+func TestSemaphore(t *testing.T) {
+	s := NewSync()
+
+	sem, err := s.Semaphore("foo", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p1, err := sem.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sem.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the limit is reached
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if _, err := sem.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected the deadline to be exceeded, got %v", err)
+	}
+
+	// a release wakes up the waiter
+	go func() {
+		time.Sleep(time.Millisecond * 20)
+		sem.Release(p1)
+	}()
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := sem.Acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the permits expire after the ttl
+	ttl, err := s.Semaphore("bar", 1, sync.SemaphoreTTL(time.Millisecond*20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ttl.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ttl.Acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	s := NewSync()
+	interval := time.Millisecond * 200
+
+	r, err := s.RateLimiter("foo", 2, interval)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// start at the beginning of a window
+	_, next := sync.Window(time.Now(), interval)
+	time.Sleep(time.Until(next))
+
+	for i := 0; i < 2; i++ {
+		if ok, err := r.Allow(); err != nil || !ok {
+			t.Fatalf("Expected to be allowed, got %v %v", ok, err)
+		}
+	}
+	if ok, err := r.Allow(); err != nil || ok {
+		t.Fatalf("Expected to be limited, got %v %v", ok, err)
+	}
+
+	// waiting allows the event in the next window
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRWLock(t *testing.T) {
+	s := NewSync()
+
+	// readers share the lock
+	if err := s.RLock("foo", sync.LockOwner("reader"), sync.LockMetadata(map[string]string{"foo": "bar"})); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RLock("foo", sync.LockWait(time.Millisecond*50)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Lock("foo", sync.LockWait(time.Millisecond*50)); err != sync.ErrLockTimeout {
+		t.Fatalf("Expected a lock timeout, got %v", err)
+	}
+
+	holders, err := s.Holders("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holders) != 2 || !holders[0].Read || holders[0].Owner != "reader" || holders[0].Metadata["foo"] != "bar" {
+		t.Fatalf("Unexpected holders %+v", holders)
+	}
+	if len(holders[1].Owner) == 0 {
+		t.Fatal("Expected the default owner to be set")
+	}
+
+	// the writer acquires the lock once the readers release it
+	go func() {
+		time.Sleep(time.Millisecond * 20)
+		s.RUnlock("foo")
+		s.RUnlock("foo")
+	}()
+	if err := s.Lock("foo", sync.LockWait(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RLock("foo", sync.LockWait(time.Millisecond*50)); err != sync.ErrLockTimeout {
+		t.Fatalf("Expected a lock timeout, got %v", err)
+	}
+
+	// force releasing the lock lets the reader in
+	if err := s.ForceUnlock("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RLock("foo", sync.LockWait(time.Millisecond*50)); err != nil {
+		t.Fatal(err)
+	}
+	if holders, _ := s.Holders("foo"); len(holders) != 1 || !holders[0].Read {
+		t.Fatalf("Unexpected holders %+v", holders)
+	}
+}
@@ -1,6 +1,8 @@
 package sync
 
 import (
+	"fmt"
+	"os"
 	"time"
 )
 
@@ -18,6 +20,21 @@ func Prefix(p string) Option {
 	}
 }
 
+// LeaderNode sets the id of the candidate
+func LeaderNode(n string) LeaderOption {
+	return func(o *LeaderOptions) {
+		o.Node = n
+	}
+}
+
+// LeaderTTL sets the ttl of the lease, the leadership is lost
+// if the leader fails to renew it before it expires
+func LeaderTTL(t time.Duration) LeaderOption {
+	return func(o *LeaderOptions) {
+		o.TTL = t
+	}
+}
+
 // LockTTL sets the lock ttl
 func LockTTL(t time.Duration) LockOption {
 	return func(o *LockOptions) {
@@ -31,3 +48,45 @@ func LockWait(t time.Duration) LockOption {
 		o.Wait = t
 	}
 }
+
+// LockRenew renews the lock while it's held so the ttl only
+// releases the lock when the holder fails to renew it
+func LockRenew() LockOption {
+	return func(o *LockOptions) {
+		o.Renew = true
+	}
+}
+
+// LockOwner sets the owner of the lock returned by Holders
+func LockOwner(owner string) LockOption {
+	return func(o *LockOptions) {
+		o.Owner = owner
+	}
+}
+
+// LockMetadata sets the metadata of the holder returned by Holders
+func LockMetadata(md map[string]string) LockOption {
+	return func(o *LockOptions) {
+		o.Metadata = md
+	}
+}
+
+// NewLockOptions returns the lock options with the owner defaulting to the hostname and pid
+func NewLockOptions(opts ...LockOption) LockOptions {
+	var options LockOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Owner) == 0 {
+		host, _ := os.Hostname()
+		options.Owner = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	return options
+}
+
+// SemaphoreTTL sets the ttl of the permits so they're released if a holder crashes
+func SemaphoreTTL(t time.Duration) SemaphoreOption {
+	return func(o *SemaphoreOptions) {
+		o.TTL = t
+	}
+}
@@ -0,0 +1,114 @@
+// Package redis implements the semaphores and rate limiters of sync with redis
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+var (
+	// DefaultInterval redis is polled at while waiting for a permit
+	DefaultInterval = time.Second
+)
+
+// acquireScript releases the expired permits of the semaphore and adds the permit if
+// there are fewer than the limit. The permits are a sorted set scored by their expiry.
+var acquireScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[4])
+return 1
+`)
+
+// allowScript counts the event in the window if there are fewer than the limit,
+// the window expires after the interval
+var allowScript = redis.NewScript(`
+local count = tonumber(redis.call('GET', KEYS[1]) or '0')
+if count >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+type redisSemaphore struct {
+	client redis.UniversalClient
+	key    string
+	limit  int
+	opts   sync.SemaphoreOptions
+}
+
+type redisRateLimiter struct {
+	client   redis.UniversalClient
+	id       string
+	limit    int
+	interval time.Duration
+}
+
+// NewSemaphore returns a semaphore limiting the concurrent holders of the id across the
+// nodes sharing the redis to the limit
+func NewSemaphore(c redis.UniversalClient, id string, limit int, opts ...sync.SemaphoreOption) sync.Semaphore {
+	var options sync.SemaphoreOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return &redisSemaphore{client: c, key: "sync/semaphore/" + id, limit: limit, opts: options}
+}
+
+func (s *redisSemaphore) Acquire(ctx context.Context) (string, error) {
+	permit := uuid.New().String()
+
+	for {
+		now := time.Now()
+		exp := "+inf"
+		if s.opts.TTL > 0 {
+			exp = fmt.Sprint(now.Add(s.opts.TTL).UnixNano())
+		}
+
+		ok, err := acquireScript.Run(s.client, []string{s.key}, now.UnixNano(), s.limit, exp, permit).Int()
+		if err != nil {
+			return "", err
+		} else if ok == 1 {
+			return permit, nil
+		}
+
+		select {
+		case <-time.After(DefaultInterval):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+func (s *redisSemaphore) Release(permit string) error {
+	return s.client.ZRem(s.key, permit).Err()
+}
+
+// NewRateLimiter returns a rate limiter limiting the events of the id across the nodes
+// sharing the redis to the limit per interval
+func NewRateLimiter(c redis.UniversalClient, id string, limit int, interval time.Duration) sync.RateLimiter {
+	return &redisRateLimiter{client: c, id: id, limit: limit, interval: interval}
+}
+
+func (r *redisRateLimiter) Allow() (bool, error) {
+	w, _ := sync.Window(time.Now(), r.interval)
+	key := fmt.Sprintf("sync/rate/%s/%d", r.id, w)
+
+	ok, err := allowScript.Run(r.client, []string{key}, r.limit, (r.interval * 2).Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return ok == 1, nil
+}
+
+func (r *redisRateLimiter) Wait(ctx context.Context) error {
+	return sync.WaitAllow(ctx, r.interval, r.Allow)
+}
@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v7"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+func newTestClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mr, redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+func TestSemaphore(t *testing.T) {
+	mr, c := newTestClient(t)
+	defer mr.Close()
+	defer c.Close()
+
+	DefaultInterval = time.Millisecond * 10
+	sem := NewSemaphore(c, "foo", 1)
+
+	p, err := sem.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if _, err := sem.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected the deadline to be exceeded, got %v", err)
+	}
+
+	if err := sem.Release(p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sem.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSemaphoreTTL(t *testing.T) {
+	mr, c := newTestClient(t)
+	defer mr.Close()
+	defer c.Close()
+
+	DefaultInterval = time.Millisecond * 10
+	sem := NewSemaphore(c, "foo", 1, sync.SemaphoreTTL(time.Millisecond*50))
+
+	if _, err := sem.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the permit which isn't released expires
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := sem.Acquire(ctx); err != nil {
+		t.Fatalf("Expected the permit to expire, got %v", err)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	mr, c := newTestClient(t)
+	defer mr.Close()
+	defer c.Close()
+
+	interval := time.Millisecond * 200
+	r := NewRateLimiter(c, "foo", 2, interval)
+
+	// start at the beginning of a window
+	_, next := sync.Window(time.Now(), interval)
+	time.Sleep(time.Until(next))
+
+	for i := 0; i < 2; i++ {
+		if ok, err := r.Allow(); err != nil || !ok {
+			t.Fatalf("Expected to be allowed, got %v %v", ok, err)
+		}
+	}
+	if ok, err := r.Allow(); err != nil || ok {
+		t.Fatalf("Expected to be limited, got %v %v", ok, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	gostore "github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+// storeSemaphore keeps the permits of the semaphore in a record written with conditional
+// writes so the permits are only added while there are fewer than the limit
+type storeSemaphore struct {
+	s     *storeSync
+	id    string
+	limit int
+	opts  sync.SemaphoreOptions
+}
+
+// storeRateLimiter counts the events of each window in a record which expires after the window
+type storeRateLimiter struct {
+	s        *storeSync
+	id       string
+	limit    int
+	interval time.Duration
+}
+
+func (s *storeSync) Semaphore(id string, limit int, opts ...sync.SemaphoreOption) (sync.Semaphore, error) {
+	var options sync.SemaphoreOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return &storeSemaphore{s: s, id: id, limit: limit, opts: options}, nil
+}
+
+// update reads the permits, applies the func and writes them if they changed
+func (s *storeSemaphore) update(fn func(permits map[string]time.Time) bool) error {
+	key := s.s.key("semaphore", s.id)
+
+	for {
+		permits := make(map[string]time.Time)
+		rec := &gostore.Record{Key: key}
+		opt := gostore.WriteIfNotExists()
+
+		recs, err := s.s.store.Read(key)
+		if err != nil && err != gostore.ErrNotFound {
+			return err
+		} else if err == nil {
+			if err := json.Unmarshal(recs[0].Value, &permits); err != nil {
+				return err
+			}
+			rec.Version = recs[0].Version
+			opt = gostore.WriteIfMatch()
+		}
+
+		// release the expired permits
+		for p, exp := range permits {
+			if !exp.IsZero() && time.Now().After(exp) {
+				delete(permits, p)
+			}
+		}
+
+		if !fn(permits) {
+			return nil
+		}
+
+		rec.Value, err = json.Marshal(permits)
+		if err != nil {
+			return err
+		}
+
+		err = s.s.store.Write(rec, opt)
+		if err == gostore.ErrConflict {
+			continue
+		}
+		return err
+	}
+}
+
+func (s *storeSemaphore) Acquire(ctx context.Context) (string, error) {
+	permit := uuid.New().String()
+
+	for {
+		var acquired bool
+		err := s.update(func(permits map[string]time.Time) bool {
+			if len(permits) >= s.limit {
+				return false
+			}
+			var exp time.Time
+			if s.opts.TTL > 0 {
+				exp = time.Now().Add(s.opts.TTL)
+			}
+			permits[permit] = exp
+			acquired = true
+			return true
+		})
+		if err != nil {
+			return "", err
+		} else if acquired {
+			return permit, nil
+		}
+
+		select {
+		case <-time.After(s.s.interval):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+func (s *storeSemaphore) Release(permit string) error {
+	return s.update(func(permits map[string]time.Time) bool {
+		if _, ok := permits[permit]; !ok {
+			return false
+		}
+		delete(permits, permit)
+		return true
+	})
+}
+
+func (s *storeSync) RateLimiter(id string, limit int, interval time.Duration) (sync.RateLimiter, error) {
+	return &storeRateLimiter{s: s, id: id, limit: limit, interval: interval}, nil
+}
+
+func (r *storeRateLimiter) Allow() (bool, error) {
+	w, _ := sync.Window(time.Now(), r.interval)
+	key := r.s.key("rate", fmt.Sprintf("%s/%d", r.id, w))
+
+	for {
+		rec := &gostore.Record{Key: key, Value: []byte("1"), Expiry: r.interval * 2}
+		opt := gostore.WriteIfNotExists()
+
+		recs, err := r.s.store.Read(key)
+		if err != nil && err != gostore.ErrNotFound {
+			return false, err
+		} else if err == nil {
+			count, err := strconv.Atoi(string(recs[0].Value))
+			if err != nil {
+				return false, err
+			}
+			if count >= r.limit {
+				return false, nil
+			}
+			rec.Value = []byte(strconv.Itoa(count + 1))
+			rec.Version = recs[0].Version
+			opt = gostore.WriteIfMatch()
+		}
+
+		err = r.s.store.Write(rec, opt)
+		if err == gostore.ErrConflict {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+}
+
+func (r *storeRateLimiter) Wait(ctx context.Context) error {
+	return sync.WaitAllow(ctx, r.interval, r.Allow)
+}
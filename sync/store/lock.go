@@ -0,0 +1,284 @@
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/logger"
+	gostore "github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+// heldLock is a lock held by the sync, exit stops its renewal
+type heldLock struct {
+	token string
+	exit  chan bool
+}
+
+// updateLock reads the holders of the lock, applies the func and writes them if they changed.
+// The holders are kept in a single record so readers and writers are checked atomically.
+func (s *storeSync) updateLock(id string, fn func(holders map[string]*sync.LockHolder) bool) error {
+	key := s.key("lock", id)
+
+	for {
+		holders, version, err := s.readLock(id)
+		if err != nil {
+			return err
+		}
+
+		if !fn(holders) {
+			return nil
+		}
+
+		rec := &gostore.Record{
+			Key:     key,
+			Version: version,
+			// the record expires with its last holder so it's removed if they crash
+			Expiry: s.interval,
+		}
+		for _, h := range holders {
+			if h.Expires.IsZero() {
+				rec.Expiry = 0
+				break
+			}
+			if d := time.Until(h.Expires); d > rec.Expiry {
+				rec.Expiry = d
+			}
+		}
+		rec.Value, err = json.Marshal(holders)
+		if err != nil {
+			return err
+		}
+
+		opt := gostore.WriteIfMatch()
+		if version == 0 {
+			opt = gostore.WriteIfNotExists()
+		}
+
+		err = s.store.Write(rec, opt)
+		if err == gostore.ErrConflict {
+			continue
+		}
+		return err
+	}
+}
+
+// readLock returns the holders of the lock which haven't expired and the version of the record
+func (s *storeSync) readLock(id string) (map[string]*sync.LockHolder, uint64, error) {
+	holders := make(map[string]*sync.LockHolder)
+
+	recs, err := s.store.Read(s.key("lock", id))
+	if err == gostore.ErrNotFound {
+		return holders, 0, nil
+	} else if err != nil {
+		return nil, 0, err
+	}
+	if err := json.Unmarshal(recs[0].Value, &holders); err != nil {
+		return nil, 0, err
+	}
+
+	for k, h := range holders {
+		if !h.Expires.IsZero() && time.Now().After(h.Expires) {
+			delete(holders, k)
+		}
+	}
+	return holders, recs[0].Version, nil
+}
+
+func (s *storeSync) acquire(id string, read bool, opts ...sync.LockOption) (*heldLock, error) {
+	options := sync.NewLockOptions(opts...)
+	if options.Renew && options.TTL <= 0 {
+		options.TTL = DefaultTTL
+	}
+
+	var wait <-chan time.Time
+	if options.Wait > 0 {
+		t := time.NewTimer(options.Wait)
+		defer t.Stop()
+		wait = t.C
+	}
+
+	token := uuid.New().String()
+
+	for {
+		var acquired bool
+		err := s.updateLock(id, func(holders map[string]*sync.LockHolder) bool {
+			// readers only wait for a writer
+			for _, h := range holders {
+				if !read || !h.Read {
+					return false
+				}
+			}
+
+			h := &sync.LockHolder{
+				Id:       id,
+				Owner:    options.Owner,
+				Metadata: options.Metadata,
+				Read:     read,
+				Acquired: time.Now(),
+			}
+			if options.TTL > 0 {
+				h.Expires = h.Acquired.Add(options.TTL)
+			}
+			holders[token] = h
+			acquired = true
+			return true
+		})
+		if err != nil {
+			return nil, err
+		} else if acquired {
+			break
+		}
+
+		t := time.NewTimer(s.interval)
+		select {
+		case <-t.C:
+		case <-wait:
+			t.Stop()
+			return nil, sync.ErrLockTimeout
+		}
+	}
+
+	held := &heldLock{token: token, exit: make(chan bool)}
+	if options.Renew {
+		go s.renew(id, held, options.TTL)
+	}
+	return held, nil
+}
+
+// renew the lock until it's released, the renewal stops if the lock
+// is lost e.g because it was force released or the renewals failed
+func (s *storeSync) renew(id string, held *heldLock, ttl time.Duration) {
+	t := time.NewTicker(ttl / 3)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+		case <-held.exit:
+			return
+		}
+
+		var lost bool
+		err := s.updateLock(id, func(holders map[string]*sync.LockHolder) bool {
+			h, ok := holders[held.token]
+			if !ok {
+				lost = true
+				return false
+			}
+			h.Expires = time.Now().Add(ttl)
+			return true
+		})
+		if err != nil {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Error renewing the lock %s: %v", id, err)
+			}
+			continue
+		}
+		if lost {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Lost the lock %s", id)
+			}
+			return
+		}
+	}
+}
+
+// release the lock held, it's a noop if the lock was lost
+func (s *storeSync) release(id string, held *heldLock) error {
+	close(held.exit)
+
+	return s.updateLock(id, func(holders map[string]*sync.LockHolder) bool {
+		if _, ok := holders[held.token]; !ok {
+			return false
+		}
+		delete(holders, held.token)
+		return true
+	})
+}
+
+func (s *storeSync) Lock(id string, opts ...sync.LockOption) error {
+	held, err := s.acquire(id, false, opts...)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.locks[id] = held
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *storeSync) Unlock(id string) error {
+	s.mu.Lock()
+	held, ok := s.locks[id]
+	delete(s.locks, id)
+	s.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return s.release(id, held)
+}
+
+func (s *storeSync) RLock(id string, opts ...sync.LockOption) error {
+	held, err := s.acquire(id, true, opts...)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.reads[id] = append(s.reads[id], held)
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *storeSync) RUnlock(id string) error {
+	s.mu.Lock()
+	reads := s.reads[id]
+	if len(reads) == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	held := reads[len(reads)-1]
+	if len(reads) == 1 {
+		delete(s.reads, id)
+	} else {
+		s.reads[id] = reads[:len(reads)-1]
+	}
+	s.mu.Unlock()
+
+	return s.release(id, held)
+}
+
+func (s *storeSync) Holders(id string) ([]*sync.LockHolder, error) {
+	holders, _, err := s.readLock(id)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*sync.LockHolder, 0, len(holders))
+	for _, h := range holders {
+		list = append(list, h)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Acquired.Before(list[j].Acquired)
+	})
+	return list, nil
+}
+
+func (s *storeSync) ForceUnlock(id string) error {
+	return s.updateLock(id, func(holders map[string]*sync.LockHolder) bool {
+		if len(holders) == 0 {
+			return false
+		}
+		for k := range holders {
+			delete(holders, k)
+		}
+		return true
+	})
+}
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	gostore "github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+type storeKey struct{}
+
+type intervalKey struct{}
+
+// Store the locks and leases are written to, it must support conditional writes and expiry
+func Store(s gostore.Store) sync.Option {
+	return func(o *sync.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, storeKey{}, s)
+	}
+}
+
+// Interval the store is polled at while waiting for a lock or leadership and when observing a leader
+func Interval(d time.Duration) sync.Option {
+	return func(o *sync.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, intervalKey{}, d)
+	}
+}
@@ -0,0 +1,374 @@
+// Package store is a store backed implementation of sync. Locks and leases are records
+// written with conditional writes which expire unless they're renewed. Use it with the
+// redis store for a redis backed sync.
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	gosync "sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v3/logger"
+	gostore "github.com/micro/go-micro/v3/store"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+var (
+	// DefaultInterval the store is polled at
+	DefaultInterval = time.Second
+	// DefaultTTL of a leader's lease
+	DefaultTTL = time.Second * 15
+)
+
+type storeSync struct {
+	options  sync.Options
+	store    gostore.Store
+	interval time.Duration
+
+	mu gosync.Mutex
+	// the locks and read locks held
+	locks map[string]*heldLock
+	reads map[string][]*heldLock
+}
+
+type storeLeader struct {
+	s      *storeSync
+	id     string
+	ttl    time.Duration
+	once   gosync.Once
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    gosync.Mutex
+	lease *sync.Lease
+}
+
+type storeObserver struct {
+	s    *storeSync
+	id   string
+	last *sync.Lease
+	exit chan bool
+	once gosync.Once
+}
+
+func (s *storeSync) key(kind, id string) string {
+	return "sync/" + kind + "/" + s.options.Prefix + id
+}
+
+func (s *storeSync) Init(opts ...sync.Option) error {
+	for _, o := range opts {
+		o(&s.options)
+	}
+	s.configure()
+	return nil
+}
+
+func (s *storeSync) configure() {
+	if s.options.Context == nil {
+		return
+	}
+	if st, ok := s.options.Context.Value(storeKey{}).(gostore.Store); ok {
+		s.store = st
+	}
+	if d, ok := s.options.Context.Value(intervalKey{}).(time.Duration); ok && d > 0 {
+		s.interval = d
+	}
+}
+
+func (s *storeSync) Options() sync.Options {
+	return s.options
+}
+
+// lease reads the current lease of the election, nil if there's no leader. The lease
+// is null rather than deleted once resigned, the version of the record is returned.
+func (s *storeSync) lease(id string) (*sync.Lease, uint64, error) {
+	recs, err := s.store.Read(s.key("leader", id))
+	if err == gostore.ErrNotFound {
+		return nil, 0, nil
+	} else if err != nil {
+		return nil, 0, err
+	}
+
+	var l *sync.Lease
+	if err := json.Unmarshal(recs[0].Value, &l); err != nil {
+		return nil, 0, err
+	}
+	return l, recs[0].Version, nil
+}
+
+// writeLease writes the lease if the version matches or for a new lease if there's none
+func (s *storeSync) writeLease(l *sync.Lease, version uint64, ttl time.Duration) error {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	rec := &gostore.Record{
+		Key:     s.key("leader", l.Id),
+		Value:   b,
+		Expiry:  ttl,
+		Version: version,
+	}
+	if version == 0 {
+		return s.store.Write(rec, gostore.WriteIfNotExists())
+	}
+	return s.store.Write(rec, gostore.WriteIfMatch())
+}
+
+// token increments and returns the fencing token of the election, the token is kept
+// in a record which doesn't expire so it increases even when leases expire
+func (s *storeSync) token(id string) (uint64, error) {
+	key := s.key("token", id)
+
+	for {
+		rec := &gostore.Record{Key: key, Value: []byte("1")}
+		var opts []gostore.WriteOption
+
+		recs, err := s.store.Read(key)
+		if err == gostore.ErrNotFound {
+			opts = append(opts, gostore.WriteIfNotExists())
+		} else if err != nil {
+			return 0, err
+		} else {
+			n, err := strconv.ParseUint(string(recs[0].Value), 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			rec.Value = []byte(strconv.FormatUint(n+1, 10))
+			rec.Version = recs[0].Version
+			opts = append(opts, gostore.WriteIfMatch())
+		}
+
+		err = s.store.Write(rec, opts...)
+		if err == gostore.ErrConflict {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+
+		return strconv.ParseUint(string(rec.Value), 10, 64)
+	}
+}
+
+func (s *storeSync) Leader(id string, opts ...sync.LeaderOption) (sync.Leader, error) {
+	options := sync.LeaderOptions{
+		Node: uuid.New().String(),
+		TTL:  DefaultTTL,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.TTL <= 0 {
+		options.TTL = DefaultTTL
+	}
+
+	// campaign until there's no leader and our lease is written
+	var lease *sync.Lease
+	for {
+		current, version, err := s.lease(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if current == nil {
+			token, err := s.token(id)
+			if err != nil {
+				return nil, err
+			}
+
+			lease = &sync.Lease{
+				Id:      id,
+				Node:    options.Node,
+				Token:   token,
+				Expires: time.Now().Add(options.TTL),
+			}
+
+			// the version is set when the lease was cleared by a resign
+			err = s.writeLease(lease, version, options.TTL)
+			if err == nil {
+				break
+			} else if err != gostore.ErrConflict {
+				return nil, err
+			}
+		}
+
+		time.Sleep(s.interval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &storeLeader{
+		s:      s,
+		id:     id,
+		ttl:    options.TTL,
+		lease:  lease,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	go l.renew()
+
+	return l, nil
+}
+
+// renew the lease until it's lost or resigned
+func (l *storeLeader) renew() {
+	defer l.cancel()
+
+	t := time.NewTicker(l.ttl / 3)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+		case <-l.ctx.Done():
+			return
+		}
+
+		l.mu.Lock()
+		lease := *l.lease
+		l.mu.Unlock()
+
+		current, version, err := l.s.lease(l.id)
+		if err == nil && (current == nil || current.Token != lease.Token) {
+			// another node is the leader
+			return
+		}
+
+		if err == nil {
+			lease.Expires = time.Now().Add(l.ttl)
+			err = l.s.writeLease(&lease, version, l.ttl)
+		}
+
+		if err == gostore.ErrConflict {
+			return
+		} else if err != nil {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Error renewing the lease of %s: %v", l.id, err)
+			}
+			// the store may recover before the lease expires
+			if time.Now().After(lease.Expires) {
+				return
+			}
+			continue
+		}
+
+		l.mu.Lock()
+		l.lease = &lease
+		l.mu.Unlock()
+	}
+}
+
+func (l *storeLeader) Resign() error {
+	var err error
+
+	l.once.Do(func() {
+		l.cancel()
+
+		current, version, rerr := l.s.lease(l.id)
+		if rerr != nil {
+			err = rerr
+			return
+		}
+		if current == nil || current.Token != l.Lease().Token {
+			return
+		}
+
+		// clear the lease only if it's unchanged since it was read, a new
+		// leader may have written its lease since ours expired
+		err = l.s.store.Write(&gostore.Record{
+			Key:     l.s.key("leader", l.id),
+			Value:   []byte("null"),
+			Expiry:  l.ttl,
+			Version: version,
+		}, gostore.WriteIfMatch())
+		if err == gostore.ErrConflict {
+			err = nil
+		}
+	})
+
+	return err
+}
+
+func (l *storeLeader) Status() chan bool {
+	ch := make(chan bool, 1)
+	go func() {
+		<-l.ctx.Done()
+		ch <- true
+		close(ch)
+	}()
+	return ch
+}
+
+func (l *storeLeader) Lease() *sync.Lease {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lease := *l.lease
+	return &lease
+}
+
+func (l *storeLeader) Context() context.Context {
+	return l.ctx
+}
+
+func (s *storeSync) ObserveLeader(id string) (sync.Observer, error) {
+	return &storeObserver{
+		s:    s,
+		id:   id,
+		exit: make(chan bool),
+	}, nil
+}
+
+func (o *storeObserver) Next() (*sync.Lease, error) {
+	for {
+		current, _, err := o.s.lease(o.id)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			current = &sync.Lease{Id: o.id}
+		}
+
+		// return the current lease first and then on every change of leader
+		if o.last == nil || o.last.Node != current.Node || o.last.Token != current.Token {
+			o.last = current
+			return current, nil
+		}
+
+		select {
+		case <-time.After(o.s.interval):
+		case <-o.exit:
+			return nil, sync.ErrObserverStopped
+		}
+	}
+}
+
+func (o *storeObserver) Stop() {
+	o.once.Do(func() {
+		close(o.exit)
+	})
+}
+
+func (s *storeSync) String() string {
+	return "store"
+}
+
+// NewSync returns a sync backed by the store set with the Store option, the default store is used otherwise
+func NewSync(opts ...sync.Option) sync.Sync {
+	var options sync.Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	s := &storeSync{
+		options:  options,
+		store:    gostore.DefaultStore,
+		interval: DefaultInterval,
+		locks:    make(map[string]*heldLock),
+		reads:    make(map[string][]*heldLock),
+	}
+	s.configure()
+
+	return s
+}
@@ -0,0 +1,273 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/store/memory"
+	"github.com/micro/go-micro/v3/sync"
+)
+
+func TestLock(t *testing.T) {
+	s := NewSync(Store(memory.NewStore()), Interval(time.Millisecond*10))
+
+	if err := s.Lock("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Lock("foo", sync.LockWait(time.Millisecond*50)); err != sync.ErrLockTimeout {
+		t.Fatalf("Expected a lock timeout, got %v", err)
+	}
+	if err := s.Unlock("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Lock("foo", sync.LockWait(time.Millisecond*50)); err != nil {
+		t.Fatalf("Expected the lock after unlocking, got %v", err)
+	}
+
+	// the lock expires
+	if err := s.Lock("bar", sync.LockTTL(time.Millisecond*50)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Lock("bar", sync.LockWait(time.Second)); err != nil {
+		t.Fatalf("Expected the lock to expire, got %v", err)
+	}
+}
+
+func TestLeader(t *testing.T) {
+	st := memory.NewStore()
+	s := NewSync(Store(st), Interval(time.Millisecond*10))
+
+	o, err := s.ObserveLeader("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Stop()
+
+	if l, err := o.Next(); err != nil || len(l.Node) > 0 {
+		t.Fatalf("Expected no leader, got %+v %v", l, err)
+	}
+
+	a, err := s.Leader("foo", sync.LeaderNode("a"), sync.LeaderTTL(time.Millisecond*300))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, _ := o.Next(); l.Node != "a" || l.Token != 1 {
+		t.Fatalf("Expected leader a with token 1, got %+v", l)
+	}
+
+	// the lease is renewed past its ttl
+	time.Sleep(time.Millisecond * 500)
+	if a.Context().Err() != nil {
+		t.Fatal("Expected a to still be the leader")
+	}
+	if !a.Lease().Expires.After(time.Now()) {
+		t.Fatalf("Expected the lease to be renewed, expires %v", a.Lease().Expires)
+	}
+
+	// the lease is lost mid work
+	if err := st.Delete(s.(*storeSync).key("leader", "foo")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-a.Status():
+	case <-time.After(time.Second):
+		t.Fatal("Expected a to lose the leadership")
+	}
+
+	b, err := s.Leader("foo", sync.LeaderNode("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Lease().Token != 2 {
+		t.Fatalf("Expected token 2, got %v", b.Lease().Token)
+	}
+
+	// b resigns gracefully
+	if err := b.Resign(); err != nil {
+		t.Fatal(err)
+	}
+	if b.Context().Err() == nil {
+		t.Fatal("Expected the context of b to be cancelled")
+	}
+	for {
+		l, err := o.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(l.Node) == 0 {
+			break
+		}
+	}
+
+	// c is elected once b resigned
+	c, err := s.Leader("foo", sync.LeaderNode("c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the lease of c isn't cleared by the resign of a stale leader
+	d := &storeLeader{s: s.(*storeSync), id: "foo", ttl: time.Second, lease: b.Lease(), cancel: func() {}}
+	if err := d.Resign(); err != nil {
+		t.Fatal(err)
+	}
+	if l, _, err := s.(*storeSync).lease("foo"); err != nil || l == nil || l.Node != "c" {
+		t.Fatalf("Expected c to still be the leader, got %+v %v", l, err)
+	}
+	if c.Context().Err() != nil {
+		t.Fatal("Expected c to still be the leader")
+	}
+}
+
+func TestSemaphore(t *testing.T) {
+	s := NewSync(Store(memory.NewStore()), Interval(time.Millisecond*10))
+
+	sem, err := s.Semaphore("foo", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := sem.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if _, err := sem.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected the deadline to be exceeded, got %v", err)
+	}
+
+	if err := sem.Release(p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sem.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	s := NewSync(Store(memory.NewStore()), Interval(time.Millisecond*10))
+	interval := time.Millisecond * 200
+
+	r, err := s.RateLimiter("foo", 2, interval)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// start at the beginning of a window
+	_, next := sync.Window(time.Now(), interval)
+	time.Sleep(time.Until(next))
+
+	for i := 0; i < 2; i++ {
+		if ok, err := r.Allow(); err != nil || !ok {
+			t.Fatalf("Expected to be allowed, got %v %v", ok, err)
+		}
+	}
+	if ok, err := r.Allow(); err != nil || ok {
+		t.Fatalf("Expected to be limited, got %v %v", ok, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRWLock(t *testing.T) {
+	st := memory.NewStore()
+	s1 := NewSync(Store(st), Interval(time.Millisecond*10))
+	s2 := NewSync(Store(st), Interval(time.Millisecond*10))
+
+	if err := s1.RLock("foo", sync.LockOwner("s1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.RLock("foo", sync.LockOwner("s2"), sync.LockWait(time.Millisecond*50)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.Lock("foo", sync.LockWait(time.Millisecond*50)); err != sync.ErrLockTimeout {
+		t.Fatalf("Expected a lock timeout, got %v", err)
+	}
+
+	holders, err := s2.Holders("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holders) != 2 || holders[0].Owner != "s1" || holders[1].Owner != "s2" || !holders[1].Read {
+		t.Fatalf("Unexpected holders %+v", holders)
+	}
+
+	if err := s1.RUnlock("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.RUnlock("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.Lock("foo", sync.LockWait(time.Millisecond*50)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s1.RLock("foo", sync.LockWait(time.Millisecond*50)); err != sync.ErrLockTimeout {
+		t.Fatalf("Expected a lock timeout, got %v", err)
+	}
+}
+
+func TestLockRenew(t *testing.T) {
+	st := memory.NewStore()
+	s1 := NewSync(Store(st), Interval(time.Millisecond*10))
+	s2 := NewSync(Store(st), Interval(time.Millisecond*10))
+
+	// the renewed lock is held past its ttl
+	if err := s1.Lock("foo", sync.LockTTL(time.Millisecond*60), sync.LockRenew()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.Lock("foo", sync.LockWait(time.Millisecond*200)); err != sync.ErrLockTimeout {
+		t.Fatalf("Expected a lock timeout, got %v", err)
+	}
+
+	// a force release stops the renewal rather than the lock being renewed again
+	if err := s2.ForceUnlock("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.Lock("foo", sync.LockWait(time.Millisecond*50)); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 100)
+	if holders, _ := s2.Holders("foo"); len(holders) != 1 {
+		t.Fatalf("Expected a single holder, got %+v", holders)
+	}
+
+	// unlocking the lost lock doesn't release the new holder
+	if err := s1.Unlock("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s1.Lock("foo", sync.LockWait(time.Millisecond*50)); err != sync.ErrLockTimeout {
+		t.Fatalf("Expected a lock timeout, got %v", err)
+	}
+}
+
+func TestLockExpired(t *testing.T) {
+	st := memory.NewStore()
+	s1 := NewSync(Store(st), Interval(time.Millisecond*10))
+	s2 := NewSync(Store(st), Interval(time.Millisecond*10))
+
+	// the lock expires without renewal and is taken by another
+	if err := s1.Lock("foo", sync.LockTTL(time.Millisecond*30)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.Lock("foo", sync.LockWait(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the expired holder unlocking doesn't release it
+	if err := s1.Unlock("foo"); err != nil {
+		t.Fatal(err)
+	}
+	holders, err := s1.Holders("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holders) != 1 || !holders[0].Expires.IsZero() {
+		t.Fatalf("Unexpected holders %+v", holders)
+	}
+}
@@ -2,12 +2,15 @@
 package sync
 
 import (
+	"context"
 	"errors"
 	"time"
 )
 
 var (
 	ErrLockTimeout = errors.New("lock timeout")
+	// ErrObserverStopped is returned by an observer which has been stopped
+	ErrObserverStopped = errors.New("observer stopped")
 )
 
 // Sync is an interface for distributed synchronization
@@ -16,12 +19,26 @@ type Sync interface {
 	Init(...Option) error
 	// Return the options
 	Options() Options
-	// Elect a leader
+	// Elect a leader, blocks until elected
 	Leader(id string, opts ...LeaderOption) (Leader, error)
+	// ObserveLeader watches the leader of an election
+	ObserveLeader(id string) (Observer, error)
 	// Lock acquires a lock
 	Lock(id string, opts ...LockOption) error
 	// Unlock releases a lock
 	Unlock(id string) error
+	// RLock acquires a read lock, it's shared with other readers and exclusive of Lock
+	RLock(id string, opts ...LockOption) error
+	// RUnlock releases a read lock
+	RUnlock(id string) error
+	// Holders returns who holds a lock, it's empty if the lock isn't held
+	Holders(id string) ([]*LockHolder, error)
+	// ForceUnlock releases a lock and its read locks whoever holds them
+	ForceUnlock(id string) error
+	// Semaphore limits the concurrent holders of the id to the limit
+	Semaphore(id string, limit int, opts ...SemaphoreOption) (Semaphore, error)
+	// RateLimiter limits the events of the id to the limit per interval
+	RateLimiter(id string, limit int, interval time.Duration) (RateLimiter, error)
 	// Sync implementation
 	String() string
 }
@@ -32,22 +49,101 @@ type Leader interface {
 	Resign() error
 	// status returns when leadership is lost
 	Status() chan bool
+	// Lease held by the leader
+	Lease() *Lease
+	// Context is cancelled when leadership is lost or resigned so
+	// work can be stopped mid way rather than after it completes
+	Context() context.Context
+}
+
+// Lease of the leadership of an election
+type Lease struct {
+	// Id of the election
+	Id string
+	// Node holding the lease, empty when there's no leader
+	Node string
+	// Token is a fencing token which increases with every new leader. Pass it with
+	// writes to downstream services so they can reject the writes of a stale leader.
+	Token uint64
+	// Expires is when the lease expires unless it's renewed, zero if it doesn't expire
+	Expires time.Time
+}
+
+// Observer returns the changes of leader of an election
+type Observer interface {
+	// Next blocks until the leader changes, the current lease is returned first
+	Next() (*Lease, error)
+	// Stop observing
+	Stop()
+}
+
+// LockHolder describes the holder of a lock
+type LockHolder struct {
+	// Id of the lock
+	Id string
+	// Owner of the lock, the hostname and pid of the process unless set with LockOwner
+	Owner string
+	// Metadata set with LockMetadata
+	Metadata map[string]string
+	// Read is true for the holder of a read lock
+	Read bool
+	// Acquired is when the lock was acquired
+	Acquired time.Time
+	// Expires is when the lock expires unless it's renewed, zero if it doesn't expire
+	Expires time.Time
+}
+
+// Semaphore limits the number of concurrent holders of a shared resource across the nodes
+type Semaphore interface {
+	// Acquire a permit, blocks until one is available or the context is done
+	Acquire(ctx context.Context) (string, error)
+	// Release the permit
+	Release(permit string) error
+}
+
+// RateLimiter limits the rate of events across the nodes, the events
+// are counted in fixed windows of the interval
+type RateLimiter interface {
+	// Allow returns true and counts the event if it's allowed now
+	Allow() (bool, error)
+	// Wait blocks until an event is allowed or the context is done
+	Wait(ctx context.Context) error
 }
 
 type Options struct {
 	Nodes  []string
 	Prefix string
+	// Other options for implementations of the interface
+	// can be stored in a context
+	Context context.Context
 }
 
 type Option func(o *Options)
 
-type LeaderOptions struct{}
+type LeaderOptions struct {
+	// Node is the id of the candidate, a random id is used if not set
+	Node string
+	// TTL of the lease, it's renewed while the leader is alive
+	TTL time.Duration
+}
 
 type LeaderOption func(o *LeaderOptions)
 
 type LockOptions struct {
 	TTL  time.Duration
 	Wait time.Duration
+	// Renew the lock before the ttl is reached while it's held
+	Renew bool
+	// Owner and metadata of the holder
+	Owner    string
+	Metadata map[string]string
 }
 
 type LockOption func(o *LockOptions)
+
+type SemaphoreOptions struct {
+	// TTL of a permit after which it's released if the holder hasn't, zero never expires
+	TTL time.Duration
+}
+
+type SemaphoreOption func(o *SemaphoreOptions)
@@ -0,0 +1,59 @@
+// Package fault simulates the failures of the in memory registry and broker so tests can
+// verify how clients behave when discovery and messaging misbehave.
+package fault
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Faults applied to the events of the registry and the messages of the broker
+type Faults struct {
+	// Delay before an event or message is delivered
+	Delay time.Duration
+	// Drop is the probability between 0 and 1 an event or message isn't delivered
+	Drop float64
+}
+
+// Dropped decides if an event or message is dropped
+func (f Faults) Dropped() bool {
+	return f.Drop > 0 && rand.Float64() < f.Drop
+}
+
+// Partition splits nodes into groups which can't reach each other. Nodes are identified by
+// an id, those which aren't in any group reach every other node.
+type Partition struct {
+	sync.RWMutex
+	groups map[string]int
+}
+
+// Split the nodes into the groups, replacing any previous split
+func (p *Partition) Split(groups ...[]string) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.groups = make(map[string]int)
+	for i, g := range groups {
+		for _, id := range g {
+			p.groups[id] = i
+		}
+	}
+}
+
+// Heal the partition so every node reaches every other
+func (p *Partition) Heal() {
+	p.Lock()
+	p.groups = nil
+	p.Unlock()
+}
+
+// Reachable returns true if the nodes can reach each other
+func (p *Partition) Reachable(a, b string) bool {
+	p.RLock()
+	defer p.RUnlock()
+
+	ga, oka := p.groups[a]
+	gb, okb := p.groups[b]
+	return !oka || !okb || ga == gb
+}
@@ -0,0 +1,43 @@
+package fault
+
+import "testing"
+
+func TestPartition(t *testing.T) {
+	var p Partition
+
+	if !p.Reachable("a", "b") {
+		t.Fatal("Expected nodes to reach each other without a partition")
+	}
+
+	p.Split([]string{"a", "b"}, []string{"c"})
+
+	testCases := []struct {
+		a, b   string
+		expect bool
+	}{
+		{"a", "b", true},
+		{"a", "c", false},
+		{"c", "b", false},
+		{"a", "d", true},
+		{"d", "c", true},
+	}
+	for _, tc := range testCases {
+		if got := p.Reachable(tc.a, tc.b); got != tc.expect {
+			t.Errorf("Expected %s reaching %s to be %v, got %v", tc.a, tc.b, tc.expect, got)
+		}
+	}
+
+	p.Heal()
+	if !p.Reachable("a", "c") {
+		t.Fatal("Expected nodes to reach each other once healed")
+	}
+}
+
+func TestDropped(t *testing.T) {
+	if (Faults{}).Dropped() {
+		t.Fatal("Expected nothing to be dropped")
+	}
+	if !(Faults{Drop: 1}).Dropped() {
+		t.Fatal("Expected everything to be dropped")
+	}
+}
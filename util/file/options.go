@@ -2,14 +2,37 @@ package file
 
 import "context"
 
+// DefaultChunkSize is the size of the chunks files are streamed in
+var DefaultChunkSize = 64 * 1024
+
 type Options struct {
 	Context context.Context
+	// ChunkSize is the size of the chunks files are streamed in
+	ChunkSize int
 }
 
 type Option func(o *Options)
 
+func newOptions(opts ...Option) Options {
+	options := Options{
+		Context:   context.TODO(),
+		ChunkSize: DefaultChunkSize,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
+
 func WithContext(ctx context.Context) Option {
 	return func(o *Options) {
 		o.Context = ctx
 	}
 }
+
+// ChunkSize sets the size of the chunks files are streamed in
+func ChunkSize(n int) Option {
+	return func(o *Options) {
+		o.ChunkSize = n
+	}
+}
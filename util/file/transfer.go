@@ -0,0 +1,204 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/micro/go-micro/v3/client"
+)
+
+const (
+	// ContentType of the transfer streams
+	ContentType = "application/json"
+	// the suffix of the files being transferred, which are kept to resume the transfer
+	partSuffix = ".part"
+)
+
+var (
+	// ErrChecksum is returned when the transferred file doesn't match the checksum of the original
+	ErrChecksum = errors.New("checksum mismatch")
+)
+
+// Message is the frame exchanged over the transfer streams. The first message of
+// each side is a header describing the file, followed by the chunks of data and
+// a message flagging the end of the file.
+type Message struct {
+	Name     string `json:"name,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	Offset   int64  `json:"offset,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+	Eof      bool   `json:"eof,omitempty"`
+}
+
+// SendFile streams the local file at path to the transfer handler of the service, which
+// stores it under name. An interrupted transfer is resumed from the offset the service
+// received, and the file is only stored once its checksum is verified.
+func SendFile(c client.Client, service, name, path string, opts ...Option) error {
+	options := newOptions(opts...)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	sum, err := checksum(f)
+	if err != nil {
+		return err
+	}
+
+	req := c.NewRequest(service, "Transfer.Upload", &Message{}, client.WithContentType(ContentType))
+	stream, err := c.Stream(options.Context, req)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	if err := stream.Send(&Message{Name: name, Size: fi.Size(), Checksum: sum}); err != nil {
+		return err
+	}
+
+	// the service replies with the offset to resume from
+	var hdr Message
+	if err := stream.Recv(&hdr); err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(hdr.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	offset := hdr.Offset
+	buf := make([]byte, options.ChunkSize)
+
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&Message{Offset: offset, Data: buf[:n]}); err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := stream.Send(&Message{Offset: offset, Eof: true}); err != nil {
+		return err
+	}
+
+	var rsp Message
+	if err := stream.Recv(&rsp); err != nil {
+		return err
+	}
+
+	if rsp.Checksum != sum {
+		return ErrChecksum
+	}
+
+	return nil
+}
+
+// ReceiveFile streams the file stored under name by the transfer handler of the service
+// to the local path. An interrupted transfer is resumed from what was already received,
+// and the file is only moved to path once its checksum is verified.
+func ReceiveFile(c client.Client, service, name, path string, opts ...Option) error {
+	options := newOptions(opts...)
+
+	part := path + partSuffix
+
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	req := c.NewRequest(service, "Transfer.Download", &Message{}, client.WithContentType(ContentType))
+	stream, err := c.Stream(options.Context, req)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	if err := stream.Send(&Message{Name: name, Offset: fi.Size()}); err != nil {
+		return err
+	}
+
+	var hdr Message
+	if err := stream.Recv(&hdr); err != nil {
+		return err
+	}
+
+	// the service restarts the transfer if what was received doesn't fit the file
+	if err := f.Truncate(hdr.Offset); err != nil {
+		return err
+	}
+
+	for {
+		var msg Message
+		if err := stream.Recv(&msg); err != nil {
+			return err
+		}
+		if msg.Eof {
+			break
+		}
+		if _, err := f.WriteAt(msg.Data, msg.Offset); err != nil {
+			return err
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	sum, err := checksumFile(part)
+	if err != nil {
+		return err
+	}
+
+	if sum != hdr.Checksum {
+		os.Remove(part)
+		return ErrChecksum
+	}
+
+	return os.Rename(part, path)
+}
+
+// checksum returns the hex encoded sha256 of the file, rewinding it afterwards
+func checksum(f *os.File) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func checksumFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return checksum(f)
+}
@@ -0,0 +1,198 @@
+package file
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/server"
+)
+
+// Transfer is a handler streaming the files of a directory, to be used
+// with SendFile and ReceiveFile
+type Transfer struct {
+	dir  string
+	opts Options
+}
+
+// NewTransfer returns a transfer handler storing and serving the files of dir
+func NewTransfer(dir string, opts ...Option) *Transfer {
+	return &Transfer{
+		dir:  dir,
+		opts: newOptions(opts...),
+	}
+}
+
+// RegisterTransfer is a convenience method for registering a transfer handler
+func RegisterTransfer(s server.Server, dir string, opts ...Option) error {
+	return s.Handle(s.NewHandler(NewTransfer(dir, opts...)))
+}
+
+// path returns the path of the file in the directory, the name can't escape it
+func (t *Transfer) path(name string) string {
+	return filepath.Join(t.dir, filepath.Clean("/"+name))
+}
+
+// header receives the header of the stream. Like the other bidirectional streams the
+// request body may be sent as an empty message first, which is skipped.
+func (t *Transfer) header(stream server.Stream) (*Message, error) {
+	for {
+		msg := new(Message)
+		if err := stream.Recv(msg); err != nil {
+			return nil, err
+		}
+		if len(msg.Name) > 0 {
+			return msg, nil
+		}
+	}
+}
+
+// Upload receives a file sent with SendFile
+func (t *Transfer) Upload(ctx context.Context, stream server.Stream) error {
+	hdr, err := t.header(stream)
+	if err != nil {
+		return err
+	}
+
+	path := t.path(hdr.Name)
+	part := path + partSuffix
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.InternalServerError("go.micro.server", err.Error())
+	}
+
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return errors.InternalServerError("go.micro.server", err.Error())
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return errors.InternalServerError("go.micro.server", err.Error())
+	}
+
+	// resume from what was received unless it can't be part of the file
+	offset := fi.Size()
+	if offset > hdr.Size {
+		offset = 0
+	}
+	if err := f.Truncate(offset); err != nil {
+		return errors.InternalServerError("go.micro.server", err.Error())
+	}
+
+	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Debugf("Upload %s from offset %d of %d", hdr.Name, offset, hdr.Size)
+	}
+
+	if err := stream.Send(&Message{Name: hdr.Name, Size: hdr.Size, Offset: offset}); err != nil {
+		return err
+	}
+
+	for {
+		var msg Message
+		if err := stream.Recv(&msg); err != nil {
+			return err
+		}
+		if msg.Eof {
+			break
+		}
+		if msg.Offset != offset {
+			return errors.BadRequest("go.micro.server", "unexpected offset %d, expected %d", msg.Offset, offset)
+		}
+		if _, err := f.WriteAt(msg.Data, msg.Offset); err != nil {
+			return errors.InternalServerError("go.micro.server", err.Error())
+		}
+		offset += int64(len(msg.Data))
+	}
+
+	if err := f.Close(); err != nil {
+		return errors.InternalServerError("go.micro.server", err.Error())
+	}
+
+	sum, err := checksumFile(part)
+	if err != nil {
+		return errors.InternalServerError("go.micro.server", err.Error())
+	}
+
+	if sum != hdr.Checksum {
+		os.Remove(part)
+		return errors.BadRequest("go.micro.server", "%s: %v", hdr.Name, ErrChecksum)
+	}
+
+	if err := os.Rename(part, path); err != nil {
+		return errors.InternalServerError("go.micro.server", err.Error())
+	}
+
+	return stream.Send(&Message{Name: hdr.Name, Size: offset, Checksum: sum})
+}
+
+// Download sends a file received with ReceiveFile
+func (t *Transfer) Download(ctx context.Context, stream server.Stream) error {
+	hdr, err := t.header(stream)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(t.path(hdr.Name))
+	if os.IsNotExist(err) {
+		return errors.NotFound("go.micro.server", "%s not found", hdr.Name)
+	} else if err != nil {
+		return errors.InternalServerError("go.micro.server", err.Error())
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return errors.InternalServerError("go.micro.server", err.Error())
+	}
+	if fi.IsDir() {
+		return errors.BadRequest("go.micro.server", "%s is a directory", hdr.Name)
+	}
+
+	sum, err := checksum(f)
+	if err != nil {
+		return errors.InternalServerError("go.micro.server", err.Error())
+	}
+
+	// restart the transfer if what was received can't be part of the file
+	offset := hdr.Offset
+	if offset < 0 || offset > fi.Size() {
+		offset = 0
+	}
+
+	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Debugf("Download %s from offset %d of %d", hdr.Name, offset, fi.Size())
+	}
+
+	if err := stream.Send(&Message{Name: hdr.Name, Size: fi.Size(), Offset: offset, Checksum: sum}); err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return errors.InternalServerError("go.micro.server", err.Error())
+	}
+
+	buf := make([]byte, t.opts.ChunkSize)
+
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&Message{Offset: offset, Data: buf[:n]}); err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.InternalServerError("go.micro.server", err.Error())
+		}
+	}
+
+	return stream.Send(&Message{Offset: offset, Eof: true})
+}
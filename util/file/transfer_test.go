@@ -0,0 +1,111 @@
+package file
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/micro/go-micro/v3/util/test"
+)
+
+func TestTransfer(t *testing.T) {
+	env := test.NewEnv()
+	defer env.Stop()
+
+	dir, err := ioutil.TempDir("", "transfer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	remote := filepath.Join(dir, "remote")
+	local := filepath.Join(dir, "local")
+	os.MkdirAll(local, 0755)
+
+	if _, err := env.Run("files", NewTransfer(remote, ChunkSize(1024))); err != nil {
+		t.Fatal(err)
+	}
+
+	c := env.NewClient()
+
+	data := make([]byte, 10*1024+17)
+	rand.Read(data)
+
+	src := filepath.Join(local, "src")
+	if err := ioutil.WriteFile(src, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := func(path string) {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, data) {
+			t.Fatalf("Expected %s to match the source file", path)
+		}
+		if _, err := os.Stat(path + partSuffix); !os.IsNotExist(err) {
+			t.Fatalf("Expected the part of %s to be removed", path)
+		}
+	}
+
+	// send the file
+	if err := SendFile(c, "files", "a/file", src, ChunkSize(1000)); err != nil {
+		t.Fatalf("Unexpected send error: %v", err)
+	}
+	expect(filepath.Join(remote, "a", "file"))
+
+	// resume a send
+	if err := ioutil.WriteFile(filepath.Join(remote, "resumed"+partSuffix), data[:4096], 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := SendFile(c, "files", "resumed", src); err != nil {
+		t.Fatalf("Unexpected send error: %v", err)
+	}
+	expect(filepath.Join(remote, "resumed"))
+
+	// a corrupted part fails the checksum
+	if err := ioutil.WriteFile(filepath.Join(remote, "corrupted"+partSuffix), make([]byte, 4096), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := SendFile(c, "files", "corrupted", src); err == nil {
+		t.Fatal("Expected a checksum error")
+	}
+	if _, err := os.Stat(filepath.Join(remote, "corrupted")); !os.IsNotExist(err) {
+		t.Fatal("Expected the corrupted file not to be stored")
+	}
+
+	// receive the file
+	dst := filepath.Join(local, "dst")
+	if err := ReceiveFile(c, "files", "a/file", dst); err != nil {
+		t.Fatalf("Unexpected receive error: %v", err)
+	}
+	expect(dst)
+
+	// resume a receive
+	dst = filepath.Join(local, "resumed")
+	if err := ioutil.WriteFile(dst+partSuffix, data[:5000], 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReceiveFile(c, "files", "resumed", dst); err != nil {
+		t.Fatalf("Unexpected receive error: %v", err)
+	}
+	expect(dst)
+
+	// a corrupted part fails the checksum
+	dst = filepath.Join(local, "corrupted")
+	if err := ioutil.WriteFile(dst+partSuffix, make([]byte, 5000), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReceiveFile(c, "files", "resumed", dst); err != ErrChecksum {
+		t.Fatalf("Expected %v got %v", ErrChecksum, err)
+	}
+
+	// a missing file isn't found
+	if err := ReceiveFile(c, "files", "missing", filepath.Join(local, "missing")); err == nil {
+		t.Fatal("Expected a not found error")
+	}
+}
@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"net/http"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/micro/go-micro/v3/errors"
+	pberr "github.com/micro/go-micro/v3/errors/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errMapping = map[int32]codes.Code{
+	http.StatusOK:                  codes.OK,
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusRequestTimeout:      codes.DeadlineExceeded,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusPreconditionFailed:  codes.FailedPrecondition,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusInternalServerError: codes.Internal,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+var codeMapping = map[codes.Code]int32{
+	codes.OK:                 http.StatusOK,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusRequestTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusPreconditionFailed,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+}
+
+// StatusCode returns the grpc code of the http code of a micro error
+func StatusCode(code int32) codes.Code {
+	if c, ok := errMapping[code]; ok {
+		return c
+	}
+	return codes.Unknown
+}
+
+// ErrorCode returns the http code of a grpc code
+func ErrorCode(code codes.Code) int32 {
+	if c, ok := codeMapping[code]; ok {
+		return c
+	}
+	return http.StatusInternalServerError
+}
+
+// ToStatus converts the error to a grpc status. The error is attached to the status
+// along with its violations and retry info as the standard google.rpc error details
+// so they can be read by clients in other languages.
+func ToStatus(err *errors.Error) (*status.Status, error) {
+	details := []proto.Message{
+		&pberr.Error{
+			Id:     err.Id,
+			Code:   err.Code,
+			Detail: err.Detail,
+			Status: err.Status,
+		},
+	}
+
+	if len(err.Violations) > 0 {
+		br := new(errdetails.BadRequest)
+		for _, v := range err.Violations {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+		details = append(details, br)
+	}
+
+	if err.Retry != nil {
+		details = append(details, &errdetails.RetryInfo{
+			RetryDelay: ptypes.DurationProto(err.Retry.Delay),
+		})
+	}
+
+	return status.New(StatusCode(err.Code), err.Error()).WithDetails(details...)
+}
+
+// FromStatus converts a grpc status to an error. The micro error attached to the status
+// is used if there is one, otherwise the error is built from the status code and message.
+func FromStatus(s *status.Status) *errors.Error {
+	var err *errors.Error
+
+	for _, d := range s.Details() {
+		if perr, ok := d.(*pberr.Error); ok {
+			err = &errors.Error{
+				Id:     perr.Id,
+				Code:   perr.Code,
+				Detail: perr.Detail,
+				Status: perr.Status,
+			}
+			break
+		}
+	}
+
+	if err == nil {
+		code := ErrorCode(s.Code())
+		err = &errors.Error{
+			Code:   code,
+			Detail: s.Message(),
+			Status: http.StatusText(int(code)),
+		}
+	}
+
+	for _, d := range s.Details() {
+		switch v := d.(type) {
+		case *errdetails.BadRequest:
+			for _, fv := range v.FieldViolations {
+				err.AddViolation(fv.Field, fv.Description)
+			}
+		case *errdetails.RetryInfo:
+			delay, derr := ptypes.Duration(v.RetryDelay)
+			if derr != nil {
+				continue
+			}
+			err.RetryAfter(delay)
+		}
+	}
+
+	return err
+}
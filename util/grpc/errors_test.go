@@ -0,0 +1,44 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v3/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatus(t *testing.T) {
+	err := errors.BadRequest("go.micro.test", "invalid request").(*errors.Error).
+		AddViolation("email", "must be set").
+		RetryAfter(time.Second)
+
+	s, serr := ToStatus(err)
+	if serr != nil {
+		t.Fatal(serr)
+	}
+	if s.Code() != codes.InvalidArgument {
+		t.Fatalf("Expected invalid argument got %v", s.Code())
+	}
+
+	// sent over the wire as a google.rpc.Status
+	s = status.FromProto(s.Proto())
+
+	verr := FromStatus(s)
+	if verr.Id != err.Id || verr.Code != err.Code || verr.Detail != err.Detail || verr.Status != err.Status {
+		t.Fatalf("Expected %v got %v", err, verr)
+	}
+	if len(verr.Violations) != 1 || *verr.Violations[0] != *err.Violations[0] {
+		t.Fatalf("Expected the violation got %v", verr.Violations)
+	}
+	if verr.Retry == nil || verr.Retry.Delay != time.Second {
+		t.Fatalf("Expected the retry info got %v", verr.Retry)
+	}
+
+	// status of a non micro server
+	verr = FromStatus(status.New(codes.NotFound, "no such thing"))
+	if verr.Code != 404 || verr.Detail != "no such thing" {
+		t.Fatalf("Expected not found got %v", verr)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/micro/go-micro/v3/logger"
 )
 
@@ -151,6 +153,13 @@ func (r *Request) Params(p *Params) *Request {
 	return r
 }
 
+// Param adds a query parameter, unlike Params the key can be repeated
+// e.g for each argument of the command of an exec
+func (r *Request) Param(key, value string) *Request {
+	r.params.Add(key, value)
+	return r
+}
+
 // SetHeader sets a header on a request with
 // a `key` and `value`
 func (r *Request) SetHeader(key, value string) *Request {
@@ -245,6 +254,50 @@ func (r *Request) Raw() (*http.Response, error) {
 	return res, nil
 }
 
+// Websocket upgrades the request to a websocket speaking one of the
+// protocols, e.g to stream the output of an exec in a pod
+func (r *Request) Websocket(protocols ...string) (*websocket.Conn, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	req, err := r.request()
+	if err != nil {
+		return nil, err
+	}
+
+	u := *req.URL
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	} else {
+		u.Scheme = "ws"
+	}
+
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 45 * time.Second,
+		Subprotocols:     protocols,
+	}
+	if t, ok := r.client.Transport.(*http.Transport); ok {
+		dialer.TLSClientConfig = t.TLSClientConfig
+	}
+
+	ctx := r.context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	logger.Debugf("[Kubernetes] %v %v", req.Method, u.String())
+	conn, rsp, err := dialer.DialContext(ctx, u.String(), req.Header)
+	if err == websocket.ErrBadHandshake && rsp != nil {
+		// return the error of the api rather than the handshake
+		if rerr := newResponse(rsp, nil).Error(); rerr != nil {
+			return nil, rerr
+		}
+	}
+	return conn, err
+}
+
 // Options ...
 type Options struct {
 	Host        string
@@ -23,6 +23,20 @@ type Status struct {
 	Message string `json:"message"`
 	Reason  string `json:"reason"`
 	Code    int    `json:"code"`
+	// Details of the failure, e.g the exit code of an exec
+	Details *StatusDetails `json:"details,omitempty"`
+}
+
+// StatusDetails are the causes of a failure
+type StatusDetails struct {
+	Causes []StatusCause `json:"causes,omitempty"`
+}
+
+// StatusCause is a cause of a failure
+type StatusCause struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
 }
 
 // Response ...
@@ -4,6 +4,7 @@ package client
 import (
 	"bytes"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"io"
 	"io/ioutil"
@@ -11,8 +12,10 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/gorilla/websocket"
 	"github.com/micro/go-micro/v3/logger"
 	"github.com/micro/go-micro/v3/util/kubernetes/api"
 )
@@ -49,6 +52,8 @@ type Client interface {
 	Log(*Resource, ...LogOption) (io.ReadCloser, error)
 	// Watch for events
 	Watch(*Resource, ...WatchOption) (Watcher, error)
+	// Exec runs a command in a pod returning its exit code
+	Exec(*Resource, ...ExecOption) (int, error)
 }
 
 // Create creates new API object
@@ -79,6 +84,36 @@ var (
 	nameRegex = regexp.MustCompile("[^a-zA-Z0-9]+")
 )
 
+// the exec protocol multiplexes the streams over the websocket
+const (
+	execProtocol = "v4.channel.k8s.io"
+
+	execStdout = 1
+	execStderr = 2
+	execError  = 3
+)
+
+// execStatus returns the exit code of the command from the status sent on the error channel
+func execStatus(b []byte) (int, error) {
+	var status api.Status
+	if err := json.Unmarshal(b, &status); err != nil {
+		return 0, err
+	}
+	if status.Status == "Success" {
+		return 0, nil
+	}
+
+	if status.Details != nil {
+		for _, c := range status.Details.Causes {
+			if c.Reason == "ExitCode" {
+				return strconv.Atoi(c.Message)
+			}
+		}
+	}
+
+	return 0, errors.New(status.Message)
+}
+
 // SerializeResourceName removes all spacial chars from a string so it
 // can be used as a k8s resource name
 func SerializeResourceName(ns string) string {
@@ -134,6 +169,82 @@ func (c *client) Log(r *Resource, opts ...LogOption) (io.ReadCloser, error) {
 	return resp.Body, nil
 }
 
+// Exec runs a command in a pod, streaming the output over a websocket
+func (c *client) Exec(r *Resource, opts ...ExecOption) (int, error) {
+	options := ExecOptions{
+		Namespace: c.opts.Namespace,
+		Stdout:    ioutil.Discard,
+		Stderr:    ioutil.Discard,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	req := api.NewRequest(c.opts).
+		Get().
+		Resource(r.Kind).
+		SubResource("exec").
+		Name(r.Name).
+		Namespace(options.Namespace).
+		Param("stdout", "true").
+		Param("stderr", "true")
+
+	for _, arg := range options.Command {
+		req.Param("command", arg)
+	}
+	if len(options.Container) > 0 {
+		req.Param("container", options.Container)
+	}
+	if options.Context != nil {
+		req.Context(options.Context)
+	}
+
+	conn, err := req.Websocket(execProtocol)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	// stop reading the output when the context is cancelled
+	if options.Context != nil {
+		done := make(chan bool)
+		defer close(done)
+
+		go func() {
+			select {
+			case <-options.Context.Done():
+				conn.Close()
+			case <-done:
+			}
+		}()
+	}
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			return 0, nil
+		} else if err != nil {
+			if options.Context != nil && options.Context.Err() != nil {
+				return 0, options.Context.Err()
+			}
+			return 0, err
+		}
+		if len(msg) == 0 {
+			continue
+		}
+
+		// the first byte of each message is the channel
+		switch msg[0] {
+		case execStdout:
+			options.Stdout.Write(msg[1:])
+		case execStderr:
+			options.Stderr.Write(msg[1:])
+		case execError:
+			return execStatus(msg[1:])
+		}
+	}
+}
+
 // Update updates API object
 func (c *client) Update(r *Resource, opts ...UpdateOption) error {
 	options := UpdateOptions{
@@ -157,6 +268,8 @@ func (c *client) Update(r *Resource, opts ...UpdateOption) error {
 		req.Body(r.Value.(*Deployment))
 	case "pod":
 		req.Body(r.Value.(*Pod))
+	case "secret":
+		req.Body(r.Value.(*Secret))
 	default:
 		return errors.New("unsupported resource")
 	}
@@ -321,8 +434,16 @@ func NewDeployment(name, version, typ, namespace string) *Deployment {
 						Name:          "service-port",
 						ContainerPort: 8080,
 					}},
+					LivenessProbe: &Probe{
+						TCPSocket: &TCPSocketAction{
+							Port: 8080,
+						},
+						PeriodSeconds:       10,
+						InitialDelaySeconds: 30,
+						FailureThreshold:    3,
+					},
 					ReadinessProbe: &Probe{
-						TCPSocket: TCPSocketAction{
+						TCPSocket: &TCPSocketAction{
 							Port: 8080,
 						},
 						PeriodSeconds:       10,
@@ -331,6 +452,14 @@ func NewDeployment(name, version, typ, namespace string) *Deployment {
 				}},
 			},
 		},
+		// replace pods one at a time, only once the new pod is ready
+		Strategy: &DeploymentStrategy{
+			Type: "RollingUpdate",
+			RollingUpdate: &RollingUpdateDeployment{
+				MaxUnavailable: "0",
+				MaxSurge:       "1",
+			},
+		},
 	}
 
 	return &Deployment{
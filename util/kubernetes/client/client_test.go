@@ -0,0 +1,72 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestExec(t *testing.T) {
+	upgrader := websocket.Upgrader{Subprotocols: []string{execProtocol}}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/foo/pods/bar/exec" {
+			http.NotFound(w, r)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		cmd := r.URL.Query()["command"]
+		conn.WriteMessage(websocket.BinaryMessage, append([]byte{execStdout}, strings.Join(cmd, " ")...))
+		conn.WriteMessage(websocket.BinaryMessage, append([]byte{execStderr}, "warning"...))
+
+		if cmd[0] == "false" {
+			conn.WriteMessage(websocket.BinaryMessage, append([]byte{execError},
+				`{"status":"Failure","reason":"NonZeroExitCode","details":{"causes":[{"reason":"ExitCode","message":"2"}]}}`...))
+			return
+		}
+		conn.WriteMessage(websocket.BinaryMessage, append([]byte{execError}, `{"status":"Success"}`...))
+	}))
+	defer srv.Close()
+
+	c := NewLocalClient(srv.URL)
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	code, err := c.Exec(&Resource{Kind: "pod", Name: "bar"},
+		ExecNamespace("foo"),
+		ExecCommand("echo", "hello"),
+		ExecOutput(stdout, stderr),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 got %d", code)
+	}
+	if stdout.String() != "echo hello" || stderr.String() != "warning" {
+		t.Fatalf("Expected the output of the command got %q %q", stdout, stderr)
+	}
+
+	code, err = c.Exec(&Resource{Kind: "pod", Name: "bar"}, ExecNamespace("foo"), ExecCommand("false"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 2 {
+		t.Fatalf("Expected exit code 2 got %d", code)
+	}
+
+	if _, err := c.Exec(&Resource{Kind: "pod", Name: "baz"}, ExecNamespace("foo"), ExecCommand("true")); err == nil {
+		t.Fatal("Expected an error for an unknown pod")
+	}
+}
@@ -1,5 +1,10 @@
 package client
 
+import (
+	"context"
+	"io"
+)
+
 type CreateOptions struct {
 	Namespace string
 }
@@ -28,6 +33,15 @@ type WatchOptions struct {
 	Params    map[string]string
 }
 
+type ExecOptions struct {
+	Namespace string
+	Container string
+	Command   []string
+	Stdout    io.Writer
+	Stderr    io.Writer
+	Context   context.Context
+}
+
 type CreateOption func(*CreateOptions)
 type GetOption func(*GetOptions)
 type UpdateOption func(*UpdateOptions)
@@ -35,6 +49,7 @@ type DeleteOption func(*DeleteOptions)
 type ListOption func(*ListOptions)
 type LogOption func(*LogOptions)
 type WatchOption func(*WatchOptions)
+type ExecOption func(*ExecOptions)
 
 // LogParams provides additional params for logs
 func LogParams(p map[string]string) LogOption {
@@ -105,3 +120,39 @@ func WatchNamespace(ns string) WatchOption {
 		o.Namespace = SerializeResourceName(ns)
 	}
 }
+
+// ExecNamespace sets the namespace of the pod to exec in
+func ExecNamespace(ns string) ExecOption {
+	return func(o *ExecOptions) {
+		o.Namespace = SerializeResourceName(ns)
+	}
+}
+
+// ExecContainer sets the container to exec in, required if the pod has multiple containers
+func ExecContainer(name string) ExecOption {
+	return func(o *ExecOptions) {
+		o.Container = name
+	}
+}
+
+// ExecCommand sets the command to run
+func ExecCommand(cmd ...string) ExecOption {
+	return func(o *ExecOptions) {
+		o.Command = cmd
+	}
+}
+
+// ExecOutput sets the writers of the output of the command
+func ExecOutput(stdout, stderr io.Writer) ExecOption {
+	return func(o *ExecOptions) {
+		o.Stdout = stdout
+		o.Stderr = stderr
+	}
+}
+
+// ExecContext sets the context, cancelling it stops streaming the output
+func ExecContext(ctx context.Context) ExecOption {
+	return func(o *ExecOptions) {
+		o.Context = ctx
+	}
+}
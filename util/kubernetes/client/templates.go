@@ -28,6 +28,23 @@ metadata:
     {{- end }}
 spec:
   replicas: {{ .Spec.Replicas }}
+  {{- if .Spec.Strategy }}
+  {{- with .Spec.Strategy }}
+  strategy:
+    type: {{ .Type }}
+    {{- if .RollingUpdate }}
+    {{- with .RollingUpdate }}
+    rollingUpdate:
+      {{- if .MaxUnavailable }}
+      maxUnavailable: {{ .MaxUnavailable }}
+      {{- end }}
+      {{- if .MaxSurge }}
+      maxSurge: {{ .MaxSurge }}
+      {{- end }}
+    {{- end }}
+    {{- end }}
+  {{- end }}
+  {{- end }}
   selector:
     matchLabels:
       {{- with .Spec.Selector.MatchLabels }}
@@ -51,6 +68,12 @@ spec:
         {{- end }}
     spec: 
       serviceAccountName: {{ .Spec.Template.PodSpec.ServiceAccountName }}
+      {{- with .Spec.Template.PodSpec.ImagePullSecrets }}
+      imagePullSecrets:
+      {{- range . }}
+      - name: "{{ .Name }}"
+      {{- end }}
+      {{- end }}
       containers:
       {{- with .Spec.Template.PodSpec.Containers }}
       {{- range . }}
@@ -75,6 +98,18 @@ spec:
           {{- end }}
           {{- end }}
           {{- end }}
+          {{- with .EnvFrom }}
+          envFrom:
+          {{- range . }}
+          {{- if .SecretRef }}
+          {{- with .SecretRef }}
+          - secretRef:
+              name: "{{ .Name }}"
+              optional: {{ .Optional }}
+          {{- end }}
+          {{- end }}
+          {{- end }}
+          {{- end }}
           args:
           {{- range .Args }}
           - {{.}}
@@ -92,6 +127,28 @@ spec:
             name: {{ .Name }}
           {{- end }}
           {{- end }}
+          {{- if .LivenessProbe }}
+          {{- with .LivenessProbe }}
+          livenessProbe:
+            {{- with .TCPSocket }}
+            tcpSocket:
+              {{- if .Host }}
+              host: {{ .Host }}
+              {{- end }}
+              port: {{ .Port }}
+            {{- end }}
+            {{- with .HTTPGet }}
+            httpGet:
+              path: "{{ .Path }}"
+              port: {{ .Port }}
+            {{- end }}
+            initialDelaySeconds: {{ .InitialDelaySeconds }}
+            periodSeconds: {{ .PeriodSeconds }}
+            {{- if .FailureThreshold }}
+            failureThreshold: {{ .FailureThreshold }}
+            {{- end }}
+          {{- end }}
+          {{- end }}
           {{- if .ReadinessProbe }}
           {{- with .ReadinessProbe }}
           readinessProbe:
@@ -102,8 +159,16 @@ spec:
               {{- end }}
               port: {{ .Port }}
             {{- end }}
+            {{- with .HTTPGet }}
+            httpGet:
+              path: "{{ .Path }}"
+              port: {{ .Port }}
+            {{- end }}
             initialDelaySeconds: {{ .InitialDelaySeconds }}
             periodSeconds: {{ .PeriodSeconds }}
+            {{- if .FailureThreshold }}
+            failureThreshold: {{ .FailureThreshold }}
+            {{- end }}
           {{- end }}
           {{- end }}
           {{- if .Resources }}
@@ -1,5 +1,10 @@
 package client
 
+import (
+	"encoding/json"
+	"strconv"
+)
+
 // ContainerPort
 type ContainerPort struct {
 	Name          string `json:"name,omitempty"`
@@ -20,6 +25,17 @@ type EnvVarSource struct {
 	SecretKeyRef *SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
+// EnvFromSource sets the environment from all the keys of a source
+type EnvFromSource struct {
+	SecretRef *SecretEnvSource `json:"secretRef,omitempty"`
+}
+
+// SecretEnvSource selects a Secret to populate the environment with
+type SecretEnvSource struct {
+	Name     string `json:"name"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
 // SecretKeySelector selects a key of a Secret.
 type SecretKeySelector struct {
 	Key      string `json:"key"`
@@ -38,18 +54,62 @@ type Container struct {
 	Name           string                `json:"name"`
 	Image          string                `json:"image"`
 	Env            []EnvVar              `json:"env,omitempty"`
+	EnvFrom        []EnvFromSource       `json:"envFrom,omitempty"`
 	Command        []string              `json:"command,omitempty"`
 	Args           []string              `json:"args,omitempty"`
 	Ports          []ContainerPort       `json:"ports,omitempty"`
+	LivenessProbe  *Probe                `json:"livenessProbe,omitempty"`
 	ReadinessProbe *Probe                `json:"readinessProbe,omitempty"`
 	Resources      *ResourceRequirements `json:"resources,omitempty"`
 }
 
 // DeploymentSpec defines micro deployment spec
 type DeploymentSpec struct {
-	Replicas int            `json:"replicas,omitempty"`
-	Selector *LabelSelector `json:"selector"`
-	Template *Template      `json:"template,omitempty"`
+	Replicas int                 `json:"replicas,omitempty"`
+	Selector *LabelSelector      `json:"selector"`
+	Template *Template           `json:"template,omitempty"`
+	Strategy *DeploymentStrategy `json:"strategy,omitempty"`
+}
+
+// DeploymentStrategy describes how pods are replaced by new ones
+type DeploymentStrategy struct {
+	// Type is RollingUpdate or Recreate
+	Type          string                   `json:"type,omitempty"`
+	RollingUpdate *RollingUpdateDeployment `json:"rollingUpdate,omitempty"`
+}
+
+// RollingUpdateDeployment controls the pace of a rolling update
+type RollingUpdateDeployment struct {
+	// MaxUnavailable pods during the update, a number or a percentage
+	MaxUnavailable IntOrString `json:"maxUnavailable,omitempty"`
+	// MaxSurge is the number of pods above the replicas, a number or a percentage
+	MaxSurge IntOrString `json:"maxSurge,omitempty"`
+}
+
+// IntOrString is a number e.g 1 or a percentage e.g 25%
+type IntOrString string
+
+// MarshalJSON encodes numbers as json numbers
+func (s IntOrString) MarshalJSON() ([]byte, error) {
+	if i, err := strconv.Atoi(string(s)); err == nil {
+		return json.Marshal(i)
+	}
+	return json.Marshal(string(s))
+}
+
+// UnmarshalJSON decodes a json number or string
+func (s *IntOrString) UnmarshalJSON(b []byte) error {
+	var i int
+	if err := json.Unmarshal(b, &i); err == nil {
+		*s = IntOrString(strconv.Itoa(i))
+		return nil
+	}
+	var v string
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*s = IntOrString(v)
+	return nil
 }
 
 // DeploymentCondition describes the state of deployment
@@ -62,6 +122,7 @@ type DeploymentCondition struct {
 
 // DeploymentStatus is returned when querying deployment
 type DeploymentStatus struct {
+	ObservedGeneration  int64                 `json:"observedGeneration,omitempty"`
 	Replicas            int                   `json:"replicas,omitempty"`
 	UpdatedReplicas     int                   `json:"updatedReplicas,omitempty"`
 	ReadyReplicas       int                   `json:"readyReplicas,omitempty"`
@@ -102,14 +163,16 @@ type Metadata struct {
 	Name        string            `json:"name,omitempty"`
 	Namespace   string            `json:"namespace,omitempty"`
 	Version     string            `json:"version,omitempty"`
+	Generation  int64             `json:"generation,omitempty"`
 	Labels      map[string]string `json:"labels,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // PodSpec is a pod
 type PodSpec struct {
-	Containers         []Container `json:"containers"`
-	ServiceAccountName string      `json:"serviceAccountName"`
+	Containers         []Container       `json:"containers"`
+	ServiceAccountName string            `json:"serviceAccountName"`
+	ImagePullSecrets   []ImagePullSecret `json:"imagePullSecrets,omitempty"`
 }
 
 // PodList
@@ -225,9 +288,11 @@ type ServiceAccount struct {
 
 // Probe describes a health check to be performed against a container to determine whether it is alive or ready to receive traffic.
 type Probe struct {
-	TCPSocket           TCPSocketAction `json:"tcpSocket,omitempty"`
-	PeriodSeconds       int             `json:"periodSeconds"`
-	InitialDelaySeconds int             `json:"initialDelaySeconds"`
+	TCPSocket           *TCPSocketAction `json:"tcpSocket,omitempty"`
+	HTTPGet             *HTTPGetAction   `json:"httpGet,omitempty"`
+	PeriodSeconds       int              `json:"periodSeconds"`
+	InitialDelaySeconds int              `json:"initialDelaySeconds"`
+	FailureThreshold    int              `json:"failureThreshold,omitempty"`
 }
 
 // TCPSocketAction describes an action based on opening a socket
@@ -236,6 +301,12 @@ type TCPSocketAction struct {
 	Port int    `json:"port,omitempty"`
 }
 
+// HTTPGetAction describes an action based on a http get request
+type HTTPGetAction struct {
+	Path string `json:"path,omitempty"`
+	Port int    `json:"port"`
+}
+
 // ResourceRequirements describes the compute resource requirements.
 type ResourceRequirements struct {
 	Limits   *ResourceLimits `json:"limits,omitempty"`
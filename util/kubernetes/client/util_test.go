@@ -2,7 +2,10 @@ package client
 
 import (
 	"bytes"
+	"encoding/json"
 	"testing"
+
+	"github.com/ghodss/yaml"
 )
 
 func TestTemplates(t *testing.T) {
@@ -26,6 +29,85 @@ func TestTemplates(t *testing.T) {
 	}
 }
 
+func TestDeploymentTemplate(t *testing.T) {
+	d := NewDeployment("foo", "123", "service", "default")
+	c := &d.Spec.Template.PodSpec.Containers[0]
+	c.EnvFrom = []EnvFromSource{{SecretRef: &SecretEnvSource{Name: "foo-env"}}}
+	c.LivenessProbe = &Probe{
+		HTTPGet:          &HTTPGetAction{Path: "/health", Port: 8080},
+		PeriodSeconds:    5,
+		FailureThreshold: 3,
+	}
+	c.Resources = &ResourceRequirements{
+		Limits:   &ResourceLimits{CPU: "500m", Memory: "256Mi"},
+		Requests: &ResourceLimits{CPU: "100m"},
+	}
+	d.Spec.Template.PodSpec.ImagePullSecrets = []ImagePullSecret{{Name: "registry"}}
+
+	b := new(bytes.Buffer)
+	if err := renderTemplate("deployment", b, d); err != nil {
+		t.Fatalf("Failed to render kubernetes deployment: %v", err)
+	}
+
+	// the rendered yaml must decode back to the deployment
+	j, err := yaml.YAMLToJSON(b.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to parse rendered deployment: %v\n%s", err, b)
+	}
+	var rd *Deployment
+	if err := json.Unmarshal(j, &rd); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := rd.Spec.Strategy; s == nil || s.Type != "RollingUpdate" || s.RollingUpdate.MaxUnavailable != "0" || s.RollingUpdate.MaxSurge != "1" {
+		t.Fatalf("Expected a rolling update strategy got %+v", s)
+	}
+	if s := rd.Spec.Template.PodSpec.ImagePullSecrets; len(s) != 1 || s[0].Name != "registry" {
+		t.Fatalf("Expected the image pull secret got %+v", s)
+	}
+
+	rc := rd.Spec.Template.PodSpec.Containers[0]
+	if len(rc.EnvFrom) != 1 || rc.EnvFrom[0].SecretRef.Name != "foo-env" {
+		t.Fatalf("Expected the env from secret got %+v", rc.EnvFrom)
+	}
+	if p := rc.LivenessProbe; p == nil || p.HTTPGet == nil || p.HTTPGet.Path != "/health" || p.FailureThreshold != 3 || p.TCPSocket != nil {
+		t.Fatalf("Expected the http liveness probe got %+v", p)
+	}
+	if p := rc.ReadinessProbe; p == nil || p.TCPSocket == nil || p.TCPSocket.Port != 8080 {
+		t.Fatalf("Expected the tcp readiness probe got %+v", p)
+	}
+	if r := rc.Resources; r.Limits.CPU != "500m" || r.Limits.Memory != "256Mi" || r.Requests.CPU != "100m" {
+		t.Fatalf("Expected the resources got %+v %+v", r.Limits, r.Requests)
+	}
+}
+
+func TestIntOrString(t *testing.T) {
+	testCases := []struct {
+		value IntOrString
+		json  string
+	}{
+		{"1", `1`},
+		{"25%", `"25%"`},
+	}
+
+	for _, test := range testCases {
+		b, err := json.Marshal(test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.json {
+			t.Fatalf("Expected %s to encode as %s got %s", test.value, test.json, b)
+		}
+		var v IntOrString
+		if err := json.Unmarshal(b, &v); err != nil {
+			t.Fatal(err)
+		}
+		if v != test.value {
+			t.Fatalf("Expected %s to decode as %s got %s", b, test.value, v)
+		}
+	}
+}
+
 func TestFormatName(t *testing.T) {
 	testCases := []struct {
 		name   string
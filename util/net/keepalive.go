@@ -0,0 +1,66 @@
+package net
+
+import (
+	"net"
+	"time"
+)
+
+// KeepAlive enables TCP keepalives on the connection. Probes are sent once the
+// connection has been idle for the interval and, where the platform supports it,
+// the connection is closed if the peer hasn't responded within the timeout.
+func KeepAlive(c net.Conn, interval, timeout time.Duration) error {
+	tc, ok := c.(*net.TCPConn)
+	if !ok || interval <= 0 {
+		return nil
+	}
+
+	if err := tc.SetKeepAlive(true); err != nil {
+		return err
+	}
+	if err := tc.SetKeepAlivePeriod(interval); err != nil {
+		return err
+	}
+
+	if timeout <= 0 {
+		return nil
+	}
+
+	raw, err := tc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var serr error
+	if err := raw.Control(func(fd uintptr) {
+		serr = setKeepAliveTimeout(fd, interval, timeout)
+	}); err != nil {
+		return err
+	}
+	return serr
+}
+
+type keepAliveListener struct {
+	net.Listener
+	interval time.Duration
+	timeout  time.Duration
+}
+
+func (l *keepAliveListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if err := KeepAlive(c, l.interval, l.timeout); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// KeepAliveListener enables TCP keepalives on the connections accepted by the listener
+func KeepAliveListener(l net.Listener, interval, timeout time.Duration) net.Listener {
+	if interval <= 0 {
+		return l
+	}
+	return &keepAliveListener{Listener: l, interval: interval, timeout: timeout}
+}
@@ -0,0 +1,22 @@
+package net
+
+import (
+	"syscall"
+	"time"
+)
+
+// TCP_USER_TIMEOUT isn't defined by the syscall package
+const tcpUserTimeout = 0x12
+
+// setKeepAliveTimeout sets the number of probes sent before the connection is closed and
+// the time data may remain unacknowledged, which covers peers lost while sending
+func setKeepAliveTimeout(fd uintptr, interval, timeout time.Duration) error {
+	count := int(timeout / interval)
+	if count < 1 {
+		count = 1
+	}
+	if err := syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPCNT, count); err != nil {
+		return err
+	}
+	return syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, tcpUserTimeout, int(timeout/time.Millisecond))
+}
@@ -0,0 +1,11 @@
+// +build !linux
+
+package net
+
+import "time"
+
+// setKeepAliveTimeout is a no-op where the probe count can't be set,
+// the operating system default applies
+func setKeepAliveTimeout(fd uintptr, interval, timeout time.Duration) error {
+	return nil
+}
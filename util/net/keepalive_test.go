@@ -0,0 +1,42 @@
+package net
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestKeepAlive(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l = KeepAliveListener(l, time.Second, time.Second*5)
+	defer l.Close()
+
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		c.Close()
+	}()
+
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := KeepAlive(c, time.Second, time.Second*5); err != nil {
+		t.Fatal(err)
+	}
+
+	// connections other than tcp are ignored
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	if err := KeepAlive(client, time.Second, time.Second*5); err != nil {
+		t.Fatal(err)
+	}
+}
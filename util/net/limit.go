@@ -0,0 +1,130 @@
+package net
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/net/netutil"
+)
+
+// limiter is a token bucket of bytes which refills at the rate per second
+type limiter struct {
+	sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate int64) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &limiter{
+		rate:   float64(rate),
+		burst:  int(rate),
+		tokens: float64(rate),
+		last:   time.Now(),
+	}
+}
+
+// take n bytes from the bucket, n must not exceed the burst,
+// and wait until they're available
+func (l *limiter) take(n int) {
+	l.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+type rateLimitedConn struct {
+	net.Conn
+	read  *limiter
+	write *limiter
+}
+
+func (c *rateLimitedConn) Read(b []byte) (int, error) {
+	if c.read == nil {
+		return c.Conn.Read(b)
+	}
+	if len(b) > c.read.burst {
+		b = b[:c.read.burst]
+	}
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.read.take(n)
+	}
+	return n, err
+}
+
+func (c *rateLimitedConn) Write(b []byte) (int, error) {
+	if c.write == nil {
+		return c.Conn.Write(b)
+	}
+
+	var written int
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > c.write.burst {
+			chunk = chunk[:c.write.burst]
+		}
+		c.write.take(len(chunk))
+
+		n, err := c.Conn.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[n:]
+	}
+	return written, nil
+}
+
+// RateLimit limits the bytes per second read from and written to the connection, zero is unlimited
+func RateLimit(c net.Conn, read, write int64) net.Conn {
+	if read <= 0 && write <= 0 {
+		return c
+	}
+	return &rateLimitedConn{
+		Conn:  c,
+		read:  newLimiter(read),
+		write: newLimiter(write),
+	}
+}
+
+type rateLimitedListener struct {
+	net.Listener
+	read  int64
+	write int64
+}
+
+func (l *rateLimitedListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return RateLimit(c, l.read, l.write), nil
+}
+
+// LimitListener limits the connections accepted at once to max and the bytes per
+// second read and written by each of them, zero is unlimited
+func LimitListener(l net.Listener, max int, read, write int64) net.Listener {
+	if max > 0 {
+		l = netutil.LimitListener(l, max)
+	}
+	if read > 0 || write > 0 {
+		l = &rateLimitedListener{Listener: l, read: read, write: write}
+	}
+	return l
+}
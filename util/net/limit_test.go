@@ -0,0 +1,78 @@
+package net
+
+import (
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	// the first second is the burst so the rest takes half a second
+	c := RateLimit(client, 0, 100000)
+
+	go func() {
+		c.Write(make([]byte, 150000))
+		c.Close()
+	}()
+
+	start := time.Now()
+	b, err := ioutil.ReadAll(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 150000 {
+		t.Fatalf("Expected 150000 bytes, got %d", len(b))
+	}
+	if d := time.Since(start); d < time.Millisecond*400 {
+		t.Fatalf("Expected the write to be rate limited, took %v", d)
+	}
+}
+
+func TestLimitListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l = LimitListener(l, 1, 0, 0)
+	defer l.Close()
+
+	for i := 0; i < 2; i++ {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+	}
+
+	first, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := l.Accept()
+		if err == nil {
+			accepted <- c
+		}
+	}()
+
+	select {
+	case <-accepted:
+		t.Fatal("Expected the second connection to wait for the first to close")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	first.Close()
+
+	select {
+	case c := <-accepted:
+		c.Close()
+	case <-time.After(time.Second):
+		t.Fatal("Expected the second connection to be accepted once the first closed")
+	}
+}
@@ -122,6 +122,9 @@ func Sign(CACrt, CAKey, CSR []byte, opts ...CertOption) ([]byte, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "csr is invalid")
 	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, errors.Wrap(err, "csr signature is invalid")
+	}
 	template := &x509.Certificate{
 		SignatureAlgorithm:    x509.PureEd25519,
 		Subject:               csr.Subject,
@@ -135,7 +138,7 @@ func Sign(CACrt, CAKey, CSR []byte, opts ...CertOption) ([]byte, error) {
 		BasicConstraintsValid: true,
 	}
 
-	x509Cert, err := x509.CreateCertificate(rand.Reader, template, caCrt, caCrt.PublicKey, caKey)
+	x509Cert, err := x509.CreateCertificate(rand.Reader, template, caCrt, csr.PublicKey, caKey)
 	if err != nil {
 		return nil, errors.Wrap(err, "Couldn't sign certificate")
 	}
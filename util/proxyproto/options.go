@@ -0,0 +1,39 @@
+package proxyproto
+
+import "time"
+
+// Options of the listener
+type Options struct {
+	// Trusted are the addresses or CIDRs of the load balancers whose
+	// headers are accepted, at least one is required
+	Trusted []string
+	// Timeout for reading the header
+	Timeout time.Duration
+	// Required rejects connections from trusted addresses without a header
+	Required bool
+}
+
+// Option sets an option of the listener
+type Option func(o *Options)
+
+// Trusted sets the addresses or CIDRs of the load balancers, headers from other
+// addresses are ignored so the client address can't be spoofed
+func Trusted(addrs ...string) Option {
+	return func(o *Options) {
+		o.Trusted = addrs
+	}
+}
+
+// Timeout sets how long to wait for the header
+func Timeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
+
+// Required rejects connections from trusted addresses without a header
+func Required(b bool) Option {
+	return func(o *Options) {
+		o.Required = b
+	}
+}
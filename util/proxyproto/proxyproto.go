@@ -0,0 +1,316 @@
+// Package proxyproto accepts the PROXY protocol header (v1 and v2) sent by L4 load
+// balancers such as HAProxy or AWS NLB so the address of the client is preserved.
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// DefaultTimeout is how long to wait for the header
+	DefaultTimeout = time.Second * 5
+
+	// ErrInvalidHeader is returned when the header can't be parsed
+	ErrInvalidHeader = errors.New("invalid proxy protocol header")
+	// ErrNoHeader is returned when the header is required and the connection didn't send one
+	ErrNoHeader = errors.New("proxy protocol header required")
+	// ErrNoTrusted is returned when the listener has no trusted addresses, trusting
+	// every address would let any client spoof its address
+	ErrNoTrusted = errors.New("proxy protocol requires trusted addresses")
+
+	v1Prefix    = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+const (
+	// the maximum length of a v1 header including the CRLF
+	v1MaxLength = 107
+	// the length of the fixed v2 header
+	v2Length = 16
+)
+
+type listener struct {
+	net.Listener
+	opts    Options
+	trusted []*net.IPNet
+}
+
+type conn struct {
+	net.Conn
+	listener *listener
+	reader   *bufio.Reader
+
+	once   sync.Once
+	err    error
+	local  net.Addr
+	remote net.Addr
+}
+
+// NewListener returns a listener which reads the header of connections from trusted
+// addresses. The addresses of the connections are those of the header if present.
+func NewListener(l net.Listener, opts ...Option) (net.Listener, error) {
+	options := Options{
+		Timeout: DefaultTimeout,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if len(options.Trusted) == 0 {
+		return nil, ErrNoTrusted
+	}
+
+	var trusted []*net.IPNet
+	for _, addr := range options.Trusted {
+		if !strings.Contains(addr, "/") {
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted address %s", addr)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			trusted = append(trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipnet, err := net.ParseCIDR(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted address %s: %v", addr, err)
+		}
+		trusted = append(trusted, ipnet)
+	}
+
+	return &listener{
+		Listener: l,
+		opts:     options,
+		trusted:  trusted,
+	}, nil
+}
+
+// Accept returns the connection without waiting for the header, it's read on first use
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &conn{
+		Conn:     c,
+		listener: l,
+		reader:   bufio.NewReader(c),
+	}, nil
+}
+
+// trust returns whether headers from the address are accepted
+func (l *listener) trust(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	for _, ipnet := range l.trusted {
+		if ipnet.Contains(tcp.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *conn) init() error {
+	c.once.Do(func() {
+		c.err = c.readHeader()
+	})
+	return c.err
+}
+
+func (c *conn) Read(b []byte) (int, error) {
+	if err := c.init(); err != nil {
+		return 0, err
+	}
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns the source address of the header if present
+func (c *conn) RemoteAddr() net.Addr {
+	if c.init() == nil && c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns the destination address of the header if present
+func (c *conn) LocalAddr() net.Addr {
+	if c.init() == nil && c.local != nil {
+		return c.local
+	}
+	return c.Conn.LocalAddr()
+}
+
+func (c *conn) readHeader() error {
+	if !c.listener.trust(c.Conn.RemoteAddr()) {
+		return nil
+	}
+
+	if c.listener.opts.Timeout > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.listener.opts.Timeout))
+		defer c.Conn.SetReadDeadline(time.Time{})
+	}
+
+	var err error
+
+	switch c.version() {
+	case 1:
+		err = c.readV1()
+	case 2:
+		err = c.readV2()
+	default:
+		if c.listener.opts.Required {
+			err = ErrNoHeader
+		}
+	}
+
+	if err != nil {
+		c.Conn.Close()
+	}
+	return err
+}
+
+// version peeks at the connection for a header, only reading as much as matches
+// so clients which don't send a header aren't blocked
+func (c *conn) version() int {
+	for i := 1; i <= len(v2Signature); i++ {
+		b, err := c.reader.Peek(i)
+		if err != nil {
+			return 0
+		}
+		if bytes.Equal(b, v1Prefix) {
+			return 1
+		}
+		if bytes.Equal(b, v2Signature) {
+			return 2
+		}
+		if !bytes.HasPrefix(v1Prefix, b) && !bytes.HasPrefix(v2Signature, b) {
+			return 0
+		}
+	}
+	return 0
+}
+
+// readV1 reads the human readable header e.g PROXY TCP4 192.168.0.1 192.168.0.11 56324 443
+func (c *conn) readV1() error {
+	line, err := c.reader.ReadSlice('\n')
+	if err != nil || len(line) > v1MaxLength || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return ErrInvalidHeader
+	}
+
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) < 2 {
+		return ErrInvalidHeader
+	}
+
+	switch fields[1] {
+	case "UNKNOWN":
+		// the connection addresses are used
+		return nil
+	case "TCP4", "TCP6":
+	default:
+		return ErrInvalidHeader
+	}
+
+	if len(fields) != 6 {
+		return ErrInvalidHeader
+	}
+
+	src, err := parseAddr(fields[2], fields[4])
+	if err != nil {
+		return err
+	}
+	dst, err := parseAddr(fields[3], fields[5])
+	if err != nil {
+		return err
+	}
+
+	c.remote, c.local = src, dst
+	return nil
+}
+
+func parseAddr(host, port string) (*net.TCPAddr, error) {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, ErrInvalidHeader
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, ErrInvalidHeader
+	}
+	return &net.TCPAddr{IP: ip, Port: int(p)}, nil
+}
+
+// readV2 reads the binary header
+func (c *conn) readV2() error {
+	header := make([]byte, v2Length)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return ErrInvalidHeader
+	}
+
+	// the high bits are the version and the low the command
+	if header[12]>>4 != 2 {
+		return ErrInvalidHeader
+	}
+	command := header[12] & 0xf
+
+	// the high bits are the address family and the low the transport protocol
+	family := header[13] >> 4
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return ErrInvalidHeader
+	}
+
+	switch command {
+	case 0:
+		// LOCAL e.g health checks from the load balancer, the connection addresses are used
+		return nil
+	case 1:
+	default:
+		return ErrInvalidHeader
+	}
+
+	var size int
+	switch family {
+	case 1:
+		size = net.IPv4len
+	case 2:
+		size = net.IPv6len
+	default:
+		// unix sockets or unspecified, the connection addresses are used
+		return nil
+	}
+
+	// the addresses are followed by optional TLVs which are ignored
+	if len(payload) < size*2+4 {
+		return ErrInvalidHeader
+	}
+
+	c.remote = &net.TCPAddr{
+		IP:   net.IP(payload[:size]),
+		Port: int(binary.BigEndian.Uint16(payload[size*2:])),
+	}
+	c.local = &net.TCPAddr{
+		IP:   net.IP(payload[size : size*2]),
+		Port: int(binary.BigEndian.Uint16(payload[size*2+2:])),
+	}
+	return nil
+}
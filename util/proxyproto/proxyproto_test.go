@@ -0,0 +1,182 @@
+package proxyproto
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"testing"
+)
+
+func v2Header(command, family byte, payload []byte) []byte {
+	b := append([]byte{}, v2Signature...)
+	b = append(b, 0x20|command, family<<4|1, 0, 0)
+	binary.BigEndian.PutUint16(b[14:], uint16(len(payload)))
+	return append(b, payload...)
+}
+
+func v2Payload(src, dst net.IP, sport, dport uint16) []byte {
+	b := append(append([]byte{}, src...), dst...)
+	b = append(b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint16(b[len(b)-4:], sport)
+	binary.BigEndian.PutUint16(b[len(b)-2:], dport)
+	return b
+}
+
+// send writes the data to a listener and returns the remote address and data of the accepted connection
+func send(t *testing.T, data []byte, opts ...Option) (string, string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// the local address is trusted unless the test case sets its own
+	pl, err := NewListener(l, append([]Option{Trusted("127.0.0.1")}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			return
+		}
+		c.Write(data)
+		c.Close()
+	}()
+
+	c, err := pl.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	b, err := ioutil.ReadAll(c)
+	return c.RemoteAddr().String(), string(b), err
+}
+
+func TestProxyProtocol(t *testing.T) {
+	testCases := []struct {
+		name   string
+		data   []byte
+		opts   []Option
+		remote string
+		body   string
+		err    bool
+	}{
+		{
+			name:   "v1 tcp4",
+			data:   []byte("PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\nhello"),
+			remote: "192.168.0.1:56324",
+			body:   "hello",
+		},
+		{
+			name:   "v1 tcp6",
+			data:   []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\nhello"),
+			remote: "[2001:db8::1]:56324",
+			body:   "hello",
+		},
+		{
+			name:   "v1 unknown",
+			data:   []byte("PROXY UNKNOWN\r\nhello"),
+			remote: "127.0.0.1",
+			body:   "hello",
+		},
+		{
+			name: "v1 invalid",
+			data: []byte("PROXY TCP4 192.168.0.1\r\nhello"),
+			err:  true,
+		},
+		{
+			name:   "v2 ipv4",
+			data:   append(v2Header(1, 1, v2Payload(net.IPv4(10, 0, 0, 1).To4(), net.IPv4(10, 0, 0, 2).To4(), 1234, 80)), "hello"...),
+			remote: "10.0.0.1:1234",
+			body:   "hello",
+		},
+		{
+			name:   "v2 ipv6 with tlvs",
+			data:   append(v2Header(1, 2, append(v2Payload(net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::2"), 1234, 80), 0x01, 0, 2, 'h', '2')), "hello"...),
+			remote: "[2001:db8::1]:1234",
+			body:   "hello",
+		},
+		{
+			name:   "v2 local",
+			data:   append(v2Header(0, 0, nil), "hello"...),
+			remote: "127.0.0.1",
+			body:   "hello",
+		},
+		{
+			name: "v2 truncated",
+			data: v2Header(1, 1, []byte{10, 0, 0, 1}),
+			err:  true,
+		},
+		{
+			name:   "no header",
+			data:   []byte("GET / HTTP/1.1\r\n\r\n"),
+			remote: "127.0.0.1",
+			body:   "GET / HTTP/1.1\r\n\r\n",
+		},
+		{
+			name:   "short data",
+			data:   []byte("PRO"),
+			remote: "127.0.0.1",
+			body:   "PRO",
+		},
+		{
+			name: "no header required",
+			data: []byte("GET / HTTP/1.1\r\n\r\n"),
+			opts: []Option{Required(true)},
+			err:  true,
+		},
+		{
+			name:   "untrusted",
+			data:   []byte("PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\nhello"),
+			opts:   []Option{Trusted("10.0.0.0/8")},
+			remote: "127.0.0.1",
+			body:   "PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\nhello",
+		},
+		{
+			name:   "trusted",
+			data:   []byte("PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\nhello"),
+			opts:   []Option{Trusted("10.0.0.0/8", "127.0.0.1")},
+			remote: "192.168.0.1:56324",
+			body:   "hello",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			remote, body, err := send(t, tc.data, tc.opts...)
+			if tc.err {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if host, _, _ := net.SplitHostPort(remote); remote != tc.remote && host != tc.remote {
+				t.Errorf("Expected remote address %s, got %s", tc.remote, remote)
+			}
+			if body != tc.body {
+				t.Errorf("Expected body %q, got %q", tc.body, body)
+			}
+		})
+	}
+}
+
+func TestInvalidTrusted(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if _, err := NewListener(l, Trusted("foo")); err == nil {
+		t.Fatal("Expected an error for an invalid address")
+	}
+	if _, err := NewListener(l); err != ErrNoTrusted {
+		t.Fatalf("Expected %v without trusted addresses, got %v", ErrNoTrusted, err)
+	}
+}
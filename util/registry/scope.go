@@ -0,0 +1,39 @@
+package registry
+
+import (
+	"github.com/micro/go-micro/v3/registry"
+)
+
+// scope restricts a registry to a single domain
+type scope struct {
+	registry.Registry
+	domain string
+}
+
+// Scope returns a registry which only registers and looks up services in the given
+// domain, e.g the namespace of a tenant. The domain options passed to the calls,
+// including the wildcard domain, are overridden so services in other domains are
+// never visible.
+func Scope(r registry.Registry, domain string) registry.Registry {
+	return &scope{Registry: r, domain: domain}
+}
+
+func (s *scope) Register(srv *registry.Service, opts ...registry.RegisterOption) error {
+	return s.Registry.Register(srv, append(opts, registry.RegisterDomain(s.domain))...)
+}
+
+func (s *scope) Deregister(srv *registry.Service, opts ...registry.DeregisterOption) error {
+	return s.Registry.Deregister(srv, append(opts, registry.DeregisterDomain(s.domain))...)
+}
+
+func (s *scope) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	return s.Registry.GetService(name, append(opts, registry.GetDomain(s.domain))...)
+}
+
+func (s *scope) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	return s.Registry.ListServices(append(opts, registry.ListDomain(s.domain))...)
+}
+
+func (s *scope) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	return s.Registry.Watch(append(opts, registry.WatchDomain(s.domain))...)
+}
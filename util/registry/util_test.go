@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/registry/memory"
 )
 
 func TestRemove(t *testing.T) {
@@ -76,3 +77,35 @@ func TestRemoveNodes(t *testing.T) {
 		t.Logf("Nodes %+v", nodes)
 	}
 }
+
+func TestScope(t *testing.T) {
+	r := memory.NewRegistry()
+	a := Scope(r, "a")
+	b := Scope(r, "b")
+
+	srv := &registry.Service{
+		Name:    "foo",
+		Version: "1.0.0",
+		Nodes:   []*registry.Node{{Id: "foo-1", Address: "localhost:9999"}},
+	}
+
+	if err := a.Register(srv); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.GetService("foo"); err != nil {
+		t.Fatalf("Expected the service in its own scope got %v", err)
+	}
+	if _, err := b.GetService("foo"); err != registry.ErrNotFound {
+		t.Fatalf("Expected %v got %v", registry.ErrNotFound, err)
+	}
+
+	// the wildcard domain can't be used to escape the scope
+	list, err := b.ListServices(registry.ListDomain(registry.WildcardDomain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 0 {
+		t.Fatalf("Expected no services in scope b got %d", len(list))
+	}
+}
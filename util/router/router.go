@@ -1,6 +1,8 @@
 package router
 
 import (
+	"strconv"
+
 	"github.com/micro/go-micro/v3/registry"
 	"github.com/micro/go-micro/v3/router"
 )
@@ -18,15 +20,102 @@ func (r *apiRouter) String() string {
 	return "api"
 }
 
-// Router is a hack for API routing
+// Router is a hack for API routing. Nodes which are down or draining are excluded,
+// as are the nodes of versions which receive none of the traffic.
 func New(srvs []*registry.Service) router.Router {
 	var routes []router.Route
 
-	for _, srv := range srvs {
+	split, ok := shares(srvs)
+
+	for i, srv := range srvs {
+		if ok && split[i] == 0 {
+			continue
+		}
+
 		for _, n := range srv.Nodes {
+			if n.Health == registry.HealthDown || n.Health == registry.HealthDraining {
+				continue
+			}
 			routes = append(routes, router.Route{Address: n.Address, Metadata: n.Metadata})
 		}
 	}
 
 	return &apiRouter{routes: routes}
 }
+
+// shares returns the percentage of the traffic routed to each version if any of
+// them set one in their metadata, the versions without one share the remainder
+func shares(srvs []*registry.Service) ([]int, bool) {
+	split := make([]int, len(srvs))
+	remaining := 100
+	var set bool
+	var unset int
+
+	for i, srv := range srvs {
+		t, err := strconv.Atoi(srv.Metadata[registry.MetadataTraffic])
+		if err != nil || t < 0 {
+			split[i] = -1
+			unset++
+			continue
+		}
+		if t > remaining {
+			t = remaining
+		}
+		split[i] = t
+		remaining -= t
+		set = true
+	}
+
+	if !set {
+		return nil, false
+	}
+
+	for i := range split {
+		if split[i] < 0 {
+			split[i] = remaining / unset
+		}
+	}
+
+	return split, true
+}
+
+func weight(n *registry.Node) int {
+	if n.Weight > 0 {
+		return n.Weight
+	}
+	return registry.DefaultWeight
+}
+
+// Weights returns the weight of each node address to pass to the selector. When the
+// versions of a service split the traffic between them the weights of the nodes are
+// scaled so each version receives its percentage of the requests.
+func Weights(srvs []*registry.Service) map[string]int {
+	weights := make(map[string]int)
+
+	split, ok := shares(srvs)
+
+	for i, srv := range srvs {
+		if !ok {
+			for _, n := range srv.Nodes {
+				weights[n.Address] = weight(n)
+			}
+			continue
+		}
+
+		var total int
+		for _, n := range srv.Nodes {
+			total += weight(n)
+		}
+
+		for _, n := range srv.Nodes {
+			// scale by the default weight to keep the precision of small shares
+			if w := split[i] * weight(n) * registry.DefaultWeight / total; w > 0 {
+				weights[n.Address] = w
+			} else {
+				weights[n.Address] = 1
+			}
+		}
+	}
+
+	return weights
+}
@@ -0,0 +1,38 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/v3/registry"
+)
+
+func TestWeights(t *testing.T) {
+	srvs := []*registry.Service{
+		{Version: "v1", Nodes: []*registry.Node{{Address: "v1-a"}, {Address: "v1-b", Weight: 300}}},
+		{Version: "v2", Nodes: []*registry.Node{{Address: "v2-a"}}},
+	}
+
+	// the node weights are used without a traffic split
+	weights := Weights(srvs)
+	if weights["v1-a"] != 100 || weights["v1-b"] != 300 || weights["v2-a"] != 100 {
+		t.Fatalf("Expected the node weights got %v", weights)
+	}
+
+	// the canary receives its share and the other version the rest
+	srvs[1].Metadata = map[string]string{registry.MetadataTraffic: "10"}
+	weights = Weights(srvs)
+	if weights["v2-a"] != 1000 || weights["v1-a"]+weights["v1-b"] != 9000 || weights["v1-b"] != 3*weights["v1-a"] {
+		t.Fatalf("Expected the traffic to be split got %v", weights)
+	}
+	if routes, _ := New(srvs).Lookup("foo"); len(routes) != 3 {
+		t.Fatalf("Expected 3 routes got %d", len(routes))
+	}
+
+	// versions switched to no traffic aren't routed to
+	srvs[0].Metadata = map[string]string{registry.MetadataTraffic: "0"}
+	srvs[1].Metadata = map[string]string{registry.MetadataTraffic: "100"}
+	routes, _ := New(srvs).Lookup("foo")
+	if len(routes) != 1 || routes[0].Address != "v2-a" {
+		t.Fatalf("Expected only the new version to be routed to got %v", routes)
+	}
+}
@@ -0,0 +1,134 @@
+// Package schema checks the compatibility of the proto messages of callers and services. Services
+// advertise the schema of the request and response of each endpoint in the registry and callers
+// send the schema of theirs with each request, the field numbers used by both must have the
+// same type or the data is corrupted on the wire.
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	// ErrIncompatible is returned when the schemas of a caller and service don't match
+	ErrIncompatible = errors.New("incompatible schema")
+
+	// RequestMetadata is the endpoint metadata the schema of the request is advertised in
+	RequestMetadata = "request_schema"
+	// ResponseMetadata is the endpoint metadata the schema of the response is advertised in
+	ResponseMetadata = "response_schema"
+
+	// RequestHeader is the metadata the caller sends the schema of its request in
+	RequestHeader = "Micro-Request-Schema"
+	// ResponseHeader is the metadata the caller sends the schema of its response in
+	ResponseHeader = "Micro-Response-Schema"
+)
+
+// Describe returns the schema of the proto message, the schema is empty if it isn't
+// a proto message. It lists the fields by number e.g 1=name:string;2=tags:[]string
+func Describe(v interface{}) string {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return ""
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return ""
+	}
+
+	fields := proto.MessageReflect(m).Descriptor().Fields()
+	parts := make([]string, 0, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		parts = append(parts, fmt.Sprintf("%d=%s:%s", fd.Number(), fd.Name(), kind(fd)))
+	}
+	sort.Strings(parts)
+
+	return strings.Join(parts, ";")
+}
+
+// DescribeType returns the schema of the proto message type
+func DescribeType(t reflect.Type) string {
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return ""
+	}
+	return Describe(reflect.New(t.Elem()).Interface())
+}
+
+func kind(fd protoreflect.FieldDescriptor) string {
+	if fd.IsMap() {
+		return "map<" + kind(fd.MapKey()) + "," + kind(fd.MapValue()) + ">"
+	}
+
+	var k string
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		k = string(fd.Message().FullName())
+	case protoreflect.EnumKind:
+		k = string(fd.Enum().FullName())
+	default:
+		k = fd.Kind().String()
+	}
+
+	if fd.IsList() {
+		return "[]" + k
+	}
+	return k
+}
+
+type field struct {
+	name string
+	kind string
+}
+
+func parse(s string) map[int]field {
+	fields := make(map[int]field)
+	for _, part := range strings.Split(s, ";") {
+		num := strings.SplitN(part, "=", 2)
+		if len(num) != 2 {
+			continue
+		}
+		n, err := strconv.Atoi(num[0])
+		if err != nil {
+			continue
+		}
+		f := strings.SplitN(num[1], ":", 2)
+		if len(f) != 2 {
+			continue
+		}
+		fields[n] = field{name: f[0], kind: f[1]}
+	}
+	return fields
+}
+
+// Compare returns an error if the field numbers in both schemas don't have the same type,
+// fields only in one of the schemas are compatible as they're ignored by the other side
+func Compare(caller, callee string) error {
+	if len(caller) == 0 || len(callee) == 0 {
+		return nil
+	}
+
+	a := parse(caller)
+	b := parse(callee)
+
+	var mismatches []string
+	for n, fa := range a {
+		fb, ok := b[n]
+		if !ok || fa.kind == fb.kind {
+			continue
+		}
+		mismatches = append(mismatches, fmt.Sprintf("field %d is %s %s in the caller and %s %s in the service", n, fa.name, fa.kind, fb.name, fb.kind))
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	sort.Strings(mismatches)
+	return fmt.Errorf("%w: %s", ErrIncompatible, strings.Join(mismatches, ", "))
+}
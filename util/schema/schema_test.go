@@ -0,0 +1,125 @@
+package schema
+
+import (
+	"context"
+	stderrors "errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v3/codec"
+	"github.com/micro/go-micro/v3/errors"
+	pb "github.com/micro/go-micro/v3/errors/proto"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/server"
+)
+
+type testRequest struct{}
+
+func (r *testRequest) Service() string           { return "test" }
+func (r *testRequest) Method() string            { return "Foo.Bar" }
+func (r *testRequest) Endpoint() string          { return "Foo.Bar" }
+func (r *testRequest) ContentType() string       { return "application/protobuf" }
+func (r *testRequest) Header() map[string]string { return nil }
+func (r *testRequest) Body() interface{}         { return &pb.Error{} }
+func (r *testRequest) Read() ([]byte, error)     { return nil, nil }
+func (r *testRequest) Codec() codec.Reader       { return nil }
+func (r *testRequest) Stream() bool              { return false }
+
+func TestDescribe(t *testing.T) {
+	expect := "1=id:string;2=code:int32;3=detail:string;4=status:string"
+	if v := Describe(&pb.Error{}); v != expect {
+		t.Fatalf("Expected %v got %v", expect, v)
+	}
+	if v := DescribeType(reflect.TypeOf(&pb.Error{})); v != expect {
+		t.Fatalf("Expected %v got %v", expect, v)
+	}
+	if v := Describe(map[string]string{}); len(v) > 0 {
+		t.Fatalf("Expected no schema for a map got %v", v)
+	}
+
+	testData := []struct {
+		caller string
+		callee string
+		err    bool
+	}{
+		{expect, expect, false},
+		// added and renamed fields are compatible
+		{expect, expect + ";5=trace:string", false},
+		{"1=uuid:string", expect, false},
+		// the type of a field changed
+		{"2=code:string", expect, true},
+		{"", expect, false},
+	}
+	for _, d := range testData {
+		err := Compare(d.caller, d.callee)
+		if d.err != (err != nil) || (err != nil && !stderrors.Is(err, ErrIncompatible)) {
+			t.Fatalf("Unexpected result comparing %v with %v: %v", d.caller, d.callee, err)
+		}
+	}
+}
+
+func TestHandlerWrapper(t *testing.T) {
+	var called bool
+	h := NewHandlerWrapper(Reject())(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		called = true
+		if _, ok := metadata.Get(ctx, RequestHeader); ok {
+			t.Fatal("Expected the schema to be removed from the metadata")
+		}
+		return nil
+	})
+
+	ctx := metadata.NewContext(context.Background(), metadata.Metadata{"micro-request-schema": "1=id:string"})
+	if err := h(ctx, &testRequest{}, nil); err != nil || !called {
+		t.Fatalf("Expected the compatible call to succeed got %v", err)
+	}
+
+	called = false
+	ctx = metadata.NewContext(context.Background(), metadata.Metadata{RequestHeader: "1=id:int64"})
+	err := h(ctx, &testRequest{}, nil)
+	if verr, ok := err.(*errors.Error); !ok || verr.Code != 412 || called {
+		t.Fatalf("Expected the incompatible call to be rejected got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := "1=id:string;2=max_count:uint32;3=tags:[]string;4=labels:map<string,int64>;5=ratio:double;6=enabled:bool;7=owner:go.micro.Owner"
+
+	testData := []struct {
+		body    string
+		problem string
+	}{
+		{`{"id":"foo","max_count":10,"tags":["a"],"labels":{"a":"1"},"ratio":0.5,"enabled":true}`, ""},
+		// lowerCamelCase names, quoted integers and null
+		{`{"maxCount":"10","tags":null,"owner":{"name":"bar"}}`, ""},
+		{``, ""},
+		{`{"id":1}`, "field id must be a string"},
+		{`{"maxCount":-1}`, "field maxCount is out of the range of uint32"},
+		{`{"max_count":1.5}`, "field max_count must be an integer"},
+		{`{"tags":["a",1]}`, "field tags element 1 must be a string"},
+		{`{"labels":{"a":"b"}}`, "field labels value a must be an integer"},
+		{`{"enabled":"yes"}`, "field enabled must be a boolean"},
+		{`{"foo":"bar"}`, "unknown field foo"},
+		{`["foo"]`, "the body must be a JSON object"},
+		{`{"id":`, "malformed JSON"},
+	}
+
+	for _, d := range testData {
+		err := Validate(s, []byte(d.body))
+		if len(d.problem) == 0 {
+			if err != nil {
+				t.Fatalf("Expected %s to be valid, got %v", d.body, err)
+			}
+			continue
+		}
+		if err == nil || !stderrors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), d.problem) {
+			t.Fatalf("Expected %s to be invalid with %q, got %v", d.body, d.problem, err)
+		}
+	}
+
+	// every problem is reported
+	err := Validate(s, []byte(`{"id":1,"enabled":"yes","foo":"bar"}`))
+	if err == nil || strings.Count(err.Error(), ",") != 2 {
+		t.Fatalf("Expected three problems, got %v", err)
+	}
+}
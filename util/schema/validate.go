@@ -0,0 +1,184 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalid is returned when a JSON body doesn't match the schema of the request
+	ErrInvalid = errors.New("invalid request")
+)
+
+// integer ranges of the proto scalar kinds
+var ranges = map[string][2]float64{
+	"int32":    {math.MinInt32, math.MaxInt32},
+	"sint32":   {math.MinInt32, math.MaxInt32},
+	"sfixed32": {math.MinInt32, math.MaxInt32},
+	"uint32":   {0, math.MaxUint32},
+	"fixed32":  {0, math.MaxUint32},
+	"int64":    {math.MinInt64, math.MaxInt64},
+	"sint64":   {math.MinInt64, math.MaxInt64},
+	"sfixed64": {math.MinInt64, math.MaxInt64},
+	"uint64":   {0, math.MaxUint64},
+	"fixed64":  {0, math.MaxUint64},
+}
+
+// Validate checks the JSON body against the schema of a proto message following the proto3
+// JSON mapping: fields are named as in the proto or in lowerCamelCase, integers may be quoted
+// and null is the default of any field. The fields of nested messages and the values of enums
+// aren't part of the schema so only their JSON type is checked. An empty schema or body is valid.
+func Validate(schema string, body []byte) error {
+	if len(schema) == 0 || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("%w: malformed JSON: %v", ErrInvalid, err)
+	}
+
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: the body must be a JSON object", ErrInvalid)
+	}
+
+	// index the fields by their proto and JSON names
+	fields := make(map[string]field)
+	for _, f := range parse(schema) {
+		fields[f.name] = f
+		fields[jsonName(f.name)] = f
+	}
+
+	var problems []string
+	for name, val := range obj {
+		f, ok := fields[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown field %s", name))
+			continue
+		}
+		if err := checkKind(f.kind, val); err != nil {
+			problems = append(problems, fmt.Sprintf("field %s %v", name, err))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+	return fmt.Errorf("%w: %s", ErrInvalid, strings.Join(problems, ", "))
+}
+
+// jsonName returns the lowerCamelCase name protoc derives from the field name
+func jsonName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, c := range name {
+		if c == '_' {
+			upper = true
+			continue
+		}
+		if upper && c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper = false
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func checkKind(kind string, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+
+	if strings.HasPrefix(kind, "[]") {
+		list, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("must be an array")
+		}
+		for i, e := range list {
+			if err := checkKind(kind[2:], e); err != nil {
+				return fmt.Errorf("element %d %v", i, err)
+			}
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(kind, "map<") && strings.HasSuffix(kind, ">") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("must be an object")
+		}
+		kv := strings.SplitN(kind[4:len(kind)-1], ",", 2)
+		if len(kv) != 2 {
+			return nil
+		}
+		for k, e := range m {
+			if err := checkKind(kv[1], e); err != nil {
+				return fmt.Errorf("value %s %v", k, err)
+			}
+		}
+		return nil
+	}
+
+	switch kind {
+	case "bool":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("must be a boolean")
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("must be a string")
+		}
+	case "bytes":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("must be a base64 encoded string")
+		}
+	case "float", "double":
+		switch n := v.(type) {
+		case json.Number:
+		case string:
+			if _, err := strconv.ParseFloat(n, 64); err != nil && n != "NaN" && n != "Infinity" && n != "-Infinity" {
+				return fmt.Errorf("must be a number")
+			}
+		default:
+			return fmt.Errorf("must be a number")
+		}
+	default:
+		r, ok := ranges[kind]
+		if !ok {
+			// an enum or message, the json type is checked by the service
+			return nil
+		}
+
+		var s string
+		switch n := v.(type) {
+		case json.Number:
+			s = n.String()
+		case string:
+			s = n
+		default:
+			return fmt.Errorf("must be an integer")
+		}
+
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || f != math.Trunc(f) {
+			return fmt.Errorf("must be an integer")
+		}
+		if f < r[0] || f > r[1] {
+			return fmt.Errorf("is out of the range of %s", kind)
+		}
+	}
+
+	return nil
+}
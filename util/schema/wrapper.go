@@ -0,0 +1,149 @@
+package schema
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/errors"
+	"github.com/micro/go-micro/v3/logger"
+	"github.com/micro/go-micro/v3/metadata"
+	"github.com/micro/go-micro/v3/registry"
+	"github.com/micro/go-micro/v3/server"
+)
+
+type Options struct {
+	// Reject the calls with incompatible schemas, they're only logged if false
+	Reject bool
+	// Registry the schemas of the services are read from by the client wrapper,
+	// the client only sends its schemas for the service to check if not set
+	Registry registry.Registry
+}
+
+type Option func(o *Options)
+
+// Reject the calls with incompatible schemas rather than only logging them
+func Reject() Option {
+	return func(o *Options) {
+		o.Reject = true
+	}
+}
+
+// WithRegistry sets the registry the schemas of the services are read from,
+// it should be cached as it's read on every call
+func WithRegistry(r registry.Registry) Option {
+	return func(o *Options) {
+		o.Registry = r
+	}
+}
+
+func newOptions(opts ...Option) Options {
+	var options Options
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
+
+// check logs the incompatibility and returns an error if the call is rejected
+func (o Options) check(id, service, endpoint string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+		logger.Warnf("Call to %s %s: %v", service, endpoint, err)
+	}
+	if !o.Reject {
+		return nil
+	}
+	return errors.New(id, err.Error(), 412)
+}
+
+// NewHandlerWrapper returns a handler wrapper which checks the schemas sent by the callers
+// against the request and response of the endpoint
+func NewHandlerWrapper(opts ...Option) server.HandlerWrapper {
+	options := newOptions(opts...)
+
+	return func(fn server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			md, ok := metadata.FromContext(ctx)
+			if !ok {
+				return fn(ctx, req, rsp)
+			}
+
+			if s, ok := md[RequestHeader]; ok {
+				if err := options.check(req.Service(), req.Service(), req.Endpoint(), Compare(s, Describe(req.Body()))); err != nil {
+					return err
+				}
+			}
+			if s, ok := md[ResponseHeader]; ok {
+				if err := options.check(req.Service(), req.Service(), req.Endpoint(), Compare(s, Describe(rsp))); err != nil {
+					return err
+				}
+			}
+
+			// the schemas aren't passed on to the calls made by the handler
+			delete(md, RequestHeader)
+			delete(md, ResponseHeader)
+			return fn(metadata.NewContext(ctx, md), req, rsp)
+		}
+	}
+}
+
+type schemaClient struct {
+	client.Client
+	opts Options
+}
+
+// compare the schemas with those of the versions of the service which have the endpoint
+func (s *schemaClient) compare(req client.Request, rsp interface{}) error {
+	services, err := s.opts.Registry.GetService(req.Service())
+	if err != nil {
+		// the call fails on its own if the service can't be found
+		return nil
+	}
+
+	reqSchema := Describe(req.Body())
+	rspSchema := Describe(rsp)
+
+	for _, srv := range services {
+		for _, ep := range srv.Endpoints {
+			if ep.Name != req.Endpoint() || ep.Metadata == nil {
+				continue
+			}
+			if err := Compare(reqSchema, ep.Metadata[RequestMetadata]); err != nil {
+				return err
+			}
+			if err := Compare(rspSchema, ep.Metadata[ResponseMetadata]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *schemaClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	if s.opts.Registry != nil {
+		if err := s.opts.check("go.micro.client", req.Service(), req.Endpoint(), s.compare(req, rsp)); err != nil {
+			return err
+		}
+	}
+
+	if v := Describe(req.Body()); len(v) > 0 {
+		ctx = metadata.Set(ctx, RequestHeader, v)
+	}
+	if v := Describe(rsp); len(v) > 0 {
+		ctx = metadata.Set(ctx, ResponseHeader, v)
+	}
+
+	return s.Client.Call(ctx, req, rsp, opts...)
+}
+
+// NewClientWrapper returns a client wrapper which checks the schemas of the calls against
+// those advertised by the service in the registry and sends them for the service to check
+func NewClientWrapper(opts ...Option) client.Wrapper {
+	options := newOptions(opts...)
+
+	return func(c client.Client) client.Client {
+		return &schemaClient{Client: c, opts: options}
+	}
+}
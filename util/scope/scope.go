@@ -38,6 +38,21 @@ func (s *Scope) Delete(key string, opts ...store.DeleteOption) error {
 	return s.Store.Delete(key, opts...)
 }
 
+func (s *Scope) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	for _, r := range recs {
+		r.Key = fmt.Sprintf("%v/%v", s.prefix, r.Key)
+	}
+	return s.Store.BatchWrite(recs, opts...)
+}
+
+func (s *Scope) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	scoped := make([]string, len(keys))
+	for i, key := range keys {
+		scoped[i] = fmt.Sprintf("%v/%v", s.prefix, key)
+	}
+	return s.Store.BatchDelete(scoped, opts...)
+}
+
 func (s *Scope) List(opts ...store.ListOption) ([]string, error) {
 	var lops store.ListOptions
 	for _, o := range opts {
@@ -104,6 +104,14 @@ func (c *syncStore) Delete(key string, opts ...store.DeleteOption) error {
 	return c.syncOpts.Stores[0].Delete(key, opts...)
 }
 
+func (c *syncStore) BatchWrite(recs []*store.Record, opts ...store.WriteOption) error {
+	return c.syncOpts.Stores[0].BatchWrite(recs, opts...)
+}
+
+func (c *syncStore) BatchDelete(keys []string, opts ...store.DeleteOption) error {
+	return c.syncOpts.Stores[0].BatchDelete(keys, opts...)
+}
+
 func (c *syncStore) Sync() error {
 	return nil
 }
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is a deterministic clock for tests, its time only moves when it's advanced. Code
+// under test takes the clock's Now, After and Sleep in place of the time package ones.
+type Clock struct {
+	sync.Mutex
+	now    time.Time
+	timers []*clockTimer
+}
+
+type clockTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewClock returns a clock set to the time
+func NewClock(t time.Time) *Clock {
+	return &Clock{now: t}
+}
+
+// Now returns the time of the clock
+func (c *Clock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+// Since returns the time elapsed on the clock since t
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// After returns a channel which receives the time once the clock is advanced by d
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.timers = append(c.timers, &clockTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Sleep blocks until the clock is advanced by d
+func (c *Clock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// Waiting returns the number of timers waiting for the clock to be advanced, it's
+// used to wait for the code under test to sleep before advancing the clock
+func (c *Clock) Waiting() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.timers)
+}
+
+// Advance moves the clock forward by d, firing the timers which are due in the order they're due
+func (c *Clock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.now = c.now.Add(d)
+
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].at.Before(c.timers[j].at)
+	})
+
+	var waiting []*clockTimer
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			waiting = append(waiting, t)
+			continue
+		}
+		t.ch <- t.at
+	}
+	c.timers = waiting
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v3/auth"
+	"github.com/micro/go-micro/v3/auth/noop"
+	"github.com/micro/go-micro/v3/broker"
+	bmemory "github.com/micro/go-micro/v3/broker/memory"
+	"github.com/micro/go-micro/v3/client"
+	cmucp "github.com/micro/go-micro/v3/client/mucp"
+	"github.com/micro/go-micro/v3/network/transport"
+	tmemory "github.com/micro/go-micro/v3/network/transport/memory"
+	"github.com/micro/go-micro/v3/registry"
+	rmemory "github.com/micro/go-micro/v3/registry/memory"
+	"github.com/micro/go-micro/v3/router"
+	rtreg "github.com/micro/go-micro/v3/router/registry"
+	"github.com/micro/go-micro/v3/server"
+	smucp "github.com/micro/go-micro/v3/server/mucp"
+	"github.com/micro/go-micro/v3/store"
+	smemory "github.com/micro/go-micro/v3/store/memory"
+)
+
+// Env is an in memory stack to run services in for integration tests, the services find
+// each other in the memory registry and talk over the memory transport and broker so the
+// tests don't need mdns, the network or any external dependencies
+type Env struct {
+	Registry  registry.Registry
+	Broker    broker.Broker
+	Transport transport.Transport
+	Store     store.Store
+	Auth      auth.Auth
+	Clock     *Clock
+
+	sync.Mutex
+	servers []server.Server
+}
+
+// NewEnv returns an in memory stack with a clock set to the unix epoch
+func NewEnv() *Env {
+	reg := rmemory.NewRegistry()
+
+	return &Env{
+		Registry:  reg,
+		Broker:    bmemory.NewBroker(broker.Registry(reg)),
+		Transport: tmemory.NewTransport(),
+		Store:     smemory.NewStore(),
+		Auth:      noop.NewAuth(),
+		Clock:     NewClock(time.Unix(0, 0)),
+	}
+}
+
+// NewServer returns a server in the env, it's stopped when the env is
+func (e *Env) NewServer(opts ...server.Option) server.Server {
+	options := []server.Option{
+		server.Registry(e.Registry),
+		server.Broker(e.Broker),
+		server.Transport(e.Transport),
+		server.Auth(e.Auth),
+		server.Address("127.0.0.1:0"),
+	}
+
+	srv := smucp.NewServer(append(options, opts...)...)
+
+	e.Lock()
+	e.servers = append(e.servers, srv)
+	e.Unlock()
+
+	return srv
+}
+
+// Run starts a service with the name serving the handlers
+func (e *Env) Run(name string, handlers ...interface{}) (server.Server, error) {
+	srv := e.NewServer(server.Name(name))
+	for _, h := range handlers {
+		if err := srv.Handle(srv.NewHandler(h)); err != nil {
+			return nil, err
+		}
+	}
+	if err := srv.Start(); err != nil {
+		return nil, err
+	}
+	return srv, nil
+}
+
+// NewClient returns a client calling the services in the env
+func (e *Env) NewClient(opts ...client.Option) client.Client {
+	options := []client.Option{
+		client.Router(rtreg.NewRouter(router.Registry(e.Registry))),
+		client.Broker(e.Broker),
+		client.Transport(e.Transport),
+		client.ContentType("application/json"),
+	}
+
+	return cmucp.NewClient(append(options, opts...)...)
+}
+
+// Stop the servers started in the env
+func (e *Env) Stop() error {
+	e.Lock()
+	servers := e.servers
+	e.servers = nil
+	e.Unlock()
+
+	var err error
+	for _, srv := range servers {
+		if serr := srv.Stop(); serr != nil {
+			err = serr
+		}
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type Greeter struct {
+	received chan string
+}
+
+type Request struct {
+	Name string
+}
+
+type Response struct {
+	Msg string
+}
+
+func (g *Greeter) Hello(ctx context.Context, req *Request, rsp *Response) error {
+	rsp.Msg = "Hello " + req.Name
+	return nil
+}
+
+func (g *Greeter) Handle(ctx context.Context, msg *Request) error {
+	g.received <- msg.Name
+	return nil
+}
+
+func TestEnv(t *testing.T) {
+	env := NewEnv()
+	defer env.Stop()
+
+	greeter := &Greeter{received: make(chan string, 1)}
+
+	if _, err := env.Run("greeter", greeter); err != nil {
+		t.Fatal(err)
+	}
+
+	sub := env.NewServer()
+	if err := sub.Subscribe(sub.NewSubscriber("greetings", greeter.Handle)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := env.NewClient()
+
+	rsp := new(Response)
+	if err := c.Call(context.Background(), c.NewRequest("greeter", "Greeter.Hello", &Request{Name: "John"}), rsp); err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Msg != "Hello John" {
+		t.Fatalf("Expected Hello John got %v", rsp.Msg)
+	}
+
+	if err := c.Publish(context.Background(), c.NewMessage("greetings", &Request{Name: "Jane"})); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case name := <-greeter.received:
+		if name != "Jane" {
+			t.Fatalf("Expected Jane got %v", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the message to be received")
+	}
+}
+
+func TestClock(t *testing.T) {
+	c := NewClock(time.Unix(0, 0))
+
+	done := make(chan bool)
+	go func() {
+		c.Sleep(time.Minute)
+		close(done)
+	}()
+
+	for c.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	c.Advance(30 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("Expected the sleep to wait for the clock")
+	default:
+	}
+
+	c.Advance(30 * time.Second)
+	<-done
+
+	if c.Since(time.Unix(0, 0)) != time.Minute {
+		t.Fatalf("Expected a minute to have passed got %v", c.Since(time.Unix(0, 0)))
+	}
+}
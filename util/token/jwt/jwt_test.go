@@ -46,6 +46,9 @@ func TestInspect(t *testing.T) {
 		subject := "test"
 
 		acc := &auth.Account{ID: subject, Scopes: scopes, Metadata: md}
+		if err := acc.SetClaim("tier", 2); err != nil {
+			t.Fatal(err)
+		}
 		tok, err := j.Generate(acc)
 		if err != nil {
 			t.Fatalf("Generate returned %v error, expected nil", err)
@@ -64,6 +67,10 @@ func TestInspect(t *testing.T) {
 		if len(tok2.Metadata) != len(md) {
 			t.Errorf("Inspect returned %v as the token metadata, expected %v", tok2.Metadata, md)
 		}
+		var tier int
+		if err := tok2.Claim("tier", &tier); err != nil || tier != 2 {
+			t.Errorf("Inspect returned %v as the tier claim, expected 2", tier)
+		}
 	})
 
 	t.Run("Expired token", func(t *testing.T) {
@@ -0,0 +1,211 @@
+// Package wrapper is a registry of named middleware for the handlers and clients of services.
+// Middleware declares the middleware it must wrap or be wrapped by and is enabled and
+// configured through the config, so it's applied in a consistent order rather than the
+// order the wrappers happen to be passed to the server and client.
+package wrapper
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/micro/go-micro/v3/client"
+	"github.com/micro/go-micro/v3/config/reader"
+	"github.com/micro/go-micro/v3/server"
+)
+
+var (
+	// ErrExists is returned when middleware with the name is already registered
+	ErrExists = errors.New("middleware already registered")
+	// ErrNotFound is returned when the middleware isn't registered
+	ErrNotFound = errors.New("middleware not found")
+	// ErrCycle is returned when the ordering constraints of the middleware form a cycle
+	ErrCycle = errors.New("middleware ordering cycle")
+
+	// Path of the middleware config, the config of each middleware is under its name
+	// e.g {"middleware": {"ratelimit": {"enabled": true, "rate": 10}}}
+	Path = []string{"middleware"}
+
+	// DefaultManager is the default middleware registry
+	DefaultManager = NewManager()
+)
+
+// path of the config of the middleware
+func path(p ...string) []string {
+	return append(append([]string{}, Path...), p...)
+}
+
+// Middleware is a named wrapper of the handlers and or clients of a service
+type Middleware struct {
+	// Name the middleware is registered and configured with
+	Name string
+	// Before lists the middleware this middleware wraps, i.e it sees the requests before them
+	Before []string
+	// After lists the middleware which wrap this middleware
+	After []string
+	// Enabled is true if the middleware is used when it's not enabled or disabled in the config
+	Enabled bool
+	// Handler returns the handler wrapper configured with the config of the middleware
+	Handler func(reader.Value) (server.HandlerWrapper, error)
+	// Client returns the client wrapper configured with the config of the middleware
+	Client func(reader.Value) (client.Wrapper, error)
+}
+
+// Manager is a registry of middleware
+type Manager struct {
+	sync.RWMutex
+	middleware map[string]*Middleware
+	// names in the order the middleware was registered
+	names []string
+}
+
+// NewManager returns an empty middleware registry
+func NewManager() *Manager {
+	return &Manager{
+		middleware: make(map[string]*Middleware),
+	}
+}
+
+// Register the middleware
+func (m *Manager) Register(mw *Middleware) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if _, ok := m.middleware[mw.Name]; ok {
+		return ErrExists
+	}
+	m.middleware[mw.Name] = mw
+	m.names = append(m.names, mw.Name)
+	return nil
+}
+
+// Deregister the middleware
+func (m *Manager) Deregister(name string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if _, ok := m.middleware[name]; !ok {
+		return ErrNotFound
+	}
+	delete(m.middleware, name)
+	for i, n := range m.names {
+		if n == name {
+			m.names = append(m.names[:i], m.names[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Order returns the middleware enabled in the config from the outermost to the innermost.
+// Middleware without constraints between them is kept in the order it was registered.
+func (m *Manager) Order(values reader.Values) ([]*Middleware, error) {
+	m.RLock()
+	var enabled []*Middleware
+	for _, name := range m.names {
+		mw := m.middleware[name]
+		if values.Get(path(name, "enabled")...).Bool(mw.Enabled) {
+			enabled = append(enabled, mw)
+		}
+	}
+	m.RUnlock()
+
+	index := make(map[string]int, len(enabled))
+	for i, mw := range enabled {
+		index[mw.Name] = i
+	}
+
+	// edges from the outer to the inner middleware, constraints on middleware
+	// which isn't enabled are ignored
+	edges := make([][]int, len(enabled))
+	degree := make([]int, len(enabled))
+	edge := func(outer, inner string) {
+		o, ok1 := index[outer]
+		i, ok2 := index[inner]
+		if !ok1 || !ok2 {
+			return
+		}
+		edges[o] = append(edges[o], i)
+		degree[i]++
+	}
+	for _, mw := range enabled {
+		for _, n := range mw.Before {
+			edge(mw.Name, n)
+		}
+		for _, n := range mw.After {
+			edge(n, mw.Name)
+		}
+	}
+
+	// take the first middleware in the registration order without any outer middleware left
+	ordered := make([]*Middleware, 0, len(enabled))
+	done := make([]bool, len(enabled))
+	for len(ordered) < len(enabled) {
+		next := -1
+		for i := range enabled {
+			if !done[i] && degree[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return nil, ErrCycle
+		}
+
+		done[next] = true
+		ordered = append(ordered, enabled[next])
+		for _, i := range edges[next] {
+			degree[i]--
+		}
+	}
+
+	return ordered, nil
+}
+
+// HandlerWrappers returns the handler wrappers of the middleware enabled in the config in the
+// order they're passed to the server, e.g with server.WrapHandler
+func (m *Manager) HandlerWrappers(values reader.Values) ([]server.HandlerWrapper, error) {
+	ordered, err := m.Order(values)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrappers []server.HandlerWrapper
+	for _, mw := range ordered {
+		if mw.Handler == nil {
+			continue
+		}
+		w, err := mw.Handler(values.Get(path(mw.Name)...))
+		if err != nil {
+			return nil, err
+		}
+		wrappers = append(wrappers, w)
+	}
+	return wrappers, nil
+}
+
+// ClientWrappers returns the client wrappers of the middleware enabled in the config in the
+// order they're passed to the client, e.g with client.Wrap
+func (m *Manager) ClientWrappers(values reader.Values) ([]client.Wrapper, error) {
+	ordered, err := m.Order(values)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrappers []client.Wrapper
+	for _, mw := range ordered {
+		if mw.Client == nil {
+			continue
+		}
+		w, err := mw.Client(values.Get(path(mw.Name)...))
+		if err != nil {
+			return nil, err
+		}
+		wrappers = append(wrappers, w)
+	}
+	return wrappers, nil
+}
+
+// Register the middleware with the default manager
+func Register(mw *Middleware) error {
+	return DefaultManager.Register(mw)
+}
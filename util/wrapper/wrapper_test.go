@@ -0,0 +1,80 @@
+package wrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/v3/config"
+	"github.com/micro/go-micro/v3/config/reader"
+	"github.com/micro/go-micro/v3/config/source/memory"
+	"github.com/micro/go-micro/v3/server"
+)
+
+func testConfig(t *testing.T, data string) config.Config {
+	c, err := config.NewConfig(config.WithSource(memory.NewSource(memory.WithJSON([]byte(data)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestManager(t *testing.T) {
+	var calls []string
+	handler := func(name string) func(reader.Value) (server.HandlerWrapper, error) {
+		return func(v reader.Value) (server.HandlerWrapper, error) {
+			var conf struct {
+				Prefix string `json:"prefix"`
+			}
+			if err := v.Scan(&conf); err != nil {
+				return nil, err
+			}
+			prefix := conf.Prefix
+			return func(fn server.HandlerFunc) server.HandlerFunc {
+				return func(ctx context.Context, req server.Request, rsp interface{}) error {
+					calls = append(calls, prefix+name)
+					return fn(ctx, req, rsp)
+				}
+			}, nil
+		}
+	}
+
+	m := NewManager()
+	m.Register(&Middleware{Name: "metrics", Enabled: true, Handler: handler("metrics")})
+	m.Register(&Middleware{Name: "ratelimit", After: []string{"auth"}, Handler: handler("ratelimit")})
+	m.Register(&Middleware{Name: "auth", Enabled: true, Before: []string{"metrics"}, Handler: handler("auth")})
+	m.Register(&Middleware{Name: "trace", Enabled: true, Before: []string{"auth"}, Handler: handler("trace")})
+
+	if err := m.Register(&Middleware{Name: "auth"}); err != ErrExists {
+		t.Fatalf("Expected the middleware to exist got %v", err)
+	}
+
+	c := testConfig(t, `{"middleware": {"ratelimit": {"enabled": true, "prefix": "x-"}, "trace": {"enabled": false}}}`)
+	wrappers, err := m.HandlerWrappers(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// wrapped the way the server applies the wrappers
+	var fn server.HandlerFunc = func(ctx context.Context, req server.Request, rsp interface{}) error {
+		return nil
+	}
+	for i := len(wrappers); i > 0; i-- {
+		fn = wrappers[i-1](fn)
+	}
+	fn(context.Background(), nil, nil)
+
+	expect := []string{"auth", "metrics", "x-ratelimit"}
+	if len(calls) != len(expect) {
+		t.Fatalf("Expected %v got %v", expect, calls)
+	}
+	for i, name := range expect {
+		if calls[i] != name {
+			t.Fatalf("Expected %v got %v", expect, calls)
+		}
+	}
+
+	m.Register(&Middleware{Name: "retry", Enabled: true, Before: []string{"auth"}, After: []string{"metrics"}})
+	if _, err := m.Order(c); err != ErrCycle {
+		t.Fatalf("Expected a cycle got %v", err)
+	}
+}